@@ -5,15 +5,25 @@ import (
 	"database/sql"
 	"log/slog"
 	"net/http"
+	"net/mail"
+	"net/smtp"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/example/intent/backend/internal/calsync"
 	"github.com/example/intent/backend/internal/database"
 	"github.com/example/intent/backend/internal/handlers"
+	"github.com/example/intent/backend/internal/jobs"
 	"github.com/example/intent/backend/internal/logging"
+	"github.com/example/intent/backend/internal/messaging"
+	"github.com/example/intent/backend/internal/notify"
+	"github.com/example/intent/backend/internal/secret"
+	"github.com/example/intent/backend/internal/tracker"
+	"github.com/example/intent/backend/internal/webhooks"
 )
 
 func main() {
@@ -27,14 +37,52 @@ func main() {
 	}
 	defer db.Close()
 
+	workTracker := setupTracker(logger)
+	credentials := setupCalendarCredentials(logger)
+
 	srv := &http.Server{
 		Addr:         ":8080",
-		Handler:      routes(logger, db),
+		Handler:      routes(logger, db, workTracker, credentials),
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
 
+	pollCtx, stopPolling := context.WithCancel(context.Background())
+	defer stopPolling()
+
+	if interval := calendarSyncInterval(logger); interval > 0 {
+		go calsync.NewPoller(logger, db, calsync.DefaultClient, credentials, interval).Run(pollCtx)
+	}
+
+	workersDone := make(chan struct{})
+	if workers := jobWorkers(logger); workers > 0 {
+		pool := jobs.NewPool(logger, db, workers)
+		notifier := setupNotifier(logger, db)
+		pool.Register(notify.KindDeliver, notifier.Deliver)
+		pool.Register(webhooks.KindDeliver, webhooks.NewDeliverer(logger, db, webhooks.DefaultClient).Deliver)
+		registerSwarmChannels(pool, logger, db)
+		jobs.RegisterNudges(pool, logger, db, notifier)
+		jobs.RegisterOutcomeAlerts(pool, logger, db, notifier, outcomeEscalationDelay(logger))
+		jobs.RegisterCoverage(pool, logger, db)
+		planners := []string{jobs.KindNudgePlan, jobs.KindOutcomePlan, jobs.KindCoveragePlan}
+		if workTracker != nil {
+			jobs.RegisterTrackerSync(pool, logger, db, workTracker)
+			planners = append(planners, jobs.KindTrackerPlan)
+		}
+		for _, kind := range planners {
+			if err := jobs.EnqueuePlanner(pollCtx, db, kind, time.Now()); err != nil {
+				logger.Error("failed to schedule planner", "kind", kind, "error", err)
+			}
+		}
+		go func() {
+			defer close(workersDone)
+			pool.Run(pollCtx)
+		}()
+	} else {
+		close(workersDone)
+	}
+
 	go func() {
 		logger.Info("server listening", "addr", srv.Addr)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -47,12 +95,160 @@ func main() {
 	sig := <-sigCh
 	logger.Info("shutting down", "signal", sig.String())
 
+	stopPolling()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Error("graceful shutdown failed", "error", err)
 	}
+
+	select {
+	case <-workersDone:
+	case <-ctx.Done():
+		logger.Warn("background jobs still running at shutdown")
+	}
+}
+
+// jobWorkers reads JOB_WORKERS (default 2), the number of background job
+// workers to run. Zero leaves jobs to other instances.
+func jobWorkers(logger *slog.Logger) int {
+	value := os.Getenv("JOB_WORKERS")
+	if value == "" {
+		return 2
+	}
+
+	workers, err := strconv.Atoi(value)
+	if err != nil || workers < 0 {
+		logger.Warn("invalid JOB_WORKERS, using default", "value", value, "error", err)
+		return 2
+	}
+
+	return workers
+}
+
+// calendarSyncInterval reads CALENDAR_SYNC_INTERVAL (a Go duration, default
+// 15m). Zero disables polling of members' calendar sources.
+func calendarSyncInterval(logger *slog.Logger) time.Duration {
+	value := os.Getenv("CALENDAR_SYNC_INTERVAL")
+	if value == "" {
+		return 15 * time.Minute
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < 0 {
+		logger.Warn("invalid CALENDAR_SYNC_INTERVAL, using default", "value", value, "error", err)
+		return 15 * time.Minute
+	}
+
+	return interval
+}
+
+// outcomeEscalationDelay reads OUTCOME_ESCALATION_DAYS (default 3), how long
+// after the first missing-outcome alert Chapter Leads get an escalation
+// digest.
+func outcomeEscalationDelay(logger *slog.Logger) time.Duration {
+	value := os.Getenv("OUTCOME_ESCALATION_DAYS")
+	if value == "" {
+		return 3 * 24 * time.Hour
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 {
+		logger.Warn("invalid OUTCOME_ESCALATION_DAYS, using default", "value", value, "error", err)
+		return 3 * 24 * time.Hour
+	}
+
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// setupNotifier builds the notification channels. Email is only enabled when
+// SMTP_ADDR (host:port) is set; SMTP_USERNAME and SMTP_PASSWORD are optional.
+func setupNotifier(logger *slog.Logger, db *sql.DB) *notify.Notifier {
+	channels := []notify.Channel{notify.NewInbox(db), notify.NewWebhook(notify.DefaultClient)}
+
+	if addr := os.Getenv("SMTP_ADDR"); addr != "" {
+		from, err := mail.ParseAddress(getEnv("SMTP_FROM", "Intent <intent@localhost>"))
+		if err != nil {
+			logger.Warn("invalid SMTP_FROM, using default", "error", err)
+			from = &mail.Address{Name: "Intent", Address: "intent@localhost"}
+		}
+
+		var auth smtp.Auth
+		if user := os.Getenv("SMTP_USERNAME"); user != "" {
+			host, _, _ := strings.Cut(addr, ":")
+			auth = smtp.PlainAuth("", user, os.Getenv("SMTP_PASSWORD"), host)
+		}
+
+		channels = append(channels, notify.NewEmail(addr, *from, auth))
+	} else {
+		logger.Info("SMTP_ADDR not set, email notifications disabled")
+	}
+
+	return notify.NewNotifier(logger, db, channels...)
+}
+
+// setupCalendarCredentials reads CALENDAR_CREDENTIALS_KEY, the base64 encoded
+// 32-byte key calendar source passwords are sealed with. Without it calendar
+// sources can only be configured without a password.
+func setupCalendarCredentials(logger *slog.Logger) *secret.Box {
+	value := os.Getenv("CALENDAR_CREDENTIALS_KEY")
+	if value == "" {
+		logger.Info("CALENDAR_CREDENTIALS_KEY not set, calendar source passwords disabled")
+		return nil
+	}
+
+	box, err := secret.ParseKey(value)
+	if err != nil {
+		logger.Warn("invalid CALENDAR_CREDENTIALS_KEY, calendar source passwords disabled", "error", err)
+		return nil
+	}
+
+	return box
+}
+
+// setupTracker builds the work tracker intents are linked to. Tracking is
+// only enabled when JIRA_BASE_URL is set; issues are created in JIRA_PROJECT
+// as JIRA_ISSUE_TYPE. JIRA_EMAIL and JIRA_API_TOKEN authenticate a Jira
+// Cloud API token, or JIRA_API_TOKEN alone a personal access token.
+func setupTracker(logger *slog.Logger) tracker.Tracker {
+	baseURL := os.Getenv("JIRA_BASE_URL")
+	if baseURL == "" {
+		logger.Info("JIRA_BASE_URL not set, work tracker sync disabled")
+		return nil
+	}
+
+	project := os.Getenv("JIRA_PROJECT")
+	if project == "" {
+		logger.Warn("JIRA_PROJECT not set, work tracker sync disabled")
+		return nil
+	}
+
+	return tracker.NewJira(baseURL, project, getEnv("JIRA_ISSUE_TYPE", "Task"), os.Getenv("JIRA_EMAIL"), os.Getenv("JIRA_API_TOKEN"), tracker.DefaultClient)
+}
+
+// registerSwarmChannels registers the swarm chat channel jobs. Channels are
+// only provisioned when SLACK_BOT_TOKEN is set; SLACK_API_URL points the
+// messenger at another Slack-compatible API. Without a token the jobs are
+// completed unrun so they do not pile up.
+func registerSwarmChannels(pool *jobs.Pool, logger *slog.Logger, db *sql.DB) {
+	kinds := []string{messaging.KindProvision, messaging.KindInvite, messaging.KindArchive}
+
+	token := os.Getenv("SLACK_BOT_TOKEN")
+	if token == "" {
+		logger.Info("SLACK_BOT_TOKEN not set, swarm chat channels disabled")
+		for _, kind := range kinds {
+			pool.Register(kind, func(context.Context, database.Job) error { return nil })
+		}
+		return
+	}
+
+	messenger := messaging.NewSlack(getEnv("SLACK_API_URL", messaging.SlackAPIURL), token, messaging.DefaultClient)
+	provisioner := messaging.NewProvisioner(logger, db, messenger)
+	pool.Register(messaging.KindProvision, provisioner.Provision)
+	pool.Register(messaging.KindInvite, provisioner.Invite)
+	pool.Register(messaging.KindArchive, provisioner.Archive)
 }
 
 func setupDatabase(logger *slog.Logger) (*sql.DB, error) {
@@ -89,15 +285,41 @@ func setupDatabase(logger *slog.Logger) (*sql.DB, error) {
 	return db, nil
 }
 
-func routes(logger *slog.Logger, db *sql.DB) http.Handler {
+func routes(logger *slog.Logger, db *sql.DB, workTracker tracker.Tracker, credentials *secret.Box) http.Handler {
 	mux := http.NewServeMux()
 	mux.Handle("/api/hello", handlers.HelloHandler(logger, db))
-	intentsHandler := handlers.IntentsHandler(logger, db)
+	intentsHandler := handlers.TrackedIntentsHandler(logger, db, workTracker)
 	mux.Handle("/api/intents", intentsHandler)
 	mux.Handle("/api/intents/", intentsHandler)
+	mux.Handle("/api/intents:fromTemplate", intentsHandler)
+	intentTemplatesHandler := handlers.IntentTemplatesHandler(logger, db)
+	mux.Handle("/api/intent-templates", intentTemplatesHandler)
+	mux.Handle("/api/intent-templates/", intentTemplatesHandler)
+	exemplarsHandler := handlers.ExemplarsHandler(logger, db)
+	mux.Handle("/api/exemplars", exemplarsHandler)
+	mux.Handle("/api/exemplars/", exemplarsHandler)
 	goalsHandler := handlers.GoalsHandler(logger, db)
 	mux.Handle("/api/goals", goalsHandler)
 	mux.Handle("/api/goals/", goalsHandler)
+	chaptersHandler := handlers.ChaptersHandler(logger, db)
+	mux.Handle("/api/chapters", chaptersHandler)
+	mux.Handle("/api/chapters/", chaptersHandler)
+	membersHandler := handlers.CalendarMembersHandler(logger, db, credentials)
+	mux.Handle("/api/members", membersHandler)
+	mux.Handle("/api/members/", membersHandler)
+	sessionsHandler := handlers.SessionsHandler(logger, db)
+	mux.Handle("/api/sessions", sessionsHandler)
+	mux.Handle("/api/sessions/", sessionsHandler)
+	retroTemplatesHandler := handlers.RetroTemplatesHandler(logger, db)
+	mux.Handle("/api/retro-templates", retroTemplatesHandler)
+	mux.Handle("/api/retro-templates/", retroTemplatesHandler)
+	jobsHandler := handlers.JobsHandler(logger, db)
+	mux.Handle("/api/jobs", jobsHandler)
+	mux.Handle("/api/jobs/", jobsHandler)
+	webhooksHandler := handlers.WebhooksHandler(logger, db)
+	mux.Handle("/api/webhooks", webhooksHandler)
+	mux.Handle("/api/webhooks/", webhooksHandler)
+	mux.Handle("/api/analytics/", handlers.AnalyticsHandler(logger, db))
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok"))
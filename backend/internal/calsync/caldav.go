@@ -0,0 +1,139 @@
+package calsync
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/ical"
+)
+
+// maxCalendarBytes caps how much of a calendar response is read.
+const maxCalendarBytes = 10 << 20
+
+// DefaultClient is used to poll calendar servers.
+var DefaultClient = &http.Client{Timeout: 20 * time.Second}
+
+// ErrUnsupportedURL is returned for calendar URLs that are not http(s).
+var ErrUnsupportedURL = errors.New("calendar url must use http or https")
+
+const calendarQuery = `<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop>
+    <c:calendar-data/>
+  </d:prop>
+  <c:filter>
+    <c:comp-filter name="VCALENDAR">
+      <c:comp-filter name="VEVENT">
+        <c:time-range start="%s" end="%s"/>
+      </c:comp-filter>
+    </c:comp-filter>
+  </c:filter>
+</c:calendar-query>`
+
+type multistatus struct {
+	Responses []struct {
+		Propstats []struct {
+			Status string `xml:"DAV: status"`
+			Prop   struct {
+				CalendarData string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+			} `xml:"DAV: prop"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+// Fetch retrieves the events of a calendar between from and to. CalDAV
+// collections are queried with a calendar-query REPORT; servers that reject
+// REPORT, or plain ICS URLs, are read with GET instead. When the source has a
+// username, it is sent with password as basic auth.
+func Fetch(ctx context.Context, client *http.Client, source database.CalendarSource, password string, from, to time.Time, loc *time.Location) ([]ical.VEvent, error) {
+	if !strings.HasPrefix(source.URL, "http://") && !strings.HasPrefix(source.URL, "https://") {
+		return nil, ErrUnsupportedURL
+	}
+
+	body := fmt.Sprintf(calendarQuery, from.UTC().Format("20060102T150405Z"), to.UTC().Format("20060102T150405Z"))
+	resp, err := do(ctx, client, source, password, "REPORT", strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented, http.StatusBadRequest:
+		resp.Body.Close()
+		resp, err = do(ctx, client, source, password, http.MethodGet, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCalendarBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusMultiStatus:
+		return parseMultistatus(data, loc)
+	case resp.StatusCode == http.StatusOK && isCalendar(resp.Header.Get("Content-Type"), data):
+		return ical.Parse(bytes.NewReader(data), loc)
+	default:
+		return nil, fmt.Errorf("calendar server responded with %s", resp.Status)
+	}
+}
+
+func do(ctx context.Context, client *http.Client, source database.CalendarSource, password, method string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, source.URL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+		req.Header.Set("Depth", "1")
+	}
+	req.Header.Set("Accept", "text/calendar, application/xml")
+	if source.Username != "" {
+		req.SetBasicAuth(source.Username, password)
+	}
+
+	return client.Do(req)
+}
+
+func parseMultistatus(data []byte, loc *time.Location) ([]ical.VEvent, error) {
+	var status multistatus
+	if err := xml.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("invalid CalDAV response: %w", err)
+	}
+
+	var events []ical.VEvent
+	for _, response := range status.Responses {
+		for _, propstat := range response.Propstats {
+			if propstat.Prop.CalendarData == "" || (propstat.Status != "" && !strings.Contains(propstat.Status, " 200 ")) {
+				continue
+			}
+			parsed, err := ical.Parse(strings.NewReader(propstat.Prop.CalendarData), loc)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, parsed...)
+		}
+	}
+
+	return events, nil
+}
+
+func isCalendar(contentType string, data []byte) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType == "text/calendar" {
+		return true
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("BEGIN:VCALENDAR"))
+}
@@ -0,0 +1,90 @@
+package calsync
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+)
+
+const busyEvent = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:dentist\r\nDTSTART:20240506T140000Z\r\nDTEND:20240506T150000Z\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+func TestFetchCalendarQuery(t *testing.T) {
+	var gotBody, gotDepth, gotUser string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "REPORT" {
+			t.Errorf("expected REPORT got %s", r.Method)
+		}
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		gotDepth = r.Header.Get("Depth")
+		gotUser, _, _ = r.BasicAuth()
+
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.WriteHeader(http.StatusMultiStatus)
+		io.WriteString(w, `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:response>
+    <d:href>/cal/dentist.ics</d:href>
+    <d:propstat>
+      <d:prop><c:calendar-data>`+busyEvent+`</c:calendar-data></d:prop>
+      <d:status>HTTP/1.1 200 OK</d:status>
+    </d:propstat>
+  </d:response>
+</d:multistatus>`)
+	}))
+	t.Cleanup(server.Close)
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	events, err := Fetch(context.Background(), server.Client(), database.CalendarSource{URL: server.URL + "/cal/", Username: "ada"}, "app-pass", from, from.Add(Horizon), time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(events) != 1 || events[0].UID != "dentist" {
+		t.Fatalf("unexpected events %+v", events)
+	}
+
+	if gotDepth != "1" || gotUser != "ada" {
+		t.Fatalf("expected Depth 1 and basic auth, got depth %q user %q", gotDepth, gotUser)
+	}
+
+	if !strings.Contains(gotBody, `<c:time-range start="20240501T000000Z"`) {
+		t.Fatalf("expected time-range filter in body, got %s", gotBody)
+	}
+}
+
+func TestFetchFallsBackToGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/calendar")
+		io.WriteString(w, busyEvent)
+	}))
+	t.Cleanup(server.Close)
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	events, err := Fetch(context.Background(), server.Client(), database.CalendarSource{URL: server.URL + "/basic.ics"}, "", from, from.Add(Horizon), time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event got %d", len(events))
+	}
+}
+
+func TestFetchRejectsNonHTTP(t *testing.T) {
+	_, err := Fetch(context.Background(), http.DefaultClient, database.CalendarSource{URL: "file:///etc/passwd"}, "", time.Now(), time.Now(), time.UTC)
+	if err != ErrUnsupportedURL {
+		t.Fatalf("expected ErrUnsupportedURL got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+// Package calsync derives session availability from members' calendars,
+// either uploaded as ICS files or polled from a CalDAV server.
+package calsync
+
+import (
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/ical"
+)
+
+// Derive turns the busy intervals overlapping a session window into an
+// availability status. A window with no busy time is available, a fully busy
+// window is unavailable, and anything in between is partial over the longest
+// free stretch, since availability records hold a single contiguous range.
+func Derive(windowStart, windowEnd time.Time, busy []ical.Interval) (string, *time.Time, *time.Time) {
+	var (
+		bestStart, bestEnd time.Time
+		cursor             = windowStart
+	)
+
+	consider := func(start, end time.Time) {
+		if end.Sub(start) > bestEnd.Sub(bestStart) {
+			bestStart, bestEnd = start, end
+		}
+	}
+
+	for _, interval := range busy {
+		if !interval.End.After(windowStart) || !interval.Start.Before(windowEnd) {
+			continue
+		}
+		if interval.Start.After(cursor) {
+			consider(cursor, interval.Start)
+		}
+		if interval.End.After(cursor) {
+			cursor = interval.End
+		}
+	}
+	if cursor.Before(windowEnd) {
+		consider(cursor, windowEnd)
+	}
+
+	switch {
+	case !bestStart.Before(bestEnd):
+		return database.AvailabilityUnavailable, nil, nil
+	case bestStart.Equal(windowStart) && bestEnd.Equal(windowEnd):
+		return database.AvailabilityAvailable, nil, nil
+	default:
+		start, end := bestStart.UTC(), bestEnd.UTC()
+		return database.AvailabilityPartial, &start, &end
+	}
+}
@@ -0,0 +1,60 @@
+package calsync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/ical"
+)
+
+func TestDerive(t *testing.T) {
+	start := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+	end := start.Add(4 * time.Hour)
+	at := func(hours float64) time.Time { return start.Add(time.Duration(hours * float64(time.Hour))) }
+
+	cases := []struct {
+		name      string
+		busy      []ical.Interval
+		status    string
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{name: "free", status: database.AvailabilityAvailable},
+		{name: "fully busy", busy: []ical.Interval{{Start: at(-1), End: at(5)}}, status: database.AvailabilityUnavailable},
+		{
+			name:      "longest gap wins",
+			busy:      []ical.Interval{{Start: at(0.5), End: at(1)}, {Start: at(3.5), End: at(4)}},
+			status:    database.AvailabilityPartial,
+			wantStart: at(1),
+			wantEnd:   at(3.5),
+		},
+		{
+			name:      "busy at start",
+			busy:      []ical.Interval{{Start: at(0), End: at(2)}},
+			status:    database.AvailabilityPartial,
+			wantStart: at(2),
+			wantEnd:   end,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, gotStart, gotEnd := Derive(start, end, tc.busy)
+			if status != tc.status {
+				t.Fatalf("expected status %s got %s", tc.status, status)
+			}
+
+			if tc.status != database.AvailabilityPartial {
+				if gotStart != nil || gotEnd != nil {
+					t.Fatalf("expected no range got %v - %v", gotStart, gotEnd)
+				}
+				return
+			}
+
+			if !gotStart.Equal(tc.wantStart) || !gotEnd.Equal(tc.wantEnd) {
+				t.Fatalf("expected %s - %s got %s - %s", tc.wantStart, tc.wantEnd, gotStart, gotEnd)
+			}
+		})
+	}
+}
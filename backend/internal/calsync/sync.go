@@ -0,0 +1,190 @@
+package calsync
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/ical"
+	"github.com/example/intent/backend/internal/schedule"
+	"github.com/example/intent/backend/internal/secret"
+)
+
+// Horizon is how far ahead of now sessions are synced.
+const Horizon = 8 * 7 * 24 * time.Hour
+
+// Result summarises a sync run for one member.
+type Result struct {
+	Availability []database.Availability
+	Conflicts    int
+}
+
+// MemberLocation returns the timezone of the member's chapter, used to
+// interpret floating times in their calendar.
+func MemberLocation(ctx context.Context, db *sql.DB, member database.Member) (*time.Location, error) {
+	chapter, err := database.GetChapter(ctx, db, member.ChapterID)
+	if err != nil {
+		return nil, err
+	}
+	return schedule.LoadLocation(chapter.Timezone)
+}
+
+// SyncMember derives availability for each of the member's upcoming sessions
+// from their calendar events and records it.
+func SyncMember(ctx context.Context, db *sql.DB, member database.Member, events []ical.VEvent, now time.Time) (Result, error) {
+	from, to := now.UTC(), now.UTC().Add(Horizon)
+	chapterID := member.ChapterID
+
+	sessions, err := database.ListSessions(ctx, db, database.SessionFilters{
+		ChapterID:    &chapterID,
+		StartsAfter:  &from,
+		StartsBefore: &to,
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{Availability: make([]database.Availability, 0, len(sessions))}
+	for _, session := range sessions {
+		if session.State == database.SessionClosed {
+			continue
+		}
+
+		status, startsAt, endsAt := Derive(session.StartsAt, session.EndsAt, ical.Busy(events, session.StartsAt, session.EndsAt))
+
+		record, err := database.ApplySyncedAvailability(ctx, db, database.AvailabilityInput{
+			SessionID: session.ID,
+			MemberID:  member.ID,
+			Status:    status,
+			StartsAt:  startsAt,
+			EndsAt:    endsAt,
+		})
+		if err != nil {
+			return Result{}, err
+		}
+
+		if record.SyncConflict {
+			result.Conflicts++
+		}
+		result.Availability = append(result.Availability, record)
+	}
+
+	return result, nil
+}
+
+// SyncSource polls a member's configured calendar and syncs their
+// availability, recording the outcome on the source. The source's password is
+// opened with credentials; a nil box only supports sources without one.
+func SyncSource(ctx context.Context, db *sql.DB, client *http.Client, credentials *secret.Box, source database.CalendarSource, now time.Time) (Result, error) {
+	result, err := syncSource(ctx, db, client, credentials, source, now)
+
+	message := ""
+	if err != nil {
+		message = err.Error()
+	}
+	if recordErr := database.RecordCalendarSourceSync(ctx, db, source.MemberID, now, message); recordErr != nil && err == nil {
+		err = recordErr
+	}
+
+	return result, err
+}
+
+func syncSource(ctx context.Context, db *sql.DB, client *http.Client, credentials *secret.Box, source database.CalendarSource, now time.Time) (Result, error) {
+	password, err := openPassword(credentials, source)
+	if err != nil {
+		return Result{}, err
+	}
+
+	member, err := database.GetMember(ctx, db, source.MemberID)
+	if err != nil {
+		return Result{}, err
+	}
+
+	loc, err := MemberLocation(ctx, db, member)
+	if err != nil {
+		return Result{}, err
+	}
+
+	events, err := Fetch(ctx, client, source, password, now, now.Add(Horizon), loc)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return SyncMember(ctx, db, member, events, now)
+}
+
+func openPassword(credentials *secret.Box, source database.CalendarSource) (string, error) {
+	if source.SealedPassword == "" {
+		return "", nil
+	}
+
+	if credentials == nil {
+		return "", errors.New("calendar credentials key is not configured")
+	}
+
+	password, err := credentials.Open(source.SealedPassword)
+	if err != nil {
+		return "", errors.New("calendar password cannot be read; enter it again")
+	}
+
+	return password, nil
+}
+
+// Poller periodically syncs every configured calendar source.
+type Poller struct {
+	logger      *slog.Logger
+	db          *sql.DB
+	client      *http.Client
+	credentials *secret.Box
+	interval    time.Duration
+}
+
+// NewPoller constructs a poller that syncs all sources every interval,
+// opening their passwords with credentials.
+func NewPoller(logger *slog.Logger, db *sql.DB, client *http.Client, credentials *secret.Box, interval time.Duration) *Poller {
+	return &Poller{logger: logger, db: db, client: client, credentials: credentials, interval: interval}
+}
+
+// Run polls until ctx is cancelled.
+func (p *Poller) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.SyncAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncAll syncs every configured source once. Failures are logged and
+// recorded on the source without stopping the remaining syncs.
+func (p *Poller) SyncAll(ctx context.Context) {
+	sources, err := database.ListCalendarSources(ctx, p.db)
+	if err != nil {
+		p.logger.ErrorContext(ctx, "failed to list calendar sources", "error", err)
+		return
+	}
+
+	for _, source := range sources {
+		if ctx.Err() != nil {
+			return
+		}
+
+		result, err := SyncSource(ctx, p.db, p.client, p.credentials, source, time.Now().UTC())
+		if err != nil {
+			p.logger.WarnContext(ctx, "calendar sync failed", "member_id", source.MemberID, "error", err)
+			continue
+		}
+
+		p.logger.InfoContext(ctx, "calendar synced", "member_id", source.MemberID, "sessions", len(result.Availability), "conflicts", result.Conflicts)
+	}
+}
@@ -0,0 +1,418 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Availability statuses a member can declare for a session.
+const (
+	AvailabilityAvailable   = "available"
+	AvailabilityPartial     = "partial"
+	AvailabilityUnavailable = "unavailable"
+)
+
+// AvailabilityRemoved is the status member calendar feeds report for
+// availability the member withdrew. It is never stored on an availability row.
+const AvailabilityRemoved = "removed"
+
+// ErrAvailabilityNotFound is returned when a member has not declared
+// availability for a session.
+var ErrAvailabilityNotFound = errors.New("availability not found")
+
+// Availability sources. Manual entries always win over synced ones.
+const (
+	AvailabilitySourceManual = "manual"
+	AvailabilitySourceSync   = "sync"
+)
+
+// Availability records whether a member can attend a session. StartsAt and
+// EndsAt are only set for partial availability.
+type Availability struct {
+	SessionID       uuid.UUID
+	MemberID        uuid.UUID
+	Status          string
+	StartsAt        *time.Time
+	EndsAt          *time.Time
+	AvailableBlocks int
+	Source          string
+	SyncConflict    bool
+	Synced          *SyncedAvailability
+	UpdatedAt       time.Time
+}
+
+// SyncedAvailability is the availability most recently derived from the
+// member's calendar, kept alongside manual entries so discrepancies can be
+// surfaced.
+type SyncedAvailability struct {
+	Status   string
+	StartsAt *time.Time
+	EndsAt   *time.Time
+	SyncedAt time.Time
+}
+
+// AvailabilityInput captures a member's declared availability for a session.
+type AvailabilityInput struct {
+	SessionID uuid.UUID
+	MemberID  uuid.UUID
+	Status    string
+	StartsAt  *time.Time
+	EndsAt    *time.Time
+}
+
+const availabilityColumns = `session_id, member_id, status, starts_at, ends_at, source, sync_conflict, synced_status, synced_starts_at, synced_ends_at, synced_at, updated_at`
+
+// UpsertAvailability records a member's manual availability for a session.
+// Reducing availability below the blocks the member has already committed
+// returns a *CapacityError so commitments are released explicitly first.
+func UpsertAvailability(ctx context.Context, db *sql.DB, input AvailabilityInput) (Availability, error) {
+	if db == nil {
+		return Availability{}, errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Availability{}, err
+	}
+	defer tx.Rollback()
+
+	capacity, err := loadSessionCapacity(ctx, tx, input.SessionID, true)
+	if err != nil {
+		return Availability{}, err
+	}
+
+	if err := lockMember(ctx, tx, input.MemberID, capacity.Session.ChapterID); err != nil {
+		return Availability{}, err
+	}
+
+	available := AvailableBlocks(capacity.Session, capacity.BlockLength, input.Status, input.StartsAt, input.EndsAt)
+
+	var committed int
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(SUM(blocks), 0) FROM commitments WHERE session_id = $1 AND member_id = $2`, input.SessionID, input.MemberID).Scan(&committed); err != nil {
+		return Availability{}, err
+	}
+
+	if committed > available {
+		member := input.MemberID
+		return Availability{}, &CapacityError{
+			Cap:       CapMemberBlocks,
+			Limit:     available,
+			Current:   committed,
+			SessionID: input.SessionID,
+			ChapterID: capacity.Session.ChapterID,
+			MemberID:  &member,
+		}
+	}
+
+	const query = `
+INSERT INTO availability (session_id, member_id, status, starts_at, ends_at, source, updated_at)
+VALUES ($1, $2, $3, $4, $5, 'manual', $6)
+ON CONFLICT (session_id, member_id) DO UPDATE
+SET status = EXCLUDED.status,
+    starts_at = EXCLUDED.starts_at,
+    ends_at = EXCLUDED.ends_at,
+    source = EXCLUDED.source,
+    sync_conflict = availability.synced_status IS NOT NULL
+        AND (availability.synced_status, availability.synced_starts_at, availability.synced_ends_at)
+            IS DISTINCT FROM (EXCLUDED.status, EXCLUDED.starts_at, EXCLUDED.ends_at),
+    updated_at = EXCLUDED.updated_at,
+    sequence = availability.sequence + 1
+RETURNING ` + availabilityColumns
+
+	record, err := scanAvailability(tx.QueryRowContext(ctx, query, input.SessionID, input.MemberID, input.Status, timePtrValue(input.StartsAt), timePtrValue(input.EndsAt), time.Now().UTC()))
+	if err != nil {
+		return Availability{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Availability{}, err
+	}
+
+	record.AvailableBlocks = available
+	return record, nil
+}
+
+// ApplySyncedAvailability records availability derived from a member's
+// calendar. Manual entries are never overwritten: the synced value is stored
+// next to them and SyncConflict is raised when the two disagree. A synced
+// value that would leave the member with fewer blocks than they have already
+// committed is treated the same way.
+func ApplySyncedAvailability(ctx context.Context, db *sql.DB, input AvailabilityInput) (Availability, error) {
+	if db == nil {
+		return Availability{}, errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Availability{}, err
+	}
+	defer tx.Rollback()
+
+	capacity, err := loadSessionCapacity(ctx, tx, input.SessionID, true)
+	if err != nil {
+		return Availability{}, err
+	}
+
+	if err := lockMember(ctx, tx, input.MemberID, capacity.Session.ChapterID); err != nil {
+		return Availability{}, err
+	}
+
+	var (
+		existing    AvailabilityInput
+		source      string
+		hasExisting = true
+		startsAt    sql.NullTime
+		endsAt      sql.NullTime
+	)
+
+	err = tx.QueryRowContext(ctx, `SELECT status, starts_at, ends_at, source FROM availability WHERE session_id = $1 AND member_id = $2 FOR UPDATE`, input.SessionID, input.MemberID).Scan(&existing.Status, &startsAt, &endsAt, &source)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		hasExisting = false
+	case err != nil:
+		return Availability{}, err
+	}
+	existing.StartsAt = nullTimePtr(startsAt)
+	existing.EndsAt = nullTimePtr(endsAt)
+
+	available := AvailableBlocks(capacity.Session, capacity.BlockLength, input.Status, input.StartsAt, input.EndsAt)
+
+	var committed int
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(SUM(blocks), 0) FROM commitments WHERE session_id = $1 AND member_id = $2`, input.SessionID, input.MemberID).Scan(&committed); err != nil {
+		return Availability{}, err
+	}
+
+	now := time.Now().UTC()
+	keepExisting := hasExisting && (source == AvailabilitySourceManual || committed > available)
+
+	var row *sql.Row
+	if keepExisting {
+		const query = `
+UPDATE availability
+SET synced_status = $3,
+    synced_starts_at = $4,
+    synced_ends_at = $5,
+    synced_at = $6,
+    sync_conflict = $7
+WHERE session_id = $1 AND member_id = $2
+RETURNING ` + availabilityColumns
+
+		conflict := !sameAvailability(existing, input)
+		row = tx.QueryRowContext(ctx, query, input.SessionID, input.MemberID, input.Status, timePtrValue(input.StartsAt), timePtrValue(input.EndsAt), now, conflict)
+	} else {
+		const query = `
+INSERT INTO availability (session_id, member_id, status, starts_at, ends_at, source, synced_status, synced_starts_at, synced_ends_at, synced_at, sync_conflict, updated_at)
+VALUES ($1, $2, $3, $4, $5, 'sync', $3, $4, $5, $6, FALSE, $6)
+ON CONFLICT (session_id, member_id) DO UPDATE
+SET status = EXCLUDED.status,
+    starts_at = EXCLUDED.starts_at,
+    ends_at = EXCLUDED.ends_at,
+    synced_status = EXCLUDED.synced_status,
+    synced_starts_at = EXCLUDED.synced_starts_at,
+    synced_ends_at = EXCLUDED.synced_ends_at,
+    synced_at = EXCLUDED.synced_at,
+    sync_conflict = FALSE,
+    updated_at = EXCLUDED.updated_at,
+    sequence = availability.sequence + 1
+WHERE availability.sync_conflict
+   OR (availability.status, availability.starts_at, availability.ends_at) IS DISTINCT FROM (EXCLUDED.status, EXCLUDED.starts_at, EXCLUDED.ends_at)
+RETURNING ` + availabilityColumns
+
+		row = tx.QueryRowContext(ctx, query, input.SessionID, input.MemberID, input.Status, timePtrValue(input.StartsAt), timePtrValue(input.EndsAt), now)
+	}
+
+	record, err := scanAvailability(row)
+	if errors.Is(err, sql.ErrNoRows) && hasExisting && !keepExisting {
+		// The synced slot is unchanged, so the row was left alone to keep its
+		// sequence and feed stamp stable.
+		row = tx.QueryRowContext(ctx, `SELECT `+availabilityColumns+` FROM availability WHERE session_id = $1 AND member_id = $2`, input.SessionID, input.MemberID)
+		record, err = scanAvailability(row)
+	}
+	if err != nil {
+		return Availability{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Availability{}, err
+	}
+
+	record.AvailableBlocks = AvailableBlocks(capacity.Session, capacity.BlockLength, record.Status, record.StartsAt, record.EndsAt)
+	return record, nil
+}
+
+// RemoveAvailability withdraws a member's availability for a session. A
+// tombstone is kept so member calendar feeds publish the session as
+// cancelled. Members must release their commitments in the session first.
+func RemoveAvailability(ctx context.Context, db *sql.DB, sessionID, memberID uuid.UUID) error {
+	if db == nil {
+		return errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	capacity, err := loadSessionCapacity(ctx, tx, sessionID, true)
+	if err != nil {
+		return err
+	}
+
+	if err := lockMember(ctx, tx, memberID, capacity.Session.ChapterID); err != nil {
+		return err
+	}
+
+	var committed int
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(SUM(blocks), 0) FROM commitments WHERE session_id = $1 AND member_id = $2`, sessionID, memberID).Scan(&committed); err != nil {
+		return err
+	}
+
+	if committed > 0 {
+		member := memberID
+		return &CapacityError{
+			Cap:       CapMemberBlocks,
+			Limit:     0,
+			Current:   committed,
+			SessionID: sessionID,
+			ChapterID: capacity.Session.ChapterID,
+			MemberID:  &member,
+		}
+	}
+
+	var (
+		startsAt sql.NullTime
+		endsAt   sql.NullTime
+		sequence int
+	)
+	err = tx.QueryRowContext(ctx, `DELETE FROM availability WHERE session_id = $1 AND member_id = $2 RETURNING starts_at, ends_at, sequence`, sessionID, memberID).Scan(&startsAt, &endsAt, &sequence)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAvailabilityNotFound
+		}
+		return err
+	}
+
+	// The tombstone must outrank whatever the feed last published for the
+	// session, including any earlier tombstone it replaces.
+	const query = `
+INSERT INTO availability_removals (session_id, member_id, starts_at, ends_at, sequence, removed_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (session_id, member_id) DO UPDATE
+SET starts_at = EXCLUDED.starts_at,
+    ends_at = EXCLUDED.ends_at,
+    sequence = availability_removals.sequence + 1 + EXCLUDED.sequence,
+    removed_at = EXCLUDED.removed_at
+`
+
+	if _, err := tx.ExecContext(ctx, query, sessionID, memberID, startsAt, endsAt, sequence+1, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListAvailability returns the declared availability of every member for a
+// session, including the number of capacity blocks each declaration yields.
+func ListAvailability(ctx context.Context, db *sql.DB, sessionID uuid.UUID) ([]Availability, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	capacity, err := loadSessionCapacity(ctx, db, sessionID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+SELECT ` + availabilityColumns + `
+FROM availability
+WHERE session_id = $1
+ORDER BY updated_at
+`
+
+	rows, err := db.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make([]Availability, 0)
+	for rows.Next() {
+		record, err := scanAvailability(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		record.AvailableBlocks = AvailableBlocks(capacity.Session, capacity.BlockLength, record.Status, record.StartsAt, record.EndsAt)
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAvailability(row rowScanner) (Availability, error) {
+	var (
+		record         Availability
+		startsAt       sql.NullTime
+		endsAt         sql.NullTime
+		syncedStatus   sql.NullString
+		syncedStartsAt sql.NullTime
+		syncedEndsAt   sql.NullTime
+		syncedAt       sql.NullTime
+	)
+
+	if err := row.Scan(
+		&record.SessionID,
+		&record.MemberID,
+		&record.Status,
+		&startsAt,
+		&endsAt,
+		&record.Source,
+		&record.SyncConflict,
+		&syncedStatus,
+		&syncedStartsAt,
+		&syncedEndsAt,
+		&syncedAt,
+		&record.UpdatedAt,
+	); err != nil {
+		return Availability{}, err
+	}
+
+	record.StartsAt = nullTimePtr(startsAt)
+	record.EndsAt = nullTimePtr(endsAt)
+
+	if syncedStatus.Valid {
+		record.Synced = &SyncedAvailability{
+			Status:   syncedStatus.String,
+			StartsAt: nullTimePtr(syncedStartsAt),
+			EndsAt:   nullTimePtr(syncedEndsAt),
+			SyncedAt: syncedAt.Time,
+		}
+	}
+
+	return record, nil
+}
+
+func sameAvailability(a, b AvailabilityInput) bool {
+	return a.Status == b.Status && sameTimePtr(a.StartsAt, b.StartsAt) && sameTimePtr(a.EndsAt, b.EndsAt)
+}
+
+func sameTimePtr(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
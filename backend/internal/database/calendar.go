@@ -0,0 +1,205 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Calendar entry kinds.
+const (
+	CalendarEntrySession = "session"
+	CalendarEntrySwarm   = "swarm"
+)
+
+// CalendarEntry is a session or swarm rendered into a calendar feed. Status
+// carries the member's availability, or AvailabilityRemoved once withdrawn,
+// for member session entries, the swarm status for swarm entries, and is
+// empty for chapter session entries.
+type CalendarEntry struct {
+	Kind      string
+	ID        uuid.UUID
+	SessionID uuid.UUID
+	Title     string
+	Details   string
+	Status    string
+	StartsAt  time.Time
+	EndsAt    time.Time
+	Sequence  int
+	UpdatedAt time.Time
+}
+
+// IssueCalendarToken generates a new calendar feed token for a member,
+// replacing any previous token. Only a hash of the token is stored, so the
+// returned value cannot be retrieved again.
+func IssueCalendarToken(ctx context.Context, db *sql.DB, memberID uuid.UUID) (string, error) {
+	if db == nil {
+		return "", errors.New("database handle is nil")
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	result, err := db.ExecContext(ctx, `UPDATE members SET calendar_token_hash = $1 WHERE id = $2`, hashCalendarToken(token), memberID)
+	if err != nil {
+		return "", err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return "", err
+	}
+
+	if affected == 0 {
+		return "", sql.ErrNoRows
+	}
+
+	return token, nil
+}
+
+// MemberCalendarTokenValid reports whether token is the member's current
+// calendar feed token.
+func MemberCalendarTokenValid(ctx context.Context, db *sql.DB, memberID uuid.UUID, token string) (bool, error) {
+	if db == nil {
+		return false, errors.New("database handle is nil")
+	}
+
+	if token == "" {
+		return false, nil
+	}
+
+	var valid bool
+	err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM members WHERE id = $1 AND calendar_token_hash = $2)`, memberID, hashCalendarToken(token)).Scan(&valid)
+	return valid, err
+}
+
+// ChapterCalendarTokenValid reports whether token belongs to a member of the
+// chapter.
+func ChapterCalendarTokenValid(ctx context.Context, db *sql.DB, chapterID uuid.UUID, token string) (bool, error) {
+	if db == nil {
+		return false, errors.New("database handle is nil")
+	}
+
+	if token == "" {
+		return false, nil
+	}
+
+	var valid bool
+	err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM members WHERE chapter_id = $1 AND calendar_token_hash = $2)`, chapterID, hashCalendarToken(token)).Scan(&valid)
+	return valid, err
+}
+
+// ListMemberCalendarEntries returns the sessions a member has declared or
+// withdrawn availability for and the swarms they joined, ending at or after
+// since. Withdrawn sessions carry the AvailabilityRemoved status.
+func ListMemberCalendarEntries(ctx context.Context, db *sql.DB, memberID uuid.UUID, since time.Time) ([]CalendarEntry, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	// Withdrawn availability is published from its tombstone. A declaration
+	// made after a withdrawal counts past the tombstone's sequence so it
+	// replaces the cancellation again.
+	const sessionsQuery = `
+SELECT s.id, s.id, c.name, '', a.status, COALESCE(a.starts_at, s.starts_at), COALESCE(a.ends_at, s.ends_at), s.sequence + a.sequence + COALESCE(r.sequence + 1, 0), a.updated_at
+FROM availability a
+JOIN sessions s ON s.id = a.session_id
+JOIN chapters c ON c.id = s.chapter_id
+LEFT JOIN availability_removals r ON r.session_id = a.session_id AND r.member_id = a.member_id
+WHERE a.member_id = $1 AND s.ends_at >= $2
+UNION ALL
+SELECT s.id, s.id, c.name, '', 'removed', COALESCE(r.starts_at, s.starts_at), COALESCE(r.ends_at, s.ends_at), s.sequence + r.sequence, r.removed_at
+FROM availability_removals r
+JOIN sessions s ON s.id = r.session_id
+JOIN chapters c ON c.id = s.chapter_id
+WHERE r.member_id = $1 AND s.ends_at >= $2
+  AND NOT EXISTS (SELECT 1 FROM availability a WHERE a.session_id = r.session_id AND a.member_id = r.member_id)
+`
+
+	const swarmsQuery = `
+SELECT w.id, w.session_id, w.name, w.mission, w.status, w.starts_at, w.ends_at, w.sequence, w.updated_at
+FROM swarms w
+JOIN swarm_members sm ON sm.swarm_id = w.id
+WHERE sm.member_id = $1 AND w.ends_at >= $2
+`
+
+	return listCalendarEntries(ctx, db, sessionsQuery, swarmsQuery, memberID, since)
+}
+
+// ListChapterCalendarEntries returns every session and swarm of a chapter
+// ending at or after since.
+func ListChapterCalendarEntries(ctx context.Context, db *sql.DB, chapterID uuid.UUID, since time.Time) ([]CalendarEntry, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	const sessionsQuery = `
+SELECT s.id, s.id, c.name, '', '', s.starts_at, s.ends_at, s.sequence, s.updated_at
+FROM sessions s
+JOIN chapters c ON c.id = s.chapter_id
+WHERE s.chapter_id = $1 AND s.ends_at >= $2
+`
+
+	const swarmsQuery = `
+SELECT w.id, w.session_id, w.name, w.mission, w.status, w.starts_at, w.ends_at, w.sequence, w.updated_at
+FROM swarms w
+JOIN sessions s ON s.id = w.session_id
+WHERE s.chapter_id = $1 AND w.ends_at >= $2
+`
+
+	return listCalendarEntries(ctx, db, sessionsQuery, swarmsQuery, chapterID, since)
+}
+
+func listCalendarEntries(ctx context.Context, db *sql.DB, sessionsQuery, swarmsQuery string, id uuid.UUID, since time.Time) ([]CalendarEntry, error) {
+	entries := make([]CalendarEntry, 0)
+
+	for _, source := range []struct {
+		kind  string
+		query string
+	}{
+		{kind: CalendarEntrySession, query: sessionsQuery},
+		{kind: CalendarEntrySwarm, query: swarmsQuery},
+	} {
+		rows, err := db.QueryContext(ctx, source.query, id, since.UTC())
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			entry := CalendarEntry{Kind: source.kind}
+			if err := rows.Scan(&entry.ID, &entry.SessionID, &entry.Title, &entry.Details, &entry.Status, &entry.StartsAt, &entry.EndsAt, &entry.Sequence, &entry.UpdatedAt); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			entries = append(entries, entry)
+		}
+
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		rows.Close()
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartsAt.Before(entries[j].StartsAt)
+	})
+
+	return entries, nil
+}
+
+func hashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
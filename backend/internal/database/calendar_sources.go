@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarSource is a member's CalDAV (or plain ICS) URL polled to sync
+// their availability. SealedPassword holds the password sealed with the
+// server's credentials key; it is never returned by the API.
+type CalendarSource struct {
+	MemberID       uuid.UUID
+	URL            string
+	Username       string
+	SealedPassword string
+	LastSyncedAt   *time.Time
+	LastError      string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// CalendarSourceInput captures the fields required to configure a source.
+// SealedPassword must already be sealed by the caller.
+type CalendarSourceInput struct {
+	URL            string
+	Username       string
+	SealedPassword string
+}
+
+const calendarSourceColumns = `member_id, url, username, sealed_password, last_synced_at, last_error, created_at, updated_at`
+
+// UpsertCalendarSource configures the calendar polled for a member, replacing
+// any previous source.
+func UpsertCalendarSource(ctx context.Context, db *sql.DB, memberID uuid.UUID, input CalendarSourceInput) (CalendarSource, error) {
+	if db == nil {
+		return CalendarSource{}, errors.New("database handle is nil")
+	}
+
+	now := time.Now().UTC()
+
+	const query = `
+INSERT INTO calendar_sources (member_id, url, username, sealed_password, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $5)
+ON CONFLICT (member_id) DO UPDATE
+SET url = EXCLUDED.url,
+    username = EXCLUDED.username,
+    sealed_password = EXCLUDED.sealed_password,
+    last_error = '',
+    updated_at = EXCLUDED.updated_at
+RETURNING ` + calendarSourceColumns
+
+	return scanCalendarSource(db.QueryRowContext(ctx, query, memberID, input.URL, input.Username, input.SealedPassword, now))
+}
+
+// GetCalendarSource retrieves the calendar source configured for a member.
+func GetCalendarSource(ctx context.Context, db *sql.DB, memberID uuid.UUID) (CalendarSource, error) {
+	if db == nil {
+		return CalendarSource{}, errors.New("database handle is nil")
+	}
+
+	query := `SELECT ` + calendarSourceColumns + ` FROM calendar_sources WHERE member_id = $1`
+	return scanCalendarSource(db.QueryRowContext(ctx, query, memberID))
+}
+
+// DeleteCalendarSource stops syncing a member's calendar. Previously synced
+// availability is left in place.
+func DeleteCalendarSource(ctx context.Context, db *sql.DB, memberID uuid.UUID) error {
+	if db == nil {
+		return errors.New("database handle is nil")
+	}
+
+	result, err := db.ExecContext(ctx, `DELETE FROM calendar_sources WHERE member_id = $1`, memberID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ListCalendarSources returns every configured source, least recently synced
+// first.
+func ListCalendarSources(ctx context.Context, db *sql.DB) ([]CalendarSource, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	query := `SELECT ` + calendarSourceColumns + ` FROM calendar_sources ORDER BY last_synced_at NULLS FIRST`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sources := make([]CalendarSource, 0)
+	for rows.Next() {
+		source, err := scanCalendarSource(rows)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sources, nil
+}
+
+// RecordCalendarSourceSync stores the outcome of a sync attempt. An empty
+// syncErr marks the attempt as successful.
+func RecordCalendarSourceSync(ctx context.Context, db *sql.DB, memberID uuid.UUID, syncedAt time.Time, syncErr string) error {
+	if db == nil {
+		return errors.New("database handle is nil")
+	}
+
+	_, err := db.ExecContext(ctx, `UPDATE calendar_sources SET last_synced_at = $1, last_error = $2 WHERE member_id = $3`, syncedAt.UTC(), syncErr, memberID)
+	return err
+}
+
+func scanCalendarSource(row rowScanner) (CalendarSource, error) {
+	var (
+		source       CalendarSource
+		lastSyncedAt sql.NullTime
+	)
+
+	if err := row.Scan(&source.MemberID, &source.URL, &source.Username, &source.SealedPassword, &lastSyncedAt, &source.LastError, &source.CreatedAt, &source.UpdatedAt); err != nil {
+		return CalendarSource{}, err
+	}
+
+	source.LastSyncedAt = nullTimePtr(lastSyncedAt)
+	return source, nil
+}
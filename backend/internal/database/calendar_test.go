@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestIssueCalendarTokenStoresHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	memberID := uuid.New()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE members SET calendar_token_hash = $1 WHERE id = $2")).
+		WithArgs(sqlmock.AnyArg(), memberID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	token, err := IssueCalendarToken(context.Background(), db, memberID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(token) < 40 {
+		t.Fatalf("expected a long random token got %q", token)
+	}
+
+	if hashCalendarToken(token) == token {
+		t.Fatal("expected token to be hashed before storage")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestIssueCalendarTokenMissingMember(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE members SET calendar_token_hash")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if _, err := IssueCalendarToken(context.Background(), db, uuid.New()); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestListMemberCalendarEntriesOrdersByStart(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	memberID, sessionID, swarmID := uuid.New(), uuid.New(), uuid.New()
+	start := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+	since := start.Add(-24 * time.Hour)
+	columns := []string{"id", "session_id", "title", "details", "status", "starts_at", "ends_at", "sequence", "updated_at"}
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM availability a JOIN sessions s ON s.id = a.session_id")).
+		WithArgs(memberID, since).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(sessionID, sessionID, "Platform", "", AvailabilityAvailable, start, start.Add(4*time.Hour), 1, start))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM swarms w JOIN swarm_members sm ON sm.swarm_id = w.id")).
+		WithArgs(memberID, since).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(swarmID, sessionID, "Checkout", "Fix it", SwarmDissolved, start.Add(-time.Hour), start, 3, start))
+
+	entries, err := ListMemberCalendarEntries(context.Background(), db, memberID, since)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries got %d", len(entries))
+	}
+
+	if entries[0].Kind != CalendarEntrySwarm || entries[0].Sequence != 3 {
+		t.Fatalf("expected earlier swarm first got %+v", entries[0])
+	}
+
+	if entries[1].Kind != CalendarEntrySession || entries[1].Status != AvailabilityAvailable {
+		t.Fatalf("unexpected session entry %+v", entries[1])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestListChapterCalendarEntriesStampsSessionsWithUpdatedAt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	chapterID, sessionID := uuid.New(), uuid.New()
+	start := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+	edited := start.Add(-2 * time.Hour)
+	columns := []string{"id", "session_id", "title", "details", "status", "starts_at", "ends_at", "sequence", "updated_at"}
+
+	mock.ExpectQuery(regexp.QuoteMeta("s.sequence, s.updated_at FROM sessions s JOIN chapters c ON c.id = s.chapter_id")).
+		WithArgs(chapterID, start).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(sessionID, sessionID, "Platform", "", "", start, start.Add(4*time.Hour), 0, edited))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM swarms w JOIN sessions s ON s.id = w.session_id")).
+		WithArgs(chapterID, start).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	entries, err := ListChapterCalendarEntries(context.Background(), db, chapterID, start)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(entries) != 1 || !entries[0].UpdatedAt.Equal(edited) {
+		t.Fatalf("expected session stamped %s got %+v", edited, entries)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
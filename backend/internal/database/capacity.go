@@ -0,0 +1,413 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/example/intent/backend/internal/schedule"
+)
+
+// Capacity guardrails enforced when members commit to work.
+const (
+	CapMemberBlocks            = "member_blocks"
+	CapChapterConcurrentSwarms = "chapter_concurrent_swarms"
+)
+
+var (
+	// ErrMemberNotFound is returned when a referenced member does not exist.
+	ErrMemberNotFound = errors.New("member not found")
+	// ErrIntentNotFound is returned when a referenced intent does not exist.
+	ErrIntentNotFound = errors.New("intent not found")
+)
+
+// CapacityError reports which capacity guardrail a write would violate.
+type CapacityError struct {
+	Cap       string
+	Limit     int
+	Current   int
+	Requested int
+	SessionID uuid.UUID
+	ChapterID uuid.UUID
+	MemberID  *uuid.UUID
+}
+
+func (e *CapacityError) Error() string {
+	switch e.Cap {
+	case CapMemberBlocks:
+		return fmt.Sprintf("member has %d available blocks with %d already committed; %d more requested", e.Limit, e.Current, e.Requested)
+	case CapChapterConcurrentSwarms:
+		return fmt.Sprintf("chapter allows %d concurrent swarms per session and %d are already active", e.Limit, e.Current)
+	default:
+		return fmt.Sprintf("capacity %s exceeded", e.Cap)
+	}
+}
+
+// Commitment books a member's blocks in a session against an intent or a
+// swarm. Exactly one of IntentID and SwarmID is set.
+type Commitment struct {
+	ID        uuid.UUID
+	SessionID uuid.UUID
+	MemberID  uuid.UUID
+	IntentID  *uuid.UUID
+	SwarmID   *uuid.UUID
+	Blocks    int
+	CreatedAt time.Time
+}
+
+// CommitmentInput captures a member committing blocks to an intent.
+type CommitmentInput struct {
+	SessionID uuid.UUID
+	MemberID  uuid.UUID
+	IntentID  uuid.UUID
+	Blocks    int
+}
+
+// sessionCapacity bundles a session with the capacity settings of its chapter.
+type sessionCapacity struct {
+	Session             Session
+	MaxConcurrentSwarms int
+	BlockLength         time.Duration
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// loadSessionCapacity reads a session with its chapter's capacity settings.
+// With forWrite set the session row is share-locked, so that a concurrent
+// close-out waits for the write, and ErrSessionClosed is returned for
+// sessions that have already been closed.
+func loadSessionCapacity(ctx context.Context, q queryer, sessionID uuid.UUID, forWrite bool) (sessionCapacity, error) {
+	query := `
+SELECT s.id, s.chapter_id, s.starts_at, s.ends_at, s.state, s.created_at, c.max_concurrent_swarms, c.block_minutes
+FROM sessions s
+JOIN chapters c ON c.id = s.chapter_id
+WHERE s.id = $1
+`
+	if forWrite {
+		query += "FOR SHARE OF s\n"
+	}
+
+	var (
+		capacity     sessionCapacity
+		blockMinutes int
+	)
+
+	err := q.QueryRowContext(ctx, query, sessionID).Scan(
+		&capacity.Session.ID,
+		&capacity.Session.ChapterID,
+		&capacity.Session.StartsAt,
+		&capacity.Session.EndsAt,
+		&capacity.Session.State,
+		&capacity.Session.CreatedAt,
+		&capacity.MaxConcurrentSwarms,
+		&blockMinutes,
+	)
+	if err != nil {
+		return sessionCapacity{}, err
+	}
+
+	if forWrite && capacity.Session.State == SessionClosed {
+		return sessionCapacity{}, ErrSessionClosed
+	}
+
+	capacity.BlockLength = time.Duration(blockMinutes) * time.Minute
+	return capacity, nil
+}
+
+// AvailableBlocks returns how many capacity blocks a member has in a session
+// given their declared availability.
+func AvailableBlocks(session Session, blockLength time.Duration, status string, startsAt, endsAt *time.Time) int {
+	switch status {
+	case AvailabilityAvailable:
+		return schedule.Blocks(session.StartsAt, session.EndsAt, blockLength)
+	case AvailabilityPartial:
+		if startsAt == nil || endsAt == nil {
+			return 0
+		}
+		start, end := *startsAt, *endsAt
+		if start.Before(session.StartsAt) {
+			start = session.StartsAt
+		}
+		if end.After(session.EndsAt) {
+			end = session.EndsAt
+		}
+		return schedule.Blocks(start, end, blockLength)
+	default:
+		return 0
+	}
+}
+
+// lockMember takes a row lock on the member so that concurrent commitments
+// for the same person are serialised, and verifies chapter membership.
+func lockMember(ctx context.Context, tx *sql.Tx, memberID, chapterID uuid.UUID) error {
+	var memberChapter uuid.UUID
+	err := tx.QueryRowContext(ctx, `SELECT chapter_id FROM members WHERE id = $1 FOR UPDATE`, memberID).Scan(&memberChapter)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrMemberNotFound
+		}
+		return err
+	}
+
+	if memberChapter != chapterID {
+		return ErrMemberNotInChapter
+	}
+
+	return nil
+}
+
+// memberBlocks returns the member's available and already committed blocks
+// for the session.
+func memberBlocks(ctx context.Context, tx *sql.Tx, capacity sessionCapacity, memberID uuid.UUID) (int, int, error) {
+	var (
+		status   string
+		startsAt sql.NullTime
+		endsAt   sql.NullTime
+	)
+
+	err := tx.QueryRowContext(ctx, `SELECT status, starts_at, ends_at FROM availability WHERE session_id = $1 AND member_id = $2`, capacity.Session.ID, memberID).Scan(&status, &startsAt, &endsAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, 0, err
+	}
+
+	available := AvailableBlocks(capacity.Session, capacity.BlockLength, status, nullTimePtr(startsAt), nullTimePtr(endsAt))
+
+	var committed int
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(SUM(blocks), 0) FROM commitments WHERE session_id = $1 AND member_id = $2`, capacity.Session.ID, memberID).Scan(&committed); err != nil {
+		return 0, 0, err
+	}
+
+	return available, committed, nil
+}
+
+// commitMember books blocks for a member after checking their capacity. The
+// member row must already be locked via lockMember.
+func commitMember(ctx context.Context, tx *sql.Tx, capacity sessionCapacity, memberID uuid.UUID, intentID, swarmID *uuid.UUID, blocks int) (Commitment, error) {
+	available, committed, err := memberBlocks(ctx, tx, capacity, memberID)
+	if err != nil {
+		return Commitment{}, err
+	}
+
+	if committed+blocks > available {
+		member := memberID
+		return Commitment{}, &CapacityError{
+			Cap:       CapMemberBlocks,
+			Limit:     available,
+			Current:   committed,
+			Requested: blocks,
+			SessionID: capacity.Session.ID,
+			ChapterID: capacity.Session.ChapterID,
+			MemberID:  &member,
+		}
+	}
+
+	commitment := Commitment{
+		ID:        uuid.New(),
+		SessionID: capacity.Session.ID,
+		MemberID:  memberID,
+		IntentID:  intentID,
+		SwarmID:   swarmID,
+		Blocks:    blocks,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	const query = `
+INSERT INTO commitments (id, session_id, member_id, intent_id, swarm_id, blocks, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+	if _, err := tx.ExecContext(ctx, query, commitment.ID, commitment.SessionID, commitment.MemberID, uuidPtrValue(intentID), uuidPtrValue(swarmID), commitment.Blocks, commitment.CreatedAt); err != nil {
+		return Commitment{}, err
+	}
+
+	return commitment, nil
+}
+
+// CreateCommitment books a member's blocks in a session against an intent,
+// returning a *CapacityError when the member would exceed their available
+// blocks.
+func CreateCommitment(ctx context.Context, db *sql.DB, input CommitmentInput) (Commitment, error) {
+	if db == nil {
+		return Commitment{}, errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Commitment{}, err
+	}
+	defer tx.Rollback()
+
+	capacity, err := loadSessionCapacity(ctx, tx, input.SessionID, true)
+	if err != nil {
+		return Commitment{}, err
+	}
+
+	if err := lockMember(ctx, tx, input.MemberID, capacity.Session.ChapterID); err != nil {
+		return Commitment{}, err
+	}
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM intents WHERE id = $1)`, input.IntentID).Scan(&exists); err != nil {
+		return Commitment{}, err
+	}
+	if !exists {
+		return Commitment{}, ErrIntentNotFound
+	}
+
+	intentID := input.IntentID
+	commitment, err := commitMember(ctx, tx, capacity, input.MemberID, &intentID, nil, input.Blocks)
+	if err != nil {
+		return Commitment{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Commitment{}, err
+	}
+
+	return commitment, nil
+}
+
+// DeleteCommitment releases a commitment within a session.
+func DeleteCommitment(ctx context.Context, db *sql.DB, sessionID, id uuid.UUID) error {
+	if db == nil {
+		return errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockOpenSession(ctx, tx, sessionID); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM commitments WHERE id = $1 AND session_id = $2`, id, sessionID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return tx.Commit()
+}
+
+// ListCommitments returns every commitment booked in a session.
+func ListCommitments(ctx context.Context, db *sql.DB, sessionID uuid.UUID) ([]Commitment, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	const query = `
+SELECT id, session_id, member_id, intent_id, swarm_id, blocks, created_at
+FROM commitments
+WHERE session_id = $1
+ORDER BY created_at
+`
+
+	rows, err := db.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	commitments := make([]Commitment, 0)
+	for rows.Next() {
+		var (
+			commitment Commitment
+			intentID   uuid.NullUUID
+			swarmID    uuid.NullUUID
+		)
+
+		if err := rows.Scan(&commitment.ID, &commitment.SessionID, &commitment.MemberID, &intentID, &swarmID, &commitment.Blocks, &commitment.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		commitment.IntentID = nullUUIDPtr(intentID)
+		commitment.SwarmID = nullUUIDPtr(swarmID)
+		commitments = append(commitments, commitment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return commitments, nil
+}
+
+func nullTimePtr(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	t := value.Time
+	return &t
+}
+
+func nullUUIDPtr(value uuid.NullUUID) *uuid.UUID {
+	if !value.Valid {
+		return nil
+	}
+	id := value.UUID
+	return &id
+}
+
+func uuidPtrValue(value *uuid.UUID) any {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+func intPtrValue(value *int) any {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+func timePtrValue(value *time.Time) any {
+	if value == nil {
+		return nil
+	}
+	return value.UTC()
+}
+
+// uuidArrayLiteral renders ids as a Postgres array literal suitable for a
+// $n::uuid[] parameter.
+func uuidArrayLiteral(ids []uuid.UUID) string {
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, id.String())
+	}
+	return "{" + strings.Join(values, ",") + "}"
+}
+
+// textArrayLiteral renders values as a Postgres array literal suitable for a
+// $n::text[] parameter, quoting each element.
+func textArrayLiteral(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.ReplaceAll(value, `\`, `\\`)
+		value = strings.ReplaceAll(value, `"`, `\"`)
+		quoted = append(quoted, `"`+value+`"`)
+	}
+	return "{" + strings.Join(quoted, ",") + "}"
+}
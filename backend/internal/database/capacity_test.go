@@ -0,0 +1,422 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func expectSessionCapacity(mock sqlmock.Sqlmock, sessionID, chapterID uuid.UUID, startsAt time.Time, maxSwarms int) {
+	rows := sqlmock.NewRows([]string{"id", "chapter_id", "starts_at", "ends_at", "state", "created_at", "max_concurrent_swarms", "block_minutes"}).
+		AddRow(sessionID, chapterID, startsAt, startsAt.Add(4*time.Hour), "scheduled", startsAt.Add(-24*time.Hour), maxSwarms, 60)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM sessions s JOIN chapters c ON c.id = s.chapter_id WHERE s.id = $1")).
+		WithArgs(sessionID).
+		WillReturnRows(rows)
+}
+
+func expectMemberLock(mock sqlmock.Sqlmock, memberID, chapterID uuid.UUID) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT chapter_id FROM members WHERE id = $1 FOR UPDATE")).
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"chapter_id"}).AddRow(chapterID))
+}
+
+func expectMemberBlocks(mock sqlmock.Sqlmock, sessionID, memberID uuid.UUID, status string, committed int) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status, starts_at, ends_at FROM availability WHERE session_id = $1 AND member_id = $2")).
+		WithArgs(sessionID, memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "starts_at", "ends_at"}).AddRow(status, nil, nil))
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(blocks), 0) FROM commitments WHERE session_id = $1 AND member_id = $2")).
+		WithArgs(sessionID, memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(committed))
+}
+
+func TestCreateCommitmentSuccess(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, memberID, intentID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	startsAt := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	expectSessionCapacity(mock, sessionID, chapterID, startsAt, 3)
+	expectMemberLock(mock, memberID, chapterID)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM intents WHERE id = $1)")).
+		WithArgs(intentID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	expectMemberBlocks(mock, sessionID, memberID, AvailabilityAvailable, 2)
+	mock.ExpectExec("INSERT INTO commitments").
+		WithArgs(sqlmock.AnyArg(), sessionID, memberID, intentID, nil, 2, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	commitment, err := CreateCommitment(context.Background(), db, CommitmentInput{
+		SessionID: sessionID,
+		MemberID:  memberID,
+		IntentID:  intentID,
+		Blocks:    2,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if commitment.IntentID == nil || *commitment.IntentID != intentID {
+		t.Fatalf("expected intent %s got %v", intentID, commitment.IntentID)
+	}
+
+	if commitment.SwarmID != nil {
+		t.Fatalf("expected no swarm id got %v", commitment.SwarmID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestCreateCommitmentExceedsMemberBlocks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, memberID, intentID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	startsAt := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	expectSessionCapacity(mock, sessionID, chapterID, startsAt, 3)
+	expectMemberLock(mock, memberID, chapterID)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM intents WHERE id = $1)")).
+		WithArgs(intentID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	expectMemberBlocks(mock, sessionID, memberID, AvailabilityAvailable, 3)
+	mock.ExpectRollback()
+
+	_, err = CreateCommitment(context.Background(), db, CommitmentInput{
+		SessionID: sessionID,
+		MemberID:  memberID,
+		IntentID:  intentID,
+		Blocks:    2,
+	})
+
+	var capErr *CapacityError
+	if !errors.As(err, &capErr) {
+		t.Fatalf("expected capacity error got %v", err)
+	}
+
+	if capErr.Cap != CapMemberBlocks || capErr.Limit != 4 || capErr.Current != 3 || capErr.Requested != 2 {
+		t.Fatalf("unexpected capacity error %+v", capErr)
+	}
+
+	if capErr.MemberID == nil || *capErr.MemberID != memberID {
+		t.Fatalf("expected member %s got %v", memberID, capErr.MemberID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestCreateCommitmentMemberOutsideChapter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, memberID := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	expectSessionCapacity(mock, sessionID, chapterID, time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC), 3)
+	expectMemberLock(mock, memberID, uuid.New())
+	mock.ExpectRollback()
+
+	_, err = CreateCommitment(context.Background(), db, CommitmentInput{
+		SessionID: sessionID,
+		MemberID:  memberID,
+		IntentID:  uuid.New(),
+		Blocks:    1,
+	})
+	if !errors.Is(err, ErrMemberNotInChapter) {
+		t.Fatalf("expected ErrMemberNotInChapter got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestAvailableBlocksClampsPartialToSession(t *testing.T) {
+	start := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+	session := Session{StartsAt: start, EndsAt: start.Add(4 * time.Hour)}
+
+	partialStart := start.Add(-time.Hour)
+	partialEnd := start.Add(150 * time.Minute)
+
+	if got := AvailableBlocks(session, time.Hour, AvailabilityPartial, &partialStart, &partialEnd); got != 2 {
+		t.Fatalf("expected 2 blocks got %d", got)
+	}
+
+	if got := AvailableBlocks(session, time.Hour, AvailabilityAvailable, nil, nil); got != 4 {
+		t.Fatalf("expected 4 blocks got %d", got)
+	}
+
+	if got := AvailableBlocks(session, time.Hour, AvailabilityUnavailable, nil, nil); got != 0 {
+		t.Fatalf("expected 0 blocks got %d", got)
+	}
+}
+
+func TestUpsertAvailabilityBelowCommitted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, memberID := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	expectSessionCapacity(mock, sessionID, chapterID, time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC), 3)
+	expectMemberLock(mock, memberID, chapterID)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(blocks), 0) FROM commitments WHERE session_id = $1 AND member_id = $2")).
+		WithArgs(sessionID, memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(2))
+	mock.ExpectRollback()
+
+	_, err = UpsertAvailability(context.Background(), db, AvailabilityInput{
+		SessionID: sessionID,
+		MemberID:  memberID,
+		Status:    AvailabilityUnavailable,
+	})
+
+	var capErr *CapacityError
+	if !errors.As(err, &capErr) {
+		t.Fatalf("expected capacity error got %v", err)
+	}
+
+	if capErr.Limit != 0 || capErr.Current != 2 {
+		t.Fatalf("unexpected capacity error %+v", capErr)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestRemoveAvailabilityLeavesTombstone(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, memberID := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	expectSessionCapacity(mock, sessionID, chapterID, time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC), 3)
+	expectMemberLock(mock, memberID, chapterID)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(blocks), 0) FROM commitments WHERE session_id = $1 AND member_id = $2")).
+		WithArgs(sessionID, memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta("DELETE FROM availability WHERE session_id = $1 AND member_id = $2 RETURNING starts_at, ends_at, sequence")).
+		WithArgs(sessionID, memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"starts_at", "ends_at", "sequence"}).AddRow(nil, nil, 2))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO availability_removals (session_id, member_id, starts_at, ends_at, sequence, removed_at)")).
+		WithArgs(sessionID, memberID, nil, nil, 3, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := RemoveAvailability(context.Background(), db, sessionID, memberID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestRemoveAvailabilityWithCommitments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, memberID := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	expectSessionCapacity(mock, sessionID, chapterID, time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC), 3)
+	expectMemberLock(mock, memberID, chapterID)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(blocks), 0) FROM commitments WHERE session_id = $1 AND member_id = $2")).
+		WithArgs(sessionID, memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(1))
+	mock.ExpectRollback()
+
+	err = RemoveAvailability(context.Background(), db, sessionID, memberID)
+
+	var capErr *CapacityError
+	if !errors.As(err, &capErr) || capErr.Current != 1 {
+		t.Fatalf("expected capacity error got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestApplySyncedAvailabilityKeepsManualValue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, memberID := uuid.New(), uuid.New(), uuid.New()
+	start := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	expectSessionCapacity(mock, sessionID, chapterID, start, 3)
+	expectMemberLock(mock, memberID, chapterID)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status, starts_at, ends_at, source FROM availability WHERE session_id = $1 AND member_id = $2 FOR UPDATE")).
+		WithArgs(sessionID, memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "starts_at", "ends_at", "source"}).AddRow(AvailabilityAvailable, nil, nil, AvailabilitySourceManual))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(blocks), 0) FROM commitments")).
+		WithArgs(sessionID, memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE availability SET synced_status = $3")).
+		WithArgs(sessionID, memberID, AvailabilityUnavailable, nil, nil, sqlmock.AnyArg(), true).
+		WillReturnRows(sqlmock.NewRows([]string{"session_id", "member_id", "status", "starts_at", "ends_at", "source", "sync_conflict", "synced_status", "synced_starts_at", "synced_ends_at", "synced_at", "updated_at"}).
+			AddRow(sessionID, memberID, AvailabilityAvailable, nil, nil, AvailabilitySourceManual, true, AvailabilityUnavailable, nil, nil, start, start))
+	mock.ExpectCommit()
+
+	record, err := ApplySyncedAvailability(context.Background(), db, AvailabilityInput{
+		SessionID: sessionID,
+		MemberID:  memberID,
+		Status:    AvailabilityUnavailable,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if record.Status != AvailabilityAvailable || !record.SyncConflict {
+		t.Fatalf("expected manual value kept with conflict flagged got %+v", record)
+	}
+
+	if record.Synced == nil || record.Synced.Status != AvailabilityUnavailable {
+		t.Fatalf("expected synced value to be recorded got %+v", record.Synced)
+	}
+
+	if record.AvailableBlocks != 4 {
+		t.Fatalf("expected blocks from manual value got %d", record.AvailableBlocks)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestApplySyncedAvailabilityReplacesSyncedValue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, memberID := uuid.New(), uuid.New(), uuid.New()
+	start := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+	partialStart, partialEnd := start.Add(time.Hour), start.Add(3*time.Hour)
+
+	mock.ExpectBegin()
+	expectSessionCapacity(mock, sessionID, chapterID, start, 3)
+	expectMemberLock(mock, memberID, chapterID)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM availability WHERE session_id = $1 AND member_id = $2 FOR UPDATE")).
+		WithArgs(sessionID, memberID).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(blocks), 0) FROM commitments")).
+		WithArgs(sessionID, memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO availability")).
+		WithArgs(sessionID, memberID, AvailabilityPartial, partialStart, partialEnd, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"session_id", "member_id", "status", "starts_at", "ends_at", "source", "sync_conflict", "synced_status", "synced_starts_at", "synced_ends_at", "synced_at", "updated_at"}).
+			AddRow(sessionID, memberID, AvailabilityPartial, partialStart, partialEnd, AvailabilitySourceSync, false, AvailabilityPartial, partialStart, partialEnd, start, start))
+	mock.ExpectCommit()
+
+	record, err := ApplySyncedAvailability(context.Background(), db, AvailabilityInput{
+		SessionID: sessionID,
+		MemberID:  memberID,
+		Status:    AvailabilityPartial,
+		StartsAt:  &partialStart,
+		EndsAt:    &partialEnd,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if record.Source != AvailabilitySourceSync || record.SyncConflict {
+		t.Fatalf("unexpected record %+v", record)
+	}
+
+	if record.AvailableBlocks != 2 {
+		t.Fatalf("expected 2 blocks got %d", record.AvailableBlocks)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestApplySyncedAvailabilityLeavesUnchangedSlotAlone(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, memberID := uuid.New(), uuid.New(), uuid.New()
+	start := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+	columns := []string{"session_id", "member_id", "status", "starts_at", "ends_at", "source", "sync_conflict", "synced_status", "synced_starts_at", "synced_ends_at", "synced_at", "updated_at"}
+
+	mock.ExpectBegin()
+	expectSessionCapacity(mock, sessionID, chapterID, start, 3)
+	expectMemberLock(mock, memberID, chapterID)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM availability WHERE session_id = $1 AND member_id = $2 FOR UPDATE")).
+		WithArgs(sessionID, memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "starts_at", "ends_at", "source"}).AddRow(AvailabilityUnavailable, nil, nil, AvailabilitySourceSync))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(blocks), 0) FROM commitments")).
+		WithArgs(sessionID, memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta("IS DISTINCT FROM (EXCLUDED.status, EXCLUDED.starts_at, EXCLUDED.ends_at)")).
+		WithArgs(sessionID, memberID, AvailabilityUnavailable, nil, nil, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(columns))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM availability WHERE session_id = $1 AND member_id = $2")).
+		WithArgs(sessionID, memberID).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(sessionID, memberID, AvailabilityUnavailable, nil, nil, AvailabilitySourceSync, false, AvailabilityUnavailable, nil, nil, start, start))
+	mock.ExpectCommit()
+
+	record, err := ApplySyncedAvailability(context.Background(), db, AvailabilityInput{
+		SessionID: sessionID,
+		MemberID:  memberID,
+		Status:    AvailabilityUnavailable,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !record.UpdatedAt.Equal(start) || record.AvailableBlocks != 0 {
+		t.Fatalf("expected untouched record got %+v", record)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Chapter represents a chapter instance whose members share session windows.
+type Chapter struct {
+	ID                  uuid.UUID
+	Name                string
+	Timezone            string
+	MaxConcurrentSwarms int
+	BlockMinutes        int
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+// ChapterInput captures the configurable fields of a chapter instance.
+type ChapterInput struct {
+	Name                string
+	Timezone            string
+	MaxConcurrentSwarms int
+	BlockMinutes        int
+}
+
+// BlockLength returns the duration of a single capacity block.
+func (c Chapter) BlockLength() time.Duration {
+	return time.Duration(c.BlockMinutes) * time.Minute
+}
+
+// CreateChapter persists a new chapter instance.
+func CreateChapter(ctx context.Context, db *sql.DB, input ChapterInput) (Chapter, error) {
+	if db == nil {
+		return Chapter{}, errors.New("database handle is nil")
+	}
+
+	now := time.Now().UTC()
+	id := uuid.New()
+
+	const query = `
+INSERT INTO chapters (id, name, timezone, max_concurrent_swarms, block_minutes, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+	if _, err := db.ExecContext(ctx, query, id, input.Name, input.Timezone, input.MaxConcurrentSwarms, input.BlockMinutes, now, now); err != nil {
+		return Chapter{}, err
+	}
+
+	return Chapter{
+		ID:                  id,
+		Name:                input.Name,
+		Timezone:            input.Timezone,
+		MaxConcurrentSwarms: input.MaxConcurrentSwarms,
+		BlockMinutes:        input.BlockMinutes,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}, nil
+}
+
+// GetChapter retrieves a chapter instance by identifier.
+func GetChapter(ctx context.Context, db *sql.DB, id uuid.UUID) (Chapter, error) {
+	if db == nil {
+		return Chapter{}, errors.New("database handle is nil")
+	}
+
+	const query = `
+SELECT id, name, timezone, max_concurrent_swarms, block_minutes, created_at, updated_at
+FROM chapters
+WHERE id = $1
+`
+
+	var chapter Chapter
+	err := db.QueryRowContext(ctx, query, id).Scan(
+		&chapter.ID,
+		&chapter.Name,
+		&chapter.Timezone,
+		&chapter.MaxConcurrentSwarms,
+		&chapter.BlockMinutes,
+		&chapter.CreatedAt,
+		&chapter.UpdatedAt,
+	)
+	if err != nil {
+		return Chapter{}, err
+	}
+
+	return chapter, nil
+}
+
+// UpdateChapter replaces the configurable fields of a chapter instance.
+func UpdateChapter(ctx context.Context, db *sql.DB, id uuid.UUID, input ChapterInput) (Chapter, error) {
+	if db == nil {
+		return Chapter{}, errors.New("database handle is nil")
+	}
+
+	const query = `
+UPDATE chapters
+SET name = $1,
+    timezone = $2,
+    max_concurrent_swarms = $3,
+    block_minutes = $4,
+    updated_at = $5
+WHERE id = $6
+RETURNING id, name, timezone, max_concurrent_swarms, block_minutes, created_at, updated_at
+`
+
+	var chapter Chapter
+	err := db.QueryRowContext(ctx, query, input.Name, input.Timezone, input.MaxConcurrentSwarms, input.BlockMinutes, time.Now().UTC(), id).Scan(
+		&chapter.ID,
+		&chapter.Name,
+		&chapter.Timezone,
+		&chapter.MaxConcurrentSwarms,
+		&chapter.BlockMinutes,
+		&chapter.CreatedAt,
+		&chapter.UpdatedAt,
+	)
+	if err != nil {
+		return Chapter{}, err
+	}
+
+	return chapter, nil
+}
+
+// ListChapters returns every chapter instance ordered by name.
+func ListChapters(ctx context.Context, db *sql.DB) ([]Chapter, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	const query = `
+SELECT id, name, timezone, max_concurrent_swarms, block_minutes, created_at, updated_at
+FROM chapters
+ORDER BY name
+`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chapters := make([]Chapter, 0)
+	for rows.Next() {
+		var chapter Chapter
+		if err := rows.Scan(
+			&chapter.ID,
+			&chapter.Name,
+			&chapter.Timezone,
+			&chapter.MaxConcurrentSwarms,
+			&chapter.BlockMinutes,
+			&chapter.CreatedAt,
+			&chapter.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		chapters = append(chapters, chapter)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return chapters, nil
+}
@@ -0,0 +1,162 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CoverageSnapshot records how many members of a session's chapter had
+// declared an intent for it at a checkpoint before it started. Members who
+// marked themselves unavailable are counted in Members only; DraftMembers
+// had only draft intents planned.
+type CoverageSnapshot struct {
+	SessionID        uuid.UUID
+	ChapterID        uuid.UUID
+	Checkpoint       string
+	SessionStartsAt  time.Time
+	Members          int
+	AvailableMembers int
+	DeclaredMembers  int
+	DraftMembers     int
+	ActiveIntents    int
+	CapturedAt       time.Time
+}
+
+// Coverage returns the share of available members who had declared an
+// intent, or 0 when nobody was available.
+func (s CoverageSnapshot) Coverage() float64 {
+	if s.AvailableMembers == 0 {
+		return 0
+	}
+	return float64(s.DeclaredMembers) / float64(s.AvailableMembers)
+}
+
+// CoverageFilters narrows coverage snapshots by chapter and session start.
+type CoverageFilters struct {
+	ChapterID    *uuid.UUID
+	StartsAfter  *time.Time
+	StartsBefore *time.Time
+}
+
+const coverageSnapshotColumns = `session_id, chapter_id, checkpoint, session_starts_at, members, available_members, declared_members, draft_members, active_intents, captured_at`
+
+// RecordCoverageSnapshot captures the session's intent coverage at
+// checkpoint. A checkpoint is captured once; recording it again returns
+// false and leaves the stored snapshot alone, as does a session that no
+// longer exists.
+func RecordCoverageSnapshot(ctx context.Context, db *sql.DB, sessionID uuid.UUID, checkpoint string, now time.Time) (CoverageSnapshot, bool, error) {
+	if db == nil {
+		return CoverageSnapshot{}, false, errors.New("database handle is nil")
+	}
+
+	const query = `
+INSERT INTO coverage_snapshots (` + coverageSnapshotColumns + `)
+SELECT s.id, s.chapter_id, $2, s.starts_at,
+       COUNT(m.id),
+       COUNT(m.id) FILTER (WHERE NOT m.unavailable),
+       COUNT(m.id) FILTER (WHERE m.declared AND NOT m.unavailable),
+       COUNT(m.id) FILTER (WHERE m.drafted AND NOT m.declared AND NOT m.unavailable),
+       (SELECT COUNT(*) FROM intents i WHERE i.session_id = s.id AND i.status = 'active'),
+       $3
+FROM sessions s
+LEFT JOIN LATERAL (
+    SELECT mb.id,
+           EXISTS (SELECT 1 FROM availability a WHERE a.session_id = s.id AND a.member_id = mb.id AND a.status = 'unavailable') AS unavailable,
+           EXISTS (SELECT 1 FROM intents i WHERE i.session_id = s.id AND i.member_id = mb.id AND i.status = 'active') AS declared,
+           EXISTS (SELECT 1 FROM intents i WHERE i.session_id = s.id AND i.member_id = mb.id AND i.status = 'draft') AS drafted
+    FROM members mb
+    WHERE mb.chapter_id = s.chapter_id
+) m ON TRUE
+WHERE s.id = $1
+GROUP BY s.id, s.chapter_id, s.starts_at
+ON CONFLICT (session_id, checkpoint) DO NOTHING
+RETURNING ` + coverageSnapshotColumns
+
+	snapshot, err := scanCoverageSnapshot(db.QueryRowContext(ctx, query, sessionID, checkpoint, now.UTC()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return CoverageSnapshot{}, false, nil
+	}
+	if err != nil {
+		return CoverageSnapshot{}, false, err
+	}
+
+	return snapshot, true, nil
+}
+
+// ListCoverageSnapshots returns stored snapshots ordered by session start,
+// each session's earlier checkpoint first.
+func ListCoverageSnapshots(ctx context.Context, db *sql.DB, filters CoverageFilters) ([]CoverageSnapshot, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	clauses := make([]string, 0, 3)
+	args := make([]any, 0, 3)
+
+	if filters.ChapterID != nil {
+		args = append(args, *filters.ChapterID)
+		clauses = append(clauses, fmt.Sprintf("chapter_id = $%d", len(args)))
+	}
+
+	if filters.StartsAfter != nil {
+		args = append(args, filters.StartsAfter.UTC())
+		clauses = append(clauses, fmt.Sprintf("session_starts_at >= $%d", len(args)))
+	}
+
+	if filters.StartsBefore != nil {
+		args = append(args, filters.StartsBefore.UTC())
+		clauses = append(clauses, fmt.Sprintf("session_starts_at <= $%d", len(args)))
+	}
+
+	query := `SELECT ` + coverageSnapshotColumns + ` FROM coverage_snapshots`
+	if len(clauses) > 0 {
+		query += " WHERE " + strings.Join(clauses, " AND ")
+	}
+	query += " ORDER BY session_starts_at, session_id, captured_at"
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshots := make([]CoverageSnapshot, 0)
+	for rows.Next() {
+		snapshot, err := scanCoverageSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+func scanCoverageSnapshot(row rowScanner) (CoverageSnapshot, error) {
+	var snapshot CoverageSnapshot
+	if err := row.Scan(
+		&snapshot.SessionID,
+		&snapshot.ChapterID,
+		&snapshot.Checkpoint,
+		&snapshot.SessionStartsAt,
+		&snapshot.Members,
+		&snapshot.AvailableMembers,
+		&snapshot.DeclaredMembers,
+		&snapshot.DraftMembers,
+		&snapshot.ActiveIntents,
+		&snapshot.CapturedAt,
+	); err != nil {
+		return CoverageSnapshot{}, err
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+var coverageSnapshotRowColumns = []string{"session_id", "chapter_id", "checkpoint", "session_starts_at", "members", "available_members", "declared_members", "draft_members", "active_intents", "captured_at"}
+
+func TestRecordCoverageSnapshotCapturesOnce(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID := uuid.New(), uuid.New()
+	now := time.Now().UTC()
+
+	mock.ExpectQuery("INSERT INTO coverage_snapshots").
+		WithArgs(sessionID, "24h", now).
+		WillReturnRows(sqlmock.NewRows(coverageSnapshotRowColumns).
+			AddRow(sessionID, chapterID, "24h", now.Add(24*time.Hour), 6, 5, 4, 1, 7, now))
+	mock.ExpectQuery("INSERT INTO coverage_snapshots").
+		WithArgs(sessionID, "24h", now).
+		WillReturnRows(sqlmock.NewRows(coverageSnapshotRowColumns))
+
+	snapshot, ok, err := RecordCoverageSnapshot(context.Background(), db, sessionID, "24h", now)
+	if err != nil || !ok {
+		t.Fatalf("RecordCoverageSnapshot returned %v, %v", ok, err)
+	}
+
+	if snapshot.Coverage() != 0.8 || snapshot.ActiveIntents != 7 {
+		t.Fatalf("unexpected snapshot %+v", snapshot)
+	}
+
+	if _, ok, err := RecordCoverageSnapshot(context.Background(), db, sessionID, "24h", now); err != nil || ok {
+		t.Fatalf("expected the second capture to be skipped, got %v, %v", ok, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestRecordCoverageSnapshotIgnoresUnavailableDeclarers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID := uuid.New(), uuid.New()
+	now := time.Now().UTC()
+
+	// Three members, all of whom declared, but one marked themselves
+	// unavailable: only the two available declarers count.
+	mock.ExpectQuery(regexp.QuoteMeta("COUNT(m.id) FILTER (WHERE m.declared AND NOT m.unavailable)")).
+		WithArgs(sessionID, "1h", now).
+		WillReturnRows(sqlmock.NewRows(coverageSnapshotRowColumns).
+			AddRow(sessionID, chapterID, "1h", now.Add(time.Hour), 3, 2, 2, 0, 3, now))
+
+	snapshot, ok, err := RecordCoverageSnapshot(context.Background(), db, sessionID, "1h", now)
+	if err != nil || !ok {
+		t.Fatalf("RecordCoverageSnapshot returned %v, %v", ok, err)
+	}
+
+	if snapshot.DeclaredMembers > snapshot.AvailableMembers || snapshot.Coverage() != 1 {
+		t.Fatalf("expected coverage capped at available members got %+v", snapshot)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestListCoverageSnapshotsFilters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	chapterID := uuid.New()
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM coverage_snapshots WHERE chapter_id = $1 AND session_starts_at >= $2 AND session_starts_at <= $3 ORDER BY session_starts_at")).
+		WithArgs(chapterID, from, to).
+		WillReturnRows(sqlmock.NewRows(coverageSnapshotRowColumns))
+
+	snapshots, err := ListCoverageSnapshots(context.Background(), db, CoverageFilters{ChapterID: &chapterID, StartsAfter: &from, StartsBefore: &to})
+	if err != nil {
+		t.Fatalf("ListCoverageSnapshots returned error: %v", err)
+	}
+
+	if snapshots == nil || len(snapshots) != 0 {
+		t.Fatalf("expected an empty list got %v", snapshots)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,388 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotChapterLead is returned when an exemplar is promoted by someone
+	// who is not a chapter lead.
+	ErrNotChapterLead = errors.New("promotedBy must be a chapter lead")
+	// ErrAlreadyExemplar is returned when promoting an intent that already
+	// has an exemplar.
+	ErrAlreadyExemplar = errors.New("intent is already an exemplar")
+)
+
+// Exemplar is an intent promoted to its chapter's library. Intent and
+// Outcome are snapshots taken at promotion; IntentID points at the source
+// until it is deleted.
+type Exemplar struct {
+	ID           uuid.UUID
+	ChapterID    uuid.UUID
+	IntentID     *uuid.UUID
+	GoalID       *uuid.UUID
+	TemplateKind string
+	Tags         []string
+	Annotation   string
+	Intent       Intent
+	Outcome      *SessionOutcome
+	PromotedBy   *uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// ExemplarInput promotes an intent. OutcomeID picks one of the intent's
+// close-out outcomes; without it the latest one, if any, is used.
+type ExemplarInput struct {
+	IntentID   uuid.UUID
+	OutcomeID  *uuid.UUID
+	PromotedBy uuid.UUID
+	Annotation string
+	Tags       []string
+}
+
+// ExemplarFilters narrow the exemplar library.
+type ExemplarFilters struct {
+	ChapterID    *uuid.UUID
+	GoalID       *uuid.UUID
+	Tag          string
+	TemplateKind string
+}
+
+// storedOutcomeSnapshot is the JSON shape used when freezing an outcome.
+type storedOutcomeSnapshot struct {
+	ID                uuid.UUID  `json:"id"`
+	SessionID         uuid.UUID  `json:"sessionId"`
+	MemberID          uuid.UUID  `json:"memberId"`
+	Outcome           string     `json:"outcome"`
+	Obstacles         string     `json:"obstacles"`
+	SatisfiedCriteria []string   `json:"satisfiedCriteria"`
+	NextIntentID      *uuid.UUID `json:"nextIntentId,omitempty"`
+	CreatedAt         time.Time  `json:"createdAt"`
+}
+
+const exemplarColumns = "id, chapter_id, intent_id, goal_id, template_kind, tags, annotation, intent_snapshot, outcome_snapshot, promoted_by, created_at, updated_at"
+
+// PromoteExemplar freezes an intent and its outcome into the library of the
+// promoting lead's chapter.
+func PromoteExemplar(ctx context.Context, db *sql.DB, input ExemplarInput) (Exemplar, error) {
+	if db == nil {
+		return Exemplar{}, errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Exemplar{}, err
+	}
+	defer tx.Rollback()
+
+	var (
+		chapterID uuid.UUID
+		role      string
+	)
+	err = tx.QueryRowContext(ctx, `SELECT chapter_id, role FROM members WHERE id = $1`, input.PromotedBy).Scan(&chapterID, &role)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && role != RoleChapterLead) {
+		return Exemplar{}, ErrNotChapterLead
+	}
+	if err != nil {
+		return Exemplar{}, err
+	}
+
+	intent, err := scanIntent(tx.QueryRowContext(ctx, `SELECT `+intentColumns+` FROM intents WHERE id = $1 FOR SHARE`, input.IntentID))
+	if err != nil {
+		return Exemplar{}, err
+	}
+
+	const outcomeQuery = `
+SELECT ` + sessionOutcomeColumns + `
+FROM session_outcomes
+WHERE intent_id = $1 AND ($2::uuid IS NULL OR id = $2)
+ORDER BY created_at DESC, id DESC
+LIMIT 1
+`
+
+	var outcome *SessionOutcome
+	found, err := scanSessionOutcome(tx.QueryRowContext(ctx, outcomeQuery, input.IntentID, uuidPtrValue(input.OutcomeID)))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		if input.OutcomeID != nil {
+			return Exemplar{}, ErrOutcomeNotFound
+		}
+	case err != nil:
+		return Exemplar{}, err
+	default:
+		outcome = &found
+	}
+
+	var templateKind string
+	if intent.TemplateID != nil {
+		err := tx.QueryRowContext(ctx, `SELECT kind FROM intent_templates WHERE id = $1`, *intent.TemplateID).Scan(&templateKind)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return Exemplar{}, err
+		}
+	}
+
+	now := time.Now().UTC()
+	exemplar := Exemplar{
+		ID:           uuid.New(),
+		ChapterID:    chapterID,
+		IntentID:     &intent.ID,
+		GoalID:       intent.GoalID,
+		TemplateKind: templateKind,
+		Tags:         nonNilStrings(input.Tags),
+		Annotation:   input.Annotation,
+		Intent:       intent,
+		Outcome:      outcome,
+		PromotedBy:   &input.PromotedBy,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	tagsJSON, err := json.Marshal(exemplar.Tags)
+	if err != nil {
+		return Exemplar{}, err
+	}
+
+	intentJSON, err := json.Marshal(intentSnapshot(intent))
+	if err != nil {
+		return Exemplar{}, err
+	}
+
+	var outcomeJSON any
+	if outcome != nil {
+		encoded, err := json.Marshal(outcomeSnapshot(*outcome))
+		if err != nil {
+			return Exemplar{}, err
+		}
+		outcomeJSON = string(encoded)
+	}
+
+	const insert = `
+INSERT INTO exemplars (` + exemplarColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11)
+ON CONFLICT (intent_id) DO NOTHING
+`
+
+	result, err := tx.ExecContext(ctx, insert, exemplar.ID, chapterID, intent.ID, uuidPtrValue(intent.GoalID), templateKind, string(tagsJSON), exemplar.Annotation, string(intentJSON), outcomeJSON, input.PromotedBy, now)
+	if err != nil {
+		return Exemplar{}, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return Exemplar{}, err
+	}
+
+	if affected == 0 {
+		return Exemplar{}, ErrAlreadyExemplar
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Exemplar{}, err
+	}
+
+	return exemplar, nil
+}
+
+// GetExemplar retrieves an exemplar by identifier.
+func GetExemplar(ctx context.Context, db *sql.DB, id uuid.UUID) (Exemplar, error) {
+	if db == nil {
+		return Exemplar{}, errors.New("database handle is nil")
+	}
+
+	return scanExemplar(db.QueryRowContext(ctx, `SELECT `+exemplarColumns+` FROM exemplars WHERE id = $1`, id))
+}
+
+// ListExemplars returns exemplars, newest first.
+func ListExemplars(ctx context.Context, db *sql.DB, filters ExemplarFilters) ([]Exemplar, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	conditions := make([]string, 0, 4)
+	args := make([]any, 0, 4)
+
+	if filters.ChapterID != nil {
+		args = append(args, *filters.ChapterID)
+		conditions = append(conditions, fmt.Sprintf("chapter_id = $%d", len(args)))
+	}
+
+	if filters.GoalID != nil {
+		args = append(args, *filters.GoalID)
+		conditions = append(conditions, fmt.Sprintf("goal_id = $%d", len(args)))
+	}
+
+	if filters.Tag != "" {
+		args = append(args, filters.Tag)
+		conditions = append(conditions, fmt.Sprintf("tags @> jsonb_build_array($%d::text)", len(args)))
+	}
+
+	if filters.TemplateKind != "" {
+		args = append(args, filters.TemplateKind)
+		conditions = append(conditions, fmt.Sprintf("template_kind = $%d", len(args)))
+	}
+
+	query := `SELECT ` + exemplarColumns + ` FROM exemplars`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY created_at DESC, id`
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	exemplars := make([]Exemplar, 0)
+	for rows.Next() {
+		exemplar, err := scanExemplar(rows)
+		if err != nil {
+			return nil, err
+		}
+		exemplars = append(exemplars, exemplar)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return exemplars, nil
+}
+
+// UpdateExemplar replaces an exemplar's annotation and tags. The snapshots
+// never change.
+func UpdateExemplar(ctx context.Context, db *sql.DB, id uuid.UUID, annotation string, tags []string) (Exemplar, error) {
+	if db == nil {
+		return Exemplar{}, errors.New("database handle is nil")
+	}
+
+	tagsJSON, err := json.Marshal(nonNilStrings(tags))
+	if err != nil {
+		return Exemplar{}, err
+	}
+
+	const query = `
+UPDATE exemplars
+SET annotation = $1,
+    tags = $2,
+    updated_at = $3
+WHERE id = $4
+RETURNING ` + exemplarColumns
+
+	return scanExemplar(db.QueryRowContext(ctx, query, annotation, string(tagsJSON), time.Now().UTC(), id))
+}
+
+// DeleteExemplar removes an exemplar from the library. The source intent is
+// left untouched.
+func DeleteExemplar(ctx context.Context, db *sql.DB, id uuid.UUID) error {
+	if db == nil {
+		return errors.New("database handle is nil")
+	}
+
+	result, err := db.ExecContext(ctx, `DELETE FROM exemplars WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func scanExemplar(row rowScanner) (Exemplar, error) {
+	var (
+		exemplar    Exemplar
+		intentID    uuid.NullUUID
+		goalID      uuid.NullUUID
+		tags        []byte
+		intentJSON  []byte
+		outcomeJSON []byte
+		promotedBy  uuid.NullUUID
+	)
+
+	if err := row.Scan(
+		&exemplar.ID,
+		&exemplar.ChapterID,
+		&intentID,
+		&goalID,
+		&exemplar.TemplateKind,
+		&tags,
+		&exemplar.Annotation,
+		&intentJSON,
+		&outcomeJSON,
+		&promotedBy,
+		&exemplar.CreatedAt,
+		&exemplar.UpdatedAt,
+	); err != nil {
+		return Exemplar{}, err
+	}
+
+	exemplar.Tags = []string{}
+	if len(tags) > 0 {
+		if err := json.Unmarshal(tags, &exemplar.Tags); err != nil {
+			return Exemplar{}, err
+		}
+	}
+
+	var snapshot storedIntentSnapshot
+	if err := json.Unmarshal(intentJSON, &snapshot); err != nil {
+		return Exemplar{}, err
+	}
+	exemplar.Intent = snapshot.toIntent()
+
+	if len(outcomeJSON) > 0 {
+		var outcome storedOutcomeSnapshot
+		if err := json.Unmarshal(outcomeJSON, &outcome); err != nil {
+			return Exemplar{}, err
+		}
+		exemplar.Outcome = outcome.toOutcome(snapshot.ID)
+	}
+
+	exemplar.IntentID = nullUUIDPtr(intentID)
+	exemplar.GoalID = nullUUIDPtr(goalID)
+	exemplar.PromotedBy = nullUUIDPtr(promotedBy)
+	return exemplar, nil
+}
+
+func outcomeSnapshot(outcome SessionOutcome) storedOutcomeSnapshot {
+	return storedOutcomeSnapshot{
+		ID:                outcome.ID,
+		SessionID:         outcome.SessionID,
+		MemberID:          outcome.MemberID,
+		Outcome:           outcome.Outcome,
+		Obstacles:         outcome.Obstacles,
+		SatisfiedCriteria: outcome.SatisfiedCriteria,
+		NextIntentID:      outcome.NextIntentID,
+		CreatedAt:         outcome.CreatedAt,
+	}
+}
+
+func (s storedOutcomeSnapshot) toOutcome(intentID uuid.UUID) *SessionOutcome {
+	return &SessionOutcome{
+		ID:                s.ID,
+		SessionID:         s.SessionID,
+		MemberID:          s.MemberID,
+		IntentID:          &intentID,
+		Outcome:           s.Outcome,
+		Obstacles:         s.Obstacles,
+		SatisfiedCriteria: nonNilStrings(s.SatisfiedCriteria),
+		NextIntentID:      s.NextIntentID,
+		CreatedAt:         s.CreatedAt,
+	}
+}
@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestPromoteExemplarFreezesIntentAndLatestOutcome(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	leadID, chapterID, intentID, goalID, templateID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	outcomeID, sessionID, memberID := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT chapter_id, role FROM members WHERE id = $1")).
+		WithArgs(leadID).
+		WillReturnRows(sqlmock.NewRows([]string{"chapter_id", "role"}).AddRow(chapterID, RoleChapterLead))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM intents WHERE id = $1 FOR SHARE")).
+		WithArgs(intentID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags"}).
+			AddRow(intentID, "I intend to halve deploy time", "context", "Deploys under 20 minutes", `["Ana"]`, IntentDone, memberID, goalID, sessionID, now, nil, nil, templateID, 2, nil))
+	mock.ExpectQuery("FROM session_outcomes").
+		WithArgs(intentID, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "session_id", "member_id", "intent_id", "outcome", "obstacles", "satisfied_criteria", "next_intent_id", "created_at"}).
+			AddRow(outcomeID, sessionID, memberID, intentID, "Deploys take 18 minutes", "", []byte(`[]`), nil, now))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT kind FROM intent_templates WHERE id = $1")).
+		WithArgs(templateID).
+		WillReturnRows(sqlmock.NewRows([]string{"kind"}).AddRow("spike"))
+	mock.ExpectExec("INSERT INTO exemplars").
+		WithArgs(sqlmock.AnyArg(), chapterID, intentID, goalID, "spike", `["delivery"]`, "Clear, measurable outcome", sqlmock.AnyArg(), sqlmock.AnyArg(), leadID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	exemplar, err := PromoteExemplar(context.Background(), db, ExemplarInput{
+		IntentID:   intentID,
+		PromotedBy: leadID,
+		Annotation: "Clear, measurable outcome",
+		Tags:       []string{"delivery"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if exemplar.ChapterID != chapterID || exemplar.TemplateKind != "spike" {
+		t.Fatalf("unexpected exemplar %+v", exemplar)
+	}
+	if exemplar.Outcome == nil || exemplar.Outcome.ID != outcomeID {
+		t.Fatalf("expected outcome %s got %+v", outcomeID, exemplar.Outcome)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestPromoteExemplarRequiresChapterLead(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	memberID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT chapter_id, role FROM members WHERE id = $1")).
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"chapter_id", "role"}).AddRow(uuid.New(), RoleMember))
+	mock.ExpectRollback()
+
+	_, err = PromoteExemplar(context.Background(), db, ExemplarInput{IntentID: uuid.New(), PromotedBy: memberID, Annotation: "Great"})
+	if !errors.Is(err, ErrNotChapterLead) {
+		t.Fatalf("expected ErrNotChapterLead got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestPromoteExemplarRejectsForeignOutcome(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	leadID, intentID, outcomeID := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT chapter_id, role FROM members WHERE id = $1")).
+		WillReturnRows(sqlmock.NewRows([]string{"chapter_id", "role"}).AddRow(uuid.New(), RoleChapterLead))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM intents WHERE id = $1 FOR SHARE")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags"}).
+			AddRow(intentID, "statement", "context", "outcome", `[]`, IntentActive, nil, nil, nil, time.Now(), nil, nil, nil, nil, nil))
+	mock.ExpectQuery("FROM session_outcomes").
+		WithArgs(intentID, outcomeID).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, err = PromoteExemplar(context.Background(), db, ExemplarInput{IntentID: intentID, OutcomeID: &outcomeID, PromotedBy: leadID, Annotation: "Great"})
+	if !errors.Is(err, ErrOutcomeNotFound) {
+		t.Fatalf("expected ErrOutcomeNotFound got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestListExemplarsFilters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	goalID, intentID := uuid.New(), uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM exemplars WHERE goal_id = $1 AND tags @> jsonb_build_array($2::text) AND template_kind = $3 ORDER BY created_at DESC, id")).
+		WithArgs(goalID, "delivery", "spike").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "chapter_id", "intent_id", "goal_id", "template_kind", "tags", "annotation", "intent_snapshot", "outcome_snapshot", "promoted_by", "created_at", "updated_at"}).
+			AddRow(uuid.New(), uuid.New(), nil, goalID, "spike", []byte(`["delivery"]`), "Clear", []byte(`{"id":"`+intentID.String()+`","statement":"I intend to","collaborators":[],"createdAt":"2024-05-06T09:00:00Z"}`), nil, nil, now, now))
+
+	exemplars, err := ListExemplars(context.Background(), db, ExemplarFilters{GoalID: &goalID, Tag: "delivery", TemplateKind: "spike"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(exemplars) != 1 || exemplars[0].Intent.ID != intentID || exemplars[0].IntentID != nil || exemplars[0].Outcome != nil {
+		t.Fatalf("unexpected exemplars %+v", exemplars)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,289 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FlowFilters bound flow metrics to a date range. Each cycle time is counted
+// in the range its starting event falls in, and utilization covers the
+// sessions starting in the range.
+type FlowFilters struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// DurationStats summarises a set of cycle times with nearest-rank
+// percentiles. The percentiles are zero when Count is zero.
+type DurationStats struct {
+	Count int
+	P50   time.Duration
+	P85   time.Duration
+	P95   time.Duration
+}
+
+// UtilizationStats compares the member-hours committed in sessions with the
+// member-hours their members declared available. The percentiles are over
+// the per-session ratios of sessions with any availability.
+type UtilizationStats struct {
+	Sessions       int
+	CommittedHours float64
+	AvailableHours float64
+	Ratio          float64
+	P50            float64
+	P85            float64
+	P95            float64
+}
+
+// FlowMetrics holds the cycle times from goal to swarm to outcome and the
+// session utilization over a date range.
+type FlowMetrics struct {
+	// GoalToIntent runs from a goal's creation to the first intent linked
+	// to it.
+	GoalToIntent DurationStats
+	// IntentToSwarm runs from an intent's declaration to its owner joining
+	// a swarm in the intent's session.
+	IntentToSwarm DurationStats
+	// SwarmToOutcome runs from a swarm's start to the first outcome its
+	// members recorded for the session.
+	SwarmToOutcome DurationStats
+	Utilization    UtilizationStats
+}
+
+// Intents created before their history was recorded have no transitions;
+// their creation time stands in for the goal link and the declaration.
+const goalToIntentQuery = `
+SELECT EXTRACT(EPOCH FROM linked.at - g.created_at)
+FROM goals g
+JOIN LATERAL (
+    SELECT LEAST(
+        (SELECT MIN(t.created_at) FROM intent_transitions t WHERE t.goal_id = g.id),
+        (SELECT MIN(i.created_at) FROM intents i
+         WHERE i.goal_id = g.id AND NOT EXISTS (SELECT 1 FROM intent_transitions t WHERE t.intent_id = i.id))
+    ) AS at
+) linked ON linked.at IS NOT NULL
+WHERE ($1::timestamptz IS NULL OR g.created_at >= $1)
+  AND ($2::timestamptz IS NULL OR g.created_at <= $2)
+`
+
+const intentToSwarmQuery = `
+SELECT EXTRACT(EPOCH FROM joined.at - declared.at)
+FROM intents i
+JOIN LATERAL (
+    SELECT COALESCE(
+        (SELECT MIN(t.created_at) FROM intent_transitions t WHERE t.intent_id = i.id AND t.to_status = 'active'),
+        CASE WHEN i.status <> 'draft' AND NOT EXISTS (SELECT 1 FROM intent_transitions t WHERE t.intent_id = i.id) THEN i.created_at END
+    ) AS at
+) declared ON declared.at IS NOT NULL
+JOIN LATERAL (
+    SELECT MIN(sm.joined_at) AS at
+    FROM swarms s
+    JOIN swarm_members sm ON sm.swarm_id = s.id
+    WHERE s.session_id = i.session_id AND sm.member_id = i.member_id AND sm.joined_at >= declared.at
+) joined ON joined.at IS NOT NULL
+WHERE ($1::timestamptz IS NULL OR declared.at >= $1)
+  AND ($2::timestamptz IS NULL OR declared.at <= $2)
+`
+
+const swarmToOutcomeQuery = `
+SELECT EXTRACT(EPOCH FROM outcome.at - s.starts_at)
+FROM swarms s
+JOIN LATERAL (
+    SELECT MIN(o.created_at) AS at
+    FROM session_outcomes o
+    JOIN swarm_members sm ON sm.swarm_id = s.id AND sm.member_id = o.member_id
+    WHERE o.session_id = s.session_id AND o.created_at >= s.starts_at
+) outcome ON outcome.at IS NOT NULL
+WHERE ($1::timestamptz IS NULL OR s.starts_at >= $1)
+  AND ($2::timestamptz IS NULL OR s.starts_at <= $2)
+`
+
+// GetFlowMetrics computes cycle-time percentiles and session utilization
+// over the date range.
+func GetFlowMetrics(ctx context.Context, db *sql.DB, filters FlowFilters) (FlowMetrics, error) {
+	if db == nil {
+		return FlowMetrics{}, errors.New("database handle is nil")
+	}
+
+	var (
+		metrics FlowMetrics
+		err     error
+	)
+
+	if metrics.GoalToIntent, err = flowDurations(ctx, db, goalToIntentQuery, filters); err != nil {
+		return FlowMetrics{}, err
+	}
+	if metrics.IntentToSwarm, err = flowDurations(ctx, db, intentToSwarmQuery, filters); err != nil {
+		return FlowMetrics{}, err
+	}
+	if metrics.SwarmToOutcome, err = flowDurations(ctx, db, swarmToOutcomeQuery, filters); err != nil {
+		return FlowMetrics{}, err
+	}
+	if metrics.Utilization, err = sessionUtilization(ctx, db, filters); err != nil {
+		return FlowMetrics{}, err
+	}
+
+	return metrics, nil
+}
+
+// flowDurations runs a query selecting cycle times in seconds and
+// summarises them.
+func flowDurations(ctx context.Context, q queryer, query string, filters FlowFilters) (DurationStats, error) {
+	rows, err := q.QueryContext(ctx, query, timePtrValue(filters.From), timePtrValue(filters.To))
+	if err != nil {
+		return DurationStats{}, err
+	}
+	defer rows.Close()
+
+	seconds := make([]float64, 0)
+	for rows.Next() {
+		var value float64
+		if err := rows.Scan(&value); err != nil {
+			return DurationStats{}, err
+		}
+		seconds = append(seconds, value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return DurationStats{}, err
+	}
+
+	sort.Float64s(seconds)
+
+	toDuration := func(value float64) time.Duration {
+		return time.Duration(value * float64(time.Second)).Round(time.Second)
+	}
+
+	return DurationStats{
+		Count: len(seconds),
+		P50:   toDuration(percentile(seconds, 50)),
+		P85:   toDuration(percentile(seconds, 85)),
+		P95:   toDuration(percentile(seconds, 95)),
+	}, nil
+}
+
+// sessionUtilization totals the committed and available member-hours of the
+// sessions starting in the range. Availability is counted in whole capacity
+// blocks, as it is when members commit.
+func sessionUtilization(ctx context.Context, db *sql.DB, filters FlowFilters) (UtilizationStats, error) {
+	const sessionsQuery = `
+SELECT s.id, s.starts_at, s.ends_at, c.block_minutes,
+       (SELECT COALESCE(SUM(cm.blocks), 0) FROM commitments cm WHERE cm.session_id = s.id)
+FROM sessions s
+JOIN chapters c ON c.id = s.chapter_id
+WHERE ($1::timestamptz IS NULL OR s.starts_at >= $1)
+  AND ($2::timestamptz IS NULL OR s.starts_at <= $2)
+ORDER BY s.starts_at, s.id
+`
+
+	rows, err := db.QueryContext(ctx, sessionsQuery, timePtrValue(filters.From), timePtrValue(filters.To))
+	if err != nil {
+		return UtilizationStats{}, err
+	}
+	defer rows.Close()
+
+	type sessionHours struct {
+		session     Session
+		blockLength time.Duration
+		committed   int
+		available   int
+	}
+
+	sessions := make([]*sessionHours, 0)
+	byID := make(map[uuid.UUID]*sessionHours)
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var (
+			entry        sessionHours
+			blockMinutes int
+		)
+		if err := rows.Scan(&entry.session.ID, &entry.session.StartsAt, &entry.session.EndsAt, &blockMinutes, &entry.committed); err != nil {
+			return UtilizationStats{}, err
+		}
+		entry.blockLength = time.Duration(blockMinutes) * time.Minute
+
+		sessions = append(sessions, &entry)
+		byID[entry.session.ID] = &entry
+		ids = append(ids, entry.session.ID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return UtilizationStats{}, err
+	}
+	rows.Close()
+
+	stats := UtilizationStats{Sessions: len(sessions)}
+	if len(sessions) == 0 {
+		return stats, nil
+	}
+
+	availabilityRows, err := db.QueryContext(ctx, `SELECT session_id, status, starts_at, ends_at FROM availability WHERE session_id = ANY($1::uuid[])`, uuidArrayLiteral(ids))
+	if err != nil {
+		return UtilizationStats{}, err
+	}
+	defer availabilityRows.Close()
+
+	for availabilityRows.Next() {
+		var (
+			sessionID uuid.UUID
+			status    string
+			startsAt  sql.NullTime
+			endsAt    sql.NullTime
+		)
+		if err := availabilityRows.Scan(&sessionID, &status, &startsAt, &endsAt); err != nil {
+			return UtilizationStats{}, err
+		}
+
+		if entry, ok := byID[sessionID]; ok {
+			entry.available += AvailableBlocks(entry.session, entry.blockLength, status, nullTimePtr(startsAt), nullTimePtr(endsAt))
+		}
+	}
+
+	if err := availabilityRows.Err(); err != nil {
+		return UtilizationStats{}, err
+	}
+
+	ratios := make([]float64, 0, len(sessions))
+	for _, entry := range sessions {
+		committed := float64(entry.committed) * entry.blockLength.Hours()
+		available := float64(entry.available) * entry.blockLength.Hours()
+
+		stats.CommittedHours += committed
+		stats.AvailableHours += available
+		if available > 0 {
+			ratios = append(ratios, committed/available)
+		}
+	}
+
+	if stats.AvailableHours > 0 {
+		stats.Ratio = stats.CommittedHours / stats.AvailableHours
+	}
+
+	sort.Float64s(ratios)
+	stats.P50 = percentile(ratios, 50)
+	stats.P85 = percentile(ratios, 85)
+	stats.P95 = percentile(ratios, 95)
+
+	return stats, nil
+}
+
+// percentile returns the nearest-rank percentile p of sorted values, or zero
+// when there are none.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
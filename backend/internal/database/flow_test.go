@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestPercentileNearestRank(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	for _, tc := range []struct {
+		p    float64
+		want float64
+	}{
+		{p: 50, want: 5},
+		{p: 85, want: 9},
+		{p: 95, want: 10},
+	} {
+		if got := percentile(values, tc.p); got != tc.want {
+			t.Fatalf("p%v: expected %v got %v", tc.p, tc.want, got)
+		}
+	}
+
+	if got := percentile(nil, 50); got != 0 {
+		t.Fatalf("expected 0 for no values got %v", got)
+	}
+}
+
+func TestGetFlowMetrics(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	sessionID := uuid.New()
+	startsAt := time.Date(2026, 10, 5, 13, 0, 0, 0, time.UTC)
+	partialStart, partialEnd := startsAt, startsAt.Add(2*time.Hour)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goals g")).
+		WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"seconds"}).AddRow(7200.0).AddRow(3600.0).AddRow(86400.0))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM intents i")).
+		WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"seconds"}).AddRow(1800.4))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM swarms s")).
+		WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"seconds"}))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM sessions s")).
+		WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "starts_at", "ends_at", "block_minutes", "committed"}).
+			AddRow(sessionID, startsAt, startsAt.Add(4*time.Hour), 60, 3))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT session_id, status, starts_at, ends_at FROM availability WHERE session_id = ANY($1::uuid[])")).
+		WithArgs("{" + sessionID.String() + "}").
+		WillReturnRows(sqlmock.NewRows([]string{"session_id", "status", "starts_at", "ends_at"}).
+			AddRow(sessionID, AvailabilityAvailable, nil, nil).
+			AddRow(sessionID, AvailabilityPartial, partialStart, partialEnd).
+			AddRow(sessionID, AvailabilityUnavailable, nil, nil))
+
+	metrics, err := GetFlowMetrics(context.Background(), db, FlowFilters{From: &from, To: &to})
+	if err != nil {
+		t.Fatalf("GetFlowMetrics returned error: %v", err)
+	}
+
+	if metrics.GoalToIntent.Count != 3 || metrics.GoalToIntent.P50 != 2*time.Hour || metrics.GoalToIntent.P95 != 24*time.Hour {
+		t.Fatalf("unexpected goal to intent stats %+v", metrics.GoalToIntent)
+	}
+
+	if metrics.IntentToSwarm.Count != 1 || metrics.IntentToSwarm.P85 != 30*time.Minute {
+		t.Fatalf("unexpected intent to swarm stats %+v", metrics.IntentToSwarm)
+	}
+
+	if metrics.SwarmToOutcome.Count != 0 || metrics.SwarmToOutcome.P50 != 0 {
+		t.Fatalf("unexpected swarm to outcome stats %+v", metrics.SwarmToOutcome)
+	}
+
+	utilization := metrics.Utilization
+	if utilization.Sessions != 1 || utilization.CommittedHours != 3 || utilization.AvailableHours != 6 || utilization.Ratio != 0.5 || utilization.P50 != 0.5 {
+		t.Fatalf("unexpected utilization %+v", utilization)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,255 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/example/intent/backend/internal/keyresult"
+	"github.com/google/uuid"
+)
+
+// ErrCriterionHasNoMetric is returned when measuring a success criterion that
+// has no metric.
+var ErrCriterionHasNoMetric = errors.New("criterion has no metric to measure")
+
+// GoalCriterion is a success criterion of a goal with its optional metric.
+// Current and Previous are the latest two measurements, most recent first.
+type GoalCriterion struct {
+	ID         uuid.UUID
+	GoalID     uuid.UUID
+	Criterion  string
+	Metric     *keyresult.Metric
+	Current    *float64
+	MeasuredAt *time.Time
+	Previous   *float64
+}
+
+// Progress evaluates the criterion against its metric.
+func (c GoalCriterion) Progress() keyresult.Progress {
+	return keyresult.Evaluate(c.Metric, c.Current, c.Previous)
+}
+
+// CriterionMeasurement is a value recorded for a measurable criterion.
+type CriterionMeasurement struct {
+	ID          uuid.UUID
+	CriterionID uuid.UUID
+	Value       float64
+	MeasuredAt  time.Time
+	Note        string
+	CreatedAt   time.Time
+}
+
+// CriterionMeasurementInput captures the fields required to record a
+// measurement.
+type CriterionMeasurementInput struct {
+	Value      float64
+	MeasuredAt time.Time
+	Note       string
+}
+
+const criterionMeasurementColumns = `id, criterion_id, value, measured_at, note, created_at`
+
+// syncGoalCriteria makes the goal's criterion rows match criteria, keeping
+// the identity and measurements of criteria whose text is unchanged.
+// metrics holds the metric of each measurable criterion by its text.
+func syncGoalCriteria(ctx context.Context, q queryer, goalID uuid.UUID, criteria []string, metrics map[string]keyresult.Metric, now time.Time) error {
+	const upsert = `
+INSERT INTO goal_criteria (id, goal_id, criterion, position, baseline, target, unit, direction, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+ON CONFLICT (goal_id, criterion) DO UPDATE
+SET position = EXCLUDED.position,
+    baseline = EXCLUDED.baseline,
+    target = EXCLUDED.target,
+    unit = EXCLUDED.unit,
+    direction = EXCLUDED.direction,
+    updated_at = EXCLUDED.updated_at
+`
+
+	for position, criterion := range criteria {
+		var baseline, target, unit, direction any
+		if metric, ok := metrics[criterion]; ok {
+			baseline, target, unit, direction = metric.Baseline, metric.Target, metric.Unit, metric.Direction
+		}
+
+		if _, err := q.ExecContext(ctx, upsert, uuid.New(), goalID, criterion, position, baseline, target, unit, direction, now); err != nil {
+			return err
+		}
+	}
+
+	_, err := q.ExecContext(ctx, `DELETE FROM goal_criteria WHERE goal_id = $1 AND criterion <> ALL($2::text[])`, goalID, textArrayLiteral(criteria))
+	return err
+}
+
+// ListGoalCriteria returns the criteria of each goal in their listed order,
+// keyed by goal.
+func ListGoalCriteria(ctx context.Context, db *sql.DB, goalIDs []uuid.UUID) (map[uuid.UUID][]GoalCriterion, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	criteria := make(map[uuid.UUID][]GoalCriterion, len(goalIDs))
+	if len(goalIDs) == 0 {
+		return criteria, nil
+	}
+
+	const query = `
+SELECT c.id, c.goal_id, c.criterion, c.baseline, c.target, c.unit, c.direction,
+       latest.value, latest.measured_at, previous.value
+FROM goal_criteria c
+LEFT JOIN LATERAL (
+    SELECT value, measured_at FROM goal_criterion_measurements m
+    WHERE m.criterion_id = c.id
+    ORDER BY m.measured_at DESC, m.created_at DESC
+    LIMIT 1
+) latest ON TRUE
+LEFT JOIN LATERAL (
+    SELECT value FROM goal_criterion_measurements m
+    WHERE m.criterion_id = c.id
+    ORDER BY m.measured_at DESC, m.created_at DESC
+    OFFSET 1 LIMIT 1
+) previous ON TRUE
+WHERE c.goal_id = ANY($1::uuid[])
+ORDER BY c.goal_id, c.position
+`
+
+	rows, err := db.QueryContext(ctx, query, uuidArrayLiteral(goalIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			criterion  GoalCriterion
+			baseline   sql.NullFloat64
+			target     sql.NullFloat64
+			unit       sql.NullString
+			direction  sql.NullString
+			current    sql.NullFloat64
+			measuredAt sql.NullTime
+			previous   sql.NullFloat64
+		)
+
+		if err := rows.Scan(&criterion.ID, &criterion.GoalID, &criterion.Criterion, &baseline, &target, &unit, &direction, &current, &measuredAt, &previous); err != nil {
+			return nil, err
+		}
+
+		if direction.Valid {
+			criterion.Metric = &keyresult.Metric{
+				Baseline:  baseline.Float64,
+				Target:    target.Float64,
+				Unit:      unit.String,
+				Direction: direction.String,
+			}
+		}
+		criterion.Current = nullFloatPtr(current)
+		criterion.MeasuredAt = nullTimePtr(measuredAt)
+		criterion.Previous = nullFloatPtr(previous)
+
+		criteria[criterion.GoalID] = append(criteria[criterion.GoalID], criterion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return criteria, nil
+}
+
+// RecordCriterionMeasurement stores a measurement of one of the goal's
+// criteria. It returns sql.ErrNoRows when the criterion is not the goal's
+// and ErrCriterionHasNoMetric when it has no metric.
+func RecordCriterionMeasurement(ctx context.Context, db *sql.DB, goalID, criterionID uuid.UUID, input CriterionMeasurementInput) (CriterionMeasurement, error) {
+	if db == nil {
+		return CriterionMeasurement{}, errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return CriterionMeasurement{}, err
+	}
+	defer tx.Rollback()
+
+	var measurable bool
+	if err := tx.QueryRowContext(ctx, `SELECT direction IS NOT NULL FROM goal_criteria WHERE id = $1 AND goal_id = $2 FOR SHARE`, criterionID, goalID).Scan(&measurable); err != nil {
+		return CriterionMeasurement{}, err
+	}
+
+	if !measurable {
+		return CriterionMeasurement{}, ErrCriterionHasNoMetric
+	}
+
+	measurement := CriterionMeasurement{
+		ID:          uuid.New(),
+		CriterionID: criterionID,
+		Value:       input.Value,
+		MeasuredAt:  input.MeasuredAt.UTC(),
+		Note:        input.Note,
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	const query = `
+INSERT INTO goal_criterion_measurements (` + criterionMeasurementColumns + `)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+	if _, err := tx.ExecContext(ctx, query, measurement.ID, criterionID, measurement.Value, measurement.MeasuredAt, measurement.Note, measurement.CreatedAt); err != nil {
+		return CriterionMeasurement{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return CriterionMeasurement{}, err
+	}
+
+	return measurement, nil
+}
+
+// ListCriterionMeasurements returns the measurements of one of the goal's
+// criteria, oldest first. It returns sql.ErrNoRows when the criterion is not
+// the goal's.
+func ListCriterionMeasurements(ctx context.Context, db *sql.DB, goalID, criterionID uuid.UUID) ([]CriterionMeasurement, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	var exists bool
+	if err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM goal_criteria WHERE id = $1 AND goal_id = $2)`, criterionID, goalID).Scan(&exists); err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	query := `SELECT ` + criterionMeasurementColumns + ` FROM goal_criterion_measurements WHERE criterion_id = $1 ORDER BY measured_at, created_at`
+
+	rows, err := db.QueryContext(ctx, query, criterionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	measurements := make([]CriterionMeasurement, 0)
+	for rows.Next() {
+		var measurement CriterionMeasurement
+		if err := rows.Scan(&measurement.ID, &measurement.CriterionID, &measurement.Value, &measurement.MeasuredAt, &measurement.Note, &measurement.CreatedAt); err != nil {
+			return nil, err
+		}
+		measurements = append(measurements, measurement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return measurements, nil
+}
+
+func nullFloatPtr(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
@@ -0,0 +1,155 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/intent/backend/internal/keyresult"
+	"github.com/google/uuid"
+)
+
+func TestSyncGoalCriteriaKeepsMetrics(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	goalID := uuid.New()
+	now := time.Now().UTC()
+	criteria := []string{"Coverage above 80%", "Handbook published"}
+	metrics := map[string]keyresult.Metric{
+		"Coverage above 80%": {Baseline: 60, Target: 80, Unit: "%", Direction: keyresult.Increase},
+	}
+
+	mock.ExpectExec("INSERT INTO goal_criteria").
+		WithArgs(sqlmock.AnyArg(), goalID, "Coverage above 80%", 0, 60.0, 80.0, "%", keyresult.Increase, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO goal_criteria").
+		WithArgs(sqlmock.AnyArg(), goalID, "Handbook published", 1, nil, nil, nil, nil, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM goal_criteria WHERE goal_id = $1 AND criterion <> ALL($2::text[])")).
+		WithArgs(goalID, `{"Coverage above 80%","Handbook published"}`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := syncGoalCriteria(context.Background(), db, goalID, criteria, metrics, now); err != nil {
+		t.Fatalf("syncGoalCriteria returned error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestListGoalCriteria(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	goalID, coverageID, handbookID := uuid.New(), uuid.New(), uuid.New()
+	measuredAt := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goal_criteria c")).
+		WithArgs("{" + goalID.String() + "}").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "goal_id", "criterion", "baseline", "target", "unit", "direction", "current", "measured_at", "previous"}).
+			AddRow(coverageID, goalID, "Coverage above 80%", 60.0, 80.0, "%", keyresult.Increase, 70.0, measuredAt, 75.0).
+			AddRow(handbookID, goalID, "Handbook published", nil, nil, nil, nil, nil, nil, nil))
+
+	criteria, err := ListGoalCriteria(context.Background(), db, []uuid.UUID{goalID})
+	if err != nil {
+		t.Fatalf("ListGoalCriteria returned error: %v", err)
+	}
+
+	list := criteria[goalID]
+	if len(list) != 2 {
+		t.Fatalf("expected 2 criteria got %d", len(list))
+	}
+
+	coverage := list[0]
+	if coverage.Metric == nil || coverage.Metric.Target != 80 || coverage.Current == nil || *coverage.Current != 70 {
+		t.Fatalf("unexpected measurable criterion %+v", coverage)
+	}
+
+	if progress := coverage.Progress(); progress.Status != keyresult.StatusAtRisk || *progress.Ratio != 0.5 {
+		t.Fatalf("unexpected progress %+v", progress)
+	}
+
+	if list[1].Metric != nil || list[1].Progress().Status != keyresult.StatusNoMetric {
+		t.Fatalf("expected criterion without metric got %+v", list[1])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestRecordCriterionMeasurementRequiresMetric(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	goalID, criterionID := uuid.New(), uuid.New()
+	input := CriterionMeasurementInput{Value: 72, MeasuredAt: time.Now()}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT direction IS NOT NULL FROM goal_criteria")).
+		WithArgs(criterionID, goalID).
+		WillReturnRows(sqlmock.NewRows([]string{"measurable"}).AddRow(false))
+	mock.ExpectRollback()
+
+	if _, err := RecordCriterionMeasurement(context.Background(), db, goalID, criterionID, input); err != ErrCriterionHasNoMetric {
+		t.Fatalf("expected ErrCriterionHasNoMetric got %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT direction IS NOT NULL FROM goal_criteria")).
+		WithArgs(criterionID, goalID).
+		WillReturnRows(sqlmock.NewRows([]string{"measurable"}).AddRow(true))
+	mock.ExpectExec("INSERT INTO goal_criterion_measurements").
+		WithArgs(sqlmock.AnyArg(), criterionID, 72.0, sqlmock.AnyArg(), "", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	measurement, err := RecordCriterionMeasurement(context.Background(), db, goalID, criterionID, input)
+	if err != nil {
+		t.Fatalf("RecordCriterionMeasurement returned error: %v", err)
+	}
+
+	if measurement.CriterionID != criterionID || measurement.Value != 72 {
+		t.Fatalf("unexpected measurement %+v", measurement)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestListCriterionMeasurementsUnknownCriterion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	goalID, criterionID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM goal_criteria")).
+		WithArgs(criterionID, goalID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	if _, err := ListCriterionMeasurements(context.Background(), db, goalID, criterionID); err != sql.ErrNoRows {
+		t.Fatalf("expected sql.ErrNoRows got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,280 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/example/intent/backend/internal/diff"
+	"github.com/example/intent/backend/internal/keyresult"
+	"github.com/google/uuid"
+)
+
+// GoalRevision is an immutable copy of a goal's content as it was created,
+// updated or restored. RestoredFrom is the revision a restore copied.
+type GoalRevision struct {
+	GoalID           uuid.UUID
+	Revision         int
+	Title            string
+	ClarityStatement string
+	Guardrails       []string
+	DecisionRights   []string
+	Constraints      []string
+	SuccessCriteria  []string
+	Metrics          map[string]keyresult.Metric
+	RestoredFrom     *int
+	CreatedAt        time.Time
+}
+
+// storedMetric is the JSON shape of a criterion metric in a revision.
+type storedMetric struct {
+	Baseline  float64 `json:"baseline"`
+	Target    float64 `json:"target"`
+	Unit      string  `json:"unit"`
+	Direction string  `json:"direction"`
+}
+
+const goalRevisionColumns = "goal_id, revision, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, metrics, restored_from, created_at"
+
+// recordGoalRevision stores the goal's content as its next revision.
+func recordGoalRevision(ctx context.Context, q queryer, goal Goal, metrics map[string]keyresult.Metric, restoredFrom *int, now time.Time) error {
+	guardrailsJSON, err := json.Marshal(goal.Guardrails)
+	if err != nil {
+		return err
+	}
+
+	decisionJSON, err := json.Marshal(goal.DecisionRights)
+	if err != nil {
+		return err
+	}
+
+	constraintsJSON, err := json.Marshal(goal.Constraints)
+	if err != nil {
+		return err
+	}
+
+	successJSON, err := json.Marshal(goal.SuccessCriteria)
+	if err != nil {
+		return err
+	}
+
+	stored := make(map[string]storedMetric, len(metrics))
+	for criterion, metric := range metrics {
+		stored[criterion] = storedMetric(metric)
+	}
+
+	metricsJSON, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+
+	const query = `
+INSERT INTO goal_revisions (` + goalRevisionColumns + `)
+SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+FROM goal_revisions
+WHERE goal_id = $1
+`
+
+	var restored any
+	if restoredFrom != nil {
+		restored = *restoredFrom
+	}
+
+	_, err = q.ExecContext(ctx, query, goal.ID, goal.Title, goal.ClarityStatement, string(guardrailsJSON), string(decisionJSON), string(constraintsJSON), string(successJSON), string(metricsJSON), restored, now)
+	return err
+}
+
+// ListGoalRevisions returns the goal's revisions, oldest first. It returns
+// sql.ErrNoRows when the goal does not exist.
+func ListGoalRevisions(ctx context.Context, db *sql.DB, goalID uuid.UUID) ([]GoalRevision, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	query := `SELECT ` + goalRevisionColumns + ` FROM goal_revisions WHERE goal_id = $1 ORDER BY revision`
+
+	rows, err := db.QueryContext(ctx, query, goalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]GoalRevision, 0)
+	for rows.Next() {
+		revision, err := scanGoalRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(revisions) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return revisions, nil
+}
+
+// GetGoalRevision returns one revision of the goal, or sql.ErrNoRows when
+// there is no such revision.
+func GetGoalRevision(ctx context.Context, db *sql.DB, goalID uuid.UUID, revision int) (GoalRevision, error) {
+	if db == nil {
+		return GoalRevision{}, errors.New("database handle is nil")
+	}
+
+	return getGoalRevision(ctx, db, goalID, revision)
+}
+
+func getGoalRevision(ctx context.Context, q queryer, goalID uuid.UUID, revision int) (GoalRevision, error) {
+	query := `SELECT ` + goalRevisionColumns + ` FROM goal_revisions WHERE goal_id = $1 AND revision = $2`
+	return scanGoalRevision(q.QueryRowContext(ctx, query, goalID, revision))
+}
+
+// RestoreGoalRevision puts the content of a revision back on the goal and
+// records it as a new revision; the goal's parent and status are left as
+// they are. It returns sql.ErrNoRows when there is no such revision.
+func RestoreGoalRevision(ctx context.Context, db *sql.DB, goalID uuid.UUID, revision int) (Goal, error) {
+	if db == nil {
+		return Goal{}, errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Goal{}, err
+	}
+	defer tx.Rollback()
+
+	restored, err := getGoalRevision(ctx, tx, goalID, revision)
+	if err != nil {
+		return Goal{}, err
+	}
+
+	const query = `
+UPDATE goals g
+SET title = r.title,
+    clarity_statement = r.clarity_statement,
+    guardrails = r.guardrails,
+    decision_rights = r.decision_rights,
+    constraints = r.constraints,
+    success_criteria = r.success_criteria,
+    updated_at = $3
+FROM goal_revisions r
+WHERE g.id = $1 AND r.goal_id = g.id AND r.revision = $2
+RETURNING ` + prefixedGoalColumns + `
+`
+
+	now := time.Now().UTC()
+
+	goal, err := scanGoal(tx.QueryRowContext(ctx, query, goalID, revision, now))
+	if err != nil {
+		return Goal{}, err
+	}
+
+	if err := syncGoalCriteria(ctx, tx, goalID, goal.SuccessCriteria, restored.Metrics, now); err != nil {
+		return Goal{}, err
+	}
+
+	if err := recordGoalRevision(ctx, tx, goal, restored.Metrics, &revision, now); err != nil {
+		return Goal{}, err
+	}
+
+	if err := recordWebhookEvent(ctx, tx, EventGoalUpdated, goalSnapshot(goal)); err != nil {
+		return Goal{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Goal{}, err
+	}
+
+	return goal, nil
+}
+
+// DiffGoalRevisions lists the fields that changed from one revision to
+// another, in the order they appear on a goal.
+func DiffGoalRevisions(from, to GoalRevision) []diff.Change {
+	changes := make([]diff.Change, 0)
+	add := func(change diff.Change, changed bool) {
+		if changed {
+			changes = append(changes, change)
+		}
+	}
+
+	add(diff.Text("title", from.Title, to.Title))
+	add(diff.Text("clarityStatement", from.ClarityStatement, to.ClarityStatement))
+	add(diff.List("guardrails", from.Guardrails, to.Guardrails))
+	add(diff.List("decisionRights", from.DecisionRights, to.DecisionRights))
+	add(diff.List("constraints", from.Constraints, to.Constraints))
+	add(diff.List("successCriteria", from.SuccessCriteria, to.SuccessCriteria))
+
+	return changes
+}
+
+func scanGoalRevision(row rowScanner) (GoalRevision, error) {
+	var (
+		revision       GoalRevision
+		rawGuardrails  []byte
+		rawDecision    []byte
+		rawConstraints []byte
+		rawSuccess     []byte
+		rawMetrics     []byte
+		restoredFrom   sql.NullInt64
+	)
+
+	if err := row.Scan(
+		&revision.GoalID,
+		&revision.Revision,
+		&revision.Title,
+		&revision.ClarityStatement,
+		&rawGuardrails,
+		&rawDecision,
+		&rawConstraints,
+		&rawSuccess,
+		&rawMetrics,
+		&restoredFrom,
+		&revision.CreatedAt,
+	); err != nil {
+		return GoalRevision{}, err
+	}
+
+	for _, field := range []struct {
+		raw  []byte
+		dest *[]string
+	}{
+		{raw: rawGuardrails, dest: &revision.Guardrails},
+		{raw: rawDecision, dest: &revision.DecisionRights},
+		{raw: rawConstraints, dest: &revision.Constraints},
+		{raw: rawSuccess, dest: &revision.SuccessCriteria},
+	} {
+		if len(field.raw) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(field.raw, field.dest); err != nil {
+			return GoalRevision{}, err
+		}
+	}
+
+	stored := map[string]storedMetric{}
+	if len(rawMetrics) > 0 {
+		if err := json.Unmarshal(rawMetrics, &stored); err != nil {
+			return GoalRevision{}, err
+		}
+	}
+
+	revision.Metrics = make(map[string]keyresult.Metric, len(stored))
+	for criterion, metric := range stored {
+		revision.Metrics[criterion] = keyresult.Metric(metric)
+	}
+
+	if restoredFrom.Valid {
+		value := int(restoredFrom.Int64)
+		revision.RestoredFrom = &value
+	}
+
+	return revision, nil
+}
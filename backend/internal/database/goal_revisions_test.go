@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+var goalRevisionRowColumns = []string{"goal_id", "revision", "title", "clarity_statement", "guardrails", "decision_rights", "constraints", "success_criteria", "metrics", "restored_from", "created_at"}
+
+func TestRestoreGoalRevision(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id := uuid.New()
+	now := time.Now().UTC()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goal_revisions WHERE goal_id = $1 AND revision = $2")).
+		WithArgs(id, 2).
+		WillReturnRows(sqlmock.NewRows(goalRevisionRowColumns).
+			AddRow(id, 2, "Reliable checkout", "Clarity", `["No Friday deploys"]`, `[]`, `[]`, `["Coverage above 80%"]`, `{"Coverage above 80%":{"baseline":60,"target":80,"unit":"%","direction":"increase"}}`, nil, now))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goal_revisions r")).
+		WithArgs(id, 2, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "clarity_statement", "guardrails", "decision_rights", "constraints", "success_criteria", "parent_goal_id", "created_at", "updated_at", "status", "closing_summary", "achieved_at", "tags"}).
+			AddRow(id, "Reliable checkout", "Clarity", `["No Friday deploys"]`, `[]`, `[]`, `["Coverage above 80%"]`, nil, now, now, GoalActive, nil, nil, nil))
+	mock.ExpectExec("INSERT INTO goal_criteria").
+		WithArgs(sqlmock.AnyArg(), id, "Coverage above 80%", 0, 60.0, 80.0, "%", "increase", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM goal_criteria")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO goal_revisions")).
+		WithArgs(id, "Reliable checkout", "Clarity", `["No Friday deploys"]`, `[]`, `[]`, `["Coverage above 80%"]`, `{"Coverage above 80%":{"baseline":60,"target":80,"unit":"%","direction":"increase"}}`, 2, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectWebhookEvent(mock, EventGoalUpdated)
+	mock.ExpectCommit()
+
+	goal, err := RestoreGoalRevision(context.Background(), db, id, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !slices.Equal(goal.Guardrails, []string{"No Friday deploys"}) {
+		t.Fatalf("expected restored guardrails got %v", goal.Guardrails)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestListGoalRevisionsUnknownGoal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goal_revisions WHERE goal_id = $1 ORDER BY revision")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows(goalRevisionRowColumns))
+
+	if _, err := ListGoalRevisions(context.Background(), db, id); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestDiffGoalRevisions(t *testing.T) {
+	from := GoalRevision{
+		Title:           "Checkout",
+		Guardrails:      []string{"Keep focus time", "No Friday deploys"},
+		SuccessCriteria: []string{"Retries cover checkout"},
+	}
+	to := GoalRevision{
+		Title:           "Reliable checkout",
+		Guardrails:      []string{"Keep focus time", "Deploy behind flags"},
+		SuccessCriteria: []string{"Retries cover checkout"},
+	}
+
+	changes := DiffGoalRevisions(from, to)
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes got %+v", changes)
+	}
+
+	if changes[0].Field != "title" || *changes[0].To != "Reliable checkout" {
+		t.Fatalf("unexpected title change %+v", changes[0])
+	}
+
+	if changes[1].Field != "guardrails" || !slices.Equal(changes[1].Added, []string{"Deploy behind flags"}) || !slices.Equal(changes[1].Removed, []string{"No Friday deploys"}) {
+		t.Fatalf("unexpected guardrails change %+v", changes[1])
+	}
+}
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// GoalTree is a goal with its chain of ancestors and its descendants.
+// Ancestors are ordered from the top-level goal down to the direct parent.
+type GoalTree struct {
+	Ancestors []Goal
+	Root      GoalTreeNode
+}
+
+// GoalTreeNode is a goal in a tree together with its own progress and the
+// rollup of its progress and that of its descendants.
+type GoalTreeNode struct {
+	Goal     Goal
+	Progress GoalProgress
+	Rollup   GoalProgress
+	Children []GoalTreeNode
+}
+
+// GetGoalTree returns the goal with its ancestors and descendants. It
+// returns sql.ErrNoRows when the goal does not exist.
+func GetGoalTree(ctx context.Context, db *sql.DB, id uuid.UUID) (GoalTree, error) {
+	if db == nil {
+		return GoalTree{}, errors.New("database handle is nil")
+	}
+
+	root, err := GetGoal(ctx, db, id)
+	if err != nil {
+		return GoalTree{}, err
+	}
+
+	const ancestorsQuery = `
+WITH RECURSIVE ancestors AS (
+    SELECT parent_goal_id AS ancestor_id, 1 AS depth FROM goals WHERE id = $1
+    UNION ALL
+    SELECT g.parent_goal_id, a.depth + 1 FROM goals g JOIN ancestors a ON g.id = a.ancestor_id
+)
+SELECT ` + prefixedGoalColumns + `
+FROM ancestors a
+JOIN goals g ON g.id = a.ancestor_id
+ORDER BY a.depth DESC
+`
+
+	ancestors, err := queryGoals(ctx, db, ancestorsQuery, id)
+	if err != nil {
+		return GoalTree{}, err
+	}
+
+	const descendantsQuery = `
+WITH RECURSIVE descendants AS (
+    SELECT id FROM goals WHERE parent_goal_id = $1
+    UNION
+    SELECT g.id FROM goals g JOIN descendants d ON g.parent_goal_id = d.id
+)
+SELECT ` + prefixedGoalColumns + `
+FROM descendants d
+JOIN goals g ON g.id = d.id
+ORDER BY g.created_at, g.id
+`
+
+	descendants, err := queryGoals(ctx, db, descendantsQuery, id)
+	if err != nil {
+		return GoalTree{}, err
+	}
+
+	goalIDs := make([]uuid.UUID, 0, len(descendants)+1)
+	goalIDs = append(goalIDs, root.ID)
+	for _, goal := range descendants {
+		goalIDs = append(goalIDs, goal.ID)
+	}
+
+	impacts, err := GetImpactMap(ctx, db, ImpactFilters{GoalIDs: goalIDs})
+	if err != nil {
+		return GoalTree{}, err
+	}
+
+	byID := make(map[uuid.UUID]GoalImpact, len(impacts))
+	for _, impact := range impacts {
+		byID[impact.GoalID] = impact
+	}
+
+	children := make(map[uuid.UUID][]Goal, len(descendants))
+	for _, goal := range descendants {
+		if goal.ParentGoalID != nil {
+			children[*goal.ParentGoalID] = append(children[*goal.ParentGoalID], goal)
+		}
+	}
+
+	var build func(goal Goal) GoalTreeNode
+	build = func(goal Goal) GoalTreeNode {
+		impact := byID[goal.ID]
+		node := GoalTreeNode{
+			Goal:     goal,
+			Progress: impact.Progress(),
+			Rollup:   impact.Rollup,
+			Children: make([]GoalTreeNode, 0, len(children[goal.ID])),
+		}
+		for _, child := range children[goal.ID] {
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+
+	return GoalTree{Ancestors: ancestors, Root: build(root)}, nil
+}
+
+func queryGoals(ctx context.Context, q queryer, query string, args ...any) ([]Goal, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	goals := make([]Goal, 0)
+	for rows.Next() {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			return nil, err
+		}
+		goals = append(goals, goal)
+	}
+
+	return goals, rows.Err()
+}
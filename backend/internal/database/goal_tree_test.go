@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestGetGoalTree(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	topID, parentID, id, childID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	now := time.Now().UTC()
+	columns := []string{"id", "title", "clarity_statement", "guardrails", "decision_rights", "constraints", "success_criteria", "parent_goal_id", "created_at", "updated_at", "status", "closing_summary", "achieved_at", "tags"}
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goals WHERE id = $1")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(id, "Checkout", "", `[]`, `[]`, `[]`, `["Retries"]`, parentID, now, now, "active", nil, nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM ancestors a")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(topID, "Revenue", "", `[]`, `[]`, `[]`, `[]`, nil, now, now, "active", nil, nil, nil).
+			AddRow(parentID, "Enterprise", "", `[]`, `[]`, `[]`, `[]`, topID, now, now, "active", nil, nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM descendants d")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(childID, "Retry budget", "", `[]`, `[]`, `[]`, `["Budget set"]`, id, now, now, "active", nil, nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goals g")).
+		WithArgs(nil, nil, nil, "{"+id.String()+","+childID.String()+"}").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_goal_id", "title", "success_criteria", "intents", "outcomes", "links", "sessions", "satisfied"}).
+			AddRow(id, parentID, "Checkout", `["Retries"]`, 2, 1, 0, 1, `["Retries"]`).
+			AddRow(childID, id, "Retry budget", `["Budget set"]`, 1, 0, 0, 1, `[]`))
+
+	tree, err := GetGoalTree(context.Background(), db, id)
+	if err != nil {
+		t.Fatalf("GetGoalTree returned error: %v", err)
+	}
+
+	if len(tree.Ancestors) != 2 || tree.Ancestors[0].ID != topID || tree.Ancestors[1].ID != parentID {
+		t.Fatalf("expected ancestors top first got %+v", tree.Ancestors)
+	}
+
+	if len(tree.Root.Children) != 1 || tree.Root.Children[0].Goal.ID != childID {
+		t.Fatalf("expected one child got %+v", tree.Root.Children)
+	}
+
+	if tree.Root.Progress.Intents != 2 || tree.Root.Rollup.Intents != 3 || tree.Root.Rollup.CriteriaTotal != 2 || tree.Root.Rollup.CriteriaSatisfied != 1 {
+		t.Fatalf("unexpected progress %+v rollup %+v", tree.Root.Progress, tree.Root.Rollup)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
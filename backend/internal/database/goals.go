@@ -6,13 +6,70 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/example/intent/backend/internal/keyresult"
 	"github.com/google/uuid"
 )
 
-// Goal represents a chapter-level objective that guides intents.
+// Goal statuses. Only active goals accept new intents; archived goals are
+// hidden from listings unless asked for.
+const (
+	GoalDraft    = "draft"
+	GoalActive   = "active"
+	GoalPaused   = "paused"
+	GoalAchieved = "achieved"
+	GoalArchived = "archived"
+)
+
+// goalTransitions lists the statuses each goal status may move to.
+var goalTransitions = map[string][]string{
+	GoalDraft:    {GoalActive, GoalArchived},
+	GoalActive:   {GoalPaused, GoalAchieved, GoalArchived},
+	GoalPaused:   {GoalActive, GoalArchived},
+	GoalAchieved: {GoalActive, GoalArchived},
+	GoalArchived: {},
+}
+
+// Choices for the child goals of a goal being deleted: move them up to the
+// deleted goal's parent, or delete the whole subtree.
+const (
+	GoalChildrenReparent = "reparent"
+	GoalChildrenCascade  = "cascade"
+)
+
+var (
+	// ErrParentGoalNotFound is returned when a goal names a parent that does
+	// not exist.
+	ErrParentGoalNotFound = errors.New("parent goal not found")
+	// ErrGoalCycle is returned when a parent would make a goal its own
+	// ancestor.
+	ErrGoalCycle = errors.New("a goal cannot be its own ancestor")
+	// ErrGoalHasChildren is returned when a goal with child goals is deleted
+	// without choosing what happens to them.
+	ErrGoalHasChildren = errors.New("goal has child goals; delete with children=reparent or children=cascade")
+	// ErrGoalTransition is returned when a goal cannot move to the requested
+	// status from its current one.
+	ErrGoalTransition = errors.New("goal status transition not allowed")
+	// ErrGoalNotActive is returned when linking an intent to a goal that is
+	// not active.
+	ErrGoalNotActive = errors.New("intents can only be linked to active goals")
+)
+
+// goalHierarchyLock is the advisory lock key serialising parent changes, so
+// that two concurrent moves cannot form a cycle between them.
+const goalHierarchyLock = 4_174_201_042
+
+// goalColumns lists the goal columns in the order scanGoal expects.
+const goalColumns = "id, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, parent_goal_id, created_at, updated_at, status, closing_summary, achieved_at, tags"
+
+// prefixedGoalColumns is goalColumns qualified with the g alias.
+const prefixedGoalColumns = "g.id, g.title, g.clarity_statement, g.guardrails, g.decision_rights, g.constraints, g.success_criteria, g.parent_goal_id, g.created_at, g.updated_at, g.status, g.closing_summary, g.achieved_at, g.tags"
+
+// Goal represents a chapter-level objective that guides intents. Goals may
+// decompose a parent goal, such as a strategic objective.
 type Goal struct {
 	ID               uuid.UUID
 	Title            string
@@ -21,11 +78,19 @@ type Goal struct {
 	DecisionRights   []string
 	Constraints      []string
 	SuccessCriteria  []string
+	ParentGoalID     *uuid.UUID
+	Status           string
+	ClosingSummary   string
+	AchievedAt       *time.Time
+	Tags             []string
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
 }
 
 // GoalInput captures the fields required to create or update a goal.
+// Metrics holds the metric of each measurable success criterion, keyed by the
+// criterion's text. Status sets the initial status of a new goal, active by
+// default, and is ignored on update. Tags replace the goal's current tags.
 type GoalInput struct {
 	Title            string
 	ClarityStatement string
@@ -33,19 +98,32 @@ type GoalInput struct {
 	DecisionRights   []string
 	Constraints      []string
 	SuccessCriteria  []string
+	Metrics          map[string]keyresult.Metric
+	ParentGoalID     *uuid.UUID
+	Status           string
+	Tags             []string
 }
 
 // GoalFilters captures optional filters applied when querying goals.
+// Archived goals are left out unless Status is GoalArchived or
+// IncludeArchived is set. Tags keeps goals carrying any of the tags, or all
+// of them when TagMatch is TagMatchAll.
 type GoalFilters struct {
-	Query         string
-	CreatedAfter  *time.Time
-	CreatedBefore *time.Time
+	Query           string
+	Status          string
+	IncludeArchived bool
+	CreatedAfter    *time.Time
+	CreatedBefore   *time.Time
+	Tags            []string
+	TagMatch        TagMatch
 }
 
-// GoalListResult represents the outcome of listing goals.
+// GoalListResult represents the outcome of listing goals. Facets count the
+// matching goals per tag and status.
 type GoalListResult struct {
 	Goals      []Goal
 	TotalCount int
+	Facets     Facets
 }
 
 // CreateGoal persists a new goal and returns the stored entity.
@@ -74,19 +152,45 @@ func CreateGoal(ctx context.Context, db *sql.DB, input GoalInput) (Goal, error)
 		return Goal{}, err
 	}
 
+	tagsJSON, err := json.Marshal(nonNilStrings(input.Tags))
+	if err != nil {
+		return Goal{}, err
+	}
+
 	now := time.Now().UTC()
 	id := uuid.New()
 
 	const query = `
-INSERT INTO goals (id, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+INSERT INTO goals (id, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, parent_goal_id, status, created_at, updated_at, tags)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 `
 
-	if _, err := db.ExecContext(ctx, query, id, input.Title, input.ClarityStatement, string(guardrailsJSON), string(decisionRightsJSON), string(constraintsJSON), string(successJSON), now, now); err != nil {
+	status := input.Status
+	if status == "" {
+		status = GoalActive
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Goal{}, err
+	}
+	defer tx.Rollback()
+
+	if input.ParentGoalID != nil {
+		if err := checkGoalParent(ctx, tx, id, *input.ParentGoalID); err != nil {
+			return Goal{}, err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, query, id, input.Title, input.ClarityStatement, string(guardrailsJSON), string(decisionRightsJSON), string(constraintsJSON), string(successJSON), uuidPtrValue(input.ParentGoalID), status, now, now, string(tagsJSON)); err != nil {
 		return Goal{}, err
 	}
 
-	return Goal{
+	if err := syncGoalCriteria(ctx, tx, id, input.SuccessCriteria, input.Metrics, now); err != nil {
+		return Goal{}, err
+	}
+
+	goal := Goal{
 		ID:               id,
 		Title:            input.Title,
 		ClarityStatement: input.ClarityStatement,
@@ -94,9 +198,26 @@ VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		DecisionRights:   input.DecisionRights,
 		Constraints:      input.Constraints,
 		SuccessCriteria:  input.SuccessCriteria,
+		ParentGoalID:     input.ParentGoalID,
+		Status:           status,
+		Tags:             nonNilStrings(input.Tags),
 		CreatedAt:        now,
 		UpdatedAt:        now,
-	}, nil
+	}
+
+	if err := recordGoalRevision(ctx, tx, goal, input.Metrics, nil, now); err != nil {
+		return Goal{}, err
+	}
+
+	if err := recordWebhookEvent(ctx, tx, EventGoalCreated, goalSnapshot(goal)); err != nil {
+		return Goal{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Goal{}, err
+	}
+
+	return goal, nil
 }
 
 // GetGoal retrieves a goal by identifier.
@@ -106,62 +227,17 @@ func GetGoal(ctx context.Context, db *sql.DB, id uuid.UUID) (Goal, error) {
 	}
 
 	const query = `
-SELECT id, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, created_at, updated_at
+SELECT ` + goalColumns + `
 FROM goals
 WHERE id = $1
 `
 
-	var (
-		goal            Goal
-		guardrailsJSON  []byte
-		decisionJSON    []byte
-		constraintsJSON []byte
-		successCriteria []byte
-	)
-
-	err := db.QueryRowContext(ctx, query, id).Scan(
-		&goal.ID,
-		&goal.Title,
-		&goal.ClarityStatement,
-		&guardrailsJSON,
-		&decisionJSON,
-		&constraintsJSON,
-		&successCriteria,
-		&goal.CreatedAt,
-		&goal.UpdatedAt,
-	)
-	if err != nil {
-		return Goal{}, err
-	}
-
-	if len(guardrailsJSON) > 0 {
-		if err := json.Unmarshal(guardrailsJSON, &goal.Guardrails); err != nil {
-			return Goal{}, err
-		}
-	}
-
-	if len(decisionJSON) > 0 {
-		if err := json.Unmarshal(decisionJSON, &goal.DecisionRights); err != nil {
-			return Goal{}, err
-		}
-	}
-
-	if len(constraintsJSON) > 0 {
-		if err := json.Unmarshal(constraintsJSON, &goal.Constraints); err != nil {
-			return Goal{}, err
-		}
-	}
-
-	if len(successCriteria) > 0 {
-		if err := json.Unmarshal(successCriteria, &goal.SuccessCriteria); err != nil {
-			return Goal{}, err
-		}
-	}
-
-	return goal, nil
+	return scanGoal(db.QueryRowContext(ctx, query, id))
 }
 
-// UpdateGoal updates an existing goal and returns the persisted entity.
+// UpdateGoal updates an existing goal, recording its content as a new
+// revision, and returns the persisted entity. A nil ParentGoalID makes the
+// goal top-level.
 func UpdateGoal(ctx context.Context, db *sql.DB, id uuid.UUID, input GoalInput) (Goal, error) {
 	if db == nil {
 		return Goal{}, errors.New("database handle is nil")
@@ -187,6 +263,11 @@ func UpdateGoal(ctx context.Context, db *sql.DB, id uuid.UUID, input GoalInput)
 		return Goal{}, err
 	}
 
+	tagsJSON, err := json.Marshal(nonNilStrings(input.Tags))
+	if err != nil {
+		return Goal{}, err
+	}
+
 	now := time.Now().UTC()
 
 	const query = `
@@ -197,70 +278,272 @@ SET title = $1,
     decision_rights = $4,
     constraints = $5,
     success_criteria = $6,
-    updated_at = $7
-WHERE id = $8
-RETURNING id, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, created_at, updated_at
+    parent_goal_id = $7,
+    tags = $8,
+    updated_at = $9
+WHERE id = $10
+RETURNING ` + goalColumns + `
 `
 
-	var (
-		goal           Goal
-		rawGuardrails  []byte
-		rawDecision    []byte
-		rawConstraints []byte
-		rawSuccess     []byte
-	)
-
-	err = db.QueryRowContext(ctx, query, input.Title, input.ClarityStatement, string(guardrailsJSON), string(decisionJSON), string(constraintsJSON), string(successJSON), now, id).Scan(
-		&goal.ID,
-		&goal.Title,
-		&goal.ClarityStatement,
-		&rawGuardrails,
-		&rawDecision,
-		&rawConstraints,
-		&rawSuccess,
-		&goal.CreatedAt,
-		&goal.UpdatedAt,
-	)
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return Goal{}, err
 	}
+	defer tx.Rollback()
 
-	if len(rawGuardrails) > 0 {
-		if err := json.Unmarshal(rawGuardrails, &goal.Guardrails); err != nil {
+	if input.ParentGoalID != nil {
+		if err := checkGoalParent(ctx, tx, id, *input.ParentGoalID); err != nil {
 			return Goal{}, err
 		}
 	}
 
-	if len(rawDecision) > 0 {
-		if err := json.Unmarshal(rawDecision, &goal.DecisionRights); err != nil {
-			return Goal{}, err
-		}
+	goal, err := scanGoal(tx.QueryRowContext(ctx, query, input.Title, input.ClarityStatement, string(guardrailsJSON), string(decisionJSON), string(constraintsJSON), string(successJSON), uuidPtrValue(input.ParentGoalID), string(tagsJSON), now, id))
+	if err != nil {
+		return Goal{}, err
 	}
 
-	if len(rawConstraints) > 0 {
-		if err := json.Unmarshal(rawConstraints, &goal.Constraints); err != nil {
-			return Goal{}, err
-		}
+	if err := syncGoalCriteria(ctx, tx, id, input.SuccessCriteria, input.Metrics, now); err != nil {
+		return Goal{}, err
 	}
 
-	if len(rawSuccess) > 0 {
-		if err := json.Unmarshal(rawSuccess, &goal.SuccessCriteria); err != nil {
-			return Goal{}, err
-		}
+	if err := recordGoalRevision(ctx, tx, goal, input.Metrics, nil, now); err != nil {
+		return Goal{}, err
+	}
+
+	if err := recordWebhookEvent(ctx, tx, EventGoalUpdated, goalSnapshot(goal)); err != nil {
+		return Goal{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Goal{}, err
 	}
 
 	return goal, nil
 }
 
-// DeleteGoal removes a goal by identifier.
-func DeleteGoal(ctx context.Context, db *sql.DB, id uuid.UUID) error {
+// TransitionGoal moves a goal to another status, returning ErrGoalTransition
+// when the move is not allowed. Achieving a goal records summary as its
+// closing summary; reopening an achieved goal clears it.
+func TransitionGoal(ctx context.Context, db *sql.DB, id uuid.UUID, status, summary string) (Goal, error) {
+	if db == nil {
+		return Goal{}, errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Goal{}, err
+	}
+	defer tx.Rollback()
+
+	var current string
+	if err := tx.QueryRowContext(ctx, `SELECT status FROM goals WHERE id = $1 FOR UPDATE`, id).Scan(&current); err != nil {
+		return Goal{}, err
+	}
+
+	if !slices.Contains(goalTransitions[current], status) {
+		return Goal{}, fmt.Errorf("%w: %s to %s", ErrGoalTransition, current, status)
+	}
+
+	const query = `
+UPDATE goals
+SET status = $1,
+    closing_summary = CASE $1 WHEN 'achieved' THEN $2 WHEN 'active' THEN NULL ELSE closing_summary END,
+    achieved_at = CASE $1 WHEN 'achieved' THEN $3 WHEN 'active' THEN NULL ELSE achieved_at END,
+    updated_at = $3
+WHERE id = $4
+RETURNING ` + goalColumns + `
+`
+
+	goal, err := scanGoal(tx.QueryRowContext(ctx, query, status, summary, time.Now().UTC(), id))
+	if err != nil {
+		return Goal{}, err
+	}
+
+	if err := recordWebhookEvent(ctx, tx, EventGoalUpdated, goalSnapshot(goal)); err != nil {
+		return Goal{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Goal{}, err
+	}
+
+	return goal, nil
+}
+
+// checkGoalActive returns ErrGoalNotActive unless the goal is active. A goal
+// that does not exist is left to the foreign key to reject.
+func checkGoalActive(ctx context.Context, q queryer, id uuid.UUID) error {
+	var status string
+	if err := q.QueryRowContext(ctx, `SELECT status FROM goals WHERE id = $1`, id).Scan(&status); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	if status != GoalActive {
+		return ErrGoalNotActive
+	}
+
+	return nil
+}
+
+// checkGoalParent verifies that parentID exists and that goalID is not among
+// its ancestors, which would close a cycle.
+func checkGoalParent(ctx context.Context, tx *sql.Tx, goalID, parentID uuid.UUID) error {
+	if err := lockGoalHierarchy(ctx, tx); err != nil {
+		return err
+	}
+
+	const query = `
+WITH RECURSIVE ancestors AS (
+    SELECT id, parent_goal_id FROM goals WHERE id = $1
+    UNION
+    SELECT g.id, g.parent_goal_id FROM goals g JOIN ancestors a ON g.id = a.parent_goal_id
+)
+SELECT COUNT(*) FILTER (WHERE id = $1), COUNT(*) FILTER (WHERE id = $2)
+FROM ancestors
+`
+
+	var found, cycle int
+	if err := tx.QueryRowContext(ctx, query, parentID, goalID).Scan(&found, &cycle); err != nil {
+		return err
+	}
+
+	if found == 0 {
+		return ErrParentGoalNotFound
+	}
+	if cycle > 0 {
+		return ErrGoalCycle
+	}
+
+	return nil
+}
+
+// lockGoalHierarchy takes the transaction-level lock every change to goal
+// parents holds, so the ancestor chain read by checkGoalParent cannot change
+// before the transaction commits.
+func lockGoalHierarchy(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, goalHierarchyLock)
+	return err
+}
+
+// DeleteGoal removes a goal by identifier. A goal with child goals is only
+// deleted when children is GoalChildrenReparent, which moves them to the
+// goal's parent, or GoalChildrenCascade, which deletes its descendants too.
+func DeleteGoal(ctx context.Context, db *sql.DB, id uuid.UUID, children string) error {
 	if db == nil {
 		return errors.New("database handle is nil")
 	}
 
-	const query = `DELETE FROM goals WHERE id = $1`
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Children counted here must not be moved away or gain siblings before
+	// they are reparented or deleted.
+	if err := lockGoalHierarchy(ctx, tx); err != nil {
+		return err
+	}
+
+	var childCount int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM goals WHERE parent_goal_id = $1`, id).Scan(&childCount); err != nil {
+		return err
+	}
+
+	deleted := []uuid.UUID{id}
+	switch {
+	case childCount == 0:
+		if err := deleteGoalRow(ctx, tx, id); err != nil {
+			return err
+		}
+	case children == GoalChildrenReparent:
+		const reparentQuery = `
+UPDATE goals
+SET parent_goal_id = (SELECT parent_goal_id FROM goals WHERE id = $1),
+    updated_at = $2
+WHERE parent_goal_id = $1
+RETURNING ` + goalColumns
+
+		rows, err := tx.QueryContext(ctx, reparentQuery, id, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+
+		moved := make([]Goal, 0, childCount)
+		for rows.Next() {
+			goal, err := scanGoal(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			moved = append(moved, goal)
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, goal := range moved {
+			if err := recordWebhookEvent(ctx, tx, EventGoalUpdated, goalSnapshot(goal)); err != nil {
+				return err
+			}
+		}
+
+		if err := deleteGoalRow(ctx, tx, id); err != nil {
+			return err
+		}
+	case children == GoalChildrenCascade:
+		const cascadeQuery = `
+WITH RECURSIVE subtree AS (
+    SELECT id FROM goals WHERE id = $1
+    UNION
+    SELECT g.id FROM goals g JOIN subtree s ON g.parent_goal_id = s.id
+)
+DELETE FROM goals
+WHERE id IN (SELECT id FROM subtree)
+RETURNING id
+`
+
+		rows, err := tx.QueryContext(ctx, cascadeQuery, id)
+		if err != nil {
+			return err
+		}
+
+		deleted = deleted[:0]
+		for rows.Next() {
+			var goalID uuid.UUID
+			if err := rows.Scan(&goalID); err != nil {
+				rows.Close()
+				return err
+			}
+			deleted = append(deleted, goalID)
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	default:
+		return ErrGoalHasChildren
+	}
+
+	for _, goalID := range deleted {
+		if err := recordWebhookEvent(ctx, tx, EventGoalDeleted, deletedResource{ID: goalID}); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
 
-	result, err := db.ExecContext(ctx, query, id)
+func deleteGoalRow(ctx context.Context, tx *sql.Tx, id uuid.UUID) error {
+	result, err := tx.ExecContext(ctx, `DELETE FROM goals WHERE id = $1`, id)
 	if err != nil {
 		return err
 	}
@@ -308,6 +591,21 @@ func ListGoals(ctx context.Context, db *sql.DB, filters GoalFilters, pagination
 		param++
 	}
 
+	if len(filters.Tags) > 0 {
+		conditions = append(conditions, tagCondition(filters.TagMatch, param))
+		args = append(args, textArrayLiteral(filters.Tags))
+		param++
+	}
+
+	switch {
+	case filters.Status != "":
+		conditions = append(conditions, fmt.Sprintf("status = $%d", param))
+		args = append(args, filters.Status)
+		param++
+	case !filters.IncludeArchived:
+		conditions = append(conditions, "status <> 'archived'")
+	}
+
 	whereClause := ""
 	if len(conditions) > 0 {
 		whereClause = " WHERE " + strings.Join(conditions, " AND ")
@@ -320,7 +618,7 @@ func ListGoals(ctx context.Context, db *sql.DB, filters GoalFilters, pagination
 		return GoalListResult{}, err
 	}
 
-	listQuery := "SELECT id, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, created_at, updated_at FROM goals" + whereClause + " ORDER BY created_at DESC"
+	listQuery := "SELECT " + goalColumns + " FROM goals" + whereClause + " ORDER BY created_at DESC"
 	listArgs := append([]any{}, args...)
 
 	if pagination.Limit > 0 {
@@ -342,58 +640,116 @@ func ListGoals(ctx context.Context, db *sql.DB, filters GoalFilters, pagination
 
 	goals := make([]Goal, 0)
 	for rows.Next() {
-		var (
-			goal           Goal
-			rawGuardrails  []byte
-			rawDecision    []byte
-			rawConstraints []byte
-			rawSuccess     []byte
-		)
-
-		if err := rows.Scan(
-			&goal.ID,
-			&goal.Title,
-			&goal.ClarityStatement,
-			&rawGuardrails,
-			&rawDecision,
-			&rawConstraints,
-			&rawSuccess,
-			&goal.CreatedAt,
-			&goal.UpdatedAt,
-		); err != nil {
+		goal, err := scanGoal(rows)
+		if err != nil {
 			return GoalListResult{}, err
 		}
 
-		if len(rawGuardrails) > 0 {
-			if err := json.Unmarshal(rawGuardrails, &goal.Guardrails); err != nil {
-				return GoalListResult{}, err
-			}
-		}
+		goals = append(goals, goal)
+	}
 
-		if len(rawDecision) > 0 {
-			if err := json.Unmarshal(rawDecision, &goal.DecisionRights); err != nil {
-				return GoalListResult{}, err
-			}
-		}
+	if err := rows.Err(); err != nil {
+		return GoalListResult{}, err
+	}
 
-		if len(rawConstraints) > 0 {
-			if err := json.Unmarshal(rawConstraints, &goal.Constraints); err != nil {
-				return GoalListResult{}, err
-			}
-		}
+	facets, err := listFacets(ctx, db, facetQuery("goals", whereClause, facetColumn{FacetStatus, "status"}), args)
+	if err != nil {
+		return GoalListResult{}, err
+	}
 
-		if len(rawSuccess) > 0 {
-			if err := json.Unmarshal(rawSuccess, &goal.SuccessCriteria); err != nil {
-				return GoalListResult{}, err
-			}
-		}
+	return GoalListResult{Goals: goals, TotalCount: total, Facets: facets}, nil
+}
 
-		goals = append(goals, goal)
+// scanGoal reads a row selected with goalColumns.
+func scanGoal(row rowScanner) (Goal, error) {
+	var (
+		goal           Goal
+		rawGuardrails  []byte
+		rawDecision    []byte
+		rawConstraints []byte
+		rawSuccess     []byte
+		parentGoalID   uuid.NullUUID
+		closingSummary sql.NullString
+		achievedAt     sql.NullTime
+		rawTags        []byte
+	)
+
+	if err := row.Scan(
+		&goal.ID,
+		&goal.Title,
+		&goal.ClarityStatement,
+		&rawGuardrails,
+		&rawDecision,
+		&rawConstraints,
+		&rawSuccess,
+		&parentGoalID,
+		&goal.CreatedAt,
+		&goal.UpdatedAt,
+		&goal.Status,
+		&closingSummary,
+		&achievedAt,
+		&rawTags,
+	); err != nil {
+		return Goal{}, err
 	}
 
-	if err := rows.Err(); err != nil {
-		return GoalListResult{}, err
+	for _, field := range []struct {
+		raw  []byte
+		dest *[]string
+	}{
+		{raw: rawGuardrails, dest: &goal.Guardrails},
+		{raw: rawDecision, dest: &goal.DecisionRights},
+		{raw: rawConstraints, dest: &goal.Constraints},
+		{raw: rawSuccess, dest: &goal.SuccessCriteria},
+		{raw: rawTags, dest: &goal.Tags},
+	} {
+		if len(field.raw) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(field.raw, field.dest); err != nil {
+			return Goal{}, err
+		}
 	}
 
-	return GoalListResult{Goals: goals, TotalCount: total}, nil
+	goal.Tags = nonNilStrings(goal.Tags)
+	goal.ParentGoalID = nullUUIDPtr(parentGoalID)
+	goal.ClosingSummary = closingSummary.String
+	goal.AchievedAt = nullTimePtr(achievedAt)
+
+	return goal, nil
+}
+
+// storedGoalSnapshot is the JSON shape used when freezing a goal.
+type storedGoalSnapshot struct {
+	ID               uuid.UUID  `json:"id"`
+	Title            string     `json:"title"`
+	ClarityStatement string     `json:"clarityStatement"`
+	Guardrails       []string   `json:"guardrails"`
+	DecisionRights   []string   `json:"decisionRights"`
+	Constraints      []string   `json:"constraints"`
+	SuccessCriteria  []string   `json:"successCriteria"`
+	ParentGoalID     *uuid.UUID `json:"parentGoalId"`
+	Status           string     `json:"status"`
+	ClosingSummary   string     `json:"closingSummary,omitempty"`
+	Tags             []string   `json:"tags"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+}
+
+func goalSnapshot(goal Goal) storedGoalSnapshot {
+	return storedGoalSnapshot{
+		ID:               goal.ID,
+		Title:            goal.Title,
+		ClarityStatement: goal.ClarityStatement,
+		Guardrails:       goal.Guardrails,
+		DecisionRights:   goal.DecisionRights,
+		Constraints:      goal.Constraints,
+		SuccessCriteria:  goal.SuccessCriteria,
+		ParentGoalID:     goal.ParentGoalID,
+		Status:           goal.Status,
+		ClosingSummary:   goal.ClosingSummary,
+		Tags:             goal.Tags,
+		CreatedAt:        goal.CreatedAt,
+		UpdatedAt:        goal.UpdatedAt,
+	}
 }
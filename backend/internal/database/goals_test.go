@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"regexp"
 	"testing"
 	"time"
@@ -11,6 +12,12 @@ import (
 	"github.com/google/uuid"
 )
 
+func expectGoalHierarchyLock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).
+		WithArgs(goalHierarchyLock).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
 func TestCreateGoalSuccess(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -27,9 +34,14 @@ func TestCreateGoalSuccess(t *testing.T) {
 		SuccessCriteria:  []string{"Zero Sev-1 incidents"},
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectExec("INSERT INTO goals").
-		WithArgs(sqlmock.AnyArg(), input.Title, input.ClarityStatement, `["Respect freeze"]`, `["Feature toggles"]`, `["Keep production stable"]`, `["Zero Sev-1 incidents"]`, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(sqlmock.AnyArg(), input.Title, input.ClarityStatement, `["Respect freeze"]`, `["Feature toggles"]`, `["Keep production stable"]`, `["Zero Sev-1 incidents"]`, nil, GoalActive, sqlmock.AnyArg(), sqlmock.AnyArg(), "[]").
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectGoalCriteriaSync(mock, 1)
+	expectGoalRevision(mock)
+	expectWebhookEvent(mock, EventGoalCreated)
+	mock.ExpectCommit()
 
 	goal, err := CreateGoal(context.Background(), db, input)
 	if err != nil {
@@ -53,6 +65,10 @@ func TestCreateGoalSuccess(t *testing.T) {
 	}
 }
 
+func expectGoalRevision(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO goal_revisions")).WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
 func TestGetGoalSuccess(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -64,10 +80,10 @@ func TestGetGoalSuccess(t *testing.T) {
 	createdAt := time.Now().UTC()
 	updatedAt := createdAt.Add(time.Hour)
 
-	rows := sqlmock.NewRows([]string{"id", "title", "clarity_statement", "guardrails", "decision_rights", "constraints", "success_criteria", "created_at", "updated_at"}).
-		AddRow(id, "Goal", "Clarity", `["Guardrail"]`, `["Delegate"]`, `["Guardrail"]`, `["Outcome"]`, createdAt, updatedAt)
+	rows := sqlmock.NewRows([]string{"id", "title", "clarity_statement", "guardrails", "decision_rights", "constraints", "success_criteria", "parent_goal_id", "created_at", "updated_at", "status", "closing_summary", "achieved_at", "tags"}).
+		AddRow(id, "Goal", "Clarity", `["Guardrail"]`, `["Delegate"]`, `["Guardrail"]`, `["Outcome"]`, nil, createdAt, updatedAt, "active", nil, nil, nil)
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, created_at, updated_at FROM goals WHERE id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, parent_goal_id, created_at, updated_at, status, closing_summary, achieved_at, tags FROM goals WHERE id = $1")).
 		WithArgs(id).
 		WillReturnRows(rows)
 
@@ -110,7 +126,7 @@ func TestGetGoalNotFound(t *testing.T) {
 
 	id := uuid.New()
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, created_at, updated_at FROM goals WHERE id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, parent_goal_id, created_at, updated_at, status, closing_summary, achieved_at, tags FROM goals WHERE id = $1")).
 		WithArgs(id).
 		WillReturnError(sql.ErrNoRows)
 
@@ -143,6 +159,7 @@ func TestUpdateGoalSuccess(t *testing.T) {
 		SuccessCriteria:  []string{"Handbook updated"},
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE goals
 SET title = $1,
     clarity_statement = $2,
@@ -150,12 +167,18 @@ SET title = $1,
     decision_rights = $4,
     constraints = $5,
     success_criteria = $6,
-    updated_at = $7
-WHERE id = $8
-RETURNING id, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, created_at, updated_at`)).
-		WithArgs(input.Title, input.ClarityStatement, `["Timebox experiments"]`, `["Empower pairing"]`, `["Stay within budget"]`, `["Handbook updated"]`, sqlmock.AnyArg(), id).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "clarity_statement", "guardrails", "decision_rights", "constraints", "success_criteria", "created_at", "updated_at"}).
-			AddRow(id, input.Title, input.ClarityStatement, `["Timebox experiments"]`, `["Empower pairing"]`, `["Stay within budget"]`, `["Handbook updated"]`, createdAt, updatedAt))
+    parent_goal_id = $7,
+    tags = $8,
+    updated_at = $9
+WHERE id = $10
+RETURNING id, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, parent_goal_id, created_at, updated_at, status, closing_summary, achieved_at, tags`)).
+		WithArgs(input.Title, input.ClarityStatement, `["Timebox experiments"]`, `["Empower pairing"]`, `["Stay within budget"]`, `["Handbook updated"]`, nil, "[]", sqlmock.AnyArg(), id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "clarity_statement", "guardrails", "decision_rights", "constraints", "success_criteria", "parent_goal_id", "created_at", "updated_at", "status", "closing_summary", "achieved_at", "tags"}).
+			AddRow(id, input.Title, input.ClarityStatement, `["Timebox experiments"]`, `["Empower pairing"]`, `["Stay within budget"]`, `["Handbook updated"]`, nil, createdAt, updatedAt, "active", nil, nil, nil))
+	expectGoalCriteriaSync(mock, 1)
+	expectGoalRevision(mock)
+	expectWebhookEvent(mock, EventGoalUpdated)
+	mock.ExpectCommit()
 
 	goal, err := UpdateGoal(context.Background(), db, id, input)
 	if err != nil {
@@ -180,11 +203,16 @@ func TestDeleteGoal(t *testing.T) {
 
 	id := uuid.New()
 
+	mock.ExpectBegin()
+	expectGoalHierarchyLock(mock)
+	expectGoalChildCount(mock, id, 0)
 	mock.ExpectExec("DELETE FROM goals").
 		WithArgs(id).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectWebhookEvent(mock, EventGoalDeleted)
+	mock.ExpectCommit()
 
-	if err := DeleteGoal(context.Background(), db, id); err != nil {
+	if err := DeleteGoal(context.Background(), db, id, ""); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
@@ -202,11 +230,15 @@ func TestDeleteGoalNotFound(t *testing.T) {
 
 	id := uuid.New()
 
+	mock.ExpectBegin()
+	expectGoalHierarchyLock(mock)
+	expectGoalChildCount(mock, id, 0)
 	mock.ExpectExec("DELETE FROM goals").
 		WithArgs(id).
 		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
 
-	if err := DeleteGoal(context.Background(), db, id); err != sql.ErrNoRows {
+	if err := DeleteGoal(context.Background(), db, id, ""); err != sql.ErrNoRows {
 		t.Fatalf("expected sql.ErrNoRows got %v", err)
 	}
 
@@ -215,6 +247,156 @@ func TestDeleteGoalNotFound(t *testing.T) {
 	}
 }
 
+func TestDeleteGoalWithChildrenRequiresChoice(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	expectGoalHierarchyLock(mock)
+	expectGoalChildCount(mock, id, 2)
+	mock.ExpectRollback()
+
+	if err := DeleteGoal(context.Background(), db, id, ""); err != ErrGoalHasChildren {
+		t.Fatalf("expected ErrGoalHasChildren got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestDeleteGoalReparentsChildren(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id, grandparentID, childID := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now().UTC()
+
+	mock.ExpectBegin()
+	expectGoalHierarchyLock(mock)
+	expectGoalChildCount(mock, id, 1)
+	mock.ExpectQuery(regexp.QuoteMeta("SET parent_goal_id = (SELECT parent_goal_id FROM goals WHERE id = $1)")).
+		WithArgs(id, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "clarity_statement", "guardrails", "decision_rights", "constraints", "success_criteria", "parent_goal_id", "created_at", "updated_at", "status", "closing_summary", "achieved_at", "tags"}).
+			AddRow(childID, "Child", "", `[]`, `[]`, `[]`, `[]`, grandparentID, now, now, "active", nil, nil, nil))
+	expectWebhookEvent(mock, EventGoalUpdated)
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM goals WHERE id = $1")).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectWebhookEvent(mock, EventGoalDeleted)
+	mock.ExpectCommit()
+
+	if err := DeleteGoal(context.Background(), db, id, GoalChildrenReparent); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestDeleteGoalCascadesToDescendants(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id, childID, grandchildID := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	expectGoalHierarchyLock(mock)
+	expectGoalChildCount(mock, id, 1)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE id IN (SELECT id FROM subtree)")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id).AddRow(childID).AddRow(grandchildID))
+	expectWebhookEvent(mock, EventGoalDeleted)
+	expectWebhookEvent(mock, EventGoalDeleted)
+	expectWebhookEvent(mock, EventGoalDeleted)
+	mock.ExpectCommit()
+
+	if err := DeleteGoal(context.Background(), db, id, GoalChildrenCascade); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestUpdateGoalRejectsCycle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id, descendantID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	expectGoalHierarchyLock(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("WITH RECURSIVE ancestors")).
+		WithArgs(descendantID, id).
+		WillReturnRows(sqlmock.NewRows([]string{"found", "cycle"}).AddRow(1, 1))
+	mock.ExpectRollback()
+
+	_, err = UpdateGoal(context.Background(), db, id, GoalInput{Title: "Loop", ParentGoalID: &descendantID})
+	if err != ErrGoalCycle {
+		t.Fatalf("expected ErrGoalCycle got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestCreateGoalRejectsUnknownParent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	parentID := uuid.New()
+
+	mock.ExpectBegin()
+	expectGoalHierarchyLock(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("WITH RECURSIVE ancestors")).
+		WithArgs(parentID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"found", "cycle"}).AddRow(0, 0))
+	mock.ExpectRollback()
+
+	_, err = CreateGoal(context.Background(), db, GoalInput{Title: "Orphan", ParentGoalID: &parentID})
+	if err != ErrParentGoalNotFound {
+		t.Fatalf("expected ErrParentGoalNotFound got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func expectGoalCriteriaSync(mock sqlmock.Sqlmock, criteria int) {
+	for i := 0; i < criteria; i++ {
+		mock.ExpectExec("INSERT INTO goal_criteria").WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM goal_criteria")).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectGoalChildCount(mock sqlmock.Sqlmock, id uuid.UUID, count int) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM goals WHERE parent_goal_id = $1")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+}
+
 func TestListGoalsWithFilters(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -231,14 +413,18 @@ func TestListGoalsWithFilters(t *testing.T) {
 	updatedAt := now
 	pattern := "%Clarity%"
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM goals WHERE (title ILIKE $1 OR clarity_statement ILIKE $2 OR guardrails::text ILIKE $3 OR decision_rights::text ILIKE $4 OR success_criteria::text ILIKE $5 OR constraints::text ILIKE $6) AND created_at >= $7")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM goals WHERE (title ILIKE $1 OR clarity_statement ILIKE $2 OR guardrails::text ILIKE $3 OR decision_rights::text ILIKE $4 OR success_criteria::text ILIKE $5 OR constraints::text ILIKE $6) AND created_at >= $7 AND status <> 'archived'")).
 		WithArgs(pattern, pattern, pattern, pattern, pattern, pattern, now).
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, created_at, updated_at FROM goals WHERE (title ILIKE $1 OR clarity_statement ILIKE $2 OR guardrails::text ILIKE $3 OR decision_rights::text ILIKE $4 OR success_criteria::text ILIKE $5 OR constraints::text ILIKE $6) AND created_at >= $7 ORDER BY created_at DESC LIMIT $8 OFFSET $9")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, parent_goal_id, created_at, updated_at, status, closing_summary, achieved_at, tags FROM goals WHERE (title ILIKE $1 OR clarity_statement ILIKE $2 OR guardrails::text ILIKE $3 OR decision_rights::text ILIKE $4 OR success_criteria::text ILIKE $5 OR constraints::text ILIKE $6) AND created_at >= $7 AND status <> 'archived' ORDER BY created_at DESC LIMIT $8 OFFSET $9")).
 		WithArgs(pattern, pattern, pattern, pattern, pattern, pattern, now, pagination.Limit, pagination.Offset).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "clarity_statement", "guardrails", "decision_rights", "constraints", "success_criteria", "created_at", "updated_at"}).
-			AddRow(id, "Goal", "Clarity", `["Guardrail"]`, `["Decide"]`, `["Constraint"]`, `["Outcome"]`, createdAt, updatedAt))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "clarity_statement", "guardrails", "decision_rights", "constraints", "success_criteria", "parent_goal_id", "created_at", "updated_at", "status", "closing_summary", "achieved_at", "tags"}).
+			AddRow(id, "Goal", "Clarity", `["Guardrail"]`, `["Decide"]`, `["Constraint"]`, `["Outcome"]`, nil, createdAt, updatedAt, "active", nil, nil, nil))
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT 'status', COALESCE(status::text, ''), COUNT(*) FROM goals WHERE (title ILIKE $1")).
+		WithArgs(pattern, pattern, pattern, pattern, pattern, pattern, now).
+		WillReturnRows(sqlmock.NewRows([]string{"facet", "value", "count"}).AddRow(FacetStatus, GoalActive, 1))
 
 	result, err := ListGoals(context.Background(), db, filters, pagination)
 	if err != nil {
@@ -257,3 +443,101 @@ func TestListGoalsWithFilters(t *testing.T) {
 		t.Fatalf("expectations: %v", err)
 	}
 }
+
+func TestListGoalsByStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM goals WHERE status = $1")).
+		WithArgs(GoalArchived).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goals WHERE status = $1 ORDER BY created_at DESC")).
+		WithArgs(GoalArchived).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goals WHERE status = $1 GROUP BY status")).
+		WithArgs(GoalArchived).
+		WillReturnRows(sqlmock.NewRows([]string{"facet", "value", "count"}))
+
+	if _, err := ListGoals(context.Background(), db, GoalFilters{Status: GoalArchived}, Pagination{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM goals")).
+		WithArgs().
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goals ORDER BY created_at DESC")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goals GROUP BY status")).
+		WillReturnRows(sqlmock.NewRows([]string{"facet", "value", "count"}))
+
+	if _, err := ListGoals(context.Background(), db, GoalFilters{IncludeArchived: true}, Pagination{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestTransitionGoalRecordsClosingSummary(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id := uuid.New()
+	now := time.Now().UTC()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM goals WHERE id = $1 FOR UPDATE")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(GoalActive))
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE goals")).
+		WithArgs(GoalAchieved, "Checkout retries shipped", sqlmock.AnyArg(), id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "clarity_statement", "guardrails", "decision_rights", "constraints", "success_criteria", "parent_goal_id", "created_at", "updated_at", "status", "closing_summary", "achieved_at", "tags"}).
+			AddRow(id, "Reliable checkout", "", `[]`, `[]`, `[]`, `[]`, nil, now, now, GoalAchieved, "Checkout retries shipped", now, nil))
+	expectWebhookEvent(mock, EventGoalUpdated)
+	mock.ExpectCommit()
+
+	goal, err := TransitionGoal(context.Background(), db, id, GoalAchieved, "Checkout retries shipped")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if goal.Status != GoalAchieved || goal.ClosingSummary != "Checkout retries shipped" || goal.AchievedAt == nil {
+		t.Fatalf("expected achieved goal with summary got %+v", goal)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestTransitionGoalRejectsArchivedGoal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM goals WHERE id = $1 FOR UPDATE")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(GoalArchived))
+	mock.ExpectRollback()
+
+	_, err = TransitionGoal(context.Background(), db, id, GoalActive, "")
+	if !errors.Is(err, ErrGoalTransition) {
+		t.Fatalf("expected ErrGoalTransition got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
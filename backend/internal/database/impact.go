@@ -0,0 +1,208 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImpactFilters scope the impact map to intents planned for sessions of a
+// chapter or starting in a date range. Without filters every intent counts.
+// GoalIDs restricts the goals returned; rollups only cover those goals.
+type ImpactFilters struct {
+	ChapterID *uuid.UUID
+	From      *time.Time
+	To        *time.Time
+	GoalIDs   []uuid.UUID
+}
+
+// GoalImpact rolls up what the intents serving a goal produced: their
+// outcomes, the evidence links they carry, the sessions involved and the
+// success criteria their outcomes satisfied. Rollup adds the progress of
+// every descendant goal to the goal's own.
+type GoalImpact struct {
+	GoalID            uuid.UUID
+	ParentGoalID      *uuid.UUID
+	Title             string
+	Intents           int
+	Outcomes          int
+	EvidenceLinks     int
+	Sessions          int
+	SuccessCriteria   []string
+	SatisfiedCriteria []string
+	Rollup            GoalProgress
+}
+
+// GoalProgress sums the measurable progress of one or more goals.
+type GoalProgress struct {
+	Intents           int
+	Outcomes          int
+	EvidenceLinks     int
+	CriteriaTotal     int
+	CriteriaSatisfied int
+}
+
+// Ratio is the share of success criteria satisfied, or zero without any.
+func (p GoalProgress) Ratio() float64 {
+	if p.CriteriaTotal == 0 {
+		return 0
+	}
+	return float64(p.CriteriaSatisfied) / float64(p.CriteriaTotal)
+}
+
+// Progress returns the goal's own progress, excluding its descendants.
+func (g GoalImpact) Progress() GoalProgress {
+	return GoalProgress{
+		Intents:           g.Intents,
+		Outcomes:          g.Outcomes,
+		EvidenceLinks:     g.EvidenceLinks,
+		CriteriaTotal:     len(g.SuccessCriteria),
+		CriteriaSatisfied: len(g.SatisfiedCriteria),
+	}
+}
+
+func (p GoalProgress) add(other GoalProgress) GoalProgress {
+	return GoalProgress{
+		Intents:           p.Intents + other.Intents,
+		Outcomes:          p.Outcomes + other.Outcomes,
+		EvidenceLinks:     p.EvidenceLinks + other.EvidenceLinks,
+		CriteriaTotal:     p.CriteriaTotal + other.CriteriaTotal,
+		CriteriaSatisfied: p.CriteriaSatisfied + other.CriteriaSatisfied,
+	}
+}
+
+// GetImpactMap returns the impact of every goal, oldest goal first. Only
+// criteria the goal still lists count as satisfied.
+func GetImpactMap(ctx context.Context, db *sql.DB, filters ImpactFilters) ([]GoalImpact, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	const query = `
+WITH scoped AS (
+    SELECT i.id, i.goal_id, i.session_id
+    FROM intents i
+    LEFT JOIN sessions s ON s.id = i.session_id
+    WHERE i.goal_id IS NOT NULL
+      AND ($1::uuid IS NULL OR s.chapter_id = $1)
+      AND ($2::timestamptz IS NULL OR s.starts_at >= $2)
+      AND ($3::timestamptz IS NULL OR s.starts_at <= $3)
+),
+outcomes AS (
+    SELECT scoped.goal_id, o.session_id, o.satisfied_criteria
+    FROM session_outcomes o
+    JOIN scoped ON scoped.id = o.intent_id
+)
+SELECT g.id, g.parent_goal_id, g.title, g.success_criteria,
+       (SELECT COUNT(*) FROM scoped WHERE scoped.goal_id = g.id),
+       (SELECT COUNT(*) FROM outcomes WHERE outcomes.goal_id = g.id),
+       (SELECT COUNT(*) FROM intent_links l JOIN scoped ON scoped.id = l.intent_id WHERE scoped.goal_id = g.id),
+       (SELECT COUNT(DISTINCT session_id) FROM (
+            SELECT session_id FROM scoped WHERE scoped.goal_id = g.id
+            UNION ALL
+            SELECT session_id FROM outcomes WHERE outcomes.goal_id = g.id
+        ) involved),
+       COALESCE((SELECT jsonb_agg(DISTINCT criterion)
+                 FROM outcomes, jsonb_array_elements_text(outcomes.satisfied_criteria) criterion
+                 WHERE outcomes.goal_id = g.id), '[]'::jsonb)
+FROM goals g
+WHERE $4::uuid[] IS NULL OR g.id = ANY($4::uuid[])
+ORDER BY g.created_at, g.id
+`
+
+	var goalIDs any
+	if filters.GoalIDs != nil {
+		goalIDs = uuidArrayLiteral(filters.GoalIDs)
+	}
+
+	rows, err := db.QueryContext(ctx, query, uuidPtrValue(filters.ChapterID), timePtrValue(filters.From), timePtrValue(filters.To), goalIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	impacts := make([]GoalImpact, 0)
+	for rows.Next() {
+		var (
+			impact        GoalImpact
+			parentGoalID  uuid.NullUUID
+			criteriaJSON  []byte
+			satisfiedJSON []byte
+			cited         []string
+		)
+
+		if err := rows.Scan(&impact.GoalID, &parentGoalID, &impact.Title, &criteriaJSON, &impact.Intents, &impact.Outcomes, &impact.EvidenceLinks, &impact.Sessions, &satisfiedJSON); err != nil {
+			return nil, err
+		}
+
+		impact.ParentGoalID = nullUUIDPtr(parentGoalID)
+
+		impact.SuccessCriteria = []string{}
+		if len(criteriaJSON) > 0 {
+			if err := json.Unmarshal(criteriaJSON, &impact.SuccessCriteria); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := json.Unmarshal(satisfiedJSON, &cited); err != nil {
+			return nil, err
+		}
+
+		satisfied := make(map[string]struct{}, len(cited))
+		for _, criterion := range cited {
+			satisfied[criterion] = struct{}{}
+		}
+
+		impact.SatisfiedCriteria = []string{}
+		for _, criterion := range impact.SuccessCriteria {
+			if _, ok := satisfied[criterion]; ok {
+				impact.SatisfiedCriteria = append(impact.SatisfiedCriteria, criterion)
+			}
+		}
+
+		impacts = append(impacts, impact)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rollUpImpacts(impacts)
+
+	return impacts, nil
+}
+
+// rollUpImpacts sets each goal's Rollup to its own progress plus that of
+// every descendant present in impacts.
+func rollUpImpacts(impacts []GoalImpact) {
+	children := make(map[uuid.UUID][]int, len(impacts))
+	for i, impact := range impacts {
+		if impact.ParentGoalID != nil {
+			children[*impact.ParentGoalID] = append(children[*impact.ParentGoalID], i)
+		}
+	}
+
+	done := make(map[int]bool, len(impacts))
+	var roll func(i int) GoalProgress
+	roll = func(i int) GoalProgress {
+		if done[i] {
+			return impacts[i].Rollup
+		}
+		done[i] = true
+
+		total := impacts[i].Progress()
+		for _, child := range children[impacts[i].GoalID] {
+			total = total.add(roll(child))
+		}
+		impacts[i].Rollup = total
+		return total
+	}
+
+	for i := range impacts {
+		roll(i)
+	}
+}
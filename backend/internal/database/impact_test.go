@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestGetImpactMapCountsCurrentCriteriaOnly(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	chapterID, goalID, idleGoalID := uuid.New(), uuid.New(), uuid.New()
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goals g")).
+		WithArgs(chapterID, from, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_goal_id", "title", "success_criteria", "intents", "outcomes", "links", "sessions", "satisfied"}).
+			AddRow(goalID, nil, "Reliable checkout", `["Retries cover checkout","Error rate under 1%"]`, 3, 2, 4, 2, `["Dropped criterion","Retries cover checkout"]`).
+			AddRow(idleGoalID, nil, "Faster builds", `[]`, 0, 0, 0, 0, `[]`))
+
+	impacts, err := GetImpactMap(context.Background(), db, ImpactFilters{ChapterID: &chapterID, From: &from})
+	if err != nil {
+		t.Fatalf("GetImpactMap returned error: %v", err)
+	}
+
+	if len(impacts) != 2 {
+		t.Fatalf("expected 2 goals got %d", len(impacts))
+	}
+
+	impact := impacts[0]
+	if impact.Intents != 3 || impact.Outcomes != 2 || impact.EvidenceLinks != 4 || impact.Sessions != 2 {
+		t.Fatalf("unexpected counts %+v", impact)
+	}
+
+	if len(impact.SatisfiedCriteria) != 1 || impact.SatisfiedCriteria[0] != "Retries cover checkout" {
+		t.Fatalf("expected only the current criterion to count got %v", impact.SatisfiedCriteria)
+	}
+
+	if impacts[1].SatisfiedCriteria == nil || len(impacts[1].SatisfiedCriteria) != 0 {
+		t.Fatalf("expected no satisfied criteria got %v", impacts[1].SatisfiedCriteria)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestGetImpactMapRollsUpDescendants(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	rootID, childID, grandchildID := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goals g")).
+		WithArgs(nil, nil, nil, "{"+rootID.String()+","+childID.String()+","+grandchildID.String()+"}").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_goal_id", "title", "success_criteria", "intents", "outcomes", "links", "sessions", "satisfied"}).
+			AddRow(rootID, nil, "Win enterprise", `["Two logos"]`, 1, 0, 0, 1, `[]`).
+			AddRow(childID, rootID, "Reliable checkout", `["Retries"]`, 2, 1, 3, 1, `["Retries"]`).
+			AddRow(grandchildID, childID, "Retry budget", `["Budget set","Alerts wired"]`, 1, 1, 1, 1, `["Budget set"]`))
+
+	impacts, err := GetImpactMap(context.Background(), db, ImpactFilters{GoalIDs: []uuid.UUID{rootID, childID, grandchildID}})
+	if err != nil {
+		t.Fatalf("GetImpactMap returned error: %v", err)
+	}
+
+	want := GoalProgress{Intents: 4, Outcomes: 2, EvidenceLinks: 4, CriteriaTotal: 4, CriteriaSatisfied: 2}
+	if impacts[0].Rollup != want {
+		t.Fatalf("expected root rollup %+v got %+v", want, impacts[0].Rollup)
+	}
+
+	if impacts[0].Rollup.Ratio() != 0.5 {
+		t.Fatalf("expected ratio 0.5 got %v", impacts[0].Rollup.Ratio())
+	}
+
+	if impacts[1].Rollup.Intents != 3 || impacts[2].Rollup != impacts[2].Progress() {
+		t.Fatalf("unexpected descendant rollups %+v %+v", impacts[1].Rollup, impacts[2].Rollup)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrGuardrailsNotAcknowledged is returned when an intent is linked to a
+	// goal with guardrails without acknowledging them.
+	ErrGuardrailsNotAcknowledged = errors.New("the guardrails of the linked goal must be acknowledged")
+	// ErrIntentHasNoGoal is returned when acknowledging guardrails for an
+	// intent that serves no goal.
+	ErrIntentHasNoGoal = errors.New("intent is not linked to a goal")
+)
+
+// guardrailsUnacknowledged matches an intent, named intents, whose goal g has
+// guardrails that none of the intent's acknowledgments cover.
+const guardrailsUnacknowledged = `jsonb_array_length(g.guardrails) > 0 AND NOT EXISTS (
+    SELECT 1 FROM intent_guardrail_acknowledgments a
+    WHERE a.intent_id = intents.id AND a.goal_id = g.id AND a.guardrails = g.guardrails
+)`
+
+// GuardrailAcknowledgment records the guardrails of a goal, at a revision,
+// that an intent's owner accepted.
+type GuardrailAcknowledgment struct {
+	ID             uuid.UUID
+	IntentID       uuid.UUID
+	GoalID         uuid.UUID
+	GoalRevision   int
+	Guardrails     []string
+	AcknowledgedAt time.Time
+}
+
+// IntentGuardrails is the guardrail state of an intent: its latest
+// acknowledgment, if any, and whether the goal's current guardrails still
+// need acknowledging.
+type IntentGuardrails struct {
+	Latest              *GuardrailAcknowledgment
+	NeedsAcknowledgment bool
+}
+
+// checkIntentGoal returns ErrGoalNotActive unless the goal is active and,
+// when requireAcknowledgment is set, ErrGuardrailsNotAcknowledged if the
+// goal has guardrails. A goal that does not exist is left to the foreign key
+// to reject.
+func checkIntentGoal(ctx context.Context, q queryer, goalID uuid.UUID, requireAcknowledgment bool) error {
+	var (
+		status     string
+		guardrails int
+	)
+	if err := q.QueryRowContext(ctx, `SELECT status, jsonb_array_length(guardrails) FROM goals WHERE id = $1`, goalID).Scan(&status, &guardrails); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	if status != GoalActive {
+		return ErrGoalNotActive
+	}
+
+	if requireAcknowledgment && guardrails > 0 {
+		return ErrGuardrailsNotAcknowledged
+	}
+
+	return nil
+}
+
+// recordGuardrailAcknowledgment stores the goal's current guardrails and
+// latest revision as acknowledged for the intent.
+func recordGuardrailAcknowledgment(ctx context.Context, q queryer, intentID, goalID uuid.UUID, now time.Time) (GuardrailAcknowledgment, error) {
+	const query = `
+INSERT INTO intent_guardrail_acknowledgments (id, intent_id, goal_id, goal_revision, guardrails, acknowledged_at)
+SELECT $1, $2, g.id, COALESCE((SELECT MAX(revision) FROM goal_revisions WHERE goal_id = g.id), 1), g.guardrails, $4
+FROM goals g
+WHERE g.id = $3
+RETURNING goal_revision, guardrails
+`
+
+	acknowledgment := GuardrailAcknowledgment{
+		ID:             uuid.New(),
+		IntentID:       intentID,
+		GoalID:         goalID,
+		AcknowledgedAt: now,
+	}
+
+	var rawGuardrails []byte
+	if err := q.QueryRowContext(ctx, query, acknowledgment.ID, intentID, goalID, now).Scan(&acknowledgment.GoalRevision, &rawGuardrails); err != nil {
+		return GuardrailAcknowledgment{}, err
+	}
+
+	if err := json.Unmarshal(rawGuardrails, &acknowledgment.Guardrails); err != nil {
+		return GuardrailAcknowledgment{}, err
+	}
+
+	return acknowledgment, nil
+}
+
+// AcknowledgeIntentGuardrails records that the intent's owner accepted the
+// current guardrails of its goal. It returns sql.ErrNoRows when the intent
+// does not exist and ErrIntentHasNoGoal when it serves no goal.
+func AcknowledgeIntentGuardrails(ctx context.Context, db *sql.DB, intentID uuid.UUID) (GuardrailAcknowledgment, error) {
+	if db == nil {
+		return GuardrailAcknowledgment{}, errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return GuardrailAcknowledgment{}, err
+	}
+	defer tx.Rollback()
+
+	var goalID uuid.NullUUID
+	if err := tx.QueryRowContext(ctx, `SELECT goal_id FROM intents WHERE id = $1 FOR UPDATE`, intentID).Scan(&goalID); err != nil {
+		return GuardrailAcknowledgment{}, err
+	}
+
+	if !goalID.Valid {
+		return GuardrailAcknowledgment{}, ErrIntentHasNoGoal
+	}
+
+	acknowledgment, err := recordGuardrailAcknowledgment(ctx, tx, intentID, goalID.UUID, time.Now().UTC())
+	if err != nil {
+		return GuardrailAcknowledgment{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return GuardrailAcknowledgment{}, err
+	}
+
+	return acknowledgment, nil
+}
+
+// ListIntentGuardrails returns the guardrail state of each intent that
+// serves a goal, keyed by intent.
+func ListIntentGuardrails(ctx context.Context, db *sql.DB, intentIDs []uuid.UUID) (map[uuid.UUID]IntentGuardrails, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	states := make(map[uuid.UUID]IntentGuardrails, len(intentIDs))
+	if len(intentIDs) == 0 {
+		return states, nil
+	}
+
+	const query = `
+SELECT intents.id, ` + guardrailsUnacknowledged + `,
+       latest.id, latest.goal_id, latest.goal_revision, latest.guardrails, latest.acknowledged_at
+FROM intents
+JOIN goals g ON g.id = intents.goal_id
+LEFT JOIN LATERAL (
+    SELECT id, goal_id, goal_revision, guardrails, acknowledged_at FROM intent_guardrail_acknowledgments a
+    WHERE a.intent_id = intents.id
+    ORDER BY a.acknowledged_at DESC
+    LIMIT 1
+) latest ON TRUE
+WHERE intents.id = ANY($1::uuid[])
+`
+
+	rows, err := db.QueryContext(ctx, query, uuidArrayLiteral(intentIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			intentID       uuid.UUID
+			state          IntentGuardrails
+			id             uuid.NullUUID
+			goalID         uuid.NullUUID
+			revision       sql.NullInt64
+			rawGuardrails  []byte
+			acknowledgedAt sql.NullTime
+		)
+
+		if err := rows.Scan(&intentID, &state.NeedsAcknowledgment, &id, &goalID, &revision, &rawGuardrails, &acknowledgedAt); err != nil {
+			return nil, err
+		}
+
+		if id.Valid {
+			acknowledgment := GuardrailAcknowledgment{
+				ID:             id.UUID,
+				IntentID:       intentID,
+				GoalID:         goalID.UUID,
+				GoalRevision:   int(revision.Int64),
+				AcknowledgedAt: acknowledgedAt.Time,
+			}
+			if err := json.Unmarshal(rawGuardrails, &acknowledgment.Guardrails); err != nil {
+				return nil, err
+			}
+			state.Latest = &acknowledgment
+		}
+
+		states[intentID] = state
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return states, nil
+}
@@ -0,0 +1,170 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestCreateIntentRequiresGuardrailAcknowledgment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	goalID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status, jsonb_array_length(guardrails) FROM goals WHERE id = $1")).
+		WithArgs(goalID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "guardrails"}).AddRow(GoalActive, 2))
+	mock.ExpectRollback()
+
+	_, err = CreateIntent(context.Background(), db, IntentInput{Statement: "Add retries", Collaborators: []string{}, GoalID: &goalID, Timebox: &Timebox{Blocks: 2}})
+	if !errors.Is(err, ErrGuardrailsNotAcknowledged) {
+		t.Fatalf("expected ErrGuardrailsNotAcknowledged got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestCreateIntentRecordsGuardrailAcknowledgment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	goalID, sessionID := uuid.New(), uuid.New()
+	input := IntentInput{
+		Statement:             "Add retries",
+		Collaborators:         []string{},
+		GoalID:                &goalID,
+		Timebox:               &Timebox{SessionIDs: []uuid.UUID{sessionID}},
+		AcknowledgeGuardrails: true,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status, jsonb_array_length(guardrails) FROM goals WHERE id = $1")).
+		WithArgs(goalID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "guardrails"}).AddRow(GoalActive, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM sessions WHERE id = ANY($1::uuid[])")).
+		WithArgs("{" + sessionID.String() + "}").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec("INSERT INTO intents").
+		WithArgs(sqlmock.AnyArg(), input.Statement, "", "", "[]", IntentActive, nil, goalID, nil, sqlmock.AnyArg(), `{"sessionIds":["`+sessionID.String()+`"]}`, "[]", nil, nil, "[]").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectIntentTransition(mock, sqlmock.AnyArg(), nil, IntentActive)
+	mock.ExpectQuery("INSERT INTO intent_guardrail_acknowledgments").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), goalID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"goal_revision", "guardrails"}).AddRow(3, `["No schema changes"]`))
+	expectWebhookEvent(mock, EventIntentCreated)
+	mock.ExpectCommit()
+
+	intent, err := CreateIntent(context.Background(), db, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if intent.Timebox == nil || len(intent.Timebox.SessionIDs) != 1 {
+		t.Fatalf("expected timebox with one session got %+v", intent.Timebox)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestCreateIntentRejectsUnknownTimeboxSession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionIDs := []uuid.UUID{uuid.New(), uuid.New()}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM sessions WHERE id = ANY($1::uuid[])")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	_, err = CreateIntent(context.Background(), db, IntentInput{Statement: "Add retries", Collaborators: []string{}, Timebox: &Timebox{SessionIDs: sessionIDs}})
+	if !errors.Is(err, ErrTimeboxSessionNotFound) {
+		t.Fatalf("expected ErrTimeboxSessionNotFound got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestAcknowledgeIntentGuardrailsRequiresGoal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	intentID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT goal_id FROM intents WHERE id = $1 FOR UPDATE")).
+		WithArgs(intentID).
+		WillReturnRows(sqlmock.NewRows([]string{"goal_id"}).AddRow(nil))
+	mock.ExpectRollback()
+
+	if _, err := AcknowledgeIntentGuardrails(context.Background(), db, intentID); !errors.Is(err, ErrIntentHasNoGoal) {
+		t.Fatalf("expected ErrIntentHasNoGoal got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestListIntentGuardrailsFlagsChangedGuardrails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	acknowledgedID, staleID, neverID := uuid.New(), uuid.New(), uuid.New()
+	goalID := uuid.New()
+	acknowledgedAt := time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("a.guardrails = g.guardrails").
+		WithArgs("{" + acknowledgedID.String() + "," + staleID.String() + "," + neverID.String() + "}").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "needs_acknowledgment", "id", "goal_id", "goal_revision", "guardrails", "acknowledged_at"}).
+			AddRow(acknowledgedID, false, uuid.New(), goalID, 2, `["No schema changes"]`, acknowledgedAt).
+			AddRow(staleID, true, uuid.New(), goalID, 1, `["No new vendors"]`, acknowledgedAt).
+			AddRow(neverID, true, nil, nil, nil, nil, nil))
+
+	states, err := ListIntentGuardrails(context.Background(), db, []uuid.UUID{acknowledgedID, staleID, neverID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if states[acknowledgedID].NeedsAcknowledgment || states[acknowledgedID].Latest.GoalRevision != 2 {
+		t.Fatalf("expected current acknowledgment at revision 2 got %+v", states[acknowledgedID])
+	}
+	if !states[staleID].NeedsAcknowledgment || states[staleID].Latest.Guardrails[0] != "No new vendors" {
+		t.Fatalf("expected stale acknowledgment to need renewal got %+v", states[staleID])
+	}
+	if !states[neverID].NeedsAcknowledgment || states[neverID].Latest != nil {
+		t.Fatalf("expected unacknowledged intent got %+v", states[neverID])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,425 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Intent link kinds.
+const (
+	LinkIssue     = "issue"
+	LinkDoc       = "doc"
+	LinkPR        = "pr"
+	LinkDashboard = "dashboard"
+)
+
+// LinkKinds lists every kind of external link an intent can carry.
+var LinkKinds = []string{LinkIssue, LinkDoc, LinkPR, LinkDashboard}
+
+// Status suggestion resolutions.
+const (
+	SuggestionAccepted   = "accepted"
+	SuggestionDismissed  = "dismissed"
+	SuggestionSuperseded = "superseded"
+)
+
+// ErrSuggestionResolved is returned when resolving a status suggestion that
+// was already accepted, dismissed or superseded.
+var ErrSuggestionResolved = errors.New("status suggestion has already been resolved")
+
+// IntentLink is an external resource attached to an intent. Tracker,
+// ExternalKey and ExternalStatus are set for issues held in a work tracker.
+type IntentLink struct {
+	ID             uuid.UUID
+	IntentID       uuid.UUID
+	Kind           string
+	URL            string
+	Title          string
+	Tracker        string
+	ExternalKey    string
+	ExternalStatus string
+	SyncedAt       *time.Time
+	CreatedAt      time.Time
+}
+
+// IntentLinkInput captures the fields required to attach a link.
+type IntentLinkInput struct {
+	Kind           string
+	URL            string
+	Title          string
+	Tracker        string
+	ExternalKey    string
+	ExternalStatus string
+}
+
+// StatusSuggestion proposes a new status for an intent, raised when its
+// tracker issue changed status. Resolution is empty while it is pending.
+type StatusSuggestion struct {
+	ID              uuid.UUID
+	IntentID        uuid.UUID
+	LinkID          *uuid.UUID
+	SuggestedStatus string
+	Reason          string
+	CreatedAt       time.Time
+	ResolvedAt      *time.Time
+	Resolution      string
+}
+
+const intentLinkColumns = `id, intent_id, kind, url, title, tracker, external_key, external_status, synced_at, created_at`
+
+const statusSuggestionColumns = `id, intent_id, link_id, suggested_status, reason, created_at, resolved_at, resolution`
+
+// CreateIntentLink attaches a link to an intent. Tracker issues are synced
+// from the moment they are linked.
+func CreateIntentLink(ctx context.Context, db *sql.DB, intentID uuid.UUID, input IntentLinkInput) (IntentLink, error) {
+	if db == nil {
+		return IntentLink{}, errors.New("database handle is nil")
+	}
+
+	now := time.Now().UTC()
+	link := IntentLink{
+		ID:             uuid.New(),
+		IntentID:       intentID,
+		Kind:           input.Kind,
+		URL:            input.URL,
+		Title:          input.Title,
+		Tracker:        input.Tracker,
+		ExternalKey:    input.ExternalKey,
+		ExternalStatus: input.ExternalStatus,
+		CreatedAt:      now,
+	}
+	if input.Tracker != "" {
+		link.SyncedAt = &now
+	}
+
+	const query = `
+INSERT INTO intent_links (id, intent_id, kind, url, title, tracker, external_key, external_status, synced_at, created_at)
+VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), $9, $10)
+`
+
+	if _, err := db.ExecContext(ctx, query, link.ID, intentID, link.Kind, link.URL, link.Title, link.Tracker, link.ExternalKey, link.ExternalStatus, link.SyncedAt, now); err != nil {
+		return IntentLink{}, err
+	}
+
+	return link, nil
+}
+
+// GetIntentLink retrieves one of the intent's links.
+func GetIntentLink(ctx context.Context, db *sql.DB, intentID, linkID uuid.UUID) (IntentLink, error) {
+	if db == nil {
+		return IntentLink{}, errors.New("database handle is nil")
+	}
+
+	query := `SELECT ` + intentLinkColumns + ` FROM intent_links WHERE id = $1 AND intent_id = $2`
+
+	return scanIntentLink(db.QueryRowContext(ctx, query, linkID, intentID))
+}
+
+// ListIntentLinks returns the intent's links in the order they were added.
+func ListIntentLinks(ctx context.Context, db *sql.DB, intentID uuid.UUID) ([]IntentLink, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	query := `SELECT ` + intentLinkColumns + ` FROM intent_links WHERE intent_id = $1 ORDER BY created_at, id`
+
+	return queryIntentLinks(ctx, db, query, intentID)
+}
+
+// ListTrackedLinks returns the issues held in tracker whose intents are not
+// done yet, the ones worth syncing.
+func ListTrackedLinks(ctx context.Context, db *sql.DB, tracker string) ([]IntentLink, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	const query = `
+SELECT l.id, l.intent_id, l.kind, l.url, l.title, l.tracker, l.external_key, l.external_status, l.synced_at, l.created_at
+FROM intent_links l
+JOIN intents i ON i.id = l.intent_id
+WHERE l.tracker = $1 AND l.external_key IS NOT NULL AND i.status <> 'done'
+ORDER BY l.synced_at NULLS FIRST, l.id
+`
+
+	return queryIntentLinks(ctx, db, query, tracker)
+}
+
+func queryIntentLinks(ctx context.Context, db *sql.DB, query string, args ...any) ([]IntentLink, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := make([]IntentLink, 0)
+	for rows.Next() {
+		link, err := scanIntentLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// DeleteIntentLink removes one of the intent's links.
+func DeleteIntentLink(ctx context.Context, db *sql.DB, intentID, linkID uuid.UUID) error {
+	if db == nil {
+		return errors.New("database handle is nil")
+	}
+
+	result, err := db.ExecContext(ctx, `DELETE FROM intent_links WHERE id = $1 AND intent_id = $2`, linkID, intentID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// RecordTrackerStatus stores the status a tracker reports for a linked
+// issue. When the status changed since the last sync and maps to an intent
+// status other than the current one, suggested is offered as a pending
+// status suggestion, superseding any earlier one; the intent itself is left
+// alone. An empty suggested never raises a suggestion.
+func RecordTrackerStatus(ctx context.Context, db *sql.DB, linkID uuid.UUID, status, suggested string) (IntentLink, *StatusSuggestion, error) {
+	if db == nil {
+		return IntentLink{}, nil, errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return IntentLink{}, nil, err
+	}
+	defer tx.Rollback()
+
+	var (
+		previous     sql.NullString
+		intentStatus string
+	)
+
+	const lockQuery = `
+SELECT l.external_status, i.status
+FROM intent_links l
+JOIN intents i ON i.id = l.intent_id
+WHERE l.id = $1
+FOR UPDATE OF l, i
+`
+
+	if err := tx.QueryRowContext(ctx, lockQuery, linkID).Scan(&previous, &intentStatus); err != nil {
+		return IntentLink{}, nil, err
+	}
+
+	now := time.Now().UTC()
+	const updateQuery = `UPDATE intent_links SET external_status = $2, synced_at = $3 WHERE id = $1 RETURNING ` + intentLinkColumns
+
+	link, err := scanIntentLink(tx.QueryRowContext(ctx, updateQuery, linkID, status, now))
+	if err != nil {
+		return IntentLink{}, nil, err
+	}
+
+	var suggestion *StatusSuggestion
+	if status != previous.String && suggested != "" && suggested != intentStatus {
+		const supersedeQuery = `
+UPDATE intent_status_suggestions SET resolved_at = $2, resolution = 'superseded'
+WHERE intent_id = $1 AND resolved_at IS NULL
+`
+
+		if _, err := tx.ExecContext(ctx, supersedeQuery, link.IntentID, now); err != nil {
+			return IntentLink{}, nil, err
+		}
+
+		suggestion = &StatusSuggestion{
+			ID:              uuid.New(),
+			IntentID:        link.IntentID,
+			LinkID:          &link.ID,
+			SuggestedStatus: suggested,
+			Reason:          link.ExternalKey + " moved to " + status,
+			CreatedAt:       now,
+		}
+
+		const insertQuery = `
+INSERT INTO intent_status_suggestions (id, intent_id, link_id, suggested_status, reason, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+		if _, err := tx.ExecContext(ctx, insertQuery, suggestion.ID, suggestion.IntentID, link.ID, suggestion.SuggestedStatus, suggestion.Reason, now); err != nil {
+			return IntentLink{}, nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return IntentLink{}, nil, err
+	}
+
+	return link, suggestion, nil
+}
+
+// ListStatusSuggestions returns the intent's status suggestions, newest
+// first, optionally only the pending one.
+func ListStatusSuggestions(ctx context.Context, db *sql.DB, intentID uuid.UUID, pendingOnly bool) ([]StatusSuggestion, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	query := `SELECT ` + statusSuggestionColumns + ` FROM intent_status_suggestions WHERE intent_id = $1`
+	if pendingOnly {
+		query += ` AND resolved_at IS NULL`
+	}
+	query += ` ORDER BY created_at DESC, id`
+
+	rows, err := db.QueryContext(ctx, query, intentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := make([]StatusSuggestion, 0)
+	for rows.Next() {
+		suggestion, err := scanStatusSuggestion(rows)
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return suggestions, nil
+}
+
+// ResolveStatusSuggestion accepts or dismisses a pending suggestion. Accepting
+// moves the intent to the suggested status and returns the updated intent;
+// a draft that is not complete yet stays in draft. sql.ErrNoRows is returned
+// when the intent has no such suggestion and ErrSuggestionResolved when it is
+// no longer pending.
+func ResolveStatusSuggestion(ctx context.Context, db *sql.DB, intentID, suggestionID uuid.UUID, accept bool) (StatusSuggestion, *Intent, error) {
+	if db == nil {
+		return StatusSuggestion{}, nil, errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return StatusSuggestion{}, nil, err
+	}
+	defer tx.Rollback()
+
+	query := `SELECT ` + statusSuggestionColumns + ` FROM intent_status_suggestions WHERE id = $1 AND intent_id = $2 FOR UPDATE`
+
+	suggestion, err := scanStatusSuggestion(tx.QueryRowContext(ctx, query, suggestionID, intentID))
+	if err != nil {
+		return StatusSuggestion{}, nil, err
+	}
+
+	if suggestion.ResolvedAt != nil {
+		return StatusSuggestion{}, nil, ErrSuggestionResolved
+	}
+
+	now := time.Now().UTC()
+	suggestion.ResolvedAt = &now
+	suggestion.Resolution = SuggestionDismissed
+	if accept {
+		suggestion.Resolution = SuggestionAccepted
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE intent_status_suggestions SET resolved_at = $2, resolution = $3 WHERE id = $1`, suggestion.ID, now, suggestion.Resolution); err != nil {
+		return StatusSuggestion{}, nil, err
+	}
+
+	var intent *Intent
+	if accept {
+		const query = `
+UPDATE intents
+SET status = $2
+FROM (SELECT status AS previous_status FROM intents WHERE id = $1 FOR UPDATE) previous
+WHERE id = $1
+RETURNING ` + intentColumns + `, previous_status
+`
+
+		var previousStatus string
+		updated, err := scanIntent(tx.QueryRowContext(ctx, query, intentID, suggestion.SuggestedStatus), &previousStatus)
+		if err != nil {
+			return StatusSuggestion{}, nil, err
+		}
+
+		if previousStatus == IntentDraft && updated.Status != IntentDraft {
+			if err := checkDraftComplete(ctx, tx, updated, false); err != nil {
+				return StatusSuggestion{}, nil, err
+			}
+		}
+
+		if err := recordIntentTransition(ctx, tx, updated, &previousStatus, updated.GoalID, now); err != nil {
+			return StatusSuggestion{}, nil, err
+		}
+
+		if err := recordWebhookEvent(ctx, tx, EventIntentUpdated, intentSnapshot(updated)); err != nil {
+			return StatusSuggestion{}, nil, err
+		}
+		intent = &updated
+	}
+
+	if err := tx.Commit(); err != nil {
+		return StatusSuggestion{}, nil, err
+	}
+
+	return suggestion, intent, nil
+}
+
+func scanIntentLink(row rowScanner) (IntentLink, error) {
+	var (
+		link           IntentLink
+		tracker        sql.NullString
+		externalKey    sql.NullString
+		externalStatus sql.NullString
+		syncedAt       sql.NullTime
+	)
+
+	if err := row.Scan(&link.ID, &link.IntentID, &link.Kind, &link.URL, &link.Title, &tracker, &externalKey, &externalStatus, &syncedAt, &link.CreatedAt); err != nil {
+		return IntentLink{}, err
+	}
+
+	link.Tracker = tracker.String
+	link.ExternalKey = externalKey.String
+	link.ExternalStatus = externalStatus.String
+	link.SyncedAt = nullTimePtr(syncedAt)
+
+	return link, nil
+}
+
+func scanStatusSuggestion(row rowScanner) (StatusSuggestion, error) {
+	var (
+		suggestion StatusSuggestion
+		linkID     uuid.NullUUID
+		resolvedAt sql.NullTime
+		resolution sql.NullString
+	)
+
+	if err := row.Scan(&suggestion.ID, &suggestion.IntentID, &linkID, &suggestion.SuggestedStatus, &suggestion.Reason, &suggestion.CreatedAt, &resolvedAt, &resolution); err != nil {
+		return StatusSuggestion{}, err
+	}
+
+	suggestion.LinkID = nullUUIDPtr(linkID)
+	suggestion.ResolvedAt = nullTimePtr(resolvedAt)
+	suggestion.Resolution = resolution.String
+
+	return suggestion, nil
+}
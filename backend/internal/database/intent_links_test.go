@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+var intentLinkRowColumns = []string{"id", "intent_id", "kind", "url", "title", "tracker", "external_key", "external_status", "synced_at", "created_at"}
+
+var statusSuggestionRowColumns = []string{"id", "intent_id", "link_id", "suggested_status", "reason", "created_at", "resolved_at", "resolution"}
+
+func TestRecordTrackerStatusUnchangedRaisesNoSuggestion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now().UTC()
+	intentID, linkID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT l.external_status, i.status FROM intent_links l").
+		WithArgs(linkID).
+		WillReturnRows(sqlmock.NewRows([]string{"external_status", "status"}).AddRow("In Progress", IntentDraft))
+	mock.ExpectQuery("UPDATE intent_links SET external_status").
+		WithArgs(linkID, "In Progress", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(intentLinkRowColumns).
+			AddRow(linkID, intentID, LinkIssue, "https://jira/browse/PLAT-1", "PLAT-1", "jira", "PLAT-1", "In Progress", now, now))
+	mock.ExpectCommit()
+
+	link, suggestion, err := RecordTrackerStatus(context.Background(), db, linkID, "In Progress", IntentActive)
+	if err != nil {
+		t.Fatalf("RecordTrackerStatus returned error: %v", err)
+	}
+
+	if suggestion != nil {
+		t.Fatalf("expected no suggestion for an unchanged status got %+v", suggestion)
+	}
+
+	if link.ExternalStatus != "In Progress" || link.SyncedAt == nil {
+		t.Fatalf("unexpected link %+v", link)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestResolveStatusSuggestionAcceptUpdatesIntent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now().UTC()
+	intentID, suggestionID, linkID := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM intent_status_suggestions WHERE id = $1 AND intent_id = $2 FOR UPDATE")).
+		WithArgs(suggestionID, intentID).
+		WillReturnRows(sqlmock.NewRows(statusSuggestionRowColumns).
+			AddRow(suggestionID, intentID, linkID, IntentDone, "PLAT-1 moved to Done", now, nil, nil))
+	mock.ExpectExec("UPDATE intent_status_suggestions SET resolved_at").
+		WithArgs(suggestionID, sqlmock.AnyArg(), SuggestionAccepted).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("UPDATE intents SET status").
+		WithArgs(intentID, IntentDone).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags", "previous_status"}).
+			AddRow(intentID, "statement", "context", "outcome", `[]`, IntentDone, nil, nil, nil, now, nil, nil, nil, nil, nil, IntentActive))
+	expectIntentTransition(mock, intentID, IntentActive, IntentDone)
+	expectWebhookEvent(mock, EventIntentUpdated)
+	mock.ExpectCommit()
+
+	suggestion, intent, err := ResolveStatusSuggestion(context.Background(), db, intentID, suggestionID, true)
+	if err != nil {
+		t.Fatalf("ResolveStatusSuggestion returned error: %v", err)
+	}
+
+	if suggestion.Resolution != SuggestionAccepted || suggestion.ResolvedAt == nil {
+		t.Fatalf("unexpected suggestion %+v", suggestion)
+	}
+
+	if intent == nil || intent.Status != IntentDone {
+		t.Fatalf("expected intent to be done got %+v", intent)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestResolveStatusSuggestionRejectsResolved(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now().UTC()
+	intentID, suggestionID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM intent_status_suggestions WHERE id").
+		WithArgs(suggestionID, intentID).
+		WillReturnRows(sqlmock.NewRows(statusSuggestionRowColumns).
+			AddRow(suggestionID, intentID, nil, IntentActive, "PLAT-1 moved to In Progress", now, now, SuggestionSuperseded))
+	mock.ExpectRollback()
+
+	_, _, err = ResolveStatusSuggestion(context.Background(), db, intentID, suggestionID, false)
+	if !errors.Is(err, ErrSuggestionResolved) {
+		t.Fatalf("expected ErrSuggestionResolved got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,319 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrSelfMerge is returned when an intent is merged into itself.
+var ErrSelfMerge = errors.New("an intent cannot be merged into itself")
+
+// IntentMerge is the audit record left behind when one intent absorbs another.
+type IntentMerge struct {
+	ID                uuid.UUID
+	SurvivingIntentID uuid.UUID
+	AbsorbedIntentID  uuid.UUID
+	AbsorbedSnapshot  Intent
+	MergedAt          time.Time
+}
+
+// MergeIntents folds the absorbed intent into the surviving one. The
+// collaborators and tags of both intents are unioned onto the survivor;
+// session commitments, links and recorded outcomes move to the survivor; a
+// snapshot of the absorbed intent is kept in intent_merges; and the absorbed
+// row is removed. Either intent missing yields sql.ErrNoRows.
+func MergeIntents(ctx context.Context, db *sql.DB, survivingID, absorbedID uuid.UUID) (Intent, error) {
+	if db == nil {
+		return Intent{}, errors.New("database handle is nil")
+	}
+
+	if survivingID == absorbedID {
+		return Intent{}, ErrSelfMerge
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Intent{}, err
+	}
+	defer tx.Rollback()
+
+	// Lock both rows in a stable order so concurrent merges cannot deadlock.
+	const lockQuery = `
+SELECT ` + intentColumns + `
+FROM intents
+WHERE id IN ($1, $2)
+ORDER BY id
+FOR UPDATE
+`
+
+	rows, err := tx.QueryContext(ctx, lockQuery, survivingID, absorbedID)
+	if err != nil {
+		return Intent{}, err
+	}
+
+	locked := make(map[uuid.UUID]Intent, 2)
+	for rows.Next() {
+		intent, err := scanIntent(rows)
+		if err != nil {
+			rows.Close()
+			return Intent{}, err
+		}
+
+		locked[intent.ID] = intent
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return Intent{}, err
+	}
+
+	survivor, ok := locked[survivingID]
+	if !ok {
+		return Intent{}, sql.ErrNoRows
+	}
+
+	absorbed, ok := locked[absorbedID]
+	if !ok {
+		return Intent{}, sql.ErrNoRows
+	}
+
+	survivor.Collaborators = unionCollaborators(survivor.Collaborators, absorbed.Collaborators)
+	survivor.Tags = unionTags(survivor.Tags, absorbed.Tags)
+
+	collaboratorJSON, err := json.Marshal(survivor.Collaborators)
+	if err != nil {
+		return Intent{}, err
+	}
+
+	tagsJSON, err := json.Marshal(survivor.Tags)
+	if err != nil {
+		return Intent{}, err
+	}
+
+	snapshotJSON, err := json.Marshal(intentSnapshot(absorbed))
+	if err != nil {
+		return Intent{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE intents SET collaborators = $1, tags = $2 WHERE id = $3`, string(collaboratorJSON), string(tagsJSON), survivingID); err != nil {
+		return Intent{}, err
+	}
+
+	const auditQuery = `
+INSERT INTO intent_merges (id, surviving_intent_id, absorbed_intent_id, absorbed_snapshot, merged_at)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+	if _, err := tx.ExecContext(ctx, auditQuery, uuid.New(), survivingID, absorbedID, string(snapshotJSON), time.Now().UTC()); err != nil {
+		return Intent{}, err
+	}
+
+	// Carry session commitments across unless the member already committed
+	// to the survivor in that session; leftovers cascade with the delete.
+	const commitmentsQuery = `
+UPDATE commitments
+SET intent_id = $1
+WHERE intent_id = $2
+  AND NOT EXISTS (
+    SELECT 1 FROM commitments AS existing
+    WHERE existing.intent_id = $1
+      AND existing.session_id = commitments.session_id
+      AND existing.member_id = commitments.member_id
+  )
+`
+
+	if _, err := tx.ExecContext(ctx, commitmentsQuery, survivingID, absorbedID); err != nil {
+		return Intent{}, err
+	}
+
+	// Links move too, except a second issue in a tracker the survivor is
+	// already linked to.
+	const linksQuery = `
+UPDATE intent_links
+SET intent_id = $1
+WHERE intent_id = $2
+  AND (tracker IS NULL OR NOT EXISTS (
+    SELECT 1 FROM intent_links AS existing
+    WHERE existing.intent_id = $1
+      AND existing.tracker = intent_links.tracker
+  ))
+`
+
+	if _, err := tx.ExecContext(ctx, linksQuery, survivingID, absorbedID); err != nil {
+		return Intent{}, err
+	}
+
+	// Recorded outcomes would otherwise lose their intent to ON DELETE SET
+	// NULL.
+	const outcomesQuery = `
+UPDATE session_outcomes
+SET intent_id = CASE WHEN intent_id = $2 THEN $1 ELSE intent_id END,
+    next_intent_id = CASE WHEN next_intent_id = $2 THEN $1 ELSE next_intent_id END
+WHERE intent_id = $2 OR next_intent_id = $2
+`
+
+	if _, err := tx.ExecContext(ctx, outcomesQuery, survivingID, absorbedID); err != nil {
+		return Intent{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM intents WHERE id = $1`, absorbedID); err != nil {
+		return Intent{}, err
+	}
+
+	if err := recordWebhookEvent(ctx, tx, EventIntentUpdated, intentSnapshot(survivor)); err != nil {
+		return Intent{}, err
+	}
+
+	if err := recordWebhookEvent(ctx, tx, EventIntentDeleted, deletedResource{ID: absorbedID}); err != nil {
+		return Intent{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Intent{}, err
+	}
+
+	return survivor, nil
+}
+
+// ListIntentMerges returns the merge records in which the intent took part,
+// either as the survivor or as the absorbed intent.
+func ListIntentMerges(ctx context.Context, db *sql.DB, intentID uuid.UUID) ([]IntentMerge, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	const query = `
+SELECT id, surviving_intent_id, absorbed_intent_id, absorbed_snapshot, merged_at
+FROM intent_merges
+WHERE surviving_intent_id = $1 OR absorbed_intent_id = $1
+ORDER BY merged_at DESC
+`
+
+	rows, err := db.QueryContext(ctx, query, intentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	merges := make([]IntentMerge, 0)
+	for rows.Next() {
+		var (
+			merge       IntentMerge
+			rawSnapshot []byte
+		)
+
+		if err := rows.Scan(&merge.ID, &merge.SurvivingIntentID, &merge.AbsorbedIntentID, &rawSnapshot, &merge.MergedAt); err != nil {
+			return nil, err
+		}
+
+		var snapshot storedIntentSnapshot
+		if err := json.Unmarshal(rawSnapshot, &snapshot); err != nil {
+			return nil, err
+		}
+		merge.AbsorbedSnapshot = snapshot.toIntent()
+
+		merges = append(merges, merge)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return merges, nil
+}
+
+// storedIntentSnapshot is the JSON shape used when freezing an intent.
+type storedIntentSnapshot struct {
+	ID              uuid.UUID  `json:"id"`
+	Statement       string     `json:"statement"`
+	Context         string     `json:"context"`
+	ExpectedOutcome string     `json:"expectedOutcome"`
+	Collaborators   []string   `json:"collaborators"`
+	Status          string     `json:"status,omitempty"`
+	MemberID        *uuid.UUID `json:"memberId,omitempty"`
+	GoalID          *uuid.UUID `json:"goalId,omitempty"`
+	SessionID       *uuid.UUID `json:"sessionId,omitempty"`
+	Timebox         *Timebox   `json:"timebox,omitempty"`
+	NeededSkills    []string   `json:"neededSkills,omitempty"`
+	TemplateID      *uuid.UUID `json:"templateId,omitempty"`
+	TemplateVersion *int       `json:"templateVersion,omitempty"`
+	Tags            []string   `json:"tags,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+}
+
+func intentSnapshot(intent Intent) storedIntentSnapshot {
+	return storedIntentSnapshot{
+		ID:              intent.ID,
+		Statement:       intent.Statement,
+		Context:         intent.Context,
+		ExpectedOutcome: intent.ExpectedOutcome,
+		Collaborators:   intent.Collaborators,
+		Status:          intent.Status,
+		MemberID:        intent.MemberID,
+		GoalID:          intent.GoalID,
+		SessionID:       intent.SessionID,
+		Timebox:         intent.Timebox,
+		NeededSkills:    intent.NeededSkills,
+		TemplateID:      intent.TemplateID,
+		TemplateVersion: intent.TemplateVersion,
+		Tags:            intent.Tags,
+		CreatedAt:       intent.CreatedAt,
+	}
+}
+
+func (s storedIntentSnapshot) toIntent() Intent {
+	return Intent{
+		ID:              s.ID,
+		Statement:       s.Statement,
+		Context:         s.Context,
+		ExpectedOutcome: s.ExpectedOutcome,
+		Collaborators:   s.Collaborators,
+		Status:          s.Status,
+		MemberID:        s.MemberID,
+		GoalID:          s.GoalID,
+		SessionID:       s.SessionID,
+		Timebox:         s.Timebox,
+		NeededSkills:    nonNilStrings(s.NeededSkills),
+		TemplateID:      s.TemplateID,
+		TemplateVersion: s.TemplateVersion,
+		Tags:            nonNilStrings(s.Tags),
+		CreatedAt:       s.CreatedAt,
+	}
+}
+
+// unionTags appends the absorbed intent's tags the survivor lacks. Tags are
+// stored normalized, so they compare exactly.
+func unionTags(primary, secondary []string) []string {
+	union := make([]string, 0, len(primary)+len(secondary))
+	seen := make(map[string]struct{})
+	for _, tag := range append(append([]string{}, primary...), secondary...) {
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		union = append(union, tag)
+	}
+	return union
+}
+
+func unionCollaborators(primary, secondary []string) []string {
+	union := make([]string, 0, len(primary)+len(secondary))
+	seen := make(map[string]struct{})
+	for _, collaborator := range append(append([]string{}, primary...), secondary...) {
+		key := strings.ToLower(strings.TrimSpace(collaborator))
+		if key == "" {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		union = append(union, collaborator)
+	}
+	return union
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestMergeIntentsUnionsCollaborators(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	survivingID := uuid.New()
+	absorbedID := uuid.New()
+	createdAt := time.Now().UTC()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, statement, context, expected_outcome, collaborators, status, member_id, goal_id, session_id, created_at, timebox, needed_skills, template_id, template_version, tags FROM intents WHERE id IN \\(\\$1, \\$2\\) ORDER BY id FOR UPDATE").
+		WithArgs(survivingID, absorbedID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags"}).
+			AddRow(survivingID, "statement", "context", "outcome", `["Jamie","Ana"]`, "active", nil, nil, nil, createdAt, nil, nil, nil, nil, `["checkout"]`).
+			AddRow(absorbedID, "statement", "context", "outcome", `["ana","Priya"]`, "active", nil, nil, nil, createdAt, nil, nil, nil, nil, `["reliability","checkout"]`))
+	mock.ExpectExec("UPDATE intents SET collaborators").
+		WithArgs(`["Jamie","Ana","Priya"]`, `["checkout","reliability"]`, survivingID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO intent_merges").
+		WithArgs(sqlmock.AnyArg(), survivingID, absorbedID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE commitments SET intent_id").
+		WithArgs(survivingID, absorbedID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE intent_links SET intent_id").
+		WithArgs(survivingID, absorbedID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE session_outcomes SET intent_id = CASE WHEN intent_id = $2 THEN $1")).
+		WithArgs(survivingID, absorbedID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM intents WHERE id = \\$1").
+		WithArgs(absorbedID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectWebhookEvent(mock, EventIntentUpdated)
+	expectWebhookEvent(mock, EventIntentDeleted)
+	mock.ExpectCommit()
+
+	intent, err := MergeIntents(context.Background(), db, survivingID, absorbedID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(intent.Collaborators) != 3 {
+		t.Fatalf("expected 3 collaborators got %v", intent.Collaborators)
+	}
+
+	if len(intent.Tags) != 2 {
+		t.Fatalf("expected tags to be unioned got %v", intent.Tags)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestMergeIntentsMissingAbsorbed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	survivingID := uuid.New()
+	absorbedID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM intents WHERE id IN").
+		WithArgs(survivingID, absorbedID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags"}).
+			AddRow(survivingID, "statement", "context", "outcome", `[]`, "active", nil, nil, nil, time.Now().UTC(), nil, nil, nil, nil, nil))
+	mock.ExpectRollback()
+
+	if _, err := MergeIntents(context.Background(), db, survivingID, absorbedID); err != sql.ErrNoRows {
+		t.Fatalf("expected sql.ErrNoRows got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestMergeIntentsRejectsSelfMerge(t *testing.T) {
+	id := uuid.New()
+
+	if _, err := MergeIntents(context.Background(), &sql.DB{}, id, id); err != ErrSelfMerge {
+		t.Fatalf("expected ErrSelfMerge got %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/example/intent/backend/internal/quality"
+	"github.com/google/uuid"
+)
+
+// IntentQuality is the stored quality score of an intent. ChapterID names
+// the chapter whose rules produced it and is nil when the defaults did.
+type IntentQuality struct {
+	IntentID    uuid.UUID
+	Score       int
+	Breakdown   []quality.RuleResult
+	Questions   []string
+	ChapterID   *uuid.UUID
+	EvaluatedAt time.Time
+}
+
+// IntentQualityRules are the rules a chapter scores intents with. Custom is
+// false, and UpdatedAt nil, while the chapter uses the defaults.
+type IntentQualityRules struct {
+	ChapterID uuid.UUID
+	Rules     []quality.Rule
+	Custom    bool
+	UpdatedAt *time.Time
+}
+
+// ScoreIntentQuality analyzes the intent with the rules of its owner's
+// chapter, or of its session's chapter when it has no owner, and stores the
+// result in place of any earlier score.
+func ScoreIntentQuality(ctx context.Context, db *sql.DB, intent Intent) (IntentQuality, error) {
+	if db == nil {
+		return IntentQuality{}, errors.New("database handle is nil")
+	}
+
+	const rulesQuery = `
+SELECT chapter_id, rules
+FROM intent_quality_rules
+WHERE chapter_id = COALESCE(
+    (SELECT chapter_id FROM members WHERE id = $1),
+    (SELECT chapter_id FROM sessions WHERE id = $2)
+)
+`
+
+	var (
+		chapterID uuid.UUID
+		rulesJSON []byte
+	)
+
+	record := IntentQuality{IntentID: intent.ID}
+	rules := quality.DefaultRules()
+
+	err := db.QueryRowContext(ctx, rulesQuery, uuidPtrValue(intent.MemberID), uuidPtrValue(intent.SessionID)).Scan(&chapterID, &rulesJSON)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return IntentQuality{}, err
+	default:
+		if err := json.Unmarshal(rulesJSON, &rules); err != nil {
+			return IntentQuality{}, err
+		}
+		record.ChapterID = &chapterID
+	}
+
+	result := quality.Analyze(quality.Intent{
+		Statement:       intent.Statement,
+		Context:         intent.Context,
+		ExpectedOutcome: intent.ExpectedOutcome,
+		Collaborators:   intent.Collaborators,
+		HasGoal:         intent.GoalID != nil,
+	}, rules)
+
+	record.Score = result.Score
+	record.Breakdown = result.Breakdown
+	record.Questions = result.Questions
+	record.EvaluatedAt = time.Now().UTC()
+
+	breakdownJSON, err := json.Marshal(record.Breakdown)
+	if err != nil {
+		return IntentQuality{}, err
+	}
+
+	questionsJSON, err := json.Marshal(record.Questions)
+	if err != nil {
+		return IntentQuality{}, err
+	}
+
+	const upsert = `
+INSERT INTO intent_quality (intent_id, score, breakdown, questions, chapter_id, evaluated_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (intent_id) DO UPDATE
+SET score = EXCLUDED.score,
+    breakdown = EXCLUDED.breakdown,
+    questions = EXCLUDED.questions,
+    chapter_id = EXCLUDED.chapter_id,
+    evaluated_at = EXCLUDED.evaluated_at
+`
+
+	if _, err := db.ExecContext(ctx, upsert, record.IntentID, record.Score, string(breakdownJSON), string(questionsJSON), uuidPtrValue(record.ChapterID), record.EvaluatedAt); err != nil {
+		return IntentQuality{}, err
+	}
+
+	return record, nil
+}
+
+// GetIntentQuality returns the stored quality score of an intent, or
+// sql.ErrNoRows when it has not been scored yet.
+func GetIntentQuality(ctx context.Context, db *sql.DB, intentID uuid.UUID) (IntentQuality, error) {
+	if db == nil {
+		return IntentQuality{}, errors.New("database handle is nil")
+	}
+
+	const query = `
+SELECT intent_id, score, breakdown, questions, chapter_id, evaluated_at
+FROM intent_quality
+WHERE intent_id = $1
+`
+
+	var (
+		record    IntentQuality
+		breakdown []byte
+		questions []byte
+		chapterID uuid.NullUUID
+	)
+
+	if err := db.QueryRowContext(ctx, query, intentID).Scan(&record.IntentID, &record.Score, &breakdown, &questions, &chapterID, &record.EvaluatedAt); err != nil {
+		return IntentQuality{}, err
+	}
+
+	if err := json.Unmarshal(breakdown, &record.Breakdown); err != nil {
+		return IntentQuality{}, err
+	}
+
+	if err := json.Unmarshal(questions, &record.Questions); err != nil {
+		return IntentQuality{}, err
+	}
+
+	record.ChapterID = nullUUIDPtr(chapterID)
+	return record, nil
+}
+
+// GetIntentQualityRules returns the chapter's quality rules, falling back to
+// the defaults. It returns sql.ErrNoRows when the chapter does not exist.
+func GetIntentQualityRules(ctx context.Context, db *sql.DB, chapterID uuid.UUID) (IntentQualityRules, error) {
+	if db == nil {
+		return IntentQualityRules{}, errors.New("database handle is nil")
+	}
+
+	const query = `
+SELECT r.rules, r.updated_at
+FROM chapters c
+LEFT JOIN intent_quality_rules r ON r.chapter_id = c.id
+WHERE c.id = $1
+`
+
+	var (
+		rulesJSON []byte
+		updatedAt sql.NullTime
+	)
+
+	if err := db.QueryRowContext(ctx, query, chapterID).Scan(&rulesJSON, &updatedAt); err != nil {
+		return IntentQualityRules{}, err
+	}
+
+	config := IntentQualityRules{ChapterID: chapterID, Rules: quality.DefaultRules()}
+	if !updatedAt.Valid {
+		return config, nil
+	}
+
+	if err := json.Unmarshal(rulesJSON, &config.Rules); err != nil {
+		return IntentQualityRules{}, err
+	}
+	config.Custom = true
+	config.UpdatedAt = &updatedAt.Time
+
+	return config, nil
+}
+
+// PutIntentQualityRules replaces the chapter's quality rules, which must be
+// valid. Intents are rescored with them the next time they change.
+func PutIntentQualityRules(ctx context.Context, db *sql.DB, chapterID uuid.UUID, rules []quality.Rule) (IntentQualityRules, error) {
+	if db == nil {
+		return IntentQualityRules{}, errors.New("database handle is nil")
+	}
+
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		return IntentQualityRules{}, err
+	}
+
+	now := time.Now().UTC()
+
+	const query = `
+INSERT INTO intent_quality_rules (chapter_id, rules, updated_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (chapter_id) DO UPDATE
+SET rules = EXCLUDED.rules,
+    updated_at = EXCLUDED.updated_at
+`
+
+	if _, err := db.ExecContext(ctx, query, chapterID, string(rulesJSON), now); err != nil {
+		return IntentQualityRules{}, err
+	}
+
+	return IntentQualityRules{ChapterID: chapterID, Rules: rules, Custom: true, UpdatedAt: &now}, nil
+}
+
+// DeleteIntentQualityRules returns the chapter to the default rules. It
+// returns sql.ErrNoRows when the chapter had none of its own.
+func DeleteIntentQualityRules(ctx context.Context, db *sql.DB, chapterID uuid.UUID) error {
+	if db == nil {
+		return errors.New("database handle is nil")
+	}
+
+	result, err := db.ExecContext(ctx, `DELETE FROM intent_quality_rules WHERE chapter_id = $1`, chapterID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
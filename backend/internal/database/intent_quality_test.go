@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestScoreIntentQualityUsesDefaultRules(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	goalID := uuid.New()
+	intent := Intent{
+		ID:              uuid.New(),
+		Statement:       "I intend to halve our deploy time.",
+		Context:         "Slow.",
+		ExpectedOutcome: "Deploys finish within 20 minutes.",
+		Collaborators:   []string{"Ana"},
+		GoalID:          &goalID,
+	}
+
+	mock.ExpectQuery("FROM intent_quality_rules").
+		WithArgs(nil, nil).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec("INSERT INTO intent_quality").
+		WithArgs(intent.ID, 80, sqlmock.AnyArg(), `["What happened recently that makes this matter now, and who is affected?"]`, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	record, err := ScoreIntentQuality(context.Background(), db, intent)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if record.Score != 80 || record.ChapterID != nil || len(record.Breakdown) != 5 {
+		t.Fatalf("unexpected quality %+v", record)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestScoreIntentQualityUsesChapterRules(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	chapterID, memberID := uuid.New(), uuid.New()
+	intent := Intent{ID: uuid.New(), Statement: "Pair on the flaky suite", MemberID: &memberID}
+	rules := `[{"id":"pairing","label":"Two collaborators","field":"collaborators","check":"minWords","min":2,"weight":1,"question":"Who will you pair with?"}]`
+
+	mock.ExpectQuery("FROM intent_quality_rules").
+		WithArgs(memberID, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"chapter_id", "rules"}).AddRow(chapterID, []byte(rules)))
+	mock.ExpectExec("INSERT INTO intent_quality").
+		WithArgs(intent.ID, 0, `[{"ruleId":"pairing","label":"Two collaborators","weight":1,"passed":false}]`, `["Who will you pair with?"]`, chapterID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	record, err := ScoreIntentQuality(context.Background(), db, intent)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if record.ChapterID == nil || *record.ChapterID != chapterID {
+		t.Fatalf("expected chapter %s got %v", chapterID, record.ChapterID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestGetIntentQualityRulesFallsBackToDefaults(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	chapterID := uuid.New()
+
+	mock.ExpectQuery("LEFT JOIN intent_quality_rules").
+		WithArgs(chapterID).
+		WillReturnRows(sqlmock.NewRows([]string{"rules", "updated_at"}).AddRow(nil, nil))
+
+	config, err := GetIntentQualityRules(context.Background(), db, chapterID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if config.Custom || config.UpdatedAt != nil || len(config.Rules) == 0 {
+		t.Fatalf("expected default rules got %+v", config)
+	}
+
+	now := time.Now()
+	mock.ExpectQuery("LEFT JOIN intent_quality_rules").
+		WithArgs(chapterID).
+		WillReturnRows(sqlmock.NewRows([]string{"rules", "updated_at"}).
+			AddRow([]byte(`[{"id":"goal","field":"goal","check":"present","weight":1,"question":"Which goal?"}]`), now))
+
+	config, err = GetIntentQualityRules(context.Background(), db, chapterID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !config.Custom || len(config.Rules) != 1 || config.Rules[0].ID != "goal" {
+		t.Fatalf("expected the chapter's rule got %+v", config)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
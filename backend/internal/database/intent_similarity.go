@@ -0,0 +1,149 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/example/intent/backend/internal/similarity"
+)
+
+// Weights applied to the per-field trigram similarity when scoring intents.
+// The statement dominates because duplicates usually share the "I intend to"
+// wording even when their context drifts.
+const (
+	statementWeight       = 0.6
+	contextWeight         = 0.2
+	expectedOutcomeWeight = 0.2
+)
+
+// SimilarIntent pairs an intent with its similarity score against a probe.
+type SimilarIntent struct {
+	Intent Intent
+	Score  float64
+}
+
+// FindSimilarIntents returns intents whose statement, context and expected
+// outcome resemble the probe, ordered by descending score. Scoring uses
+// pg_trgm when the extension is installed and falls back to an equivalent
+// pure-Go trigram comparison otherwise. The intent identified by exclude is
+// never returned.
+func FindSimilarIntents(ctx context.Context, db *sql.DB, probe IntentInput, exclude uuid.UUID, threshold float64, limit int) ([]SimilarIntent, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	matches, err := findSimilarIntentsTrgm(ctx, db, probe, exclude, threshold, limit)
+	if err == nil {
+		return matches, nil
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && (pgErr.Code == "42883" || pgErr.Code == "42704") {
+		// undefined_function / undefined_object: pg_trgm is not installed.
+		return findSimilarIntentsFallback(ctx, db, probe, exclude, threshold, limit)
+	}
+
+	return nil, err
+}
+
+func findSimilarIntentsTrgm(ctx context.Context, db *sql.DB, probe IntentInput, exclude uuid.UUID, threshold float64, limit int) ([]SimilarIntent, error) {
+	const query = `
+SELECT ` + intentColumns + `, score
+FROM (
+    SELECT ` + intentColumns + `,
+           $7::float8 * similarity(statement, $1) + $8::float8 * similarity(context, $2) + $9::float8 * similarity(expected_outcome, $3) AS score
+    FROM intents
+    WHERE id <> $4
+) AS scored
+WHERE score >= $5
+ORDER BY score DESC, created_at DESC
+LIMIT $6
+`
+
+	rows, err := db.QueryContext(ctx, query, probe.Statement, probe.Context, probe.ExpectedOutcome, exclude, threshold, limit, statementWeight, contextWeight, expectedOutcomeWeight)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := make([]SimilarIntent, 0)
+	for rows.Next() {
+		var match SimilarIntent
+		intent, err := scanIntent(rows, &match.Score)
+		if err != nil {
+			return nil, err
+		}
+		match.Intent = intent
+
+		matches = append(matches, match)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+func findSimilarIntentsFallback(ctx context.Context, db *sql.DB, probe IntentInput, exclude uuid.UUID, threshold float64, limit int) ([]SimilarIntent, error) {
+	const query = `
+SELECT ` + intentColumns + `
+FROM intents
+WHERE id <> $1
+`
+
+	rows, err := db.QueryContext(ctx, query, exclude)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := make([]SimilarIntent, 0)
+	for rows.Next() {
+		intent, err := scanIntent(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		score := ScoreIntentSimilarity(probe, IntentInput{
+			Statement:       intent.Statement,
+			Context:         intent.Context,
+			ExpectedOutcome: intent.ExpectedOutcome,
+		})
+		if score < threshold {
+			continue
+		}
+
+		matches = append(matches, SimilarIntent{Intent: intent, Score: score})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Intent.CreatedAt.After(matches[j].Intent.CreatedAt)
+	})
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches, nil
+}
+
+// ScoreIntentSimilarity computes the weighted trigram similarity between two
+// intents using the same weights as the pg_trgm query.
+func ScoreIntentSimilarity(a, b IntentInput) float64 {
+	return statementWeight*similarity.Score(a.Statement, b.Statement) +
+		contextWeight*similarity.Score(a.Context, b.Context) +
+		expectedOutcomeWeight*similarity.Score(a.ExpectedOutcome, b.ExpectedOutcome)
+}
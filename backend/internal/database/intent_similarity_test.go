@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestFindSimilarIntentsUsesTrigramQuery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	probe := IntentInput{Statement: "statement", Context: "context", ExpectedOutcome: "outcome"}
+	exclude := uuid.New()
+	match := uuid.New()
+
+	mock.ExpectQuery("similarity\\(statement, \\$1\\)").
+		WithArgs(probe.Statement, probe.Context, probe.ExpectedOutcome, exclude, 0.5, 5, statementWeight, contextWeight, expectedOutcomeWeight).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags", "score"}).
+			AddRow(match, "statement", "context", "outcome", `["Jamie"]`, "active", nil, nil, nil, time.Now().UTC(), nil, nil, nil, nil, nil, 0.92))
+
+	matches, err := FindSimilarIntents(context.Background(), db, probe, exclude, 0.5, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(matches) != 1 || matches[0].Intent.ID != match {
+		t.Fatalf("expected single match %s got %+v", match, matches)
+	}
+
+	if matches[0].Score != 0.92 {
+		t.Fatalf("expected score 0.92 got %f", matches[0].Score)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestFindSimilarIntentsFallsBackWithoutPgTrgm(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	probe := IntentInput{
+		Statement:       "Enable Product Discovery Guild to co-create sprint objectives with clarity",
+		Context:         "During the upcoming quarter",
+		ExpectedOutcome: "Teams articulate ownership statements",
+	}
+	exclude := uuid.New()
+	duplicate := uuid.New()
+	unrelated := uuid.New()
+	now := time.Now().UTC()
+
+	mock.ExpectQuery("similarity\\(statement, \\$1\\)").
+		WillReturnError(&pgconn.PgError{Code: "42883", Message: "function similarity(text, unknown) does not exist"})
+
+	mock.ExpectQuery("SELECT id, statement, context, expected_outcome, collaborators, status, member_id, goal_id, session_id, created_at, timebox, needed_skills, template_id, template_version, tags FROM intents WHERE id <> \\$1").
+		WithArgs(exclude).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags"}).
+			AddRow(unrelated, "Publish weekly learning briefs", "Sustainability track", "Stakeholders informed", `[]`, "active", nil, nil, nil, now, nil, nil, nil, nil, nil).
+			AddRow(duplicate, probe.Statement, "During the upcoming quarter of rollout", probe.ExpectedOutcome, `["Priya"]`, "active", nil, nil, nil, now, nil, nil, nil, nil, nil))
+
+	matches, err := FindSimilarIntents(context.Background(), db, probe, exclude, 0.5, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match got %d", len(matches))
+	}
+
+	if matches[0].Intent.ID != duplicate {
+		t.Fatalf("expected duplicate %s got %s", duplicate, matches[0].Intent.ID)
+	}
+
+	if matches[0].Score < 0.8 {
+		t.Fatalf("expected high similarity score got %f", matches[0].Score)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,410 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IntentTemplate is a reusable starting point for a kind of intent, such as
+// a spike or a design review. Templates without a chapter are offered to
+// every chapter. Version counts the edits of its content.
+type IntentTemplate struct {
+	ID        uuid.UUID
+	ChapterID *uuid.UUID
+	Kind      string
+	Name      string
+	IntentTemplateContent
+	Version   int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// IntentTemplateContent is the versioned part of a template: placeholder
+// text for the intent and its defaults.
+type IntentTemplateContent struct {
+	Statement       string
+	Context         string
+	ExpectedOutcome string
+	NeededSkills    []string
+	TimeboxBlocks   *int
+	GoalID          *uuid.UUID
+}
+
+// IntentTemplateVersion is an immutable copy of a template's content.
+type IntentTemplateVersion struct {
+	TemplateID uuid.UUID
+	Version    int
+	IntentTemplateContent
+	CreatedAt time.Time
+}
+
+// IntentTemplateInput captures the fields required to create or update a
+// template. ChapterID is fixed at creation.
+type IntentTemplateInput struct {
+	ChapterID *uuid.UUID
+	Kind      string
+	Name      string
+	IntentTemplateContent
+}
+
+// IntentTemplateFilters narrow templates by chapter and kind. A chapter sees
+// its own templates and the global ones.
+type IntentTemplateFilters struct {
+	ChapterID *uuid.UUID
+	Kind      string
+}
+
+const intentTemplateColumns = "id, chapter_id, kind, name, version, statement, context, expected_outcome, needed_skills, timebox_blocks, goal_id, created_at, updated_at"
+
+const intentTemplateVersionColumns = "template_id, version, statement, context, expected_outcome, needed_skills, timebox_blocks, goal_id, created_at"
+
+// CreateIntentTemplate persists a new template as version 1.
+func CreateIntentTemplate(ctx context.Context, db *sql.DB, input IntentTemplateInput) (IntentTemplate, error) {
+	if db == nil {
+		return IntentTemplate{}, errors.New("database handle is nil")
+	}
+
+	skillsJSON, err := json.Marshal(nonNilStrings(input.NeededSkills))
+	if err != nil {
+		return IntentTemplate{}, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return IntentTemplate{}, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	template := IntentTemplate{
+		ID:                    uuid.New(),
+		ChapterID:             input.ChapterID,
+		Kind:                  input.Kind,
+		Name:                  input.Name,
+		IntentTemplateContent: input.IntentTemplateContent,
+		Version:               1,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+	}
+	template.NeededSkills = nonNilStrings(input.NeededSkills)
+
+	const query = `
+INSERT INTO intent_templates (` + intentTemplateColumns + `)
+VALUES ($1, $2, $3, $4, 1, $5, $6, $7, $8, $9, $10, $11, $11)
+`
+
+	if _, err := tx.ExecContext(ctx, query, template.ID, uuidPtrValue(template.ChapterID), template.Kind, template.Name, template.Statement, template.Context, template.ExpectedOutcome, string(skillsJSON), intPtrValue(template.TimeboxBlocks), uuidPtrValue(template.GoalID), now); err != nil {
+		return IntentTemplate{}, err
+	}
+
+	if err := recordIntentTemplateVersion(ctx, tx, template.ID, now); err != nil {
+		return IntentTemplate{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return IntentTemplate{}, err
+	}
+
+	return template, nil
+}
+
+// GetIntentTemplate retrieves a template by identifier.
+func GetIntentTemplate(ctx context.Context, db *sql.DB, id uuid.UUID) (IntentTemplate, error) {
+	if db == nil {
+		return IntentTemplate{}, errors.New("database handle is nil")
+	}
+
+	const query = `SELECT ` + intentTemplateColumns + ` FROM intent_templates WHERE id = $1`
+
+	return scanIntentTemplate(db.QueryRowContext(ctx, query, id))
+}
+
+// ListIntentTemplates returns templates ordered by kind and name.
+func ListIntentTemplates(ctx context.Context, db *sql.DB, filters IntentTemplateFilters) ([]IntentTemplate, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	conditions := make([]string, 0, 2)
+	args := make([]any, 0, 2)
+
+	if filters.ChapterID != nil {
+		args = append(args, *filters.ChapterID)
+		conditions = append(conditions, fmt.Sprintf("(chapter_id = $%d OR chapter_id IS NULL)", len(args)))
+	}
+
+	if filters.Kind != "" {
+		args = append(args, filters.Kind)
+		conditions = append(conditions, fmt.Sprintf("kind = $%d", len(args)))
+	}
+
+	query := `SELECT ` + intentTemplateColumns + ` FROM intent_templates`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY kind, name, id`
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := make([]IntentTemplate, 0)
+	for rows.Next() {
+		template, err := scanIntentTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// UpdateIntentTemplate replaces a template's kind, name and content. A
+// change to the content is stored as the next version; intents already
+// created keep the version they came from.
+func UpdateIntentTemplate(ctx context.Context, db *sql.DB, id uuid.UUID, input IntentTemplateInput) (IntentTemplate, error) {
+	if db == nil {
+		return IntentTemplate{}, errors.New("database handle is nil")
+	}
+
+	skillsJSON, err := json.Marshal(nonNilStrings(input.NeededSkills))
+	if err != nil {
+		return IntentTemplate{}, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return IntentTemplate{}, err
+	}
+	defer tx.Rollback()
+
+	const query = `
+UPDATE intent_templates
+SET kind = $1,
+    name = $2,
+    version = CASE
+        WHEN (statement, context, expected_outcome, needed_skills, timebox_blocks, goal_id)
+            IS DISTINCT FROM ($3, $4, $5, $6::jsonb, $7::integer, $8::uuid) THEN version + 1
+        ELSE version
+    END,
+    statement = $3,
+    context = $4,
+    expected_outcome = $5,
+    needed_skills = $6,
+    timebox_blocks = $7,
+    goal_id = $8,
+    updated_at = $9
+FROM (SELECT version AS previous_version FROM intent_templates WHERE id = $10 FOR UPDATE) previous
+WHERE id = $10
+RETURNING ` + intentTemplateColumns + `, previous_version
+`
+
+	now := time.Now().UTC()
+
+	var previousVersion int
+	template, err := scanIntentTemplate(tx.QueryRowContext(ctx, query, input.Kind, input.Name, input.Statement, input.Context, input.ExpectedOutcome, string(skillsJSON), intPtrValue(input.TimeboxBlocks), uuidPtrValue(input.GoalID), now, id), &previousVersion)
+	if err != nil {
+		return IntentTemplate{}, err
+	}
+
+	if template.Version != previousVersion {
+		if err := recordIntentTemplateVersion(ctx, tx, id, now); err != nil {
+			return IntentTemplate{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return IntentTemplate{}, err
+	}
+
+	return template, nil
+}
+
+// DeleteIntentTemplate removes a template and its versions. Intents created
+// from it keep their text but lose the link.
+func DeleteIntentTemplate(ctx context.Context, db *sql.DB, id uuid.UUID) error {
+	if db == nil {
+		return errors.New("database handle is nil")
+	}
+
+	result, err := db.ExecContext(ctx, `DELETE FROM intent_templates WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ListIntentTemplateVersions returns every version of a template, oldest
+// first. It returns sql.ErrNoRows when the template does not exist.
+func ListIntentTemplateVersions(ctx context.Context, db *sql.DB, templateID uuid.UUID) ([]IntentTemplateVersion, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	query := `SELECT ` + intentTemplateVersionColumns + ` FROM intent_template_versions WHERE template_id = $1 ORDER BY version`
+
+	rows, err := db.QueryContext(ctx, query, templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make([]IntentTemplateVersion, 0)
+	for rows.Next() {
+		version, err := scanIntentTemplateVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(versions) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return versions, nil
+}
+
+// GetIntentTemplateVersion returns one version of a template, or the latest
+// when version is zero. It returns sql.ErrNoRows when there is no such
+// version.
+func GetIntentTemplateVersion(ctx context.Context, db *sql.DB, templateID uuid.UUID, version int) (IntentTemplateVersion, error) {
+	if db == nil {
+		return IntentTemplateVersion{}, errors.New("database handle is nil")
+	}
+
+	query := `SELECT ` + intentTemplateVersionColumns + ` FROM intent_template_versions WHERE template_id = $1`
+	args := []any{templateID}
+	if version > 0 {
+		query += ` AND version = $2`
+		args = append(args, version)
+	}
+	query += ` ORDER BY version DESC LIMIT 1`
+
+	return scanIntentTemplateVersion(db.QueryRowContext(ctx, query, args...))
+}
+
+// recordIntentTemplateVersion copies the template's current content into its
+// version history.
+func recordIntentTemplateVersion(ctx context.Context, q queryer, templateID uuid.UUID, now time.Time) error {
+	const query = `
+INSERT INTO intent_template_versions (` + intentTemplateVersionColumns + `)
+SELECT id, version, statement, context, expected_outcome, needed_skills, timebox_blocks, goal_id, $2
+FROM intent_templates
+WHERE id = $1
+`
+
+	_, err := q.ExecContext(ctx, query, templateID, now)
+	return err
+}
+
+func scanIntentTemplate(row rowScanner, extra ...any) (IntentTemplate, error) {
+	var (
+		template  IntentTemplate
+		chapterID uuid.NullUUID
+		skills    []byte
+		blocks    sql.NullInt64
+		goalID    uuid.NullUUID
+	)
+
+	dest := append([]any{
+		&template.ID,
+		&chapterID,
+		&template.Kind,
+		&template.Name,
+		&template.Version,
+		&template.Statement,
+		&template.Context,
+		&template.ExpectedOutcome,
+		&skills,
+		&blocks,
+		&goalID,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	}, extra...)
+
+	if err := row.Scan(dest...); err != nil {
+		return IntentTemplate{}, err
+	}
+
+	template.ChapterID = nullUUIDPtr(chapterID)
+	if err := scanTemplateContent(&template.IntentTemplateContent, skills, blocks, goalID); err != nil {
+		return IntentTemplate{}, err
+	}
+
+	return template, nil
+}
+
+func scanIntentTemplateVersion(row rowScanner) (IntentTemplateVersion, error) {
+	var (
+		version IntentTemplateVersion
+		skills  []byte
+		blocks  sql.NullInt64
+		goalID  uuid.NullUUID
+	)
+
+	if err := row.Scan(
+		&version.TemplateID,
+		&version.Version,
+		&version.Statement,
+		&version.Context,
+		&version.ExpectedOutcome,
+		&skills,
+		&blocks,
+		&goalID,
+		&version.CreatedAt,
+	); err != nil {
+		return IntentTemplateVersion{}, err
+	}
+
+	if err := scanTemplateContent(&version.IntentTemplateContent, skills, blocks, goalID); err != nil {
+		return IntentTemplateVersion{}, err
+	}
+
+	return version, nil
+}
+
+func scanTemplateContent(content *IntentTemplateContent, skills []byte, blocks sql.NullInt64, goalID uuid.NullUUID) error {
+	content.NeededSkills = []string{}
+	if len(skills) > 0 {
+		if err := json.Unmarshal(skills, &content.NeededSkills); err != nil {
+			return err
+		}
+	}
+
+	if blocks.Valid {
+		value := int(blocks.Int64)
+		content.TimeboxBlocks = &value
+	}
+
+	content.GoalID = nullUUIDPtr(goalID)
+	return nil
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestCreateIntentTemplateRecordsFirstVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	blocks := 2
+	input := IntentTemplateInput{
+		Kind: "spike",
+		Name: "Spike",
+		IntentTemplateContent: IntentTemplateContent{
+			Statement:     "Investigate {{topic}}",
+			TimeboxBlocks: &blocks,
+		},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO intent_templates").
+		WithArgs(sqlmock.AnyArg(), nil, "spike", "Spike", input.Statement, "", "", "[]", 2, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO intent_template_versions").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	template, err := CreateIntentTemplate(context.Background(), db, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if template.Version != 1 || template.NeededSkills == nil {
+		t.Fatalf("unexpected template %+v", template)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestUpdateIntentTemplateRecordsVersionWhenContentChanges(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id := uuid.New()
+	now := time.Now()
+	columns := []string{"id", "chapter_id", "kind", "name", "version", "statement", "context", "expected_outcome", "needed_skills", "timebox_blocks", "goal_id", "created_at", "updated_at", "previous_version"}
+	input := IntentTemplateInput{
+		Kind:                  "spike",
+		Name:                  "Spike",
+		IntentTemplateContent: IntentTemplateContent{Statement: "Investigate {{topic}} options", NeededSkills: []string{"go"}},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE intent_templates").
+		WithArgs("spike", "Spike", input.Statement, "", "", `["go"]`, nil, nil, sqlmock.AnyArg(), id).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(id, nil, "spike", "Spike", 3, input.Statement, "", "", []byte(`["go"]`), nil, nil, now, now, 2))
+	mock.ExpectExec("INSERT INTO intent_template_versions").
+		WithArgs(id, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	template, err := UpdateIntentTemplate(context.Background(), db, id, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if template.Version != 3 || len(template.NeededSkills) != 1 {
+		t.Fatalf("unexpected template %+v", template)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestUpdateIntentTemplateSkipsVersionForRename(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id := uuid.New()
+	now := time.Now()
+	columns := []string{"id", "chapter_id", "kind", "name", "version", "statement", "context", "expected_outcome", "needed_skills", "timebox_blocks", "goal_id", "created_at", "updated_at", "previous_version"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE intent_templates").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(id, nil, "spike", "Research spike", 2, "Investigate", "", "", []byte(`[]`), nil, nil, now, now, 2))
+	mock.ExpectCommit()
+
+	template, err := UpdateIntentTemplate(context.Background(), db, id, IntentTemplateInput{Kind: "spike", Name: "Research spike", IntentTemplateContent: IntentTemplateContent{Statement: "Investigate"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if template.Version != 2 {
+		t.Fatalf("expected version 2 got %d", template.Version)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestGetIntentTemplateVersionDefaultsToLatest(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id := uuid.New()
+	columns := []string{"template_id", "version", "statement", "context", "expected_outcome", "needed_skills", "timebox_blocks", "goal_id", "created_at"}
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM intent_template_versions WHERE template_id = $1 ORDER BY version DESC LIMIT 1")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(id, 4, "Investigate {{topic}}", "", "", []byte(`["go"]`), 3, nil, time.Now()))
+
+	version, err := GetIntentTemplateVersion(context.Background(), db, id, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if version.Version != 4 || version.TimeboxBlocks == nil || *version.TimeboxBlocks != 3 {
+		t.Fatalf("unexpected version %+v", version)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
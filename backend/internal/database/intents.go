@@ -12,6 +12,34 @@ import (
 	"github.com/google/uuid"
 )
 
+// Intent statuses. Draft intents are carried forward from a session
+// close-out and become active once their owner confirms them; done intents
+// have been delivered.
+const (
+	IntentDraft  = "draft"
+	IntentActive = "active"
+	IntentDone   = "done"
+)
+
+// intentColumns lists the intent columns in the order scanIntent expects.
+const intentColumns = "id, statement, context, expected_outcome, collaborators, status, member_id, goal_id, session_id, created_at, timebox, needed_skills, template_id, template_version, tags"
+
+var (
+	// ErrTimeboxSessionNotFound is returned when a timebox names a session
+	// that does not exist.
+	ErrTimeboxSessionNotFound = errors.New("timebox session not found")
+	// ErrDraftNeedsTimebox is returned when a draft intent without a timebox
+	// leaves draft. Close-out drafts may be created without one.
+	ErrDraftNeedsTimebox = errors.New("a draft intent needs a timebox before it leaves draft")
+)
+
+// Timebox bounds the work of an intent, either as a number of session blocks
+// or as the sessions it has to fit in.
+type Timebox struct {
+	Blocks     int         `json:"blocks,omitempty"`
+	SessionIDs []uuid.UUID `json:"sessionIds,omitempty"`
+}
+
 // Intent represents a submitted intent from an engineer.
 type Intent struct {
 	ID              uuid.UUID
@@ -19,23 +47,58 @@ type Intent struct {
 	Context         string
 	ExpectedOutcome string
 	Collaborators   []string
+	Status          string
+	MemberID        *uuid.UUID
+	GoalID          *uuid.UUID
+	SessionID       *uuid.UUID
+	Timebox         *Timebox
+	NeededSkills    []string
+	TemplateID      *uuid.UUID
+	TemplateVersion *int
+	Tags            []string
 	CreatedAt       time.Time
 }
 
-// IntentInput captures the minimal fields required to create an intent.
+// IntentInput captures the fields required to create an intent. Status
+// defaults to active; MemberID, GoalID and SessionID are optional links to
+// the owning member, the goal served and the session the work is planned for.
+// AcknowledgeGuardrails records that the owner accepted the goal's current
+// guardrails. TemplateID and TemplateVersion name the template version the
+// intent was created from and are fixed at creation. Tags replace the
+// intent's current tags.
 type IntentInput struct {
-	Statement       string
-	Context         string
-	ExpectedOutcome string
-	Collaborators   []string
+	Statement             string
+	Context               string
+	ExpectedOutcome       string
+	Collaborators         []string
+	Status                string
+	MemberID              *uuid.UUID
+	GoalID                *uuid.UUID
+	SessionID             *uuid.UUID
+	Timebox               *Timebox
+	NeededSkills          []string
+	TemplateID            *uuid.UUID
+	TemplateVersion       *int
+	Tags                  []string
+	AcknowledgeGuardrails bool
 }
 
 // IntentFilters capture optional filtering criteria when querying intents.
+// NeedsGuardrailAcknowledgment keeps intents whose goal's guardrails changed
+// since they were acknowledged, or were never acknowledged. Tags keeps
+// intents carrying any of the tags, or all of them when TagMatch is
+// TagMatchAll.
 type IntentFilters struct {
-	Query         string
-	Collaborator  string
-	CreatedAfter  *time.Time
-	CreatedBefore *time.Time
+	Query                        string
+	Collaborator                 string
+	Status                       string
+	SessionID                    *uuid.UUID
+	GoalID                       *uuid.UUID
+	Tags                         []string
+	TagMatch                     TagMatch
+	CreatedAfter                 *time.Time
+	CreatedBefore                *time.Time
+	NeedsGuardrailAcknowledgment bool
 }
 
 // Pagination captures offset-based pagination inputs.
@@ -44,10 +107,12 @@ type Pagination struct {
 	Offset int
 }
 
-// IntentListResult represents the outcome of a list query.
+// IntentListResult represents the outcome of a list query. Facets count the
+// matching intents per tag, status and goal.
 type IntentListResult struct {
 	Intents    []Intent
 	TotalCount int
+	Facets     Facets
 }
 
 // CreateIntent persists a new intent record and returns the stored entity.
@@ -56,31 +121,133 @@ func CreateIntent(ctx context.Context, db *sql.DB, input IntentInput) (Intent, e
 		return Intent{}, errors.New("database handle is nil")
 	}
 
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Intent{}, err
+	}
+	defer tx.Rollback()
+
+	if input.GoalID != nil {
+		if err := checkIntentGoal(ctx, tx, *input.GoalID, !input.AcknowledgeGuardrails); err != nil {
+			return Intent{}, err
+		}
+	}
+
+	intent, err := insertIntent(ctx, tx, input)
+	if err != nil {
+		return Intent{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Intent{}, err
+	}
+
+	return intent, nil
+}
+
+// insertIntent stores an intent, with its guardrail acknowledgment when
+// given, and records its intent.created event, so q should be the
+// transaction the intent is created in.
+func insertIntent(ctx context.Context, q queryer, input IntentInput) (Intent, error) {
 	collaboratorJSON, err := json.Marshal(input.Collaborators)
 	if err != nil {
 		return Intent{}, err
 	}
 
+	skillsJSON, err := json.Marshal(nonNilStrings(input.NeededSkills))
+	if err != nil {
+		return Intent{}, err
+	}
+
+	tagsJSON, err := json.Marshal(nonNilStrings(input.Tags))
+	if err != nil {
+		return Intent{}, err
+	}
+
+	timebox, err := timeboxValue(ctx, q, input.Timebox)
+	if err != nil {
+		return Intent{}, err
+	}
+
+	status := input.Status
+	if status == "" {
+		status = IntentActive
+	}
+
 	now := time.Now().UTC()
 	id := uuid.New()
 
 	const query = `
-INSERT INTO intents (id, statement, context, expected_outcome, collaborators, created_at)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO intents (id, statement, context, expected_outcome, collaborators, status, member_id, goal_id, session_id, created_at, timebox, needed_skills, template_id, template_version, tags)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 `
 
-	if _, err := db.ExecContext(ctx, query, id, input.Statement, input.Context, input.ExpectedOutcome, string(collaboratorJSON), now); err != nil {
+	if _, err := q.ExecContext(ctx, query, id, input.Statement, input.Context, input.ExpectedOutcome, string(collaboratorJSON), status, uuidPtrValue(input.MemberID), uuidPtrValue(input.GoalID), uuidPtrValue(input.SessionID), now, timebox, string(skillsJSON), uuidPtrValue(input.TemplateID), intPtrValue(input.TemplateVersion), string(tagsJSON)); err != nil {
 		return Intent{}, err
 	}
 
-	return Intent{
+	intent := Intent{
 		ID:              id,
 		Statement:       input.Statement,
 		Context:         input.Context,
 		ExpectedOutcome: input.ExpectedOutcome,
 		Collaborators:   input.Collaborators,
+		Status:          status,
+		MemberID:        input.MemberID,
+		GoalID:          input.GoalID,
+		SessionID:       input.SessionID,
+		Timebox:         input.Timebox,
+		NeededSkills:    nonNilStrings(input.NeededSkills),
+		TemplateID:      input.TemplateID,
+		TemplateVersion: input.TemplateVersion,
+		Tags:            nonNilStrings(input.Tags),
 		CreatedAt:       now,
-	}, nil
+	}
+
+	if err := recordIntentTransition(ctx, q, intent, nil, nil, now); err != nil {
+		return Intent{}, err
+	}
+
+	if input.GoalID != nil && input.AcknowledgeGuardrails {
+		if _, err := recordGuardrailAcknowledgment(ctx, q, id, *input.GoalID, now); err != nil {
+			return Intent{}, err
+		}
+	}
+
+	if err := recordWebhookEvent(ctx, q, EventIntentCreated, intentSnapshot(intent)); err != nil {
+		return Intent{}, err
+	}
+
+	return intent, nil
+}
+
+// recordIntentTransition appends the intent's status and goal to its history
+// when either differs from the previous ones. fromStatus is nil for a newly
+// created intent.
+func recordIntentTransition(ctx context.Context, q queryer, intent Intent, fromStatus *string, fromGoalID *uuid.UUID, at time.Time) error {
+	if fromStatus != nil && *fromStatus == intent.Status && sameUUID(fromGoalID, intent.GoalID) {
+		return nil
+	}
+
+	var from any
+	if fromStatus != nil {
+		from = *fromStatus
+	}
+
+	const query = `
+INSERT INTO intent_transitions (id, intent_id, from_status, to_status, goal_id, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+	_, err := q.ExecContext(ctx, query, uuid.New(), intent.ID, from, intent.Status, uuidPtrValue(intent.GoalID), at)
+	return err
+}
+
+func sameUUID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
 
 // GetIntent retrieves a single intent by identifier.
@@ -90,37 +257,47 @@ func GetIntent(ctx context.Context, db *sql.DB, id uuid.UUID) (Intent, error) {
 	}
 
 	const query = `
-SELECT id, statement, context, expected_outcome, collaborators, created_at
+SELECT ` + intentColumns + `
 FROM intents
 WHERE id = $1
 `
 
-	var (
-		intent  Intent
-		rawJSON []byte
-	)
+	return scanIntent(db.QueryRowContext(ctx, query, id))
+}
 
-	err := db.QueryRowContext(ctx, query, id).Scan(&intent.ID, &intent.Statement, &intent.Context, &intent.ExpectedOutcome, &rawJSON, &intent.CreatedAt)
+// UpdateIntent updates an existing intent and returns the persisted entity.
+// An empty Status and a nil Timebox leave the current values untouched; the
+// owning member is fixed at creation. Moving the intent to another goal returns
+// ErrGoalNotActive unless that goal is active, and ErrGuardrailsNotAcknowledged
+// unless its guardrails are acknowledged. A draft only leaves draft once it has
+// a timebox and its goal's guardrails are acknowledged.
+func UpdateIntent(ctx context.Context, db *sql.DB, id uuid.UUID, input IntentInput) (Intent, error) {
+	if db == nil {
+		return Intent{}, errors.New("database handle is nil")
+	}
+
+	collaboratorJSON, err := json.Marshal(input.Collaborators)
 	if err != nil {
 		return Intent{}, err
 	}
 
-	if len(rawJSON) > 0 {
-		if err := json.Unmarshal(rawJSON, &intent.Collaborators); err != nil {
-			return Intent{}, err
-		}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Intent{}, err
 	}
+	defer tx.Rollback()
 
-	return intent, nil
-}
+	skillsJSON, err := json.Marshal(nonNilStrings(input.NeededSkills))
+	if err != nil {
+		return Intent{}, err
+	}
 
-// UpdateIntent updates an existing intent and returns the persisted entity.
-func UpdateIntent(ctx context.Context, db *sql.DB, id uuid.UUID, input IntentInput) (Intent, error) {
-	if db == nil {
-		return Intent{}, errors.New("database handle is nil")
+	tagsJSON, err := json.Marshal(nonNilStrings(input.Tags))
+	if err != nil {
+		return Intent{}, err
 	}
 
-	collaboratorJSON, err := json.Marshal(input.Collaborators)
+	timebox, err := timeboxValue(ctx, tx, input.Timebox)
 	if err != nil {
 		return Intent{}, err
 	}
@@ -130,34 +307,60 @@ UPDATE intents
 SET statement = $1,
     context = $2,
     expected_outcome = $3,
-    collaborators = $4
-WHERE id = $5
-RETURNING id, statement, context, expected_outcome, collaborators, created_at
+    collaborators = $4,
+    status = COALESCE(NULLIF($5, ''), status),
+    goal_id = $6,
+    session_id = $7,
+    timebox = COALESCE($8, timebox),
+    needed_skills = $9,
+    tags = $10
+FROM (SELECT status AS previous_status, goal_id AS previous_goal_id FROM intents WHERE id = $11 FOR UPDATE) previous
+WHERE id = $11
+RETURNING ` + intentColumns + `, previous_status, previous_goal_id
 `
 
 	var (
-		intent  Intent
-		rawJSON []byte
+		previousStatus string
+		previousGoalID uuid.NullUUID
 	)
 
-	err = db.QueryRowContext(ctx, query, input.Statement, input.Context, input.ExpectedOutcome, string(collaboratorJSON), id).Scan(
-		&intent.ID,
-		&intent.Statement,
-		&intent.Context,
-		&intent.ExpectedOutcome,
-		&rawJSON,
-		&intent.CreatedAt,
-	)
+	intent, err := scanIntent(tx.QueryRowContext(ctx, query, input.Statement, input.Context, input.ExpectedOutcome, string(collaboratorJSON), input.Status, uuidPtrValue(input.GoalID), uuidPtrValue(input.SessionID), timebox, string(skillsJSON), string(tagsJSON), id), &previousStatus, &previousGoalID)
 	if err != nil {
 		return Intent{}, err
 	}
 
-	if len(rawJSON) > 0 {
-		if err := json.Unmarshal(rawJSON, &intent.Collaborators); err != nil {
+	now := time.Now().UTC()
+
+	if intent.GoalID != nil && !sameUUID(nullUUIDPtr(previousGoalID), intent.GoalID) {
+		if err := checkIntentGoal(ctx, tx, *intent.GoalID, !input.AcknowledgeGuardrails); err != nil {
+			return Intent{}, err
+		}
+	}
+
+	if previousStatus == IntentDraft && intent.Status != IntentDraft {
+		if err := checkDraftComplete(ctx, tx, intent, input.AcknowledgeGuardrails); err != nil {
+			return Intent{}, err
+		}
+	}
+
+	if intent.GoalID != nil && input.AcknowledgeGuardrails {
+		if _, err := recordGuardrailAcknowledgment(ctx, tx, intent.ID, *intent.GoalID, now); err != nil {
 			return Intent{}, err
 		}
 	}
 
+	if err := recordIntentTransition(ctx, tx, intent, &previousStatus, nullUUIDPtr(previousGoalID), now); err != nil {
+		return Intent{}, err
+	}
+
+	if err := recordWebhookEvent(ctx, tx, EventIntentUpdated, intentSnapshot(intent)); err != nil {
+		return Intent{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Intent{}, err
+	}
+
 	return intent, nil
 }
 
@@ -167,9 +370,15 @@ func DeleteIntent(ctx context.Context, db *sql.DB, id uuid.UUID) error {
 		return errors.New("database handle is nil")
 	}
 
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	const query = `DELETE FROM intents WHERE id = $1`
 
-	result, err := db.ExecContext(ctx, query, id)
+	result, err := tx.ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -183,7 +392,11 @@ func DeleteIntent(ctx context.Context, db *sql.DB, id uuid.UUID) error {
 		return sql.ErrNoRows
 	}
 
-	return nil
+	if err := recordWebhookEvent(ctx, tx, EventIntentDeleted, deletedResource{ID: id}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // ListIntents returns intents applying optional filters and pagination.
@@ -211,6 +424,30 @@ func ListIntents(ctx context.Context, db *sql.DB, filters IntentFilters, paginat
 		param++
 	}
 
+	if filters.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", param))
+		args = append(args, filters.Status)
+		param++
+	}
+
+	if filters.SessionID != nil {
+		conditions = append(conditions, fmt.Sprintf("session_id = $%d", param))
+		args = append(args, *filters.SessionID)
+		param++
+	}
+
+	if filters.GoalID != nil {
+		conditions = append(conditions, fmt.Sprintf("goal_id = $%d", param))
+		args = append(args, *filters.GoalID)
+		param++
+	}
+
+	if len(filters.Tags) > 0 {
+		conditions = append(conditions, tagCondition(filters.TagMatch, param))
+		args = append(args, textArrayLiteral(filters.Tags))
+		param++
+	}
+
 	if filters.CreatedAfter != nil {
 		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", param))
 		args = append(args, *filters.CreatedAfter)
@@ -223,6 +460,10 @@ func ListIntents(ctx context.Context, db *sql.DB, filters IntentFilters, paginat
 		param++
 	}
 
+	if filters.NeedsGuardrailAcknowledgment {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM goals g WHERE g.id = intents.goal_id AND "+guardrailsUnacknowledged+")")
+	}
+
 	whereClause := ""
 	if len(conditions) > 0 {
 		whereClause = " WHERE " + strings.Join(conditions, " AND ")
@@ -235,7 +476,7 @@ func ListIntents(ctx context.Context, db *sql.DB, filters IntentFilters, paginat
 		return IntentListResult{}, err
 	}
 
-	listQuery := "SELECT id, statement, context, expected_outcome, collaborators, created_at FROM intents" + whereClause + " ORDER BY created_at DESC"
+	listQuery := "SELECT " + intentColumns + " FROM intents" + whereClause + " ORDER BY created_at DESC"
 	listArgs := append([]any{}, args...)
 
 	if pagination.Limit > 0 {
@@ -257,21 +498,10 @@ func ListIntents(ctx context.Context, db *sql.DB, filters IntentFilters, paginat
 
 	intents := make([]Intent, 0)
 	for rows.Next() {
-		var (
-			intent  Intent
-			rawJSON []byte
-		)
-
-		if err := rows.Scan(&intent.ID, &intent.Statement, &intent.Context, &intent.ExpectedOutcome, &rawJSON, &intent.CreatedAt); err != nil {
+		intent, err := scanIntent(rows)
+		if err != nil {
 			return IntentListResult{}, err
 		}
-
-		if len(rawJSON) > 0 {
-			if err := json.Unmarshal(rawJSON, &intent.Collaborators); err != nil {
-				return IntentListResult{}, err
-			}
-		}
-
 		intents = append(intents, intent)
 	}
 
@@ -279,5 +509,139 @@ func ListIntents(ctx context.Context, db *sql.DB, filters IntentFilters, paginat
 		return IntentListResult{}, err
 	}
 
-	return IntentListResult{Intents: intents, TotalCount: total}, nil
+	facets, err := listFacets(ctx, db, facetQuery("intents", whereClause, facetColumn{FacetStatus, "status"}, facetColumn{FacetGoal, "goal_id"}), args)
+	if err != nil {
+		return IntentListResult{}, err
+	}
+
+	return IntentListResult{Intents: intents, TotalCount: total, Facets: facets}, nil
+}
+
+// scanIntent reads a row selected with intentColumns. Any extra destinations
+// are scanned after the intent columns.
+func scanIntent(row rowScanner, extra ...any) (Intent, error) {
+	var (
+		intent    Intent
+		rawJSON   []byte
+		memberID  uuid.NullUUID
+		goalID    uuid.NullUUID
+		sessionID uuid.NullUUID
+		timebox   []byte
+		skills    []byte
+		template  uuid.NullUUID
+		version   sql.NullInt64
+		tags      []byte
+	)
+
+	dest := append([]any{
+		&intent.ID,
+		&intent.Statement,
+		&intent.Context,
+		&intent.ExpectedOutcome,
+		&rawJSON,
+		&intent.Status,
+		&memberID,
+		&goalID,
+		&sessionID,
+		&intent.CreatedAt,
+		&timebox,
+		&skills,
+		&template,
+		&version,
+		&tags,
+	}, extra...)
+
+	if err := row.Scan(dest...); err != nil {
+		return Intent{}, err
+	}
+
+	if len(rawJSON) > 0 {
+		if err := json.Unmarshal(rawJSON, &intent.Collaborators); err != nil {
+			return Intent{}, err
+		}
+	}
+
+	intent.MemberID = nullUUIDPtr(memberID)
+	intent.GoalID = nullUUIDPtr(goalID)
+	intent.SessionID = nullUUIDPtr(sessionID)
+
+	if len(timebox) > 0 {
+		if err := json.Unmarshal(timebox, &intent.Timebox); err != nil {
+			return Intent{}, err
+		}
+	}
+
+	intent.NeededSkills = []string{}
+	if len(skills) > 0 {
+		if err := json.Unmarshal(skills, &intent.NeededSkills); err != nil {
+			return Intent{}, err
+		}
+	}
+
+	intent.TemplateID = nullUUIDPtr(template)
+	if version.Valid {
+		value := int(version.Int64)
+		intent.TemplateVersion = &value
+	}
+
+	intent.Tags = []string{}
+	if len(tags) > 0 {
+		if err := json.Unmarshal(tags, &intent.Tags); err != nil {
+			return Intent{}, err
+		}
+	}
+
+	return intent, nil
+}
+
+// checkDraftComplete holds a draft intent in draft until it has a timebox and
+// the guardrails of its goal are acknowledged, the rules new intents are
+// created under. acknowledging reports that the change records an
+// acknowledgment itself.
+func checkDraftComplete(ctx context.Context, q queryer, intent Intent, acknowledging bool) error {
+	if intent.Timebox == nil {
+		return ErrDraftNeedsTimebox
+	}
+
+	if intent.GoalID == nil || acknowledging {
+		return nil
+	}
+
+	query := `SELECT EXISTS (SELECT 1 FROM intents JOIN goals g ON g.id = intents.goal_id WHERE intents.id = $1 AND ` + guardrailsUnacknowledged + `)`
+
+	var unacknowledged bool
+	if err := q.QueryRowContext(ctx, query, intent.ID).Scan(&unacknowledged); err != nil {
+		return err
+	}
+
+	if unacknowledged {
+		return ErrGuardrailsNotAcknowledged
+	}
+
+	return nil
+}
+
+// timeboxValue checks that the sessions of a timebox exist and returns its
+// JSON, or nil when there is no timebox.
+func timeboxValue(ctx context.Context, q queryer, timebox *Timebox) (any, error) {
+	if timebox == nil {
+		return nil, nil
+	}
+
+	if len(timebox.SessionIDs) > 0 {
+		var found int
+		if err := q.QueryRowContext(ctx, `SELECT COUNT(*) FROM sessions WHERE id = ANY($1::uuid[])`, uuidArrayLiteral(timebox.SessionIDs)).Scan(&found); err != nil {
+			return nil, err
+		}
+		if found != len(timebox.SessionIDs) {
+			return nil, ErrTimeboxSessionNotFound
+		}
+	}
+
+	raw, err := json.Marshal(timebox)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(raw), nil
 }
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"regexp"
 	"testing"
 	"time"
@@ -11,6 +12,12 @@ import (
 	"github.com/google/uuid"
 )
 
+func expectIntentTransition(mock sqlmock.Sqlmock, intentID, fromStatus any, toStatus string) {
+	mock.ExpectExec("INSERT INTO intent_transitions").
+		WithArgs(sqlmock.AnyArg(), intentID, fromStatus, toStatus, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
 func TestGetIntentSuccess(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -21,10 +28,10 @@ func TestGetIntentSuccess(t *testing.T) {
 	id := uuid.New()
 	createdAt := time.Now().UTC()
 
-	rows := sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "created_at"}).
-		AddRow(id, "statement", "context", "outcome", `["Jamie"]`, createdAt)
+	rows := sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags"}).
+		AddRow(id, "statement", "context", "outcome", `["Jamie"]`, "active", nil, nil, nil, createdAt, nil, nil, nil, nil, nil)
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, statement, context, expected_outcome, collaborators, created_at FROM intents WHERE id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, statement, context, expected_outcome, collaborators, status, member_id, goal_id, session_id, created_at, timebox, needed_skills, template_id, template_version, tags FROM intents WHERE id = $1")).
 		WithArgs(id).
 		WillReturnRows(rows)
 
@@ -55,7 +62,7 @@ func TestGetIntentNotFound(t *testing.T) {
 
 	id := uuid.New()
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, statement, context, expected_outcome, collaborators, created_at FROM intents WHERE id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, statement, context, expected_outcome, collaborators, status, member_id, goal_id, session_id, created_at, timebox, needed_skills, template_id, template_version, tags FROM intents WHERE id = $1")).
 		WithArgs(id).
 		WillReturnError(sql.ErrNoRows)
 
@@ -85,16 +92,27 @@ func TestUpdateIntentSuccess(t *testing.T) {
 		Collaborators:   []string{"Jamie", "Ana"},
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE intents
 SET statement = $1,
     context = $2,
     expected_outcome = $3,
-    collaborators = $4
-WHERE id = $5
-RETURNING id, statement, context, expected_outcome, collaborators, created_at`)).
-		WithArgs(input.Statement, input.Context, input.ExpectedOutcome, `["Jamie","Ana"]`, id).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "created_at"}).
-			AddRow(id, input.Statement, input.Context, input.ExpectedOutcome, `["Jamie","Ana"]`, createdAt))
+    collaborators = $4,
+    status = COALESCE(NULLIF($5, ''), status),
+    goal_id = $6,
+    session_id = $7,
+    timebox = COALESCE($8, timebox),
+    needed_skills = $9,
+    tags = $10
+FROM (SELECT status AS previous_status, goal_id AS previous_goal_id FROM intents WHERE id = $11 FOR UPDATE) previous
+WHERE id = $11
+RETURNING id, statement, context, expected_outcome, collaborators, status, member_id, goal_id, session_id, created_at, timebox, needed_skills, template_id, template_version, tags, previous_status, previous_goal_id`)).
+		WithArgs(input.Statement, input.Context, input.ExpectedOutcome, `["Jamie","Ana"]`, "", nil, nil, nil, "[]", "[]", id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags", "previous_status", "previous_goal_id"}).
+			AddRow(id, input.Statement, input.Context, input.ExpectedOutcome, `["Jamie","Ana"]`, "active", nil, nil, nil, createdAt, []byte(`{"blocks":2}`), nil, nil, nil, nil, "draft", nil))
+	expectIntentTransition(mock, id, "draft", IntentActive)
+	expectWebhookEvent(mock, EventIntentUpdated)
+	mock.ExpectCommit()
 
 	intent, err := UpdateIntent(context.Background(), db, id, input)
 	if err != nil {
@@ -110,6 +128,49 @@ RETURNING id, statement, context, expected_outcome, collaborators, created_at`))
 	}
 }
 
+func TestUpdateIntentKeepsIncompleteDraftInDraft(t *testing.T) {
+	id, goalID := uuid.New(), uuid.New()
+	createdAt := time.Now().UTC()
+	columns := []string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags", "previous_status", "previous_goal_id"}
+
+	for _, tc := range []struct {
+		name    string
+		timebox any
+		want    error
+	}{
+		{name: "without timebox", timebox: nil, want: ErrDraftNeedsTimebox},
+		{name: "with unacknowledged guardrails", timebox: []byte(`{"blocks":2}`), want: ErrGuardrailsNotAcknowledged},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create sqlmock: %v", err)
+			}
+			t.Cleanup(func() { db.Close() })
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta("UPDATE intents")).
+				WillReturnRows(sqlmock.NewRows(columns).
+					AddRow(id, "Ship it", "", "", `[]`, "active", nil, goalID, nil, createdAt, tc.timebox, nil, nil, nil, nil, "draft", goalID))
+			if tc.timebox != nil {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM intents JOIN goals g ON g.id = intents.goal_id WHERE intents.id = $1 AND")).
+					WithArgs(id).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			}
+			mock.ExpectRollback()
+
+			_, err = UpdateIntent(context.Background(), db, id, IntentInput{Statement: "Ship it", Status: IntentActive, GoalID: &goalID})
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v got %v", tc.want, err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("expectations: %v", err)
+			}
+		})
+	}
+}
+
 func TestDeleteIntent(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -119,9 +180,12 @@ func TestDeleteIntent(t *testing.T) {
 
 	id := uuid.New()
 
+	mock.ExpectBegin()
 	mock.ExpectExec("DELETE FROM intents").
 		WithArgs(id).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectWebhookEvent(mock, EventIntentDeleted)
+	mock.ExpectCommit()
 
 	if err := DeleteIntent(context.Background(), db, id); err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -141,9 +205,11 @@ func TestDeleteIntentNotFound(t *testing.T) {
 
 	id := uuid.New()
 
+	mock.ExpectBegin()
 	mock.ExpectExec("DELETE FROM intents").
 		WithArgs(id).
 		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
 
 	if err := DeleteIntent(context.Background(), db, id); err != sql.ErrNoRows {
 		t.Fatalf("expected sql.ErrNoRows, got %v", err)
@@ -173,10 +239,14 @@ func TestListIntentsWithFilters(t *testing.T) {
 		WithArgs(pattern, pattern, pattern, filters.Collaborator).
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, statement, context, expected_outcome, collaborators, created_at FROM intents WHERE (statement ILIKE $1 OR context ILIKE $2 OR expected_outcome ILIKE $3) AND EXISTS (SELECT 1 FROM jsonb_array_elements_text(collaborators) AS c WHERE LOWER(c) = LOWER($4)) ORDER BY created_at DESC LIMIT $5 OFFSET $6")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, statement, context, expected_outcome, collaborators, status, member_id, goal_id, session_id, created_at, timebox, needed_skills, template_id, template_version, tags FROM intents WHERE (statement ILIKE $1 OR context ILIKE $2 OR expected_outcome ILIKE $3) AND EXISTS (SELECT 1 FROM jsonb_array_elements_text(collaborators) AS c WHERE LOWER(c) = LOWER($4)) ORDER BY created_at DESC LIMIT $5 OFFSET $6")).
 		WithArgs(pattern, pattern, pattern, filters.Collaborator, pagination.Limit, pagination.Offset).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "created_at"}).
-			AddRow(id, "statement", "context", "outcome", `["Jamie"]`, createdAt))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags"}).
+			AddRow(id, "statement", "context", "outcome", `["Jamie"]`, "active", nil, nil, nil, createdAt, nil, nil, nil, nil, nil))
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT 'status', COALESCE(status::text, ''), COUNT(*) FROM intents WHERE (statement ILIKE $1")).
+		WithArgs(pattern, pattern, pattern, filters.Collaborator).
+		WillReturnRows(sqlmock.NewRows([]string{"facet", "value", "count"}).AddRow(FacetStatus, IntentActive, 1).AddRow(FacetGoal, "", 1))
 
 	result, err := ListIntents(context.Background(), db, filters, pagination)
 	if err != nil {
@@ -195,3 +265,32 @@ func TestListIntentsWithFilters(t *testing.T) {
 		t.Fatalf("expectations: %v", err)
 	}
 }
+
+func TestUpdateIntentRejectsInactiveGoal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id, goalID := uuid.New(), uuid.New()
+	input := IntentInput{Statement: "updated", Context: "context", ExpectedOutcome: "outcome", Collaborators: []string{}, GoalID: &goalID}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE intents")).
+		WithArgs(input.Statement, input.Context, input.ExpectedOutcome, `[]`, "", goalID, nil, nil, "[]", "[]", id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags", "previous_status", "previous_goal_id"}).
+			AddRow(id, input.Statement, input.Context, input.ExpectedOutcome, `[]`, "active", nil, goalID, nil, time.Now().UTC(), nil, nil, nil, nil, nil, "active", nil))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status, jsonb_array_length(guardrails) FROM goals WHERE id = $1")).
+		WithArgs(goalID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "guardrails"}).AddRow(GoalPaused, 0))
+	mock.ExpectRollback()
+
+	if _, err := UpdateIntent(context.Background(), db, id, input); !errors.Is(err, ErrGoalNotActive) {
+		t.Fatalf("expected ErrGoalNotActive got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
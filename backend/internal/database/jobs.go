@@ -0,0 +1,286 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job states. Pending jobs run once run_at passes; running jobs are held by
+// a worker until their lease lapses; dead jobs exhausted their attempts.
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobDead    = "dead"
+)
+
+// DefaultJobAttempts is how many times a job runs before it is dead-lettered.
+const DefaultJobAttempts = 5
+
+// ErrJobNotDead is returned when retrying a job that has not been
+// dead-lettered.
+var ErrJobNotDead = errors.New("job is not dead")
+
+// ErrJobLeaseLost is returned when completing or failing a job whose lease
+// has expired and which has since been reclaimed or dead-lettered.
+var ErrJobLeaseLost = errors.New("job lease was lost")
+
+// Job is a unit of background work claimed by the worker pool.
+type Job struct {
+	ID          uuid.UUID
+	Kind        string
+	Key         string
+	Payload     json.RawMessage
+	Status      string
+	Attempts    int
+	MaxAttempts int
+	RunAt       time.Time
+	LockedUntil *time.Time
+	LastError   string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// JobInput captures the fields required to enqueue a job. Key identifies the
+// job within its kind; enqueueing the same kind and key twice is a no-op.
+type JobInput struct {
+	Kind        string
+	Key         string
+	Payload     any
+	RunAt       time.Time
+	MaxAttempts int
+}
+
+const jobColumns = `id, kind, key, payload, status, attempts, max_attempts, run_at, locked_until, last_error, created_at, updated_at`
+
+// EnqueueJob adds a job to the queue unless one with the same kind and key
+// already exists. The boolean reports whether a new job was created.
+func EnqueueJob(ctx context.Context, db *sql.DB, input JobInput) (bool, error) {
+	if db == nil {
+		return false, errors.New("database handle is nil")
+	}
+
+	return enqueueJob(ctx, db, input, time.Now().UTC())
+}
+
+// EnqueueJobTx adds a job inside tx, so it only becomes visible to workers
+// if the transaction commits. Like EnqueueJob it is a no-op for a kind and
+// key that already exist.
+func EnqueueJobTx(ctx context.Context, tx *sql.Tx, input JobInput) (bool, error) {
+	if tx == nil {
+		return false, errors.New("transaction is nil")
+	}
+
+	return enqueueJob(ctx, tx, input, time.Now().UTC())
+}
+
+func enqueueJob(ctx context.Context, q queryer, input JobInput, now time.Time) (bool, error) {
+	payload, err := json.Marshal(input.Payload)
+	if err != nil {
+		return false, err
+	}
+
+	attempts := input.MaxAttempts
+	if attempts <= 0 {
+		attempts = DefaultJobAttempts
+	}
+
+	runAt := input.RunAt.UTC()
+	if runAt.IsZero() {
+		runAt = now
+	}
+
+	const query = `
+INSERT INTO jobs (id, kind, key, payload, max_attempts, run_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+ON CONFLICT (kind, key) DO NOTHING
+`
+
+	result, err := q.ExecContext(ctx, query, uuid.New(), input.Kind, input.Key, payload, attempts, runAt, now)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// ClaimJob leases the next due job of one of the given kinds to the caller
+// until now plus lease. Jobs held by a worker whose lease lapsed are
+// reclaimed, or dead-lettered if that was their last attempt. Concurrent
+// workers skip each other's rows rather than blocking. sql.ErrNoRows is
+// returned when nothing is due.
+func ClaimJob(ctx context.Context, db *sql.DB, kinds []string, lease time.Duration) (Job, error) {
+	if db == nil {
+		return Job{}, errors.New("database handle is nil")
+	}
+
+	now := time.Now().UTC()
+
+	const query = `
+WITH expired AS (
+    UPDATE jobs
+    SET status = 'dead', locked_until = NULL, last_error = 'lease expired on the final attempt', updated_at = $2
+    WHERE kind = ANY($1::text[])
+      AND status = 'running' AND locked_until <= $2 AND attempts >= max_attempts
+)
+UPDATE jobs
+SET status = 'running', attempts = attempts + 1, locked_until = $3, updated_at = $2
+WHERE id = (
+    SELECT id FROM jobs
+    WHERE kind = ANY($1::text[])
+      AND ((status = 'pending' AND run_at <= $2) OR (status = 'running' AND locked_until <= $2 AND attempts < max_attempts))
+    ORDER BY run_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING ` + jobColumns
+
+	return scanJob(db.QueryRowContext(ctx, query, textArrayLiteral(kinds), now, now.Add(lease)))
+}
+
+// CompleteJob marks a claimed job as done. ErrJobLeaseLost is returned when
+// the claim no longer holds the job's lease.
+func CompleteJob(ctx context.Context, db *sql.DB, job Job) error {
+	if db == nil {
+		return errors.New("database handle is nil")
+	}
+
+	const query = `
+UPDATE jobs SET status = 'done', locked_until = NULL, last_error = NULL, updated_at = $2
+WHERE id = $1 AND attempts = $3 AND status = 'running'
+`
+
+	result, err := db.ExecContext(ctx, query, job.ID, time.Now().UTC(), job.Attempts)
+	if err != nil {
+		return err
+	}
+
+	return requireLease(result)
+}
+
+// FailJob records a failed attempt. The job runs again at retryAt unless it
+// has used all of its attempts, in which case it is dead-lettered. The
+// returned status is the job's new state. ErrJobLeaseLost is returned when
+// the claim no longer holds the job's lease.
+func FailJob(ctx context.Context, db *sql.DB, job Job, cause error, retryAt time.Time) (string, error) {
+	if db == nil {
+		return "", errors.New("database handle is nil")
+	}
+
+	status := JobPending
+	if job.Attempts >= job.MaxAttempts {
+		status = JobDead
+	}
+
+	const query = `
+UPDATE jobs SET status = $2, run_at = $3, locked_until = NULL, last_error = $4, updated_at = $5
+WHERE id = $1 AND attempts = $6 AND status = 'running'
+`
+
+	result, err := db.ExecContext(ctx, query, job.ID, status, retryAt.UTC(), cause.Error(), time.Now().UTC(), job.Attempts)
+	if err != nil {
+		return "", err
+	}
+
+	if err := requireLease(result); err != nil {
+		return "", err
+	}
+
+	return status, nil
+}
+
+// requireLease reports ErrJobLeaseLost when a lease-guarded update matched no
+// row. Each claim bumps attempts, so a stale claim no longer matches.
+func requireLease(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrJobLeaseLost
+	}
+
+	return nil
+}
+
+// ListJobs returns jobs in the given state, most recently updated first.
+func ListJobs(ctx context.Context, db *sql.DB, status string, limit int) ([]Job, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE status = $1 ORDER BY updated_at DESC LIMIT $2`
+
+	rows, err := db.QueryContext(ctx, query, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := make([]Job, 0)
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// RetryJob gives a dead-lettered job a fresh set of attempts, due now.
+func RetryJob(ctx context.Context, db *sql.DB, id uuid.UUID) (Job, error) {
+	if db == nil {
+		return Job{}, errors.New("database handle is nil")
+	}
+
+	const query = `
+UPDATE jobs SET status = 'pending', attempts = 0, run_at = $2, updated_at = $2
+WHERE id = $1 AND status = 'dead'
+RETURNING ` + jobColumns
+
+	job, err := scanJob(db.QueryRowContext(ctx, query, id, time.Now().UTC()))
+	if errors.Is(err, sql.ErrNoRows) {
+		var exists bool
+		if err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM jobs WHERE id = $1)`, id).Scan(&exists); err != nil {
+			return Job{}, err
+		}
+		if exists {
+			return Job{}, ErrJobNotDead
+		}
+		return Job{}, sql.ErrNoRows
+	}
+
+	return job, err
+}
+
+func scanJob(row rowScanner) (Job, error) {
+	var (
+		job         Job
+		payload     []byte
+		lockedUntil sql.NullTime
+		lastError   sql.NullString
+	)
+
+	if err := row.Scan(&job.ID, &job.Kind, &job.Key, &payload, &job.Status, &job.Attempts, &job.MaxAttempts, &job.RunAt, &lockedUntil, &lastError, &job.CreatedAt, &job.UpdatedAt); err != nil {
+		return Job{}, err
+	}
+
+	job.Payload = json.RawMessage(payload)
+	job.LockedUntil = nullTimePtr(lockedUntil)
+	job.LastError = lastError.String
+
+	return job, nil
+}
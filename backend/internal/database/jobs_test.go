@@ -0,0 +1,269 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+var jobRowColumns = []string{"id", "kind", "key", "payload", "status", "attempts", "max_attempts", "run_at", "locked_until", "last_error", "created_at", "updated_at"}
+
+func TestEnqueueJobIsIdempotent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectExec(regexp.QuoteMeta("ON CONFLICT (kind, key) DO NOTHING")).
+		WithArgs(sqlmock.AnyArg(), "session.nudge", "abc:24h", []byte(`{"n":1}`), DefaultJobAttempts, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	created, err := EnqueueJob(context.Background(), db, JobInput{Kind: "session.nudge", Key: "abc:24h", Payload: map[string]int{"n": 1}})
+	if err != nil {
+		t.Fatalf("EnqueueJob returned error: %v", err)
+	}
+	if created {
+		t.Fatal("expected duplicate job not to be created")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestClaimJobSkipsLockedRows(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	jobID := uuid.New()
+	now := time.Now().UTC()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+		WithArgs(`{"nudge.plan","session.nudge"}`, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(jobRowColumns).
+			AddRow(jobID, "session.nudge", "abc:1h", []byte(`{}`), JobRunning, 1, 5, now, now.Add(time.Minute), nil, now, now))
+
+	job, err := ClaimJob(context.Background(), db, []string{"nudge.plan", "session.nudge"}, time.Minute)
+	if err != nil {
+		t.Fatalf("ClaimJob returned error: %v", err)
+	}
+	if job.ID != jobID || job.Status != JobRunning || job.LockedUntil == nil || job.LastError != "" {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestFailJobDeadLettersAfterMaxAttempts(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		want     string
+	}{
+		{name: "retries", attempts: 2, want: JobPending},
+		{name: "dead", attempts: 3, want: JobDead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create sqlmock: %v", err)
+			}
+			t.Cleanup(func() { db.Close() })
+
+			job := Job{ID: uuid.New(), Attempts: tt.attempts, MaxAttempts: 3}
+			retryAt := time.Now().UTC().Add(time.Minute)
+
+			mock.ExpectExec(regexp.QuoteMeta("UPDATE jobs SET status = $2")).
+				WithArgs(job.ID, tt.want, retryAt, "boom", sqlmock.AnyArg(), tt.attempts).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			status, err := FailJob(context.Background(), db, job, errors.New("boom"), retryAt)
+			if err != nil {
+				t.Fatalf("FailJob returned error: %v", err)
+			}
+			if status != tt.want {
+				t.Fatalf("expected status %q got %q", tt.want, status)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatalf("unmet expectations: %v", err)
+			}
+		})
+	}
+}
+
+func TestClaimJobDeadLettersExhaustedExpiredLeases(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectQuery(regexp.QuoteMeta("SET status = 'dead', locked_until = NULL, last_error = 'lease expired on the final attempt'")).
+		WithArgs(`{"session.nudge"}`, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(jobRowColumns))
+
+	if _, err := ClaimJob(context.Background(), db, []string{"session.nudge"}, time.Minute); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestCompleteJobRequiresLease(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	job := Job{ID: uuid.New(), Attempts: 2, MaxAttempts: 5}
+
+	// Another worker reclaimed the job after the lease lapsed.
+	mock.ExpectExec(regexp.QuoteMeta("WHERE id = $1 AND attempts = $3 AND status = 'running'")).
+		WithArgs(job.ID, sqlmock.AnyArg(), 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := CompleteJob(context.Background(), db, job); !errors.Is(err, ErrJobLeaseLost) {
+		t.Fatalf("expected ErrJobLeaseLost got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestFailJobRequiresLease(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	job := Job{ID: uuid.New(), Attempts: 1, MaxAttempts: 5}
+	retryAt := time.Now().UTC().Add(time.Minute)
+
+	mock.ExpectExec(regexp.QuoteMeta("WHERE id = $1 AND attempts = $6 AND status = 'running'")).
+		WithArgs(job.ID, JobPending, retryAt, "boom", sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if _, err := FailJob(context.Background(), db, job, errors.New("boom"), retryAt); !errors.Is(err, ErrJobLeaseLost) {
+		t.Fatalf("expected ErrJobLeaseLost got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestRetryJobRejectsLiveJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	jobID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE id = $1 AND status = 'dead'")).
+		WithArgs(jobID, sqlmock.AnyArg()).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM jobs WHERE id = $1)")).
+		WithArgs(jobID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	if _, err := RetryJob(context.Background(), db, jobID); !errors.Is(err, ErrJobNotDead) {
+		t.Fatalf("expected ErrJobNotDead got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestRecordSessionNudgesDeliversNewNudgesOnly(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, declareID, refineID := uuid.New(), uuid.New(), uuid.New()
+	startsAt := time.Now().UTC().Add(time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("ON CONFLICT (session_id, member_id, lead) DO NOTHING")).
+		WithArgs(sessionID, "1h", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"member_id", "reason"}).
+			AddRow(declareID, NudgeDeclare).
+			AddRow(refineID, NudgeRefine))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT starts_at FROM sessions WHERE id = $1")).
+		WithArgs(sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"starts_at"}).AddRow(startsAt))
+	mock.ExpectCommit()
+
+	delivered := make([]uuid.UUID, 0)
+	nudges, err := RecordSessionNudges(context.Background(), db, sessionID, "1h", func(_ *sql.Tx, nudge SessionNudge) error {
+		if !nudge.SessionStartsAt.Equal(startsAt) {
+			t.Errorf("expected session start %v got %v", startsAt, nudge.SessionStartsAt)
+		}
+		delivered = append(delivered, nudge.MemberID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RecordSessionNudges returned error: %v", err)
+	}
+	if len(nudges) != 2 || len(delivered) != 2 || nudges[1].Reason != NudgeRefine {
+		t.Fatalf("unexpected nudges: %+v", nudges)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestRecordSessionNudgesRollsBackOnDeliveryFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO session_nudges")).
+		WithArgs(sessionID, "24h", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"member_id", "reason"}).AddRow(uuid.New(), NudgeDeclare))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT starts_at FROM sessions WHERE id = $1")).
+		WithArgs(sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"starts_at"}).AddRow(time.Now().UTC().Add(24 * time.Hour)))
+	mock.ExpectRollback()
+
+	_, err = RecordSessionNudges(context.Background(), db, sessionID, "24h", func(*sql.Tx, SessionNudge) error {
+		return errors.New("inbox unavailable")
+	})
+	if err == nil {
+		t.Fatal("expected delivery failure to be returned")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
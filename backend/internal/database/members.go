@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Member roles recognised by the platform.
+const (
+	RoleMember       = "member"
+	RoleChapterLead  = "chapter_lead"
+	RoleFacilitator  = "facilitator"
+	RoleSkillSteward = "skill_steward"
+)
+
+// ErrMemberNotInChapter is returned when a member is referenced from a
+// session or swarm belonging to a different chapter instance.
+var ErrMemberNotInChapter = errors.New("member does not belong to the session's chapter")
+
+// Member represents an engineer belonging to a chapter instance.
+type Member struct {
+	ID          uuid.UUID
+	ChapterID   uuid.UUID
+	DisplayName string
+	Email       string
+	Role        string
+	CreatedAt   time.Time
+}
+
+// MemberInput captures the fields required to register a member.
+type MemberInput struct {
+	ChapterID   uuid.UUID
+	DisplayName string
+	Email       string
+	Role        string
+}
+
+// CreateMember persists a new chapter member.
+func CreateMember(ctx context.Context, db *sql.DB, input MemberInput) (Member, error) {
+	if db == nil {
+		return Member{}, errors.New("database handle is nil")
+	}
+
+	now := time.Now().UTC()
+	id := uuid.New()
+
+	const query = `
+INSERT INTO members (id, chapter_id, display_name, email, role, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+	if _, err := db.ExecContext(ctx, query, id, input.ChapterID, input.DisplayName, input.Email, input.Role, now); err != nil {
+		return Member{}, err
+	}
+
+	return Member{
+		ID:          id,
+		ChapterID:   input.ChapterID,
+		DisplayName: input.DisplayName,
+		Email:       input.Email,
+		Role:        input.Role,
+		CreatedAt:   now,
+	}, nil
+}
+
+// GetMember retrieves a member by identifier.
+func GetMember(ctx context.Context, db *sql.DB, id uuid.UUID) (Member, error) {
+	if db == nil {
+		return Member{}, errors.New("database handle is nil")
+	}
+
+	const query = `
+SELECT id, chapter_id, display_name, email, role, created_at
+FROM members
+WHERE id = $1
+`
+
+	var member Member
+	err := db.QueryRowContext(ctx, query, id).Scan(&member.ID, &member.ChapterID, &member.DisplayName, &member.Email, &member.Role, &member.CreatedAt)
+	if err != nil {
+		return Member{}, err
+	}
+
+	return member, nil
+}
+
+// ListMembers returns members ordered by display name, optionally limited to
+// a single chapter instance.
+func ListMembers(ctx context.Context, db *sql.DB, chapterID *uuid.UUID) ([]Member, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	query := "SELECT id, chapter_id, display_name, email, role, created_at FROM members"
+	var args []any
+	if chapterID != nil {
+		query += " WHERE chapter_id = $1"
+		args = append(args, *chapterID)
+	}
+	query += " ORDER BY display_name"
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := make([]Member, 0)
+	for rows.Next() {
+		var member Member
+		if err := rows.Scan(&member.ID, &member.ChapterID, &member.DisplayName, &member.Email, &member.Role, &member.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
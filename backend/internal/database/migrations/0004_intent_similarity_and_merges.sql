@@ -0,0 +1,29 @@
+-- pg_trgm powers duplicate detection, but not every server ships it or lets
+-- the migration role install it. Without it the migration still succeeds and
+-- the backend compares trigrams in Go instead.
+DO $$
+BEGIN
+    CREATE EXTENSION IF NOT EXISTS pg_trgm;
+EXCEPTION
+    WHEN OTHERS THEN
+        RAISE NOTICE 'pg_trgm unavailable, intent similarity falls back to Go: %', SQLERRM;
+END
+$$;
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_trgm') THEN
+        CREATE INDEX IF NOT EXISTS intents_statement_trgm_idx ON intents USING GIN (statement gin_trgm_ops);
+    END IF;
+END
+$$;
+
+CREATE TABLE IF NOT EXISTS intent_merges (
+    id UUID PRIMARY KEY,
+    surviving_intent_id UUID NOT NULL,
+    absorbed_intent_id UUID NOT NULL UNIQUE,
+    absorbed_snapshot JSONB NOT NULL,
+    merged_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS intent_merges_surviving_intent_id_idx ON intent_merges (surviving_intent_id);
@@ -0,0 +1,78 @@
+CREATE TABLE IF NOT EXISTS chapters (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    max_concurrent_swarms INTEGER NOT NULL DEFAULT 3 CHECK (max_concurrent_swarms > 0),
+    block_minutes INTEGER NOT NULL DEFAULT 60 CHECK (block_minutes > 0),
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS members (
+    id UUID PRIMARY KEY,
+    chapter_id UUID NOT NULL REFERENCES chapters(id) ON DELETE CASCADE,
+    display_name TEXT NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    role TEXT NOT NULL DEFAULT 'member',
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS members_chapter_id_idx ON members (chapter_id);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id UUID PRIMARY KEY,
+    chapter_id UUID NOT NULL REFERENCES chapters(id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (chapter_id, starts_at),
+    CHECK (starts_at < ends_at)
+);
+
+CREATE TABLE IF NOT EXISTS swarms (
+    id UUID PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    mission TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'dissolved')),
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    CHECK (starts_at < ends_at)
+);
+
+CREATE INDEX IF NOT EXISTS swarms_session_id_idx ON swarms (session_id);
+
+CREATE TABLE IF NOT EXISTS swarm_members (
+    swarm_id UUID NOT NULL REFERENCES swarms(id) ON DELETE CASCADE,
+    member_id UUID NOT NULL REFERENCES members(id) ON DELETE CASCADE,
+    joined_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (swarm_id, member_id)
+);
+
+CREATE TABLE IF NOT EXISTS availability (
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    member_id UUID NOT NULL REFERENCES members(id) ON DELETE CASCADE,
+    status TEXT NOT NULL CHECK (status IN ('available', 'partial', 'unavailable')),
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (session_id, member_id),
+    CHECK (status <> 'partial' OR (starts_at IS NOT NULL AND ends_at IS NOT NULL AND starts_at < ends_at))
+);
+
+CREATE TABLE IF NOT EXISTS commitments (
+    id UUID PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    member_id UUID NOT NULL REFERENCES members(id) ON DELETE CASCADE,
+    intent_id UUID REFERENCES intents(id) ON DELETE CASCADE,
+    swarm_id UUID REFERENCES swarms(id) ON DELETE CASCADE,
+    blocks INTEGER NOT NULL CHECK (blocks > 0),
+    created_at TIMESTAMPTZ NOT NULL,
+    CHECK ((intent_id IS NULL) <> (swarm_id IS NULL)),
+    UNIQUE (session_id, member_id, intent_id),
+    UNIQUE (session_id, member_id, swarm_id)
+);
+
+CREATE INDEX IF NOT EXISTS commitments_member_session_idx ON commitments (member_id, session_id);
@@ -0,0 +1,11 @@
+ALTER TABLE members ADD COLUMN IF NOT EXISTS calendar_token_hash TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS members_calendar_token_hash_idx ON members (calendar_token_hash);
+
+-- SEQUENCE values published in calendar feeds; bumped whenever the event a
+-- row renders to changes so subscribed clients replace their copy.
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS sequence INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE swarms ADD COLUMN IF NOT EXISTS sequence INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE availability ADD COLUMN IF NOT EXISTS sequence INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS swarm_members_member_id_idx ON swarm_members (member_id);
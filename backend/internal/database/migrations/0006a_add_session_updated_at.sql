@@ -0,0 +1,7 @@
+-- Chapter calendar feeds stamp session events with the time they last
+-- changed. Existing sessions have not changed since they were created.
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ;
+
+UPDATE sessions SET updated_at = created_at WHERE updated_at IS NULL;
+
+ALTER TABLE sessions ALTER COLUMN updated_at SET NOT NULL;
@@ -0,0 +1,15 @@
+-- Availability a member withdraws leaves a tombstone so member calendar feeds
+-- can publish the session as cancelled instead of silently dropping it.
+-- sequence carries the withdrawn row's SEQUENCE forward so a later
+-- declaration for the same session still supersedes the cancellation.
+CREATE TABLE IF NOT EXISTS availability_removals (
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    member_id UUID NOT NULL REFERENCES members(id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    sequence INTEGER NOT NULL,
+    removed_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (session_id, member_id)
+);
+
+CREATE INDEX IF NOT EXISTS availability_removals_member_id_idx ON availability_removals (member_id);
@@ -0,0 +1,20 @@
+-- Availability can be entered manually or synced from a member's calendar.
+-- Synced values are always recorded; when they disagree with a manual entry
+-- the manual value is kept and sync_conflict is raised.
+ALTER TABLE availability ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'sync'));
+ALTER TABLE availability ADD COLUMN IF NOT EXISTS synced_status TEXT CHECK (synced_status IN ('available', 'partial', 'unavailable'));
+ALTER TABLE availability ADD COLUMN IF NOT EXISTS synced_starts_at TIMESTAMPTZ;
+ALTER TABLE availability ADD COLUMN IF NOT EXISTS synced_ends_at TIMESTAMPTZ;
+ALTER TABLE availability ADD COLUMN IF NOT EXISTS synced_at TIMESTAMPTZ;
+ALTER TABLE availability ADD COLUMN IF NOT EXISTS sync_conflict BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS calendar_sources (
+    member_id UUID PRIMARY KEY REFERENCES members(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    username TEXT NOT NULL DEFAULT '',
+    password TEXT NOT NULL DEFAULT '',
+    last_synced_at TIMESTAMPTZ,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,17 @@
+-- Calendar source passwords are sealed with the server's credentials key
+-- (CALENDAR_CREDENTIALS_KEY) before they are stored. Passwords stored in
+-- plaintext cannot be sealed here, so they are dropped and their members are
+-- asked to enter them again.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'calendar_sources' AND column_name = 'password') THEN
+        UPDATE calendar_sources
+        SET last_error = 'calendar password must be entered again'
+        WHERE password <> '';
+
+        ALTER TABLE calendar_sources DROP COLUMN password;
+    END IF;
+END
+$$;
+
+ALTER TABLE calendar_sources ADD COLUMN IF NOT EXISTS sealed_password TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,42 @@
+-- Sessions move from scheduled to kicked_off at the kickoff ritual and to
+-- closed at the close-out; closed sessions no longer accept writes.
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS state TEXT NOT NULL DEFAULT 'scheduled' CHECK (state IN ('scheduled', 'kicked_off', 'closed'));
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS kicked_off_at TIMESTAMPTZ;
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS closed_at TIMESTAMPTZ;
+
+-- Intents may be owned by a member, serve a goal and be planned for a
+-- session. Drafts are carried forward from a close-out until confirmed.
+ALTER TABLE intents ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('draft', 'active'));
+ALTER TABLE intents ADD COLUMN IF NOT EXISTS member_id UUID REFERENCES members(id) ON DELETE SET NULL;
+ALTER TABLE intents ADD COLUMN IF NOT EXISTS goal_id UUID REFERENCES goals(id) ON DELETE SET NULL;
+ALTER TABLE intents ADD COLUMN IF NOT EXISTS session_id UUID REFERENCES sessions(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS intents_member_id_idx ON intents (member_id);
+CREATE INDEX IF NOT EXISTS intents_goal_id_idx ON intents (goal_id);
+CREATE INDEX IF NOT EXISTS intents_session_id_idx ON intents (session_id);
+
+-- One kickoff snapshot per swarm: the intents it confirmed, the dependencies
+-- it called out, the guardrails in force and who acknowledged them.
+CREATE TABLE IF NOT EXISTS session_kickoffs (
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    swarm_id UUID NOT NULL REFERENCES swarms(id) ON DELETE CASCADE,
+    intent_ids JSONB NOT NULL DEFAULT '[]'::jsonb,
+    dependencies JSONB NOT NULL DEFAULT '[]'::jsonb,
+    guardrails JSONB NOT NULL DEFAULT '[]'::jsonb,
+    acknowledged_by JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (session_id, swarm_id)
+);
+
+CREATE TABLE IF NOT EXISTS session_outcomes (
+    id UUID PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    member_id UUID NOT NULL REFERENCES members(id) ON DELETE CASCADE,
+    intent_id UUID REFERENCES intents(id) ON DELETE SET NULL,
+    outcome TEXT NOT NULL,
+    obstacles TEXT NOT NULL DEFAULT '',
+    next_intent_id UUID REFERENCES intents(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS session_outcomes_session_id_idx ON session_outcomes (session_id);
@@ -0,0 +1,44 @@
+-- Retro survey templates hold the micro-survey questions. Templates without a
+-- chapter apply to every chapter that has none of its own.
+CREATE TABLE IF NOT EXISTS retro_templates (
+    id UUID PRIMARY KEY,
+    chapter_id UUID REFERENCES chapters(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    anonymous BOOLEAN NOT NULL DEFAULT FALSE,
+    questions JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS retro_templates_chapter_id_idx ON retro_templates (chapter_id);
+
+-- A survey is opened once per session, snapshotting the template's questions
+-- and anonymity so later template edits do not change what was asked.
+CREATE TABLE IF NOT EXISTS retro_surveys (
+    id UUID PRIMARY KEY,
+    session_id UUID NOT NULL UNIQUE REFERENCES sessions(id) ON DELETE CASCADE,
+    template_id UUID REFERENCES retro_templates(id) ON DELETE SET NULL,
+    anonymous BOOLEAN NOT NULL,
+    questions JSONB NOT NULL,
+    opened_at TIMESTAMPTZ NOT NULL
+);
+
+-- Participation is tracked apart from the answers so each member responds
+-- once. It carries no timestamp.
+CREATE TABLE IF NOT EXISTS retro_participants (
+    survey_id UUID NOT NULL REFERENCES retro_surveys(id) ON DELETE CASCADE,
+    member_id UUID NOT NULL REFERENCES members(id) ON DELETE CASCADE,
+    PRIMARY KEY (survey_id, member_id)
+);
+
+-- Responses to surveys that are not anonymous. Anonymous answers are tallied
+-- instead (0009a_add_retro_tallies.sql).
+CREATE TABLE IF NOT EXISTS retro_responses (
+    id UUID PRIMARY KEY,
+    survey_id UUID NOT NULL REFERENCES retro_surveys(id) ON DELETE CASCADE,
+    member_id UUID REFERENCES members(id) ON DELETE SET NULL,
+    answers JSONB NOT NULL,
+    submitted_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS retro_responses_survey_id_idx ON retro_responses (survey_id);
@@ -0,0 +1,32 @@
+-- Anonymous surveys keep one tally per survey instead of a row per response.
+-- The tally is rewritten in the same transaction as the member's
+-- participation row, and it holds each question's answers sorted and apart
+-- from the other questions, so neither transaction ids nor row positions tie
+-- an answer back to a member.
+CREATE TABLE IF NOT EXISTS retro_tallies (
+    survey_id UUID PRIMARY KEY REFERENCES retro_surveys(id) ON DELETE CASCADE,
+    tally JSONB NOT NULL
+);
+
+-- Fold anonymous responses stored one per row into tallies and drop them.
+WITH anonymous AS (
+    SELECT r.survey_id, r.answers
+    FROM retro_responses r
+    JOIN retro_surveys s ON s.id = r.survey_id
+    WHERE s.anonymous
+), answers AS (
+    SELECT a.survey_id, e.key, jsonb_agg(e.value ORDER BY e.value::text) AS answer_values
+    FROM anonymous a, jsonb_each(a.answers) e
+    GROUP BY a.survey_id, e.key
+)
+INSERT INTO retro_tallies (survey_id, tally)
+SELECT c.survey_id, jsonb_build_object(
+    'responses', c.responses,
+    'answers', COALESCE((SELECT jsonb_object_agg(answers.key, answers.answer_values) FROM answers WHERE answers.survey_id = c.survey_id), '{}'::jsonb)
+)
+FROM (SELECT survey_id, COUNT(*) AS responses FROM anonymous GROUP BY survey_id) c
+ON CONFLICT (survey_id) DO NOTHING;
+
+DELETE FROM retro_responses r
+USING retro_surveys s
+WHERE s.id = r.survey_id AND s.anonymous;
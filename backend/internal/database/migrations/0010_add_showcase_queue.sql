@@ -0,0 +1,25 @@
+-- Demo requests queue per chapter. Each comes from a swarm or from a
+-- member's close-out outcome. Position is the queue order the scheduler
+-- packs in. The slot columns hold the scheduled time, or are NULL while the
+-- request waits for room. Locked slots are never moved by the scheduler.
+CREATE TABLE IF NOT EXISTS showcase_requests (
+    id UUID PRIMARY KEY,
+    chapter_id UUID NOT NULL REFERENCES chapters(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    swarm_id UUID REFERENCES swarms(id) ON DELETE CASCADE,
+    outcome_id UUID REFERENCES session_outcomes(id) ON DELETE CASCADE,
+    duration_minutes INTEGER NOT NULL CHECK (duration_minutes > 0),
+    priority TEXT NOT NULL DEFAULT 'normal' CHECK (priority IN ('high', 'normal', 'low')),
+    position INTEGER NOT NULL,
+    locked BOOLEAN NOT NULL DEFAULT FALSE,
+    session_id UUID REFERENCES sessions(id) ON DELETE SET NULL,
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    sequence INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    CHECK ((swarm_id IS NULL) <> (outcome_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS showcase_requests_chapter_position_idx ON showcase_requests (chapter_id, position);
+CREATE INDEX IF NOT EXISTS showcase_requests_session_id_idx ON showcase_requests (session_id);
@@ -0,0 +1,33 @@
+-- Durable background jobs. Workers claim due jobs with FOR UPDATE SKIP
+-- LOCKED and hold them until locked_until; a job whose worker died is
+-- reclaimed once its lease lapses. Failed jobs are retried with backoff
+-- until max_attempts, then parked as dead for inspection. The (kind, key)
+-- pair makes enqueueing idempotent.
+CREATE TABLE IF NOT EXISTS jobs (
+    id UUID PRIMARY KEY,
+    kind TEXT NOT NULL,
+    key TEXT NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'done', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL CHECK (max_attempts > 0),
+    run_at TIMESTAMPTZ NOT NULL,
+    locked_until TIMESTAMPTZ,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (kind, key)
+);
+
+CREATE INDEX IF NOT EXISTS jobs_due_idx ON jobs (run_at) WHERE status IN ('pending', 'running');
+
+-- One row per nudge a member has been sent for a session, so a nudge job
+-- that is retried or reclaimed after a restart never notifies twice.
+CREATE TABLE IF NOT EXISTS session_nudges (
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    member_id UUID NOT NULL REFERENCES members(id) ON DELETE CASCADE,
+    lead TEXT NOT NULL,
+    reason TEXT NOT NULL CHECK (reason IN ('declare', 'refine')),
+    sent_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (session_id, member_id, lead)
+);
@@ -0,0 +1,25 @@
+-- Notifications sent to members. Each is delivered to the channels the
+-- member chose for its type by a notify.deliver job; inbox_at is set once
+-- it has been delivered to the in-app inbox.
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY,
+    member_id UUID NOT NULL REFERENCES members(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    inbox_at TIMESTAMPTZ,
+    read_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS notifications_inbox_idx ON notifications (member_id, inbox_at DESC) WHERE inbox_at IS NOT NULL;
+
+-- Per-member delivery preferences. channels maps a notification type to the
+-- channels it is sent to; types missing from it use the defaults.
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    member_id UUID PRIMARY KEY REFERENCES members(id) ON DELETE CASCADE,
+    channels JSONB NOT NULL DEFAULT '{}',
+    quiet_during_sessions BOOLEAN NOT NULL DEFAULT TRUE,
+    chat_webhook_url TEXT,
+    updated_at TIMESTAMPTZ NOT NULL
+);
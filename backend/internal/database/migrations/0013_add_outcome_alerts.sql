@@ -0,0 +1,13 @@
+-- One row per alert a member has been sent about an intent left without an
+-- outcome after its session, so alert jobs that are retried or reclaimed
+-- never notify twice. Authors and Chapter Leads get a 'missing' alert; leads
+-- get an 'escalation' digest if the outcome is still missing days later.
+CREATE TABLE IF NOT EXISTS outcome_alerts (
+    intent_id UUID NOT NULL REFERENCES intents(id) ON DELETE CASCADE,
+    recipient_id UUID NOT NULL REFERENCES members(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('missing', 'escalation')),
+    sent_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (intent_id, recipient_id, kind)
+);
+
+CREATE INDEX IF NOT EXISTS session_outcomes_intent_id_idx ON session_outcomes (intent_id);
@@ -0,0 +1,38 @@
+-- Intents can be marked done, usually on the suggestion of a linked tracker
+-- issue that was resolved.
+ALTER TABLE intents DROP CONSTRAINT IF EXISTS intents_status_check;
+ALTER TABLE intents ADD CONSTRAINT intents_status_check CHECK (status IN ('draft', 'active', 'done'));
+
+-- Typed external links on intents. Issues created in or synced from a work
+-- tracker also record the tracker, the issue key and the last status seen.
+CREATE TABLE IF NOT EXISTS intent_links (
+    id UUID PRIMARY KEY,
+    intent_id UUID NOT NULL REFERENCES intents(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('issue', 'doc', 'pr', 'dashboard')),
+    url TEXT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    tracker TEXT,
+    external_key TEXT,
+    external_status TEXT,
+    synced_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS intent_links_intent_id_idx ON intent_links (intent_id);
+CREATE UNIQUE INDEX IF NOT EXISTS intent_links_tracker_idx ON intent_links (intent_id, tracker) WHERE tracker IS NOT NULL;
+
+-- Status changes seen in a tracker are offered to the intent's owner rather
+-- than applied. An intent has at most one pending suggestion; a newer one
+-- supersedes it.
+CREATE TABLE IF NOT EXISTS intent_status_suggestions (
+    id UUID PRIMARY KEY,
+    intent_id UUID NOT NULL REFERENCES intents(id) ON DELETE CASCADE,
+    link_id UUID REFERENCES intent_links(id) ON DELETE SET NULL,
+    suggested_status TEXT NOT NULL CHECK (suggested_status IN ('draft', 'active', 'done')),
+    reason TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    resolved_at TIMESTAMPTZ,
+    resolution TEXT CHECK (resolution IN ('accepted', 'dismissed', 'superseded'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS intent_status_suggestions_pending_idx ON intent_status_suggestions (intent_id) WHERE resolved_at IS NULL;
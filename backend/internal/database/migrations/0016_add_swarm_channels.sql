@@ -0,0 +1,17 @@
+-- Chat channels provisioned for swarms. The row is written by the first
+-- provisioning attempt and records each step taken, so a retried job resumes
+-- where the last one failed; last_error and attempts surface failures on the
+-- swarm.
+CREATE TABLE IF NOT EXISTS swarm_channels (
+    swarm_id UUID PRIMARY KEY REFERENCES swarms(id) ON DELETE CASCADE,
+    provider TEXT NOT NULL,
+    channel_id TEXT,
+    name TEXT NOT NULL,
+    url TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL CHECK (status IN ('provisioning', 'active', 'archived', 'failed')),
+    pinned BOOLEAN NOT NULL DEFAULT FALSE,
+    last_error TEXT NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,18 @@
+-- Intent coverage captured ahead of each session. Rows are written once per
+-- checkpoint and never recomputed, so later edits to intents do not change
+-- historical numbers.
+CREATE TABLE IF NOT EXISTS coverage_snapshots (
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    chapter_id UUID NOT NULL REFERENCES chapters(id) ON DELETE CASCADE,
+    checkpoint TEXT NOT NULL CHECK (checkpoint IN ('24h', '1h')),
+    session_starts_at TIMESTAMPTZ NOT NULL,
+    members INTEGER NOT NULL,
+    available_members INTEGER NOT NULL,
+    declared_members INTEGER NOT NULL,
+    draft_members INTEGER NOT NULL,
+    active_intents INTEGER NOT NULL,
+    captured_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (session_id, checkpoint)
+);
+
+CREATE INDEX IF NOT EXISTS coverage_snapshots_chapter_idx ON coverage_snapshots (chapter_id, session_starts_at);
@@ -0,0 +1,14 @@
+-- Status and goal history of intents. A row is appended whenever an intent
+-- is created or its status or goal changes, recording the state it moved
+-- into; flow metrics read declaration and goal-link times from here.
+CREATE TABLE IF NOT EXISTS intent_transitions (
+    id UUID PRIMARY KEY,
+    intent_id UUID NOT NULL REFERENCES intents(id) ON DELETE CASCADE,
+    from_status TEXT,
+    to_status TEXT NOT NULL,
+    goal_id UUID REFERENCES goals(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS intent_transitions_intent_idx ON intent_transitions (intent_id, created_at);
+CREATE INDEX IF NOT EXISTS intent_transitions_goal_idx ON intent_transitions (goal_id, created_at);
@@ -0,0 +1,3 @@
+-- Outcomes may cite the success criteria of their intent's goal that they
+-- satisfied; the impact map counts the distinct criteria cited per goal.
+ALTER TABLE session_outcomes ADD COLUMN IF NOT EXISTS satisfied_criteria JSONB NOT NULL DEFAULT '[]'::jsonb;
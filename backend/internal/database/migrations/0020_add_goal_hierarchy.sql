@@ -0,0 +1,5 @@
+-- Goals may decompose a parent goal. The reference has no ON DELETE action:
+-- deleting a goal with children must explicitly reparent or cascade them.
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS parent_goal_id UUID REFERENCES goals(id);
+
+CREATE INDEX IF NOT EXISTS goals_parent_goal_idx ON goals (parent_goal_id);
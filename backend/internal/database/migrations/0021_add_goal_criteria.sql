@@ -0,0 +1,32 @@
+-- Success criteria get a stable identity so they can carry an optional metric
+-- and a series of measurements. goals.success_criteria stays the ordered list
+-- of criterion texts; goal_criteria holds one row per text.
+CREATE TABLE IF NOT EXISTS goal_criteria (
+    id UUID PRIMARY KEY,
+    goal_id UUID NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+    criterion TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    baseline DOUBLE PRECISION,
+    target DOUBLE PRECISION,
+    unit TEXT,
+    direction TEXT CHECK (direction IN ('increase', 'decrease')),
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (goal_id, criterion)
+);
+
+CREATE TABLE IF NOT EXISTS goal_criterion_measurements (
+    id UUID PRIMARY KEY,
+    criterion_id UUID NOT NULL REFERENCES goal_criteria(id) ON DELETE CASCADE,
+    value DOUBLE PRECISION NOT NULL,
+    measured_at TIMESTAMPTZ NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS goal_criterion_measurements_criterion_idx ON goal_criterion_measurements (criterion_id, measured_at DESC);
+
+INSERT INTO goal_criteria (id, goal_id, criterion, position, created_at, updated_at)
+SELECT gen_random_uuid(), g.id, c.criterion, c.position - 1, now(), now()
+FROM goals g, jsonb_array_elements_text(g.success_criteria) WITH ORDINALITY AS c(criterion, position)
+ON CONFLICT (goal_id, criterion) DO NOTHING;
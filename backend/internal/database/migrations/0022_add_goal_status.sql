@@ -0,0 +1,9 @@
+-- Goals move through draft, active, paused, achieved and archived instead of
+-- being live until deleted. Existing goals stay active. Achieving a goal
+-- records a closing summary and when it was achieved.
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active'
+    CHECK (status IN ('draft', 'active', 'paused', 'achieved', 'archived'));
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS closing_summary TEXT;
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS achieved_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS goals_status_idx ON goals (status, created_at);
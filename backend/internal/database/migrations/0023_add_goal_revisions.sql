@@ -0,0 +1,28 @@
+-- Every create, update and restore of a goal's content is kept as an
+-- immutable, numbered revision. metrics maps criterion text to its metric so
+-- a restore brings measurable criteria back as they were.
+CREATE TABLE IF NOT EXISTS goal_revisions (
+    goal_id UUID NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    clarity_statement TEXT NOT NULL,
+    guardrails JSONB NOT NULL DEFAULT '[]'::jsonb,
+    decision_rights JSONB NOT NULL DEFAULT '[]'::jsonb,
+    constraints JSONB NOT NULL DEFAULT '[]'::jsonb,
+    success_criteria JSONB NOT NULL DEFAULT '[]'::jsonb,
+    metrics JSONB NOT NULL DEFAULT '{}'::jsonb,
+    restored_from INTEGER,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (goal_id, revision)
+);
+
+INSERT INTO goal_revisions (goal_id, revision, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, metrics, created_at)
+SELECT g.id, 1, g.title, g.clarity_statement, g.guardrails, g.decision_rights, g.constraints, g.success_criteria,
+       COALESCE((
+           SELECT jsonb_object_agg(c.criterion, jsonb_build_object('baseline', c.baseline, 'target', c.target, 'unit', c.unit, 'direction', c.direction))
+           FROM goal_criteria c
+           WHERE c.goal_id = g.id AND c.direction IS NOT NULL
+       ), '{}'::jsonb),
+       g.updated_at
+FROM goals g
+ON CONFLICT (goal_id, revision) DO NOTHING;
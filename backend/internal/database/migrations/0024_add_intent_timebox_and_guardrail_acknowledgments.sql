@@ -0,0 +1,19 @@
+-- Intents declare a timebox, either a number of session blocks or the
+-- sessions the work must fit in. Intents created before timeboxes were
+-- required keep a NULL timebox.
+ALTER TABLE intents ADD COLUMN IF NOT EXISTS timebox JSONB;
+
+-- Each acknowledgment freezes the guardrails of the intent's goal, and the
+-- goal revision they came from, at the time the owner accepted them. An
+-- intent needs a fresh acknowledgment once its goal's guardrails no longer
+-- match any it acknowledged.
+CREATE TABLE IF NOT EXISTS intent_guardrail_acknowledgments (
+    id UUID PRIMARY KEY,
+    intent_id UUID NOT NULL REFERENCES intents(id) ON DELETE CASCADE,
+    goal_id UUID NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+    goal_revision INTEGER NOT NULL,
+    guardrails JSONB NOT NULL,
+    acknowledged_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS intent_guardrail_acknowledgments_intent_idx ON intent_guardrail_acknowledgments (intent_id, acknowledged_at DESC);
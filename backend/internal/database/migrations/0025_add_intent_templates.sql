@@ -0,0 +1,46 @@
+-- Intent templates give new members a starting point for common kinds of
+-- work. Placeholders such as {{service}} in the text are filled in when an
+-- intent is created from the template. Templates without a chapter are
+-- offered to every chapter.
+CREATE TABLE IF NOT EXISTS intent_templates (
+    id UUID PRIMARY KEY,
+    chapter_id UUID REFERENCES chapters(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    name TEXT NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    statement TEXT NOT NULL,
+    context TEXT NOT NULL DEFAULT '',
+    expected_outcome TEXT NOT NULL DEFAULT '',
+    needed_skills JSONB NOT NULL DEFAULT '[]'::jsonb,
+    timebox_blocks INTEGER CHECK (timebox_blocks > 0),
+    goal_id UUID REFERENCES goals(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS intent_templates_chapter_id_idx ON intent_templates (chapter_id);
+CREATE INDEX IF NOT EXISTS intent_templates_kind_idx ON intent_templates (kind);
+
+-- Every version of a template's content is kept so intents created from an
+-- older version can still be traced back to the text they started from.
+CREATE TABLE IF NOT EXISTS intent_template_versions (
+    template_id UUID NOT NULL REFERENCES intent_templates(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    statement TEXT NOT NULL,
+    context TEXT NOT NULL,
+    expected_outcome TEXT NOT NULL,
+    needed_skills JSONB NOT NULL,
+    timebox_blocks INTEGER,
+    goal_id UUID REFERENCES goals(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (template_id, version)
+);
+
+-- Intents record the skills the work needs and the template version they
+-- were created from, if any.
+ALTER TABLE intents
+    ADD COLUMN IF NOT EXISTS needed_skills JSONB NOT NULL DEFAULT '[]'::jsonb,
+    ADD COLUMN IF NOT EXISTS template_id UUID REFERENCES intent_templates(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS template_version INTEGER;
+
+CREATE INDEX IF NOT EXISTS intents_template_id_idx ON intents (template_id);
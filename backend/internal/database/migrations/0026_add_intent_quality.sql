@@ -0,0 +1,21 @@
+-- Chapters can replace the default intent quality rules with their own.
+-- rules holds the full list of quality.Rule definitions.
+CREATE TABLE IF NOT EXISTS intent_quality_rules (
+    chapter_id UUID PRIMARY KEY REFERENCES chapters(id) ON DELETE CASCADE,
+    rules JSONB NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+-- The latest quality score of each intent, recomputed whenever it is created
+-- or updated. chapter_id names the chapter whose rules were applied and is
+-- null when the defaults were.
+CREATE TABLE IF NOT EXISTS intent_quality (
+    intent_id UUID PRIMARY KEY REFERENCES intents(id) ON DELETE CASCADE,
+    score INTEGER NOT NULL CHECK (score BETWEEN 0 AND 100),
+    breakdown JSONB NOT NULL DEFAULT '[]'::jsonb,
+    questions JSONB NOT NULL DEFAULT '[]'::jsonb,
+    chapter_id UUID REFERENCES chapters(id) ON DELETE SET NULL,
+    evaluated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS intent_quality_score_idx ON intent_quality (score);
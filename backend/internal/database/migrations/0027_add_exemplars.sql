@@ -0,0 +1,22 @@
+-- Exemplars are intents a chapter lead promoted to the chapter's library as
+-- examples of good practice. The intent and its outcome are frozen in
+-- snapshots so later edits to the source do not change the exemplar; goal_id
+-- and template_kind are copied from the intent for filtering.
+CREATE TABLE IF NOT EXISTS exemplars (
+    id UUID PRIMARY KEY,
+    chapter_id UUID NOT NULL REFERENCES chapters(id) ON DELETE CASCADE,
+    intent_id UUID UNIQUE REFERENCES intents(id) ON DELETE SET NULL,
+    goal_id UUID REFERENCES goals(id) ON DELETE SET NULL,
+    template_kind TEXT NOT NULL DEFAULT '',
+    tags JSONB NOT NULL DEFAULT '[]'::jsonb,
+    annotation TEXT NOT NULL,
+    intent_snapshot JSONB NOT NULL,
+    outcome_snapshot JSONB,
+    promoted_by UUID REFERENCES members(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS exemplars_chapter_id_idx ON exemplars (chapter_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS exemplars_goal_id_idx ON exemplars (goal_id);
+CREATE INDEX IF NOT EXISTS exemplars_tags_idx ON exemplars USING GIN (tags);
@@ -0,0 +1,11 @@
+-- Tags label intents and goals with short, lowercase categories such as
+-- "reliability" or "onboarding". Both use the same JSONB array of names, so a
+-- tag filter or facet means the same thing on either list.
+ALTER TABLE intents
+    ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+ALTER TABLE goals
+    ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+CREATE INDEX IF NOT EXISTS intents_tags_idx ON intents USING GIN (tags);
+CREATE INDEX IF NOT EXISTS goals_tags_idx ON goals USING GIN (tags);
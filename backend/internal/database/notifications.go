@@ -0,0 +1,314 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification is a message addressed to a member. It appears in their
+// in-app inbox once InboxAt is set.
+type Notification struct {
+	ID        uuid.UUID
+	MemberID  uuid.UUID
+	Type      string
+	Title     string
+	Body      string
+	CreatedAt time.Time
+	InboxAt   *time.Time
+	ReadAt    *time.Time
+}
+
+// NotificationInput captures the fields required to record a notification.
+type NotificationInput struct {
+	MemberID uuid.UUID
+	Type     string
+	Title    string
+	Body     string
+}
+
+// NotificationPreferences holds a member's delivery settings. Channels maps
+// a notification type to the channels it is sent to; types it leaves out use
+// the defaults of the notify package.
+type NotificationPreferences struct {
+	MemberID            uuid.UUID
+	Channels            map[string][]string
+	QuietDuringSessions bool
+	ChatWebhookURL      string
+	UpdatedAt           *time.Time
+}
+
+const notificationColumns = `id, member_id, type, title, body, created_at, inbox_at, read_at`
+
+// CreateNotification records a notification inside tx so that it commits or
+// rolls back with the work that raised it.
+func CreateNotification(ctx context.Context, tx *sql.Tx, input NotificationInput) (Notification, error) {
+	if tx == nil {
+		return Notification{}, errors.New("transaction is nil")
+	}
+
+	notification := Notification{
+		ID:        uuid.New(),
+		MemberID:  input.MemberID,
+		Type:      input.Type,
+		Title:     input.Title,
+		Body:      input.Body,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	const query = `
+INSERT INTO notifications (id, member_id, type, title, body, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+	if _, err := tx.ExecContext(ctx, query, notification.ID, notification.MemberID, notification.Type, notification.Title, notification.Body, notification.CreatedAt); err != nil {
+		return Notification{}, err
+	}
+
+	return notification, nil
+}
+
+// GetNotification retrieves a notification by identifier.
+func GetNotification(ctx context.Context, db *sql.DB, id uuid.UUID) (Notification, error) {
+	if db == nil {
+		return Notification{}, errors.New("database handle is nil")
+	}
+
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE id = $1`
+
+	return scanNotification(db.QueryRowContext(ctx, query, id))
+}
+
+// DeliverToInbox makes a notification visible in its member's inbox. Doing
+// so again is a no-op.
+func DeliverToInbox(ctx context.Context, db *sql.DB, id uuid.UUID) error {
+	if db == nil {
+		return errors.New("database handle is nil")
+	}
+
+	const query = `UPDATE notifications SET inbox_at = $2 WHERE id = $1 AND inbox_at IS NULL`
+
+	_, err := db.ExecContext(ctx, query, id, time.Now().UTC())
+	return err
+}
+
+// ListInbox returns the member's inbox, newest first, optionally limited to
+// unread notifications. The unread count covers the whole inbox.
+func ListInbox(ctx context.Context, db *sql.DB, memberID uuid.UUID, unreadOnly bool, limit int) ([]Notification, int, error) {
+	if db == nil {
+		return nil, 0, errors.New("database handle is nil")
+	}
+
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE member_id = $1 AND inbox_at IS NOT NULL`
+	if unreadOnly {
+		query += ` AND read_at IS NULL`
+	}
+	query += ` ORDER BY inbox_at DESC, id LIMIT $2`
+
+	rows, err := db.QueryContext(ctx, query, memberID, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	notifications := make([]Notification, 0)
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		notifications = append(notifications, notification)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var unread int
+	const countQuery = `SELECT COUNT(*) FROM notifications WHERE member_id = $1 AND inbox_at IS NOT NULL AND read_at IS NULL`
+	if err := db.QueryRowContext(ctx, countQuery, memberID).Scan(&unread); err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, unread, nil
+}
+
+// MarkNotificationRead marks one of the member's inbox notifications as read.
+// sql.ErrNoRows is returned when the member has no such notification.
+func MarkNotificationRead(ctx context.Context, db *sql.DB, memberID, id uuid.UUID) (Notification, error) {
+	if db == nil {
+		return Notification{}, errors.New("database handle is nil")
+	}
+
+	const query = `
+UPDATE notifications SET read_at = COALESCE(read_at, $3)
+WHERE id = $1 AND member_id = $2 AND inbox_at IS NOT NULL
+RETURNING ` + notificationColumns
+
+	return scanNotification(db.QueryRowContext(ctx, query, id, memberID, time.Now().UTC()))
+}
+
+// MarkInboxRead marks every unread notification in the member's inbox as
+// read, returning how many changed.
+func MarkInboxRead(ctx context.Context, db *sql.DB, memberID uuid.UUID) (int, error) {
+	if db == nil {
+		return 0, errors.New("database handle is nil")
+	}
+
+	const query = `UPDATE notifications SET read_at = $2 WHERE member_id = $1 AND inbox_at IS NOT NULL AND read_at IS NULL`
+
+	result, err := db.ExecContext(ctx, query, memberID, time.Now().UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
+
+// GetNotificationPreferences returns the member's delivery settings. A member
+// who never saved any gets empty channel choices with quiet hours on.
+func GetNotificationPreferences(ctx context.Context, db *sql.DB, memberID uuid.UUID) (NotificationPreferences, error) {
+	if db == nil {
+		return NotificationPreferences{}, errors.New("database handle is nil")
+	}
+
+	return getNotificationPreferences(ctx, db, memberID)
+}
+
+func getNotificationPreferences(ctx context.Context, q queryer, memberID uuid.UUID) (NotificationPreferences, error) {
+	const query = `
+SELECT channels, quiet_during_sessions, chat_webhook_url, updated_at
+FROM notification_preferences
+WHERE member_id = $1
+`
+
+	var (
+		channels  []byte
+		webhook   sql.NullString
+		updatedAt time.Time
+	)
+
+	prefs := NotificationPreferences{MemberID: memberID, Channels: map[string][]string{}, QuietDuringSessions: true}
+	err := q.QueryRowContext(ctx, query, memberID).Scan(&channels, &prefs.QuietDuringSessions, &webhook, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return prefs, nil
+	}
+	if err != nil {
+		return NotificationPreferences{}, err
+	}
+
+	if err := json.Unmarshal(channels, &prefs.Channels); err != nil {
+		return NotificationPreferences{}, err
+	}
+	prefs.ChatWebhookURL = webhook.String
+	prefs.UpdatedAt = &updatedAt
+
+	return prefs, nil
+}
+
+// PutNotificationPreferences replaces the member's delivery settings.
+func PutNotificationPreferences(ctx context.Context, db *sql.DB, prefs NotificationPreferences) (NotificationPreferences, error) {
+	if db == nil {
+		return NotificationPreferences{}, errors.New("database handle is nil")
+	}
+
+	if prefs.Channels == nil {
+		prefs.Channels = map[string][]string{}
+	}
+
+	channels, err := json.Marshal(prefs.Channels)
+	if err != nil {
+		return NotificationPreferences{}, err
+	}
+
+	now := time.Now().UTC()
+
+	const query = `
+INSERT INTO notification_preferences (member_id, channels, quiet_during_sessions, chat_webhook_url, updated_at)
+VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+ON CONFLICT (member_id) DO UPDATE
+SET channels = EXCLUDED.channels,
+    quiet_during_sessions = EXCLUDED.quiet_during_sessions,
+    chat_webhook_url = EXCLUDED.chat_webhook_url,
+    updated_at = EXCLUDED.updated_at
+`
+
+	if _, err := db.ExecContext(ctx, query, prefs.MemberID, channels, prefs.QuietDuringSessions, prefs.ChatWebhookURL, now); err != nil {
+		return NotificationPreferences{}, err
+	}
+
+	prefs.UpdatedAt = &now
+	return prefs, nil
+}
+
+// quietUntil reports when the session block the member is sitting in ends,
+// or nil when they are not in one at now. Sessions the member marked
+// themselves unavailable for do not count.
+func quietUntil(ctx context.Context, q queryer, memberID uuid.UUID, now time.Time) (*time.Time, error) {
+	const query = `
+SELECT MAX(s.ends_at)
+FROM sessions s
+JOIN members m ON m.chapter_id = s.chapter_id
+WHERE m.id = $1 AND s.state <> 'closed' AND s.starts_at <= $2 AND s.ends_at > $2
+  AND NOT EXISTS (
+      SELECT 1 FROM availability a WHERE a.session_id = s.id AND a.member_id = m.id AND a.status = 'unavailable'
+  )
+`
+
+	var until sql.NullTime
+	if err := q.QueryRowContext(ctx, query, memberID, now.UTC()).Scan(&until); err != nil {
+		return nil, err
+	}
+
+	return nullTimePtr(until), nil
+}
+
+// NotificationPlan is what a transaction needs to know to fan a notification
+// out: the member's preferences and the end of the session block they are
+// in, if any.
+type NotificationPlan struct {
+	Preferences NotificationPreferences
+	QuietUntil  *time.Time
+}
+
+// PlanNotification reads the member's preferences and quiet window inside tx.
+func PlanNotification(ctx context.Context, tx *sql.Tx, memberID uuid.UUID, now time.Time) (NotificationPlan, error) {
+	if tx == nil {
+		return NotificationPlan{}, errors.New("transaction is nil")
+	}
+
+	prefs, err := getNotificationPreferences(ctx, tx, memberID)
+	if err != nil {
+		return NotificationPlan{}, err
+	}
+
+	plan := NotificationPlan{Preferences: prefs}
+	if prefs.QuietDuringSessions {
+		if plan.QuietUntil, err = quietUntil(ctx, tx, memberID, now); err != nil {
+			return NotificationPlan{}, err
+		}
+	}
+
+	return plan, nil
+}
+
+func scanNotification(row rowScanner) (Notification, error) {
+	var (
+		notification Notification
+		inboxAt      sql.NullTime
+		readAt       sql.NullTime
+	)
+
+	if err := row.Scan(&notification.ID, &notification.MemberID, &notification.Type, &notification.Title, &notification.Body, &notification.CreatedAt, &inboxAt, &readAt); err != nil {
+		return Notification{}, err
+	}
+
+	notification.InboxAt = nullTimePtr(inboxAt)
+	notification.ReadAt = nullTimePtr(readAt)
+
+	return notification, nil
+}
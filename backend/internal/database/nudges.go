@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Nudge reasons: a member with nothing planned for the session is asked to
+// declare an intent; one with only drafts carried forward is asked to
+// refine them.
+const (
+	NudgeDeclare = "declare"
+	NudgeRefine  = "refine"
+)
+
+// SessionNudge records a reminder sent to a member ahead of a session.
+type SessionNudge struct {
+	SessionID       uuid.UUID
+	MemberID        uuid.UUID
+	Lead            string
+	Reason          string
+	SessionStartsAt time.Time
+	SentAt          time.Time
+}
+
+// RecordSessionNudges finds members of the session's chapter without an
+// active intent planned for it, skipping those who marked themselves
+// unavailable, and records a nudge for each under lead. Members already
+// nudged for this lead are left out, so running it again is harmless.
+// deliver is called for every new nudge inside the transaction; if it
+// fails, nothing is recorded and the caller may try again. Closed, kicked
+// off and already started sessions yield no nudges.
+func RecordSessionNudges(ctx context.Context, db *sql.DB, sessionID uuid.UUID, lead string, deliver func(*sql.Tx, SessionNudge) error) ([]SessionNudge, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+
+	const query = `
+INSERT INTO session_nudges (session_id, member_id, lead, reason, sent_at)
+SELECT s.id, m.id, $2,
+       CASE WHEN EXISTS (
+           SELECT 1 FROM intents i WHERE i.session_id = s.id AND i.member_id = m.id AND i.status = 'draft'
+       ) THEN 'refine' ELSE 'declare' END,
+       $3
+FROM sessions s
+JOIN members m ON m.chapter_id = s.chapter_id
+WHERE s.id = $1 AND s.state = 'scheduled' AND s.starts_at > $3
+  AND NOT EXISTS (
+      SELECT 1 FROM intents i WHERE i.session_id = s.id AND i.member_id = m.id AND i.status = 'active'
+  )
+  AND NOT EXISTS (
+      SELECT 1 FROM availability a WHERE a.session_id = s.id AND a.member_id = m.id AND a.status = 'unavailable'
+  )
+ON CONFLICT (session_id, member_id, lead) DO NOTHING
+RETURNING member_id, reason
+`
+
+	rows, err := tx.QueryContext(ctx, query, sessionID, lead, now)
+	if err != nil {
+		return nil, err
+	}
+
+	nudges := make([]SessionNudge, 0)
+	for rows.Next() {
+		nudge := SessionNudge{SessionID: sessionID, Lead: lead, SentAt: now}
+		if err := rows.Scan(&nudge.MemberID, &nudge.Reason); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		nudges = append(nudges, nudge)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(nudges) > 0 {
+		var startsAt time.Time
+		if err := tx.QueryRowContext(ctx, `SELECT starts_at FROM sessions WHERE id = $1`, sessionID).Scan(&startsAt); err != nil {
+			return nil, err
+		}
+
+		for i := range nudges {
+			nudges[i].SessionStartsAt = startsAt
+			if err := deliver(tx, nudges[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return nudges, nil
+}
@@ -0,0 +1,266 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Outcome alert kinds. Authors and Chapter Leads are alerted once an intent
+// is found without an outcome; leads get an escalation digest if it is still
+// missing later on.
+const (
+	OutcomeAlertMissing    = "missing"
+	OutcomeAlertEscalation = "escalation"
+)
+
+// MissingOutcomeGrace is how long after a session's end an intent may go
+// without an outcome when the session was never closed out.
+const MissingOutcomeGrace = 2 * time.Hour
+
+// ErrIntentNotInSession is returned when an outcome reports on an intent that
+// was not planned for the session.
+var ErrIntentNotInSession = errors.New("intent is not planned for the session")
+
+// MissingOutcome is an intent that was active in a session which has closed
+// or ended, yet has no recorded outcome.
+type MissingOutcome struct {
+	IntentID        uuid.UUID
+	Statement       string
+	MemberID        *uuid.UUID
+	GoalID          *uuid.UUID
+	SessionID       uuid.UUID
+	ChapterID       uuid.UUID
+	SessionEndsAt   time.Time
+	SessionClosedAt *time.Time
+}
+
+// MissingOutcomeFilters narrows ListMissingOutcomes to a goal or a session.
+type MissingOutcomeFilters struct {
+	GoalID    *uuid.UUID
+	SessionID *uuid.UUID
+}
+
+// OutcomeAlert is an alert for one recipient covering every intent of a
+// session they were newly alerted about.
+type OutcomeAlert struct {
+	RecipientID uuid.UUID
+	Kind        string
+	SessionID   uuid.UUID
+	Intents     []MissingOutcome
+}
+
+// missingOutcomeCondition selects active intents of sessions that have been
+// closed, or that ended more than MissingOutcomeGrace before $1, which no
+// outcome reports on. It expects intents aliased i and sessions s.
+const missingOutcomeCondition = `
+i.status = 'active'
+AND (s.state = 'closed' OR s.ends_at <= $1)
+AND NOT EXISTS (SELECT 1 FROM session_outcomes o WHERE o.intent_id = i.id)
+`
+
+const missingOutcomeColumns = `i.id, i.statement, i.member_id, i.goal_id, s.id, s.chapter_id, s.ends_at, s.closed_at`
+
+// ListMissingOutcomes returns the intents still lacking an outcome after
+// their session, oldest session first.
+func ListMissingOutcomes(ctx context.Context, db *sql.DB, filters MissingOutcomeFilters) ([]MissingOutcome, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	conditions := []string{missingOutcomeCondition}
+	args := []any{time.Now().UTC().Add(-MissingOutcomeGrace)}
+
+	if filters.GoalID != nil {
+		args = append(args, *filters.GoalID)
+		conditions = append(conditions, fmt.Sprintf("i.goal_id = $%d", len(args)))
+	}
+
+	if filters.SessionID != nil {
+		args = append(args, *filters.SessionID)
+		conditions = append(conditions, fmt.Sprintf("s.id = $%d", len(args)))
+	}
+
+	query := `SELECT ` + missingOutcomeColumns + ` FROM intents i JOIN sessions s ON s.id = i.session_id WHERE ` +
+		strings.Join(conditions, " AND ") + ` ORDER BY s.ends_at, i.created_at`
+
+	return queryMissingOutcomes(ctx, db, query, args...)
+}
+
+// RecordOutcomeAlerts records an alert of kind for every recipient due one
+// about the session's intents that lack an outcome: missing alerts go to each
+// intent's author and to the Chapter Leads of the session's chapter,
+// escalations to the leads only. Recipients already alerted about an intent
+// are left out, so running it again is harmless. deliver is called once per
+// recipient inside the transaction; if it fails, nothing is recorded and the
+// caller may try again.
+func RecordOutcomeAlerts(ctx context.Context, db *sql.DB, sessionID uuid.UUID, kind string, deliver func(*sql.Tx, OutcomeAlert) error) ([]OutcomeAlert, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+
+	query := `SELECT ` + missingOutcomeColumns + ` FROM intents i JOIN sessions s ON s.id = i.session_id WHERE ` +
+		missingOutcomeCondition + ` AND s.id = $2 ORDER BY i.created_at`
+
+	missing, err := queryMissingOutcomes(ctx, tx, query, now.Add(-MissingOutcomeGrace), sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) == 0 {
+		return []OutcomeAlert{}, tx.Commit()
+	}
+
+	byIntent := make(map[uuid.UUID]MissingOutcome, len(missing))
+	intentIDs := make([]uuid.UUID, 0, len(missing))
+	for _, item := range missing {
+		byIntent[item.IntentID] = item
+		intentIDs = append(intentIDs, item.IntentID)
+	}
+
+	const insert = `
+WITH recipients AS (
+    SELECT i.id AS intent_id, i.member_id AS recipient_id
+    FROM intents i
+    WHERE i.id = ANY($1::uuid[]) AND i.member_id IS NOT NULL AND $2 = 'missing'
+    UNION
+    SELECT i.id, l.id
+    FROM intents i
+    JOIN members l ON l.chapter_id = $3 AND l.role = 'chapter_lead'
+    WHERE i.id = ANY($1::uuid[])
+)
+INSERT INTO outcome_alerts (intent_id, recipient_id, kind, sent_at)
+SELECT intent_id, recipient_id, $2, $4 FROM recipients
+ON CONFLICT (intent_id, recipient_id, kind) DO NOTHING
+RETURNING recipient_id, intent_id
+`
+
+	rows, err := tx.QueryContext(ctx, insert, uuidArrayLiteral(intentIDs), kind, missing[0].ChapterID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := make([]OutcomeAlert, 0)
+	index := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var recipientID, intentID uuid.UUID
+		if err := rows.Scan(&recipientID, &intentID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		i, ok := index[recipientID]
+		if !ok {
+			i = len(alerts)
+			index[recipientID] = i
+			alerts = append(alerts, OutcomeAlert{RecipientID: recipientID, Kind: kind, SessionID: sessionID})
+		}
+		alerts[i].Intents = append(alerts[i].Intents, byIntent[intentID])
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, alert := range alerts {
+		if err := deliver(tx, alert); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return alerts, nil
+}
+
+// RecordLateOutcome records an outcome for an intent of a session that was
+// closed out without one. The entry must report on an intent planned for the
+// session; a next intent becomes a draft in the chapter's following session
+// as it would at close-out.
+func RecordLateOutcome(ctx context.Context, db *sql.DB, sessionID uuid.UUID, entry SessionOutcomeInput) (SessionOutcome, *Intent, error) {
+	if db == nil {
+		return SessionOutcome{}, nil, errors.New("database handle is nil")
+	}
+
+	if entry.IntentID == nil {
+		return SessionOutcome{}, nil, ErrIntentNotInSession
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return SessionOutcome{}, nil, err
+	}
+	defer tx.Rollback()
+
+	session, err := lockSessionForRitual(ctx, tx, sessionID)
+	if err != nil {
+		return SessionOutcome{}, nil, err
+	}
+
+	if session.State != SessionClosed {
+		return SessionOutcome{}, nil, ErrSessionNotClosed
+	}
+
+	var nextSessionID *uuid.UUID
+	var next uuid.UUID
+	err = tx.QueryRowContext(ctx, `SELECT id FROM sessions WHERE chapter_id = $1 AND starts_at > $2 ORDER BY starts_at LIMIT 1`, session.ChapterID, session.StartsAt).Scan(&next)
+	switch {
+	case err == nil:
+		nextSessionID = &next
+	case !errors.Is(err, sql.ErrNoRows):
+		return SessionOutcome{}, nil, err
+	}
+
+	outcome, draft, err := recordOutcome(ctx, tx, session, nextSessionID, entry, time.Now().UTC())
+	if err != nil {
+		return SessionOutcome{}, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return SessionOutcome{}, nil, err
+	}
+
+	return outcome, draft, nil
+}
+
+func queryMissingOutcomes(ctx context.Context, q queryer, query string, args ...any) ([]MissingOutcome, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	missing := make([]MissingOutcome, 0)
+	for rows.Next() {
+		var (
+			item     MissingOutcome
+			memberID uuid.NullUUID
+			goalID   uuid.NullUUID
+			closedAt sql.NullTime
+		)
+		if err := rows.Scan(&item.IntentID, &item.Statement, &memberID, &goalID, &item.SessionID, &item.ChapterID, &item.SessionEndsAt, &closedAt); err != nil {
+			return nil, err
+		}
+		item.MemberID = nullUUIDPtr(memberID)
+		item.GoalID = nullUUIDPtr(goalID)
+		item.SessionClosedAt = nullTimePtr(closedAt)
+		missing = append(missing, item)
+	}
+
+	return missing, rows.Err()
+}
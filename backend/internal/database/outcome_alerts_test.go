@@ -0,0 +1,151 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+var missingOutcomeRowColumns = []string{"id", "statement", "member_id", "goal_id", "id", "chapter_id", "ends_at", "closed_at"}
+
+func TestRecordOutcomeAlertsGroupsByRecipient(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, authorID, leadID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	first, second := uuid.New(), uuid.New()
+	endsAt := time.Date(2024, 5, 6, 17, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("NOT EXISTS (SELECT 1 FROM session_outcomes o WHERE o.intent_id = i.id)")).
+		WithArgs(sqlmock.AnyArg(), sessionID).
+		WillReturnRows(sqlmock.NewRows(missingOutcomeRowColumns).
+			AddRow(first, "Ship retry dashboard", authorID, nil, sessionID, chapterID, endsAt, endsAt).
+			AddRow(second, "Spike on caching", nil, nil, sessionID, chapterID, endsAt, endsAt))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO outcome_alerts")).
+		WithArgs("{"+first.String()+","+second.String()+"}", OutcomeAlertMissing, chapterID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"recipient_id", "intent_id"}).
+			AddRow(authorID, first).
+			AddRow(leadID, first).
+			AddRow(leadID, second))
+	mock.ExpectCommit()
+
+	delivered := 0
+	alerts, err := RecordOutcomeAlerts(context.Background(), db, sessionID, OutcomeAlertMissing, func(*sql.Tx, OutcomeAlert) error {
+		delivered++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RecordOutcomeAlerts returned error: %v", err)
+	}
+
+	if len(alerts) != 2 || delivered != 2 {
+		t.Fatalf("expected one alert per recipient got %+v", alerts)
+	}
+	if alerts[0].RecipientID != authorID || len(alerts[0].Intents) != 1 {
+		t.Fatalf("unexpected author alert: %+v", alerts[0])
+	}
+	if alerts[1].RecipientID != leadID || len(alerts[1].Intents) != 2 || alerts[1].Intents[1].Statement != "Spike on caching" {
+		t.Fatalf("unexpected lead alert: %+v", alerts[1])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestRecordOutcomeAlertsNothingMissing(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM intents i JOIN sessions s")).
+		WithArgs(sqlmock.AnyArg(), sessionID).
+		WillReturnRows(sqlmock.NewRows(missingOutcomeRowColumns))
+	mock.ExpectCommit()
+
+	alerts, err := RecordOutcomeAlerts(context.Background(), db, sessionID, OutcomeAlertEscalation, func(*sql.Tx, OutcomeAlert) error {
+		t.Fatal("nothing should be delivered")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RecordOutcomeAlerts returned error: %v", err)
+	}
+	if len(alerts) != 0 {
+		t.Fatalf("expected no alerts got %+v", alerts)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestRecordLateOutcomeRequiresClosedSession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, intentID := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	expectSessionRitualLock(mock, sessionID, chapterID, time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC), SessionKickedOff)
+	mock.ExpectRollback()
+
+	_, _, err = RecordLateOutcome(context.Background(), db, sessionID, SessionOutcomeInput{MemberID: uuid.New(), IntentID: &intentID, Outcome: "Shipped"})
+	if !errors.Is(err, ErrSessionNotClosed) {
+		t.Fatalf("expected ErrSessionNotClosed got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestRecordLateOutcomeRejectsIntentFromAnotherSession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, memberID, intentID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	startsAt := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	expectSessionRitualLock(mock, sessionID, chapterID, startsAt, SessionClosed)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM sessions WHERE chapter_id = $1 AND starts_at > $2 ORDER BY starts_at LIMIT 1")).
+		WithArgs(chapterID, startsAt).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT chapter_id FROM members WHERE id = $1")).
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"chapter_id"}).AddRow(chapterID))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM intents i LEFT JOIN goals g ON g.id = i.goal_id WHERE i.id = $1")).
+		WithArgs(intentID).
+		WillReturnRows(sqlmock.NewRows(outcomeIntentRowColumns).AddRow(nil, nil, uuid.New(), memberID))
+	mock.ExpectRollback()
+
+	_, _, err = RecordLateOutcome(context.Background(), db, sessionID, SessionOutcomeInput{MemberID: memberID, IntentID: &intentID, Outcome: "Shipped"})
+	if !errors.Is(err, ErrIntentNotInSession) {
+		t.Fatalf("expected ErrIntentNotInSession got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,651 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/example/intent/backend/internal/retro"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrRetroTemplateNotFound is returned when no usable survey template
+	// exists for the session's chapter.
+	ErrRetroTemplateNotFound = errors.New("retro template not found")
+	// ErrRetroSurveyNotOpen is returned when a session has no retro survey.
+	ErrRetroSurveyNotOpen = errors.New("no retro survey is open for this session")
+	// ErrRetroSurveyAlreadyOpen is returned when a session already has a
+	// retro survey.
+	ErrRetroSurveyAlreadyOpen = errors.New("a retro survey is already open for this session")
+	// ErrSessionNotClosed is returned when a retro survey is opened for a
+	// session that has not been closed out.
+	ErrSessionNotClosed = errors.New("session has not been closed out")
+	// ErrAlreadyResponded is returned when a member answers a retro survey a
+	// second time.
+	ErrAlreadyResponded = errors.New("member has already responded to this retro survey")
+)
+
+// RetroTemplate is a reusable set of micro-survey questions. Templates
+// without a chapter apply to every chapter.
+type RetroTemplate struct {
+	ID        uuid.UUID
+	ChapterID *uuid.UUID
+	Name      string
+	Anonymous bool
+	Questions []retro.Question
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// RetroTemplateInput captures the fields required to create a template.
+type RetroTemplateInput struct {
+	ChapterID *uuid.UUID
+	Name      string
+	Anonymous bool
+	Questions []retro.Question
+}
+
+// RetroSurvey is the survey opened for a session. It snapshots the template's
+// questions and anonymity at the time it was opened.
+type RetroSurvey struct {
+	ID         uuid.UUID
+	SessionID  uuid.UUID
+	TemplateID *uuid.UUID
+	Anonymous  bool
+	Questions  []retro.Question
+	OpenedAt   time.Time
+}
+
+// SessionRetro is a session's survey together with its responses. Answers to
+// anonymous surveys are only available as a tally.
+type SessionRetro struct {
+	Survey       RetroSurvey
+	Participants int
+	Responses    []retro.Response
+	Tally        retro.Tally
+}
+
+// ChapterRetroGroup collects the responses to every survey a chapter opened
+// from the same template. Questions come from the most recent survey.
+type ChapterRetroGroup struct {
+	TemplateID *uuid.UUID
+	Questions  []retro.Question
+	Surveys    int
+	Responses  []retro.Response
+	Tally      retro.Tally
+}
+
+const retroTemplateColumns = "id, chapter_id, name, anonymous, questions, created_at, updated_at"
+
+const retroSurveyColumns = "id, session_id, template_id, anonymous, questions, opened_at"
+
+// CreateRetroTemplate persists a new survey template.
+func CreateRetroTemplate(ctx context.Context, db *sql.DB, input RetroTemplateInput) (RetroTemplate, error) {
+	if db == nil {
+		return RetroTemplate{}, errors.New("database handle is nil")
+	}
+
+	questionsJSON, err := json.Marshal(input.Questions)
+	if err != nil {
+		return RetroTemplate{}, err
+	}
+
+	now := time.Now().UTC()
+	template := RetroTemplate{
+		ID:        uuid.New(),
+		ChapterID: input.ChapterID,
+		Name:      input.Name,
+		Anonymous: input.Anonymous,
+		Questions: input.Questions,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	const query = `
+INSERT INTO retro_templates (id, chapter_id, name, anonymous, questions, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+	if _, err := db.ExecContext(ctx, query, template.ID, uuidPtrValue(template.ChapterID), template.Name, template.Anonymous, string(questionsJSON), now, now); err != nil {
+		return RetroTemplate{}, err
+	}
+
+	return template, nil
+}
+
+// GetRetroTemplate retrieves a survey template by identifier.
+func GetRetroTemplate(ctx context.Context, db *sql.DB, id uuid.UUID) (RetroTemplate, error) {
+	if db == nil {
+		return RetroTemplate{}, errors.New("database handle is nil")
+	}
+
+	const query = `SELECT ` + retroTemplateColumns + ` FROM retro_templates WHERE id = $1`
+
+	return scanRetroTemplate(db.QueryRowContext(ctx, query, id))
+}
+
+// ListRetroTemplates returns survey templates, newest first. When chapterID is
+// set only that chapter's templates and the global ones are returned.
+func ListRetroTemplates(ctx context.Context, db *sql.DB, chapterID *uuid.UUID) ([]RetroTemplate, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	query := `SELECT ` + retroTemplateColumns + ` FROM retro_templates`
+	args := []any{}
+	if chapterID != nil {
+		query += ` WHERE chapter_id = $1 OR chapter_id IS NULL`
+		args = append(args, *chapterID)
+	}
+	query += ` ORDER BY created_at DESC, id`
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := make([]RetroTemplate, 0)
+	for rows.Next() {
+		template, err := scanRetroTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// DeleteRetroTemplate removes a survey template. Surveys already opened from
+// it keep their question snapshot.
+func DeleteRetroTemplate(ctx context.Context, db *sql.DB, id uuid.UUID) error {
+	if db == nil {
+		return errors.New("database handle is nil")
+	}
+
+	result, err := db.ExecContext(ctx, `DELETE FROM retro_templates WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// OpenRetroSurvey opens a retro survey for a closed session that does not
+// have one yet, typically because no template existed when it closed. Without
+// a templateID the chapter's default template is used.
+func OpenRetroSurvey(ctx context.Context, db *sql.DB, sessionID uuid.UUID, templateID *uuid.UUID) (RetroSurvey, error) {
+	if db == nil {
+		return RetroSurvey{}, errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return RetroSurvey{}, err
+	}
+	defer tx.Rollback()
+
+	session, err := lockSessionForRitual(ctx, tx, sessionID)
+	if err != nil {
+		return RetroSurvey{}, err
+	}
+
+	if session.State != SessionClosed {
+		return RetroSurvey{}, ErrSessionNotClosed
+	}
+
+	var existing uuid.UUID
+	err = tx.QueryRowContext(ctx, `SELECT id FROM retro_surveys WHERE session_id = $1`, sessionID).Scan(&existing)
+	switch {
+	case err == nil:
+		return RetroSurvey{}, ErrRetroSurveyAlreadyOpen
+	case !errors.Is(err, sql.ErrNoRows):
+		return RetroSurvey{}, err
+	}
+
+	survey, err := openRetroSurvey(ctx, tx, session, templateID, time.Now().UTC())
+	if err != nil {
+		return RetroSurvey{}, err
+	}
+
+	if survey == nil {
+		return RetroSurvey{}, ErrRetroTemplateNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return RetroSurvey{}, err
+	}
+
+	return *survey, nil
+}
+
+// openRetroSurvey snapshots a template into a survey for the session. Without
+// a templateID the newest template of the session's chapter is used, falling
+// back to the newest global template; it returns nil when there is none.
+func openRetroSurvey(ctx context.Context, tx *sql.Tx, session Session, templateID *uuid.UUID, now time.Time) (*RetroSurvey, error) {
+	var (
+		template RetroTemplate
+		err      error
+	)
+
+	if templateID != nil {
+		const query = `
+SELECT ` + retroTemplateColumns + `
+FROM retro_templates
+WHERE id = $1 AND (chapter_id = $2 OR chapter_id IS NULL)
+`
+		template, err = scanRetroTemplate(tx.QueryRowContext(ctx, query, *templateID, session.ChapterID))
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRetroTemplateNotFound
+		}
+	} else {
+		const query = `
+SELECT ` + retroTemplateColumns + `
+FROM retro_templates
+WHERE chapter_id = $1 OR chapter_id IS NULL
+ORDER BY chapter_id IS NULL, created_at DESC
+LIMIT 1
+`
+		template, err = scanRetroTemplate(tx.QueryRowContext(ctx, query, session.ChapterID))
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	questionsJSON, err := json.Marshal(template.Questions)
+	if err != nil {
+		return nil, err
+	}
+
+	survey := RetroSurvey{
+		ID:         uuid.New(),
+		SessionID:  session.ID,
+		TemplateID: &template.ID,
+		Anonymous:  template.Anonymous,
+		Questions:  template.Questions,
+		OpenedAt:   now,
+	}
+
+	const query = `
+INSERT INTO retro_surveys (id, session_id, template_id, anonymous, questions, opened_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+	if _, err := tx.ExecContext(ctx, query, survey.ID, survey.SessionID, template.ID, survey.Anonymous, string(questionsJSON), now); err != nil {
+		return nil, err
+	}
+
+	return &survey, nil
+}
+
+// SubmitRetroResponse records a member's answers to the session's retro
+// survey. Each member may respond once. Answers to anonymous surveys are only
+// added to the survey's tally, never stored as a response of their own.
+func SubmitRetroResponse(ctx context.Context, db *sql.DB, sessionID, memberID uuid.UUID, answers map[string]any) error {
+	if db == nil {
+		return errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const surveyQuery = `
+SELECT rs.id, rs.session_id, rs.template_id, rs.anonymous, rs.questions, rs.opened_at, s.chapter_id
+FROM retro_surveys rs
+JOIN sessions s ON s.id = rs.session_id
+WHERE rs.session_id = $1
+FOR SHARE OF rs
+`
+
+	var chapterID uuid.UUID
+	survey, err := scanRetroSurvey(tx.QueryRowContext(ctx, surveyQuery, sessionID), &chapterID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrRetroSurveyNotOpen
+		}
+		return err
+	}
+
+	var memberChapter uuid.UUID
+	if err := tx.QueryRowContext(ctx, `SELECT chapter_id FROM members WHERE id = $1`, memberID).Scan(&memberChapter); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrMemberNotFound
+		}
+		return err
+	}
+
+	if memberChapter != chapterID {
+		return ErrMemberNotInChapter
+	}
+
+	normalized, err := retro.NormalizeAnswers(survey.Questions, answers)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `INSERT INTO retro_participants (survey_id, member_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, survey.ID, memberID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrAlreadyResponded
+	}
+
+	if survey.Anonymous {
+		if err := addToRetroTally(ctx, tx, survey.ID, normalized); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	answersJSON, err := json.Marshal(normalized)
+	if err != nil {
+		return err
+	}
+
+	const insertQuery = `
+INSERT INTO retro_responses (id, survey_id, member_id, answers, submitted_at)
+VALUES ($1, $2, $3, $4, $5)
+`
+
+	if _, err := tx.ExecContext(ctx, insertQuery, uuid.New(), survey.ID, memberID, string(answersJSON), time.Now().UTC()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// addToRetroTally adds answers to an anonymous survey's tally. The tally row
+// is locked so concurrent responses are not lost.
+func addToRetroTally(ctx context.Context, tx *sql.Tx, surveyID uuid.UUID, answers map[string]any) error {
+	if _, err := tx.ExecContext(ctx, `INSERT INTO retro_tallies (survey_id, tally) VALUES ($1, '{"responses": 0, "answers": {}}') ON CONFLICT (survey_id) DO NOTHING`, surveyID); err != nil {
+		return err
+	}
+
+	var raw []byte
+	if err := tx.QueryRowContext(ctx, `SELECT tally FROM retro_tallies WHERE survey_id = $1 FOR UPDATE`, surveyID).Scan(&raw); err != nil {
+		return err
+	}
+
+	var tally retro.Tally
+	if err := json.Unmarshal(raw, &tally); err != nil {
+		return err
+	}
+	tally.Add(answers)
+
+	tallyJSON, err := json.Marshal(tally)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE retro_tallies SET tally = $1 WHERE survey_id = $2`, string(tallyJSON), surveyID)
+	return err
+}
+
+// GetSessionRetro returns the session's retro survey and its responses.
+func GetSessionRetro(ctx context.Context, db *sql.DB, sessionID uuid.UUID) (SessionRetro, error) {
+	if db == nil {
+		return SessionRetro{}, errors.New("database handle is nil")
+	}
+
+	const surveyQuery = `
+SELECT ` + retroSurveyColumns + `, (SELECT COUNT(*) FROM retro_participants WHERE survey_id = retro_surveys.id)
+FROM retro_surveys
+WHERE session_id = $1
+`
+
+	var sessionRetro SessionRetro
+	survey, err := scanRetroSurvey(db.QueryRowContext(ctx, surveyQuery, sessionID), &sessionRetro.Participants)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return SessionRetro{}, ErrRetroSurveyNotOpen
+		}
+		return SessionRetro{}, err
+	}
+	sessionRetro.Survey = survey
+
+	responses, err := listRetroResponses(ctx, db, []uuid.UUID{survey.ID})
+	if err != nil {
+		return SessionRetro{}, err
+	}
+	sessionRetro.Responses = responses[survey.ID]
+	if sessionRetro.Responses == nil {
+		sessionRetro.Responses = []retro.Response{}
+	}
+
+	if survey.Anonymous {
+		tallies, err := listRetroTallies(ctx, db, []uuid.UUID{survey.ID})
+		if err != nil {
+			return SessionRetro{}, err
+		}
+		sessionRetro.Tally = tallies[survey.ID]
+	}
+
+	return sessionRetro, nil
+}
+
+// ListChapterRetro groups the responses to every retro survey of the
+// chapter's sessions by the template they were opened from.
+func ListChapterRetro(ctx context.Context, db *sql.DB, chapterID uuid.UUID) ([]ChapterRetroGroup, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	const query = `
+SELECT rs.id, rs.session_id, rs.template_id, rs.anonymous, rs.questions, rs.opened_at
+FROM retro_surveys rs
+JOIN sessions s ON s.id = rs.session_id
+WHERE s.chapter_id = $1
+ORDER BY rs.opened_at DESC, rs.id
+`
+
+	rows, err := db.QueryContext(ctx, query, chapterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		surveys   []RetroSurvey
+		surveyIDs []uuid.UUID
+	)
+	for rows.Next() {
+		survey, err := scanRetroSurvey(rows)
+		if err != nil {
+			return nil, err
+		}
+		surveys = append(surveys, survey)
+		surveyIDs = append(surveyIDs, survey.ID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	groups := make([]ChapterRetroGroup, 0)
+	if len(surveys) == 0 {
+		return groups, nil
+	}
+
+	responses, err := listRetroResponses(ctx, db, surveyIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	tallies, err := listRetroTallies(ctx, db, surveyIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Surveys whose template was deleted are grouped on their own.
+	index := make(map[uuid.UUID]int)
+	for _, survey := range surveys {
+		key := survey.ID
+		if survey.TemplateID != nil {
+			key = *survey.TemplateID
+		}
+
+		position, ok := index[key]
+		if !ok {
+			position = len(groups)
+			index[key] = position
+			groups = append(groups, ChapterRetroGroup{
+				TemplateID: survey.TemplateID,
+				Questions:  survey.Questions,
+				Responses:  []retro.Response{},
+			})
+		}
+
+		groups[position].Surveys++
+		groups[position].Responses = append(groups[position].Responses, responses[survey.ID]...)
+		groups[position].Tally.Merge(tallies[survey.ID])
+	}
+
+	return groups, nil
+}
+
+// listRetroTallies loads the tallies of anonymous surveys keyed by survey.
+// Surveys nobody has answered have none.
+func listRetroTallies(ctx context.Context, q queryer, surveyIDs []uuid.UUID) (map[uuid.UUID]retro.Tally, error) {
+	rows, err := q.QueryContext(ctx, `SELECT survey_id, tally FROM retro_tallies WHERE survey_id = ANY($1::uuid[])`, uuidArrayLiteral(surveyIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tallies := make(map[uuid.UUID]retro.Tally)
+	for rows.Next() {
+		var (
+			surveyID uuid.UUID
+			raw      []byte
+			tally    retro.Tally
+		)
+
+		if err := rows.Scan(&surveyID, &raw); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(raw, &tally); err != nil {
+			return nil, err
+		}
+
+		tallies[surveyID] = tally
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tallies, nil
+}
+
+// listRetroResponses loads responses keyed by survey, in submission order.
+func listRetroResponses(ctx context.Context, q queryer, surveyIDs []uuid.UUID) (map[uuid.UUID][]retro.Response, error) {
+	const query = `
+SELECT survey_id, member_id, answers
+FROM retro_responses
+WHERE survey_id = ANY($1::uuid[])
+ORDER BY submitted_at NULLS LAST, id
+`
+
+	rows, err := q.QueryContext(ctx, query, uuidArrayLiteral(surveyIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	responses := make(map[uuid.UUID][]retro.Response)
+	for rows.Next() {
+		var (
+			surveyID   uuid.UUID
+			memberID   uuid.NullUUID
+			rawAnswers []byte
+			response   retro.Response
+		)
+
+		if err := rows.Scan(&surveyID, &memberID, &rawAnswers); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(rawAnswers, &response.Answers); err != nil {
+			return nil, err
+		}
+		response.Respondent = nullUUIDPtr(memberID)
+
+		responses[surveyID] = append(responses[surveyID], response)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return responses, nil
+}
+
+func scanRetroTemplate(row rowScanner) (RetroTemplate, error) {
+	var (
+		template     RetroTemplate
+		chapterID    uuid.NullUUID
+		rawQuestions []byte
+	)
+
+	if err := row.Scan(&template.ID, &chapterID, &template.Name, &template.Anonymous, &rawQuestions, &template.CreatedAt, &template.UpdatedAt); err != nil {
+		return RetroTemplate{}, err
+	}
+
+	if err := json.Unmarshal(rawQuestions, &template.Questions); err != nil {
+		return RetroTemplate{}, err
+	}
+
+	template.ChapterID = nullUUIDPtr(chapterID)
+	return template, nil
+}
+
+func scanRetroSurvey(row rowScanner, extra ...any) (RetroSurvey, error) {
+	var (
+		survey       RetroSurvey
+		templateID   uuid.NullUUID
+		rawQuestions []byte
+	)
+
+	dest := append([]any{&survey.ID, &survey.SessionID, &templateID, &survey.Anonymous, &rawQuestions, &survey.OpenedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return RetroSurvey{}, err
+	}
+
+	if err := json.Unmarshal(rawQuestions, &survey.Questions); err != nil {
+		return RetroSurvey{}, err
+	}
+
+	survey.TemplateID = nullUUIDPtr(templateID)
+	return survey, nil
+}
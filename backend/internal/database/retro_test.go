@@ -0,0 +1,243 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/intent/backend/internal/retro"
+	"github.com/google/uuid"
+)
+
+var retroSurveyRowColumns = []string{"id", "session_id", "template_id", "anonymous", "questions", "opened_at"}
+
+const retroTestQuestions = `[{"id":"energy","prompt":"How was your energy?","kind":"scale","min":1,"max":5,"required":true},` +
+	`{"id":"notes","prompt":"Anything else?","kind":"text","required":false}]`
+
+func expectRetroSurveyForResponse(mock sqlmock.Sqlmock, surveyID, sessionID, chapterID uuid.UUID, anonymous bool) {
+	mock.ExpectQuery(regexp.QuoteMeta("FROM retro_surveys rs")).
+		WithArgs(sessionID).
+		WillReturnRows(sqlmock.NewRows(append(retroSurveyRowColumns, "chapter_id")).
+			AddRow(surveyID, sessionID, uuid.New(), anonymous, []byte(retroTestQuestions), time.Now(), chapterID))
+}
+
+func TestCloseoutSessionOpensRetroSurveyFromTemplate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, templateID := uuid.New(), uuid.New(), uuid.New()
+	startsAt := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	expectSessionRitualLock(mock, sessionID, chapterID, startsAt, SessionKickedOff)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM sessions WHERE chapter_id = $1")).
+		WithArgs(chapterID, startsAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE sessions SET state = $1")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY chapter_id IS NULL, created_at DESC")).
+		WithArgs(chapterID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "chapter_id", "name", "anonymous", "questions", "created_at", "updated_at"}).
+			AddRow(templateID, nil, "Pulse", true, []byte(retroTestQuestions), startsAt, startsAt))
+	mock.ExpectExec("INSERT INTO retro_surveys").
+		WithArgs(sqlmock.AnyArg(), sessionID, templateID, true, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	closeout, err := CloseoutSession(context.Background(), db, sessionID, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	survey := closeout.RetroSurvey
+	if survey == nil || !survey.Anonymous || survey.TemplateID == nil || *survey.TemplateID != templateID {
+		t.Fatalf("expected anonymous survey from template got %+v", survey)
+	}
+
+	if len(survey.Questions) != 2 {
+		t.Fatalf("expected question snapshot got %+v", survey.Questions)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSubmitRetroResponseAnonymousOnlyTallies(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	surveyID, sessionID, chapterID, memberID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	expectRetroSurveyForResponse(mock, surveyID, sessionID, chapterID, true)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT chapter_id FROM members WHERE id = $1")).
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"chapter_id"}).AddRow(chapterID))
+	mock.ExpectExec("INSERT INTO retro_participants").
+		WithArgs(surveyID, memberID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO retro_tallies").
+		WithArgs(surveyID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT tally FROM retro_tallies WHERE survey_id = $1 FOR UPDATE")).
+		WithArgs(surveyID).
+		WillReturnRows(sqlmock.NewRows([]string{"tally"}).AddRow([]byte(`{"responses":1,"answers":{"energy":[5]}}`)))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE retro_tallies SET tally = $1 WHERE survey_id = $2")).
+		WithArgs(`{"responses":2,"answers":{"energy":[4,5]}}`, surveyID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Participation and the tally are committed together; no response row is
+	// written.
+	mock.ExpectCommit()
+
+	if err := SubmitRetroResponse(context.Background(), db, sessionID, memberID, map[string]any{"energy": float64(4)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSubmitRetroResponseAnonymousRollsBackParticipationOnFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	surveyID, sessionID, chapterID, memberID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	updateErr := errors.New("update failed")
+
+	mock.ExpectBegin()
+	expectRetroSurveyForResponse(mock, surveyID, sessionID, chapterID, true)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT chapter_id FROM members WHERE id = $1")).
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"chapter_id"}).AddRow(chapterID))
+	mock.ExpectExec("INSERT INTO retro_participants").
+		WithArgs(surveyID, memberID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO retro_tallies").
+		WithArgs(surveyID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT tally FROM retro_tallies WHERE survey_id = $1 FOR UPDATE")).
+		WithArgs(surveyID).
+		WillReturnRows(sqlmock.NewRows([]string{"tally"}).AddRow([]byte(`{"responses":0,"answers":{}}`)))
+	mock.ExpectExec("UPDATE retro_tallies").
+		WillReturnError(updateErr)
+	mock.ExpectRollback()
+
+	err = SubmitRetroResponse(context.Background(), db, sessionID, memberID, map[string]any{"energy": float64(4)})
+	if !errors.Is(err, updateErr) {
+		t.Fatalf("expected update error got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSubmitRetroResponseRejectsSecondResponse(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	surveyID, sessionID, chapterID, memberID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	expectRetroSurveyForResponse(mock, surveyID, sessionID, chapterID, false)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT chapter_id FROM members WHERE id = $1")).
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"chapter_id"}).AddRow(chapterID))
+	mock.ExpectExec("INSERT INTO retro_participants").
+		WithArgs(surveyID, memberID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = SubmitRetroResponse(context.Background(), db, sessionID, memberID, map[string]any{"energy": float64(2)})
+	if !errors.Is(err, ErrAlreadyResponded) {
+		t.Fatalf("expected ErrAlreadyResponded got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSubmitRetroResponseValidatesAnswers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	surveyID, sessionID, chapterID, memberID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	expectRetroSurveyForResponse(mock, surveyID, sessionID, chapterID, false)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT chapter_id FROM members WHERE id = $1")).
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"chapter_id"}).AddRow(chapterID))
+	mock.ExpectRollback()
+
+	err = SubmitRetroResponse(context.Background(), db, sessionID, memberID, map[string]any{"energy": float64(9)})
+	if !errors.Is(err, retro.ErrInvalidAnswer) {
+		t.Fatalf("expected ErrInvalidAnswer got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestListChapterRetroGroupsByTemplate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	chapterID, templateID, firstSurvey, secondSurvey := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	openedAt := time.Date(2024, 5, 6, 17, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE s.chapter_id = $1")).
+		WithArgs(chapterID).
+		WillReturnRows(sqlmock.NewRows(retroSurveyRowColumns).
+			AddRow(secondSurvey, uuid.New(), templateID, true, []byte(retroTestQuestions), openedAt.AddDate(0, 0, 7)).
+			AddRow(firstSurvey, uuid.New(), templateID, true, []byte(retroTestQuestions), openedAt))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM retro_responses")).
+		WillReturnRows(sqlmock.NewRows([]string{"survey_id", "member_id", "answers"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT survey_id, tally FROM retro_tallies")).
+		WillReturnRows(sqlmock.NewRows([]string{"survey_id", "tally"}).
+			AddRow(firstSurvey, []byte(`{"responses":1,"answers":{"energy":[2]}}`)).
+			AddRow(secondSurvey, []byte(`{"responses":1,"answers":{"energy":[4]}}`)))
+
+	groups, err := ListChapterRetro(context.Background(), db, chapterID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(groups) != 1 || groups[0].Surveys != 2 || groups[0].Tally.Responses != 2 {
+		t.Fatalf("expected one group of two surveys got %+v", groups)
+	}
+
+	results := retro.Aggregate(groups[0].Questions, groups[0].Responses, groups[0].Tally, false)
+	if results[0].Average == nil || *results[0].Average != 3 {
+		t.Fatalf("expected average 3 got %+v", results[0])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
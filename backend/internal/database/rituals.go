@@ -0,0 +1,642 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrSwarmNotFound is returned when a ritual references a swarm that is
+	// not part of the session.
+	ErrSwarmNotFound = errors.New("swarm not found")
+	// ErrAcknowledgerNotInSwarm is returned when a guardrail acknowledgement
+	// comes from someone who is not a member of the swarm.
+	ErrAcknowledgerNotInSwarm = errors.New("guardrails can only be acknowledged by members of the swarm")
+	// ErrCriterionNotOnGoal is returned when an outcome cites a success
+	// criterion that the goal of its intent does not have.
+	ErrCriterionNotOnGoal = errors.New("satisfied criteria must be success criteria of the intent's goal")
+	// ErrIntentNotOwnedByMember is returned when an outcome reports on an
+	// intent owned by another member.
+	ErrIntentNotOwnedByMember = errors.New("intent is not owned by the member reporting the outcome")
+)
+
+// SwarmKickoff is the snapshot a swarm takes at the session kickoff: the
+// intents it confirmed, the dependencies it called out, the guardrails of the
+// goals those intents serve and the members who acknowledged them.
+type SwarmKickoff struct {
+	SessionID      uuid.UUID
+	SwarmID        uuid.UUID
+	IntentIDs      []uuid.UUID
+	Dependencies   []string
+	Guardrails     []string
+	AcknowledgedBy []uuid.UUID
+	CreatedAt      time.Time
+}
+
+// SwarmKickoffInput captures what a single swarm confirms at kickoff.
+type SwarmKickoffInput struct {
+	SwarmID        uuid.UUID
+	IntentIDs      []uuid.UUID
+	Dependencies   []string
+	AcknowledgedBy []uuid.UUID
+}
+
+// SessionOutcome is a member's close-out entry: what came of their work,
+// what got in the way, the goal success criteria it satisfied and,
+// optionally, the draft intent carried forward.
+type SessionOutcome struct {
+	ID                uuid.UUID
+	SessionID         uuid.UUID
+	MemberID          uuid.UUID
+	IntentID          *uuid.UUID
+	Outcome           string
+	Obstacles         string
+	SatisfiedCriteria []string
+	NextIntentID      *uuid.UUID
+	CreatedAt         time.Time
+}
+
+// SessionOutcomeInput captures a member's close-out entry. SatisfiedCriteria
+// must be success criteria of the intent's goal. NextIntent, when set,
+// becomes a draft intent in the chapter's next session.
+type SessionOutcomeInput struct {
+	MemberID          uuid.UUID
+	IntentID          *uuid.UUID
+	Outcome           string
+	Obstacles         string
+	SatisfiedCriteria []string
+	NextIntent        *IntentInput
+}
+
+// SessionCloseout is the result of closing a session.
+type SessionCloseout struct {
+	Session       Session
+	Outcomes      []SessionOutcome
+	DraftIntents  []Intent
+	NextSessionID *uuid.UUID
+	RetroSurvey   *RetroSurvey
+}
+
+// KickoffSession runs the kickoff ritual for a scheduled session. Each swarm
+// snapshots its confirmed intents, dependencies and guardrail
+// acknowledgements; confirmed draft intents become active and are planned
+// for this session. The session then moves to kicked_off.
+func KickoffSession(ctx context.Context, db *sql.DB, sessionID uuid.UUID, swarms []SwarmKickoffInput) (Session, []SwarmKickoff, error) {
+	if db == nil {
+		return Session{}, nil, errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Session{}, nil, err
+	}
+	defer tx.Rollback()
+
+	session, err := lockSessionForRitual(ctx, tx, sessionID)
+	if err != nil {
+		return Session{}, nil, err
+	}
+
+	switch session.State {
+	case SessionKickedOff:
+		return Session{}, nil, ErrSessionAlreadyKickedOff
+	case SessionClosed:
+		return Session{}, nil, ErrSessionClosed
+	}
+
+	now := time.Now().UTC()
+	kickoffs := make([]SwarmKickoff, 0, len(swarms))
+	for _, input := range swarms {
+		kickoff, err := kickoffSwarm(ctx, tx, sessionID, input, now)
+		if err != nil {
+			return Session{}, nil, err
+		}
+		kickoffs = append(kickoffs, kickoff)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE sessions SET state = $1, kicked_off_at = $2, updated_at = $2, sequence = sequence + 1 WHERE id = $3`, SessionKickedOff, now, sessionID); err != nil {
+		return Session{}, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Session{}, nil, err
+	}
+
+	session.State = SessionKickedOff
+	session.KickedOffAt = &now
+	return session, kickoffs, nil
+}
+
+func kickoffSwarm(ctx context.Context, tx *sql.Tx, sessionID uuid.UUID, input SwarmKickoffInput, now time.Time) (SwarmKickoff, error) {
+	swarm, err := getSwarm(ctx, tx, sessionID, input.SwarmID, false)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return SwarmKickoff{}, ErrSwarmNotFound
+		}
+		return SwarmKickoff{}, err
+	}
+
+	if swarm.Status != SwarmActive {
+		return SwarmKickoff{}, ErrSwarmDissolved
+	}
+
+	members := make(map[uuid.UUID]struct{}, len(swarm.MemberIDs))
+	for _, memberID := range swarm.MemberIDs {
+		members[memberID] = struct{}{}
+	}
+	for _, memberID := range input.AcknowledgedBy {
+		if _, ok := members[memberID]; !ok {
+			return SwarmKickoff{}, ErrAcknowledgerNotInSwarm
+		}
+	}
+
+	guardrails, err := confirmIntents(ctx, tx, sessionID, input.IntentIDs)
+	if err != nil {
+		return SwarmKickoff{}, err
+	}
+
+	kickoff := SwarmKickoff{
+		SessionID:      sessionID,
+		SwarmID:        input.SwarmID,
+		IntentIDs:      nonNilUUIDs(input.IntentIDs),
+		Dependencies:   nonNilStrings(input.Dependencies),
+		Guardrails:     guardrails,
+		AcknowledgedBy: nonNilUUIDs(input.AcknowledgedBy),
+		CreatedAt:      now,
+	}
+
+	intentJSON, err := json.Marshal(kickoff.IntentIDs)
+	if err != nil {
+		return SwarmKickoff{}, err
+	}
+
+	dependenciesJSON, err := json.Marshal(kickoff.Dependencies)
+	if err != nil {
+		return SwarmKickoff{}, err
+	}
+
+	guardrailsJSON, err := json.Marshal(kickoff.Guardrails)
+	if err != nil {
+		return SwarmKickoff{}, err
+	}
+
+	acknowledgedJSON, err := json.Marshal(kickoff.AcknowledgedBy)
+	if err != nil {
+		return SwarmKickoff{}, err
+	}
+
+	const query = `
+INSERT INTO session_kickoffs (session_id, swarm_id, intent_ids, dependencies, guardrails, acknowledged_by, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+`
+
+	if _, err := tx.ExecContext(ctx, query, sessionID, input.SwarmID, string(intentJSON), string(dependenciesJSON), string(guardrailsJSON), string(acknowledgedJSON), now); err != nil {
+		return SwarmKickoff{}, err
+	}
+
+	return kickoff, nil
+}
+
+// confirmIntents activates the given intents for the session and returns the
+// union of the guardrails of the goals they serve.
+func confirmIntents(ctx context.Context, tx *sql.Tx, sessionID uuid.UUID, intentIDs []uuid.UUID) ([]string, error) {
+	guardrails := make([]string, 0)
+	if len(intentIDs) == 0 {
+		return guardrails, nil
+	}
+
+	const query = `
+UPDATE intents
+SET status = 'active',
+    session_id = COALESCE(session_id, $2)
+FROM (SELECT id AS previous_id, status AS previous_status FROM intents WHERE id = ANY($1::uuid[]) FOR UPDATE) previous
+WHERE id = previous_id
+RETURNING ` + intentColumns + `, previous_status, (SELECT guardrails FROM goals WHERE goals.id = intents.goal_id)
+`
+
+	rows, err := tx.QueryContext(ctx, query, uuidArrayLiteral(intentIDs), sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := make(map[uuid.UUID]struct{}, len(intentIDs))
+	seen := make(map[string]struct{})
+	type activation struct {
+		intent Intent
+		from   string
+	}
+	activated := make([]activation, 0, len(intentIDs))
+	for rows.Next() {
+		var (
+			previousStatus string
+			rawJSON        []byte
+		)
+
+		intent, err := scanIntent(rows, &previousStatus, &rawJSON)
+		if err != nil {
+			return nil, err
+		}
+		found[intent.ID] = struct{}{}
+
+		if previousStatus != IntentActive {
+			activated = append(activated, activation{intent: intent, from: previousStatus})
+		}
+
+		if len(rawJSON) == 0 {
+			continue
+		}
+
+		var goalGuardrails []string
+		if err := json.Unmarshal(rawJSON, &goalGuardrails); err != nil {
+			return nil, err
+		}
+
+		for _, guardrail := range goalGuardrails {
+			if _, ok := seen[guardrail]; ok {
+				continue
+			}
+			seen[guardrail] = struct{}{}
+			guardrails = append(guardrails, guardrail)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, id := range intentIDs {
+		if _, ok := found[id]; !ok {
+			return nil, ErrIntentNotFound
+		}
+	}
+
+	now := time.Now().UTC()
+	for _, a := range activated {
+		if err := recordIntentTransition(ctx, tx, a.intent, &a.from, a.intent.GoalID, now); err != nil {
+			return nil, err
+		}
+
+		if err := recordWebhookEvent(ctx, tx, EventIntentUpdated, intentSnapshot(a.intent)); err != nil {
+			return nil, err
+		}
+	}
+
+	return guardrails, nil
+}
+
+// ListSessionKickoffs returns the swarm snapshots taken at a session's
+// kickoff.
+func ListSessionKickoffs(ctx context.Context, db *sql.DB, sessionID uuid.UUID) ([]SwarmKickoff, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	const query = `
+SELECT session_id, swarm_id, intent_ids, dependencies, guardrails, acknowledged_by, created_at
+FROM session_kickoffs
+WHERE session_id = $1
+ORDER BY created_at, swarm_id
+`
+
+	rows, err := db.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	kickoffs := make([]SwarmKickoff, 0)
+	for rows.Next() {
+		var (
+			kickoff         SwarmKickoff
+			rawIntents      []byte
+			rawDependencies []byte
+			rawGuardrails   []byte
+			rawAcknowledged []byte
+		)
+
+		if err := rows.Scan(&kickoff.SessionID, &kickoff.SwarmID, &rawIntents, &rawDependencies, &rawGuardrails, &rawAcknowledged, &kickoff.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		for _, field := range []struct {
+			raw  []byte
+			dest any
+		}{
+			{rawIntents, &kickoff.IntentIDs},
+			{rawDependencies, &kickoff.Dependencies},
+			{rawGuardrails, &kickoff.Guardrails},
+			{rawAcknowledged, &kickoff.AcknowledgedBy},
+		} {
+			if err := json.Unmarshal(field.raw, field.dest); err != nil {
+				return nil, err
+			}
+		}
+
+		kickoffs = append(kickoffs, kickoff)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return kickoffs, nil
+}
+
+// CloseoutSession runs the close-out ritual for a kicked-off session. Each
+// entry records a member's outcome and obstacles; a next intent becomes a
+// draft intent owned by the member in the chapter's next scheduled session,
+// inheriting the goal of the intent the outcome reports on while that goal is
+// active. The session then moves to closed and a retro survey is opened from
+// the chapter's default template, if there is one.
+func CloseoutSession(ctx context.Context, db *sql.DB, sessionID uuid.UUID, entries []SessionOutcomeInput) (SessionCloseout, error) {
+	if db == nil {
+		return SessionCloseout{}, errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return SessionCloseout{}, err
+	}
+	defer tx.Rollback()
+
+	session, err := lockSessionForRitual(ctx, tx, sessionID)
+	if err != nil {
+		return SessionCloseout{}, err
+	}
+
+	switch session.State {
+	case SessionScheduled:
+		return SessionCloseout{}, ErrSessionNotKickedOff
+	case SessionClosed:
+		return SessionCloseout{}, ErrSessionClosed
+	}
+
+	closeout := SessionCloseout{
+		Outcomes:     make([]SessionOutcome, 0, len(entries)),
+		DraftIntents: make([]Intent, 0),
+	}
+
+	var nextSessionID uuid.UUID
+	err = tx.QueryRowContext(ctx, `SELECT id FROM sessions WHERE chapter_id = $1 AND starts_at > $2 ORDER BY starts_at LIMIT 1`, session.ChapterID, session.StartsAt).Scan(&nextSessionID)
+	switch {
+	case err == nil:
+		closeout.NextSessionID = &nextSessionID
+	case !errors.Is(err, sql.ErrNoRows):
+		return SessionCloseout{}, err
+	}
+
+	now := time.Now().UTC()
+	for _, entry := range entries {
+		outcome, draft, err := recordOutcome(ctx, tx, session, closeout.NextSessionID, entry, now)
+		if err != nil {
+			return SessionCloseout{}, err
+		}
+		closeout.Outcomes = append(closeout.Outcomes, outcome)
+		if draft != nil {
+			closeout.DraftIntents = append(closeout.DraftIntents, *draft)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE sessions SET state = $1, closed_at = $2, updated_at = $2, sequence = sequence + 1 WHERE id = $3`, SessionClosed, now, sessionID); err != nil {
+		return SessionCloseout{}, err
+	}
+
+	closeout.RetroSurvey, err = openRetroSurvey(ctx, tx, session, nil, now)
+	if err != nil {
+		return SessionCloseout{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return SessionCloseout{}, err
+	}
+
+	session.State = SessionClosed
+	session.ClosedAt = &now
+	closeout.Session = session
+	return closeout, nil
+}
+
+func recordOutcome(ctx context.Context, tx *sql.Tx, session Session, nextSessionID *uuid.UUID, entry SessionOutcomeInput, now time.Time) (SessionOutcome, *Intent, error) {
+	var memberChapter uuid.UUID
+	err := tx.QueryRowContext(ctx, `SELECT chapter_id FROM members WHERE id = $1`, entry.MemberID).Scan(&memberChapter)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return SessionOutcome{}, nil, ErrMemberNotFound
+		}
+		return SessionOutcome{}, nil, err
+	}
+
+	if memberChapter != session.ChapterID {
+		return SessionOutcome{}, nil, ErrMemberNotInChapter
+	}
+
+	var (
+		goalID     uuid.NullUUID
+		goalActive sql.NullBool
+	)
+	if entry.IntentID != nil {
+		var intentSession, intentMember uuid.NullUUID
+		err := tx.QueryRowContext(ctx, `SELECT i.goal_id, g.status = 'active', i.session_id, i.member_id FROM intents i LEFT JOIN goals g ON g.id = i.goal_id WHERE i.id = $1`, *entry.IntentID).Scan(&goalID, &goalActive, &intentSession, &intentMember)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return SessionOutcome{}, nil, ErrIntentNotFound
+			}
+			return SessionOutcome{}, nil, err
+		}
+
+		if !intentSession.Valid || intentSession.UUID != session.ID {
+			return SessionOutcome{}, nil, ErrIntentNotInSession
+		}
+		if !intentMember.Valid || intentMember.UUID != entry.MemberID {
+			return SessionOutcome{}, nil, ErrIntentNotOwnedByMember
+		}
+	}
+
+	if len(entry.SatisfiedCriteria) > 0 {
+		if err := checkGoalCriteria(ctx, tx, goalID, entry.SatisfiedCriteria); err != nil {
+			return SessionOutcome{}, nil, err
+		}
+	}
+
+	outcome := SessionOutcome{
+		ID:                uuid.New(),
+		SessionID:         session.ID,
+		MemberID:          entry.MemberID,
+		IntentID:          entry.IntentID,
+		Outcome:           entry.Outcome,
+		Obstacles:         entry.Obstacles,
+		SatisfiedCriteria: nonNilStrings(entry.SatisfiedCriteria),
+		CreatedAt:         now,
+	}
+
+	var draft *Intent
+	if entry.NextIntent != nil {
+		input := *entry.NextIntent
+		input.Status = IntentDraft
+		input.MemberID = &outcome.MemberID
+		input.SessionID = nextSessionID
+		// The next intent follows the same goal while that goal is still
+		// active; a goal chosen explicitly must be active.
+		if input.GoalID != nil {
+			if err := checkGoalActive(ctx, tx, *input.GoalID); err != nil {
+				return SessionOutcome{}, nil, err
+			}
+		} else if goalActive.Bool {
+			input.GoalID = nullUUIDPtr(goalID)
+		}
+		if input.Collaborators == nil {
+			input.Collaborators = []string{}
+		}
+
+		intent, err := insertIntent(ctx, tx, input)
+		if err != nil {
+			return SessionOutcome{}, nil, err
+		}
+		draft = &intent
+		outcome.NextIntentID = &intent.ID
+	}
+
+	criteriaJSON, err := json.Marshal(outcome.SatisfiedCriteria)
+	if err != nil {
+		return SessionOutcome{}, nil, err
+	}
+
+	const query = `
+INSERT INTO session_outcomes (id, session_id, member_id, intent_id, outcome, obstacles, satisfied_criteria, next_intent_id, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+`
+
+	if _, err := tx.ExecContext(ctx, query, outcome.ID, outcome.SessionID, outcome.MemberID, uuidPtrValue(outcome.IntentID), outcome.Outcome, outcome.Obstacles, string(criteriaJSON), uuidPtrValue(outcome.NextIntentID), now); err != nil {
+		return SessionOutcome{}, nil, err
+	}
+
+	return outcome, draft, nil
+}
+
+// checkGoalCriteria verifies that every cited criterion is one of the goal's
+// success criteria.
+func checkGoalCriteria(ctx context.Context, tx *sql.Tx, goalID uuid.NullUUID, cited []string) error {
+	if !goalID.Valid {
+		return ErrCriterionNotOnGoal
+	}
+
+	var rawJSON []byte
+	if err := tx.QueryRowContext(ctx, `SELECT success_criteria FROM goals WHERE id = $1`, goalID.UUID).Scan(&rawJSON); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCriterionNotOnGoal
+		}
+		return err
+	}
+
+	var criteria []string
+	if err := json.Unmarshal(rawJSON, &criteria); err != nil {
+		return err
+	}
+
+	known := make(map[string]struct{}, len(criteria))
+	for _, criterion := range criteria {
+		known[criterion] = struct{}{}
+	}
+
+	for _, criterion := range cited {
+		if _, ok := known[criterion]; !ok {
+			return ErrCriterionNotOnGoal
+		}
+	}
+
+	return nil
+}
+
+// ListSessionOutcomes returns the close-out entries recorded for a session.
+func ListSessionOutcomes(ctx context.Context, db *sql.DB, sessionID uuid.UUID) ([]SessionOutcome, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	const query = `
+SELECT ` + sessionOutcomeColumns + `
+FROM session_outcomes
+WHERE session_id = $1
+ORDER BY created_at, id
+`
+
+	rows, err := db.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	outcomes := make([]SessionOutcome, 0)
+	for rows.Next() {
+		outcome, err := scanSessionOutcome(rows)
+		if err != nil {
+			return nil, err
+		}
+		outcomes = append(outcomes, outcome)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return outcomes, nil
+}
+
+const sessionOutcomeColumns = "id, session_id, member_id, intent_id, outcome, obstacles, satisfied_criteria, next_intent_id, created_at"
+
+func scanSessionOutcome(row rowScanner) (SessionOutcome, error) {
+	var (
+		outcome      SessionOutcome
+		intentID     uuid.NullUUID
+		nextIntentID uuid.NullUUID
+		criteriaJSON []byte
+	)
+
+	if err := row.Scan(&outcome.ID, &outcome.SessionID, &outcome.MemberID, &intentID, &outcome.Outcome, &outcome.Obstacles, &criteriaJSON, &nextIntentID, &outcome.CreatedAt); err != nil {
+		return SessionOutcome{}, err
+	}
+
+	outcome.SatisfiedCriteria = []string{}
+	if len(criteriaJSON) > 0 {
+		if err := json.Unmarshal(criteriaJSON, &outcome.SatisfiedCriteria); err != nil {
+			return SessionOutcome{}, err
+		}
+	}
+
+	outcome.IntentID = nullUUIDPtr(intentID)
+	outcome.NextIntentID = nullUUIDPtr(nextIntentID)
+	return outcome, nil
+}
+
+// lockSessionForRitual takes an exclusive lock on the session row so that a
+// state transition waits for in-flight writes holding a share lock.
+func lockSessionForRitual(ctx context.Context, tx *sql.Tx, sessionID uuid.UUID) (Session, error) {
+	const query = `
+SELECT ` + sessionColumns + `
+FROM sessions
+WHERE id = $1
+FOR UPDATE
+`
+
+	return scanSession(tx.QueryRowContext(ctx, query, sessionID))
+}
+
+func nonNilUUIDs(ids []uuid.UUID) []uuid.UUID {
+	if ids == nil {
+		return []uuid.UUID{}
+	}
+	return ids
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,349 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+var outcomeIntentRowColumns = []string{"goal_id", "active", "session_id", "member_id"}
+
+func expectSessionRitualLock(mock sqlmock.Sqlmock, sessionID, chapterID uuid.UUID, startsAt time.Time, state string) {
+	rows := sqlmock.NewRows([]string{"id", "chapter_id", "starts_at", "ends_at", "state", "kicked_off_at", "closed_at", "created_at"}).
+		AddRow(sessionID, chapterID, startsAt, startsAt.Add(4*time.Hour), state, nil, nil, startsAt.Add(-24*time.Hour))
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM sessions WHERE id = $1 FOR UPDATE")).
+		WithArgs(sessionID).
+		WillReturnRows(rows)
+}
+
+func TestKickoffSessionSnapshotsSwarm(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, swarmID, memberID, intentID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	startsAt := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	expectSessionRitualLock(mock, sessionID, chapterID, startsAt, SessionScheduled)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM swarms WHERE id = $1 AND session_id = $2")).
+		WithArgs(swarmID, sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "session_id", "name", "mission", "status", "starts_at", "ends_at", "created_at", "updated_at"}).
+			AddRow(swarmID, sessionID, "Checkout", "", SwarmActive, startsAt, startsAt.Add(time.Hour), startsAt, startsAt))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT swarm_id, member_id FROM swarm_members")).
+		WillReturnRows(sqlmock.NewRows([]string{"swarm_id", "member_id"}).AddRow(swarmID, memberID))
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE intents SET status = 'active'")).
+		WithArgs("{"+intentID.String()+"}", sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags", "previous_status", "guardrails"}).
+			AddRow(intentID, "statement", "context", "outcome", `[]`, IntentActive, nil, nil, sessionID, startsAt, nil, nil, nil, nil, nil, IntentDraft, `["No prod deploys on Friday"]`))
+	expectIntentTransition(mock, intentID, IntentDraft, IntentActive)
+	expectWebhookEvent(mock, EventIntentUpdated)
+	mock.ExpectExec("INSERT INTO session_kickoffs").
+		WithArgs(sessionID, swarmID, `["`+intentID.String()+`"]`, `["Design review"]`, `["No prod deploys on Friday"]`, `["`+memberID.String()+`"]`, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE sessions SET state = $1, kicked_off_at = $2, updated_at = $2, sequence = sequence + 1 WHERE id = $3")).
+		WithArgs(SessionKickedOff, sqlmock.AnyArg(), sessionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	session, kickoffs, err := KickoffSession(context.Background(), db, sessionID, []SwarmKickoffInput{{
+		SwarmID:        swarmID,
+		IntentIDs:      []uuid.UUID{intentID},
+		Dependencies:   []string{"Design review"},
+		AcknowledgedBy: []uuid.UUID{memberID},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if session.State != SessionKickedOff || session.KickedOffAt == nil {
+		t.Fatalf("expected session to be kicked off got %+v", session)
+	}
+
+	if len(kickoffs) != 1 || len(kickoffs[0].Guardrails) != 1 {
+		t.Fatalf("expected guardrails to be snapshotted got %+v", kickoffs)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestKickoffSessionRejectsAcknowledgerOutsideSwarm(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, swarmID := uuid.New(), uuid.New(), uuid.New()
+	startsAt := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	expectSessionRitualLock(mock, sessionID, chapterID, startsAt, SessionScheduled)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM swarms WHERE id = $1 AND session_id = $2")).
+		WithArgs(swarmID, sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "session_id", "name", "mission", "status", "starts_at", "ends_at", "created_at", "updated_at"}).
+			AddRow(swarmID, sessionID, "Checkout", "", SwarmActive, startsAt, startsAt.Add(time.Hour), startsAt, startsAt))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT swarm_id, member_id FROM swarm_members")).
+		WillReturnRows(sqlmock.NewRows([]string{"swarm_id", "member_id"}).AddRow(swarmID, uuid.New()))
+	mock.ExpectRollback()
+
+	_, _, err = KickoffSession(context.Background(), db, sessionID, []SwarmKickoffInput{{
+		SwarmID:        swarmID,
+		AcknowledgedBy: []uuid.UUID{uuid.New()},
+	}})
+	if !errors.Is(err, ErrAcknowledgerNotInSwarm) {
+		t.Fatalf("expected ErrAcknowledgerNotInSwarm got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestKickoffSessionRejectsSecondKickoff(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID := uuid.New()
+
+	mock.ExpectBegin()
+	expectSessionRitualLock(mock, sessionID, uuid.New(), time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC), SessionKickedOff)
+	mock.ExpectRollback()
+
+	if _, _, err := KickoffSession(context.Background(), db, sessionID, nil); !errors.Is(err, ErrSessionAlreadyKickedOff) {
+		t.Fatalf("expected ErrSessionAlreadyKickedOff got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestCloseoutSessionCreatesDraftIntentInNextSession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, memberID, intentID, goalID, nextSessionID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	startsAt := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	expectSessionRitualLock(mock, sessionID, chapterID, startsAt, SessionKickedOff)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM sessions WHERE chapter_id = $1 AND starts_at > $2 ORDER BY starts_at LIMIT 1")).
+		WithArgs(chapterID, startsAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(nextSessionID))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT chapter_id FROM members WHERE id = $1")).
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"chapter_id"}).AddRow(chapterID))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT i.goal_id, g.status = 'active', i.session_id, i.member_id FROM intents i LEFT JOIN goals g ON g.id = i.goal_id WHERE i.id = $1")).
+		WithArgs(intentID).
+		WillReturnRows(sqlmock.NewRows(outcomeIntentRowColumns).AddRow(goalID, true, sessionID, memberID))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT success_criteria FROM goals WHERE id = $1")).
+		WithArgs(goalID).
+		WillReturnRows(sqlmock.NewRows([]string{"success_criteria"}).AddRow(`["Retries cover checkout","Error rate under 1%"]`))
+	mock.ExpectExec("INSERT INTO intents").
+		WithArgs(sqlmock.AnyArg(), "Finish the checkout retry", "Retries landed behind a flag", "Flag removed", "[]", IntentDraft, memberID, goalID, nextSessionID, sqlmock.AnyArg(), `{"blocks":2}`, "[]", nil, nil, "[]").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectIntentTransition(mock, sqlmock.AnyArg(), nil, IntentDraft)
+	expectWebhookEvent(mock, EventIntentCreated)
+	mock.ExpectExec("INSERT INTO session_outcomes").
+		WithArgs(sqlmock.AnyArg(), sessionID, memberID, intentID, "Retries shipped", "Flaky staging", `["Retries cover checkout"]`, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE sessions SET state = $1, closed_at = $2, updated_at = $2, sequence = sequence + 1 WHERE id = $3")).
+		WithArgs(SessionClosed, sqlmock.AnyArg(), sessionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("FROM retro_templates").
+		WithArgs(chapterID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "chapter_id", "name", "anonymous", "questions", "created_at", "updated_at"}))
+	mock.ExpectCommit()
+
+	closeout, err := CloseoutSession(context.Background(), db, sessionID, []SessionOutcomeInput{{
+		MemberID:          memberID,
+		IntentID:          &intentID,
+		Outcome:           "Retries shipped",
+		Obstacles:         "Flaky staging",
+		SatisfiedCriteria: []string{"Retries cover checkout"},
+		NextIntent: &IntentInput{
+			Statement:       "Finish the checkout retry",
+			Context:         "Retries landed behind a flag",
+			ExpectedOutcome: "Flag removed",
+			Timebox:         &Timebox{Blocks: 2},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if closeout.Session.State != SessionClosed {
+		t.Fatalf("expected session to be closed got %s", closeout.Session.State)
+	}
+
+	if len(closeout.DraftIntents) != 1 {
+		t.Fatalf("expected one draft intent got %d", len(closeout.DraftIntents))
+	}
+
+	draft := closeout.DraftIntents[0]
+	if draft.Status != IntentDraft || draft.SessionID == nil || *draft.SessionID != nextSessionID {
+		t.Fatalf("expected draft intent for next session got %+v", draft)
+	}
+
+	if closeout.Outcomes[0].NextIntentID == nil || *closeout.Outcomes[0].NextIntentID != draft.ID {
+		t.Fatalf("expected outcome to link the draft intent")
+	}
+
+	if closeout.RetroSurvey != nil {
+		t.Fatalf("expected no retro survey without a template got %+v", closeout.RetroSurvey)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestCloseoutSessionRequiresKickoff(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID := uuid.New()
+
+	mock.ExpectBegin()
+	expectSessionRitualLock(mock, sessionID, uuid.New(), time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC), SessionScheduled)
+	mock.ExpectRollback()
+
+	if _, err := CloseoutSession(context.Background(), db, sessionID, nil); !errors.Is(err, ErrSessionNotKickedOff) {
+		t.Fatalf("expected ErrSessionNotKickedOff got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestCreateCommitmentRejectsClosedSession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID := uuid.New(), uuid.New()
+	startsAt := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM sessions s JOIN chapters c ON c.id = s.chapter_id WHERE s.id = $1 FOR SHARE OF s")).
+		WithArgs(sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "chapter_id", "starts_at", "ends_at", "state", "created_at", "max_concurrent_swarms", "block_minutes"}).
+			AddRow(sessionID, chapterID, startsAt, startsAt.Add(4*time.Hour), SessionClosed, startsAt, 3, 60))
+	mock.ExpectRollback()
+
+	_, err = CreateCommitment(context.Background(), db, CommitmentInput{
+		SessionID: sessionID,
+		MemberID:  uuid.New(),
+		IntentID:  uuid.New(),
+		Blocks:    1,
+	})
+	if !errors.Is(err, ErrSessionClosed) {
+		t.Fatalf("expected ErrSessionClosed got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestCloseoutSessionRejectsCriteriaNotOnGoal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, memberID, intentID, goalID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	startsAt := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	expectSessionRitualLock(mock, sessionID, chapterID, startsAt, SessionKickedOff)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM sessions WHERE chapter_id = $1 AND starts_at > $2 ORDER BY starts_at LIMIT 1")).
+		WithArgs(chapterID, startsAt).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT chapter_id FROM members WHERE id = $1")).
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"chapter_id"}).AddRow(chapterID))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT i.goal_id, g.status = 'active', i.session_id, i.member_id FROM intents i LEFT JOIN goals g ON g.id = i.goal_id WHERE i.id = $1")).
+		WithArgs(intentID).
+		WillReturnRows(sqlmock.NewRows(outcomeIntentRowColumns).AddRow(goalID, true, sessionID, memberID))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT success_criteria FROM goals WHERE id = $1")).
+		WithArgs(goalID).
+		WillReturnRows(sqlmock.NewRows([]string{"success_criteria"}).AddRow(`["Error rate under 1%"]`))
+	mock.ExpectRollback()
+
+	_, err = CloseoutSession(context.Background(), db, sessionID, []SessionOutcomeInput{{
+		MemberID:          memberID,
+		IntentID:          &intentID,
+		Outcome:           "Retries shipped",
+		SatisfiedCriteria: []string{"Retries cover checkout"},
+	}})
+	if !errors.Is(err, ErrCriterionNotOnGoal) {
+		t.Fatalf("expected ErrCriterionNotOnGoal got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestCloseoutSessionRejectsIntentOfAnotherMember(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, memberID, intentID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	startsAt := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	expectSessionRitualLock(mock, sessionID, chapterID, startsAt, SessionKickedOff)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM sessions WHERE chapter_id = $1 AND starts_at > $2 ORDER BY starts_at LIMIT 1")).
+		WithArgs(chapterID, startsAt).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT chapter_id FROM members WHERE id = $1")).
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"chapter_id"}).AddRow(chapterID))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM intents i LEFT JOIN goals g ON g.id = i.goal_id WHERE i.id = $1")).
+		WithArgs(intentID).
+		WillReturnRows(sqlmock.NewRows(outcomeIntentRowColumns).AddRow(nil, nil, sessionID, uuid.New()))
+	mock.ExpectRollback()
+
+	_, err = CloseoutSession(context.Background(), db, sessionID, []SessionOutcomeInput{{
+		MemberID: memberID,
+		IntentID: &intentID,
+		Outcome:  "Shipped someone else's work",
+	}})
+	if !errors.Is(err, ErrIntentNotOwnedByMember) {
+		t.Fatalf("expected ErrIntentNotOwnedByMember got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session states. A session is kicked off at the start of its window and
+// closed at the close-out ritual, after which it no longer accepts writes.
+const (
+	SessionScheduled = "scheduled"
+	SessionKickedOff = "kicked_off"
+	SessionClosed    = "closed"
+)
+
+var (
+	// ErrSessionClosed is returned when writing to a session that has been
+	// closed out.
+	ErrSessionClosed = errors.New("session has been closed")
+	// ErrSessionAlreadyKickedOff is returned when kicking off a session twice.
+	ErrSessionAlreadyKickedOff = errors.New("session has already been kicked off")
+	// ErrSessionNotKickedOff is returned when closing out a session that was
+	// never kicked off.
+	ErrSessionNotKickedOff = errors.New("session has not been kicked off")
+)
+
+// sessionColumns lists the session columns in the order scanSession expects.
+const sessionColumns = "id, chapter_id, starts_at, ends_at, state, kicked_off_at, closed_at, created_at"
+
+// Session is a single Monday or Thursday chapter window for a chapter
+// instance.
+type Session struct {
+	ID          uuid.UUID
+	ChapterID   uuid.UUID
+	StartsAt    time.Time
+	EndsAt      time.Time
+	State       string
+	KickedOffAt *time.Time
+	ClosedAt    *time.Time
+	CreatedAt   time.Time
+}
+
+// SessionInput captures the fields required to schedule a session. Callers
+// are expected to have validated the range against the chapter windows.
+type SessionInput struct {
+	ChapterID uuid.UUID
+	StartsAt  time.Time
+	EndsAt    time.Time
+}
+
+// SessionFilters capture optional filtering criteria when listing sessions.
+type SessionFilters struct {
+	ChapterID    *uuid.UUID
+	StartsAfter  *time.Time
+	StartsBefore *time.Time
+}
+
+// CreateSession persists a new session.
+func CreateSession(ctx context.Context, db *sql.DB, input SessionInput) (Session, error) {
+	if db == nil {
+		return Session{}, errors.New("database handle is nil")
+	}
+
+	now := time.Now().UTC()
+	id := uuid.New()
+
+	const query = `
+INSERT INTO sessions (id, chapter_id, starts_at, ends_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $5)
+`
+
+	if _, err := db.ExecContext(ctx, query, id, input.ChapterID, input.StartsAt.UTC(), input.EndsAt.UTC(), now); err != nil {
+		return Session{}, err
+	}
+
+	return Session{
+		ID:        id,
+		ChapterID: input.ChapterID,
+		StartsAt:  input.StartsAt.UTC(),
+		EndsAt:    input.EndsAt.UTC(),
+		State:     SessionScheduled,
+		CreatedAt: now,
+	}, nil
+}
+
+// GetSession retrieves a session by identifier.
+func GetSession(ctx context.Context, db *sql.DB, id uuid.UUID) (Session, error) {
+	if db == nil {
+		return Session{}, errors.New("database handle is nil")
+	}
+
+	const query = `
+SELECT ` + sessionColumns + `
+FROM sessions
+WHERE id = $1
+`
+
+	return scanSession(db.QueryRowContext(ctx, query, id))
+}
+
+// ListSessions returns sessions ordered by start time applying optional
+// filters.
+func ListSessions(ctx context.Context, db *sql.DB, filters SessionFilters) ([]Session, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	var (
+		conditions []string
+		args       []any
+		param      = 1
+	)
+
+	if filters.ChapterID != nil {
+		conditions = append(conditions, fmt.Sprintf("chapter_id = $%d", param))
+		args = append(args, *filters.ChapterID)
+		param++
+	}
+
+	if filters.StartsAfter != nil {
+		conditions = append(conditions, fmt.Sprintf("starts_at >= $%d", param))
+		args = append(args, *filters.StartsAfter)
+		param++
+	}
+
+	if filters.StartsBefore != nil {
+		conditions = append(conditions, fmt.Sprintf("starts_at <= $%d", param))
+		args = append(args, *filters.StartsBefore)
+	}
+
+	query := "SELECT " + sessionColumns + " FROM sessions"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY starts_at"
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]Session, 0)
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func scanSession(row rowScanner) (Session, error) {
+	var (
+		session     Session
+		kickedOffAt sql.NullTime
+		closedAt    sql.NullTime
+	)
+
+	if err := row.Scan(&session.ID, &session.ChapterID, &session.StartsAt, &session.EndsAt, &session.State, &kickedOffAt, &closedAt, &session.CreatedAt); err != nil {
+		return Session{}, err
+	}
+
+	session.KickedOffAt = nullTimePtr(kickedOffAt)
+	session.ClosedAt = nullTimePtr(closedAt)
+	return session, nil
+}
+
+// lockOpenSession share-locks a session for a write that does not need its
+// capacity settings, returning ErrSessionClosed once it has been closed.
+func lockOpenSession(ctx context.Context, tx *sql.Tx, sessionID uuid.UUID) error {
+	var state string
+	if err := tx.QueryRowContext(ctx, `SELECT state FROM sessions WHERE id = $1 FOR SHARE`, sessionID).Scan(&state); err != nil {
+		return err
+	}
+
+	if state == SessionClosed {
+		return ErrSessionClosed
+	}
+
+	return nil
+}
@@ -0,0 +1,641 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/example/intent/backend/internal/schedule"
+	"github.com/example/intent/backend/internal/showcase"
+	"github.com/google/uuid"
+)
+
+// Showcase priorities, highest first. New requests join the queue behind
+// every request of the same or higher priority.
+const (
+	ShowcaseHigh   = "high"
+	ShowcaseNormal = "normal"
+	ShowcaseLow    = "low"
+)
+
+// ShowcaseHorizon is how many upcoming sessions the scheduler packs demos
+// into.
+const ShowcaseHorizon = 8
+
+var (
+	// ErrOutcomeNotFound is returned when a showcase request references a
+	// close-out outcome that does not exist.
+	ErrOutcomeNotFound = errors.New("outcome not found")
+	// ErrShowcaseOutsideChapter is returned when a request comes from a swarm
+	// or outcome of another chapter.
+	ErrShowcaseOutsideChapter = errors.New("showcase requests must come from a swarm or outcome of the chapter")
+	// ErrShowcaseNotScheduled is returned when locking a request that has no
+	// slot and none was given.
+	ErrShowcaseNotScheduled = errors.New("showcase request has no slot to lock")
+	// ErrShowcaseSlotUnavailable is returned when a locked slot falls outside
+	// the session's remaining window or overlaps another locked slot.
+	ErrShowcaseSlotUnavailable = errors.New("slot must fall in the session's remaining window before 17:00 and not overlap a locked slot")
+	// ErrShowcaseOrderMismatch is returned when a reorder does not list every
+	// queued request exactly once.
+	ErrShowcaseOrderMismatch = errors.New("order must list every queued showcase request exactly once")
+)
+
+// ShowcaseRequest is a demo waiting for, or holding, a slot in an upcoming
+// session.
+type ShowcaseRequest struct {
+	ID              uuid.UUID
+	ChapterID       uuid.UUID
+	Title           string
+	SwarmID         *uuid.UUID
+	OutcomeID       *uuid.UUID
+	DurationMinutes int
+	Priority        string
+	Position        int
+	Locked          bool
+	SessionID       *uuid.UUID
+	StartsAt        *time.Time
+	EndsAt          *time.Time
+	Sequence        int
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// ShowcaseRequestInput captures a new demo request. Exactly one of SwarmID
+// and OutcomeID is set.
+type ShowcaseRequestInput struct {
+	ChapterID       uuid.UUID
+	Title           string
+	SwarmID         *uuid.UUID
+	OutcomeID       *uuid.UUID
+	DurationMinutes int
+	Priority        string
+}
+
+const showcaseColumns = "id, chapter_id, title, swarm_id, outcome_id, duration_minutes, priority, position, locked, session_id, starts_at, ends_at, sequence, created_at, updated_at"
+
+// pendingShowcase matches requests whose slot has not ended yet, including
+// those still waiting for one.
+const pendingShowcase = "(ends_at IS NULL OR ends_at > $2)"
+
+// CreateShowcaseRequest queues a demo request and reschedules the chapter's
+// showcase.
+func CreateShowcaseRequest(ctx context.Context, db *sql.DB, input ShowcaseRequestInput) (ShowcaseRequest, error) {
+	if db == nil {
+		return ShowcaseRequest{}, errors.New("database handle is nil")
+	}
+
+	id := uuid.New()
+	err := withShowcaseQueue(ctx, db, input.ChapterID, func(tx *sql.Tx, now time.Time) error {
+		if err := checkShowcaseRequester(ctx, tx, input); err != nil {
+			return err
+		}
+
+		position, err := showcaseInsertPosition(ctx, tx, input.ChapterID, input.Priority, now)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE showcase_requests SET position = position + 1 WHERE chapter_id = $1 AND position >= $2`, input.ChapterID, position); err != nil {
+			return err
+		}
+
+		const query = `
+INSERT INTO showcase_requests (id, chapter_id, title, swarm_id, outcome_id, duration_minutes, priority, position, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+`
+
+		_, err = tx.ExecContext(ctx, query, id, input.ChapterID, input.Title, uuidPtrValue(input.SwarmID), uuidPtrValue(input.OutcomeID), input.DurationMinutes, input.Priority, position, now, now)
+		return err
+	})
+	if err != nil {
+		return ShowcaseRequest{}, err
+	}
+
+	return getShowcaseRequest(ctx, db, input.ChapterID, id)
+}
+
+// GetShowcaseRequest retrieves a chapter's showcase request.
+func GetShowcaseRequest(ctx context.Context, db *sql.DB, chapterID, id uuid.UUID) (ShowcaseRequest, error) {
+	if db == nil {
+		return ShowcaseRequest{}, errors.New("database handle is nil")
+	}
+
+	return getShowcaseRequest(ctx, db, chapterID, id)
+}
+
+func getShowcaseRequest(ctx context.Context, q queryer, chapterID, id uuid.UUID) (ShowcaseRequest, error) {
+	const query = `SELECT ` + showcaseColumns + ` FROM showcase_requests WHERE id = $1 AND chapter_id = $2`
+
+	return scanShowcaseRequest(q.QueryRowContext(ctx, query, id, chapterID))
+}
+
+// ListShowcaseRequests returns the chapter's showcase schedule: requests
+// whose slot ends after since, in slot order, followed by those still waiting
+// for a slot in queue order.
+func ListShowcaseRequests(ctx context.Context, db *sql.DB, chapterID uuid.UUID, since time.Time) ([]ShowcaseRequest, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	const query = `
+SELECT ` + showcaseColumns + `
+FROM showcase_requests
+WHERE chapter_id = $1 AND ` + pendingShowcase + `
+ORDER BY starts_at NULLS LAST, position
+`
+
+	rows, err := db.QueryContext(ctx, query, chapterID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := make([]ShowcaseRequest, 0)
+	for rows.Next() {
+		request, err := scanShowcaseRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+// DeleteShowcaseRequest withdraws a demo request and reschedules the rest of
+// the queue.
+func DeleteShowcaseRequest(ctx context.Context, db *sql.DB, chapterID, id uuid.UUID) error {
+	if db == nil {
+		return errors.New("database handle is nil")
+	}
+
+	return withShowcaseQueue(ctx, db, chapterID, func(tx *sql.Tx, now time.Time) error {
+		result, err := tx.ExecContext(ctx, `DELETE FROM showcase_requests WHERE id = $1 AND chapter_id = $2`, id, chapterID)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected == 0 {
+			return sql.ErrNoRows
+		}
+
+		return nil
+	})
+}
+
+// ReorderShowcase sets the queue order of the chapter's pending requests,
+// overriding priority, and reschedules. ids must list every pending request
+// exactly once.
+func ReorderShowcase(ctx context.Context, db *sql.DB, chapterID uuid.UUID, ids []uuid.UUID) error {
+	if db == nil {
+		return errors.New("database handle is nil")
+	}
+
+	return withShowcaseQueue(ctx, db, chapterID, func(tx *sql.Tx, now time.Time) error {
+		rows, err := tx.QueryContext(ctx, `SELECT id FROM showcase_requests WHERE chapter_id = $1 AND `+pendingShowcase, chapterID, now)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		pending := make(map[uuid.UUID]struct{})
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			pending[id] = struct{}{}
+		}
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(ids) != len(pending) {
+			return ErrShowcaseOrderMismatch
+		}
+		for _, id := range ids {
+			if _, ok := pending[id]; !ok {
+				return ErrShowcaseOrderMismatch
+			}
+			delete(pending, id)
+		}
+
+		for position, id := range ids {
+			if _, err := tx.ExecContext(ctx, `UPDATE showcase_requests SET position = $1 WHERE id = $2`, position, id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// LockShowcaseSlot pins a request to a slot the scheduler will not move.
+// Without a session and start time the request's current slot is locked.
+func LockShowcaseSlot(ctx context.Context, db *sql.DB, chapterID, id uuid.UUID, sessionID *uuid.UUID, startsAt *time.Time) (ShowcaseRequest, error) {
+	if db == nil {
+		return ShowcaseRequest{}, errors.New("database handle is nil")
+	}
+
+	err := withShowcaseQueue(ctx, db, chapterID, func(tx *sql.Tx, now time.Time) error {
+		request, err := getShowcaseRequest(ctx, tx, chapterID, id)
+		if err != nil {
+			return err
+		}
+
+		if sessionID == nil || startsAt == nil {
+			if request.SessionID == nil || request.StartsAt == nil {
+				return ErrShowcaseNotScheduled
+			}
+			sessionID, startsAt = request.SessionID, request.StartsAt
+		}
+
+		slot := showcase.Slot{
+			SessionID: *sessionID,
+			Start:     startsAt.UTC(),
+			End:       startsAt.UTC().Add(time.Duration(request.DurationMinutes) * time.Minute),
+		}
+
+		if err := checkShowcaseLock(ctx, tx, chapterID, id, slot, now); err != nil {
+			return err
+		}
+
+		const query = `
+UPDATE showcase_requests
+SET locked = TRUE, session_id = $1, starts_at = $2, ends_at = $3, sequence = sequence + 1, updated_at = $4
+WHERE id = $5
+`
+
+		_, err = tx.ExecContext(ctx, query, slot.SessionID, slot.Start, slot.End, now, id)
+		return err
+	})
+	if err != nil {
+		return ShowcaseRequest{}, err
+	}
+
+	return getShowcaseRequest(ctx, db, chapterID, id)
+}
+
+// UnlockShowcaseSlot hands a request back to the scheduler.
+func UnlockShowcaseSlot(ctx context.Context, db *sql.DB, chapterID, id uuid.UUID) (ShowcaseRequest, error) {
+	if db == nil {
+		return ShowcaseRequest{}, errors.New("database handle is nil")
+	}
+
+	err := withShowcaseQueue(ctx, db, chapterID, func(tx *sql.Tx, now time.Time) error {
+		result, err := tx.ExecContext(ctx, `UPDATE showcase_requests SET locked = FALSE, updated_at = $1 WHERE id = $2 AND chapter_id = $3`, now, id, chapterID)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected == 0 {
+			return sql.ErrNoRows
+		}
+
+		return nil
+	})
+	if err != nil {
+		return ShowcaseRequest{}, err
+	}
+
+	return getShowcaseRequest(ctx, db, chapterID, id)
+}
+
+// RescheduleShowcase repacks the chapter's queue, picking up sessions
+// scheduled since the last change.
+func RescheduleShowcase(ctx context.Context, db *sql.DB, chapterID uuid.UUID) error {
+	if db == nil {
+		return errors.New("database handle is nil")
+	}
+
+	return withShowcaseQueue(ctx, db, chapterID, func(*sql.Tx, time.Time) error { return nil })
+}
+
+// withShowcaseQueue applies change to the chapter's queue with the chapter
+// locked and reschedules in the same transaction.
+func withShowcaseQueue(ctx context.Context, db *sql.DB, chapterID uuid.UUID, change func(*sql.Tx, time.Time) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	loc, err := lockShowcaseChapter(ctx, tx, chapterID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if err := change(tx, now); err != nil {
+		return err
+	}
+
+	if err := rescheduleShowcase(ctx, tx, chapterID, loc, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// lockShowcaseChapter serialises changes to a chapter's showcase queue and
+// returns the chapter's timezone.
+func lockShowcaseChapter(ctx context.Context, tx *sql.Tx, chapterID uuid.UUID) (*time.Location, error) {
+	var timezone string
+	if err := tx.QueryRowContext(ctx, `SELECT timezone FROM chapters WHERE id = $1 FOR UPDATE`, chapterID).Scan(&timezone); err != nil {
+		return nil, err
+	}
+
+	return schedule.LoadLocation(timezone)
+}
+
+func checkShowcaseRequester(ctx context.Context, tx *sql.Tx, input ShowcaseRequestInput) error {
+	var chapterID uuid.UUID
+	if input.SwarmID != nil {
+		err := tx.QueryRowContext(ctx, `SELECT s.chapter_id FROM swarms w JOIN sessions s ON s.id = w.session_id WHERE w.id = $1`, *input.SwarmID).Scan(&chapterID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSwarmNotFound
+		}
+		if err != nil {
+			return err
+		}
+	} else {
+		err := tx.QueryRowContext(ctx, `SELECT s.chapter_id FROM session_outcomes o JOIN sessions s ON s.id = o.session_id WHERE o.id = $1`, uuidPtrValue(input.OutcomeID)).Scan(&chapterID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrOutcomeNotFound
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if chapterID != input.ChapterID {
+		return ErrShowcaseOutsideChapter
+	}
+
+	return nil
+}
+
+// showcaseInsertPosition returns the queue position behind every pending
+// request of the same or higher priority.
+func showcaseInsertPosition(ctx context.Context, tx *sql.Tx, chapterID uuid.UUID, priority string, now time.Time) (int, error) {
+	const query = `
+SELECT COALESCE(MAX(position) + 1, 0)
+FROM showcase_requests
+WHERE chapter_id = $1 AND ` + pendingShowcase + ` AND ` + showcasePriorityRank + ` <= $3
+`
+
+	var position int
+	if err := tx.QueryRowContext(ctx, query, chapterID, now, showcaseRank(priority)).Scan(&position); err != nil {
+		return 0, err
+	}
+
+	return position, nil
+}
+
+const showcasePriorityRank = "CASE priority WHEN 'high' THEN 0 WHEN 'normal' THEN 1 ELSE 2 END"
+
+func showcaseRank(priority string) int {
+	switch priority {
+	case ShowcaseHigh:
+		return 0
+	case ShowcaseNormal:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// checkShowcaseLock verifies a slot lies in the remaining window of an open
+// session of the chapter and does not overlap another locked slot.
+func checkShowcaseLock(ctx context.Context, tx *sql.Tx, chapterID, requestID uuid.UUID, slot showcase.Slot, now time.Time) error {
+	var (
+		sessionChapter uuid.UUID
+		state          string
+		startsAt       time.Time
+		endsAt         time.Time
+		timezone       string
+	)
+
+	const query = `
+SELECT s.chapter_id, s.state, s.starts_at, s.ends_at, c.timezone
+FROM sessions s
+JOIN chapters c ON c.id = s.chapter_id
+WHERE s.id = $1
+`
+
+	err := tx.QueryRowContext(ctx, query, slot.SessionID).Scan(&sessionChapter, &state, &startsAt, &endsAt, &timezone)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && sessionChapter != chapterID) {
+		return ErrShowcaseSlotUnavailable
+	}
+	if err != nil {
+		return err
+	}
+
+	if state == SessionClosed {
+		return ErrSessionClosed
+	}
+
+	loc, err := schedule.LoadLocation(timezone)
+	if err != nil {
+		return err
+	}
+
+	window, ok := showcase.SessionWindow(slot.SessionID, startsAt, endsAt, now, loc)
+	if !ok || !showcase.Fits(slot, []showcase.Window{window}) {
+		return ErrShowcaseSlotUnavailable
+	}
+
+	var overlapping int
+	const overlapQuery = `
+SELECT COUNT(*)
+FROM showcase_requests
+WHERE session_id = $1 AND locked AND id <> $2 AND starts_at < $4 AND ends_at > $3
+`
+	if err := tx.QueryRowContext(ctx, overlapQuery, slot.SessionID, requestID, slot.Start, slot.End).Scan(&overlapping); err != nil {
+		return err
+	}
+
+	if overlapping > 0 {
+		return ErrShowcaseSlotUnavailable
+	}
+
+	return nil
+}
+
+// rescheduleShowcase repacks the chapter's pending requests into the
+// remaining windows of its upcoming open sessions. Locked slots and slots
+// already under way stay where they are; every other request is moved to
+// the slot the packer assigns it, or unscheduled if nothing fits. Requests
+// whose slot changes get their sequence bumped for calendar clients.
+func rescheduleShowcase(ctx context.Context, tx *sql.Tx, chapterID uuid.UUID, loc *time.Location, now time.Time) error {
+	const sessionsQuery = `
+SELECT id, starts_at, ends_at
+FROM sessions
+WHERE chapter_id = $1 AND ends_at > $2 AND state <> 'closed'
+ORDER BY starts_at
+LIMIT $3
+`
+
+	rows, err := tx.QueryContext(ctx, sessionsQuery, chapterID, now, ShowcaseHorizon)
+	if err != nil {
+		return err
+	}
+
+	windows := make([]showcase.Window, 0)
+	for rows.Next() {
+		var (
+			sessionID uuid.UUID
+			startsAt  time.Time
+			endsAt    time.Time
+		)
+		if err := rows.Scan(&sessionID, &startsAt, &endsAt); err != nil {
+			rows.Close()
+			return err
+		}
+		if window, ok := showcase.SessionWindow(sessionID, startsAt, endsAt, now, loc); ok {
+			windows = append(windows, window)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	const requestsQuery = `
+SELECT id, duration_minutes, locked, session_id, starts_at, ends_at
+FROM showcase_requests
+WHERE chapter_id = $1 AND ` + pendingShowcase + `
+ORDER BY position, created_at
+`
+
+	rows, err = tx.QueryContext(ctx, requestsQuery, chapterID, now)
+	if err != nil {
+		return err
+	}
+
+	var (
+		requests []showcase.Request
+		current  = make(map[uuid.UUID]*showcase.Slot)
+	)
+	for rows.Next() {
+		var (
+			id        uuid.UUID
+			minutes   int
+			locked    bool
+			sessionID uuid.NullUUID
+			startsAt  sql.NullTime
+			endsAt    sql.NullTime
+		)
+		if err := rows.Scan(&id, &minutes, &locked, &sessionID, &startsAt, &endsAt); err != nil {
+			rows.Close()
+			return err
+		}
+
+		request := showcase.Request{ID: id, Duration: time.Duration(minutes) * time.Minute}
+		if sessionID.Valid && startsAt.Valid && endsAt.Valid {
+			slot := &showcase.Slot{SessionID: sessionID.UUID, Start: startsAt.Time, End: endsAt.Time}
+			current[id] = slot
+			if locked || !slot.Start.After(now) {
+				request.Fixed = slot
+			}
+		}
+		requests = append(requests, request)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	packed := showcase.Pack(windows, requests)
+
+	for _, request := range requests {
+		if request.Fixed != nil {
+			continue
+		}
+
+		next, scheduled := packed[request.ID]
+		previous := current[request.ID]
+		if scheduled && previous != nil && sameSlot(*previous, next) {
+			continue
+		}
+		if !scheduled && previous == nil {
+			continue
+		}
+
+		var sessionID, startsAt, endsAt any
+		if scheduled {
+			sessionID, startsAt, endsAt = next.SessionID, next.Start, next.End
+		}
+
+		const update = `
+UPDATE showcase_requests
+SET session_id = $1, starts_at = $2, ends_at = $3, sequence = sequence + 1, updated_at = $4
+WHERE id = $5
+`
+
+		if _, err := tx.ExecContext(ctx, update, sessionID, startsAt, endsAt, now, request.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sameSlot(a, b showcase.Slot) bool {
+	return a.SessionID == b.SessionID && a.Start.Equal(b.Start) && a.End.Equal(b.End)
+}
+
+func scanShowcaseRequest(row rowScanner) (ShowcaseRequest, error) {
+	var (
+		request   ShowcaseRequest
+		swarmID   uuid.NullUUID
+		outcomeID uuid.NullUUID
+		sessionID uuid.NullUUID
+		startsAt  sql.NullTime
+		endsAt    sql.NullTime
+	)
+
+	err := row.Scan(
+		&request.ID,
+		&request.ChapterID,
+		&request.Title,
+		&swarmID,
+		&outcomeID,
+		&request.DurationMinutes,
+		&request.Priority,
+		&request.Position,
+		&request.Locked,
+		&sessionID,
+		&startsAt,
+		&endsAt,
+		&request.Sequence,
+		&request.CreatedAt,
+		&request.UpdatedAt,
+	)
+	if err != nil {
+		return ShowcaseRequest{}, err
+	}
+
+	request.SwarmID = nullUUIDPtr(swarmID)
+	request.OutcomeID = nullUUIDPtr(outcomeID)
+	request.SessionID = nullUUIDPtr(sessionID)
+	request.StartsAt = nullTimePtr(startsAt)
+	request.EndsAt = nullTimePtr(endsAt)
+	return request, nil
+}
@@ -0,0 +1,154 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+var showcaseRequestColumns = []string{"id", "chapter_id", "title", "swarm_id", "outcome_id", "duration_minutes", "priority", "position", "locked", "session_id", "starts_at", "ends_at", "sequence", "created_at", "updated_at"}
+
+// upcomingMonday returns 13:00 UTC on a Monday at least a week away so the
+// session is always ahead of the scheduler's clock.
+func upcomingMonday() time.Time {
+	day := time.Now().UTC().AddDate(0, 0, 7)
+	for day.Weekday() != time.Monday {
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), 13, 0, 0, 0, time.UTC)
+}
+
+func expectShowcaseChapterLock(mock sqlmock.Sqlmock, chapterID uuid.UUID) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT timezone FROM chapters WHERE id = $1 FOR UPDATE")).
+		WithArgs(chapterID).
+		WillReturnRows(sqlmock.NewRows([]string{"timezone"}).AddRow("UTC"))
+}
+
+func TestCreateShowcaseRequestPacksAfterLockedSlot(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	chapterID, swarmID, sessionID, lockedID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	startsAt := upcomingMonday()
+	lockedStart := startsAt.Add(3*time.Hour + 30*time.Minute)
+
+	mock.ExpectBegin()
+	expectShowcaseChapterLock(mock, chapterID)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM swarms w JOIN sessions s")).
+		WithArgs(swarmID).
+		WillReturnRows(sqlmock.NewRows([]string{"chapter_id"}).AddRow(chapterID))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(MAX(position) + 1, 0)")).
+		WithArgs(chapterID, sqlmock.AnyArg(), 0).
+		WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(1))
+	mock.ExpectExec(regexp.QuoteMeta("SET position = position + 1")).
+		WithArgs(chapterID, 1).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO showcase_requests").
+		WithArgs(sqlmock.AnyArg(), chapterID, "Retry dashboard", swarmID, nil, 30, ShowcaseHigh, 1, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM sessions\nWHERE chapter_id = $1 AND ends_at > $2")).
+		WithArgs(chapterID, sqlmock.AnyArg(), ShowcaseHorizon).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "starts_at", "ends_at"}).AddRow(sessionID, startsAt, startsAt.Add(4*time.Hour)))
+
+	// The locked demo holds 16:30-17:00, and the new request is packed at
+	// the start of the session. The mock stands in for the generated id.
+	newID := uuid.New()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, duration_minutes, locked, session_id, starts_at, ends_at")).
+		WithArgs(chapterID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "duration_minutes", "locked", "session_id", "starts_at", "ends_at"}).
+			AddRow(lockedID, 30, true, sessionID, lockedStart, lockedStart.Add(30*time.Minute)).
+			AddRow(newID, 30, false, nil, nil, nil))
+	mock.ExpectExec(regexp.QuoteMeta("SET session_id = $1, starts_at = $2, ends_at = $3, sequence = sequence + 1")).
+		WithArgs(sessionID, startsAt, startsAt.Add(30*time.Minute), sqlmock.AnyArg(), newID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM showcase_requests WHERE id = $1 AND chapter_id = $2")).
+		WillReturnRows(sqlmock.NewRows(showcaseRequestColumns).
+			AddRow(newID, chapterID, "Retry dashboard", swarmID, nil, 30, ShowcaseHigh, 1, false, sessionID, startsAt, startsAt.Add(30*time.Minute), 1, startsAt, startsAt))
+
+	request, err := CreateShowcaseRequest(context.Background(), db, ShowcaseRequestInput{
+		ChapterID:       chapterID,
+		Title:           "Retry dashboard",
+		SwarmID:         &swarmID,
+		DurationMinutes: 30,
+		Priority:        ShowcaseHigh,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if request.SessionID == nil || *request.SessionID != sessionID || !request.StartsAt.Equal(startsAt) {
+		t.Fatalf("expected request to be scheduled at the session start got %+v", request)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestReorderShowcaseRequiresEveryPendingRequest(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	chapterID, first, second := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	expectShowcaseChapterLock(mock, chapterID)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM showcase_requests WHERE chapter_id = $1")).
+		WithArgs(chapterID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(first).AddRow(second))
+	mock.ExpectRollback()
+
+	err = ReorderShowcase(context.Background(), db, chapterID, []uuid.UUID{second})
+	if !errors.Is(err, ErrShowcaseOrderMismatch) {
+		t.Fatalf("expected ErrShowcaseOrderMismatch got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestLockShowcaseSlotRejectsSlotPastFive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	chapterID, requestID, sessionID := uuid.New(), uuid.New(), uuid.New()
+	startsAt := upcomingMonday()
+	lateStart := startsAt.Add(3*time.Hour + 45*time.Minute)
+
+	mock.ExpectBegin()
+	expectShowcaseChapterLock(mock, chapterID)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM showcase_requests WHERE id = $1 AND chapter_id = $2")).
+		WithArgs(requestID, chapterID).
+		WillReturnRows(sqlmock.NewRows(showcaseRequestColumns).
+			AddRow(requestID, chapterID, "Retry dashboard", uuid.New(), nil, 30, ShowcaseNormal, 0, false, nil, nil, nil, 0, startsAt, startsAt))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT s.chapter_id, s.state, s.starts_at, s.ends_at, c.timezone")).
+		WithArgs(sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"chapter_id", "state", "starts_at", "ends_at", "timezone"}).
+			AddRow(chapterID, SessionScheduled, startsAt, startsAt.Add(4*time.Hour), "UTC"))
+	mock.ExpectRollback()
+
+	_, err = LockShowcaseSlot(context.Background(), db, chapterID, requestID, &sessionID, &lateStart)
+	if !errors.Is(err, ErrShowcaseSlotUnavailable) {
+		t.Fatalf("expected ErrShowcaseSlotUnavailable got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job kinds that keep a swarm's chat channel in step with the swarm. They are
+// enqueued in the transaction that forms, joins or dissolves the swarm, and
+// their payload is a SwarmChannelPayload.
+const (
+	SwarmChannelProvisionJob = "chat.provision"
+	SwarmChannelInviteJob    = "chat.invite"
+	SwarmChannelArchiveJob   = "chat.archive"
+)
+
+// Swarm channel statuses. A channel is failed once provisioning has used up
+// its attempts.
+const (
+	ChannelProvisioning = "provisioning"
+	ChannelActive       = "active"
+	ChannelArchived     = "archived"
+	ChannelFailed       = "failed"
+)
+
+// SwarmChannelPayload identifies the swarm, and for invites the member, of a
+// swarm channel job.
+type SwarmChannelPayload struct {
+	SessionID uuid.UUID  `json:"sessionId"`
+	SwarmID   uuid.UUID  `json:"swarmId"`
+	MemberID  *uuid.UUID `json:"memberId,omitempty"`
+}
+
+// SwarmChannel is the chat channel provisioned for a swarm. ChannelID is
+// empty until the channel has been created and Pinned records whether the
+// charter was pinned, so a retried job skips the steps already done.
+type SwarmChannel struct {
+	SwarmID   uuid.UUID
+	Provider  string
+	ChannelID string
+	Name      string
+	URL       string
+	Status    string
+	Pinned    bool
+	LastError string
+	Attempts  int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+const swarmChannelColumns = `swarm_id, provider, channel_id, name, url, status, pinned, last_error, attempts, created_at, updated_at`
+
+// GetSwarmChannel retrieves the chat channel of a swarm.
+func GetSwarmChannel(ctx context.Context, db *sql.DB, swarmID uuid.UUID) (SwarmChannel, error) {
+	if db == nil {
+		return SwarmChannel{}, errors.New("database handle is nil")
+	}
+
+	query := `SELECT ` + swarmChannelColumns + ` FROM swarm_channels WHERE swarm_id = $1`
+
+	return scanSwarmChannel(db.QueryRowContext(ctx, query, swarmID))
+}
+
+// ListSwarmChannels returns the chat channels of the given swarms keyed by
+// swarm id. Swarms without a channel are absent.
+func ListSwarmChannels(ctx context.Context, db *sql.DB, swarmIDs []uuid.UUID) (map[uuid.UUID]SwarmChannel, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	channels := make(map[uuid.UUID]SwarmChannel, len(swarmIDs))
+	if len(swarmIDs) == 0 {
+		return channels, nil
+	}
+
+	query := `SELECT ` + swarmChannelColumns + ` FROM swarm_channels WHERE swarm_id = ANY($1::uuid[])`
+
+	rows, err := db.QueryContext(ctx, query, uuidArrayLiteral(swarmIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		channel, err := scanSwarmChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+		channels[channel.SwarmID] = channel
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return channels, nil
+}
+
+// SaveSwarmChannel records the state of a swarm's chat channel, creating the
+// row on the first provisioning attempt.
+func SaveSwarmChannel(ctx context.Context, db *sql.DB, channel SwarmChannel) error {
+	if db == nil {
+		return errors.New("database handle is nil")
+	}
+
+	const query = `
+INSERT INTO swarm_channels (swarm_id, provider, channel_id, name, url, status, pinned, last_error, attempts, created_at, updated_at)
+VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9, $10, $10)
+ON CONFLICT (swarm_id) DO UPDATE
+SET provider = EXCLUDED.provider,
+    channel_id = EXCLUDED.channel_id,
+    name = EXCLUDED.name,
+    url = EXCLUDED.url,
+    status = EXCLUDED.status,
+    pinned = EXCLUDED.pinned,
+    last_error = EXCLUDED.last_error,
+    attempts = EXCLUDED.attempts,
+    updated_at = EXCLUDED.updated_at
+`
+
+	_, err := db.ExecContext(ctx, query, channel.SwarmID, channel.Provider, channel.ChannelID, channel.Name, channel.URL, channel.Status, channel.Pinned, channel.LastError, channel.Attempts, time.Now().UTC())
+	return err
+}
+
+// enqueueSwarmChannelJob schedules a chat channel job for swarm inside tx.
+// Invites are keyed by member so each member is invited once.
+func enqueueSwarmChannelJob(ctx context.Context, tx *sql.Tx, kind string, swarm Swarm, memberID *uuid.UUID) error {
+	key := swarm.ID.String()
+	if memberID != nil {
+		key += ":" + memberID.String()
+	}
+
+	_, err := enqueueJob(ctx, tx, JobInput{
+		Kind:    kind,
+		Key:     key,
+		Payload: SwarmChannelPayload{SessionID: swarm.SessionID, SwarmID: swarm.ID, MemberID: memberID},
+	}, time.Now().UTC())
+	return err
+}
+
+func scanSwarmChannel(row rowScanner) (SwarmChannel, error) {
+	var (
+		channel   SwarmChannel
+		channelID sql.NullString
+	)
+
+	if err := row.Scan(&channel.SwarmID, &channel.Provider, &channelID, &channel.Name, &channel.URL, &channel.Status, &channel.Pinned, &channel.LastError, &channel.Attempts, &channel.CreatedAt, &channel.UpdatedAt); err != nil {
+		return SwarmChannel{}, err
+	}
+
+	channel.ChannelID = channelID.String
+
+	return channel, nil
+}
@@ -0,0 +1,440 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/example/intent/backend/internal/schedule"
+)
+
+// Swarm statuses.
+const (
+	SwarmActive    = "active"
+	SwarmDissolved = "dissolved"
+)
+
+var (
+	// ErrSwarmOutsideSession is returned when a swarm's timebox does not fit
+	// inside its session window.
+	ErrSwarmOutsideSession = errors.New("swarm timebox must fit inside the session window")
+	// ErrSwarmDissolved is returned when modifying a dissolved swarm.
+	ErrSwarmDissolved = errors.New("swarm has been dissolved")
+)
+
+// Swarm is a group of members working together for part of a session.
+type Swarm struct {
+	ID        uuid.UUID
+	SessionID uuid.UUID
+	Name      string
+	Mission   string
+	Status    string
+	StartsAt  time.Time
+	EndsAt    time.Time
+	MemberIDs []uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// SwarmInput captures the fields required to form a swarm. StartsAt defaults
+// to the start of the session and the swarm lasts Blocks capacity blocks.
+type SwarmInput struct {
+	SessionID uuid.UUID
+	Name      string
+	Mission   string
+	StartsAt  *time.Time
+	Blocks    int
+	MemberIDs []uuid.UUID
+}
+
+// CreateSwarm forms a swarm in a session and commits each member to it. The
+// chapter's concurrent swarm limit and every member's available blocks are
+// checked inside the same transaction; a violation returns *CapacityError.
+// Provisioning the swarm's chat channel is queued, never waited for.
+func CreateSwarm(ctx context.Context, db *sql.DB, input SwarmInput) (Swarm, error) {
+	if db == nil {
+		return Swarm{}, errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Swarm{}, err
+	}
+	defer tx.Rollback()
+
+	capacity, err := loadSessionCapacity(ctx, tx, input.SessionID, true)
+	if err != nil {
+		return Swarm{}, err
+	}
+
+	// Serialise swarm formation per chapter so the concurrency cap holds.
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM chapters WHERE id = $1 FOR UPDATE`, capacity.Session.ChapterID); err != nil {
+		return Swarm{}, err
+	}
+
+	var active int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM swarms WHERE session_id = $1 AND status = 'active'`, input.SessionID).Scan(&active); err != nil {
+		return Swarm{}, err
+	}
+
+	if active >= capacity.MaxConcurrentSwarms {
+		return Swarm{}, &CapacityError{
+			Cap:       CapChapterConcurrentSwarms,
+			Limit:     capacity.MaxConcurrentSwarms,
+			Current:   active,
+			Requested: 1,
+			SessionID: input.SessionID,
+			ChapterID: capacity.Session.ChapterID,
+		}
+	}
+
+	startsAt := capacity.Session.StartsAt
+	if input.StartsAt != nil {
+		startsAt = input.StartsAt.UTC()
+	}
+	endsAt := startsAt.Add(time.Duration(input.Blocks) * capacity.BlockLength)
+
+	if input.Blocks < 1 || !schedule.Within(startsAt, endsAt, capacity.Session.StartsAt, capacity.Session.EndsAt) {
+		return Swarm{}, ErrSwarmOutsideSession
+	}
+
+	now := time.Now().UTC()
+	swarm := Swarm{
+		ID:        uuid.New(),
+		SessionID: input.SessionID,
+		Name:      input.Name,
+		Mission:   input.Mission,
+		Status:    SwarmActive,
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		MemberIDs: make([]uuid.UUID, 0, len(input.MemberIDs)),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	const query = `
+INSERT INTO swarms (id, session_id, name, mission, status, starts_at, ends_at, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+`
+
+	if _, err := tx.ExecContext(ctx, query, swarm.ID, swarm.SessionID, swarm.Name, swarm.Mission, swarm.Status, swarm.StartsAt, swarm.EndsAt, now, now); err != nil {
+		return Swarm{}, err
+	}
+
+	for _, memberID := range input.MemberIDs {
+		if err := joinSwarm(ctx, tx, capacity, swarm, memberID); err != nil {
+			return Swarm{}, err
+		}
+		swarm.MemberIDs = append(swarm.MemberIDs, memberID)
+	}
+
+	if err := recordWebhookEvent(ctx, tx, EventSwarmCreated, swarmSnapshot(swarm)); err != nil {
+		return Swarm{}, err
+	}
+
+	if err := enqueueSwarmChannelJob(ctx, tx, SwarmChannelProvisionJob, swarm, nil); err != nil {
+		return Swarm{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Swarm{}, err
+	}
+
+	return swarm, nil
+}
+
+// AddSwarmMember commits a member to an existing active swarm, returning a
+// *CapacityError when they lack available blocks.
+func AddSwarmMember(ctx context.Context, db *sql.DB, sessionID, swarmID, memberID uuid.UUID) (Swarm, error) {
+	if db == nil {
+		return Swarm{}, errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Swarm{}, err
+	}
+	defer tx.Rollback()
+
+	capacity, err := loadSessionCapacity(ctx, tx, sessionID, true)
+	if err != nil {
+		return Swarm{}, err
+	}
+
+	swarm, err := getSwarm(ctx, tx, sessionID, swarmID, true)
+	if err != nil {
+		return Swarm{}, err
+	}
+
+	if swarm.Status != SwarmActive {
+		return Swarm{}, ErrSwarmDissolved
+	}
+
+	for _, existing := range swarm.MemberIDs {
+		if existing == memberID {
+			return swarm, tx.Commit()
+		}
+	}
+
+	if err := joinSwarm(ctx, tx, capacity, swarm, memberID); err != nil {
+		return Swarm{}, err
+	}
+	swarm.MemberIDs = append(swarm.MemberIDs, memberID)
+
+	now := time.Now().UTC()
+	if _, err := tx.ExecContext(ctx, `UPDATE swarms SET sequence = sequence + 1, updated_at = $1 WHERE id = $2`, now, swarmID); err != nil {
+		return Swarm{}, err
+	}
+	swarm.UpdatedAt = now
+
+	if err := recordWebhookEvent(ctx, tx, EventSwarmMemberAdded, swarmSnapshot(swarm)); err != nil {
+		return Swarm{}, err
+	}
+
+	if err := enqueueSwarmChannelJob(ctx, tx, SwarmChannelInviteJob, swarm, &memberID); err != nil {
+		return Swarm{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Swarm{}, err
+	}
+
+	return swarm, nil
+}
+
+func joinSwarm(ctx context.Context, tx *sql.Tx, capacity sessionCapacity, swarm Swarm, memberID uuid.UUID) error {
+	if err := lockMember(ctx, tx, memberID, capacity.Session.ChapterID); err != nil {
+		return err
+	}
+
+	swarmID := swarm.ID
+	blocks := schedule.Blocks(swarm.StartsAt, swarm.EndsAt, capacity.BlockLength)
+	if _, err := commitMember(ctx, tx, capacity, memberID, nil, &swarmID, blocks); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO swarm_members (swarm_id, member_id, joined_at) VALUES ($1, $2, $3)`, swarm.ID, memberID, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DissolveSwarm marks a swarm as dissolved and releases the blocks its
+// members had committed to it.
+func DissolveSwarm(ctx context.Context, db *sql.DB, sessionID, swarmID uuid.UUID) (Swarm, error) {
+	if db == nil {
+		return Swarm{}, errors.New("database handle is nil")
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Swarm{}, err
+	}
+	defer tx.Rollback()
+
+	if err := lockOpenSession(ctx, tx, sessionID); err != nil {
+		return Swarm{}, err
+	}
+
+	swarm, err := getSwarm(ctx, tx, sessionID, swarmID, true)
+	if err != nil {
+		return Swarm{}, err
+	}
+
+	if swarm.Status == SwarmDissolved {
+		return swarm, tx.Commit()
+	}
+
+	now := time.Now().UTC()
+	if _, err := tx.ExecContext(ctx, `UPDATE swarms SET status = $1, sequence = sequence + 1, updated_at = $2 WHERE id = $3`, SwarmDissolved, now, swarmID); err != nil {
+		return Swarm{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM commitments WHERE swarm_id = $1`, swarmID); err != nil {
+		return Swarm{}, err
+	}
+
+	swarm.Status = SwarmDissolved
+	swarm.UpdatedAt = now
+
+	if err := recordWebhookEvent(ctx, tx, EventSwarmDissolved, swarmSnapshot(swarm)); err != nil {
+		return Swarm{}, err
+	}
+
+	if err := enqueueSwarmChannelJob(ctx, tx, SwarmChannelArchiveJob, swarm, nil); err != nil {
+		return Swarm{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Swarm{}, err
+	}
+
+	return swarm, nil
+}
+
+// GetSwarm retrieves a swarm within a session, including its member ids.
+func GetSwarm(ctx context.Context, db *sql.DB, sessionID, swarmID uuid.UUID) (Swarm, error) {
+	if db == nil {
+		return Swarm{}, errors.New("database handle is nil")
+	}
+
+	return getSwarm(ctx, db, sessionID, swarmID, false)
+}
+
+func getSwarm(ctx context.Context, q queryer, sessionID, swarmID uuid.UUID, forUpdate bool) (Swarm, error) {
+	query := `
+SELECT id, session_id, name, mission, status, starts_at, ends_at, created_at, updated_at
+FROM swarms
+WHERE id = $1 AND session_id = $2
+`
+	if forUpdate {
+		query += "FOR UPDATE\n"
+	}
+
+	var swarm Swarm
+	err := q.QueryRowContext(ctx, query, swarmID, sessionID).Scan(
+		&swarm.ID,
+		&swarm.SessionID,
+		&swarm.Name,
+		&swarm.Mission,
+		&swarm.Status,
+		&swarm.StartsAt,
+		&swarm.EndsAt,
+		&swarm.CreatedAt,
+		&swarm.UpdatedAt,
+	)
+	if err != nil {
+		return Swarm{}, err
+	}
+
+	members, err := swarmMemberIDs(ctx, q, []uuid.UUID{swarm.ID})
+	if err != nil {
+		return Swarm{}, err
+	}
+	swarm.MemberIDs = members[swarm.ID]
+	if swarm.MemberIDs == nil {
+		swarm.MemberIDs = []uuid.UUID{}
+	}
+
+	return swarm, nil
+}
+
+// ListSwarms returns the swarms formed in a session, including dissolved
+// ones, ordered by start time.
+func ListSwarms(ctx context.Context, db *sql.DB, sessionID uuid.UUID) ([]Swarm, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	const query = `
+SELECT id, session_id, name, mission, status, starts_at, ends_at, created_at, updated_at
+FROM swarms
+WHERE session_id = $1
+ORDER BY starts_at, created_at
+`
+
+	rows, err := db.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	swarms := make([]Swarm, 0)
+	ids := make([]uuid.UUID, 0)
+	for rows.Next() {
+		var swarm Swarm
+		if err := rows.Scan(
+			&swarm.ID,
+			&swarm.SessionID,
+			&swarm.Name,
+			&swarm.Mission,
+			&swarm.Status,
+			&swarm.StartsAt,
+			&swarm.EndsAt,
+			&swarm.CreatedAt,
+			&swarm.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		swarms = append(swarms, swarm)
+		ids = append(ids, swarm.ID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return swarms, nil
+	}
+
+	members, err := swarmMemberIDs(ctx, db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range swarms {
+		swarms[i].MemberIDs = members[swarms[i].ID]
+		if swarms[i].MemberIDs == nil {
+			swarms[i].MemberIDs = []uuid.UUID{}
+		}
+	}
+
+	return swarms, nil
+}
+
+func swarmMemberIDs(ctx context.Context, q queryer, swarmIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	rows, err := q.QueryContext(ctx, `SELECT swarm_id, member_id FROM swarm_members WHERE swarm_id = ANY($1::uuid[]) ORDER BY joined_at`, uuidArrayLiteral(swarmIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := make(map[uuid.UUID][]uuid.UUID)
+	for rows.Next() {
+		var swarmID, memberID uuid.UUID
+		if err := rows.Scan(&swarmID, &memberID); err != nil {
+			return nil, err
+		}
+		members[swarmID] = append(members[swarmID], memberID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// storedSwarmSnapshot is the JSON shape used when freezing a swarm.
+type storedSwarmSnapshot struct {
+	ID        uuid.UUID   `json:"id"`
+	SessionID uuid.UUID   `json:"sessionId"`
+	Name      string      `json:"name"`
+	Mission   string      `json:"mission"`
+	Status    string      `json:"status"`
+	StartsAt  time.Time   `json:"startsAt"`
+	EndsAt    time.Time   `json:"endsAt"`
+	MemberIDs []uuid.UUID `json:"memberIds"`
+	CreatedAt time.Time   `json:"createdAt"`
+	UpdatedAt time.Time   `json:"updatedAt"`
+}
+
+func swarmSnapshot(swarm Swarm) storedSwarmSnapshot {
+	return storedSwarmSnapshot{
+		ID:        swarm.ID,
+		SessionID: swarm.SessionID,
+		Name:      swarm.Name,
+		Mission:   swarm.Mission,
+		Status:    swarm.Status,
+		StartsAt:  swarm.StartsAt,
+		EndsAt:    swarm.EndsAt,
+		MemberIDs: swarm.MemberIDs,
+		CreatedAt: swarm.CreatedAt,
+		UpdatedAt: swarm.UpdatedAt,
+	}
+}
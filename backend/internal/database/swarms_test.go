@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestCreateSwarmSuccess(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, memberID := uuid.New(), uuid.New(), uuid.New()
+	startsAt := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	expectSessionCapacity(mock, sessionID, chapterID, startsAt, 2)
+	mock.ExpectExec(regexp.QuoteMeta("SELECT id FROM chapters WHERE id = $1 FOR UPDATE")).
+		WithArgs(chapterID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM swarms WHERE session_id = $1 AND status = 'active'")).
+		WithArgs(sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec("INSERT INTO swarms").
+		WithArgs(sqlmock.AnyArg(), sessionID, "Checkout fixes", "Unblock release", SwarmActive, startsAt, startsAt.Add(2*time.Hour), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectMemberLock(mock, memberID, chapterID)
+	expectMemberBlocks(mock, sessionID, memberID, AvailabilityAvailable, 0)
+	mock.ExpectExec("INSERT INTO commitments").
+		WithArgs(sqlmock.AnyArg(), sessionID, memberID, nil, sqlmock.AnyArg(), 2, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO swarm_members").
+		WithArgs(sqlmock.AnyArg(), memberID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectWebhookEvent(mock, EventSwarmCreated)
+	mock.ExpectExec("INSERT INTO jobs").
+		WithArgs(sqlmock.AnyArg(), SwarmChannelProvisionJob, sqlmock.AnyArg(), sqlmock.AnyArg(), DefaultJobAttempts, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	swarm, err := CreateSwarm(context.Background(), db, SwarmInput{
+		SessionID: sessionID,
+		Name:      "Checkout fixes",
+		Mission:   "Unblock release",
+		Blocks:    2,
+		MemberIDs: []uuid.UUID{memberID},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !swarm.EndsAt.Equal(startsAt.Add(2 * time.Hour)) {
+		t.Fatalf("expected swarm to end at %s got %s", startsAt.Add(2*time.Hour), swarm.EndsAt)
+	}
+
+	if len(swarm.MemberIDs) != 1 || swarm.MemberIDs[0] != memberID {
+		t.Fatalf("unexpected members %v", swarm.MemberIDs)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestCreateSwarmExceedsConcurrentLimit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	expectSessionCapacity(mock, sessionID, chapterID, time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC), 2)
+	mock.ExpectExec(regexp.QuoteMeta("SELECT id FROM chapters WHERE id = $1 FOR UPDATE")).
+		WithArgs(chapterID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM swarms WHERE session_id = $1 AND status = 'active'")).
+		WithArgs(sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectRollback()
+
+	_, err = CreateSwarm(context.Background(), db, SwarmInput{SessionID: sessionID, Name: "Extra", Blocks: 1})
+
+	var capErr *CapacityError
+	if !errors.As(err, &capErr) {
+		t.Fatalf("expected capacity error got %v", err)
+	}
+
+	if capErr.Cap != CapChapterConcurrentSwarms || capErr.Limit != 2 || capErr.Current != 2 {
+		t.Fatalf("unexpected capacity error %+v", capErr)
+	}
+
+	if capErr.ChapterID != chapterID {
+		t.Fatalf("expected chapter %s got %s", chapterID, capErr.ChapterID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestCreateSwarmOutsideSession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID := uuid.New(), uuid.New()
+	startsAt := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+	late := startsAt.Add(3 * time.Hour)
+
+	mock.ExpectBegin()
+	expectSessionCapacity(mock, sessionID, chapterID, startsAt, 3)
+	mock.ExpectExec(regexp.QuoteMeta("SELECT id FROM chapters WHERE id = $1 FOR UPDATE")).
+		WithArgs(chapterID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM swarms WHERE session_id = $1 AND status = 'active'")).
+		WithArgs(sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectRollback()
+
+	_, err = CreateSwarm(context.Background(), db, SwarmInput{SessionID: sessionID, Name: "Late", StartsAt: &late, Blocks: 2})
+	if !errors.Is(err, ErrSwarmOutsideSession) {
+		t.Fatalf("expected ErrSwarmOutsideSession got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// TagMatch selects whether a tag filter keeps rows carrying any or all of the
+// requested tags.
+type TagMatch string
+
+const (
+	TagMatchAny TagMatch = "any"
+	TagMatchAll TagMatch = "all"
+)
+
+// Facet names the dimensions facet counts are grouped by.
+const (
+	FacetTag    = "tag"
+	FacetStatus = "status"
+	FacetGoal   = "goal"
+)
+
+// FacetCount is the number of rows in a list result sharing one facet value.
+type FacetCount struct {
+	Value string
+	Count int
+}
+
+// Facets holds the counts of a filtered list result per tag, status and, for
+// intents, goal. The counts cover every matching row, not just the current
+// page. Intents without a goal are counted under an empty goal value.
+type Facets struct {
+	Tags     []FacetCount
+	Statuses []FacetCount
+	Goals    []FacetCount
+}
+
+// tagCondition returns the predicate keeping rows whose tags column matches
+// the text array bound to placeholder param.
+func tagCondition(match TagMatch, param int) string {
+	if match == TagMatchAll {
+		return fmt.Sprintf("tags ?& $%d::text[]", param)
+	}
+	return fmt.Sprintf("tags ?| $%d::text[]", param)
+}
+
+// facetColumn pairs a facet name with the column it groups by.
+type facetColumn struct {
+	facet  string
+	column string
+}
+
+// facetQuery counts the rows of table matching whereClause per value of each
+// column and per tag, labelled with the facet name. Values are cast to text
+// so that the parts of the union line up.
+func facetQuery(table, whereClause string, columns ...facetColumn) string {
+	parts := make([]string, 0, len(columns)+1)
+	for _, c := range columns {
+		parts = append(parts, fmt.Sprintf("SELECT '%s', COALESCE(%s::text, ''), COUNT(*) FROM %s%s GROUP BY %s", c.facet, c.column, table, whereClause, c.column))
+	}
+	parts = append(parts, fmt.Sprintf("SELECT '%s', t.tag, COUNT(*) FROM %s CROSS JOIN LATERAL jsonb_array_elements_text(%s.tags) AS t(tag)%s GROUP BY t.tag", FacetTag, table, table, whereClause))
+
+	return strings.Join(parts, " UNION ALL ") + " ORDER BY 1, 3 DESC, 2"
+}
+
+// listFacets runs a query built by facetQuery and groups its counts.
+func listFacets(ctx context.Context, db *sql.DB, query string, args []any) (Facets, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return Facets{}, err
+	}
+	defer rows.Close()
+
+	facets := Facets{Tags: []FacetCount{}, Statuses: []FacetCount{}, Goals: []FacetCount{}}
+	for rows.Next() {
+		var (
+			facet string
+			count FacetCount
+		)
+		if err := rows.Scan(&facet, &count.Value, &count.Count); err != nil {
+			return Facets{}, err
+		}
+
+		switch facet {
+		case FacetTag:
+			facets.Tags = append(facets.Tags, count)
+		case FacetStatus:
+			facets.Statuses = append(facets.Statuses, count)
+		case FacetGoal:
+			facets.Goals = append(facets.Goals, count)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return Facets{}, err
+	}
+
+	return facets, nil
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestListIntentsFiltersByAllTagsWithFacets(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	goalID, id := uuid.New(), uuid.New()
+	filters := IntentFilters{GoalID: &goalID, Tags: []string{"reliability", "checkout"}, TagMatch: TagMatchAll}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM intents WHERE goal_id = $1 AND tags ?& $2::text[]")).
+		WithArgs(goalID, "{\"reliability\",\"checkout\"}").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM intents WHERE goal_id = $1 AND tags ?& $2::text[] ORDER BY created_at DESC")).
+		WithArgs(goalID, "{\"reliability\",\"checkout\"}").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags"}).
+			AddRow(id, "statement", "context", "outcome", `[]`, IntentActive, nil, goalID, nil, time.Now().UTC(), nil, nil, nil, nil, `["reliability","checkout"]`))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT 'goal', COALESCE(goal_id::text, ''), COUNT(*) FROM intents WHERE goal_id = $1 AND tags ?& $2::text[] GROUP BY goal_id UNION ALL SELECT 'tag', t.tag, COUNT(*) FROM intents CROSS JOIN LATERAL jsonb_array_elements_text(intents.tags) AS t(tag) WHERE goal_id = $1")).
+		WithArgs(goalID, "{\"reliability\",\"checkout\"}").
+		WillReturnRows(sqlmock.NewRows([]string{"facet", "value", "count"}).
+			AddRow(FacetGoal, goalID.String(), 1).
+			AddRow(FacetStatus, IntentActive, 1).
+			AddRow(FacetTag, "checkout", 1).
+			AddRow(FacetTag, "reliability", 1))
+
+	result, err := ListIntents(context.Background(), db, filters, Pagination{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Intents) != 1 || len(result.Intents[0].Tags) != 2 {
+		t.Fatalf("unexpected intents %+v", result.Intents)
+	}
+
+	facets := result.Facets
+	if len(facets.Tags) != 2 || facets.Tags[0] != (FacetCount{Value: "checkout", Count: 1}) {
+		t.Fatalf("unexpected tag facets %+v", facets.Tags)
+	}
+	if len(facets.Statuses) != 1 || len(facets.Goals) != 1 || facets.Goals[0].Value != goalID.String() {
+		t.Fatalf("unexpected facets %+v", facets)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestListGoalsFiltersByAnyTag(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM goals WHERE tags ?| $1::text[] AND status <> 'archived'")).
+		WithArgs("{\"onboarding\"}").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goals WHERE tags ?| $1::text[] AND status <> 'archived' ORDER BY created_at DESC")).
+		WithArgs("{\"onboarding\"}").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goals WHERE tags ?| $1::text[] AND status <> 'archived' GROUP BY status UNION ALL")).
+		WithArgs("{\"onboarding\"}").
+		WillReturnRows(sqlmock.NewRows([]string{"facet", "value", "count"}))
+
+	result, err := ListGoals(context.Background(), db, GoalFilters{Tags: []string{"onboarding"}}, Pagination{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Facets.Tags == nil || len(result.Facets.Statuses) != 0 {
+		t.Fatalf("expected empty facets got %+v", result.Facets)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
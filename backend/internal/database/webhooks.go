@@ -0,0 +1,426 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook event types. Subscribers pick which of them they receive.
+const (
+	EventIntentCreated    = "intent.created"
+	EventIntentUpdated    = "intent.updated"
+	EventIntentDeleted    = "intent.deleted"
+	EventGoalCreated      = "goal.created"
+	EventGoalUpdated      = "goal.updated"
+	EventGoalDeleted      = "goal.deleted"
+	EventSwarmCreated     = "swarm.created"
+	EventSwarmMemberAdded = "swarm.member_added"
+	EventSwarmDissolved   = "swarm.dissolved"
+)
+
+// WebhookEventTypes lists every event type a subscription can filter on.
+var WebhookEventTypes = []string{
+	EventIntentCreated,
+	EventIntentUpdated,
+	EventIntentDeleted,
+	EventGoalCreated,
+	EventGoalUpdated,
+	EventGoalDeleted,
+	EventSwarmCreated,
+	EventSwarmMemberAdded,
+	EventSwarmDissolved,
+}
+
+// WebhookDeliveryJob is the job kind that delivers one event to one
+// subscription. Its payload is a WebhookDeliveryPayload.
+const WebhookDeliveryJob = "webhook.deliver"
+
+// WebhookDeliveryAttempts is how many times a webhook delivery is tried
+// before it is dead-lettered; with the queue's backoff this spans about an
+// hour.
+const WebhookDeliveryAttempts = 8
+
+// WebhookSubscription is an external endpoint that receives events. An empty
+// EventTypes subscribes to every event.
+type WebhookSubscription struct {
+	ID          uuid.UUID
+	URL         string
+	Secret      string
+	EventTypes  []string
+	Description string
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// WebhookSubscriptionInput captures the fields required to create or update
+// a subscription. An empty Secret on update keeps the current one.
+type WebhookSubscriptionInput struct {
+	URL         string
+	Secret      string
+	EventTypes  []string
+	Description string
+	Active      bool
+}
+
+// WebhookEvent is an outbox entry describing a change.
+type WebhookEvent struct {
+	ID        uuid.UUID
+	Type      string
+	Payload   json.RawMessage
+	CreatedAt time.Time
+}
+
+// WebhookDelivery is one attempt at delivering an event to a subscription.
+// StatusCode is nil when no response was received.
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	SubscriptionID uuid.UUID
+	EventID        uuid.UUID
+	EventType      string
+	Attempt        int
+	StatusCode     *int
+	Error          string
+	Duration       time.Duration
+	CreatedAt      time.Time
+}
+
+// Succeeded reports whether the endpoint accepted the delivery.
+func (d WebhookDelivery) Succeeded() bool {
+	return d.Error == "" && d.StatusCode != nil && *d.StatusCode >= 200 && *d.StatusCode <= 299
+}
+
+// WebhookDeliveryPayload is the payload of a webhook.deliver job.
+type WebhookDeliveryPayload struct {
+	EventID        uuid.UUID `json:"eventId"`
+	SubscriptionID uuid.UUID `json:"subscriptionId"`
+}
+
+const webhookSubscriptionColumns = `id, url, secret, event_types, description, active, created_at, updated_at`
+
+// CreateWebhookSubscription persists a new subscription.
+func CreateWebhookSubscription(ctx context.Context, db *sql.DB, input WebhookSubscriptionInput) (WebhookSubscription, error) {
+	if db == nil {
+		return WebhookSubscription{}, errors.New("database handle is nil")
+	}
+
+	eventTypes := input.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	eventTypesJSON, err := json.Marshal(eventTypes)
+	if err != nil {
+		return WebhookSubscription{}, err
+	}
+
+	now := time.Now().UTC()
+	subscription := WebhookSubscription{
+		ID:          uuid.New(),
+		URL:         input.URL,
+		Secret:      input.Secret,
+		EventTypes:  eventTypes,
+		Description: input.Description,
+		Active:      input.Active,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	const query = `
+INSERT INTO webhook_subscriptions (id, url, secret, event_types, description, active, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+`
+
+	if _, err := db.ExecContext(ctx, query, subscription.ID, subscription.URL, subscription.Secret, string(eventTypesJSON), subscription.Description, subscription.Active, now); err != nil {
+		return WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+// GetWebhookSubscription retrieves a subscription by identifier.
+func GetWebhookSubscription(ctx context.Context, db *sql.DB, id uuid.UUID) (WebhookSubscription, error) {
+	if db == nil {
+		return WebhookSubscription{}, errors.New("database handle is nil")
+	}
+
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1`
+
+	return scanWebhookSubscription(db.QueryRowContext(ctx, query, id))
+}
+
+// ListWebhookSubscriptions returns every subscription, oldest first.
+func ListWebhookSubscriptions(ctx context.Context, db *sql.DB) ([]WebhookSubscription, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions ORDER BY created_at, id`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := make([]WebhookSubscription, 0)
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+// UpdateWebhookSubscription replaces a subscription's settings.
+func UpdateWebhookSubscription(ctx context.Context, db *sql.DB, id uuid.UUID, input WebhookSubscriptionInput) (WebhookSubscription, error) {
+	if db == nil {
+		return WebhookSubscription{}, errors.New("database handle is nil")
+	}
+
+	eventTypes := input.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	eventTypesJSON, err := json.Marshal(eventTypes)
+	if err != nil {
+		return WebhookSubscription{}, err
+	}
+
+	const query = `
+UPDATE webhook_subscriptions
+SET url = $1,
+    secret = COALESCE(NULLIF($2, ''), secret),
+    event_types = $3,
+    description = $4,
+    active = $5,
+    updated_at = $6
+WHERE id = $7
+RETURNING ` + webhookSubscriptionColumns
+
+	return scanWebhookSubscription(db.QueryRowContext(ctx, query, input.URL, input.Secret, string(eventTypesJSON), input.Description, input.Active, time.Now().UTC(), id))
+}
+
+// DeleteWebhookSubscription removes a subscription and its delivery log.
+// Deliveries still queued for it are dropped when they run.
+func DeleteWebhookSubscription(ctx context.Context, db *sql.DB, id uuid.UUID) error {
+	if db == nil {
+		return errors.New("database handle is nil")
+	}
+
+	result, err := db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetWebhookEvent retrieves an outbox event by identifier.
+func GetWebhookEvent(ctx context.Context, db *sql.DB, id uuid.UUID) (WebhookEvent, error) {
+	if db == nil {
+		return WebhookEvent{}, errors.New("database handle is nil")
+	}
+
+	var event WebhookEvent
+	err := db.QueryRowContext(ctx, `SELECT id, type, payload, created_at FROM webhook_events WHERE id = $1`, id).Scan(&event.ID, &event.Type, &event.Payload, &event.CreatedAt)
+	if err != nil {
+		return WebhookEvent{}, err
+	}
+
+	return event, nil
+}
+
+// deletedResource is the payload of the *.deleted events.
+type deletedResource struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// recordWebhookEvent writes an event to the outbox inside the caller's
+// transaction and queues its delivery to every active subscription that
+// wants it. data is the event payload and is encoded as JSON.
+func recordWebhookEvent(ctx context.Context, q queryer, eventType string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	const query = `
+WITH event AS (
+    INSERT INTO webhook_events (id, type, payload, created_at)
+    VALUES ($1, $2, $3, $4)
+)
+INSERT INTO jobs (id, kind, key, payload, max_attempts, run_at, created_at, updated_at)
+SELECT gen_random_uuid(), $5, $1::text || ':' || s.id::text,
+       jsonb_build_object('eventId', $1::text, 'subscriptionId', s.id::text),
+       $6, $4, $4, $4
+FROM webhook_subscriptions s
+WHERE s.active
+  AND (jsonb_array_length(s.event_types) = 0 OR s.event_types @> jsonb_build_array($2::text))
+`
+
+	_, err = q.ExecContext(ctx, query, uuid.New(), eventType, payload, time.Now().UTC(), WebhookDeliveryJob, WebhookDeliveryAttempts)
+	return err
+}
+
+// RecordWebhookDelivery appends an attempt to the delivery log.
+func RecordWebhookDelivery(ctx context.Context, db *sql.DB, delivery WebhookDelivery) (WebhookDelivery, error) {
+	if db == nil {
+		return WebhookDelivery{}, errors.New("database handle is nil")
+	}
+
+	delivery.ID = uuid.New()
+	delivery.CreatedAt = time.Now().UTC()
+
+	var statusCode sql.NullInt64
+	if delivery.StatusCode != nil {
+		statusCode = sql.NullInt64{Int64: int64(*delivery.StatusCode), Valid: true}
+	}
+
+	const query = `
+INSERT INTO webhook_deliveries (id, subscription_id, event_id, attempt, status_code, error, duration_ms, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+	if _, err := db.ExecContext(ctx, query, delivery.ID, delivery.SubscriptionID, delivery.EventID, delivery.Attempt, statusCode, delivery.Error, delivery.Duration.Milliseconds(), delivery.CreatedAt); err != nil {
+		return WebhookDelivery{}, err
+	}
+
+	return delivery, nil
+}
+
+const webhookDeliveryColumns = `d.id, d.subscription_id, d.event_id, e.type, d.attempt, d.status_code, d.error, d.duration_ms, d.created_at`
+
+// ListWebhookDeliveries returns the subscription's delivery log, newest
+// first.
+func ListWebhookDeliveries(ctx context.Context, db *sql.DB, subscriptionID uuid.UUID, limit int) ([]WebhookDelivery, error) {
+	if db == nil {
+		return nil, errors.New("database handle is nil")
+	}
+
+	query := `
+SELECT ` + webhookDeliveryColumns + `
+FROM webhook_deliveries d
+JOIN webhook_events e ON e.id = d.event_id
+WHERE d.subscription_id = $1
+ORDER BY d.created_at DESC, d.id
+LIMIT $2
+`
+
+	rows, err := db.QueryContext(ctx, query, subscriptionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]WebhookDelivery, 0)
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// RedeliverWebhook queues the event of a logged delivery for another run
+// against its subscription, whatever the outcome of earlier attempts.
+// sql.ErrNoRows is returned when the subscription has no such delivery.
+func RedeliverWebhook(ctx context.Context, db *sql.DB, subscriptionID, deliveryID uuid.UUID) (WebhookDelivery, error) {
+	if db == nil {
+		return WebhookDelivery{}, errors.New("database handle is nil")
+	}
+
+	query := `
+SELECT ` + webhookDeliveryColumns + `
+FROM webhook_deliveries d
+JOIN webhook_events e ON e.id = d.event_id
+WHERE d.id = $1 AND d.subscription_id = $2
+`
+
+	delivery, err := scanWebhookDelivery(db.QueryRowContext(ctx, query, deliveryID, subscriptionID))
+	if err != nil {
+		return WebhookDelivery{}, err
+	}
+
+	_, err = EnqueueJob(ctx, db, JobInput{
+		Kind:        WebhookDeliveryJob,
+		Key:         fmt.Sprintf("%s:%s:%s", delivery.EventID, delivery.SubscriptionID, uuid.New()),
+		Payload:     WebhookDeliveryPayload{EventID: delivery.EventID, SubscriptionID: delivery.SubscriptionID},
+		MaxAttempts: WebhookDeliveryAttempts,
+	})
+	if err != nil {
+		return WebhookDelivery{}, err
+	}
+
+	return delivery, nil
+}
+
+func scanWebhookSubscription(row rowScanner) (WebhookSubscription, error) {
+	var (
+		subscription WebhookSubscription
+		rawTypes     []byte
+	)
+
+	if err := row.Scan(&subscription.ID, &subscription.URL, &subscription.Secret, &rawTypes, &subscription.Description, &subscription.Active, &subscription.CreatedAt, &subscription.UpdatedAt); err != nil {
+		return WebhookSubscription{}, err
+	}
+
+	subscription.EventTypes = []string{}
+	if len(rawTypes) > 0 {
+		if err := json.Unmarshal(rawTypes, &subscription.EventTypes); err != nil {
+			return WebhookSubscription{}, err
+		}
+	}
+
+	return subscription, nil
+}
+
+func scanWebhookDelivery(row rowScanner) (WebhookDelivery, error) {
+	var (
+		delivery   WebhookDelivery
+		statusCode sql.NullInt64
+		durationMS int64
+	)
+
+	if err := row.Scan(&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &delivery.Attempt, &statusCode, &delivery.Error, &durationMS, &delivery.CreatedAt); err != nil {
+		return WebhookDelivery{}, err
+	}
+
+	if statusCode.Valid {
+		code := int(statusCode.Int64)
+		delivery.StatusCode = &code
+	}
+	delivery.Duration = time.Duration(durationMS) * time.Millisecond
+
+	return delivery, nil
+}
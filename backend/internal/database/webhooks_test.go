@@ -0,0 +1,170 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+// expectWebhookEvent expects an event of the given type to be written to the
+// outbox and fanned out to subscribers.
+func expectWebhookEvent(mock sqlmock.Sqlmock, eventType string) {
+	mock.ExpectExec("INSERT INTO webhook_events").
+		WithArgs(sqlmock.AnyArg(), eventType, sqlmock.AnyArg(), sqlmock.AnyArg(), WebhookDeliveryJob, WebhookDeliveryAttempts).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+}
+
+func TestCreateIntentRecordsEventInSameTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	input := IntentInput{Statement: "Ship retries", Collaborators: []string{"Ana"}}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO intents").
+		WithArgs(sqlmock.AnyArg(), input.Statement, "", "", `["Ana"]`, IntentActive, nil, nil, nil, sqlmock.AnyArg(), nil, "[]", nil, nil, "[]").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectIntentTransition(mock, sqlmock.AnyArg(), nil, IntentActive)
+	mock.ExpectExec("INSERT INTO webhook_events").
+		WithArgs(sqlmock.AnyArg(), EventIntentCreated, sqlmock.AnyArg(), sqlmock.AnyArg(), WebhookDeliveryJob, WebhookDeliveryAttempts).
+		WillReturnError(errors.New("outbox unavailable"))
+	mock.ExpectRollback()
+
+	if _, err := CreateIntent(context.Background(), db, input); err == nil {
+		t.Fatal("expected the outbox failure to abort the intent")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestUpdateWebhookSubscriptionKeepsSecretWhenBlank(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id := uuid.New()
+	now := time.Now().UTC()
+	input := WebhookSubscriptionInput{URL: "https://hooks.example.com/intent", EventTypes: []string{EventGoalDeleted}, Active: true}
+
+	mock.ExpectQuery("UPDATE webhook_subscriptions SET url = \\$1, secret = COALESCE\\(NULLIF\\(\\$2, ''\\), secret\\)").
+		WithArgs(input.URL, "", `["goal.deleted"]`, "", true, sqlmock.AnyArg(), id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url", "secret", "event_types", "description", "active", "created_at", "updated_at"}).
+			AddRow(id, input.URL, "s3cret", `["goal.deleted"]`, "", true, now, now))
+
+	subscription, err := UpdateWebhookSubscription(context.Background(), db, id, input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if subscription.Secret != "s3cret" || len(subscription.EventTypes) != 1 || subscription.EventTypes[0] != EventGoalDeleted {
+		t.Fatalf("unexpected subscription %+v", subscription)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestRecordWebhookDeliveryStoresMissingStatusAsNull(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	delivery := WebhookDelivery{
+		SubscriptionID: uuid.New(),
+		EventID:        uuid.New(),
+		Attempt:        2,
+		Error:          "connection refused",
+		Duration:       1500 * time.Millisecond,
+	}
+
+	mock.ExpectExec("INSERT INTO webhook_deliveries").
+		WithArgs(sqlmock.AnyArg(), delivery.SubscriptionID, delivery.EventID, 2, sql.NullInt64{}, "connection refused", int64(1500), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	recorded, err := RecordWebhookDelivery(context.Background(), db, delivery)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if recorded.ID == uuid.Nil || recorded.Succeeded() {
+		t.Fatalf("unexpected delivery %+v", recorded)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestRedeliverWebhookQueuesFreshJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	subscriptionID := uuid.New()
+	deliveryID := uuid.New()
+	eventID := uuid.New()
+
+	mock.ExpectQuery("SELECT d.id, d.subscription_id, d.event_id, e.type").
+		WithArgs(deliveryID, subscriptionID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "subscription_id", "event_id", "type", "attempt", "status_code", "error", "duration_ms", "created_at"}).
+			AddRow(deliveryID, subscriptionID, eventID, EventIntentCreated, 8, 500, "", 120, time.Now().UTC()))
+
+	payload, err := json.Marshal(WebhookDeliveryPayload{EventID: eventID, SubscriptionID: subscriptionID})
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+
+	mock.ExpectExec("INSERT INTO jobs").
+		WithArgs(sqlmock.AnyArg(), WebhookDeliveryJob, sqlmock.AnyArg(), payload, WebhookDeliveryAttempts, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	delivery, err := RedeliverWebhook(context.Background(), db, subscriptionID, deliveryID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if delivery.EventID != eventID || delivery.EventType != EventIntentCreated {
+		t.Fatalf("unexpected delivery %+v", delivery)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestRedeliverWebhookUnknownDelivery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectQuery("SELECT d.id, d.subscription_id, d.event_id, e.type").
+		WillReturnError(sql.ErrNoRows)
+
+	if _, err := RedeliverWebhook(context.Background(), db, uuid.New(), uuid.New()); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
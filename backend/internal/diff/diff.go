@@ -0,0 +1,48 @@
+// Package diff compares two versions of a record field by field: text fields
+// by value and list fields by the items added and removed.
+package diff
+
+import "slices"
+
+// Change is a difference in one field. From and To are set for text fields;
+// Added and Removed list the items of a list field that only appear in the
+// newer or the older version, and Reordered reports a list whose items stayed
+// the same but moved.
+type Change struct {
+	Field     string
+	From      *string
+	To        *string
+	Added     []string
+	Removed   []string
+	Reordered bool
+}
+
+// Text compares two values of a text field, reporting whether they differ.
+func Text(field, from, to string) (Change, bool) {
+	if from == to {
+		return Change{}, false
+	}
+	return Change{Field: field, From: &from, To: &to}, true
+}
+
+// List compares two values of a list field, reporting whether they differ.
+func List(field string, from, to []string) (Change, bool) {
+	if slices.Equal(from, to) {
+		return Change{}, false
+	}
+
+	change := Change{Field: field, Added: missing(to, from), Removed: missing(from, to)}
+	change.Reordered = len(change.Added) == 0 && len(change.Removed) == 0
+	return change, true
+}
+
+// missing returns the items of values that are not in other, in order.
+func missing(values, other []string) []string {
+	items := make([]string, 0)
+	for _, value := range values {
+		if !slices.Contains(other, value) {
+			items = append(items, value)
+		}
+	}
+	return items
+}
@@ -0,0 +1,36 @@
+package diff
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	if _, changed := Text("title", "Checkout", "Checkout"); changed {
+		t.Fatal("expected equal values to be unchanged")
+	}
+
+	change, changed := Text("title", "Checkout", "Reliable checkout")
+	if !changed || *change.From != "Checkout" || *change.To != "Reliable checkout" {
+		t.Fatalf("unexpected change %+v", change)
+	}
+}
+
+func TestList(t *testing.T) {
+	if _, changed := List("guardrails", []string{"a", "b"}, []string{"a", "b"}); changed {
+		t.Fatal("expected equal lists to be unchanged")
+	}
+
+	change, changed := List("guardrails", []string{"Keep focus time", "No Friday deploys"}, []string{"Keep focus time", "Deploy behind flags"})
+	if !changed {
+		t.Fatal("expected a change")
+	}
+	if !slices.Equal(change.Added, []string{"Deploy behind flags"}) || !slices.Equal(change.Removed, []string{"No Friday deploys"}) || change.Reordered {
+		t.Fatalf("unexpected change %+v", change)
+	}
+
+	change, changed = List("constraints", []string{"a", "b"}, []string{"b", "a"})
+	if !changed || !change.Reordered || len(change.Added) != 0 || len(change.Removed) != 0 {
+		t.Fatalf("expected a reorder got %+v", change)
+	}
+}
@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/impact"
+	"github.com/google/uuid"
+)
+
+type coverageSnapshotResponse struct {
+	SessionID        string  `json:"sessionId"`
+	ChapterID        string  `json:"chapterId"`
+	SessionStartsAt  string  `json:"sessionStartsAt"`
+	Checkpoint       string  `json:"checkpoint"`
+	CapturedAt       string  `json:"capturedAt"`
+	Members          int     `json:"members"`
+	AvailableMembers int     `json:"availableMembers"`
+	DeclaredMembers  int     `json:"declaredMembers"`
+	DraftMembers     int     `json:"draftMembers"`
+	ActiveIntents    int     `json:"activeIntents"`
+	Coverage         float64 `json:"coverage"`
+}
+
+type listCoverageResponse struct {
+	Items []coverageSnapshotResponse `json:"items"`
+}
+
+type durationStatsResponse struct {
+	Count    int      `json:"count"`
+	P50Hours *float64 `json:"p50Hours"`
+	P85Hours *float64 `json:"p85Hours"`
+	P95Hours *float64 `json:"p95Hours"`
+}
+
+type utilizationResponse struct {
+	Sessions       int     `json:"sessions"`
+	CommittedHours float64 `json:"committedHours"`
+	AvailableHours float64 `json:"availableHours"`
+	Utilization    float64 `json:"utilization"`
+	P50            float64 `json:"p50"`
+	P85            float64 `json:"p85"`
+	P95            float64 `json:"p95"`
+}
+
+type flowMetricsResponse struct {
+	GoalToIntent   durationStatsResponse `json:"goalToIntent"`
+	IntentToSwarm  durationStatsResponse `json:"intentToSwarm"`
+	SwarmToOutcome durationStatsResponse `json:"swarmToOutcome"`
+	Utilization    utilizationResponse   `json:"utilization"`
+}
+
+type goalImpactResponse struct {
+	GoalID            string               `json:"goalId"`
+	ParentGoalID      *string              `json:"parentGoalId"`
+	Title             string               `json:"title"`
+	Intents           int                  `json:"intents"`
+	Outcomes          int                  `json:"outcomes"`
+	EvidenceLinks     int                  `json:"evidenceLinks"`
+	Sessions          int                  `json:"sessions"`
+	SuccessCriteria   []string             `json:"successCriteria"`
+	SatisfiedCriteria []string             `json:"satisfiedCriteria"`
+	Rollup            goalProgressResponse `json:"rollup"`
+}
+
+type listGoalImpactResponse struct {
+	Items []goalImpactResponse `json:"items"`
+}
+
+type analyticsHandler struct {
+	logger *slog.Logger
+	db     *sql.DB
+}
+
+// AnalyticsHandler serves the reporting endpoints under /api/analytics.
+func AnalyticsHandler(logger *slog.Logger, db *sql.DB) http.Handler {
+	return &analyticsHandler{logger: logger, db: db}
+}
+
+func (h *analyticsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/api/analytics/coverage":
+		if r.Method != http.MethodGet {
+			h.methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		h.handleCoverage(w, r)
+	case "/api/analytics/flow":
+		if r.Method != http.MethodGet {
+			h.methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		h.handleFlow(w, r)
+	case "/api/analytics/impact":
+		if r.Method != http.MethodGet {
+			h.methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		h.handleImpact(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// handleCoverage returns the stored coverage snapshots of sessions starting
+// between from and to, in session order.
+func (h *analyticsHandler) handleCoverage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var filters database.CoverageFilters
+
+	if value := strings.TrimSpace(r.URL.Query().Get("chapter")); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "chapter must be a valid chapter id")
+			return
+		}
+		filters.ChapterID = &parsed
+	}
+
+	var ok bool
+	if filters.StartsAfter, filters.StartsBefore, ok = parseDateRange(w, r); !ok {
+		return
+	}
+
+	snapshots, err := database.ListCoverageSnapshots(ctx, h.db, filters)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list coverage snapshots", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	responses := make([]coverageSnapshotResponse, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		responses = append(responses, coverageSnapshotResponse{
+			SessionID:        snapshot.SessionID.String(),
+			ChapterID:        snapshot.ChapterID.String(),
+			SessionStartsAt:  snapshot.SessionStartsAt.Format(time.RFC3339),
+			Checkpoint:       snapshot.Checkpoint,
+			CapturedAt:       snapshot.CapturedAt.Format(time.RFC3339),
+			Members:          snapshot.Members,
+			AvailableMembers: snapshot.AvailableMembers,
+			DeclaredMembers:  snapshot.DeclaredMembers,
+			DraftMembers:     snapshot.DraftMembers,
+			ActiveIntents:    snapshot.ActiveIntents,
+			Coverage:         roundTo(snapshot.Coverage(), 3),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listCoverageResponse{Items: responses}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+// handleFlow returns cycle-time percentiles and session utilization for the
+// from and to range.
+func (h *analyticsHandler) handleFlow(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var (
+		filters database.FlowFilters
+		ok      bool
+	)
+	if filters.From, filters.To, ok = parseDateRange(w, r); !ok {
+		return
+	}
+
+	metrics, err := database.GetFlowMetrics(ctx, h.db, filters)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to compute flow metrics", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	utilization := metrics.Utilization
+	response := flowMetricsResponse{
+		GoalToIntent:   newDurationStatsResponse(metrics.GoalToIntent),
+		IntentToSwarm:  newDurationStatsResponse(metrics.IntentToSwarm),
+		SwarmToOutcome: newDurationStatsResponse(metrics.SwarmToOutcome),
+		Utilization: utilizationResponse{
+			Sessions:       utilization.Sessions,
+			CommittedHours: roundTo(utilization.CommittedHours, 2),
+			AvailableHours: roundTo(utilization.AvailableHours, 2),
+			Utilization:    roundTo(utilization.Ratio, 3),
+			P50:            roundTo(utilization.P50, 3),
+			P85:            roundTo(utilization.P85, 3),
+			P95:            roundTo(utilization.P95, 3),
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+// handleImpact returns the impact map as JSON, or rendered as a Graphviz
+// digraph with format=dot or a Mermaid flowchart with format=mermaid.
+func (h *analyticsHandler) handleImpact(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	switch format {
+	case "", "json", "dot", "mermaid":
+	default:
+		writeJSONError(w, http.StatusBadRequest, "format must be json, dot or mermaid")
+		return
+	}
+
+	var filters database.ImpactFilters
+
+	if value := strings.TrimSpace(r.URL.Query().Get("chapter")); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "chapter must be a valid chapter id")
+			return
+		}
+		filters.ChapterID = &parsed
+	}
+
+	var ok bool
+	if filters.From, filters.To, ok = parseDateRange(w, r); !ok {
+		return
+	}
+
+	impacts, err := database.GetImpactMap(ctx, h.db, filters)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to build impact map", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	if format == "dot" || format == "mermaid" {
+		goals := make([]impact.Goal, 0, len(impacts))
+		for _, item := range impacts {
+			goals = append(goals, impact.Goal{
+				ID:                item.GoalID,
+				ParentID:          item.ParentGoalID,
+				Title:             item.Title,
+				Intents:           item.Intents,
+				Outcomes:          item.Outcomes,
+				EvidenceLinks:     item.EvidenceLinks,
+				Sessions:          item.Sessions,
+				CriteriaSatisfied: len(item.SatisfiedCriteria),
+				CriteriaTotal:     len(item.SuccessCriteria),
+			})
+		}
+
+		var (
+			buf         bytes.Buffer
+			contentType = "text/vnd.graphviz; charset=utf-8"
+		)
+		if format == "dot" {
+			err = impact.EncodeDOT(&buf, goals)
+		} else {
+			contentType = "text/plain; charset=utf-8"
+			err = impact.EncodeMermaid(&buf, goals)
+		}
+		if err != nil {
+			h.logger.ErrorContext(ctx, "failed to render impact map", "error", err)
+			writeJSONError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			h.logger.ErrorContext(ctx, "failed to write impact map", "error", err)
+		}
+		return
+	}
+
+	responses := make([]goalImpactResponse, 0, len(impacts))
+	for _, item := range impacts {
+		responses = append(responses, goalImpactResponse{
+			GoalID:            item.GoalID.String(),
+			ParentGoalID:      formatOptionalUUID(item.ParentGoalID),
+			Title:             item.Title,
+			Intents:           item.Intents,
+			Outcomes:          item.Outcomes,
+			EvidenceLinks:     item.EvidenceLinks,
+			Sessions:          item.Sessions,
+			SuccessCriteria:   item.SuccessCriteria,
+			SatisfiedCriteria: item.SatisfiedCriteria,
+			Rollup:            toGoalProgressResponse(item.Rollup),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listGoalImpactResponse{Items: responses}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *analyticsHandler) methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+// parseDateRange reads the optional from and to query parameters, writing a
+// 400 response and returning false when either is not an RFC3339 timestamp.
+func parseDateRange(w http.ResponseWriter, r *http.Request) (*time.Time, *time.Time, bool) {
+	var from, to *time.Time
+
+	if value := strings.TrimSpace(r.URL.Query().Get("from")); value != "" {
+		ts, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "from must be RFC3339 timestamp")
+			return nil, nil, false
+		}
+		from = &ts
+	}
+
+	if value := strings.TrimSpace(r.URL.Query().Get("to")); value != "" {
+		ts, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "to must be RFC3339 timestamp")
+			return nil, nil, false
+		}
+		to = &ts
+	}
+
+	return from, to, true
+}
+
+// newDurationStatsResponse reports percentiles in hours, leaving them null
+// when nothing was measured.
+func newDurationStatsResponse(stats database.DurationStats) durationStatsResponse {
+	response := durationStatsResponse{Count: stats.Count}
+	if stats.Count == 0 {
+		return response
+	}
+
+	hours := func(value time.Duration) *float64 {
+		rounded := roundTo(value.Hours(), 2)
+		return &rounded
+	}
+
+	response.P50Hours = hours(stats.P50)
+	response.P85Hours = hours(stats.P85)
+	response.P95Hours = hours(stats.P95)
+	return response
+}
+
+func roundTo(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
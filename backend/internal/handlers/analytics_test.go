@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestAnalyticsHandlerCoverage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID := uuid.New(), uuid.New()
+	startsAt := time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("FROM coverage_snapshots WHERE chapter_id = \\$1").
+		WithArgs(chapterID).
+		WillReturnRows(sqlmock.NewRows([]string{"session_id", "chapter_id", "checkpoint", "session_starts_at", "members", "available_members", "declared_members", "draft_members", "active_intents", "captured_at"}).
+			AddRow(sessionID, chapterID, "24h", startsAt, 7, 6, 2, 1, 2, startsAt.Add(-24*time.Hour)).
+			AddRow(sessionID, chapterID, "1h", startsAt, 7, 6, 5, 0, 6, startsAt.Add(-time.Hour)))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/analytics/coverage?chapter="+chapterID.String(), nil)
+	rr := httptest.NewRecorder()
+
+	AnalyticsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response listCoverageResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+
+	if len(response.Items) != 2 || response.Items[0].Coverage != 0.333 || response.Items[1].Coverage != 0.833 {
+		t.Fatalf("unexpected response %+v", response.Items)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestAnalyticsHandlerCoverageRejectsBadRange(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	req := httptest.NewRequest(http.MethodGet, "/api/analytics/coverage?from=yesterday", nil)
+	rr := httptest.NewRecorder()
+
+	AnalyticsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestAnalyticsHandlerFlow(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("FROM goals g").
+		WithArgs(from, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"seconds"}).AddRow(5400.0))
+	mock.ExpectQuery("FROM intents i").
+		WithArgs(from, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"seconds"}))
+	mock.ExpectQuery("FROM swarms s").
+		WithArgs(from, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"seconds"}))
+	mock.ExpectQuery("FROM sessions s").
+		WithArgs(from, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "starts_at", "ends_at", "block_minutes", "committed"}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/analytics/flow?from="+from.Format(time.RFC3339), nil)
+	rr := httptest.NewRecorder()
+
+	AnalyticsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response flowMetricsResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+
+	if response.GoalToIntent.Count != 1 || response.GoalToIntent.P50Hours == nil || *response.GoalToIntent.P50Hours != 1.5 {
+		t.Fatalf("unexpected goal to intent stats %+v", response.GoalToIntent)
+	}
+
+	if response.IntentToSwarm.P50Hours != nil || response.Utilization.Sessions != 0 {
+		t.Fatalf("expected empty metrics got %+v", response)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestAnalyticsHandlerImpactRendersDOT(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	goalID := uuid.New()
+
+	mock.ExpectQuery("FROM goals g").
+		WithArgs(nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_goal_id", "title", "success_criteria", "intents", "outcomes", "links", "sessions", "satisfied"}).
+			AddRow(goalID, nil, "Reliable checkout", `["Retries cover checkout","Error rate under 1%"]`, 2, 1, 3, 1, `["Retries cover checkout"]`))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/analytics/impact?format=dot", nil)
+	rr := httptest.NewRecorder()
+
+	AnalyticsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	if got := rr.Header().Get("Content-Type"); got != "text/vnd.graphviz; charset=utf-8" {
+		t.Fatalf("unexpected content type %q", got)
+	}
+
+	if body := rr.Body.String(); !strings.Contains(body, "Reliable checkout") || !strings.Contains(body, "1/2 criteria satisfied") {
+		t.Fatalf("unexpected DOT output:\n%s", body)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestAnalyticsHandlerImpactRejectsUnknownFormat(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	req := httptest.NewRequest(http.MethodGet, "/api/analytics/impact?format=svg", nil)
+	rr := httptest.NewRecorder()
+
+	AnalyticsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d got %d", http.StatusBadRequest, rr.Code)
+	}
+}
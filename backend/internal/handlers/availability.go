@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+type putAvailabilityRequest struct {
+	MemberID string `json:"memberId"`
+	Status   string `json:"status"`
+	StartsAt string `json:"startsAt"`
+	EndsAt   string `json:"endsAt"`
+}
+
+type availabilityResponse struct {
+	SessionID       string                      `json:"sessionId"`
+	MemberID        string                      `json:"memberId"`
+	Status          string                      `json:"status"`
+	StartsAt        *string                     `json:"startsAt"`
+	EndsAt          *string                     `json:"endsAt"`
+	AvailableBlocks int                         `json:"availableBlocks"`
+	Source          string                      `json:"source"`
+	SyncConflict    bool                        `json:"syncConflict"`
+	Synced          *syncedAvailabilityResponse `json:"synced"`
+	UpdatedAt       string                      `json:"updatedAt"`
+}
+
+type syncedAvailabilityResponse struct {
+	Status   string  `json:"status"`
+	StartsAt *string `json:"startsAt"`
+	EndsAt   *string `json:"endsAt"`
+	SyncedAt string  `json:"syncedAt"`
+}
+
+type listAvailabilityResponse struct {
+	Items []availabilityResponse `json:"items"`
+}
+
+type createCommitmentRequest struct {
+	MemberID string `json:"memberId"`
+	IntentID string `json:"intentId"`
+	Blocks   int    `json:"blocks"`
+}
+
+type commitmentResponse struct {
+	ID        string  `json:"id"`
+	SessionID string  `json:"sessionId"`
+	MemberID  string  `json:"memberId"`
+	IntentID  *string `json:"intentId"`
+	SwarmID   *string `json:"swarmId"`
+	Blocks    int     `json:"blocks"`
+	CreatedAt string  `json:"createdAt"`
+}
+
+type listCommitmentResponse struct {
+	Items []commitmentResponse `json:"items"`
+}
+
+func parseAvailabilityPayload(sessionID uuid.UUID, payload putAvailabilityRequest) (database.AvailabilityInput, error) {
+	memberID, err := uuid.Parse(strings.TrimSpace(payload.MemberID))
+	if err != nil {
+		return database.AvailabilityInput{}, errors.New("memberId must be a valid member id")
+	}
+
+	input := database.AvailabilityInput{
+		SessionID: sessionID,
+		MemberID:  memberID,
+		Status:    strings.TrimSpace(payload.Status),
+	}
+
+	switch input.Status {
+	case database.AvailabilityAvailable, database.AvailabilityUnavailable:
+		return input, nil
+	case database.AvailabilityPartial:
+	default:
+		return database.AvailabilityInput{}, errors.New("status must be one of available, partial, unavailable")
+	}
+
+	startsAt, err := time.Parse(time.RFC3339, strings.TrimSpace(payload.StartsAt))
+	if err != nil {
+		return database.AvailabilityInput{}, errors.New("partial availability requires startsAt as RFC3339 timestamp")
+	}
+
+	endsAt, err := time.Parse(time.RFC3339, strings.TrimSpace(payload.EndsAt))
+	if err != nil {
+		return database.AvailabilityInput{}, errors.New("partial availability requires endsAt as RFC3339 timestamp")
+	}
+
+	if !startsAt.Before(endsAt) {
+		return database.AvailabilityInput{}, errors.New("startsAt must be before endsAt")
+	}
+
+	input.StartsAt = &startsAt
+	input.EndsAt = &endsAt
+	return input, nil
+}
+
+func (h *sessionsHandler) handlePutAvailability(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID) {
+	ctx := r.Context()
+
+	var payload putAvailabilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid availability payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input, err := parseAvailabilityPayload(sessionID, payload)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	record, err := database.UpsertAvailability(ctx, h.db, input)
+	if err != nil {
+		h.writeSchedulingError(ctx, w, err, "failed to record availability")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toAvailabilityResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *sessionsHandler) handleListAvailability(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID) {
+	ctx := r.Context()
+
+	records, err := database.ListAvailability(ctx, h.db, sessionID)
+	if err != nil {
+		h.writeSchedulingError(ctx, w, err, "failed to list availability")
+		return
+	}
+
+	responses := make([]availabilityResponse, 0, len(records))
+	for _, record := range records {
+		responses = append(responses, toAvailabilityResponse(record))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listAvailabilityResponse{Items: responses}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *sessionsHandler) handleDeleteAvailability(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID, id string) {
+	ctx := r.Context()
+
+	memberID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid member id")
+		return
+	}
+
+	if err := database.RemoveAvailability(ctx, h.db, sessionID, memberID); err != nil {
+		h.writeSchedulingError(ctx, w, err, "failed to remove availability")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *sessionsHandler) handleCreateCommitment(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID) {
+	ctx := r.Context()
+
+	var payload createCommitmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid commitment payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	memberID, err := uuid.Parse(strings.TrimSpace(payload.MemberID))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "memberId must be a valid member id")
+		return
+	}
+
+	intentID, err := uuid.Parse(strings.TrimSpace(payload.IntentID))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "intentId must be a valid intent id")
+		return
+	}
+
+	blocks := payload.Blocks
+	if blocks == 0 {
+		blocks = 1
+	}
+	if blocks < 1 {
+		writeJSONError(w, http.StatusBadRequest, "blocks must be at least 1")
+		return
+	}
+
+	record, err := database.CreateCommitment(ctx, h.db, database.CommitmentInput{
+		SessionID: sessionID,
+		MemberID:  memberID,
+		IntentID:  intentID,
+		Blocks:    blocks,
+	})
+	if err != nil {
+		h.writeSchedulingError(ctx, w, err, "failed to persist commitment")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(toCommitmentResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *sessionsHandler) handleListCommitments(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID) {
+	ctx := r.Context()
+
+	records, err := database.ListCommitments(ctx, h.db, sessionID)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list commitments", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	responses := make([]commitmentResponse, 0, len(records))
+	for _, record := range records {
+		responses = append(responses, toCommitmentResponse(record))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listCommitmentResponse{Items: responses}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *sessionsHandler) handleDeleteCommitment(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID, id string) {
+	ctx := r.Context()
+
+	commitmentID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid commitment id")
+		return
+	}
+
+	if err := database.DeleteCommitment(ctx, h.db, sessionID, commitmentID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "commitment not found")
+			return
+		}
+		if errors.Is(err, database.ErrSessionClosed) {
+			writeJSONError(w, http.StatusConflict, err.Error())
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to delete commitment", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toAvailabilityResponse(record database.Availability) availabilityResponse {
+	response := availabilityResponse{
+		SessionID:       record.SessionID.String(),
+		MemberID:        record.MemberID.String(),
+		Status:          record.Status,
+		StartsAt:        formatOptionalTime(record.StartsAt),
+		EndsAt:          formatOptionalTime(record.EndsAt),
+		AvailableBlocks: record.AvailableBlocks,
+		Source:          record.Source,
+		SyncConflict:    record.SyncConflict,
+		UpdatedAt:       record.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if record.Synced != nil {
+		response.Synced = &syncedAvailabilityResponse{
+			Status:   record.Synced.Status,
+			StartsAt: formatOptionalTime(record.Synced.StartsAt),
+			EndsAt:   formatOptionalTime(record.Synced.EndsAt),
+			SyncedAt: record.Synced.SyncedAt.Format(time.RFC3339),
+		}
+	}
+
+	return response
+}
+
+func toCommitmentResponse(record database.Commitment) commitmentResponse {
+	return commitmentResponse{
+		ID:        record.ID.String(),
+		SessionID: record.SessionID.String(),
+		MemberID:  record.MemberID.String(),
+		IntentID:  formatOptionalUUID(record.IntentID),
+		SwarmID:   formatOptionalUUID(record.SwarmID),
+		Blocks:    record.Blocks,
+		CreatedAt: record.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func formatOptionalTime(value *time.Time) *string {
+	if value == nil {
+		return nil
+	}
+	formatted := value.Format(time.RFC3339)
+	return &formatted
+}
+
+func formatOptionalUUID(value *uuid.UUID) *string {
+	if value == nil {
+		return nil
+	}
+	formatted := value.String()
+	return &formatted
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/ical"
+	"github.com/google/uuid"
+)
+
+const (
+	calendarProdID = "-//Intent//Chapter Calendar//EN"
+	calendarDomain = "intent"
+	// calendarFeedLookback bounds how far into the past feeds reach so they
+	// stay small for long-lived chapters.
+	calendarFeedLookback = 90 * 24 * time.Hour
+)
+
+type calendarTokenResponse struct {
+	Token       string `json:"token"`
+	MemberFeed  string `json:"memberFeed"`
+	ChapterFeed string `json:"chapterFeed"`
+}
+
+func (h *membersHandler) handleIssueCalendarToken(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	memberID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid member id")
+		return
+	}
+
+	member, err := database.GetMember(ctx, h.db, memberID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "member not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to retrieve member", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	token, err := database.IssueCalendarToken(ctx, h.db, memberID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "member not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to issue calendar token", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response := calendarTokenResponse{
+		Token:       token,
+		MemberFeed:  fmt.Sprintf("/api/members/%s/calendar.ics?token=%s", member.ID, token),
+		ChapterFeed: fmt.Sprintf("/api/chapters/%s/calendar.ics?token=%s", member.ChapterID, token),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *membersHandler) handleCalendarFeed(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	memberID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid member id")
+		return
+	}
+
+	valid, err := database.MemberCalendarTokenValid(ctx, h.db, memberID, strings.TrimSpace(r.URL.Query().Get("token")))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to verify calendar token", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if !valid {
+		writeJSONError(w, http.StatusUnauthorized, "a valid calendar token is required")
+		return
+	}
+
+	member, err := database.GetMember(ctx, h.db, memberID)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to retrieve member", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	entries, err := database.ListMemberCalendarEntries(ctx, h.db, memberID, time.Now().Add(-calendarFeedLookback))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list calendar entries", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	cal := ical.Calendar{
+		ProdID: calendarProdID,
+		Name:   member.DisplayName + " chapter time",
+		Events: toCalendarEvents(entries, &memberID),
+	}
+
+	writeCalendar(w, r, h.logger, cal)
+}
+
+func (h *chaptersHandler) handleCalendarFeed(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	chapterID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid chapter id")
+		return
+	}
+
+	valid, err := database.ChapterCalendarTokenValid(ctx, h.db, chapterID, strings.TrimSpace(r.URL.Query().Get("token")))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to verify calendar token", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if !valid {
+		writeJSONError(w, http.StatusUnauthorized, "a valid calendar token is required")
+		return
+	}
+
+	chapter, err := database.GetChapter(ctx, h.db, chapterID)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to retrieve chapter", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	entries, err := database.ListChapterCalendarEntries(ctx, h.db, chapterID, time.Now().Add(-calendarFeedLookback))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list calendar entries", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	cal := ical.Calendar{
+		ProdID: calendarProdID,
+		Name:   chapter.Name + " chapter",
+		Events: toCalendarEvents(entries, nil),
+	}
+
+	writeCalendar(w, r, h.logger, cal)
+}
+
+func writeCalendar(w http.ResponseWriter, r *http.Request, logger *slog.Logger, cal ical.Calendar) {
+	var buf bytes.Buffer
+	if err := ical.Encode(&buf, cal); err != nil {
+		logger.ErrorContext(r.Context(), "failed to encode calendar", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		logger.ErrorContext(r.Context(), "failed to write calendar", "error", err)
+	}
+}
+
+// toCalendarEvents maps calendar entries onto VEVENTs. Member feeds render
+// sessions with the member's own availability, so their UIDs are scoped to
+// the member to avoid clashing with the chapter-wide session events.
+func toCalendarEvents(entries []database.CalendarEntry, memberID *uuid.UUID) []ical.Event {
+	events := make([]ical.Event, 0, len(entries))
+	for _, entry := range entries {
+		event := ical.Event{
+			Sequence: entry.Sequence,
+			Stamp:    entry.UpdatedAt,
+			Start:    entry.StartsAt,
+			End:      entry.EndsAt,
+			Status:   ical.StatusConfirmed,
+		}
+
+		switch entry.Kind {
+		case database.CalendarEntrySwarm:
+			event.UID = fmt.Sprintf("swarm-%s@%s", entry.ID, calendarDomain)
+			event.Summary = "Swarm: " + entry.Title
+			event.Description = entry.Details
+			if entry.Status == database.SwarmDissolved {
+				event.Status = ical.StatusCancelled
+			}
+		default:
+			event.UID = fmt.Sprintf("session-%s@%s", entry.ID, calendarDomain)
+			if memberID != nil {
+				event.UID = fmt.Sprintf("session-%s-member-%s@%s", entry.ID, memberID, calendarDomain)
+			}
+			event.Summary = entry.Title + " chapter session"
+			switch entry.Status {
+			case database.AvailabilityUnavailable, database.AvailabilityRemoved:
+				event.Status = ical.StatusCancelled
+			case database.AvailabilityPartial:
+				event.Description = "Partially available"
+			}
+		}
+
+		events = append(events, event)
+	}
+
+	return events
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/calsync"
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/ical"
+	"github.com/google/uuid"
+)
+
+// maxImportBytes caps the size of uploaded ICS files.
+const maxImportBytes = 5 << 20
+
+type calendarSourceRequest struct {
+	URL      string `json:"url"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type calendarSourceResponse struct {
+	MemberID     string  `json:"memberId"`
+	URL          string  `json:"url"`
+	Username     string  `json:"username"`
+	LastSyncedAt *string `json:"lastSyncedAt"`
+	LastError    string  `json:"lastError"`
+	CreatedAt    string  `json:"createdAt"`
+	UpdatedAt    string  `json:"updatedAt"`
+}
+
+type availabilitySyncResponse struct {
+	Items     []availabilityResponse `json:"items"`
+	Conflicts int                    `json:"conflicts"`
+}
+
+func (h *membersHandler) handleImportAvailability(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	member, ok := h.loadMember(w, r, id)
+	if !ok {
+		return
+	}
+
+	loc, err := calsync.MemberLocation(ctx, h.db, member)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to resolve member timezone", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	events, err := ical.Parse(io.LimitReader(r.Body, maxImportBytes), loc)
+	if err != nil {
+		h.logger.WarnContext(ctx, "invalid calendar upload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "request body must be an iCalendar file")
+		return
+	}
+
+	result, err := calsync.SyncMember(ctx, h.db, member, events, time.Now().UTC())
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to sync availability", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	h.writeSyncResult(w, r, result)
+}
+
+func (h *membersHandler) handlePutCalendarSource(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	member, ok := h.loadMember(w, r, id)
+	if !ok {
+		return
+	}
+
+	var payload calendarSourceRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid calendar source payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	parsed, err := url.Parse(strings.TrimSpace(payload.URL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		writeJSONError(w, http.StatusBadRequest, "url must be an absolute http or https URL")
+		return
+	}
+
+	input := database.CalendarSourceInput{
+		URL:      parsed.String(),
+		Username: strings.TrimSpace(payload.Username),
+	}
+	if payload.Password != "" {
+		if h.credentials == nil {
+			writeJSONError(w, http.StatusServiceUnavailable, "no calendar credentials key is configured")
+			return
+		}
+
+		input.SealedPassword, err = h.credentials.Seal(payload.Password)
+		if err != nil {
+			h.logger.ErrorContext(ctx, "failed to seal calendar password", "error", err)
+			writeJSONError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+	}
+
+	source, err := database.UpsertCalendarSource(ctx, h.db, member.ID, input)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to persist calendar source", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toCalendarSourceResponse(source)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *membersHandler) handleRetrieveCalendarSource(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	source, ok := h.loadCalendarSource(w, r, id)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toCalendarSourceResponse(source)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *membersHandler) handleDeleteCalendarSource(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	memberID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid member id")
+		return
+	}
+
+	if err := database.DeleteCalendarSource(ctx, h.db, memberID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "calendar source not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to delete calendar source", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *membersHandler) handleSyncCalendarSource(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	source, ok := h.loadCalendarSource(w, r, id)
+	if !ok {
+		return
+	}
+
+	result, err := calsync.SyncSource(ctx, h.db, calsync.DefaultClient, h.credentials, source, time.Now().UTC())
+	if err != nil {
+		h.logger.WarnContext(ctx, "calendar sync failed", "member_id", source.MemberID, "error", err)
+		writeJSONError(w, http.StatusBadGateway, "calendar sync failed: "+err.Error())
+		return
+	}
+
+	h.writeSyncResult(w, r, result)
+}
+
+func (h *membersHandler) loadMember(w http.ResponseWriter, r *http.Request, id string) (database.Member, bool) {
+	ctx := r.Context()
+
+	memberID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid member id")
+		return database.Member{}, false
+	}
+
+	member, err := database.GetMember(ctx, h.db, memberID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "member not found")
+			return database.Member{}, false
+		}
+		h.logger.ErrorContext(ctx, "failed to retrieve member", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return database.Member{}, false
+	}
+
+	return member, true
+}
+
+func (h *membersHandler) loadCalendarSource(w http.ResponseWriter, r *http.Request, id string) (database.CalendarSource, bool) {
+	ctx := r.Context()
+
+	memberID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid member id")
+		return database.CalendarSource{}, false
+	}
+
+	source, err := database.GetCalendarSource(ctx, h.db, memberID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "calendar source not found")
+			return database.CalendarSource{}, false
+		}
+		h.logger.ErrorContext(ctx, "failed to retrieve calendar source", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return database.CalendarSource{}, false
+	}
+
+	return source, true
+}
+
+func (h *membersHandler) writeSyncResult(w http.ResponseWriter, r *http.Request, result calsync.Result) {
+	responses := make([]availabilityResponse, 0, len(result.Availability))
+	for _, record := range result.Availability {
+		responses = append(responses, toAvailabilityResponse(record))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(availabilitySyncResponse{Items: responses, Conflicts: result.Conflicts}); err != nil {
+		h.logger.ErrorContext(r.Context(), "failed to encode response", "error", err)
+	}
+}
+
+func toCalendarSourceResponse(source database.CalendarSource) calendarSourceResponse {
+	return calendarSourceResponse{
+		MemberID:     source.MemberID.String(),
+		URL:          source.URL,
+		Username:     source.Username,
+		LastSyncedAt: formatOptionalTime(source.LastSyncedAt),
+		LastError:    source.LastError,
+		CreatedAt:    source.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    source.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/intent/backend/internal/secret"
+	"github.com/google/uuid"
+)
+
+func expectMemberLookup(mock sqlmock.Sqlmock, memberID, chapterID uuid.UUID) {
+	mock.ExpectQuery(regexp.QuoteMeta("FROM members WHERE id = $1")).
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "chapter_id", "display_name", "email", "role", "created_at"}).
+			AddRow(memberID, chapterID, "Ada", "ada@example.com", "member", time.Now().UTC()))
+}
+
+func TestMembersHandlerImportAvailability(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	memberID, chapterID, sessionID := uuid.New(), uuid.New(), uuid.New()
+	start := time.Now().UTC().Truncate(time.Hour).Add(48 * time.Hour)
+	busyStart := start.Add(3 * time.Hour)
+
+	ics := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:late\r\n" +
+		"DTSTART:" + busyStart.Format("20060102T150405Z") + "\r\n" +
+		"DURATION:PT2H\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+	expectMemberLookup(mock, memberID, chapterID)
+	expectChapterLookup(mock, chapterID, "UTC", 3)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, chapter_id, starts_at, ends_at, state, kicked_off_at, closed_at, created_at FROM sessions WHERE chapter_id = $1")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "chapter_id", "starts_at", "ends_at", "state", "kicked_off_at", "closed_at", "created_at"}).
+			AddRow(sessionID, chapterID, start, start.Add(4*time.Hour), "scheduled", nil, nil, start))
+
+	mock.ExpectBegin()
+	expectSessionCapacityLookup(mock, sessionID, chapterID, start, 3)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT chapter_id FROM members WHERE id = $1 FOR UPDATE")).
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"chapter_id"}).AddRow(chapterID))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM availability WHERE session_id = $1 AND member_id = $2 FOR UPDATE")).
+		WithArgs(sessionID, memberID).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(blocks), 0) FROM commitments")).
+		WithArgs(sessionID, memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO availability")).
+		WithArgs(sessionID, memberID, "partial", start, busyStart, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"session_id", "member_id", "status", "starts_at", "ends_at", "source", "sync_conflict", "synced_status", "synced_starts_at", "synced_ends_at", "synced_at", "updated_at"}).
+			AddRow(sessionID, memberID, "partial", start, busyStart, "sync", false, "partial", start, busyStart, start, start))
+	mock.ExpectCommit()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/members/"+memberID.String()+"/availability-import", strings.NewReader(ics))
+	req.Header.Set("Content-Type", "text/calendar")
+	rr := httptest.NewRecorder()
+
+	MembersHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response availabilitySyncResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+
+	if len(response.Items) != 1 || response.Items[0].Status != "partial" || response.Items[0].AvailableBlocks != 3 {
+		t.Fatalf("unexpected items %+v", response.Items)
+	}
+
+	if response.Items[0].Source != "sync" || response.Items[0].Synced == nil {
+		t.Fatalf("expected synced record got %+v", response.Items[0])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestMembersHandlerImportAvailabilityRejectsNonCalendar(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	memberID, chapterID := uuid.New(), uuid.New()
+	expectMemberLookup(mock, memberID, chapterID)
+	expectChapterLookup(mock, chapterID, "UTC", 3)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/members/"+memberID.String()+"/availability-import", strings.NewReader("not a calendar"))
+	rr := httptest.NewRecorder()
+
+	MembersHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestMembersHandlerPutCalendarSourceRejectsNonHTTP(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	memberID := uuid.New()
+	expectMemberLookup(mock, memberID, uuid.New())
+
+	req := httptest.NewRequest(http.MethodPut, "/api/members/"+memberID.String()+"/calendar-source", strings.NewReader(`{"url":"file:///etc/passwd"}`))
+	rr := httptest.NewRecorder()
+
+	MembersHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+// sealedArg matches a password sealed by box that opens to want.
+type sealedArg struct {
+	box  *secret.Box
+	want string
+}
+
+func (a sealedArg) Match(value driver.Value) bool {
+	sealed, ok := value.(string)
+	if !ok || strings.Contains(sealed, a.want) {
+		return false
+	}
+	opened, err := a.box.Open(sealed)
+	return err == nil && opened == a.want
+}
+
+func TestMembersHandlerPutCalendarSourceSealsPassword(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	box, err := secret.NewBox(bytes.Repeat([]byte{9}, secret.KeySize))
+	if err != nil {
+		t.Fatalf("failed to create box: %v", err)
+	}
+
+	memberID := uuid.New()
+	now := time.Now().UTC()
+	expectMemberLookup(mock, memberID, uuid.New())
+	mock.ExpectQuery(regexp.QuoteMeta("INSERT INTO calendar_sources (member_id, url, username, sealed_password, created_at, updated_at)")).
+		WithArgs(memberID, "https://cal.example.com/ada/", "ada", sealedArg{box: box, want: "app-pass"}, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"member_id", "url", "username", "sealed_password", "last_synced_at", "last_error", "created_at", "updated_at"}).
+			AddRow(memberID, "https://cal.example.com/ada/", "ada", "v1:sealed", nil, "", now, now))
+
+	body := `{"url":"https://cal.example.com/ada/","username":"ada","password":"app-pass"}`
+	req := httptest.NewRequest(http.MethodPut, "/api/members/"+memberID.String()+"/calendar-source", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	CalendarMembersHandler(testLogger(t), db, box).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	if strings.Contains(rr.Body.String(), "assword") || strings.Contains(rr.Body.String(), "v1:") {
+		t.Fatalf("expected password to stay out of the response got %s", rr.Body.String())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestMembersHandlerPutCalendarSourceRequiresCredentialsKeyForPassword(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	memberID := uuid.New()
+	expectMemberLookup(mock, memberID, uuid.New())
+
+	body := `{"url":"https://cal.example.com/ada/","username":"ada","password":"app-pass"}`
+	req := httptest.NewRequest(http.MethodPut, "/api/members/"+memberID.String()+"/calendar-source", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	MembersHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
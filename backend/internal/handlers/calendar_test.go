@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestMembersHandlerCalendarFeedRequiresToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	req := httptest.NewRequest(http.MethodGet, "/api/members/"+uuid.NewString()+"/calendar.ics", nil)
+	rr := httptest.NewRecorder()
+
+	MembersHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d got %d", http.StatusUnauthorized, rr.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestMembersHandlerCalendarFeed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	memberID, chapterID, sessionID, swarmID, withdrawnID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	start := time.Date(2024, 5, 6, 11, 0, 0, 0, time.UTC)
+	columns := []string{"id", "session_id", "title", "details", "status", "starts_at", "ends_at", "sequence", "updated_at"}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM members WHERE id = $1 AND calendar_token_hash = $2)")).
+		WithArgs(memberID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM members WHERE id = $1")).
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "chapter_id", "display_name", "email", "role", "created_at"}).
+			AddRow(memberID, chapterID, "Ada", "ada@example.com", "member", start))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM availability a")).
+		WithArgs(memberID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(sessionID, sessionID, "Platform", "", "unavailable", start, start.Add(4*time.Hour), 1, start).
+			AddRow(withdrawnID, withdrawnID, "Platform", "", "removed", start.Add(7*24*time.Hour), start.Add(7*24*time.Hour+4*time.Hour), 4, start))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM swarms w JOIN swarm_members sm")).
+		WithArgs(memberID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(swarmID, sessionID, "Checkout", "Unblock release", "dissolved", start, start.Add(time.Hour), 2, start))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/members/"+memberID.String()+"/calendar.ics?token=secret", nil)
+	rr := httptest.NewRecorder()
+
+	MembersHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	if got := rr.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/calendar") {
+		t.Fatalf("expected text/calendar content type got %q", got)
+	}
+
+	body := strings.ReplaceAll(rr.Body.String(), "\r\n ", "")
+	for _, want := range []string{
+		"UID:session-" + sessionID.String() + "-member-" + memberID.String() + "@intent\r\n",
+		"UID:swarm-" + swarmID.String() + "@intent\r\n",
+		"UID:session-" + withdrawnID.String() + "-member-" + memberID.String() + "@intent\r\n",
+		"SEQUENCE:2\r\n",
+		"SEQUENCE:4\r\n",
+		"SUMMARY:Swarm: Checkout\r\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected feed to contain %q, got:\n%s", want, body)
+		}
+	}
+
+	if strings.Count(body, "STATUS:CANCELLED") != 3 {
+		t.Fatalf("expected unavailable and withdrawn sessions and dissolved swarm to be cancelled, got:\n%s", body)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestChaptersHandlerCalendarFeedRejectsTokenFromOtherChapter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	chapterID := uuid.New()
+
+	// The token belongs to a member of another chapter, so no member of this
+	// chapter holds it.
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM members WHERE chapter_id = $1 AND calendar_token_hash = $2)")).
+		WithArgs(chapterID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/chapters/"+chapterID.String()+"/calendar.ics?token=other-chapter", nil)
+	rr := httptest.NewRecorder()
+
+	ChaptersHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d got %d", http.StatusUnauthorized, rr.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/schedule"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultMaxConcurrentSwarms = 3
+	defaultBlockMinutes        = 60
+)
+
+type createChapterRequest struct {
+	Name                string `json:"name"`
+	Timezone            string `json:"timezone"`
+	MaxConcurrentSwarms *int   `json:"maxConcurrentSwarms"`
+	BlockMinutes        *int   `json:"blockMinutes"`
+}
+
+type chapterResponse struct {
+	ID                  string `json:"id"`
+	Name                string `json:"name"`
+	Timezone            string `json:"timezone"`
+	MaxConcurrentSwarms int    `json:"maxConcurrentSwarms"`
+	BlockMinutes        int    `json:"blockMinutes"`
+	CreatedAt           string `json:"createdAt"`
+	UpdatedAt           string `json:"updatedAt"`
+}
+
+type listChapterResponse struct {
+	Items []chapterResponse `json:"items"`
+}
+
+type chaptersHandler struct {
+	logger *slog.Logger
+	db     *sql.DB
+}
+
+// ChaptersHandler routes operations for chapter instances and their
+// capacity settings.
+func ChaptersHandler(logger *slog.Logger, db *sql.DB) http.Handler {
+	return &chaptersHandler{logger: logger, db: db}
+}
+
+func (h *chaptersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/chapters":
+		h.handleCreate(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/api/chapters":
+		h.handleList(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/chapters/"):
+		id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/chapters/"), "/")
+		if id == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		action, rest, nested := strings.Cut(action, "/")
+		if nested && action != "showcase" {
+			http.NotFound(w, r)
+			return
+		}
+
+		switch action {
+		case "":
+			switch r.Method {
+			case http.MethodGet:
+				h.handleRetrieve(w, r, id)
+			case http.MethodPut:
+				h.handleUpdate(w, r, id)
+			default:
+				h.methodNotAllowed(w, http.MethodGet, http.MethodPut)
+			}
+		case "calendar.ics", "retro", "showcase.ics":
+			if r.Method != http.MethodGet {
+				h.methodNotAllowed(w, http.MethodGet)
+				return
+			}
+			switch action {
+			case "retro":
+				h.handleChapterRetro(w, r, id)
+			case "showcase.ics":
+				h.handleShowcaseFeed(w, r, id)
+			default:
+				h.handleCalendarFeed(w, r, id)
+			}
+		case "showcase":
+			h.routeShowcase(w, r, id, rest)
+		case "intent-quality-rules":
+			h.routeQualityRules(w, r, id)
+		default:
+			http.NotFound(w, r)
+		}
+	default:
+		h.methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func parseChapterPayload(payload createChapterRequest) (database.ChapterInput, error) {
+	input := database.ChapterInput{
+		Name:                strings.TrimSpace(payload.Name),
+		Timezone:            strings.TrimSpace(payload.Timezone),
+		MaxConcurrentSwarms: defaultMaxConcurrentSwarms,
+		BlockMinutes:        defaultBlockMinutes,
+	}
+
+	if input.Name == "" {
+		return database.ChapterInput{}, errors.New("name is required")
+	}
+
+	if input.Timezone == "" {
+		input.Timezone = "UTC"
+	}
+	if _, err := schedule.LoadLocation(input.Timezone); err != nil {
+		return database.ChapterInput{}, errors.New("timezone must be a valid IANA timezone")
+	}
+
+	if payload.MaxConcurrentSwarms != nil {
+		if *payload.MaxConcurrentSwarms < 1 {
+			return database.ChapterInput{}, errors.New("maxConcurrentSwarms must be at least 1")
+		}
+		input.MaxConcurrentSwarms = *payload.MaxConcurrentSwarms
+	}
+
+	if payload.BlockMinutes != nil {
+		if *payload.BlockMinutes < 15 || *payload.BlockMinutes > 240 {
+			return database.ChapterInput{}, errors.New("blockMinutes must be between 15 and 240")
+		}
+		input.BlockMinutes = *payload.BlockMinutes
+	}
+
+	return input, nil
+}
+
+func (h *chaptersHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var payload createChapterRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid chapter payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input, err := parseChapterPayload(payload)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	record, err := database.CreateChapter(ctx, h.db, input)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to persist chapter", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(toChapterResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *chaptersHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	chapters, err := database.ListChapters(ctx, h.db)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list chapters", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	responses := make([]chapterResponse, 0, len(chapters))
+	for _, chapter := range chapters {
+		responses = append(responses, toChapterResponse(chapter))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listChapterResponse{Items: responses}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *chaptersHandler) handleRetrieve(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	uuidValue, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid chapter id")
+		return
+	}
+
+	record, err := database.GetChapter(ctx, h.db, uuidValue)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "chapter not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to retrieve chapter", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toChapterResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *chaptersHandler) handleUpdate(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	uuidValue, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid chapter id")
+		return
+	}
+
+	var payload createChapterRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid chapter payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input, err := parseChapterPayload(payload)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	record, err := database.UpdateChapter(ctx, h.db, uuidValue, input)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "chapter not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to update chapter", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toChapterResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *chaptersHandler) methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func toChapterResponse(chapter database.Chapter) chapterResponse {
+	return chapterResponse{
+		ID:                  chapter.ID.String(),
+		Name:                chapter.Name,
+		Timezone:            chapter.Timezone,
+		MaxConcurrentSwarms: chapter.MaxConcurrentSwarms,
+		BlockMinutes:        chapter.BlockMinutes,
+		CreatedAt:           chapter.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:           chapter.UpdatedAt.Format(time.RFC3339),
+	}
+}
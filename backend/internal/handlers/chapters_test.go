@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestChaptersHandlerCreateAppliesDefaults(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectExec("INSERT INTO chapters").
+		WithArgs(sqlmock.AnyArg(), "Platform", "Europe/London", defaultMaxConcurrentSwarms, defaultBlockMinutes, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	body := []byte(`{"name":" Platform ","timezone":"Europe/London"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/chapters", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	ChaptersHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d got %d", http.StatusCreated, rr.Code)
+	}
+
+	var response chapterResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+
+	if response.MaxConcurrentSwarms != defaultMaxConcurrentSwarms || response.BlockMinutes != defaultBlockMinutes {
+		t.Fatalf("expected default capacity settings got %+v", response)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestChaptersHandlerCreateRejectsUnknownTimezone(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	body := []byte(`{"name":"Platform","timezone":"Mars/Olympus"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/chapters", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	ChaptersHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
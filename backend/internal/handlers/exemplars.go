@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+type promoteExemplarRequest struct {
+	IntentID   string   `json:"intentId"`
+	OutcomeID  string   `json:"outcomeId"`
+	PromotedBy string   `json:"promotedBy"`
+	Annotation string   `json:"annotation"`
+	Tags       []string `json:"tags"`
+}
+
+type updateExemplarRequest struct {
+	Annotation string   `json:"annotation"`
+	Tags       []string `json:"tags"`
+}
+
+type exemplarResponse struct {
+	ID             string           `json:"id"`
+	ChapterID      string           `json:"chapterId"`
+	SourceIntentID *string          `json:"sourceIntentId"`
+	GoalID         *string          `json:"goalId"`
+	TemplateKind   string           `json:"templateKind"`
+	Tags           []string         `json:"tags"`
+	Annotation     string           `json:"annotation"`
+	Intent         intentResponse   `json:"intent"`
+	Outcome        *outcomeResponse `json:"outcome"`
+	PromotedBy     *string          `json:"promotedBy"`
+	CreatedAt      string           `json:"createdAt"`
+	UpdatedAt      string           `json:"updatedAt"`
+}
+
+type listExemplarResponse struct {
+	Items []exemplarResponse `json:"items"`
+}
+
+type exemplarsHandler struct {
+	logger *slog.Logger
+	db     *sql.DB
+}
+
+// ExemplarsHandler routes operations for the exemplar library.
+func ExemplarsHandler(logger *slog.Logger, db *sql.DB) http.Handler {
+	return &exemplarsHandler{logger: logger, db: db}
+}
+
+func (h *exemplarsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/exemplars":
+		h.handlePromote(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/api/exemplars":
+		h.handleList(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/exemplars/"):
+		id := strings.TrimPrefix(r.URL.Path, "/api/exemplars/")
+		if id == "" || strings.Contains(id, "/") {
+			http.NotFound(w, r)
+			return
+		}
+
+		exemplarID, err := uuid.Parse(id)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid exemplar id")
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			h.handleRetrieve(w, r, exemplarID)
+		case http.MethodPut:
+			h.handleUpdate(w, r, exemplarID)
+		case http.MethodDelete:
+			h.handleDelete(w, r, exemplarID)
+		default:
+			h.methodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodDelete)
+		}
+	default:
+		h.methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (h *exemplarsHandler) handlePromote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var payload promoteExemplarRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid exemplar payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input := database.ExemplarInput{
+		Annotation: strings.TrimSpace(payload.Annotation),
+		Tags:       normalizeTags(payload.Tags),
+	}
+
+	if input.Annotation == "" {
+		writeJSONError(w, http.StatusBadRequest, "annotation is required")
+		return
+	}
+
+	intentID, err := uuid.Parse(strings.TrimSpace(payload.IntentID))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "intentId must be a valid intent id")
+		return
+	}
+	input.IntentID = intentID
+
+	promotedBy, err := uuid.Parse(strings.TrimSpace(payload.PromotedBy))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "promotedBy must be a valid member id")
+		return
+	}
+	input.PromotedBy = promotedBy
+
+	if value := strings.TrimSpace(payload.OutcomeID); value != "" {
+		outcomeID, err := uuid.Parse(value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "outcomeId must be a valid outcome id")
+			return
+		}
+		input.OutcomeID = &outcomeID
+	}
+
+	exemplar, err := database.PromoteExemplar(ctx, h.db, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeJSONError(w, http.StatusNotFound, "intent not found")
+		case errors.Is(err, database.ErrNotChapterLead):
+			writeJSONError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, database.ErrOutcomeNotFound):
+			writeJSONError(w, http.StatusBadRequest, "outcomeId must be an outcome recorded for the intent")
+		case errors.Is(err, database.ErrAlreadyExemplar):
+			writeJSONError(w, http.StatusConflict, err.Error())
+		default:
+			h.logger.ErrorContext(ctx, "failed to promote exemplar", "error", err)
+			writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(toExemplarResponse(exemplar)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *exemplarsHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	filters := database.ExemplarFilters{
+		Tag:          strings.ToLower(strings.TrimSpace(query.Get("tag"))),
+		TemplateKind: normalizeTemplateKind(query.Get("templateKind")),
+	}
+
+	refs := []struct {
+		param string
+		dest  **uuid.UUID
+	}{
+		{"chapter", &filters.ChapterID},
+		{"goal", &filters.GoalID},
+	}
+
+	for _, ref := range refs {
+		value := strings.TrimSpace(query.Get(ref.param))
+		if value == "" {
+			continue
+		}
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, ref.param+" must be a valid "+ref.param+" id")
+			return
+		}
+		*ref.dest = &parsed
+	}
+
+	exemplars, err := database.ListExemplars(ctx, h.db, filters)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list exemplars", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	responses := make([]exemplarResponse, 0, len(exemplars))
+	for _, exemplar := range exemplars {
+		responses = append(responses, toExemplarResponse(exemplar))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listExemplarResponse{Items: responses}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *exemplarsHandler) handleRetrieve(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	ctx := r.Context()
+
+	exemplar, err := database.GetExemplar(ctx, h.db, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "exemplar not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to retrieve exemplar", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toExemplarResponse(exemplar)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *exemplarsHandler) handleUpdate(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	ctx := r.Context()
+
+	var payload updateExemplarRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid exemplar payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	annotation := strings.TrimSpace(payload.Annotation)
+	if annotation == "" {
+		writeJSONError(w, http.StatusBadRequest, "annotation is required")
+		return
+	}
+
+	exemplar, err := database.UpdateExemplar(ctx, h.db, id, annotation, normalizeTags(payload.Tags))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "exemplar not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to update exemplar", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toExemplarResponse(exemplar)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *exemplarsHandler) handleDelete(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	ctx := r.Context()
+
+	if err := database.DeleteExemplar(ctx, h.db, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "exemplar not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to delete exemplar", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *exemplarsHandler) methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func toExemplarResponse(exemplar database.Exemplar) exemplarResponse {
+	response := exemplarResponse{
+		ID:             exemplar.ID.String(),
+		ChapterID:      exemplar.ChapterID.String(),
+		SourceIntentID: formatOptionalUUID(exemplar.IntentID),
+		GoalID:         formatOptionalUUID(exemplar.GoalID),
+		TemplateKind:   exemplar.TemplateKind,
+		Tags:           exemplar.Tags,
+		Annotation:     exemplar.Annotation,
+		Intent:         toIntentResponse(exemplar.Intent),
+		PromotedBy:     formatOptionalUUID(exemplar.PromotedBy),
+		CreatedAt:      exemplar.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      exemplar.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if exemplar.Outcome != nil {
+		outcome := toOutcomeResponses([]database.SessionOutcome{*exemplar.Outcome})[0]
+		response.Outcome = &outcome
+	}
+
+	return response
+}
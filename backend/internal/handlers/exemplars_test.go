@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/intent/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestExemplarsHandlerPromoteRequiresAnnotation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	body := `{"intentId":"` + uuid.New().String() + `","promotedBy":"` + uuid.New().String() + `","annotation":"  "}`
+	req := httptest.NewRequest(http.MethodPost, "/api/exemplars", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	ExemplarsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 got %d", rr.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func TestExemplarsHandlerPromoteRejectsNonLead(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	memberID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT chapter_id, role FROM members WHERE id = $1")).
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"chapter_id", "role"}).AddRow(uuid.New(), database.RoleFacilitator))
+	mock.ExpectRollback()
+
+	body := `{"intentId":"` + uuid.New().String() + `","promotedBy":"` + memberID.String() + `","annotation":"Names the metric"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/exemplars", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	ExemplarsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 got %d", rr.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func TestExemplarsHandlerListReturnsSnapshots(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	chapterID, intentID, outcomeID, sessionID, memberID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	now := time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC)
+	intentSnapshot := `{"id":"` + intentID.String() + `","statement":"I intend to halve deploy time","context":"c","expectedOutcome":"Deploys under 20 minutes","collaborators":["Ana"],"status":"done","createdAt":"2024-05-01T09:00:00Z"}`
+	outcomeSnapshot := `{"id":"` + outcomeID.String() + `","sessionId":"` + sessionID.String() + `","memberId":"` + memberID.String() + `","outcome":"18 minutes","obstacles":"","satisfiedCriteria":[],"createdAt":"2024-05-02T16:00:00Z"}`
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM exemplars WHERE chapter_id = $1 AND tags @> jsonb_build_array($2::text) AND template_kind = $3")).
+		WithArgs(chapterID, "delivery", "design-review").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "chapter_id", "intent_id", "goal_id", "template_kind", "tags", "annotation", "intent_snapshot", "outcome_snapshot", "promoted_by", "created_at", "updated_at"}).
+			AddRow(uuid.New(), chapterID, intentID, nil, "design-review", []byte(`["delivery"]`), "Names the metric", []byte(intentSnapshot), []byte(outcomeSnapshot), memberID, now, now))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/exemplars?chapter="+chapterID.String()+"&tag=Delivery&templateKind=Design+Review", nil)
+	rr := httptest.NewRecorder()
+
+	ExemplarsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var response listExemplarResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(response.Items) != 1 {
+		t.Fatalf("expected one exemplar got %d", len(response.Items))
+	}
+
+	item := response.Items[0]
+	if item.Intent.Statement != "I intend to halve deploy time" || item.Intent.Status != "done" {
+		t.Fatalf("unexpected intent snapshot %+v", item.Intent)
+	}
+	if item.Outcome == nil || item.Outcome.Outcome != "18 minutes" || item.Outcome.IntentID == nil || *item.Outcome.IntentID != intentID.String() {
+		t.Fatalf("unexpected outcome snapshot %+v", item.Outcome)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
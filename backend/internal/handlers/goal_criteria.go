@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/keyresult"
+	"github.com/google/uuid"
+)
+
+// successCriterionRequest is a success criterion given either as plain text
+// or as an object carrying a metric.
+type successCriterionRequest struct {
+	Criterion string
+	Metric    *criterionMetricRequest
+}
+
+type criterionMetricRequest struct {
+	Baseline  *float64 `json:"baseline"`
+	Target    *float64 `json:"target"`
+	Unit      string   `json:"unit"`
+	Direction string   `json:"direction"`
+}
+
+func (c *successCriterionRequest) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*c = successCriterionRequest{Criterion: text}
+		return nil
+	}
+
+	var object struct {
+		Criterion string                  `json:"criterion"`
+		Metric    *criterionMetricRequest `json:"metric"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+
+	*c = successCriterionRequest{Criterion: object.Criterion, Metric: object.Metric}
+	return nil
+}
+
+type criterionMetricResponse struct {
+	Baseline  float64 `json:"baseline"`
+	Target    float64 `json:"target"`
+	Unit      string  `json:"unit"`
+	Direction string  `json:"direction"`
+}
+
+type goalCriterionResponse struct {
+	ID         string                   `json:"id"`
+	Criterion  string                   `json:"criterion"`
+	Metric     *criterionMetricResponse `json:"metric"`
+	Current    *float64                 `json:"current"`
+	MeasuredAt *string                  `json:"measuredAt"`
+	Progress   *float64                 `json:"progress"`
+	Status     string                   `json:"status"`
+}
+
+type measurementRequest struct {
+	Value      *float64 `json:"value"`
+	MeasuredAt string   `json:"measuredAt"`
+	Note       string   `json:"note"`
+}
+
+type measurementResponse struct {
+	ID          string  `json:"id"`
+	CriterionID string  `json:"criterionId"`
+	Value       float64 `json:"value"`
+	MeasuredAt  string  `json:"measuredAt"`
+	Note        string  `json:"note"`
+	CreatedAt   string  `json:"createdAt"`
+}
+
+type listMeasurementResponse struct {
+	Items []measurementResponse `json:"items"`
+}
+
+// goalCriteria normalises the success criteria of a goal payload, returning
+// their texts and the metric of each measurable one keyed by its text.
+func goalCriteria(payload []successCriterionRequest) ([]string, map[string]keyresult.Metric, error) {
+	texts := make([]string, 0, len(payload))
+	byKey := make(map[string]keyresult.Metric)
+	for _, item := range payload {
+		texts = append(texts, item.Criterion)
+		if item.Metric == nil {
+			continue
+		}
+
+		criterion := strings.TrimSpace(item.Criterion)
+		if item.Metric.Baseline == nil || item.Metric.Target == nil {
+			return nil, nil, fmt.Errorf("success criterion %q: metric baseline and target are required", criterion)
+		}
+
+		metric := keyresult.Metric{
+			Baseline:  *item.Metric.Baseline,
+			Target:    *item.Metric.Target,
+			Unit:      strings.TrimSpace(item.Metric.Unit),
+			Direction: strings.ToLower(strings.TrimSpace(item.Metric.Direction)),
+		}
+		if err := metric.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("success criterion %q: %w", criterion, err)
+		}
+
+		key := strings.ToLower(criterion)
+		if _, ok := byKey[key]; !ok {
+			byKey[key] = metric
+		}
+	}
+
+	criteria := normalizeGoalValues(texts)
+	metrics := make(map[string]keyresult.Metric, len(byKey))
+	for _, criterion := range criteria {
+		if metric, ok := byKey[strings.ToLower(criterion)]; ok {
+			metrics[criterion] = metric
+		}
+	}
+
+	return criteria, metrics, nil
+}
+
+// listCriteria loads the criteria of goals for their responses.
+func (h *goalsHandler) listCriteria(ctx context.Context, goals ...database.Goal) (map[uuid.UUID][]database.GoalCriterion, error) {
+	ids := make([]uuid.UUID, 0, len(goals))
+	for _, goal := range goals {
+		ids = append(ids, goal.ID)
+	}
+	return database.ListGoalCriteria(ctx, h.db, ids)
+}
+
+func (h *goalsHandler) handleMeasurements(w http.ResponseWriter, r *http.Request, id, criterionID string) {
+	goalID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid goal id")
+		return
+	}
+
+	criterion, err := uuid.Parse(criterionID)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid criterion id")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		h.handleRecordMeasurement(w, r, goalID, criterion)
+	case http.MethodGet:
+		h.handleListMeasurements(w, r, goalID, criterion)
+	default:
+		h.methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (h *goalsHandler) handleRecordMeasurement(w http.ResponseWriter, r *http.Request, goalID, criterionID uuid.UUID) {
+	ctx := r.Context()
+
+	var payload measurementRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if payload.Value == nil {
+		writeJSONError(w, http.StatusBadRequest, "value is required")
+		return
+	}
+
+	input := database.CriterionMeasurementInput{
+		Value:      *payload.Value,
+		MeasuredAt: time.Now().UTC(),
+		Note:       strings.TrimSpace(payload.Note),
+	}
+	if value := strings.TrimSpace(payload.MeasuredAt); value != "" {
+		ts, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "measuredAt must be RFC3339 timestamp")
+			return
+		}
+		input.MeasuredAt = ts
+	}
+
+	measurement, err := database.RecordCriterionMeasurement(ctx, h.db, goalID, criterionID, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeJSONError(w, http.StatusNotFound, "criterion not found")
+		case errors.Is(err, database.ErrCriterionHasNoMetric):
+			writeJSONError(w, http.StatusConflict, err.Error())
+		default:
+			h.logger.ErrorContext(ctx, "failed to record measurement", "error", err)
+			writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(toMeasurementResponse(measurement)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *goalsHandler) handleListMeasurements(w http.ResponseWriter, r *http.Request, goalID, criterionID uuid.UUID) {
+	ctx := r.Context()
+
+	measurements, err := database.ListCriterionMeasurements(ctx, h.db, goalID, criterionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "criterion not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to list measurements", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response := listMeasurementResponse{Items: make([]measurementResponse, 0, len(measurements))}
+	for _, measurement := range measurements {
+		response.Items = append(response.Items, toMeasurementResponse(measurement))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+// toGoalCriteriaResponse renders criteria with their progress and the
+// goal's overall health.
+func toGoalCriteriaResponse(criteria []database.GoalCriterion) ([]goalCriterionResponse, string) {
+	responses := make([]goalCriterionResponse, 0, len(criteria))
+	statuses := make([]string, 0, len(criteria))
+	for _, criterion := range criteria {
+		progress := criterion.Progress()
+		statuses = append(statuses, progress.Status)
+
+		response := goalCriterionResponse{
+			ID:        criterion.ID.String(),
+			Criterion: criterion.Criterion,
+			Current:   criterion.Current,
+			Status:    progress.Status,
+		}
+		if criterion.Metric != nil {
+			response.Metric = &criterionMetricResponse{
+				Baseline:  criterion.Metric.Baseline,
+				Target:    criterion.Metric.Target,
+				Unit:      criterion.Metric.Unit,
+				Direction: criterion.Metric.Direction,
+			}
+		}
+		if criterion.MeasuredAt != nil {
+			measuredAt := criterion.MeasuredAt.Format(time.RFC3339)
+			response.MeasuredAt = &measuredAt
+		}
+		if progress.Ratio != nil {
+			ratio := roundTo(*progress.Ratio, 2)
+			response.Progress = &ratio
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, keyresult.Health(statuses)
+}
+
+func toMeasurementResponse(measurement database.CriterionMeasurement) measurementResponse {
+	return measurementResponse{
+		ID:          measurement.ID.String(),
+		CriterionID: measurement.CriterionID.String(),
+		Value:       measurement.Value,
+		MeasuredAt:  measurement.MeasuredAt.Format(time.RFC3339),
+		Note:        measurement.Note,
+		CreatedAt:   measurement.CreatedAt.Format(time.RFC3339),
+	}
+}
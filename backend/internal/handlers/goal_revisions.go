@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/diff"
+	"github.com/google/uuid"
+)
+
+type goalRevisionResponse struct {
+	Revision         int                                `json:"revision"`
+	Title            string                             `json:"title"`
+	ClarityStatement string                             `json:"clarityStatement"`
+	Guardrails       []string                           `json:"guardrails"`
+	DecisionRights   []string                           `json:"decisionRights"`
+	Constraints      []string                           `json:"constraints"`
+	SuccessCriteria  []string                           `json:"successCriteria"`
+	Metrics          map[string]criterionMetricResponse `json:"metrics"`
+	RestoredFrom     *int                               `json:"restoredFrom"`
+	CreatedAt        string                             `json:"createdAt"`
+}
+
+type listGoalRevisionResponse struct {
+	Items []goalRevisionResponse `json:"items"`
+}
+
+type fieldChangeResponse struct {
+	Field     string   `json:"field"`
+	From      *string  `json:"from,omitempty"`
+	To        *string  `json:"to,omitempty"`
+	Added     []string `json:"added,omitempty"`
+	Removed   []string `json:"removed,omitempty"`
+	Reordered bool     `json:"reordered,omitempty"`
+}
+
+type goalRevisionDiffResponse struct {
+	From    int                   `json:"from"`
+	To      int                   `json:"to"`
+	Changes []fieldChangeResponse `json:"changes"`
+}
+
+// handleRevisions serves /api/goals/{id}/revisions and the paths below it:
+// the list, a single revision, the diff between two revisions and restores.
+func (h *goalsHandler) handleRevisions(w http.ResponseWriter, r *http.Request, id, rest string) {
+	goalID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid goal id")
+		return
+	}
+
+	if value, ok := strings.CutSuffix(rest, ":restore"); ok {
+		if r.Method != http.MethodPost {
+			h.methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		revision, ok := parseRevision(w, value)
+		if !ok {
+			return
+		}
+		h.handleRestoreRevision(w, r, goalID, revision)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		h.methodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	switch rest {
+	case "":
+		h.handleListRevisions(w, r, goalID)
+	case "diff":
+		h.handleDiffRevisions(w, r, goalID)
+	default:
+		revision, ok := parseRevision(w, rest)
+		if !ok {
+			return
+		}
+		h.handleGetRevision(w, r, goalID, revision)
+	}
+}
+
+// parseRevision parses a revision number, writing a 400 response when it is
+// not a positive integer.
+func parseRevision(w http.ResponseWriter, value string) (int, bool) {
+	revision, err := strconv.Atoi(value)
+	if err != nil || revision < 1 {
+		writeJSONError(w, http.StatusBadRequest, "revision must be a positive integer")
+		return 0, false
+	}
+	return revision, true
+}
+
+func (h *goalsHandler) handleListRevisions(w http.ResponseWriter, r *http.Request, goalID uuid.UUID) {
+	ctx := r.Context()
+
+	revisions, err := database.ListGoalRevisions(ctx, h.db, goalID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "goal not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to list goal revisions", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response := listGoalRevisionResponse{Items: make([]goalRevisionResponse, 0, len(revisions))}
+	for _, revision := range revisions {
+		response.Items = append(response.Items, toGoalRevisionResponse(revision))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *goalsHandler) handleGetRevision(w http.ResponseWriter, r *http.Request, goalID uuid.UUID, number int) {
+	ctx := r.Context()
+
+	revision, err := database.GetGoalRevision(ctx, h.db, goalID, number)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "revision not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to get goal revision", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toGoalRevisionResponse(revision)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *goalsHandler) handleDiffRevisions(w http.ResponseWriter, r *http.Request, goalID uuid.UUID) {
+	ctx := r.Context()
+
+	numbers := make([]int, 0, 2)
+	for _, param := range []string{"from", "to"} {
+		number, err := strconv.Atoi(strings.TrimSpace(r.URL.Query().Get(param)))
+		if err != nil || number < 1 {
+			writeJSONError(w, http.StatusBadRequest, param+" must be a positive revision number")
+			return
+		}
+		numbers = append(numbers, number)
+	}
+
+	revisions := make([]database.GoalRevision, 0, 2)
+	for _, number := range numbers {
+		revision, err := database.GetGoalRevision(ctx, h.db, goalID, number)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				writeJSONError(w, http.StatusNotFound, "revision not found")
+				return
+			}
+			h.logger.ErrorContext(ctx, "failed to get goal revision", "error", err)
+			writeJSONError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		revisions = append(revisions, revision)
+	}
+
+	changes := database.DiffGoalRevisions(revisions[0], revisions[1])
+	response := goalRevisionDiffResponse{
+		From:    numbers[0],
+		To:      numbers[1],
+		Changes: make([]fieldChangeResponse, 0, len(changes)),
+	}
+	for _, change := range changes {
+		response.Changes = append(response.Changes, toFieldChangeResponse(change))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *goalsHandler) handleRestoreRevision(w http.ResponseWriter, r *http.Request, goalID uuid.UUID, revision int) {
+	ctx := r.Context()
+
+	record, err := database.RestoreGoalRevision(ctx, h.db, goalID, revision)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "revision not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to restore goal revision", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	criteria, err := h.listCriteria(ctx, record)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list goal criteria", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toGoalResponse(record, criteria[record.ID])); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func toGoalRevisionResponse(revision database.GoalRevision) goalRevisionResponse {
+	metrics := make(map[string]criterionMetricResponse, len(revision.Metrics))
+	for criterion, metric := range revision.Metrics {
+		metrics[criterion] = criterionMetricResponse{
+			Baseline:  metric.Baseline,
+			Target:    metric.Target,
+			Unit:      metric.Unit,
+			Direction: metric.Direction,
+		}
+	}
+
+	return goalRevisionResponse{
+		Revision:         revision.Revision,
+		Title:            revision.Title,
+		ClarityStatement: revision.ClarityStatement,
+		Guardrails:       revision.Guardrails,
+		DecisionRights:   revision.DecisionRights,
+		Constraints:      revision.Constraints,
+		SuccessCriteria:  revision.SuccessCriteria,
+		Metrics:          metrics,
+		RestoredFrom:     revision.RestoredFrom,
+		CreatedAt:        revision.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toFieldChangeResponse(change diff.Change) fieldChangeResponse {
+	return fieldChangeResponse{
+		Field:     change.Field,
+		From:      change.From,
+		To:        change.To,
+		Added:     change.Added,
+		Removed:   change.Removed,
+		Reordered: change.Reordered,
+	}
+}
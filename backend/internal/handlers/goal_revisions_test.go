@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestGoalsHandlerRevisionDiff(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id := uuid.New()
+	now := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	columns := []string{"goal_id", "revision", "title", "clarity_statement", "guardrails", "decision_rights", "constraints", "success_criteria", "metrics", "restored_from", "created_at"}
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goal_revisions WHERE goal_id = $1 AND revision = $2")).
+		WithArgs(id, 1).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(id, 1, "Checkout", "Clarity", `["No Friday deploys"]`, `[]`, `[]`, `[]`, `{}`, nil, now))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goal_revisions WHERE goal_id = $1 AND revision = $2")).
+		WithArgs(id, 3).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(id, 3, "Reliable checkout", "Clarity", `["Deploy behind flags"]`, `[]`, `[]`, `[]`, `{}`, nil, now))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/goals/"+id.String()+"/revisions/diff?from=1&to=3", nil)
+	rr := httptest.NewRecorder()
+
+	GoalsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response goalRevisionDiffResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(response.Changes) != 2 || response.Changes[0].Field != "title" || response.Changes[1].Field != "guardrails" {
+		t.Fatalf("unexpected changes: %+v", response.Changes)
+	}
+
+	if added := response.Changes[1].Added; len(added) != 1 || added[0] != "Deploy behind flags" {
+		t.Fatalf("unexpected guardrail additions: %v", added)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func TestGoalsHandlerRestoreUnknownRevision(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goal_revisions WHERE goal_id = $1 AND revision = $2")).
+		WithArgs(id, 9).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/goals/"+id.String()+"/revisions/9:restore", nil)
+	rr := httptest.NewRecorder()
+
+	GoalsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d got %d: %s", http.StatusNotFound, rr.Code, rr.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/goals/"+id.String()+"/revisions/0", nil)
+	rr = httptest.NewRecorder()
+
+	GoalsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
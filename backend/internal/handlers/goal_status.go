@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+type goalTransitionRequest struct {
+	Status  string `json:"status"`
+	Summary string `json:"summary"`
+}
+
+func isGoalStatus(status string) bool {
+	switch status {
+	case database.GoalDraft, database.GoalActive, database.GoalPaused, database.GoalAchieved, database.GoalArchived:
+		return true
+	default:
+		return false
+	}
+}
+
+// handleTransition moves a goal through its lifecycle. Marking a goal
+// achieved requires a closing summary.
+func (h *goalsHandler) handleTransition(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	goalID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid goal id")
+		return
+	}
+
+	var payload goalTransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	status := strings.ToLower(strings.TrimSpace(payload.Status))
+	if !isGoalStatus(status) {
+		writeJSONError(w, http.StatusBadRequest, "status must be draft, active, paused, achieved or archived")
+		return
+	}
+
+	summary := strings.TrimSpace(payload.Summary)
+	if status == database.GoalAchieved && summary == "" {
+		writeJSONError(w, http.StatusBadRequest, "summary is required when a goal is achieved")
+		return
+	}
+
+	record, err := database.TransitionGoal(ctx, h.db, goalID, status, summary)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeJSONError(w, http.StatusNotFound, "goal not found")
+		case errors.Is(err, database.ErrGoalTransition):
+			writeJSONError(w, http.StatusConflict, err.Error())
+		default:
+			h.logger.ErrorContext(ctx, "failed to transition goal", "error", err)
+			writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	criteria, err := h.listCriteria(ctx, record)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list goal criteria", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toGoalResponse(record, criteria[record.ID])); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
@@ -14,29 +14,61 @@ import (
 )
 
 type createGoalRequest struct {
-	Title            string   `json:"title"`
-	ClarityStatement string   `json:"clarityStatement"`
-	Guardrails       []string `json:"guardrails"`
-	DecisionRights   []string `json:"decisionRights"`
-	Constraints      []string `json:"constraints"`
-	SuccessCriteria  []string `json:"successCriteria"`
+	Title            string                    `json:"title"`
+	ClarityStatement string                    `json:"clarityStatement"`
+	Guardrails       []string                  `json:"guardrails"`
+	DecisionRights   []string                  `json:"decisionRights"`
+	Constraints      []string                  `json:"constraints"`
+	SuccessCriteria  []successCriterionRequest `json:"successCriteria"`
+	ParentGoalID     *string                   `json:"parentGoalId"`
+	Status           string                    `json:"status"`
+	Tags             []string                  `json:"tags"`
 }
 
 type goalResponse struct {
-	ID               string   `json:"id"`
-	Title            string   `json:"title"`
-	ClarityStatement string   `json:"clarityStatement"`
-	Guardrails       []string `json:"guardrails"`
-	DecisionRights   []string `json:"decisionRights"`
-	Constraints      []string `json:"constraints"`
-	SuccessCriteria  []string `json:"successCriteria"`
-	CreatedAt        string   `json:"createdAt"`
-	UpdatedAt        string   `json:"updatedAt"`
+	ID               string                  `json:"id"`
+	Title            string                  `json:"title"`
+	ClarityStatement string                  `json:"clarityStatement"`
+	Guardrails       []string                `json:"guardrails"`
+	DecisionRights   []string                `json:"decisionRights"`
+	Constraints      []string                `json:"constraints"`
+	SuccessCriteria  []string                `json:"successCriteria"`
+	Criteria         []goalCriterionResponse `json:"criteria"`
+	Health           string                  `json:"health"`
+	ParentGoalID     *string                 `json:"parentGoalId"`
+	Status           string                  `json:"status"`
+	ClosingSummary   string                  `json:"closingSummary"`
+	AchievedAt       *string                 `json:"achievedAt"`
+	Tags             []string                `json:"tags"`
+	CreatedAt        string                  `json:"createdAt"`
+	UpdatedAt        string                  `json:"updatedAt"`
+}
+
+type goalProgressResponse struct {
+	Intents           int     `json:"intents"`
+	Outcomes          int     `json:"outcomes"`
+	EvidenceLinks     int     `json:"evidenceLinks"`
+	CriteriaTotal     int     `json:"criteriaTotal"`
+	CriteriaSatisfied int     `json:"criteriaSatisfied"`
+	Ratio             float64 `json:"ratio"`
+}
+
+type goalTreeNodeResponse struct {
+	goalResponse
+	Progress goalProgressResponse   `json:"progress"`
+	Rollup   goalProgressResponse   `json:"rollup"`
+	Children []goalTreeNodeResponse `json:"children"`
+}
+
+type goalTreeResponse struct {
+	Ancestors []goalResponse       `json:"ancestors"`
+	Goal      goalTreeNodeResponse `json:"goal"`
 }
 
 type listGoalResponse struct {
 	Items      []goalResponse     `json:"items"`
 	Pagination paginationResponse `json:"pagination"`
+	Facets     goalFacetsResponse `json:"facets"`
 }
 
 type goalsHandler struct {
@@ -56,12 +88,52 @@ func (h *goalsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case r.Method == http.MethodGet && r.URL.Path == "/api/goals":
 		h.handleList(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/goals/"):
-		id := strings.TrimPrefix(r.URL.Path, "/api/goals/")
+		id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/goals/"), "/")
 		if id == "" {
 			http.NotFound(w, r)
 			return
 		}
 
+		if rest, ok := strings.CutPrefix(action, "criteria/"); ok {
+			criterionID, sub, _ := strings.Cut(rest, "/")
+			if criterionID == "" || sub != "measurements" {
+				http.NotFound(w, r)
+				return
+			}
+			h.handleMeasurements(w, r, id, criterionID)
+			return
+		}
+
+		if action == "revisions" || strings.HasPrefix(action, "revisions/") {
+			h.handleRevisions(w, r, id, strings.TrimPrefix(strings.TrimPrefix(action, "revisions"), "/"))
+			return
+		}
+
+		if action != "" {
+			if action == "status" {
+				if r.Method != http.MethodPost {
+					h.methodNotAllowed(w, http.MethodPost)
+					return
+				}
+				h.handleTransition(w, r, id)
+				return
+			}
+			if action != "missing-outcomes" && action != "tree" {
+				http.NotFound(w, r)
+				return
+			}
+			if r.Method != http.MethodGet {
+				h.methodNotAllowed(w, http.MethodGet)
+				return
+			}
+			if action == "tree" {
+				h.handleTree(w, r, id)
+				return
+			}
+			h.handleMissingOutcomes(w, r, id)
+			return
+		}
+
 		switch r.Method {
 		case http.MethodGet:
 			h.handleRetrieve(w, r, id)
@@ -89,6 +161,20 @@ func validateGoalPayload(payload createGoalRequest) error {
 	return nil
 }
 
+// parseParentGoalID reads the optional parentGoalId of a goal payload.
+func parseParentGoalID(value *string) (*uuid.UUID, error) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil, nil
+	}
+
+	parsed, err := uuid.Parse(strings.TrimSpace(*value))
+	if err != nil {
+		return nil, errors.New("parentGoalId must be a valid goal id")
+	}
+
+	return &parsed, nil
+}
+
 func normalizeGoalValues(values []string) []string {
 	if len(values) == 0 {
 		return []string{}
@@ -128,10 +214,28 @@ func (h *goalsHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	status := strings.ToLower(strings.TrimSpace(payload.Status))
+	switch status {
+	case "", database.GoalDraft, database.GoalActive:
+	default:
+		writeJSONError(w, http.StatusBadRequest, "status must be draft or active")
+		return
+	}
+
+	parentGoalID, err := parseParentGoalID(payload.ParentGoalID)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	cleanedGuardrails := normalizeGoalValues(payload.Guardrails)
 	cleanedDecisionRights := normalizeGoalValues(payload.DecisionRights)
 	cleanedConstraints := normalizeGoalValues(payload.Constraints)
-	cleanedSuccess := normalizeGoalValues(payload.SuccessCriteria)
+	cleanedSuccess, metrics, err := goalCriteria(payload.SuccessCriteria)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	record, err := database.CreateGoal(ctx, h.db, database.GoalInput{
 		Title:            strings.TrimSpace(payload.Title),
@@ -140,14 +244,28 @@ func (h *goalsHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 		DecisionRights:   cleanedDecisionRights,
 		Constraints:      cleanedConstraints,
 		SuccessCriteria:  cleanedSuccess,
+		Metrics:          metrics,
+		ParentGoalID:     parentGoalID,
+		Status:           status,
+		Tags:             normalizeTags(payload.Tags),
 	})
 	if err != nil {
+		if writeGoalHierarchyError(w, err) {
+			return
+		}
 		h.logger.ErrorContext(ctx, "failed to persist goal", "error", err)
 		writeJSONError(w, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
-	response := toGoalResponse(record)
+	criteria, err := h.listCriteria(ctx, record)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list goal criteria", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response := toGoalResponse(record, criteria[record.ID])
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -173,7 +291,14 @@ func (h *goalsHandler) handleList(w http.ResponseWriter, r *http.Request) {
 	}
 
 	filters := database.GoalFilters{
-		Query: strings.TrimSpace(r.URL.Query().Get("q")),
+		Query:           strings.TrimSpace(r.URL.Query().Get("q")),
+		Status:          strings.ToLower(strings.TrimSpace(r.URL.Query().Get("status"))),
+		IncludeArchived: r.URL.Query().Get("includeArchived") == "true",
+	}
+
+	if filters.Status != "" && !isGoalStatus(filters.Status) {
+		writeJSONError(w, http.StatusBadRequest, "status must be draft, active, paused, achieved or archived")
+		return
 	}
 
 	if value := strings.TrimSpace(r.URL.Query().Get("createdAfter")); value != "" {
@@ -194,6 +319,13 @@ func (h *goalsHandler) handleList(w http.ResponseWriter, r *http.Request) {
 		filters.CreatedBefore = &ts
 	}
 
+	tags, match, err := parseTagFilter(r.URL.Query())
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filters.Tags, filters.TagMatch = tags, match
+
 	offset := (page - 1) * pageSize
 
 	result, err := database.ListGoals(ctx, h.db, filters, database.Pagination{Limit: pageSize, Offset: offset})
@@ -208,9 +340,16 @@ func (h *goalsHandler) handleList(w http.ResponseWriter, r *http.Request) {
 		totalPages = (result.TotalCount + pageSize - 1) / pageSize
 	}
 
+	criteria, err := h.listCriteria(ctx, result.Goals...)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list goal criteria", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
 	responses := make([]goalResponse, 0, len(result.Goals))
 	for _, goal := range result.Goals {
-		responses = append(responses, toGoalResponse(goal))
+		responses = append(responses, toGoalResponse(goal, criteria[goal.ID]))
 	}
 
 	payload := listGoalResponse{
@@ -221,6 +360,10 @@ func (h *goalsHandler) handleList(w http.ResponseWriter, r *http.Request) {
 			TotalItems: result.TotalCount,
 			TotalPages: totalPages,
 		},
+		Facets: goalFacetsResponse{
+			Tags:     toFacetCountResponses(result.Facets.Tags),
+			Statuses: toFacetCountResponses(result.Facets.Statuses),
+		},
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -249,8 +392,15 @@ func (h *goalsHandler) handleRetrieve(w http.ResponseWriter, r *http.Request, id
 		return
 	}
 
+	criteria, err := h.listCriteria(ctx, record)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list goal criteria", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(toGoalResponse(record)); err != nil {
+	if err := json.NewEncoder(w).Encode(toGoalResponse(record, criteria[record.ID])); err != nil {
 		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
 	}
 }
@@ -277,10 +427,20 @@ func (h *goalsHandler) handleUpdate(w http.ResponseWriter, r *http.Request, id s
 		return
 	}
 
+	parentGoalID, err := parseParentGoalID(payload.ParentGoalID)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	cleanedGuardrails := normalizeGoalValues(payload.Guardrails)
 	cleanedDecisionRights := normalizeGoalValues(payload.DecisionRights)
 	cleanedConstraints := normalizeGoalValues(payload.Constraints)
-	cleanedSuccess := normalizeGoalValues(payload.SuccessCriteria)
+	cleanedSuccess, metrics, err := goalCriteria(payload.SuccessCriteria)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	record, err := database.UpdateGoal(ctx, h.db, uuidValue, database.GoalInput{
 		Title:            strings.TrimSpace(payload.Title),
@@ -289,19 +449,32 @@ func (h *goalsHandler) handleUpdate(w http.ResponseWriter, r *http.Request, id s
 		DecisionRights:   cleanedDecisionRights,
 		Constraints:      cleanedConstraints,
 		SuccessCriteria:  cleanedSuccess,
+		Metrics:          metrics,
+		ParentGoalID:     parentGoalID,
+		Tags:             normalizeTags(payload.Tags),
 	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeJSONError(w, http.StatusNotFound, "goal not found")
 			return
 		}
+		if writeGoalHierarchyError(w, err) {
+			return
+		}
 		h.logger.ErrorContext(ctx, "failed to update goal", "error", err)
 		writeJSONError(w, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
+	criteria, err := h.listCriteria(ctx, record)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list goal criteria", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(toGoalResponse(record)); err != nil {
+	if err := json.NewEncoder(w).Encode(toGoalResponse(record, criteria[record.ID])); err != nil {
 		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
 	}
 }
@@ -315,11 +488,20 @@ func (h *goalsHandler) handleDelete(w http.ResponseWriter, r *http.Request, id s
 		return
 	}
 
-	if err := database.DeleteGoal(ctx, h.db, uuidValue); err != nil {
+	children := strings.TrimSpace(r.URL.Query().Get("children"))
+	if children != "" && children != database.GoalChildrenReparent && children != database.GoalChildrenCascade {
+		writeJSONError(w, http.StatusBadRequest, "children must be reparent or cascade")
+		return
+	}
+
+	if err := database.DeleteGoal(ctx, h.db, uuidValue, children); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeJSONError(w, http.StatusNotFound, "goal not found")
 			return
 		}
+		if writeGoalHierarchyError(w, err) {
+			return
+		}
 		h.logger.ErrorContext(ctx, "failed to delete goal", "error", err)
 		writeJSONError(w, http.StatusInternalServerError, "internal server error")
 		return
@@ -328,12 +510,81 @@ func (h *goalsHandler) handleDelete(w http.ResponseWriter, r *http.Request, id s
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *goalsHandler) handleTree(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	uuidValue, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid goal id")
+		return
+	}
+
+	tree, err := database.GetGoalTree(ctx, h.db, uuidValue)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "goal not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to retrieve goal tree", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	goals := append([]database.Goal{}, tree.Ancestors...)
+	var collect func(node database.GoalTreeNode)
+	collect = func(node database.GoalTreeNode) {
+		goals = append(goals, node.Goal)
+		for _, child := range node.Children {
+			collect(child)
+		}
+	}
+	collect(tree.Root)
+
+	criteria, err := h.listCriteria(ctx, goals...)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list goal criteria", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	ancestors := make([]goalResponse, 0, len(tree.Ancestors))
+	for _, goal := range tree.Ancestors {
+		ancestors = append(ancestors, toGoalResponse(goal, criteria[goal.ID]))
+	}
+
+	response := goalTreeResponse{
+		Ancestors: ancestors,
+		Goal:      toGoalTreeNodeResponse(tree.Root, criteria),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+// writeGoalHierarchyError maps parent and child goal errors to responses,
+// reporting whether err was one of them.
+func writeGoalHierarchyError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, database.ErrParentGoalNotFound):
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, database.ErrGoalCycle), errors.Is(err, database.ErrGoalHasChildren):
+		writeJSONError(w, http.StatusConflict, err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
 func (h *goalsHandler) methodNotAllowed(w http.ResponseWriter, allowed ...string) {
 	w.Header().Set("Allow", strings.Join(allowed, ", "))
 	writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
 }
 
-func toGoalResponse(goal database.Goal) goalResponse {
+func toGoalResponse(goal database.Goal, criteria []database.GoalCriterion) goalResponse {
+	criteriaResponses, health := toGoalCriteriaResponse(criteria)
+
 	return goalResponse{
 		ID:               goal.ID.String(),
 		Title:            goal.Title,
@@ -342,7 +593,39 @@ func toGoalResponse(goal database.Goal) goalResponse {
 		DecisionRights:   goal.DecisionRights,
 		Constraints:      goal.Constraints,
 		SuccessCriteria:  goal.SuccessCriteria,
+		Criteria:         criteriaResponses,
+		Health:           health,
+		ParentGoalID:     formatOptionalUUID(goal.ParentGoalID),
+		Status:           goal.Status,
+		ClosingSummary:   goal.ClosingSummary,
+		AchievedAt:       formatOptionalTime(goal.AchievedAt),
+		Tags:             goal.Tags,
 		CreatedAt:        goal.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:        goal.UpdatedAt.Format(time.RFC3339),
 	}
 }
+
+func toGoalTreeNodeResponse(node database.GoalTreeNode, criteria map[uuid.UUID][]database.GoalCriterion) goalTreeNodeResponse {
+	children := make([]goalTreeNodeResponse, 0, len(node.Children))
+	for _, child := range node.Children {
+		children = append(children, toGoalTreeNodeResponse(child, criteria))
+	}
+
+	return goalTreeNodeResponse{
+		goalResponse: toGoalResponse(node.Goal, criteria[node.Goal.ID]),
+		Progress:     toGoalProgressResponse(node.Progress),
+		Rollup:       toGoalProgressResponse(node.Rollup),
+		Children:     children,
+	}
+}
+
+func toGoalProgressResponse(progress database.GoalProgress) goalProgressResponse {
+	return goalProgressResponse{
+		Intents:           progress.Intents,
+		Outcomes:          progress.Outcomes,
+		EvidenceLinks:     progress.EvidenceLinks,
+		CriteriaTotal:     progress.CriteriaTotal,
+		CriteriaSatisfied: progress.CriteriaSatisfied,
+		Ratio:             roundTo(progress.Ratio(), 2),
+	}
+}
@@ -11,9 +11,15 @@ import (
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/intent/backend/internal/database"
 	"github.com/google/uuid"
 )
 
+func expectGoalHierarchyLock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_xact_lock($1)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
 func TestGoalsHandlerCreateSuccess(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -37,9 +43,15 @@ func TestGoalsHandlerCreateSuccess(t *testing.T) {
 		t.Fatalf("failed to marshal payload: %v", err)
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectExec("INSERT INTO goals").
-		WithArgs(sqlmock.AnyArg(), payload["title"], payload["clarityStatement"], `["Respect freeze window"]`, `["Launch toggles"]`, `["Protect member focus time"]`, `["Checklist published"]`, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(sqlmock.AnyArg(), payload["title"], payload["clarityStatement"], `["Respect freeze window"]`, `["Launch toggles"]`, `["Protect member focus time"]`, `["Checklist published"]`, nil, "active", sqlmock.AnyArg(), sqlmock.AnyArg(), "[]").
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectGoalCriteriaSync(mock, 1)
+	expectGoalRevision(mock)
+	expectWebhookEvent(mock, database.EventGoalCreated)
+	mock.ExpectCommit()
+	expectGoalCriteria(mock)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/goals", bytes.NewReader(body))
 	rr := httptest.NewRecorder()
@@ -135,14 +147,18 @@ func TestGoalsHandlerListSuccess(t *testing.T) {
 	updatedAt := createdAt
 	id := uuid.New()
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM goals WHERE (title ILIKE $1 OR clarity_statement ILIKE $2 OR guardrails::text ILIKE $3 OR decision_rights::text ILIKE $4 OR success_criteria::text ILIKE $5 OR constraints::text ILIKE $6)")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM goals WHERE (title ILIKE $1 OR clarity_statement ILIKE $2 OR guardrails::text ILIKE $3 OR decision_rights::text ILIKE $4 OR success_criteria::text ILIKE $5 OR constraints::text ILIKE $6) AND status <> 'archived'")).
 		WithArgs(pattern, pattern, pattern, pattern, pattern, pattern).
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, created_at, updated_at FROM goals WHERE (title ILIKE $1 OR clarity_statement ILIKE $2 OR guardrails::text ILIKE $3 OR decision_rights::text ILIKE $4 OR success_criteria::text ILIKE $5 OR constraints::text ILIKE $6) ORDER BY created_at DESC LIMIT $7")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, parent_goal_id, created_at, updated_at, status, closing_summary, achieved_at, tags FROM goals WHERE (title ILIKE $1 OR clarity_statement ILIKE $2 OR guardrails::text ILIKE $3 OR decision_rights::text ILIKE $4 OR success_criteria::text ILIKE $5 OR constraints::text ILIKE $6) AND status <> 'archived' ORDER BY created_at DESC LIMIT $7")).
 		WithArgs(pattern, pattern, pattern, pattern, pattern, pattern, 20).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "clarity_statement", "guardrails", "decision_rights", "constraints", "success_criteria", "created_at", "updated_at"}).
-			AddRow(id, "Goal", "Clarity", `["Guardrail"]`, `["Decide"]`, `["Guardrail"]`, `["Outcome"]`, createdAt, updatedAt))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "clarity_statement", "guardrails", "decision_rights", "constraints", "success_criteria", "parent_goal_id", "created_at", "updated_at", "status", "closing_summary", "achieved_at", "tags"}).
+			AddRow(id, "Goal", "Clarity", `["Guardrail"]`, `["Decide"]`, `["Guardrail"]`, `["Outcome"]`, nil, createdAt, updatedAt, "active", nil, nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT 'status', COALESCE(status::text, ''), COUNT(*) FROM goals WHERE (title ILIKE $1")).
+		WithArgs(pattern, pattern, pattern, pattern, pattern, pattern).
+		WillReturnRows(sqlmock.NewRows([]string{"facet", "value", "count"}).AddRow(database.FacetStatus, "active", 1))
+	expectGoalCriteria(mock)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/goals?q=focus", nil)
 	rr := httptest.NewRecorder()
@@ -192,7 +208,7 @@ func TestGoalsHandlerRetrieveNotFound(t *testing.T) {
 	logger := testLogger(t)
 	id := uuid.New()
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, created_at, updated_at FROM goals WHERE id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, parent_goal_id, created_at, updated_at, status, closing_summary, achieved_at, tags FROM goals WHERE id = $1")).
 		WithArgs(id).
 		WillReturnError(sql.ErrNoRows)
 
@@ -237,6 +253,7 @@ func TestGoalsHandlerUpdateSuccess(t *testing.T) {
 		t.Fatalf("failed to marshal payload: %v", err)
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE goals
 SET title = $1,
     clarity_statement = $2,
@@ -244,12 +261,19 @@ SET title = $1,
     decision_rights = $4,
     constraints = $5,
     success_criteria = $6,
-    updated_at = $7
-WHERE id = $8
-RETURNING id, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, created_at, updated_at`)).
-		WithArgs(payload["title"], payload["clarityStatement"], `["Guardrail"]`, `["Decide"]`, `["Guardrail"]`, `["Outcome"]`, sqlmock.AnyArg(), id).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "clarity_statement", "guardrails", "decision_rights", "constraints", "success_criteria", "created_at", "updated_at"}).
-			AddRow(id, payload["title"], payload["clarityStatement"], `["Guardrail"]`, `["Decide"]`, `["Guardrail"]`, `["Outcome"]`, createdAt, updatedAt))
+    parent_goal_id = $7,
+    tags = $8,
+    updated_at = $9
+WHERE id = $10
+RETURNING id, title, clarity_statement, guardrails, decision_rights, constraints, success_criteria, parent_goal_id, created_at, updated_at, status, closing_summary, achieved_at, tags`)).
+		WithArgs(payload["title"], payload["clarityStatement"], `["Guardrail"]`, `["Decide"]`, `["Guardrail"]`, `["Outcome"]`, nil, "[]", sqlmock.AnyArg(), id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "clarity_statement", "guardrails", "decision_rights", "constraints", "success_criteria", "parent_goal_id", "created_at", "updated_at", "status", "closing_summary", "achieved_at", "tags"}).
+			AddRow(id, payload["title"], payload["clarityStatement"], `["Guardrail"]`, `["Decide"]`, `["Guardrail"]`, `["Outcome"]`, nil, createdAt, updatedAt, "active", nil, nil, nil))
+	expectGoalCriteriaSync(mock, 1)
+	expectGoalRevision(mock)
+	expectWebhookEvent(mock, database.EventGoalUpdated)
+	mock.ExpectCommit()
+	expectGoalCriteria(mock)
 
 	req := httptest.NewRequest(http.MethodPut, "/api/goals/"+id.String(), bytes.NewReader(body))
 	rr := httptest.NewRecorder()
@@ -288,9 +312,16 @@ func TestGoalsHandlerDeleteSuccess(t *testing.T) {
 	logger := testLogger(t)
 	id := uuid.New()
 
+	mock.ExpectBegin()
+	expectGoalHierarchyLock(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM goals WHERE parent_goal_id = $1")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 	mock.ExpectExec("DELETE FROM goals").
 		WithArgs(id).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectWebhookEvent(mock, database.EventGoalDeleted)
+	mock.ExpectCommit()
 
 	req := httptest.NewRequest(http.MethodDelete, "/api/goals/"+id.String(), nil)
 	rr := httptest.NewRecorder()
@@ -307,6 +338,439 @@ func TestGoalsHandlerDeleteSuccess(t *testing.T) {
 	}
 }
 
+func TestGoalsHandlerDeleteWithChildrenRequiresChoice(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id := uuid.New()
+
+	mock.ExpectBegin()
+	expectGoalHierarchyLock(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM goals WHERE parent_goal_id = $1")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/goals/"+id.String(), nil)
+	rr := httptest.NewRecorder()
+
+	GoalsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status %d got %d", http.StatusConflict, rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/goals/"+id.String()+"?children=orphan", nil)
+	rr = httptest.NewRecorder()
+
+	GoalsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d for unknown choice got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func TestGoalsHandlerUpdateRejectsCycle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id, childID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	expectGoalHierarchyLock(mock)
+	mock.ExpectQuery(regexp.QuoteMeta("WITH RECURSIVE ancestors")).
+		WithArgs(childID, id).
+		WillReturnRows(sqlmock.NewRows([]string{"found", "cycle"}).AddRow(1, 1))
+	mock.ExpectRollback()
+
+	body, _ := json.Marshal(map[string]any{
+		"title":            "Reliable checkout",
+		"clarityStatement": "Checkout survives provider outages",
+		"parentGoalId":     childID.String(),
+	})
+
+	req := httptest.NewRequest(http.MethodPut, "/api/goals/"+id.String(), bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	GoalsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status %d got %d: %s", http.StatusConflict, rr.Code, rr.Body.String())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func TestGoalsHandlerTree(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	parentID, id, childID := uuid.New(), uuid.New(), uuid.New()
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	columns := []string{"id", "title", "clarity_statement", "guardrails", "decision_rights", "constraints", "success_criteria", "parent_goal_id", "created_at", "updated_at", "status", "closing_summary", "achieved_at", "tags"}
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goals WHERE id = $1")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(id, "Checkout", "Clarity", `[]`, `[]`, `[]`, `["Retries"]`, parentID, now, now, "active", nil, nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM ancestors a")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(parentID, "Enterprise", "Clarity", `[]`, `[]`, `[]`, `[]`, nil, now, now, "active", nil, nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM descendants d")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(childID, "Retry budget", "Clarity", `[]`, `[]`, `[]`, `["Budget set"]`, id, now, now, "active", nil, nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goals g")).
+		WithArgs(nil, nil, nil, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_goal_id", "title", "success_criteria", "intents", "outcomes", "links", "sessions", "satisfied"}).
+			AddRow(id, parentID, "Checkout", `["Retries"]`, 2, 1, 0, 1, `["Retries"]`).
+			AddRow(childID, id, "Retry budget", `["Budget set"]`, 1, 0, 0, 1, `[]`))
+	expectGoalCriteria(mock)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/goals/"+id.String()+"/tree", nil)
+	rr := httptest.NewRecorder()
+
+	GoalsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response struct {
+		Ancestors []struct {
+			ID string `json:"id"`
+		} `json:"ancestors"`
+		Goal struct {
+			ID           string `json:"id"`
+			ParentGoalID string `json:"parentGoalId"`
+			Rollup       struct {
+				Intents int     `json:"intents"`
+				Ratio   float64 `json:"ratio"`
+			} `json:"rollup"`
+			Children []struct {
+				ID string `json:"id"`
+			} `json:"children"`
+		} `json:"goal"`
+	}
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+
+	if len(response.Ancestors) != 1 || response.Ancestors[0].ID != parentID.String() || response.Goal.ParentGoalID != parentID.String() {
+		t.Fatalf("unexpected ancestors %+v", response)
+	}
+
+	if len(response.Goal.Children) != 1 || response.Goal.Children[0].ID != childID.String() {
+		t.Fatalf("unexpected children %+v", response.Goal.Children)
+	}
+
+	if response.Goal.Rollup.Intents != 3 || response.Goal.Rollup.Ratio != 0.5 {
+		t.Fatalf("unexpected rollup %+v", response.Goal.Rollup)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func TestGoalsHandlerUpdateWithMetric(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id, criterionID := uuid.New(), uuid.New()
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	measuredAt := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+
+	body := []byte(`{
+		"title": "Raise test coverage",
+		"clarityStatement": "Regressions keep reaching production",
+		"successCriteria": [
+			{"criterion": "Coverage above 80%", "metric": {"baseline": 60, "target": 80, "unit": "%", "direction": "increase"}},
+			"Flaky tests quarantined"
+		]
+	}`)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE goals").
+		WithArgs("Raise test coverage", "Regressions keep reaching production", `[]`, `[]`, `[]`, `["Coverage above 80%","Flaky tests quarantined"]`, nil, "[]", sqlmock.AnyArg(), id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "clarity_statement", "guardrails", "decision_rights", "constraints", "success_criteria", "parent_goal_id", "created_at", "updated_at", "status", "closing_summary", "achieved_at", "tags"}).
+			AddRow(id, "Raise test coverage", "Regressions keep reaching production", `[]`, `[]`, `[]`, `["Coverage above 80%","Flaky tests quarantined"]`, nil, now, now, "active", nil, nil, nil))
+	mock.ExpectExec("INSERT INTO goal_criteria").
+		WithArgs(sqlmock.AnyArg(), id, "Coverage above 80%", 0, 60.0, 80.0, "%", "increase", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO goal_criteria").
+		WithArgs(sqlmock.AnyArg(), id, "Flaky tests quarantined", 1, nil, nil, nil, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM goal_criteria")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO goal_revisions")).
+		WithArgs(id, "Raise test coverage", "Regressions keep reaching production", `[]`, `[]`, `[]`, `["Coverage above 80%","Flaky tests quarantined"]`, `{"Coverage above 80%":{"baseline":60,"target":80,"unit":"%","direction":"increase"}}`, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectWebhookEvent(mock, database.EventGoalUpdated)
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goal_criteria c")).
+		WithArgs("{" + id.String() + "}").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "goal_id", "criterion", "baseline", "target", "unit", "direction", "current", "measured_at", "previous"}).
+			AddRow(criterionID, id, "Coverage above 80%", 60.0, 80.0, "%", "increase", 70.0, measuredAt, nil).
+			AddRow(uuid.New(), id, "Flaky tests quarantined", nil, nil, nil, nil, nil, nil, nil))
+
+	req := httptest.NewRequest(http.MethodPut, "/api/goals/"+id.String(), bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	GoalsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response struct {
+		Criteria []struct {
+			ID       string   `json:"id"`
+			Progress *float64 `json:"progress"`
+			Status   string   `json:"status"`
+		} `json:"criteria"`
+		Health string `json:"health"`
+	}
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+
+	if len(response.Criteria) != 2 || response.Criteria[0].ID != criterionID.String() || response.Criteria[0].Progress == nil || *response.Criteria[0].Progress != 0.5 {
+		t.Fatalf("unexpected criteria %+v", response.Criteria)
+	}
+
+	if response.Criteria[1].Status != "no_metric" || response.Health != "on_track" {
+		t.Fatalf("unexpected health %q and statuses %+v", response.Health, response.Criteria)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func TestGoalsHandlerCreateRejectsInvalidMetric(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	body := []byte(`{
+		"title": "Cut error rate",
+		"clarityStatement": "Customers see too many errors",
+		"successCriteria": [{"criterion": "Error rate under 1%", "metric": {"baseline": 4, "target": 1, "direction": "increase"}}]
+	}`)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/goals", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	GoalsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d got %d: %s", http.StatusBadRequest, rr.Code, rr.Body.String())
+	}
+}
+
+func TestGoalsHandlerRecordMeasurement(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	goalID, criterionID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT direction IS NOT NULL FROM goal_criteria")).
+		WithArgs(criterionID, goalID).
+		WillReturnRows(sqlmock.NewRows([]string{"measurable"}).AddRow(true))
+	mock.ExpectExec("INSERT INTO goal_criterion_measurements").
+		WithArgs(sqlmock.AnyArg(), criterionID, 72.5, time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC), "Nightly run", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	body := []byte(`{"value": 72.5, "measuredAt": "2026-10-12T09:00:00Z", "note": " Nightly run "}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/goals/"+goalID.String()+"/criteria/"+criterionID.String()+"/measurements", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	GoalsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d got %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+
+	var response struct {
+		CriterionID string  `json:"criterionId"`
+		Value       float64 `json:"value"`
+	}
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+
+	if response.CriterionID != criterionID.String() || response.Value != 72.5 {
+		t.Fatalf("unexpected measurement %+v", response)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func TestGoalsHandlerRecordMeasurementWithoutMetric(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	goalID, criterionID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT direction IS NOT NULL FROM goal_criteria")).
+		WithArgs(criterionID, goalID).
+		WillReturnRows(sqlmock.NewRows([]string{"measurable"}).AddRow(false))
+	mock.ExpectRollback()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/goals/"+goalID.String()+"/criteria/"+criterionID.String()+"/measurements", bytes.NewReader([]byte(`{"value": 1}`)))
+	rr := httptest.NewRecorder()
+
+	GoalsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status %d got %d: %s", http.StatusConflict, rr.Code, rr.Body.String())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func TestGoalsHandlerTransitionToAchieved(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id := uuid.New()
+	now := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM goals WHERE id = $1 FOR UPDATE")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("active"))
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE goals")).
+		WithArgs("achieved", "Retries shipped and error rate halved", sqlmock.AnyArg(), id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "clarity_statement", "guardrails", "decision_rights", "constraints", "success_criteria", "parent_goal_id", "created_at", "updated_at", "status", "closing_summary", "achieved_at", "tags"}).
+			AddRow(id, "Reliable checkout", "Clarity", `[]`, `[]`, `[]`, `[]`, nil, now, now, "achieved", "Retries shipped and error rate halved", now, nil))
+	expectWebhookEvent(mock, database.EventGoalUpdated)
+	mock.ExpectCommit()
+	expectGoalCriteria(mock)
+
+	body, _ := json.Marshal(map[string]string{"status": "achieved", "summary": " Retries shipped and error rate halved "})
+	req := httptest.NewRequest(http.MethodPost, "/api/goals/"+id.String()+"/status", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	GoalsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response goalResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if response.Status != "achieved" || response.ClosingSummary != "Retries shipped and error rate halved" || response.AchievedAt == nil {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func TestGoalsHandlerTransitionValidation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id := uuid.New()
+
+	for _, payload := range []map[string]string{
+		{"status": "achieved"},
+		{"status": "finished"},
+	} {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, "/api/goals/"+id.String()+"/status", bytes.NewReader(body))
+		rr := httptest.NewRecorder()
+
+		GoalsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%v: expected status %d got %d", payload, http.StatusBadRequest, rr.Code)
+		}
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM goals WHERE id = $1 FOR UPDATE")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("draft"))
+	mock.ExpectRollback()
+
+	body, _ := json.Marshal(map[string]string{"status": "paused"})
+	req := httptest.NewRequest(http.MethodPost, "/api/goals/"+id.String()+"/status", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	GoalsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status %d got %d: %s", http.StatusConflict, rr.Code, rr.Body.String())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func expectGoalCriteriaSync(mock sqlmock.Sqlmock, criteria int) {
+	for i := 0; i < criteria; i++ {
+		mock.ExpectExec("INSERT INTO goal_criteria").WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM goal_criteria")).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectGoalRevision(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO goal_revisions")).WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func expectGoalCriteria(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goal_criteria c")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "goal_id", "criterion", "baseline", "target", "unit", "direction", "current", "measured_at", "previous"}))
+}
+
 func TestNormalizeGoalValues(t *testing.T) {
 	input := []string{" focus ", "FOCUS", "", "Guard"}
 	got := normalizeGoalValues(input)
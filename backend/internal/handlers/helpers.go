@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+
+	"github.com/example/intent/backend/internal/database"
 )
 
 type paginationResponse struct {
@@ -32,3 +34,38 @@ func parsePositiveInt(value string, fallback int) int {
 
 	return parsed
 }
+
+type capacityViolationResponse struct {
+	Error     string            `json:"error"`
+	Violation capacityViolation `json:"violation"`
+}
+
+type capacityViolation struct {
+	Cap       string `json:"cap"`
+	Limit     int    `json:"limit"`
+	Current   int    `json:"current"`
+	Requested int    `json:"requested"`
+	SessionID string `json:"sessionId"`
+	ChapterID string `json:"chapterId"`
+	MemberID  string `json:"memberId,omitempty"`
+}
+
+// writeCapacityError responds with 409 Conflict and a structured explanation
+// of the capacity guardrail that was hit.
+func writeCapacityError(w http.ResponseWriter, capErr *database.CapacityError) {
+	violation := capacityViolation{
+		Cap:       capErr.Cap,
+		Limit:     capErr.Limit,
+		Current:   capErr.Current,
+		Requested: capErr.Requested,
+		SessionID: capErr.SessionID.String(),
+		ChapterID: capErr.ChapterID.String(),
+	}
+	if capErr.MemberID != nil {
+		violation.MemberID = capErr.MemberID.String()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	_ = json.NewEncoder(w).Encode(capacityViolationResponse{Error: capErr.Error(), Violation: violation})
+}
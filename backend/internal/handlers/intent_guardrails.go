@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+type guardrailAcknowledgmentResponse struct {
+	GoalID         string   `json:"goalId"`
+	GoalRevision   int      `json:"goalRevision"`
+	Guardrails     []string `json:"guardrails"`
+	AcknowledgedAt string   `json:"acknowledgedAt"`
+}
+
+type intentGuardrailsResponse struct {
+	Acknowledgment      *guardrailAcknowledgmentResponse `json:"acknowledgment"`
+	NeedsAcknowledgment bool                             `json:"needsAcknowledgment"`
+}
+
+// intentResponses renders intents with the guardrail state of those that
+// serve a goal.
+func (h *intentsHandler) intentResponses(ctx context.Context, intents ...database.Intent) ([]intentResponse, error) {
+	ids := make([]uuid.UUID, 0, len(intents))
+	for _, intent := range intents {
+		if intent.GoalID != nil {
+			ids = append(ids, intent.ID)
+		}
+	}
+
+	states, err := database.ListIntentGuardrails(ctx, h.db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]intentResponse, 0, len(intents))
+	for _, intent := range intents {
+		response := toIntentResponse(intent)
+		if state, ok := states[intent.ID]; ok {
+			response.Guardrails = &intentGuardrailsResponse{NeedsAcknowledgment: state.NeedsAcknowledgment}
+			if state.Latest != nil {
+				acknowledgment := toGuardrailAcknowledgmentResponse(*state.Latest)
+				response.Guardrails.Acknowledgment = &acknowledgment
+			}
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
+
+func (h *intentsHandler) handleAcknowledgeGuardrails(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	intentID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid intent id")
+		return
+	}
+
+	acknowledgment, err := database.AcknowledgeIntentGuardrails(ctx, h.db, intentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeJSONError(w, http.StatusNotFound, "intent not found")
+		case errors.Is(err, database.ErrIntentHasNoGoal):
+			writeJSONError(w, http.StatusConflict, err.Error())
+		default:
+			h.logger.ErrorContext(ctx, "failed to acknowledge guardrails", "error", err)
+			writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(toGuardrailAcknowledgmentResponse(acknowledgment)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func toGuardrailAcknowledgmentResponse(acknowledgment database.GuardrailAcknowledgment) guardrailAcknowledgmentResponse {
+	guardrails := acknowledgment.Guardrails
+	if guardrails == nil {
+		guardrails = []string{}
+	}
+
+	return guardrailAcknowledgmentResponse{
+		GoalID:         acknowledgment.GoalID.String(),
+		GoalRevision:   acknowledgment.GoalRevision,
+		Guardrails:     guardrails,
+		AcknowledgedAt: acknowledgment.AcknowledgedAt.Format(time.RFC3339),
+	}
+}
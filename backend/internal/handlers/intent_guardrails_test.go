@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestIntentsHandlerRetrieveIncludesGuardrails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id, goalID := uuid.New(), uuid.New()
+	createdAt := time.Date(2024, 5, 6, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM intents WHERE id = $1")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags"}).
+			AddRow(id, "statement", "context", "outcome", `[]`, "active", nil, goalID, nil, createdAt, `{"blocks":3}`, nil, nil, nil, nil))
+	mock.ExpectQuery("a.guardrails = g.guardrails").
+		WithArgs("{" + id.String() + "}").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "needs_acknowledgment", "id", "goal_id", "goal_revision", "guardrails", "acknowledged_at"}).
+			AddRow(id, true, uuid.New(), goalID, 1, `["No new vendors"]`, createdAt))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/intents/"+id.String(), nil)
+	rr := httptest.NewRecorder()
+
+	IntentsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var response intentResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if response.Timebox == nil || response.Timebox.Blocks != 3 {
+		t.Fatalf("expected a three block timebox got %+v", response.Timebox)
+	}
+	if response.Guardrails == nil || !response.Guardrails.NeedsAcknowledgment || response.Guardrails.Acknowledgment.GoalRevision != 1 {
+		t.Fatalf("expected stale acknowledgment at revision 1 got %+v", response.Guardrails)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func TestIntentsHandlerAcknowledgeGuardrails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id, goalID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT goal_id FROM intents WHERE id = $1 FOR UPDATE")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"goal_id"}).AddRow(goalID))
+	mock.ExpectQuery("INSERT INTO intent_guardrail_acknowledgments").
+		WithArgs(sqlmock.AnyArg(), id, goalID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"goal_revision", "guardrails"}).AddRow(4, `["No schema changes"]`))
+	mock.ExpectCommit()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/intents/"+id.String()+"/acknowledge-guardrails", nil)
+	rr := httptest.NewRecorder()
+
+	IntentsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var response guardrailAcknowledgmentResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if response.GoalRevision != 4 || len(response.Guardrails) != 1 {
+		t.Fatalf("unexpected acknowledgment %+v", response)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func TestCreateIntentHandlerTimeboxValidation(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		timebox any
+	}{
+		{name: "missing"},
+		{name: "empty", timebox: map[string]any{}},
+		{name: "both", timebox: map[string]any{"blocks": 2, "sessionIds": []string{uuid.NewString()}}},
+		{name: "negative", timebox: map[string]any{"blocks": -1}},
+		{name: "invalid session", timebox: map[string]any{"sessionIds": []string{"nope"}}},
+	} {
+		payload := map[string]any{
+			"statement":       "I intend to add checkout retries.",
+			"context":         "Provider outages drop orders.",
+			"expectedOutcome": "Retries behind a flag.",
+		}
+		if tc.timebox != nil {
+			payload["timebox"] = tc.timebox
+		}
+		body, _ := json.Marshal(payload)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/intents", bytes.NewReader(body))
+		rr := httptest.NewRecorder()
+
+		CreateIntentHandler(testLogger(t), nil).ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected status 400 got %d", tc.name, rr.Code)
+		}
+	}
+}
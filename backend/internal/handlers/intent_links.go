@@ -0,0 +1,453 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/tracker"
+	"github.com/google/uuid"
+)
+
+type createIntentLinkRequest struct {
+	Kind  string `json:"kind"`
+	URL   string `json:"url"`
+	Title string `json:"title"`
+}
+
+type createIssueRequest struct {
+	Key string `json:"key"`
+}
+
+type intentLinkResponse struct {
+	ID             string  `json:"id"`
+	IntentID       string  `json:"intentId"`
+	Kind           string  `json:"kind"`
+	URL            string  `json:"url"`
+	Title          string  `json:"title"`
+	Tracker        string  `json:"tracker,omitempty"`
+	ExternalKey    string  `json:"externalKey,omitempty"`
+	ExternalStatus string  `json:"externalStatus,omitempty"`
+	SyncedAt       *string `json:"syncedAt"`
+	CreatedAt      string  `json:"createdAt"`
+}
+
+type listIntentLinkResponse struct {
+	Items []intentLinkResponse `json:"items"`
+}
+
+type statusSuggestionResponse struct {
+	ID              string  `json:"id"`
+	IntentID        string  `json:"intentId"`
+	LinkID          *string `json:"linkId"`
+	SuggestedStatus string  `json:"suggestedStatus"`
+	Reason          string  `json:"reason"`
+	CreatedAt       string  `json:"createdAt"`
+	ResolvedAt      *string `json:"resolvedAt"`
+	Resolution      string  `json:"resolution,omitempty"`
+}
+
+type listStatusSuggestionResponse struct {
+	Items []statusSuggestionResponse `json:"items"`
+}
+
+type syncIntentLinkResponse struct {
+	Link       intentLinkResponse        `json:"link"`
+	Suggestion *statusSuggestionResponse `json:"suggestion"`
+}
+
+type resolveStatusSuggestionResponse struct {
+	Suggestion statusSuggestionResponse `json:"suggestion"`
+	Intent     *intentResponse          `json:"intent"`
+}
+
+// routeLinks serves /api/intents/{id}/links, /issue and
+// /status-suggestions and the routes beneath them.
+func (h *intentsHandler) routeLinks(w http.ResponseWriter, r *http.Request, id, action string) {
+	intentID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid intent id")
+		return
+	}
+
+	parts := strings.Split(action, "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "links":
+		switch r.Method {
+		case http.MethodGet:
+			h.handleListLinks(w, r, intentID)
+		case http.MethodPost:
+			h.handleCreateLink(w, r, intentID)
+		default:
+			h.methodNotAllowed(w, http.MethodGet, http.MethodPost)
+		}
+	case len(parts) == 1 && parts[0] == "issue":
+		if r.Method != http.MethodPost {
+			h.methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		h.handleCreateIssue(w, r, intentID)
+	case len(parts) == 1 && parts[0] == "status-suggestions":
+		if r.Method != http.MethodGet {
+			h.methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		h.handleListSuggestions(w, r, intentID)
+	case len(parts) == 2 && parts[0] == "links":
+		if r.Method != http.MethodDelete {
+			h.methodNotAllowed(w, http.MethodDelete)
+			return
+		}
+		h.handleDeleteLink(w, r, intentID, parts[1])
+	case len(parts) == 3 && parts[0] == "links" && parts[2] == "sync":
+		if r.Method != http.MethodPost {
+			h.methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		h.handleSyncLink(w, r, intentID, parts[1])
+	case len(parts) == 3 && parts[0] == "status-suggestions" && (parts[2] == "accept" || parts[2] == "dismiss"):
+		if r.Method != http.MethodPost {
+			h.methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		h.handleResolveSuggestion(w, r, intentID, parts[1], parts[2] == "accept")
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func parseIntentLinkPayload(payload createIntentLinkRequest) (database.IntentLinkInput, error) {
+	kind := strings.TrimSpace(payload.Kind)
+	if !slices.Contains(database.LinkKinds, kind) {
+		return database.IntentLinkInput{}, errors.New("kind must be one of issue, doc, pr, dashboard")
+	}
+
+	parsed, err := url.Parse(strings.TrimSpace(payload.URL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return database.IntentLinkInput{}, errors.New("url must be an absolute http or https URL")
+	}
+
+	return database.IntentLinkInput{
+		Kind:  kind,
+		URL:   parsed.String(),
+		Title: strings.TrimSpace(payload.Title),
+	}, nil
+}
+
+func (h *intentsHandler) handleListLinks(w http.ResponseWriter, r *http.Request, intentID uuid.UUID) {
+	ctx := r.Context()
+
+	links, err := database.ListIntentLinks(ctx, h.db, intentID)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list intent links", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	responses := make([]intentLinkResponse, 0, len(links))
+	for _, link := range links {
+		responses = append(responses, toIntentLinkResponse(link))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listIntentLinkResponse{Items: responses}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *intentsHandler) handleCreateLink(w http.ResponseWriter, r *http.Request, intentID uuid.UUID) {
+	ctx := r.Context()
+
+	var payload createIntentLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid intent link payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input, err := parseIntentLinkPayload(payload)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	link, err := database.CreateIntentLink(ctx, h.db, intentID, input)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			writeJSONError(w, http.StatusNotFound, "intent not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to create intent link", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(toIntentLinkResponse(link)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+// handleCreateIssue links the intent to an issue in the tracker: the issue
+// named by key when one is given, otherwise a new issue created from the
+// intent.
+func (h *intentsHandler) handleCreateIssue(w http.ResponseWriter, r *http.Request, intentID uuid.UUID) {
+	ctx := r.Context()
+
+	if h.tracker == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "no work tracker is configured")
+		return
+	}
+
+	var payload createIssueRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			h.logger.WarnContext(ctx, "invalid issue payload", "error", err)
+			writeJSONError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	intent, err := database.GetIntent(ctx, h.db, intentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "intent not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to retrieve intent", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	links, err := database.ListIntentLinks(ctx, h.db, intentID)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list intent links", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	for _, link := range links {
+		if link.Tracker == h.tracker.Name() {
+			writeJSONError(w, http.StatusConflict, "intent is already linked to "+link.ExternalKey)
+			return
+		}
+	}
+
+	var issue tracker.Issue
+	if key := strings.TrimSpace(payload.Key); key != "" {
+		issue, err = h.tracker.GetIssue(ctx, key)
+	} else {
+		issue, err = h.tracker.CreateIssue(ctx, tracker.IssueFromIntent(intent))
+	}
+	if err != nil {
+		if errors.Is(err, tracker.ErrNotFound) {
+			writeJSONError(w, http.StatusBadRequest, "issue not found in tracker")
+			return
+		}
+		h.logger.ErrorContext(ctx, "tracker request failed", "tracker", h.tracker.Name(), "error", err)
+		writeJSONError(w, http.StatusBadGateway, "work tracker request failed")
+		return
+	}
+
+	link, err := database.CreateIntentLink(ctx, h.db, intentID, database.IntentLinkInput{
+		Kind:           database.LinkIssue,
+		URL:            issue.URL,
+		Title:          issue.Key,
+		Tracker:        h.tracker.Name(),
+		ExternalKey:    issue.Key,
+		ExternalStatus: issue.Status,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			writeJSONError(w, http.StatusConflict, "intent is already linked to an issue in this tracker")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to link issue", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	h.logger.InfoContext(ctx, "intent linked to issue", "intent_id", intentID, "issue", issue.Key)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(toIntentLinkResponse(link)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *intentsHandler) handleDeleteLink(w http.ResponseWriter, r *http.Request, intentID uuid.UUID, id string) {
+	ctx := r.Context()
+
+	linkID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid link id")
+		return
+	}
+
+	if err := database.DeleteIntentLink(ctx, h.db, intentID, linkID); err != nil {
+		h.writeLinkError(ctx, w, err, "failed to delete intent link")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *intentsHandler) handleSyncLink(w http.ResponseWriter, r *http.Request, intentID uuid.UUID, id string) {
+	ctx := r.Context()
+
+	linkID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid link id")
+		return
+	}
+
+	if h.tracker == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "no work tracker is configured")
+		return
+	}
+
+	link, err := database.GetIntentLink(ctx, h.db, intentID, linkID)
+	if err != nil {
+		h.writeLinkError(ctx, w, err, "failed to retrieve intent link")
+		return
+	}
+
+	if link.Tracker != h.tracker.Name() || link.ExternalKey == "" {
+		writeJSONError(w, http.StatusBadRequest, "link is not an issue in the configured tracker")
+		return
+	}
+
+	synced, suggestion, err := tracker.Sync(ctx, h.db, h.tracker, link)
+	if err != nil {
+		if errors.Is(err, tracker.ErrNotFound) {
+			writeJSONError(w, http.StatusBadGateway, "issue no longer exists in tracker")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to sync intent link", "link_id", linkID, "error", err)
+		writeJSONError(w, http.StatusBadGateway, "work tracker request failed")
+		return
+	}
+
+	response := syncIntentLinkResponse{Link: toIntentLinkResponse(synced)}
+	if suggestion != nil {
+		converted := toStatusSuggestionResponse(*suggestion)
+		response.Suggestion = &converted
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *intentsHandler) handleListSuggestions(w http.ResponseWriter, r *http.Request, intentID uuid.UUID) {
+	ctx := r.Context()
+
+	pendingOnly := false
+	switch r.URL.Query().Get("state") {
+	case "", "all":
+	case "pending":
+		pendingOnly = true
+	default:
+		writeJSONError(w, http.StatusBadRequest, "state must be pending or all")
+		return
+	}
+
+	suggestions, err := database.ListStatusSuggestions(ctx, h.db, intentID, pendingOnly)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list status suggestions", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	responses := make([]statusSuggestionResponse, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		responses = append(responses, toStatusSuggestionResponse(suggestion))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listStatusSuggestionResponse{Items: responses}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *intentsHandler) handleResolveSuggestion(w http.ResponseWriter, r *http.Request, intentID uuid.UUID, id string, accept bool) {
+	ctx := r.Context()
+
+	suggestionID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid suggestion id")
+		return
+	}
+
+	suggestion, intent, err := database.ResolveStatusSuggestion(ctx, h.db, intentID, suggestionID, accept)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrSuggestionResolved), errors.Is(err, database.ErrDraftNeedsTimebox), errors.Is(err, database.ErrGuardrailsNotAcknowledged):
+			writeJSONError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			writeJSONError(w, http.StatusNotFound, "status suggestion not found")
+		default:
+			h.logger.ErrorContext(ctx, "failed to resolve status suggestion", "error", err)
+			writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	response := resolveStatusSuggestionResponse{Suggestion: toStatusSuggestionResponse(suggestion)}
+	if intent != nil {
+		converted := toIntentResponse(*intent)
+		response.Intent = &converted
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *intentsHandler) writeLinkError(ctx context.Context, w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, sql.ErrNoRows) {
+		writeJSONError(w, http.StatusNotFound, "link not found")
+		return
+	}
+	h.logger.ErrorContext(ctx, message, "error", err)
+	writeJSONError(w, http.StatusInternalServerError, "internal server error")
+}
+
+func toIntentLinkResponse(link database.IntentLink) intentLinkResponse {
+	return intentLinkResponse{
+		ID:             link.ID.String(),
+		IntentID:       link.IntentID.String(),
+		Kind:           link.Kind,
+		URL:            link.URL,
+		Title:          link.Title,
+		Tracker:        link.Tracker,
+		ExternalKey:    link.ExternalKey,
+		ExternalStatus: link.ExternalStatus,
+		SyncedAt:       formatOptionalTime(link.SyncedAt),
+		CreatedAt:      link.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toStatusSuggestionResponse(suggestion database.StatusSuggestion) statusSuggestionResponse {
+	return statusSuggestionResponse{
+		ID:              suggestion.ID.String(),
+		IntentID:        suggestion.IntentID.String(),
+		LinkID:          formatOptionalUUID(suggestion.LinkID),
+		SuggestedStatus: suggestion.SuggestedStatus,
+		Reason:          suggestion.Reason,
+		CreatedAt:       suggestion.CreatedAt.Format(time.RFC3339),
+		ResolvedAt:      formatOptionalTime(suggestion.ResolvedAt),
+		Resolution:      suggestion.Resolution,
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/tracker"
+	"github.com/google/uuid"
+)
+
+// stubTracker creates every issue as PLAT-1 in To Do.
+type stubTracker struct {
+	created []tracker.IssueInput
+}
+
+func (s *stubTracker) Name() string { return "jira" }
+
+func (s *stubTracker) CreateIssue(ctx context.Context, input tracker.IssueInput) (tracker.Issue, error) {
+	s.created = append(s.created, input)
+	return tracker.Issue{Key: "PLAT-1", URL: "https://jira.example.com/browse/PLAT-1", Status: "To Do", Category: tracker.CategoryToDo}, nil
+}
+
+func (s *stubTracker) GetIssue(ctx context.Context, key string) (tracker.Issue, error) {
+	return tracker.Issue{}, tracker.ErrNotFound
+}
+
+func TestIntentsHandlerCreateLinkRejectsUnknownKind(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	body := []byte(`{"kind":"wiki","url":"https://docs.example.com/rfc"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/intents/"+uuid.NewString()+"/links", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	IntentsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestIntentsHandlerCreateIssueWithoutTracker(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	req := httptest.NewRequest(http.MethodPost, "/api/intents/"+uuid.NewString()+"/issue", nil)
+	rr := httptest.NewRecorder()
+
+	IntentsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+}
+
+func TestIntentsHandlerCreateIssueLinksNewIssue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	intentID := uuid.New()
+	now := time.Now().UTC()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM intents")).
+		WithArgs(intentID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags"}).
+			AddRow(intentID, "Cut checkout latency", "p95 is 900ms", "p95 under 300ms", `[]`, database.IntentActive, nil, nil, nil, now, nil, nil, nil, nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM intent_links WHERE intent_id = $1")).
+		WithArgs(intentID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "intent_id", "kind", "url", "title", "tracker", "external_key", "external_status", "synced_at", "created_at"}))
+	mock.ExpectExec("INSERT INTO intent_links").
+		WithArgs(sqlmock.AnyArg(), intentID, database.LinkIssue, "https://jira.example.com/browse/PLAT-1", "PLAT-1", "jira", "PLAT-1", "To Do", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	stub := &stubTracker{}
+	req := httptest.NewRequest(http.MethodPost, "/api/intents/"+intentID.String()+"/issue", nil)
+	rr := httptest.NewRecorder()
+
+	TrackedIntentsHandler(testLogger(t), db, stub).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d got %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+
+	var response intentLinkResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+
+	if response.ExternalKey != "PLAT-1" || response.ExternalStatus != "To Do" || response.SyncedAt == nil {
+		t.Fatalf("unexpected response %+v", response)
+	}
+
+	if len(stub.created) != 1 || stub.created[0].Summary != "Cut checkout latency" || stub.created[0].Description != "p95 is 900ms\n\nExpected outcome: p95 under 300ms" {
+		t.Fatalf("unexpected issue input %+v", stub.created)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
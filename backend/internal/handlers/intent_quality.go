@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/quality"
+	"github.com/google/uuid"
+)
+
+type intentQualityResponse struct {
+	IntentID          string               `json:"intentId"`
+	Score             int                  `json:"score"`
+	Breakdown         []quality.RuleResult `json:"breakdown"`
+	CoachingQuestions []string             `json:"coachingQuestions"`
+	ChapterID         *string              `json:"chapterId"`
+	EvaluatedAt       string               `json:"evaluatedAt"`
+}
+
+type intentQualityRulesRequest struct {
+	Rules []quality.Rule `json:"rules"`
+}
+
+type intentQualityRulesResponse struct {
+	ChapterID string         `json:"chapterId"`
+	Rules     []quality.Rule `json:"rules"`
+	Custom    bool           `json:"custom"`
+	UpdatedAt *string        `json:"updatedAt"`
+}
+
+// scoreQuality rescores an intent after it changed. The score is advisory,
+// so a failure is logged rather than surfaced to the caller.
+func (h *intentsHandler) scoreQuality(ctx context.Context, intent database.Intent) {
+	if _, err := database.ScoreIntentQuality(ctx, h.db, intent); err != nil {
+		h.logger.WarnContext(ctx, "failed to score intent quality", "error", err, "intent_id", intent.ID)
+	}
+}
+
+// handleQuality serves GET /api/intents/{id}/quality. Intents stored before
+// scoring existed are scored on first request.
+func (h *intentsHandler) handleQuality(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	intentID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid intent id")
+		return
+	}
+
+	record, err := database.GetIntentQuality(ctx, h.db, intentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		var intent database.Intent
+		intent, err = database.GetIntent(ctx, h.db, intentID)
+		if err == nil {
+			record, err = database.ScoreIntentQuality(ctx, h.db, intent)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "intent not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to load intent quality", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toIntentQualityResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+// routeQualityRules serves /api/chapters/{id}/intent-quality-rules.
+func (h *chaptersHandler) routeQualityRules(w http.ResponseWriter, r *http.Request, id string) {
+	chapterID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid chapter id")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.handleGetQualityRules(w, r, chapterID)
+	case http.MethodPut:
+		h.handlePutQualityRules(w, r, chapterID)
+	case http.MethodDelete:
+		h.handleDeleteQualityRules(w, r, chapterID)
+	default:
+		h.methodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodDelete)
+	}
+}
+
+func (h *chaptersHandler) handleGetQualityRules(w http.ResponseWriter, r *http.Request, chapterID uuid.UUID) {
+	ctx := r.Context()
+
+	config, err := database.GetIntentQualityRules(ctx, h.db, chapterID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "chapter not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to load intent quality rules", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toIntentQualityRulesResponse(config)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *chaptersHandler) handlePutQualityRules(w http.ResponseWriter, r *http.Request, chapterID uuid.UUID) {
+	ctx := r.Context()
+
+	var payload intentQualityRulesRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid intent quality rules payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := quality.Validate(payload.Rules); err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	config, err := database.PutIntentQualityRules(ctx, h.db, chapterID, payload.Rules)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			writeJSONError(w, http.StatusNotFound, "chapter not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to save intent quality rules", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toIntentQualityRulesResponse(config)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *chaptersHandler) handleDeleteQualityRules(w http.ResponseWriter, r *http.Request, chapterID uuid.UUID) {
+	ctx := r.Context()
+
+	if err := database.DeleteIntentQualityRules(ctx, h.db, chapterID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "chapter has no custom quality rules")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to reset intent quality rules", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toIntentQualityResponse(record database.IntentQuality) intentQualityResponse {
+	return intentQualityResponse{
+		IntentID:          record.IntentID.String(),
+		Score:             record.Score,
+		Breakdown:         record.Breakdown,
+		CoachingQuestions: record.Questions,
+		ChapterID:         formatOptionalUUID(record.ChapterID),
+		EvaluatedAt:       record.EvaluatedAt.Format(time.RFC3339),
+	}
+}
+
+func toIntentQualityRulesResponse(config database.IntentQualityRules) intentQualityRulesResponse {
+	response := intentQualityRulesResponse{
+		ChapterID: config.ChapterID.String(),
+		Rules:     config.Rules,
+		Custom:    config.Custom,
+	}
+
+	if config.UpdatedAt != nil {
+		formatted := config.UpdatedAt.Format(time.RFC3339)
+		response.UpdatedAt = &formatted
+	}
+
+	return response
+}
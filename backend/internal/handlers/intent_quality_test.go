@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+// expectQualityScore expects an intent to be scored with the default rules.
+func expectQualityScore(mock sqlmock.Sqlmock, intentID any) {
+	mock.ExpectQuery("FROM intent_quality_rules").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec("INSERT INTO intent_quality").
+		WithArgs(intentID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+func TestIntentsHandlerQualityScoresUnscoredIntent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id := uuid.New()
+
+	mock.ExpectQuery("FROM intent_quality").
+		WithArgs(id).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM intents WHERE id = $1")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags"}).
+			AddRow(id, "Improve deploys", "Deploys are slow.", "Faster deploys.", `[]`, "active", nil, nil, nil, time.Now(), nil, nil, nil, nil, nil))
+	expectQualityScore(mock, id)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/intents/"+id.String()+"/quality", nil)
+	rr := httptest.NewRecorder()
+
+	IntentsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var response intentQualityResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if response.Score != 0 || len(response.CoachingQuestions) != len(response.Breakdown) || len(response.Breakdown) == 0 {
+		t.Fatalf("expected every rule to be missed got %+v", response)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func TestIntentsHandlerQualityReturnsStoredScore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id, chapterID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery("FROM intent_quality").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"intent_id", "score", "breakdown", "questions", "chapter_id", "evaluated_at"}).
+			AddRow(id, 60, []byte(`[{"ruleId":"goal-linked","label":"Linked to a goal","weight":40,"passed":false}]`), []byte(`["Which goal does this intent move forward?"]`), chapterID, time.Now()))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/intents/"+id.String()+"/quality", nil)
+	rr := httptest.NewRecorder()
+
+	IntentsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var response intentQualityResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if response.Score != 60 || response.ChapterID == nil || *response.ChapterID != chapterID.String() {
+		t.Fatalf("unexpected quality %+v", response)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func TestChaptersHandlerPutQualityRulesValidates(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	chapterID := uuid.New()
+	body := `{"rules":[{"id":"context","field":"context","check":"minWords","weight":10,"question":"Why now?"}]}`
+
+	req := httptest.NewRequest(http.MethodPut, "/api/chapters/"+chapterID.String()+"/intent-quality-rules", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	ChaptersHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 got %d", rr.Code)
+	}
+
+	mock.ExpectExec("INSERT INTO intent_quality_rules").
+		WithArgs(chapterID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	body = `{"rules":[{"id":"context","field":"context","check":"minWords","min":20,"weight":10,"question":"Why now?"}]}`
+	req = httptest.NewRequest(http.MethodPut, "/api/chapters/"+chapterID.String()+"/intent-quality-rules", strings.NewReader(body))
+	rr = httptest.NewRecorder()
+
+	ChaptersHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var response intentQualityRulesResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if !response.Custom || len(response.Rules) != 1 || response.Rules[0].Min != 20 {
+		t.Fatalf("unexpected rules %+v", response)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
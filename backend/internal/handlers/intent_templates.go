@@ -0,0 +1,479 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/placeholder"
+	"github.com/google/uuid"
+)
+
+type intentTemplateRequest struct {
+	ChapterID       string   `json:"chapterId"`
+	Kind            string   `json:"kind"`
+	Name            string   `json:"name"`
+	Statement       string   `json:"statement"`
+	Context         string   `json:"context"`
+	ExpectedOutcome string   `json:"expectedOutcome"`
+	NeededSkills    []string `json:"neededSkills"`
+	TimeboxBlocks   *int     `json:"timeboxBlocks"`
+	GoalID          string   `json:"goalId"`
+}
+
+type intentTemplateResponse struct {
+	ID        string  `json:"id"`
+	ChapterID *string `json:"chapterId"`
+	Kind      string  `json:"kind"`
+	Name      string  `json:"name"`
+	intentTemplateContentResponse
+	Version   int    `json:"version"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+type intentTemplateContentResponse struct {
+	Statement       string   `json:"statement"`
+	Context         string   `json:"context"`
+	ExpectedOutcome string   `json:"expectedOutcome"`
+	NeededSkills    []string `json:"neededSkills"`
+	TimeboxBlocks   *int     `json:"timeboxBlocks"`
+	GoalID          *string  `json:"goalId"`
+	Variables       []string `json:"variables"`
+}
+
+type listIntentTemplateResponse struct {
+	Items []intentTemplateResponse `json:"items"`
+}
+
+type intentTemplateVersionResponse struct {
+	Version int `json:"version"`
+	intentTemplateContentResponse
+	CreatedAt string `json:"createdAt"`
+}
+
+type listIntentTemplateVersionResponse struct {
+	Items []intentTemplateVersionResponse `json:"items"`
+}
+
+// intentFromTemplateRequest instantiates a template version, the latest
+// when Version is zero. Fields left empty fall back to the template.
+type intentFromTemplateRequest struct {
+	TemplateID            string            `json:"templateId"`
+	Version               int               `json:"version"`
+	Variables             map[string]string `json:"variables"`
+	Collaborators         []string          `json:"collaborators"`
+	Status                string            `json:"status"`
+	MemberID              string            `json:"memberId"`
+	GoalID                string            `json:"goalId"`
+	SessionID             string            `json:"sessionId"`
+	Timebox               *timeboxRequest   `json:"timebox"`
+	NeededSkills          []string          `json:"neededSkills"`
+	Tags                  []string          `json:"tags"`
+	AcknowledgeGuardrails bool              `json:"acknowledgeGuardrails"`
+}
+
+type intentTemplatesHandler struct {
+	logger *slog.Logger
+	db     *sql.DB
+}
+
+// IntentTemplatesHandler routes CRUDL operations for intent templates.
+func IntentTemplatesHandler(logger *slog.Logger, db *sql.DB) http.Handler {
+	return &intentTemplatesHandler{logger: logger, db: db}
+}
+
+func (h *intentTemplatesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/intent-templates":
+		h.handleCreate(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/api/intent-templates":
+		h.handleList(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/intent-templates/"):
+		id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/intent-templates/"), "/")
+		if id == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		templateID, err := uuid.Parse(id)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid intent template id")
+			return
+		}
+
+		if action == "versions" {
+			if r.Method != http.MethodGet {
+				h.methodNotAllowed(w, http.MethodGet)
+				return
+			}
+			h.handleListVersions(w, r, templateID)
+			return
+		}
+
+		if action != "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			h.handleRetrieve(w, r, templateID)
+		case http.MethodPut:
+			h.handleUpdate(w, r, templateID)
+		case http.MethodDelete:
+			h.handleDelete(w, r, templateID)
+		default:
+			h.methodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodDelete)
+		}
+	default:
+		h.methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+// normalizeTemplateKind turns a kind such as "Design Review" into its slug,
+// design-review.
+func normalizeTemplateKind(kind string) string {
+	return strings.Join(strings.Fields(strings.ToLower(kind)), "-")
+}
+
+func parseIntentTemplatePayload(payload intentTemplateRequest) (database.IntentTemplateInput, error) {
+	input := database.IntentTemplateInput{
+		Kind: normalizeTemplateKind(payload.Kind),
+		Name: strings.TrimSpace(payload.Name),
+		IntentTemplateContent: database.IntentTemplateContent{
+			Statement:       strings.TrimSpace(payload.Statement),
+			Context:         strings.TrimSpace(payload.Context),
+			ExpectedOutcome: strings.TrimSpace(payload.ExpectedOutcome),
+			NeededSkills:    normalizeCollaborators(payload.NeededSkills),
+			TimeboxBlocks:   payload.TimeboxBlocks,
+		},
+	}
+
+	if input.Kind == "" {
+		return database.IntentTemplateInput{}, errors.New("kind is required")
+	}
+
+	if input.Name == "" {
+		return database.IntentTemplateInput{}, errors.New("name is required")
+	}
+
+	if input.Statement == "" {
+		return database.IntentTemplateInput{}, errors.New("statement is required")
+	}
+
+	if input.TimeboxBlocks != nil && *input.TimeboxBlocks < 1 {
+		return database.IntentTemplateInput{}, errors.New("timeboxBlocks must be positive")
+	}
+
+	refs := []struct {
+		value string
+		dest  **uuid.UUID
+		field string
+	}{
+		{payload.ChapterID, &input.ChapterID, "chapterId"},
+		{payload.GoalID, &input.GoalID, "goalId"},
+	}
+
+	for _, ref := range refs {
+		value := strings.TrimSpace(ref.value)
+		if value == "" {
+			continue
+		}
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			return database.IntentTemplateInput{}, errors.New(ref.field + " must be a valid id")
+		}
+		*ref.dest = &parsed
+	}
+
+	return input, nil
+}
+
+func (h *intentTemplatesHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var payload intentTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid intent template payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input, err := parseIntentTemplatePayload(payload)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	record, err := database.CreateIntentTemplate(ctx, h.db, input)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			writeJSONError(w, http.StatusBadRequest, "referenced chapter or goal does not exist")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to persist intent template", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(toIntentTemplateResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *intentTemplatesHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	filters := database.IntentTemplateFilters{
+		Kind: normalizeTemplateKind(r.URL.Query().Get("kind")),
+	}
+
+	if value := strings.TrimSpace(r.URL.Query().Get("chapter")); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "chapter must be a valid chapter id")
+			return
+		}
+		filters.ChapterID = &parsed
+	}
+
+	templates, err := database.ListIntentTemplates(ctx, h.db, filters)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list intent templates", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	responses := make([]intentTemplateResponse, 0, len(templates))
+	for _, template := range templates {
+		responses = append(responses, toIntentTemplateResponse(template))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listIntentTemplateResponse{Items: responses}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *intentTemplatesHandler) handleRetrieve(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	ctx := r.Context()
+
+	record, err := database.GetIntentTemplate(ctx, h.db, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "intent template not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to retrieve intent template", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toIntentTemplateResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *intentTemplatesHandler) handleUpdate(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	ctx := r.Context()
+
+	var payload intentTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid intent template payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input, err := parseIntentTemplatePayload(payload)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	record, err := database.UpdateIntentTemplate(ctx, h.db, id, input)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "intent template not found")
+			return
+		}
+		if isForeignKeyViolation(err) {
+			writeJSONError(w, http.StatusBadRequest, "referenced goal does not exist")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to update intent template", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toIntentTemplateResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *intentTemplatesHandler) handleDelete(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	ctx := r.Context()
+
+	if err := database.DeleteIntentTemplate(ctx, h.db, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "intent template not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to delete intent template", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *intentTemplatesHandler) handleListVersions(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	ctx := r.Context()
+
+	versions, err := database.ListIntentTemplateVersions(ctx, h.db, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "intent template not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to list intent template versions", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	responses := make([]intentTemplateVersionResponse, 0, len(versions))
+	for _, version := range versions {
+		responses = append(responses, intentTemplateVersionResponse{
+			Version:                       version.Version,
+			intentTemplateContentResponse: toIntentTemplateContentResponse(version.IntentTemplateContent),
+			CreatedAt:                     version.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listIntentTemplateVersionResponse{Items: responses}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *intentTemplatesHandler) methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+// handleCreateFromTemplate serves POST /api/intents:fromTemplate, filling
+// the template's placeholders from the given variables.
+func (h *intentsHandler) handleCreateFromTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var payload intentFromTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid intent template payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	templateID, err := uuid.Parse(strings.TrimSpace(payload.TemplateID))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "templateId must be a valid intent template id")
+		return
+	}
+
+	if payload.Version < 0 {
+		writeJSONError(w, http.StatusBadRequest, "version must be positive")
+		return
+	}
+
+	template, err := database.GetIntentTemplateVersion(ctx, h.db, templateID, payload.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "intent template not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to retrieve intent template", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	request := createIntentRequest{
+		Collaborators:         payload.Collaborators,
+		Status:                payload.Status,
+		MemberID:              payload.MemberID,
+		GoalID:                payload.GoalID,
+		SessionID:             payload.SessionID,
+		Timebox:               payload.Timebox,
+		NeededSkills:          payload.NeededSkills,
+		Tags:                  payload.Tags,
+		AcknowledgeGuardrails: payload.AcknowledgeGuardrails,
+	}
+
+	missing := make([]string, 0)
+	for _, field := range []struct {
+		text string
+		dest *string
+	}{
+		{template.Statement, &request.Statement},
+		{template.Context, &request.Context},
+		{template.ExpectedOutcome, &request.ExpectedOutcome},
+	} {
+		filled, unfilled := placeholder.Fill(field.text, payload.Variables)
+		*field.dest = filled
+		for _, name := range unfilled {
+			if !slices.Contains(missing, name) {
+				missing = append(missing, name)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		writeJSONError(w, http.StatusBadRequest, "missing template variables: "+strings.Join(missing, ", "))
+		return
+	}
+
+	if strings.TrimSpace(request.GoalID) == "" && template.GoalID != nil {
+		request.GoalID = template.GoalID.String()
+	}
+	if request.Timebox == nil && template.TimeboxBlocks != nil {
+		request.Timebox = &timeboxRequest{Blocks: *template.TimeboxBlocks}
+	}
+	if request.NeededSkills == nil {
+		request.NeededSkills = template.NeededSkills
+	}
+
+	h.createIntent(w, r, request, &template)
+}
+
+func toIntentTemplateResponse(template database.IntentTemplate) intentTemplateResponse {
+	return intentTemplateResponse{
+		ID:                            template.ID.String(),
+		ChapterID:                     formatOptionalUUID(template.ChapterID),
+		Kind:                          template.Kind,
+		Name:                          template.Name,
+		intentTemplateContentResponse: toIntentTemplateContentResponse(template.IntentTemplateContent),
+		Version:                       template.Version,
+		CreatedAt:                     template.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:                     template.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func toIntentTemplateContentResponse(content database.IntentTemplateContent) intentTemplateContentResponse {
+	return intentTemplateContentResponse{
+		Statement:       content.Statement,
+		Context:         content.Context,
+		ExpectedOutcome: content.ExpectedOutcome,
+		NeededSkills:    content.NeededSkills,
+		TimeboxBlocks:   content.TimeboxBlocks,
+		GoalID:          formatOptionalUUID(content.GoalID),
+		Variables:       placeholder.Names(content.Statement, content.Context, content.ExpectedOutcome),
+	}
+}
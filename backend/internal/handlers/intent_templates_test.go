@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/intent/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+var intentTemplateVersionColumns = []string{"template_id", "version", "statement", "context", "expected_outcome", "needed_skills", "timebox_blocks", "goal_id", "created_at"}
+
+func TestIntentTemplatesHandlerCreateRequiresStatement(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	req := httptest.NewRequest(http.MethodPost, "/api/intent-templates", strings.NewReader(`{"kind":"Design Review","name":"Design review"}`))
+	rr := httptest.NewRecorder()
+
+	IntentTemplatesHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 got %d", rr.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func TestIntentTemplatesHandlerCreateNormalizesKind(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO intent_templates").
+		WithArgs(sqlmock.AnyArg(), nil, "design-review", "Design review", "Review the design of {{component}}", "", "", `["architecture"]`, 2, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO intent_template_versions").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	body := `{"kind":"Design Review","name":"Design review","statement":"Review the design of {{component}}","neededSkills":["architecture"],"timeboxBlocks":2}`
+	req := httptest.NewRequest(http.MethodPost, "/api/intent-templates", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	IntentTemplatesHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var response intentTemplateResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if response.Kind != "design-review" || response.Version != 1 {
+		t.Fatalf("unexpected template %+v", response)
+	}
+	if len(response.Variables) != 1 || response.Variables[0] != "component" {
+		t.Fatalf("expected variables [component] got %v", response.Variables)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func TestIntentsHandlerCreateFromTemplate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	templateID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM intent_template_versions WHERE template_id = $1 AND version = $2")).
+		WithArgs(templateID, 2).
+		WillReturnRows(sqlmock.NewRows(intentTemplateVersionColumns).
+			AddRow(templateID, 2, "Spike on {{topic}}", "Unknowns around {{topic}}", "A written recommendation", []byte(`["go"]`), 2, nil, time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO intents").
+		WithArgs(sqlmock.AnyArg(), "Spike on queues", "Unknowns around queues", "A written recommendation", "[]", "active", nil, nil, nil, sqlmock.AnyArg(), `{"blocks":2}`, `["go"]`, templateID, 2, "[]").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectIntentTransition(mock, sqlmock.AnyArg(), nil, database.IntentActive)
+	expectWebhookEvent(mock, database.EventIntentCreated)
+	mock.ExpectCommit()
+	mock.ExpectQuery("similarity\\(statement, \\$1\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags", "score"}))
+	expectQualityScore(mock, sqlmock.AnyArg())
+
+	body, err := json.Marshal(map[string]any{
+		"templateId": templateID.String(),
+		"version":    2,
+		"variables":  map[string]string{"topic": "queues"},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/intents:fromTemplate", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	IntentsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status 201 got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var response intentResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if response.TemplateID == nil || *response.TemplateID != templateID.String() || response.TemplateVersion == nil || *response.TemplateVersion != 2 {
+		t.Fatalf("expected template %s v2 got %v v%v", templateID, response.TemplateID, response.TemplateVersion)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func TestIntentsHandlerCreateFromTemplateReportsMissingVariables(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	templateID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM intent_template_versions WHERE template_id = $1 ORDER BY version DESC LIMIT 1")).
+		WithArgs(templateID).
+		WillReturnRows(sqlmock.NewRows(intentTemplateVersionColumns).
+			AddRow(templateID, 1, "Follow up on {{incident}}", "Owned by {{team}}", "", []byte(`[]`), nil, nil, time.Now()))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/intents:fromTemplate", strings.NewReader(`{"templateId":"`+templateID.String()+`"}`))
+	rr := httptest.NewRecorder()
+
+	IntentsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "incident, team") {
+		t.Fatalf("expected missing variables in error got %s", rr.Body.String())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
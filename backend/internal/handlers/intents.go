@@ -5,33 +5,95 @@ import (
 	"encoding/json"
 	"errors"
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/tracker"
 	"github.com/google/uuid"
 )
 
 type createIntentRequest struct {
-	Statement       string   `json:"statement"`
-	Context         string   `json:"context"`
-	ExpectedOutcome string   `json:"expectedOutcome"`
-	Collaborators   []string `json:"collaborators"`
+	Statement             string          `json:"statement"`
+	Context               string          `json:"context"`
+	ExpectedOutcome       string          `json:"expectedOutcome"`
+	Collaborators         []string        `json:"collaborators"`
+	Status                string          `json:"status"`
+	MemberID              string          `json:"memberId"`
+	GoalID                string          `json:"goalId"`
+	SessionID             string          `json:"sessionId"`
+	Timebox               *timeboxRequest `json:"timebox"`
+	NeededSkills          []string        `json:"neededSkills"`
+	Tags                  []string        `json:"tags"`
+	AcknowledgeGuardrails bool            `json:"acknowledgeGuardrails"`
+}
+
+type timeboxRequest struct {
+	Blocks     int      `json:"blocks"`
+	SessionIDs []string `json:"sessionIds"`
 }
 
 type intentResponse struct {
-	ID              string   `json:"id"`
-	Statement       string   `json:"statement"`
-	Context         string   `json:"context"`
-	ExpectedOutcome string   `json:"expectedOutcome"`
-	Collaborators   []string `json:"collaborators"`
-	CreatedAt       string   `json:"createdAt"`
+	ID              string                    `json:"id"`
+	Statement       string                    `json:"statement"`
+	Context         string                    `json:"context"`
+	ExpectedOutcome string                    `json:"expectedOutcome"`
+	Collaborators   []string                  `json:"collaborators"`
+	Status          string                    `json:"status"`
+	MemberID        *string                   `json:"memberId"`
+	GoalID          *string                   `json:"goalId"`
+	SessionID       *string                   `json:"sessionId"`
+	Timebox         *timeboxResponse          `json:"timebox"`
+	NeededSkills    []string                  `json:"neededSkills"`
+	TemplateID      *string                   `json:"templateId"`
+	TemplateVersion *int                      `json:"templateVersion"`
+	Tags            []string                  `json:"tags"`
+	Guardrails      *intentGuardrailsResponse `json:"guardrails"`
+	CreatedAt       string                    `json:"createdAt"`
+}
+
+type timeboxResponse struct {
+	Blocks     int      `json:"blocks,omitempty"`
+	SessionIDs []string `json:"sessionIds,omitempty"`
 }
 
 type listIntentResponse struct {
-	Items      []intentResponse   `json:"items"`
-	Pagination paginationResponse `json:"pagination"`
+	Items      []intentResponse     `json:"items"`
+	Pagination paginationResponse   `json:"pagination"`
+	Facets     intentFacetsResponse `json:"facets"`
+}
+
+type createIntentResponse struct {
+	intentResponse
+	LikelyDuplicates []similarIntentResponse `json:"likelyDuplicates"`
+}
+
+type similarIntentResponse struct {
+	intentResponse
+	Score float64 `json:"score"`
+}
+
+type listSimilarIntentResponse struct {
+	Items []similarIntentResponse `json:"items"`
+}
+
+type mergeIntentRequest struct {
+	AbsorbedID string `json:"absorbedId"`
+}
+
+type intentMergeResponse struct {
+	ID                string         `json:"id"`
+	SurvivingIntentID string         `json:"survivingIntentId"`
+	AbsorbedIntentID  string         `json:"absorbedIntentId"`
+	AbsorbedSnapshot  intentResponse `json:"absorbedSnapshot"`
+	MergedAt          string         `json:"mergedAt"`
+}
+
+type listIntentMergeResponse struct {
+	Items []intentMergeResponse `json:"items"`
 }
 
 const (
@@ -39,9 +101,21 @@ const (
 	maxPageSize     = 100
 )
 
+const (
+	// duplicateThreshold is the minimum weighted trigram score for an intent
+	// to be reported as a likely duplicate when another is created.
+	duplicateThreshold = 0.5
+	// defaultSimilarThreshold and defaultSimilarLimit apply to the
+	// /api/intents/{id}/similar endpoint when no query overrides are given.
+	defaultSimilarThreshold = 0.3
+	defaultSimilarLimit     = 10
+	maxDuplicateResults     = 5
+)
+
 type intentsHandler struct {
-	logger *slog.Logger
-	db     *sql.DB
+	logger  *slog.Logger
+	db      *sql.DB
+	tracker tracker.Tracker
 }
 
 // CreateIntentHandler handles POST /api/intents requests. Maintained for
@@ -60,7 +134,13 @@ func CreateIntentHandler(logger *slog.Logger, db *sql.DB) http.HandlerFunc {
 
 // IntentsHandler routes CRUDL operations for intents.
 func IntentsHandler(logger *slog.Logger, db *sql.DB) http.Handler {
-	return &intentsHandler{logger: logger, db: db}
+	return TrackedIntentsHandler(logger, db, nil)
+}
+
+// TrackedIntentsHandler routes intents like IntentsHandler and creates and
+// syncs their issues in t. A nil t disables the tracker endpoints.
+func TrackedIntentsHandler(logger *slog.Logger, db *sql.DB, t tracker.Tracker) http.Handler {
+	return &intentsHandler{logger: logger, db: db, tracker: t}
 }
 
 func (h *intentsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -69,13 +149,24 @@ func (h *intentsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.handleCreate(w, r)
 	case r.Method == http.MethodGet && r.URL.Path == "/api/intents":
 		h.handleList(w, r)
+	case r.URL.Path == "/api/intents:fromTemplate":
+		if r.Method != http.MethodPost {
+			h.methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		h.handleCreateFromTemplate(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/intents/"):
-		id := strings.TrimPrefix(r.URL.Path, "/api/intents/")
+		id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/intents/"), "/")
 		if id == "" {
 			http.NotFound(w, r)
 			return
 		}
 
+		if action != "" {
+			h.routeAction(w, r, id, action)
+			return
+		}
+
 		switch r.Method {
 		case http.MethodGet:
 			h.handleRetrieve(w, r, id)
@@ -93,6 +184,49 @@ func (h *intentsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *intentsHandler) routeAction(w http.ResponseWriter, r *http.Request, id, action string) {
+	switch action {
+	case "similar":
+		if r.Method != http.MethodGet {
+			h.methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		h.handleSimilar(w, r, id)
+	case "merge":
+		if r.Method != http.MethodPost {
+			h.methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		h.handleMerge(w, r, id)
+	case "merges":
+		if r.Method != http.MethodGet {
+			h.methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		h.handleListMerges(w, r, id)
+	case "acknowledge-guardrails":
+		if r.Method != http.MethodPost {
+			h.methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		h.handleAcknowledgeGuardrails(w, r, id)
+	case "quality":
+		if r.Method != http.MethodGet {
+			h.methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		h.handleQuality(w, r, id)
+	case "links", "issue", "status-suggestions":
+		h.routeLinks(w, r, id, action)
+	default:
+		if strings.HasPrefix(action, "links/") || strings.HasPrefix(action, "status-suggestions/") {
+			h.routeLinks(w, r, id, action)
+			return
+		}
+		http.NotFound(w, r)
+	}
+}
+
 func validateIntentPayload(payload createIntentRequest) error {
 	if strings.TrimSpace(payload.Statement) == "" {
 		return errors.New("statement is required")
@@ -106,9 +240,94 @@ func validateIntentPayload(payload createIntentRequest) error {
 		return errors.New("expectedOutcome is required")
 	}
 
+	switch strings.TrimSpace(payload.Status) {
+	case "", database.IntentDraft, database.IntentActive, database.IntentDone:
+	default:
+		return errors.New("status must be draft, active or done")
+	}
+
+	if payload.Timebox != nil {
+		if payload.Timebox.Blocks < 0 {
+			return errors.New("timebox blocks must be positive")
+		}
+		if (payload.Timebox.Blocks > 0) == (len(payload.Timebox.SessionIDs) > 0) {
+			return errors.New("timebox must give either blocks or sessionIds")
+		}
+	}
+
+	return nil
+}
+
+// validateNewIntentPayload applies validateIntentPayload plus the rules that
+// only hold when an intent is created, such as a required timebox.
+func validateNewIntentPayload(payload createIntentRequest) error {
+	if err := validateIntentPayload(payload); err != nil {
+		return err
+	}
+
+	if payload.Timebox == nil {
+		return errors.New("timebox is required")
+	}
+
 	return nil
 }
 
+// intentInputFromPayload converts a validated payload into database input,
+// parsing the optional member, goal and session references.
+func intentInputFromPayload(payload createIntentRequest) (database.IntentInput, error) {
+	input := database.IntentInput{
+		Statement:       strings.TrimSpace(payload.Statement),
+		Context:         strings.TrimSpace(payload.Context),
+		ExpectedOutcome: strings.TrimSpace(payload.ExpectedOutcome),
+		Collaborators:   normalizeCollaborators(payload.Collaborators),
+		Status:          strings.TrimSpace(payload.Status),
+		NeededSkills:    normalizeCollaborators(payload.NeededSkills),
+		Tags:            normalizeTags(payload.Tags),
+	}
+
+	refs := []struct {
+		value string
+		dest  **uuid.UUID
+		field string
+	}{
+		{payload.MemberID, &input.MemberID, "memberId"},
+		{payload.GoalID, &input.GoalID, "goalId"},
+		{payload.SessionID, &input.SessionID, "sessionId"},
+	}
+
+	for _, ref := range refs {
+		value := strings.TrimSpace(ref.value)
+		if value == "" {
+			continue
+		}
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			return database.IntentInput{}, errors.New(ref.field + " must be a valid id")
+		}
+		*ref.dest = &parsed
+	}
+
+	if payload.Timebox != nil {
+		timebox := &database.Timebox{Blocks: payload.Timebox.Blocks}
+		seen := make(map[uuid.UUID]struct{}, len(payload.Timebox.SessionIDs))
+		for _, value := range payload.Timebox.SessionIDs {
+			parsed, err := uuid.Parse(strings.TrimSpace(value))
+			if err != nil {
+				return database.IntentInput{}, errors.New("timebox sessionIds must be valid ids")
+			}
+			if _, ok := seen[parsed]; ok {
+				continue
+			}
+			seen[parsed] = struct{}{}
+			timebox.SessionIDs = append(timebox.SessionIDs, parsed)
+		}
+		input.Timebox = timebox
+	}
+	input.AcknowledgeGuardrails = payload.AcknowledgeGuardrails
+
+	return input, nil
+}
+
 func normalizeCollaborators(collaborators []string) []string {
 	if len(collaborators) == 0 {
 		return []string{}
@@ -142,27 +361,73 @@ func (h *intentsHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := validateIntentPayload(payload); err != nil {
+	h.createIntent(w, r, payload, nil)
+}
+
+// createIntent validates and stores a new intent, recording the template
+// version it was created from when one is given, and responds with the
+// intent and its likely duplicates.
+func (h *intentsHandler) createIntent(w http.ResponseWriter, r *http.Request, payload createIntentRequest, template *database.IntentTemplateVersion) {
+	ctx := r.Context()
+
+	if err := validateNewIntentPayload(payload); err != nil {
 		h.logger.WarnContext(ctx, "intent validation failed", "error", err)
 		writeJSONError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	cleanedCollaborators := normalizeCollaborators(payload.Collaborators)
+	input, err := intentInputFromPayload(payload)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	record, err := database.CreateIntent(ctx, h.db, database.IntentInput{
-		Statement:       strings.TrimSpace(payload.Statement),
-		Context:         strings.TrimSpace(payload.Context),
-		ExpectedOutcome: strings.TrimSpace(payload.ExpectedOutcome),
-		Collaborators:   cleanedCollaborators,
-	})
+	if template != nil {
+		input.TemplateID = &template.TemplateID
+		input.TemplateVersion = &template.Version
+	}
+
+	record, err := database.CreateIntent(ctx, h.db, input)
 	if err != nil {
+		if errors.Is(err, database.ErrGoalNotActive) {
+			writeJSONError(w, http.StatusConflict, err.Error())
+			return
+		}
+		if errors.Is(err, database.ErrGuardrailsNotAcknowledged) || errors.Is(err, database.ErrTimeboxSessionNotFound) {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if isForeignKeyViolation(err) {
+			writeJSONError(w, http.StatusBadRequest, "referenced member, goal or session does not exist")
+			return
+		}
 		h.logger.ErrorContext(ctx, "failed to persist intent", "error", err)
 		writeJSONError(w, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
-	response := toIntentResponse(record)
+	responses, err := h.intentResponses(ctx, record)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to load intent guardrails", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response := createIntentResponse{
+		intentResponse:   responses[0],
+		LikelyDuplicates: []similarIntentResponse{},
+	}
+
+	// Duplicate detection is advisory: the intent is already stored, so a
+	// failed lookup is logged rather than surfaced to the caller.
+	duplicates, err := database.FindSimilarIntents(ctx, h.db, input, record.ID, duplicateThreshold, maxDuplicateResults)
+	if err != nil {
+		h.logger.WarnContext(ctx, "failed to look up likely duplicates", "error", err, "intent_id", record.ID)
+	} else {
+		response.LikelyDuplicates = toSimilarIntentResponses(duplicates)
+	}
+
+	h.scoreQuality(ctx, record)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -190,8 +455,41 @@ func (h *intentsHandler) handleList(w http.ResponseWriter, r *http.Request) {
 	filters := database.IntentFilters{
 		Query:        strings.TrimSpace(r.URL.Query().Get("q")),
 		Collaborator: strings.TrimSpace(r.URL.Query().Get("collaborator")),
+		Status:       strings.TrimSpace(r.URL.Query().Get("status")),
+	}
+
+	switch filters.Status {
+	case "", database.IntentDraft, database.IntentActive, database.IntentDone:
+	default:
+		writeJSONError(w, http.StatusBadRequest, "status must be draft, active or done")
+		return
 	}
 
+	if value := strings.TrimSpace(r.URL.Query().Get("session")); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "session must be a valid session id")
+			return
+		}
+		filters.SessionID = &parsed
+	}
+
+	if value := strings.TrimSpace(r.URL.Query().Get("goal")); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "goal must be a valid goal id")
+			return
+		}
+		filters.GoalID = &parsed
+	}
+
+	tags, match, err := parseTagFilter(r.URL.Query())
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	filters.Tags, filters.TagMatch = tags, match
+
 	if value := strings.TrimSpace(r.URL.Query().Get("createdAfter")); value != "" {
 		ts, err := time.Parse(time.RFC3339, value)
 		if err != nil {
@@ -210,6 +508,15 @@ func (h *intentsHandler) handleList(w http.ResponseWriter, r *http.Request) {
 		filters.CreatedBefore = &ts
 	}
 
+	if value := strings.TrimSpace(r.URL.Query().Get("needsGuardrailAcknowledgment")); value != "" {
+		needs, err := strconv.ParseBool(value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "needsGuardrailAcknowledgment must be true or false")
+			return
+		}
+		filters.NeedsGuardrailAcknowledgment = needs
+	}
+
 	offset := (page - 1) * pageSize
 
 	result, err := database.ListIntents(ctx, h.db, filters, database.Pagination{Limit: pageSize, Offset: offset})
@@ -224,9 +531,11 @@ func (h *intentsHandler) handleList(w http.ResponseWriter, r *http.Request) {
 		totalPages = (result.TotalCount + pageSize - 1) / pageSize
 	}
 
-	responses := make([]intentResponse, 0, len(result.Intents))
-	for _, intent := range result.Intents {
-		responses = append(responses, toIntentResponse(intent))
+	responses, err := h.intentResponses(ctx, result.Intents...)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to load intent guardrails", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
 	}
 
 	payload := listIntentResponse{
@@ -237,6 +546,11 @@ func (h *intentsHandler) handleList(w http.ResponseWriter, r *http.Request) {
 			TotalItems: result.TotalCount,
 			TotalPages: totalPages,
 		},
+		Facets: intentFacetsResponse{
+			Tags:     toFacetCountResponses(result.Facets.Tags),
+			Statuses: toFacetCountResponses(result.Facets.Statuses),
+			Goals:    toFacetCountResponses(result.Facets.Goals),
+		},
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -265,8 +579,15 @@ func (h *intentsHandler) handleRetrieve(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
+	responses, err := h.intentResponses(ctx, record)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to load intent guardrails", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(toIntentResponse(record)); err != nil {
+	if err := json.NewEncoder(w).Encode(responses[0]); err != nil {
 		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
 	}
 }
@@ -293,26 +614,46 @@ func (h *intentsHandler) handleUpdate(w http.ResponseWriter, r *http.Request, id
 		return
 	}
 
-	cleanedCollaborators := normalizeCollaborators(payload.Collaborators)
+	input, err := intentInputFromPayload(payload)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	record, err := database.UpdateIntent(ctx, h.db, uuidValue, database.IntentInput{
-		Statement:       strings.TrimSpace(payload.Statement),
-		Context:         strings.TrimSpace(payload.Context),
-		ExpectedOutcome: strings.TrimSpace(payload.ExpectedOutcome),
-		Collaborators:   cleanedCollaborators,
-	})
+	record, err := database.UpdateIntent(ctx, h.db, uuidValue, input)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeJSONError(w, http.StatusNotFound, "intent not found")
 			return
 		}
+		if errors.Is(err, database.ErrGoalNotActive) {
+			writeJSONError(w, http.StatusConflict, err.Error())
+			return
+		}
+		if errors.Is(err, database.ErrGuardrailsNotAcknowledged) || errors.Is(err, database.ErrTimeboxSessionNotFound) || errors.Is(err, database.ErrDraftNeedsTimebox) {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if isForeignKeyViolation(err) {
+			writeJSONError(w, http.StatusBadRequest, "referenced goal or session does not exist")
+			return
+		}
 		h.logger.ErrorContext(ctx, "failed to update intent", "error", err)
 		writeJSONError(w, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
+	h.scoreQuality(ctx, record)
+
+	responses, err := h.intentResponses(ctx, record)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to load intent guardrails", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(toIntentResponse(record)); err != nil {
+	if err := json.NewEncoder(w).Encode(responses[0]); err != nil {
 		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
 	}
 }
@@ -339,6 +680,138 @@ func (h *intentsHandler) handleDelete(w http.ResponseWriter, r *http.Request, id
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *intentsHandler) handleSimilar(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	uuidValue, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid intent id")
+		return
+	}
+
+	threshold := defaultSimilarThreshold
+	if value := strings.TrimSpace(r.URL.Query().Get("threshold")); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			writeJSONError(w, http.StatusBadRequest, "threshold must be a number between 0 and 1")
+			return
+		}
+		threshold = parsed
+	}
+
+	limit := parsePositiveInt(r.URL.Query().Get("limit"), defaultSimilarLimit)
+	if limit < 1 {
+		limit = defaultSimilarLimit
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	record, err := database.GetIntent(ctx, h.db, uuidValue)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "intent not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to retrieve intent", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	matches, err := database.FindSimilarIntents(ctx, h.db, database.IntentInput{
+		Statement:       record.Statement,
+		Context:         record.Context,
+		ExpectedOutcome: record.ExpectedOutcome,
+	}, record.ID, threshold, limit)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to find similar intents", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listSimilarIntentResponse{Items: toSimilarIntentResponses(matches)}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *intentsHandler) handleMerge(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	survivingID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid intent id")
+		return
+	}
+
+	var payload mergeIntentRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid merge payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	absorbedID, err := uuid.Parse(strings.TrimSpace(payload.AbsorbedID))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "absorbedId must be a valid intent id")
+		return
+	}
+
+	record, err := database.MergeIntents(ctx, h.db, survivingID, absorbedID)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrSelfMerge):
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, sql.ErrNoRows):
+			writeJSONError(w, http.StatusNotFound, "intent not found")
+		default:
+			h.logger.ErrorContext(ctx, "failed to merge intents", "error", err)
+			writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	h.logger.InfoContext(ctx, "intents merged", "surviving_intent_id", survivingID, "absorbed_intent_id", absorbedID)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toIntentResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *intentsHandler) handleListMerges(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	uuidValue, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid intent id")
+		return
+	}
+
+	merges, err := database.ListIntentMerges(ctx, h.db, uuidValue)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list intent merges", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	responses := make([]intentMergeResponse, 0, len(merges))
+	for _, merge := range merges {
+		responses = append(responses, intentMergeResponse{
+			ID:                merge.ID.String(),
+			SurvivingIntentID: merge.SurvivingIntentID.String(),
+			AbsorbedIntentID:  merge.AbsorbedIntentID.String(),
+			AbsorbedSnapshot:  toIntentResponse(merge.AbsorbedSnapshot),
+			MergedAt:          merge.MergedAt.Format(time.RFC3339),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listIntentMergeResponse{Items: responses}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
 func (h *intentsHandler) methodNotAllowed(w http.ResponseWriter, allowed ...string) {
 	w.Header().Set("Allow", strings.Join(allowed, ", "))
 	writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -351,6 +824,38 @@ func toIntentResponse(intent database.Intent) intentResponse {
 		Context:         intent.Context,
 		ExpectedOutcome: intent.ExpectedOutcome,
 		Collaborators:   intent.Collaborators,
+		Status:          intent.Status,
+		MemberID:        formatOptionalUUID(intent.MemberID),
+		GoalID:          formatOptionalUUID(intent.GoalID),
+		SessionID:       formatOptionalUUID(intent.SessionID),
+		Timebox:         toTimeboxResponse(intent.Timebox),
+		NeededSkills:    intent.NeededSkills,
+		TemplateID:      formatOptionalUUID(intent.TemplateID),
+		TemplateVersion: intent.TemplateVersion,
+		Tags:            intent.Tags,
 		CreatedAt:       intent.CreatedAt.Format(time.RFC3339),
 	}
 }
+
+func toTimeboxResponse(timebox *database.Timebox) *timeboxResponse {
+	if timebox == nil {
+		return nil
+	}
+
+	response := &timeboxResponse{Blocks: timebox.Blocks}
+	for _, id := range timebox.SessionIDs {
+		response.SessionIDs = append(response.SessionIDs, id.String())
+	}
+	return response
+}
+
+func toSimilarIntentResponses(matches []database.SimilarIntent) []similarIntentResponse {
+	responses := make([]similarIntentResponse, 0, len(matches))
+	for _, match := range matches {
+		responses = append(responses, similarIntentResponse{
+			intentResponse: toIntentResponse(match.Intent),
+			Score:          math.Round(match.Score*1000) / 1000,
+		})
+	}
+	return responses
+}
@@ -9,13 +9,21 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/intent/backend/internal/database"
 	"github.com/google/uuid"
 )
 
+func expectIntentTransition(mock sqlmock.Sqlmock, intentID, fromStatus any, toStatus string) {
+	mock.ExpectExec("INSERT INTO intent_transitions").
+		WithArgs(sqlmock.AnyArg(), intentID, fromStatus, toStatus, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
 func TestCreateIntentHandlerSuccess(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -30,15 +38,27 @@ func TestCreateIntentHandlerSuccess(t *testing.T) {
 		"context":         "New joiners are confused by the handbook.",
 		"expectedOutcome": "A concise guide published in Confluence.",
 		"collaborators":   []string{"Jamie", "Ana"},
+		"timebox":         map[string]any{"blocks": 3},
 	}
 	body, err := json.Marshal(payload)
 	if err != nil {
 		t.Fatalf("failed to marshal payload: %v", err)
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectExec("INSERT INTO intents").
-		WithArgs(sqlmock.AnyArg(), payload["statement"], payload["context"], payload["expectedOutcome"], sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(sqlmock.AnyArg(), payload["statement"], payload["context"], payload["expectedOutcome"], sqlmock.AnyArg(), "active", nil, nil, nil, sqlmock.AnyArg(), `{"blocks":3}`, "[]", nil, nil, "[]").
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectIntentTransition(mock, sqlmock.AnyArg(), nil, database.IntentActive)
+	expectWebhookEvent(mock, database.EventIntentCreated)
+	mock.ExpectCommit()
+
+	duplicateID := uuid.New()
+	mock.ExpectQuery("similarity\\(statement, \\$1\\)").
+		WithArgs(payload["statement"], payload["context"], payload["expectedOutcome"], sqlmock.AnyArg(), duplicateThreshold, maxDuplicateResults, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags", "score"}).
+			AddRow(duplicateID, payload["statement"], payload["context"], payload["expectedOutcome"], `["Jamie"]`, "active", nil, nil, nil, time.Now().UTC(), nil, nil, nil, nil, nil, 0.97))
+	expectQualityScore(mock, sqlmock.AnyArg())
 
 	req := httptest.NewRequest(http.MethodPost, "/api/intents", bytes.NewReader(body))
 	rr := httptest.NewRecorder()
@@ -51,18 +71,26 @@ func TestCreateIntentHandlerSuccess(t *testing.T) {
 	}
 
 	var response struct {
-		ID              string   `json:"id"`
-		Statement       string   `json:"statement"`
-		Context         string   `json:"context"`
-		ExpectedOutcome string   `json:"expectedOutcome"`
-		Collaborators   []string `json:"collaborators"`
-		CreatedAt       string   `json:"createdAt"`
+		ID               string   `json:"id"`
+		Statement        string   `json:"statement"`
+		Context          string   `json:"context"`
+		ExpectedOutcome  string   `json:"expectedOutcome"`
+		Collaborators    []string `json:"collaborators"`
+		CreatedAt        string   `json:"createdAt"`
+		LikelyDuplicates []struct {
+			ID    string  `json:"id"`
+			Score float64 `json:"score"`
+		} `json:"likelyDuplicates"`
 	}
 
 	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
 		t.Fatalf("response is not valid JSON: %v", err)
 	}
 
+	if len(response.LikelyDuplicates) != 1 || response.LikelyDuplicates[0].ID != duplicateID.String() {
+		t.Fatalf("expected likely duplicate %s got %+v", duplicateID, response.LikelyDuplicates)
+	}
+
 	if response.ID == "" {
 		t.Error("expected response to include an ID")
 	} else if _, err := uuid.Parse(response.ID); err != nil {
@@ -119,6 +147,63 @@ func TestCreateIntentHandlerValidationError(t *testing.T) {
 	}
 }
 
+func TestCreateIntentHandlerRequiresTimebox(t *testing.T) {
+	body, err := json.Marshal(map[string]any{
+		"statement":       "statement",
+		"context":         "context",
+		"expectedOutcome": "outcome",
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/intents", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	CreateIntentHandler(testLogger(t), &sql.DB{}).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "timebox is required") {
+		t.Fatalf("expected timebox to be required got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestCreateIntentHandlerRejectsInactiveGoal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	goalID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status, jsonb_array_length(guardrails) FROM goals WHERE id = $1")).
+		WithArgs(goalID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "guardrails"}).AddRow("paused", 0))
+	mock.ExpectRollback()
+
+	body, _ := json.Marshal(map[string]any{
+		"statement":       "I intend to add checkout retries.",
+		"context":         "Provider outages drop orders.",
+		"expectedOutcome": "Retries behind a flag.",
+		"goalId":          goalID.String(),
+		"timebox":         map[string]any{"blocks": 2},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/intents", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	CreateIntentHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusConflict, rr.Code, rr.Body.String())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
 func TestCreateIntentHandlerMethodNotAllowed(t *testing.T) {
 	db := &sql.DB{}
 	logger := testLogger(t)
@@ -171,10 +256,14 @@ func TestIntentsHandlerListSuccess(t *testing.T) {
 		WithArgs(pattern, pattern, pattern, "Jamie").
 		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, statement, context, expected_outcome, collaborators, created_at FROM intents WHERE (statement ILIKE $1 OR context ILIKE $2 OR expected_outcome ILIKE $3) AND EXISTS (SELECT 1 FROM jsonb_array_elements_text(collaborators) AS c WHERE LOWER(c) = LOWER($4)) ORDER BY created_at DESC LIMIT $5 OFFSET $6")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, statement, context, expected_outcome, collaborators, status, member_id, goal_id, session_id, created_at, timebox, needed_skills, template_id, template_version, tags FROM intents WHERE (statement ILIKE $1 OR context ILIKE $2 OR expected_outcome ILIKE $3) AND EXISTS (SELECT 1 FROM jsonb_array_elements_text(collaborators) AS c WHERE LOWER(c) = LOWER($4)) ORDER BY created_at DESC LIMIT $5 OFFSET $6")).
 		WithArgs(pattern, pattern, pattern, "Jamie", 5, 5).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "created_at"}).
-			AddRow(id, "statement", "context", "outcome", `["Jamie"]`, createdAt))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags"}).
+			AddRow(id, "statement", "context", "outcome", `["Jamie"]`, "active", nil, nil, nil, createdAt, nil, nil, nil, nil, nil))
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT 'status', COALESCE(status::text, ''), COUNT(*) FROM intents WHERE (statement ILIKE $1")).
+		WithArgs(pattern, pattern, pattern, "Jamie").
+		WillReturnRows(sqlmock.NewRows([]string{"facet", "value", "count"}).AddRow(database.FacetStatus, "active", 1))
 
 	req := httptest.NewRequest(http.MethodGet, "/api/intents?page=2&pageSize=5&q=swarm&collaborator=Jamie", nil)
 	rr := httptest.NewRecorder()
@@ -231,7 +320,7 @@ func TestIntentsHandlerRetrieveNotFound(t *testing.T) {
 	logger := testLogger(t)
 	id := uuid.New()
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, statement, context, expected_outcome, collaborators, created_at FROM intents WHERE id = $1")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, statement, context, expected_outcome, collaborators, status, member_id, goal_id, session_id, created_at, timebox, needed_skills, template_id, template_version, tags FROM intents WHERE id = $1")).
 		WithArgs(id).
 		WillReturnError(sql.ErrNoRows)
 
@@ -272,16 +361,27 @@ func TestIntentsHandlerUpdateSuccess(t *testing.T) {
 		t.Fatalf("failed to marshal payload: %v", err)
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE intents
 SET statement = $1,
     context = $2,
     expected_outcome = $3,
-    collaborators = $4
-WHERE id = $5
-RETURNING id, statement, context, expected_outcome, collaborators, created_at`)).
-		WithArgs(payload["statement"], payload["context"], payload["expectedOutcome"], `["Jamie"]`, id).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "created_at"}).
-			AddRow(id, payload["statement"], payload["context"], payload["expectedOutcome"], `["Jamie"]`, createdAt))
+    collaborators = $4,
+    status = COALESCE(NULLIF($5, ''), status),
+    goal_id = $6,
+    session_id = $7,
+    timebox = COALESCE($8, timebox),
+    needed_skills = $9,
+    tags = $10
+FROM (SELECT status AS previous_status, goal_id AS previous_goal_id FROM intents WHERE id = $11 FOR UPDATE) previous
+WHERE id = $11
+RETURNING id, statement, context, expected_outcome, collaborators, status, member_id, goal_id, session_id, created_at, timebox, needed_skills, template_id, template_version, tags, previous_status, previous_goal_id`)).
+		WithArgs(payload["statement"], payload["context"], payload["expectedOutcome"], `["Jamie"]`, "", nil, nil, nil, "[]", "[]", id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags", "previous_status", "previous_goal_id"}).
+			AddRow(id, payload["statement"], payload["context"], payload["expectedOutcome"], `["Jamie"]`, "active", nil, nil, nil, createdAt, nil, nil, nil, nil, nil, "active", nil))
+	expectWebhookEvent(mock, database.EventIntentUpdated)
+	mock.ExpectCommit()
+	expectQualityScore(mock, id)
 
 	req := httptest.NewRequest(http.MethodPut, "/api/intents/"+id.String(), bytes.NewReader(body))
 	rr := httptest.NewRecorder()
@@ -320,9 +420,12 @@ func TestIntentsHandlerDeleteSuccess(t *testing.T) {
 	logger := testLogger(t)
 	id := uuid.New()
 
+	mock.ExpectBegin()
 	mock.ExpectExec("DELETE FROM intents").
 		WithArgs(id).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectWebhookEvent(mock, database.EventIntentDeleted)
+	mock.ExpectCommit()
 
 	req := httptest.NewRequest(http.MethodDelete, "/api/intents/"+id.String(), nil)
 	rr := httptest.NewRecorder()
@@ -339,6 +442,170 @@ func TestIntentsHandlerDeleteSuccess(t *testing.T) {
 	}
 }
 
+func TestIntentsHandlerSimilarSuccess(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	logger := testLogger(t)
+	id := uuid.New()
+	similarID := uuid.New()
+	createdAt := time.Now().UTC()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, statement, context, expected_outcome, collaborators, status, member_id, goal_id, session_id, created_at, timebox, needed_skills, template_id, template_version, tags FROM intents WHERE id = $1")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags"}).
+			AddRow(id, "statement", "context", "outcome", `[]`, "active", nil, nil, nil, createdAt, nil, nil, nil, nil, nil))
+
+	mock.ExpectQuery("similarity\\(statement, \\$1\\)").
+		WithArgs("statement", "context", "outcome", id, 0.4, 3, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags", "score"}).
+			AddRow(similarID, "statement", "context", "outcome", `[]`, "active", nil, nil, nil, createdAt, nil, nil, nil, nil, nil, 0.8123))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/intents/"+id.String()+"/similar?threshold=0.4&limit=3", nil)
+	rr := httptest.NewRecorder()
+
+	handler := IntentsHandler(logger, db)
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 got %d", rr.Code)
+	}
+
+	var payload struct {
+		Items []struct {
+			ID    string  `json:"id"`
+			Score float64 `json:"score"`
+		} `json:"items"`
+	}
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+
+	if len(payload.Items) != 1 || payload.Items[0].ID != similarID.String() {
+		t.Fatalf("unexpected items: %+v", payload.Items)
+	}
+
+	if payload.Items[0].Score != 0.812 {
+		t.Fatalf("expected score rounded to 0.812 got %f", payload.Items[0].Score)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func TestIntentsHandlerSimilarInvalidThreshold(t *testing.T) {
+	db := &sql.DB{}
+	logger := testLogger(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/intents/"+uuid.NewString()+"/similar?threshold=2", nil)
+	rr := httptest.NewRecorder()
+
+	handler := IntentsHandler(logger, db)
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 got %d", rr.Code)
+	}
+}
+
+func TestIntentsHandlerMergeSuccess(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	logger := testLogger(t)
+	survivingID := uuid.New()
+	absorbedID := uuid.New()
+	createdAt := time.Now().UTC()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM intents WHERE id IN").
+		WithArgs(survivingID, absorbedID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "context", "expected_outcome", "collaborators", "status", "member_id", "goal_id", "session_id", "created_at", "timebox", "needed_skills", "template_id", "template_version", "tags"}).
+			AddRow(survivingID, "statement", "context", "outcome", `["Jamie"]`, "active", nil, nil, nil, createdAt, nil, nil, nil, nil, nil).
+			AddRow(absorbedID, "statement", "context", "outcome", `["Priya"]`, "active", nil, nil, nil, createdAt, nil, nil, nil, nil, nil))
+	mock.ExpectExec("UPDATE intents SET collaborators").
+		WithArgs(`["Jamie","Priya"]`, `[]`, survivingID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO intent_merges").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE commitments SET intent_id").
+		WithArgs(survivingID, absorbedID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE intent_links SET intent_id").
+		WithArgs(survivingID, absorbedID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE session_outcomes").
+		WithArgs(survivingID, absorbedID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM intents").
+		WithArgs(absorbedID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectWebhookEvent(mock, database.EventIntentUpdated)
+	expectWebhookEvent(mock, database.EventIntentDeleted)
+	mock.ExpectCommit()
+
+	body, err := json.Marshal(map[string]string{"absorbedId": absorbedID.String()})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/intents/"+survivingID.String()+"/merge", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	handler := IntentsHandler(logger, db)
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 got %d", rr.Code)
+	}
+
+	var response struct {
+		ID            string   `json:"id"`
+		Collaborators []string `json:"collaborators"`
+	}
+
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+
+	if response.ID != survivingID.String() || len(response.Collaborators) != 2 {
+		t.Fatalf("unexpected merge response: %+v", response)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func TestIntentsHandlerMergeIntoSelf(t *testing.T) {
+	db := &sql.DB{}
+	logger := testLogger(t)
+	id := uuid.NewString()
+
+	body, err := json.Marshal(map[string]string{"absorbedId": id})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/intents/"+id+"/merge", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	handler := IntentsHandler(logger, db)
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 got %d", rr.Code)
+	}
+}
+
 // testLogger creates a slog.Logger that discards output during tests.
 func testLogger(t *testing.T) *slog.Logger {
 	t.Helper()
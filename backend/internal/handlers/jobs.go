@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+type jobResponse struct {
+	ID          string          `json:"id"`
+	Kind        string          `json:"kind"`
+	Key         string          `json:"key"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"maxAttempts"`
+	RunAt       string          `json:"runAt"`
+	LastError   *string         `json:"lastError"`
+	CreatedAt   string          `json:"createdAt"`
+	UpdatedAt   string          `json:"updatedAt"`
+}
+
+type listJobResponse struct {
+	Items []jobResponse `json:"items"`
+}
+
+type jobsHandler struct {
+	logger *slog.Logger
+	db     *sql.DB
+}
+
+// JobsHandler exposes the background job queue so operators can inspect
+// dead-lettered jobs and send them back for another run.
+func JobsHandler(logger *slog.Logger, db *sql.DB) http.Handler {
+	return &jobsHandler{logger: logger, db: db}
+}
+
+func (h *jobsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/api/jobs":
+		if r.Method != http.MethodGet {
+			h.methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		h.handleList(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/jobs/") && strings.HasSuffix(r.URL.Path, "/retry"):
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/jobs/"), "/retry")
+		if id == "" || strings.Contains(id, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodPost {
+			h.methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		h.handleRetry(w, r, id)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *jobsHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	status := strings.TrimSpace(r.URL.Query().Get("status"))
+	if status == "" {
+		status = database.JobDead
+	}
+	switch status {
+	case database.JobPending, database.JobRunning, database.JobDone, database.JobDead:
+	default:
+		writeJSONError(w, http.StatusBadRequest, "status must be one of pending, running, done or dead")
+		return
+	}
+
+	limit := parsePositiveInt(r.URL.Query().Get("limit"), 50)
+	if limit < 1 || limit > 200 {
+		writeJSONError(w, http.StatusBadRequest, "limit must be between 1 and 200")
+		return
+	}
+
+	jobs, err := database.ListJobs(ctx, h.db, status, limit)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list jobs", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	responses := make([]jobResponse, 0, len(jobs))
+	for _, job := range jobs {
+		responses = append(responses, toJobResponse(job))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listJobResponse{Items: responses}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *jobsHandler) handleRetry(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	jobID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid job id")
+		return
+	}
+
+	job, err := database.RetryJob(ctx, h.db, jobID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeJSONError(w, http.StatusNotFound, "job not found")
+		case errors.Is(err, database.ErrJobNotDead):
+			writeJSONError(w, http.StatusConflict, "only dead jobs can be retried")
+		default:
+			h.logger.ErrorContext(ctx, "failed to retry job", "error", err)
+			writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toJobResponse(job)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *jobsHandler) methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func toJobResponse(job database.Job) jobResponse {
+	response := jobResponse{
+		ID:          job.ID.String(),
+		Kind:        job.Kind,
+		Key:         job.Key,
+		Payload:     job.Payload,
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		RunAt:       job.RunAt.Format(time.RFC3339),
+		CreatedAt:   job.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   job.UpdatedAt.Format(time.RFC3339),
+	}
+	if job.LastError != "" {
+		lastError := job.LastError
+		response.LastError = &lastError
+	}
+	return response
+}
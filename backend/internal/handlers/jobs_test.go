@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestJobsHandlerListsDeadJobsByDefault(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	jobID := uuid.New()
+	now := time.Now().UTC()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM jobs WHERE status = $1")).
+		WithArgs("dead", 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "kind", "key", "payload", "status", "attempts", "max_attempts", "run_at", "locked_until", "last_error", "created_at", "updated_at"}).
+			AddRow(jobID, "session.nudge", "abc:1h", []byte(`{"lead":"1h"}`), "dead", 5, 5, now, nil, "smtp timeout", now, now))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/jobs", nil)
+	rr := httptest.NewRecorder()
+
+	JobsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d", http.StatusOK, rr.Code)
+	}
+
+	var response listJobResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Items) != 1 || response.Items[0].LastError == nil || *response.Items[0].LastError != "smtp timeout" {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestJobsHandlerRetryMissingJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	jobID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE id = $1 AND status = 'dead'")).
+		WithArgs(jobID, sqlmock.AnyArg()).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS")).
+		WithArgs(jobID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/jobs/"+jobID.String()+"/retry", nil)
+	rr := httptest.NewRecorder()
+
+	JobsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d got %d", http.StatusNotFound, rr.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestJobsHandlerRejectsUnknownStatus(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	req := httptest.NewRequest(http.MethodGet, "/api/jobs?status=stuck", nil)
+	rr := httptest.NewRecorder()
+
+	JobsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d got %d", http.StatusBadRequest, rr.Code)
+	}
+}
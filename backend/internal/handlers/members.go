@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/secret"
+	"github.com/google/uuid"
+)
+
+type createMemberRequest struct {
+	ChapterID   string `json:"chapterId"`
+	DisplayName string `json:"displayName"`
+	Email       string `json:"email"`
+	Role        string `json:"role"`
+}
+
+type memberResponse struct {
+	ID          string `json:"id"`
+	ChapterID   string `json:"chapterId"`
+	DisplayName string `json:"displayName"`
+	Email       string `json:"email"`
+	Role        string `json:"role"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+type listMemberResponse struct {
+	Items []memberResponse `json:"items"`
+}
+
+var memberRoles = map[string]struct{}{
+	database.RoleMember:       {},
+	database.RoleChapterLead:  {},
+	database.RoleFacilitator:  {},
+	database.RoleSkillSteward: {},
+}
+
+type membersHandler struct {
+	logger      *slog.Logger
+	db          *sql.DB
+	credentials *secret.Box
+}
+
+// MembersHandler routes operations for chapter members.
+func MembersHandler(logger *slog.Logger, db *sql.DB) http.Handler {
+	return CalendarMembersHandler(logger, db, nil)
+}
+
+// CalendarMembersHandler routes members like MembersHandler and seals
+// calendar source passwords with credentials. A nil credentials box rejects
+// calendar sources that carry a password.
+func CalendarMembersHandler(logger *slog.Logger, db *sql.DB, credentials *secret.Box) http.Handler {
+	return &membersHandler{logger: logger, db: db, credentials: credentials}
+}
+
+func (h *membersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/members":
+		h.handleCreate(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/api/members":
+		h.handleList(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/members/"):
+		id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/members/"), "/")
+		if id == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		id, ok := resolveMemberID(w, r, id)
+		if !ok {
+			return
+		}
+
+		if action != "" {
+			h.routeAction(w, r, id, action)
+			return
+		}
+
+		if r.Method != http.MethodGet {
+			h.methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		h.handleRetrieve(w, r, id)
+	default:
+		h.methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (h *membersHandler) routeAction(w http.ResponseWriter, r *http.Request, id, action string) {
+	switch action {
+	case "calendar.ics":
+		if r.Method != http.MethodGet {
+			h.methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		h.handleCalendarFeed(w, r, id)
+	case "calendar-token":
+		if r.Method != http.MethodPost {
+			h.methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		h.handleIssueCalendarToken(w, r, id)
+	case "availability-import":
+		if r.Method != http.MethodPost {
+			h.methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		h.handleImportAvailability(w, r, id)
+	case "calendar-source":
+		switch r.Method {
+		case http.MethodGet:
+			h.handleRetrieveCalendarSource(w, r, id)
+		case http.MethodPut:
+			h.handlePutCalendarSource(w, r, id)
+		case http.MethodDelete:
+			h.handleDeleteCalendarSource(w, r, id)
+		default:
+			h.methodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodDelete)
+		}
+	case "calendar-source/sync":
+		if r.Method != http.MethodPost {
+			h.methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		h.handleSyncCalendarSource(w, r, id)
+	case "notification-preferences":
+		switch r.Method {
+		case http.MethodGet:
+			h.handleRetrieveNotificationPreferences(w, r, id)
+		case http.MethodPut:
+			h.handlePutNotificationPreferences(w, r, id)
+		default:
+			h.methodNotAllowed(w, http.MethodGet, http.MethodPut)
+		}
+	default:
+		if segments := strings.Split(action, "/"); segments[0] == "notifications" {
+			h.routeNotifications(w, r, id, segments[1:])
+			return
+		}
+		http.NotFound(w, r)
+	}
+}
+
+func (h *membersHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var payload createMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid member payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	chapterID, err := uuid.Parse(strings.TrimSpace(payload.ChapterID))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "chapterId must be a valid chapter id")
+		return
+	}
+
+	if strings.TrimSpace(payload.DisplayName) == "" {
+		writeJSONError(w, http.StatusBadRequest, "displayName is required")
+		return
+	}
+
+	role := strings.TrimSpace(payload.Role)
+	if role == "" {
+		role = database.RoleMember
+	}
+	if _, ok := memberRoles[role]; !ok {
+		writeJSONError(w, http.StatusBadRequest, "role must be one of member, chapter_lead, facilitator, skill_steward")
+		return
+	}
+
+	if _, err := database.GetChapter(ctx, h.db, chapterID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "chapter not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to retrieve chapter", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	record, err := database.CreateMember(ctx, h.db, database.MemberInput{
+		ChapterID:   chapterID,
+		DisplayName: strings.TrimSpace(payload.DisplayName),
+		Email:       strings.TrimSpace(payload.Email),
+		Role:        role,
+	})
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to persist member", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(toMemberResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *membersHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var chapterID *uuid.UUID
+	if value := strings.TrimSpace(r.URL.Query().Get("chapter")); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "chapter must be a valid chapter id")
+			return
+		}
+		chapterID = &parsed
+	}
+
+	members, err := database.ListMembers(ctx, h.db, chapterID)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list members", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	responses := make([]memberResponse, 0, len(members))
+	for _, member := range members {
+		responses = append(responses, toMemberResponse(member))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listMemberResponse{Items: responses}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *membersHandler) handleRetrieve(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	uuidValue, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid member id")
+		return
+	}
+
+	record, err := database.GetMember(ctx, h.db, uuidValue)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "member not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to retrieve member", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toMemberResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *membersHandler) methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func toMemberResponse(member database.Member) memberResponse {
+	return memberResponse{
+		ID:          member.ID.String(),
+		ChapterID:   member.ChapterID.String(),
+		DisplayName: member.DisplayName,
+		Email:       member.Email,
+		Role:        member.Role,
+		CreatedAt:   member.CreatedAt.Format(time.RFC3339),
+	}
+}
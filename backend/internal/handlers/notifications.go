@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/notify"
+	"github.com/google/uuid"
+)
+
+// memberHeader names the acting member for /api/members/me routes until the
+// API gains authentication.
+const memberHeader = "X-Member-ID"
+
+type notificationResponse struct {
+	ID        string  `json:"id"`
+	Type      string  `json:"type"`
+	Title     string  `json:"title"`
+	Body      string  `json:"body"`
+	CreatedAt string  `json:"createdAt"`
+	ReadAt    *string `json:"readAt"`
+}
+
+type listNotificationResponse struct {
+	Items  []notificationResponse `json:"items"`
+	Unread int                    `json:"unread"`
+}
+
+type markReadResponse struct {
+	Marked int `json:"marked"`
+}
+
+type notificationPreferencesRequest struct {
+	Channels            map[string][]string `json:"channels"`
+	QuietDuringSessions *bool               `json:"quietDuringSessions"`
+	ChatWebhookURL      string              `json:"chatWebhookUrl"`
+}
+
+type notificationPreferencesResponse struct {
+	MemberID            string              `json:"memberId"`
+	Channels            map[string][]string `json:"channels"`
+	QuietDuringSessions bool                `json:"quietDuringSessions"`
+	ChatWebhookURL      string              `json:"chatWebhookUrl"`
+	UpdatedAt           *string             `json:"updatedAt"`
+}
+
+// resolveMemberID maps the "me" path segment to the member named by the
+// X-Member-ID header.
+func resolveMemberID(w http.ResponseWriter, r *http.Request, id string) (string, bool) {
+	if id != "me" {
+		return id, true
+	}
+
+	value := strings.TrimSpace(r.Header.Get(memberHeader))
+	if value == "" {
+		writeJSONError(w, http.StatusUnauthorized, memberHeader+" header is required")
+		return "", false
+	}
+
+	return value, true
+}
+
+func (h *membersHandler) routeNotifications(w http.ResponseWriter, r *http.Request, id string, segments []string) {
+	switch {
+	case len(segments) == 0 && r.Method == http.MethodGet:
+		h.handleListNotifications(w, r, id)
+	case len(segments) == 1 && segments[0] == "read" && r.Method == http.MethodPost:
+		h.handleMarkInboxRead(w, r, id)
+	case len(segments) == 2 && segments[1] == "read" && r.Method == http.MethodPost:
+		h.handleMarkNotificationRead(w, r, id, segments[0])
+	case len(segments) == 0:
+		h.methodNotAllowed(w, http.MethodGet)
+	case len(segments) == 1 && segments[0] == "read", len(segments) == 2 && segments[1] == "read":
+		h.methodNotAllowed(w, http.MethodPost)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *membersHandler) handleListNotifications(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	member, ok := h.loadMember(w, r, id)
+	if !ok {
+		return
+	}
+
+	limit := parsePositiveInt(r.URL.Query().Get("limit"), 50)
+	if limit < 1 || limit > 200 {
+		writeJSONError(w, http.StatusBadRequest, "limit must be between 1 and 200")
+		return
+	}
+
+	notifications, unread, err := database.ListInbox(ctx, h.db, member.ID, r.URL.Query().Get("unread") == "true", limit)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list notifications", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response := listNotificationResponse{Items: make([]notificationResponse, 0, len(notifications)), Unread: unread}
+	for _, notification := range notifications {
+		response.Items = append(response.Items, toNotificationResponse(notification))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *membersHandler) handleMarkNotificationRead(w http.ResponseWriter, r *http.Request, id, notificationID string) {
+	ctx := r.Context()
+
+	memberID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid member id")
+		return
+	}
+
+	parsed, err := uuid.Parse(notificationID)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid notification id")
+		return
+	}
+
+	notification, err := database.MarkNotificationRead(ctx, h.db, memberID, parsed)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "notification not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to mark notification read", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toNotificationResponse(notification)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *membersHandler) handleMarkInboxRead(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	member, ok := h.loadMember(w, r, id)
+	if !ok {
+		return
+	}
+
+	marked, err := database.MarkInboxRead(ctx, h.db, member.ID)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to mark notifications read", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(markReadResponse{Marked: marked}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *membersHandler) handleRetrieveNotificationPreferences(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	member, ok := h.loadMember(w, r, id)
+	if !ok {
+		return
+	}
+
+	prefs, err := database.GetNotificationPreferences(ctx, h.db, member.ID)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to retrieve notification preferences", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toNotificationPreferencesResponse(prefs)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func parseNotificationPreferences(memberID uuid.UUID, payload notificationPreferencesRequest) (database.NotificationPreferences, error) {
+	prefs := database.NotificationPreferences{
+		MemberID:            memberID,
+		Channels:            make(map[string][]string, len(payload.Channels)),
+		QuietDuringSessions: true,
+	}
+	if payload.QuietDuringSessions != nil {
+		prefs.QuietDuringSessions = *payload.QuietDuringSessions
+	}
+
+	if value := strings.TrimSpace(payload.ChatWebhookURL); value != "" {
+		parsed, err := url.Parse(value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return database.NotificationPreferences{}, errors.New("chatWebhookUrl must be an absolute http or https URL")
+		}
+		prefs.ChatWebhookURL = parsed.String()
+	}
+
+	for notificationType, channels := range payload.Channels {
+		if !notify.ValidType(notificationType) {
+			return database.NotificationPreferences{}, errors.New("channels has unknown notification type " + notificationType)
+		}
+
+		chosen := make([]string, 0, len(channels))
+		seen := make(map[string]struct{}, len(channels))
+		for _, channel := range channels {
+			if !notify.ValidChannel(channel) {
+				return database.NotificationPreferences{}, errors.New("channels must be one of inbox, email or chat")
+			}
+			if channel == notify.ChannelChat && prefs.ChatWebhookURL == "" {
+				return database.NotificationPreferences{}, errors.New("chatWebhookUrl is required to use the chat channel")
+			}
+			if _, ok := seen[channel]; ok {
+				continue
+			}
+			seen[channel] = struct{}{}
+			chosen = append(chosen, channel)
+		}
+		prefs.Channels[notificationType] = chosen
+	}
+
+	return prefs, nil
+}
+
+func (h *membersHandler) handlePutNotificationPreferences(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	member, ok := h.loadMember(w, r, id)
+	if !ok {
+		return
+	}
+
+	var payload notificationPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid notification preferences payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	prefs, err := parseNotificationPreferences(member.ID, payload)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	prefs, err = database.PutNotificationPreferences(ctx, h.db, prefs)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to persist notification preferences", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toNotificationPreferencesResponse(prefs)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func toNotificationResponse(notification database.Notification) notificationResponse {
+	response := notificationResponse{
+		ID:        notification.ID.String(),
+		Type:      notification.Type,
+		Title:     notification.Title,
+		Body:      notification.Body,
+		CreatedAt: notification.CreatedAt.Format(time.RFC3339),
+	}
+	if notification.ReadAt != nil {
+		readAt := notification.ReadAt.Format(time.RFC3339)
+		response.ReadAt = &readAt
+	}
+	return response
+}
+
+// toNotificationPreferencesResponse reports the channels in effect for every
+// notification type, filling in defaults for types the member left out.
+func toNotificationPreferencesResponse(prefs database.NotificationPreferences) notificationPreferencesResponse {
+	response := notificationPreferencesResponse{
+		MemberID:            prefs.MemberID.String(),
+		Channels:            make(map[string][]string, len(notify.Types)),
+		QuietDuringSessions: prefs.QuietDuringSessions,
+		ChatWebhookURL:      prefs.ChatWebhookURL,
+	}
+	for _, notificationType := range notify.Types {
+		response.Channels[notificationType] = notify.ChannelsFor(prefs, notificationType)
+	}
+	if prefs.UpdatedAt != nil {
+		updatedAt := prefs.UpdatedAt.Format(time.RFC3339)
+		response.UpdatedAt = &updatedAt
+	}
+	return response
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestMembersHandlerMeRequiresMemberHeader(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	req := httptest.NewRequest(http.MethodGet, "/api/members/me/notifications", nil)
+	rr := httptest.NewRecorder()
+
+	MembersHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status %d got %d", http.StatusUnauthorized, rr.Code)
+	}
+}
+
+func TestMembersHandlerListsInboxForMe(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	memberID, notificationID := uuid.New(), uuid.New()
+	now := time.Now().UTC()
+
+	expectMemberLookup(mock, memberID, uuid.New())
+	mock.ExpectQuery(regexp.QuoteMeta("AND read_at IS NULL ORDER BY inbox_at DESC")).
+		WithArgs(memberID, 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "member_id", "type", "title", "body", "created_at", "inbox_at", "read_at"}).
+			AddRow(notificationID, memberID, "session_nudge", "Declare your intent", "Body", now, now, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM notifications")).
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/members/me/notifications?unread=true", nil)
+	req.Header.Set("X-Member-ID", memberID.String())
+	rr := httptest.NewRecorder()
+
+	MembersHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response listNotificationResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Unread != 1 || len(response.Items) != 1 || response.Items[0].ID != notificationID.String() || response.Items[0].ReadAt != nil {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestMembersHandlerMarkNotificationReadNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	memberID, notificationID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE notifications SET read_at = COALESCE(read_at, $3)")).
+		WithArgs(notificationID, memberID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/members/me/notifications/"+notificationID.String()+"/read", nil)
+	req.Header.Set("X-Member-ID", memberID.String())
+	rr := httptest.NewRecorder()
+
+	MembersHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d got %d", http.StatusNotFound, rr.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestMembersHandlerNotificationPreferencesChatNeedsWebhook(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	memberID := uuid.New()
+	expectMemberLookup(mock, memberID, uuid.New())
+
+	body := []byte(`{"channels":{"session_nudge":["inbox","chat"]}}`)
+	req := httptest.NewRequest(http.MethodPut, "/api/members/"+memberID.String()+"/notification-preferences", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	MembersHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestMembersHandlerNotificationPreferencesDefaults(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	memberID := uuid.New()
+	expectMemberLookup(mock, memberID, uuid.New())
+	mock.ExpectQuery(regexp.QuoteMeta("FROM notification_preferences")).
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"channels", "quiet_during_sessions", "chat_webhook_url", "updated_at"}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/members/"+memberID.String()+"/notification-preferences", nil)
+	rr := httptest.NewRecorder()
+
+	MembersHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d", http.StatusOK, rr.Code)
+	}
+
+	var response notificationPreferencesResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !response.QuietDuringSessions || response.UpdatedAt != nil || len(response.Channels["session_nudge"]) != 2 {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+}
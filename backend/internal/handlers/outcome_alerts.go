@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+type missingOutcomeResponse struct {
+	IntentID        string  `json:"intentId"`
+	Statement       string  `json:"statement"`
+	MemberID        *string `json:"memberId"`
+	SessionID       string  `json:"sessionId"`
+	SessionEndsAt   string  `json:"sessionEndsAt"`
+	SessionClosedAt *string `json:"sessionClosedAt"`
+}
+
+type listMissingOutcomeResponse struct {
+	Items []missingOutcomeResponse `json:"items"`
+}
+
+type lateOutcomeResponse struct {
+	Outcome     outcomeResponse `json:"outcome"`
+	DraftIntent *intentResponse `json:"draftIntent"`
+}
+
+func (h *goalsHandler) handleMissingOutcomes(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	goalID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid goal id")
+		return
+	}
+
+	if _, err := database.GetGoal(ctx, h.db, goalID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "goal not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to retrieve goal", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	missing, err := database.ListMissingOutcomes(ctx, h.db, database.MissingOutcomeFilters{GoalID: &goalID})
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list missing outcomes", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response := listMissingOutcomeResponse{Items: make([]missingOutcomeResponse, 0, len(missing))}
+	for _, item := range missing {
+		entry := missingOutcomeResponse{
+			IntentID:      item.IntentID.String(),
+			Statement:     item.Statement,
+			MemberID:      formatOptionalUUID(item.MemberID),
+			SessionID:     item.SessionID.String(),
+			SessionEndsAt: item.SessionEndsAt.Format(time.RFC3339),
+		}
+		if item.SessionClosedAt != nil {
+			closedAt := item.SessionClosedAt.Format(time.RFC3339)
+			entry.SessionClosedAt = &closedAt
+		}
+		response.Items = append(response.Items, entry)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *sessionsHandler) handleLateOutcome(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID) {
+	ctx := r.Context()
+
+	var payload outcomeRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid outcome payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	entries, err := parseCloseoutPayload(closeoutRequest{Outcomes: []outcomeRequest{payload}})
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if entries[0].IntentID == nil {
+		writeJSONError(w, http.StatusBadRequest, "intentId is required")
+		return
+	}
+
+	outcome, draft, err := database.RecordLateOutcome(ctx, h.db, sessionID, entries[0])
+	if err != nil {
+		switch {
+		case isForeignKeyViolation(err):
+			writeJSONError(w, http.StatusBadRequest, "nextIntent references a goal that does not exist")
+		default:
+			h.writeSchedulingError(ctx, w, err, "failed to record outcome")
+		}
+		return
+	}
+
+	response := lateOutcomeResponse{Outcome: toOutcomeResponses([]database.SessionOutcome{outcome})[0]}
+	if draft != nil {
+		intent := toIntentResponse(*draft)
+		response.DraftIntent = &intent
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestGoalsHandlerMissingOutcomes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	goalID, intentID, memberID, sessionID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	endsAt := time.Date(2024, 5, 6, 17, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goals")).
+		WithArgs(goalID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "clarity_statement", "guardrails", "decision_rights", "constraints", "success_criteria", "parent_goal_id", "created_at", "updated_at", "status", "closing_summary", "achieved_at", "tags"}).
+			AddRow(goalID, "Reliability", "Fewer pages", `[]`, `[]`, `[]`, `[]`, nil, endsAt, endsAt, "active", nil, nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("AND i.goal_id = $2 ORDER BY s.ends_at")).
+		WithArgs(sqlmock.AnyArg(), goalID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "statement", "member_id", "goal_id", "id", "chapter_id", "ends_at", "closed_at"}).
+			AddRow(intentID, "Ship retry dashboard", memberID, goalID, sessionID, uuid.New(), endsAt, nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/goals/"+goalID.String()+"/missing-outcomes", nil)
+	rr := httptest.NewRecorder()
+
+	GoalsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response listMissingOutcomeResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Items) != 1 || response.Items[0].IntentID != intentID.String() || response.Items[0].SessionClosedAt != nil {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestGoalsHandlerMissingOutcomesUnknownGoal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	goalID := uuid.New()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM goals")).
+		WithArgs(goalID).
+		WillReturnError(sql.ErrNoRows)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/goals/"+goalID.String()+"/missing-outcomes", nil)
+	rr := httptest.NewRecorder()
+
+	GoalsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status %d got %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+func TestSessionsHandlerLateOutcomeRequiresIntent(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	body := []byte(`{"memberId":"` + uuid.NewString() + `","outcome":"Shipped it"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/"+uuid.NewString()+"/outcomes", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	SessionsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d got %d", http.StatusBadRequest, rr.Code)
+	}
+}
@@ -0,0 +1,473 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/retro"
+	"github.com/google/uuid"
+)
+
+type createRetroTemplateRequest struct {
+	ChapterID string           `json:"chapterId"`
+	Name      string           `json:"name"`
+	Anonymous bool             `json:"anonymous"`
+	Questions []retro.Question `json:"questions"`
+}
+
+type retroTemplateResponse struct {
+	ID        string           `json:"id"`
+	ChapterID *string          `json:"chapterId"`
+	Name      string           `json:"name"`
+	Anonymous bool             `json:"anonymous"`
+	Questions []retro.Question `json:"questions"`
+	CreatedAt string           `json:"createdAt"`
+	UpdatedAt string           `json:"updatedAt"`
+}
+
+type listRetroTemplateResponse struct {
+	Items []retroTemplateResponse `json:"items"`
+}
+
+type openRetroRequest struct {
+	TemplateID string `json:"templateId"`
+}
+
+type retroAnswersRequest struct {
+	MemberID string         `json:"memberId"`
+	Answers  map[string]any `json:"answers"`
+}
+
+type retroSurveyResponse struct {
+	ID         string           `json:"id"`
+	SessionID  string           `json:"sessionId"`
+	TemplateID *string          `json:"templateId"`
+	Anonymous  bool             `json:"anonymous"`
+	Questions  []retro.Question `json:"questions"`
+	OpenedAt   string           `json:"openedAt"`
+}
+
+type retroTextAnswerResponse struct {
+	Text     string  `json:"text"`
+	MemberID *string `json:"memberId,omitempty"`
+}
+
+type retroResultResponse struct {
+	QuestionID   string                    `json:"questionId"`
+	Prompt       string                    `json:"prompt"`
+	Kind         string                    `json:"kind"`
+	Answered     int                       `json:"answered"`
+	Average      *float64                  `json:"average,omitempty"`
+	Distribution map[string]int            `json:"distribution,omitempty"`
+	TextAnswers  []retroTextAnswerResponse `json:"textAnswers,omitempty"`
+}
+
+type sessionRetroResponse struct {
+	Survey       retroSurveyResponse   `json:"survey"`
+	Participants int                   `json:"participants"`
+	Responses    int                   `json:"responses"`
+	Results      []retroResultResponse `json:"results"`
+}
+
+type chapterRetroGroupResponse struct {
+	TemplateID *string               `json:"templateId"`
+	Surveys    int                   `json:"surveys"`
+	Responses  int                   `json:"responses"`
+	Results    []retroResultResponse `json:"results"`
+}
+
+type chapterRetroResponse struct {
+	ChapterID string                      `json:"chapterId"`
+	Templates []chapterRetroGroupResponse `json:"templates"`
+}
+
+type retroTemplatesHandler struct {
+	logger *slog.Logger
+	db     *sql.DB
+}
+
+// RetroTemplatesHandler routes operations for retro survey templates.
+func RetroTemplatesHandler(logger *slog.Logger, db *sql.DB) http.Handler {
+	return &retroTemplatesHandler{logger: logger, db: db}
+}
+
+func (h *retroTemplatesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/retro-templates":
+		h.handleCreate(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/api/retro-templates":
+		h.handleList(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/retro-templates/"):
+		id := strings.TrimPrefix(r.URL.Path, "/api/retro-templates/")
+		if id == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			h.handleRetrieve(w, r, id)
+		case http.MethodDelete:
+			h.handleDelete(w, r, id)
+		default:
+			h.methodNotAllowed(w, http.MethodGet, http.MethodDelete)
+		}
+	default:
+		h.methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func parseRetroTemplatePayload(payload createRetroTemplateRequest) (database.RetroTemplateInput, error) {
+	input := database.RetroTemplateInput{
+		Name:      strings.TrimSpace(payload.Name),
+		Anonymous: payload.Anonymous,
+		Questions: make([]retro.Question, 0, len(payload.Questions)),
+	}
+
+	if input.Name == "" {
+		return database.RetroTemplateInput{}, errors.New("name is required")
+	}
+
+	if value := strings.TrimSpace(payload.ChapterID); value != "" {
+		chapterID, err := uuid.Parse(value)
+		if err != nil {
+			return database.RetroTemplateInput{}, errors.New("chapterId must be a valid chapter id")
+		}
+		input.ChapterID = &chapterID
+	}
+
+	for _, question := range payload.Questions {
+		question.ID = strings.TrimSpace(question.ID)
+		question.Prompt = strings.TrimSpace(question.Prompt)
+		question.Kind = strings.TrimSpace(question.Kind)
+		for i, option := range question.Options {
+			question.Options[i] = strings.TrimSpace(option)
+		}
+		if question.Kind != retro.KindScale {
+			question.Min, question.Max = 0, 0
+		}
+		if question.Kind != retro.KindChoice {
+			question.Options = nil
+		}
+		input.Questions = append(input.Questions, question)
+	}
+
+	if err := retro.ValidateQuestions(input.Questions); err != nil {
+		return database.RetroTemplateInput{}, err
+	}
+
+	return input, nil
+}
+
+func (h *retroTemplatesHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var payload createRetroTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid retro template payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input, err := parseRetroTemplatePayload(payload)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	record, err := database.CreateRetroTemplate(ctx, h.db, input)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			writeJSONError(w, http.StatusBadRequest, "chapterId references a chapter that does not exist")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to persist retro template", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(toRetroTemplateResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *retroTemplatesHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var chapterID *uuid.UUID
+	if value := strings.TrimSpace(r.URL.Query().Get("chapter")); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "chapter must be a valid chapter id")
+			return
+		}
+		chapterID = &parsed
+	}
+
+	templates, err := database.ListRetroTemplates(ctx, h.db, chapterID)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list retro templates", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	responses := make([]retroTemplateResponse, 0, len(templates))
+	for _, template := range templates {
+		responses = append(responses, toRetroTemplateResponse(template))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listRetroTemplateResponse{Items: responses}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *retroTemplatesHandler) handleRetrieve(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	templateID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid retro template id")
+		return
+	}
+
+	record, err := database.GetRetroTemplate(ctx, h.db, templateID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "retro template not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to retrieve retro template", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toRetroTemplateResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *retroTemplatesHandler) handleDelete(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	templateID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid retro template id")
+		return
+	}
+
+	if err := database.DeleteRetroTemplate(ctx, h.db, templateID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "retro template not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to delete retro template", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *retroTemplatesHandler) methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func (h *sessionsHandler) routeRetro(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID, segments []string) {
+	switch {
+	case len(segments) == 0 && r.Method == http.MethodGet:
+		h.handleSessionRetro(w, r, sessionID)
+	case len(segments) == 0 && r.Method == http.MethodPost:
+		h.handleOpenRetro(w, r, sessionID)
+	case len(segments) == 0:
+		h.methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	case len(segments) == 1 && segments[0] == "responses" && r.Method == http.MethodPost:
+		h.handleSubmitRetro(w, r, sessionID)
+	case len(segments) == 1 && segments[0] == "responses":
+		h.methodNotAllowed(w, http.MethodPost)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *sessionsHandler) handleSessionRetro(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID) {
+	ctx := r.Context()
+
+	sessionRetro, err := database.GetSessionRetro(ctx, h.db, sessionID)
+	if err != nil {
+		h.writeSchedulingError(ctx, w, err, "failed to retrieve session retro")
+		return
+	}
+
+	response := sessionRetroResponse{
+		Survey:       toRetroSurveyResponse(sessionRetro.Survey),
+		Participants: sessionRetro.Participants,
+		Responses:    len(sessionRetro.Responses) + sessionRetro.Tally.Responses,
+		Results:      toRetroResultResponses(retro.Aggregate(sessionRetro.Survey.Questions, sessionRetro.Responses, sessionRetro.Tally, true)),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *sessionsHandler) handleOpenRetro(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID) {
+	ctx := r.Context()
+
+	var payload openRetroRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.WarnContext(ctx, "invalid retro payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var templateID *uuid.UUID
+	if value := strings.TrimSpace(payload.TemplateID); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "templateId must be a valid retro template id")
+			return
+		}
+		templateID = &parsed
+	}
+
+	survey, err := database.OpenRetroSurvey(ctx, h.db, sessionID, templateID)
+	if err != nil {
+		h.writeSchedulingError(ctx, w, err, "failed to open retro survey")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(toRetroSurveyResponse(survey)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *sessionsHandler) handleSubmitRetro(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID) {
+	ctx := r.Context()
+
+	var payload retroAnswersRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid retro response payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	memberID, err := uuid.Parse(strings.TrimSpace(payload.MemberID))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "memberId must be a valid member id")
+		return
+	}
+
+	if err := database.SubmitRetroResponse(ctx, h.db, sessionID, memberID, payload.Answers); err != nil {
+		h.writeSchedulingError(ctx, w, err, "failed to record retro response")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *chaptersHandler) handleChapterRetro(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	chapterID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid chapter id")
+		return
+	}
+
+	if _, err := database.GetChapter(ctx, h.db, chapterID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "chapter not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to retrieve chapter", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	groups, err := database.ListChapterRetro(ctx, h.db, chapterID)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list chapter retro", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	response := chapterRetroResponse{
+		ChapterID: chapterID.String(),
+		Templates: make([]chapterRetroGroupResponse, 0, len(groups)),
+	}
+	for _, group := range groups {
+		response.Templates = append(response.Templates, chapterRetroGroupResponse{
+			TemplateID: formatOptionalUUID(group.TemplateID),
+			Surveys:    group.Surveys,
+			Responses:  len(group.Responses) + group.Tally.Responses,
+			// Free text stays on the session view; the chapter view only
+			// carries counts.
+			Results: toRetroResultResponses(retro.Aggregate(group.Questions, group.Responses, group.Tally, false)),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func toRetroTemplateResponse(template database.RetroTemplate) retroTemplateResponse {
+	return retroTemplateResponse{
+		ID:        template.ID.String(),
+		ChapterID: formatOptionalUUID(template.ChapterID),
+		Name:      template.Name,
+		Anonymous: template.Anonymous,
+		Questions: template.Questions,
+		CreatedAt: template.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: template.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func toRetroSurveyResponse(survey database.RetroSurvey) retroSurveyResponse {
+	return retroSurveyResponse{
+		ID:         survey.ID.String(),
+		SessionID:  survey.SessionID.String(),
+		TemplateID: formatOptionalUUID(survey.TemplateID),
+		Anonymous:  survey.Anonymous,
+		Questions:  survey.Questions,
+		OpenedAt:   survey.OpenedAt.Format(time.RFC3339),
+	}
+}
+
+func toRetroResultResponses(results []retro.Result) []retroResultResponse {
+	responses := make([]retroResultResponse, 0, len(results))
+	for _, result := range results {
+		response := retroResultResponse{
+			QuestionID:   result.Question.ID,
+			Prompt:       result.Question.Prompt,
+			Kind:         result.Question.Kind,
+			Answered:     result.Answered,
+			Average:      result.Average,
+			Distribution: result.Distribution,
+		}
+		for _, answer := range result.TextAnswers {
+			response.TextAnswers = append(response.TextAnswers, retroTextAnswerResponse{
+				Text:     answer.Text,
+				MemberID: formatOptionalUUID(answer.Respondent),
+			})
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+const retroHandlerQuestions = `[{"id":"energy","prompt":"How was your energy?","kind":"scale","min":1,"max":5,"required":true},` +
+	`{"id":"notes","prompt":"Anything else?","kind":"text","required":false}]`
+
+func TestSessionsHandlerRetroAnonymousHidesRespondents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, surveyID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM retro_surveys")).
+		WithArgs(sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "session_id", "template_id", "anonymous", "questions", "opened_at", "count"}).
+			AddRow(surveyID, sessionID, uuid.New(), true, []byte(retroHandlerQuestions), time.Now(), 3))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM retro_responses")).
+		WillReturnRows(sqlmock.NewRows([]string{"survey_id", "member_id", "answers"}))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM retro_tallies")).
+		WillReturnRows(sqlmock.NewRows([]string{"survey_id", "tally"}).
+			AddRow(surveyID, []byte(`{"responses":2,"answers":{"energy":[2,5],"notes":["More pairing"]}}`)))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sessions/"+sessionID.String()+"/retro", nil)
+	rr := httptest.NewRecorder()
+
+	SessionsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response sessionRetroResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+
+	if response.Participants != 3 || response.Responses != 2 {
+		t.Fatalf("expected 3 participants and 2 responses got %d and %d", response.Participants, response.Responses)
+	}
+
+	if average := response.Results[0].Average; average == nil || *average != 3.5 {
+		t.Fatalf("expected average 3.5 got %v", average)
+	}
+
+	notes := response.Results[1].TextAnswers
+	if len(notes) != 1 || notes[0].MemberID != nil {
+		t.Fatalf("expected one unattributed text answer got %+v", notes)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSessionsHandlerRetroResponseRejectsInvalidAnswer(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, memberID := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM retro_surveys rs")).
+		WithArgs(sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "session_id", "template_id", "anonymous", "questions", "opened_at", "chapter_id"}).
+			AddRow(uuid.New(), sessionID, nil, false, []byte(retroHandlerQuestions), time.Now(), chapterID))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT chapter_id FROM members WHERE id = $1")).
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"chapter_id"}).AddRow(chapterID))
+	mock.ExpectRollback()
+
+	body := []byte(`{"memberId":"` + memberID.String() + `","answers":{"notes":"Skipped the scale"}}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/"+sessionID.String()+"/retro/responses", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	SessionsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d got %d: %s", http.StatusBadRequest, rr.Code, rr.Body.String())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestRetroTemplatesHandlerCreateValidatesQuestions(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	body := []byte(`{"name":"Pulse","questions":[{"id":"format","prompt":"Keep the format?","kind":"choice","options":["yes"]}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/retro-templates", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	RetroTemplatesHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestChaptersHandlerRetroOmitsTextAnswers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	chapterID, templateID, surveyID, memberID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	expectChapterLookup(mock, chapterID, "UTC", 3)
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE s.chapter_id = $1")).
+		WithArgs(chapterID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "session_id", "template_id", "anonymous", "questions", "opened_at"}).
+			AddRow(surveyID, uuid.New(), templateID, false, []byte(retroHandlerQuestions), time.Now()))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM retro_responses")).
+		WillReturnRows(sqlmock.NewRows([]string{"survey_id", "member_id", "answers"}).
+			AddRow(surveyID, memberID, []byte(`{"energy":4,"notes":"Good flow"}`)))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM retro_tallies")).
+		WillReturnRows(sqlmock.NewRows([]string{"survey_id", "tally"}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/chapters/"+chapterID.String()+"/retro", nil)
+	rr := httptest.NewRecorder()
+
+	ChaptersHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response chapterRetroResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+
+	if len(response.Templates) != 1 || response.Templates[0].Responses != 1 {
+		t.Fatalf("expected one template with one response got %+v", response.Templates)
+	}
+
+	notes := response.Templates[0].Results[1]
+	if notes.Answered != 1 || len(notes.TextAnswers) != 0 {
+		t.Fatalf("expected text answers to be counted only got %+v", notes)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,345 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+type kickoffRequest struct {
+	Swarms []swarmKickoffRequest `json:"swarms"`
+}
+
+type swarmKickoffRequest struct {
+	SwarmID        string   `json:"swarmId"`
+	IntentIDs      []string `json:"intentIds"`
+	Dependencies   []string `json:"dependencies"`
+	AcknowledgedBy []string `json:"guardrailsAcknowledgedBy"`
+}
+
+type swarmKickoffResponse struct {
+	SwarmID        string   `json:"swarmId"`
+	IntentIDs      []string `json:"intentIds"`
+	Dependencies   []string `json:"dependencies"`
+	Guardrails     []string `json:"guardrails"`
+	AcknowledgedBy []string `json:"guardrailsAcknowledgedBy"`
+	CreatedAt      string   `json:"createdAt"`
+}
+
+type kickoffResponse struct {
+	Session sessionResponse        `json:"session"`
+	Swarms  []swarmKickoffResponse `json:"swarms"`
+}
+
+type closeoutRequest struct {
+	Outcomes []outcomeRequest `json:"outcomes"`
+}
+
+type outcomeRequest struct {
+	MemberID          string               `json:"memberId"`
+	IntentID          string               `json:"intentId"`
+	Outcome           string               `json:"outcome"`
+	Obstacles         string               `json:"obstacles"`
+	SatisfiedCriteria []string             `json:"satisfiedCriteria"`
+	NextIntent        *createIntentRequest `json:"nextIntent"`
+}
+
+type outcomeResponse struct {
+	ID                string   `json:"id"`
+	MemberID          string   `json:"memberId"`
+	IntentID          *string  `json:"intentId"`
+	Outcome           string   `json:"outcome"`
+	Obstacles         string   `json:"obstacles"`
+	SatisfiedCriteria []string `json:"satisfiedCriteria"`
+	NextIntentID      *string  `json:"nextIntentId"`
+	CreatedAt         string   `json:"createdAt"`
+}
+
+type closeoutResponse struct {
+	Session       sessionResponse      `json:"session"`
+	Outcomes      []outcomeResponse    `json:"outcomes"`
+	DraftIntents  []intentResponse     `json:"draftIntents"`
+	NextSessionID *string              `json:"nextSessionId"`
+	RetroSurvey   *retroSurveyResponse `json:"retroSurvey"`
+}
+
+type listSwarmKickoffResponse struct {
+	Items []swarmKickoffResponse `json:"items"`
+}
+
+type listOutcomeResponse struct {
+	Items []outcomeResponse `json:"items"`
+}
+
+func (h *sessionsHandler) routeRitual(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID, ritual string) {
+	switch {
+	case ritual == "kickoff" && r.Method == http.MethodPost:
+		h.handleKickoff(w, r, sessionID)
+	case ritual == "kickoff" && r.Method == http.MethodGet:
+		h.handleListKickoff(w, r, sessionID)
+	case ritual == "closeout" && r.Method == http.MethodPost:
+		h.handleCloseout(w, r, sessionID)
+	case ritual == "closeout" && r.Method == http.MethodGet:
+		h.handleListOutcomes(w, r, sessionID)
+	default:
+		h.methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (h *sessionsHandler) handleKickoff(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID) {
+	ctx := r.Context()
+
+	var payload kickoffRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid kickoff payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	inputs, err := parseKickoffPayload(payload)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	session, kickoffs, err := database.KickoffSession(ctx, h.db, sessionID, inputs)
+	if err != nil {
+		h.writeSchedulingError(ctx, w, err, "failed to kick off session")
+		return
+	}
+
+	response := kickoffResponse{
+		Session: toSessionResponse(session),
+		Swarms:  toSwarmKickoffResponses(kickoffs),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *sessionsHandler) handleListKickoff(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID) {
+	ctx := r.Context()
+
+	kickoffs, err := database.ListSessionKickoffs(ctx, h.db, sessionID)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list session kickoff", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listSwarmKickoffResponse{Items: toSwarmKickoffResponses(kickoffs)}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *sessionsHandler) handleCloseout(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID) {
+	ctx := r.Context()
+
+	var payload closeoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid closeout payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	entries, err := parseCloseoutPayload(payload)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	closeout, err := database.CloseoutSession(ctx, h.db, sessionID, entries)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			writeJSONError(w, http.StatusBadRequest, "nextIntent references a goal that does not exist")
+			return
+		}
+		h.writeSchedulingError(ctx, w, err, "failed to close out session")
+		return
+	}
+
+	response := closeoutResponse{
+		Session:       toSessionResponse(closeout.Session),
+		Outcomes:      toOutcomeResponses(closeout.Outcomes),
+		DraftIntents:  make([]intentResponse, 0, len(closeout.DraftIntents)),
+		NextSessionID: formatOptionalUUID(closeout.NextSessionID),
+	}
+	for _, intent := range closeout.DraftIntents {
+		response.DraftIntents = append(response.DraftIntents, toIntentResponse(intent))
+	}
+	if closeout.RetroSurvey != nil {
+		survey := toRetroSurveyResponse(*closeout.RetroSurvey)
+		response.RetroSurvey = &survey
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *sessionsHandler) handleListOutcomes(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID) {
+	ctx := r.Context()
+
+	outcomes, err := database.ListSessionOutcomes(ctx, h.db, sessionID)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list session outcomes", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listOutcomeResponse{Items: toOutcomeResponses(outcomes)}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func parseKickoffPayload(payload kickoffRequest) ([]database.SwarmKickoffInput, error) {
+	inputs := make([]database.SwarmKickoffInput, 0, len(payload.Swarms))
+	seen := make(map[uuid.UUID]struct{})
+	for _, swarm := range payload.Swarms {
+		swarmID, err := uuid.Parse(strings.TrimSpace(swarm.SwarmID))
+		if err != nil {
+			return nil, errors.New("swarmId must be a valid swarm id")
+		}
+		if _, ok := seen[swarmID]; ok {
+			return nil, errors.New("each swarm may only be kicked off once")
+		}
+		seen[swarmID] = struct{}{}
+
+		intentIDs, err := parseUUIDList(swarm.IntentIDs)
+		if err != nil {
+			return nil, errors.New("intentIds must contain valid intent ids")
+		}
+
+		acknowledgedBy, err := parseUUIDList(swarm.AcknowledgedBy)
+		if err != nil {
+			return nil, errors.New("guardrailsAcknowledgedBy must contain valid member ids")
+		}
+
+		dependencies := make([]string, 0, len(swarm.Dependencies))
+		for _, dependency := range swarm.Dependencies {
+			if trimmed := strings.TrimSpace(dependency); trimmed != "" {
+				dependencies = append(dependencies, trimmed)
+			}
+		}
+
+		inputs = append(inputs, database.SwarmKickoffInput{
+			SwarmID:        swarmID,
+			IntentIDs:      intentIDs,
+			Dependencies:   dependencies,
+			AcknowledgedBy: acknowledgedBy,
+		})
+	}
+
+	return inputs, nil
+}
+
+func parseCloseoutPayload(payload closeoutRequest) ([]database.SessionOutcomeInput, error) {
+	entries := make([]database.SessionOutcomeInput, 0, len(payload.Outcomes))
+	for _, outcome := range payload.Outcomes {
+		memberID, err := uuid.Parse(strings.TrimSpace(outcome.MemberID))
+		if err != nil {
+			return nil, errors.New("memberId must be a valid member id")
+		}
+
+		entry := database.SessionOutcomeInput{
+			MemberID:          memberID,
+			Outcome:           strings.TrimSpace(outcome.Outcome),
+			Obstacles:         strings.TrimSpace(outcome.Obstacles),
+			SatisfiedCriteria: normalizeGoalValues(outcome.SatisfiedCriteria),
+		}
+
+		if entry.Outcome == "" {
+			return nil, errors.New("outcome is required")
+		}
+
+		if value := strings.TrimSpace(outcome.IntentID); value != "" {
+			intentID, err := uuid.Parse(value)
+			if err != nil {
+				return nil, errors.New("intentId must be a valid intent id")
+			}
+			entry.IntentID = &intentID
+		}
+
+		if outcome.NextIntent != nil {
+			if err := validateIntentPayload(*outcome.NextIntent); err != nil {
+				return nil, errors.New("nextIntent: " + err.Error())
+			}
+			input, err := intentInputFromPayload(*outcome.NextIntent)
+			if err != nil {
+				return nil, errors.New("nextIntent: " + err.Error())
+			}
+			entry.NextIntent = &input
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func parseUUIDList(values []string) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(values))
+	seen := make(map[uuid.UUID]struct{})
+	for _, raw := range values {
+		id, err := uuid.Parse(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func toSwarmKickoffResponses(kickoffs []database.SwarmKickoff) []swarmKickoffResponse {
+	responses := make([]swarmKickoffResponse, 0, len(kickoffs))
+	for _, kickoff := range kickoffs {
+		responses = append(responses, swarmKickoffResponse{
+			SwarmID:        kickoff.SwarmID.String(),
+			IntentIDs:      formatUUIDs(kickoff.IntentIDs),
+			Dependencies:   kickoff.Dependencies,
+			Guardrails:     kickoff.Guardrails,
+			AcknowledgedBy: formatUUIDs(kickoff.AcknowledgedBy),
+			CreatedAt:      kickoff.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return responses
+}
+
+func toOutcomeResponses(outcomes []database.SessionOutcome) []outcomeResponse {
+	responses := make([]outcomeResponse, 0, len(outcomes))
+	for _, outcome := range outcomes {
+		responses = append(responses, outcomeResponse{
+			ID:                outcome.ID.String(),
+			MemberID:          outcome.MemberID.String(),
+			IntentID:          formatOptionalUUID(outcome.IntentID),
+			Outcome:           outcome.Outcome,
+			Obstacles:         outcome.Obstacles,
+			SatisfiedCriteria: outcome.SatisfiedCriteria,
+			NextIntentID:      formatOptionalUUID(outcome.NextIntentID),
+			CreatedAt:         outcome.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return responses
+}
+
+func formatUUIDs(ids []uuid.UUID) []string {
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, id.String())
+	}
+	return values
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/intent/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+func expectSessionStateLock(mock sqlmock.Sqlmock, sessionID, chapterID uuid.UUID, startsAt time.Time, state string) {
+	mock.ExpectQuery(regexp.QuoteMeta("FROM sessions WHERE id = $1 FOR UPDATE")).
+		WithArgs(sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "chapter_id", "starts_at", "ends_at", "state", "kicked_off_at", "closed_at", "created_at"}).
+			AddRow(sessionID, chapterID, startsAt, startsAt.Add(4*time.Hour), state, nil, nil, startsAt))
+}
+
+func TestSessionsHandlerCloseoutCreatesDraftIntents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, memberID, nextSessionID, templateID := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	startsAt := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	expectSessionStateLock(mock, sessionID, chapterID, startsAt, "kicked_off")
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM sessions WHERE chapter_id = $1")).
+		WithArgs(chapterID, startsAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(nextSessionID))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT chapter_id FROM members WHERE id = $1")).
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"chapter_id"}).AddRow(chapterID))
+	mock.ExpectExec("INSERT INTO intents").
+		WithArgs(sqlmock.AnyArg(), "I intend to document the retry policy.", "Only the code explains it today.", "A runbook page.", "[]", "draft", memberID, nil, nextSessionID, sqlmock.AnyArg(), nil, "[]", nil, nil, "[]").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectIntentTransition(mock, sqlmock.AnyArg(), nil, database.IntentDraft)
+	expectWebhookEvent(mock, database.EventIntentCreated)
+	mock.ExpectExec("INSERT INTO session_outcomes").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE sessions SET state").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("FROM retro_templates").
+		WithArgs(chapterID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "chapter_id", "name", "anonymous", "questions", "created_at", "updated_at"}).
+			AddRow(templateID, chapterID, "Pulse", false, []byte(`[{"id":"energy","prompt":"Energy?","kind":"scale","min":1,"max":5,"required":true}]`), startsAt, startsAt))
+	mock.ExpectExec("INSERT INTO retro_surveys").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	body := []byte(`{"outcomes":[{"memberId":"` + memberID.String() + `","outcome":"Retries shipped","obstacles":"Flaky staging",` +
+		`"nextIntent":{"statement":"I intend to document the retry policy.","context":"Only the code explains it today.","expectedOutcome":"A runbook page."}}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/"+sessionID.String()+"/closeout", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	SessionsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response closeoutResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+
+	if response.Session.State != "closed" || response.Session.ClosedAt == nil {
+		t.Fatalf("expected closed session got %+v", response.Session)
+	}
+
+	if len(response.DraftIntents) != 1 || response.DraftIntents[0].Status != "draft" {
+		t.Fatalf("expected one draft intent got %+v", response.DraftIntents)
+	}
+
+	if response.NextSessionID == nil || *response.NextSessionID != nextSessionID.String() {
+		t.Fatalf("expected next session %s got %v", nextSessionID, response.NextSessionID)
+	}
+
+	if response.RetroSurvey == nil || response.RetroSurvey.TemplateID == nil || *response.RetroSurvey.TemplateID != templateID.String() {
+		t.Fatalf("expected retro survey from template %s got %+v", templateID, response.RetroSurvey)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSessionsHandlerKickoffClosedSession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID := uuid.New()
+
+	mock.ExpectBegin()
+	expectSessionStateLock(mock, sessionID, uuid.New(), time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC), "closed")
+	mock.ExpectRollback()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/"+sessionID.String()+"/kickoff", bytes.NewReader([]byte(`{"swarms":[]}`)))
+	rr := httptest.NewRecorder()
+
+	SessionsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status %d got %d", http.StatusConflict, rr.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSessionsHandlerCloseoutRequiresOutcome(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	body := []byte(`{"outcomes":[{"memberId":"` + uuid.NewString() + `"}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/"+uuid.NewString()+"/closeout", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	SessionsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d got %d", http.StatusBadRequest, rr.Code)
+	}
+}
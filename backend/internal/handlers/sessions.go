@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/retro"
+	"github.com/example/intent/backend/internal/schedule"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type createSessionRequest struct {
+	ChapterID string `json:"chapterId"`
+	Date      string `json:"date"`
+}
+
+type sessionResponse struct {
+	ID          string  `json:"id"`
+	ChapterID   string  `json:"chapterId"`
+	StartsAt    string  `json:"startsAt"`
+	EndsAt      string  `json:"endsAt"`
+	State       string  `json:"state"`
+	KickedOffAt *string `json:"kickedOffAt"`
+	ClosedAt    *string `json:"closedAt"`
+	CreatedAt   string  `json:"createdAt"`
+}
+
+type listSessionResponse struct {
+	Items []sessionResponse `json:"items"`
+}
+
+type sessionsHandler struct {
+	logger *slog.Logger
+	db     *sql.DB
+}
+
+// SessionsHandler routes operations for sessions and the availability,
+// commitments and swarms scheduled inside them.
+func SessionsHandler(logger *slog.Logger, db *sql.DB) http.Handler {
+	return &sessionsHandler{logger: logger, db: db}
+}
+
+func (h *sessionsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/sessions":
+		h.handleCreate(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/api/sessions":
+		h.handleList(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/sessions/"):
+		segments := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/sessions/"), "/")
+		if segments[0] == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		sessionID, err := uuid.Parse(segments[0])
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid session id")
+			return
+		}
+
+		h.routeSession(w, r, sessionID, segments[1:])
+	default:
+		h.methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (h *sessionsHandler) routeSession(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID, segments []string) {
+	if len(segments) == 0 {
+		if r.Method != http.MethodGet {
+			h.methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		h.handleRetrieve(w, r, sessionID)
+		return
+	}
+
+	switch segments[0] {
+	case "availability":
+		switch {
+		case len(segments) == 1 && r.Method == http.MethodGet:
+			h.handleListAvailability(w, r, sessionID)
+		case len(segments) == 1 && r.Method == http.MethodPut:
+			h.handlePutAvailability(w, r, sessionID)
+		case len(segments) == 1:
+			h.methodNotAllowed(w, http.MethodGet, http.MethodPut)
+		case len(segments) == 2 && r.Method == http.MethodDelete:
+			h.handleDeleteAvailability(w, r, sessionID, segments[1])
+		case len(segments) == 2:
+			h.methodNotAllowed(w, http.MethodDelete)
+		default:
+			http.NotFound(w, r)
+		}
+	case "commitments":
+		switch {
+		case len(segments) == 1 && r.Method == http.MethodGet:
+			h.handleListCommitments(w, r, sessionID)
+		case len(segments) == 1 && r.Method == http.MethodPost:
+			h.handleCreateCommitment(w, r, sessionID)
+		case len(segments) == 1:
+			h.methodNotAllowed(w, http.MethodGet, http.MethodPost)
+		case len(segments) == 2 && r.Method == http.MethodDelete:
+			h.handleDeleteCommitment(w, r, sessionID, segments[1])
+		case len(segments) == 2:
+			h.methodNotAllowed(w, http.MethodDelete)
+		default:
+			http.NotFound(w, r)
+		}
+	case "swarms":
+		h.routeSwarms(w, r, sessionID, segments[1:])
+	case "kickoff", "closeout":
+		if len(segments) != 1 {
+			http.NotFound(w, r)
+			return
+		}
+		h.routeRitual(w, r, sessionID, segments[0])
+	case "outcomes":
+		switch {
+		case len(segments) != 1:
+			http.NotFound(w, r)
+		case r.Method == http.MethodPost:
+			h.handleLateOutcome(w, r, sessionID)
+		case r.Method == http.MethodGet:
+			h.handleListOutcomes(w, r, sessionID)
+		default:
+			h.methodNotAllowed(w, http.MethodGet, http.MethodPost)
+		}
+	case "retro":
+		h.routeRetro(w, r, sessionID, segments[1:])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *sessionsHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var payload createSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid session payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	chapterID, err := uuid.Parse(strings.TrimSpace(payload.ChapterID))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "chapterId must be a valid chapter id")
+		return
+	}
+
+	date, err := schedule.ParseDate(strings.TrimSpace(payload.Date))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "date must be formatted as YYYY-MM-DD")
+		return
+	}
+
+	chapter, err := database.GetChapter(ctx, h.db, chapterID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "chapter not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to retrieve chapter", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	loc, err := schedule.LoadLocation(chapter.Timezone)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "chapter has invalid timezone", "error", err, "chapter_id", chapter.ID)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	startsAt, endsAt, err := schedule.WindowOn(date, loc)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	record, err := database.CreateSession(ctx, h.db, database.SessionInput{
+		ChapterID: chapterID,
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			writeJSONError(w, http.StatusConflict, "a session already exists for this chapter on that date")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to persist session", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(toSessionResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *sessionsHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var filters database.SessionFilters
+
+	if value := strings.TrimSpace(r.URL.Query().Get("chapter")); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "chapter must be a valid chapter id")
+			return
+		}
+		filters.ChapterID = &parsed
+	}
+
+	if value := strings.TrimSpace(r.URL.Query().Get("from")); value != "" {
+		ts, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "from must be RFC3339 timestamp")
+			return
+		}
+		filters.StartsAfter = &ts
+	}
+
+	if value := strings.TrimSpace(r.URL.Query().Get("to")); value != "" {
+		ts, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "to must be RFC3339 timestamp")
+			return
+		}
+		filters.StartsBefore = &ts
+	}
+
+	sessions, err := database.ListSessions(ctx, h.db, filters)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list sessions", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	responses := make([]sessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		responses = append(responses, toSessionResponse(session))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listSessionResponse{Items: responses}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *sessionsHandler) handleRetrieve(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID) {
+	ctx := r.Context()
+
+	record, err := database.GetSession(ctx, h.db, sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "session not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to retrieve session", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toSessionResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+// writeSchedulingError maps errors from session-scoped writes onto HTTP
+// responses, including structured capacity violations.
+func (h *sessionsHandler) writeSchedulingError(ctx context.Context, w http.ResponseWriter, err error, message string) {
+	var capErr *database.CapacityError
+	switch {
+	case errors.As(err, &capErr):
+		h.logger.InfoContext(ctx, "capacity guardrail hit", "cap", capErr.Cap, "session_id", capErr.SessionID)
+		writeCapacityError(w, capErr)
+	case errors.Is(err, sql.ErrNoRows):
+		writeJSONError(w, http.StatusNotFound, "session not found")
+	case errors.Is(err, database.ErrMemberNotFound), errors.Is(err, database.ErrIntentNotFound), errors.Is(err, database.ErrSwarmNotFound),
+		errors.Is(err, database.ErrRetroTemplateNotFound), errors.Is(err, database.ErrRetroSurveyNotOpen), errors.Is(err, database.ErrAvailabilityNotFound):
+		writeJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, database.ErrMemberNotInChapter), errors.Is(err, database.ErrSwarmOutsideSession), errors.Is(err, database.ErrAcknowledgerNotInSwarm),
+		errors.Is(err, database.ErrCriterionNotOnGoal), errors.Is(err, retro.ErrInvalidAnswer), errors.Is(err, database.ErrTimeboxSessionNotFound),
+		errors.Is(err, database.ErrIntentNotInSession), errors.Is(err, database.ErrIntentNotOwnedByMember):
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, database.ErrSwarmDissolved), errors.Is(err, database.ErrSessionClosed),
+		errors.Is(err, database.ErrSessionAlreadyKickedOff), errors.Is(err, database.ErrSessionNotKickedOff),
+		errors.Is(err, database.ErrSessionNotClosed), errors.Is(err, database.ErrRetroSurveyAlreadyOpen), errors.Is(err, database.ErrAlreadyResponded),
+		errors.Is(err, database.ErrGoalNotActive):
+		writeJSONError(w, http.StatusConflict, err.Error())
+	case isUniqueViolation(err):
+		writeJSONError(w, http.StatusConflict, "member is already committed to that work in this session")
+	default:
+		h.logger.ErrorContext(ctx, message, "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+func (h *sessionsHandler) methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+func toSessionResponse(session database.Session) sessionResponse {
+	return sessionResponse{
+		ID:          session.ID.String(),
+		ChapterID:   session.ChapterID.String(),
+		StartsAt:    session.StartsAt.Format(time.RFC3339),
+		EndsAt:      session.EndsAt.Format(time.RFC3339),
+		State:       session.State,
+		KickedOffAt: formatOptionalTime(session.KickedOffAt),
+		ClosedAt:    formatOptionalTime(session.ClosedAt),
+		CreatedAt:   session.CreatedAt.Format(time.RFC3339),
+	}
+}
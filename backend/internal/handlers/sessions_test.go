@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func expectChapterLookup(mock sqlmock.Sqlmock, chapterID uuid.UUID, timezone string, maxSwarms int) {
+	now := time.Now().UTC()
+	rows := sqlmock.NewRows([]string{"id", "name", "timezone", "max_concurrent_swarms", "block_minutes", "created_at", "updated_at"}).
+		AddRow(chapterID, "Platform", timezone, maxSwarms, 60, now, now)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM chapters WHERE id = $1")).
+		WithArgs(chapterID).
+		WillReturnRows(rows)
+}
+
+func expectSessionCapacityLookup(mock sqlmock.Sqlmock, sessionID, chapterID uuid.UUID, startsAt time.Time, maxSwarms int) {
+	rows := sqlmock.NewRows([]string{"id", "chapter_id", "starts_at", "ends_at", "state", "created_at", "max_concurrent_swarms", "block_minutes"}).
+		AddRow(sessionID, chapterID, startsAt, startsAt.Add(4*time.Hour), "scheduled", startsAt.Add(-24*time.Hour), maxSwarms, 60)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM sessions s JOIN chapters c ON c.id = s.chapter_id WHERE s.id = $1")).
+		WithArgs(sessionID).
+		WillReturnRows(rows)
+}
+
+func TestSessionsHandlerCreateUsesChapterTimezone(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	chapterID := uuid.New()
+	expectChapterLookup(mock, chapterID, "Europe/Berlin", 3)
+
+	// 13:00-17:00 CEST on Monday 6 May 2024 is 11:00-15:00 UTC.
+	startsAt := time.Date(2024, 5, 6, 11, 0, 0, 0, time.UTC)
+	mock.ExpectExec("INSERT INTO sessions").
+		WithArgs(sqlmock.AnyArg(), chapterID, startsAt, startsAt.Add(4*time.Hour), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	body := []byte(`{"chapterId":"` + chapterID.String() + `","date":"2024-05-06"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	SessionsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d got %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+
+	var response sessionResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+
+	if response.StartsAt != "2024-05-06T11:00:00Z" || response.EndsAt != "2024-05-06T15:00:00Z" {
+		t.Fatalf("unexpected window %s - %s", response.StartsAt, response.EndsAt)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSessionsHandlerCreateRejectsOutsideWindow(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	chapterID := uuid.New()
+	expectChapterLookup(mock, chapterID, "UTC", 3)
+
+	body := []byte(`{"chapterId":"` + chapterID.String() + `","date":"2024-05-07"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	SessionsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSessionsHandlerCommitmentCapacityViolation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID, memberID, intentID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	expectSessionCapacityLookup(mock, sessionID, chapterID, time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC), 3)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT chapter_id FROM members WHERE id = $1 FOR UPDATE")).
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"chapter_id"}).AddRow(chapterID))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM intents WHERE id = $1)")).
+		WithArgs(intentID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status, starts_at, ends_at FROM availability")).
+		WithArgs(sessionID, memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "starts_at", "ends_at"}).AddRow("available", nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COALESCE(SUM(blocks), 0) FROM commitments")).
+		WithArgs(sessionID, memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(4))
+	mock.ExpectRollback()
+
+	body := []byte(`{"memberId":"` + memberID.String() + `","intentId":"` + intentID.String() + `","blocks":1}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/"+sessionID.String()+"/commitments", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	SessionsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status %d got %d", http.StatusConflict, rr.Code)
+	}
+
+	var response capacityViolationResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+
+	if response.Violation.Cap != "member_blocks" || response.Violation.Limit != 4 || response.Violation.Current != 4 || response.Violation.Requested != 1 {
+		t.Fatalf("unexpected violation %+v", response.Violation)
+	}
+
+	if response.Violation.MemberID != memberID.String() {
+		t.Fatalf("expected violation to name member %s", memberID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSessionsHandlerSwarmConcurrencyViolation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, chapterID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	expectSessionCapacityLookup(mock, sessionID, chapterID, time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC), 1)
+	mock.ExpectExec(regexp.QuoteMeta("SELECT id FROM chapters WHERE id = $1 FOR UPDATE")).
+		WithArgs(chapterID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM swarms")).
+		WithArgs(sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	body := []byte(`{"name":"Release swarm","blocks":2}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions/"+sessionID.String()+"/swarms", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	SessionsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("expected status %d got %d", http.StatusConflict, rr.Code)
+	}
+
+	var response capacityViolationResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+
+	if response.Violation.Cap != "chapter_concurrent_swarms" || response.Violation.Limit != 1 {
+		t.Fatalf("unexpected violation %+v", response.Violation)
+	}
+
+	if response.Violation.ChapterID != chapterID.String() {
+		t.Fatalf("expected chapter %s got %s", chapterID, response.Violation.ChapterID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSessionsHandlerPartialAvailabilityRequiresRange(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	body := []byte(`{"memberId":"` + uuid.NewString() + `","status":"partial"}`)
+	req := httptest.NewRequest(http.MethodPut, "/api/sessions/"+uuid.NewString()+"/availability", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	SessionsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,432 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/ical"
+	"github.com/google/uuid"
+)
+
+const (
+	minShowcaseMinutes = 5
+	maxShowcaseMinutes = 60
+)
+
+type createShowcaseRequest struct {
+	Title           string `json:"title"`
+	DurationMinutes int    `json:"durationMinutes"`
+	Priority        string `json:"priority"`
+	SwarmID         string `json:"swarmId"`
+	OutcomeID       string `json:"outcomeId"`
+}
+
+type lockShowcaseRequest struct {
+	SessionID string `json:"sessionId"`
+	StartsAt  string `json:"startsAt"`
+}
+
+type reorderShowcaseRequest struct {
+	RequestIDs []string `json:"requestIds"`
+}
+
+type showcaseResponse struct {
+	ID              string  `json:"id"`
+	ChapterID       string  `json:"chapterId"`
+	Title           string  `json:"title"`
+	SwarmID         *string `json:"swarmId"`
+	OutcomeID       *string `json:"outcomeId"`
+	DurationMinutes int     `json:"durationMinutes"`
+	Priority        string  `json:"priority"`
+	Position        int     `json:"position"`
+	Locked          bool    `json:"locked"`
+	Status          string  `json:"status"`
+	SessionID       *string `json:"sessionId"`
+	StartsAt        *string `json:"startsAt"`
+	EndsAt          *string `json:"endsAt"`
+	CreatedAt       string  `json:"createdAt"`
+	UpdatedAt       string  `json:"updatedAt"`
+}
+
+type listShowcaseResponse struct {
+	Items []showcaseResponse `json:"items"`
+}
+
+func (h *chaptersHandler) routeShowcase(w http.ResponseWriter, r *http.Request, id, rest string) {
+	chapterID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid chapter id")
+		return
+	}
+
+	requestID, action, _ := strings.Cut(rest, "/")
+	switch {
+	case requestID == "" && r.Method == http.MethodGet:
+		h.handleListShowcase(w, r, chapterID)
+	case requestID == "" && r.Method == http.MethodPost:
+		h.handleCreateShowcase(w, r, chapterID)
+	case requestID == "":
+		h.methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	case requestID == "order" && action == "":
+		if r.Method != http.MethodPut {
+			h.methodNotAllowed(w, http.MethodPut)
+			return
+		}
+		h.handleReorderShowcase(w, r, chapterID)
+	case requestID == "schedule" && action == "":
+		if r.Method != http.MethodPost {
+			h.methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		h.handleRescheduleShowcase(w, r, chapterID)
+	default:
+		parsed, err := uuid.Parse(requestID)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid showcase request id")
+			return
+		}
+
+		switch {
+		case action == "" && r.Method == http.MethodGet:
+			h.handleRetrieveShowcase(w, r, chapterID, parsed)
+		case action == "" && r.Method == http.MethodDelete:
+			h.handleDeleteShowcase(w, r, chapterID, parsed)
+		case action == "":
+			h.methodNotAllowed(w, http.MethodGet, http.MethodDelete)
+		case action == "lock" && r.Method == http.MethodPut:
+			h.handleLockShowcase(w, r, chapterID, parsed)
+		case action == "lock" && r.Method == http.MethodDelete:
+			h.handleUnlockShowcase(w, r, chapterID, parsed)
+		case action == "lock":
+			h.methodNotAllowed(w, http.MethodPut, http.MethodDelete)
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+func parseShowcasePayload(chapterID uuid.UUID, payload createShowcaseRequest) (database.ShowcaseRequestInput, error) {
+	input := database.ShowcaseRequestInput{
+		ChapterID:       chapterID,
+		Title:           strings.TrimSpace(payload.Title),
+		DurationMinutes: payload.DurationMinutes,
+		Priority:        strings.TrimSpace(payload.Priority),
+	}
+
+	if input.Title == "" {
+		return database.ShowcaseRequestInput{}, errors.New("title is required")
+	}
+
+	if input.DurationMinutes < minShowcaseMinutes || input.DurationMinutes > maxShowcaseMinutes {
+		return database.ShowcaseRequestInput{}, fmt.Errorf("durationMinutes must be between %d and %d", minShowcaseMinutes, maxShowcaseMinutes)
+	}
+
+	switch input.Priority {
+	case "":
+		input.Priority = database.ShowcaseNormal
+	case database.ShowcaseHigh, database.ShowcaseNormal, database.ShowcaseLow:
+	default:
+		return database.ShowcaseRequestInput{}, errors.New("priority must be high, normal, or low")
+	}
+
+	swarm := strings.TrimSpace(payload.SwarmID)
+	outcome := strings.TrimSpace(payload.OutcomeID)
+	if (swarm == "") == (outcome == "") {
+		return database.ShowcaseRequestInput{}, errors.New("exactly one of swarmId or outcomeId is required")
+	}
+
+	if swarm != "" {
+		swarmID, err := uuid.Parse(swarm)
+		if err != nil {
+			return database.ShowcaseRequestInput{}, errors.New("swarmId must be a valid swarm id")
+		}
+		input.SwarmID = &swarmID
+	} else {
+		outcomeID, err := uuid.Parse(outcome)
+		if err != nil {
+			return database.ShowcaseRequestInput{}, errors.New("outcomeId must be a valid outcome id")
+		}
+		input.OutcomeID = &outcomeID
+	}
+
+	return input, nil
+}
+
+func (h *chaptersHandler) handleCreateShowcase(w http.ResponseWriter, r *http.Request, chapterID uuid.UUID) {
+	ctx := r.Context()
+
+	var payload createShowcaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid showcase payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	input, err := parseShowcasePayload(chapterID, payload)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	record, err := database.CreateShowcaseRequest(ctx, h.db, input)
+	if err != nil {
+		h.writeShowcaseError(ctx, w, err, "chapter not found", "failed to queue showcase request")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(toShowcaseResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *chaptersHandler) handleListShowcase(w http.ResponseWriter, r *http.Request, chapterID uuid.UUID) {
+	ctx := r.Context()
+
+	requests, err := database.ListShowcaseRequests(ctx, h.db, chapterID, time.Now().UTC())
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list showcase", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	responses := make([]showcaseResponse, 0, len(requests))
+	for _, request := range requests {
+		responses = append(responses, toShowcaseResponse(request))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listShowcaseResponse{Items: responses}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *chaptersHandler) handleRetrieveShowcase(w http.ResponseWriter, r *http.Request, chapterID, requestID uuid.UUID) {
+	ctx := r.Context()
+
+	record, err := database.GetShowcaseRequest(ctx, h.db, chapterID, requestID)
+	if err != nil {
+		h.writeShowcaseError(ctx, w, err, "showcase request not found", "failed to retrieve showcase request")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toShowcaseResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *chaptersHandler) handleDeleteShowcase(w http.ResponseWriter, r *http.Request, chapterID, requestID uuid.UUID) {
+	ctx := r.Context()
+
+	if err := database.DeleteShowcaseRequest(ctx, h.db, chapterID, requestID); err != nil {
+		h.writeShowcaseError(ctx, w, err, "showcase request not found", "failed to delete showcase request")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *chaptersHandler) handleReorderShowcase(w http.ResponseWriter, r *http.Request, chapterID uuid.UUID) {
+	ctx := r.Context()
+
+	var payload reorderShowcaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid showcase order payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	ids, err := parseUUIDList(payload.RequestIDs)
+	if err != nil || len(ids) != len(payload.RequestIDs) {
+		writeJSONError(w, http.StatusBadRequest, "requestIds must list distinct showcase request ids")
+		return
+	}
+
+	if err := database.ReorderShowcase(ctx, h.db, chapterID, ids); err != nil {
+		h.writeShowcaseError(ctx, w, err, "chapter not found", "failed to reorder showcase")
+		return
+	}
+
+	h.handleListShowcase(w, r, chapterID)
+}
+
+func (h *chaptersHandler) handleRescheduleShowcase(w http.ResponseWriter, r *http.Request, chapterID uuid.UUID) {
+	ctx := r.Context()
+
+	if err := database.RescheduleShowcase(ctx, h.db, chapterID); err != nil {
+		h.writeShowcaseError(ctx, w, err, "chapter not found", "failed to reschedule showcase")
+		return
+	}
+
+	h.handleListShowcase(w, r, chapterID)
+}
+
+func (h *chaptersHandler) handleLockShowcase(w http.ResponseWriter, r *http.Request, chapterID, requestID uuid.UUID) {
+	ctx := r.Context()
+
+	var payload lockShowcaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.WarnContext(ctx, "invalid showcase lock payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var (
+		sessionID *uuid.UUID
+		startsAt  *time.Time
+	)
+	session := strings.TrimSpace(payload.SessionID)
+	start := strings.TrimSpace(payload.StartsAt)
+	if (session == "") != (start == "") {
+		writeJSONError(w, http.StatusBadRequest, "sessionId and startsAt must be given together")
+		return
+	}
+	if session != "" {
+		parsedSession, err := uuid.Parse(session)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "sessionId must be a valid session id")
+			return
+		}
+		parsedStart, err := time.Parse(time.RFC3339, start)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "startsAt must be an RFC3339 timestamp")
+			return
+		}
+		sessionID, startsAt = &parsedSession, &parsedStart
+	}
+
+	record, err := database.LockShowcaseSlot(ctx, h.db, chapterID, requestID, sessionID, startsAt)
+	if err != nil {
+		h.writeShowcaseError(ctx, w, err, "showcase request not found", "failed to lock showcase slot")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toShowcaseResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *chaptersHandler) handleUnlockShowcase(w http.ResponseWriter, r *http.Request, chapterID, requestID uuid.UUID) {
+	ctx := r.Context()
+
+	record, err := database.UnlockShowcaseSlot(ctx, h.db, chapterID, requestID)
+	if err != nil {
+		h.writeShowcaseError(ctx, w, err, "showcase request not found", "failed to unlock showcase slot")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toShowcaseResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *chaptersHandler) handleShowcaseFeed(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	chapterID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid chapter id")
+		return
+	}
+
+	valid, err := database.ChapterCalendarTokenValid(ctx, h.db, chapterID, strings.TrimSpace(r.URL.Query().Get("token")))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to verify calendar token", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if !valid {
+		writeJSONError(w, http.StatusUnauthorized, "a valid calendar token is required")
+		return
+	}
+
+	chapter, err := database.GetChapter(ctx, h.db, chapterID)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to retrieve chapter", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	requests, err := database.ListShowcaseRequests(ctx, h.db, chapterID, time.Now().Add(-calendarFeedLookback))
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list showcase", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	events := make([]ical.Event, 0, len(requests))
+	for _, request := range requests {
+		if request.StartsAt == nil || request.EndsAt == nil {
+			continue
+		}
+		events = append(events, ical.Event{
+			UID:         fmt.Sprintf("showcase-%s@%s", request.ID, calendarDomain),
+			Sequence:    request.Sequence,
+			Stamp:       request.UpdatedAt,
+			Start:       *request.StartsAt,
+			End:         *request.EndsAt,
+			Summary:     "Showcase: " + request.Title,
+			Description: fmt.Sprintf("%d minute demo, %s priority", request.DurationMinutes, request.Priority),
+			Status:      ical.StatusConfirmed,
+		})
+	}
+
+	writeCalendar(w, r, h.logger, ical.Calendar{
+		ProdID: calendarProdID,
+		Name:   chapter.Name + " showcase",
+		Events: events,
+	})
+}
+
+func (h *chaptersHandler) writeShowcaseError(ctx context.Context, w http.ResponseWriter, err error, notFound, message string) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeJSONError(w, http.StatusNotFound, notFound)
+	case errors.Is(err, database.ErrSwarmNotFound), errors.Is(err, database.ErrOutcomeNotFound):
+		writeJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, database.ErrShowcaseOutsideChapter), errors.Is(err, database.ErrShowcaseOrderMismatch):
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, database.ErrShowcaseNotScheduled), errors.Is(err, database.ErrShowcaseSlotUnavailable), errors.Is(err, database.ErrSessionClosed):
+		writeJSONError(w, http.StatusConflict, err.Error())
+	default:
+		h.logger.ErrorContext(ctx, message, "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+func toShowcaseResponse(request database.ShowcaseRequest) showcaseResponse {
+	status := "queued"
+	if request.SessionID != nil {
+		status = "scheduled"
+	}
+
+	return showcaseResponse{
+		ID:              request.ID.String(),
+		ChapterID:       request.ChapterID.String(),
+		Title:           request.Title,
+		SwarmID:         formatOptionalUUID(request.SwarmID),
+		OutcomeID:       formatOptionalUUID(request.OutcomeID),
+		DurationMinutes: request.DurationMinutes,
+		Priority:        request.Priority,
+		Position:        request.Position,
+		Locked:          request.Locked,
+		Status:          status,
+		SessionID:       formatOptionalUUID(request.SessionID),
+		StartsAt:        formatOptionalTime(request.StartsAt),
+		EndsAt:          formatOptionalTime(request.EndsAt),
+		CreatedAt:       request.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:       request.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+)
+
+func TestChaptersHandlerShowcaseRequiresOneRequester(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	body := []byte(`{"title":"Retry dashboard","durationMinutes":20,"swarmId":"` + uuid.NewString() + `","outcomeId":"` + uuid.NewString() + `"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/chapters/"+uuid.NewString()+"/showcase", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	ChaptersHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestChaptersHandlerShowcaseLockNeedsSessionAndStart(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	body := []byte(`{"sessionId":"` + uuid.NewString() + `"}`)
+	req := httptest.NewRequest(http.MethodPut, "/api/chapters/"+uuid.NewString()+"/showcase/"+uuid.NewString()+"/lock", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	ChaptersHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestChaptersHandlerShowcaseFeed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	chapterID, requestID, sessionID := uuid.New(), uuid.New(), uuid.New()
+	startsAt := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Hour)
+
+	mock.ExpectQuery(regexp.QuoteMeta("calendar_token_hash = $2")).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	expectChapterLookup(mock, chapterID, "UTC", 3)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM showcase_requests")).
+		WithArgs(chapterID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "chapter_id", "title", "swarm_id", "outcome_id", "duration_minutes", "priority", "position", "locked", "session_id", "starts_at", "ends_at", "sequence", "created_at", "updated_at"}).
+			AddRow(requestID, chapterID, "Retry dashboard", uuid.New(), nil, 20, "high", 0, true, sessionID, startsAt, startsAt.Add(20*time.Minute), 2, startsAt, startsAt).
+			AddRow(uuid.New(), chapterID, "Waiting demo", uuid.New(), nil, 30, "low", 1, false, nil, nil, nil, 0, startsAt, startsAt))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/chapters/"+chapterID.String()+"/showcase.ics?token=secret", nil)
+	rr := httptest.NewRecorder()
+
+	ChaptersHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	body := rr.Body.String()
+	if !strings.Contains(body, "UID:showcase-"+requestID.String()+"@intent") || !strings.Contains(body, "SEQUENCE:2") {
+		t.Fatalf("expected showcase event in feed got %s", body)
+	}
+
+	if strings.Contains(body, "Waiting demo") {
+		t.Fatalf("expected unscheduled requests to be left out of the feed")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
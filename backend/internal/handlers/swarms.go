@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+type createSwarmRequest struct {
+	Name      string   `json:"name"`
+	Mission   string   `json:"mission"`
+	StartsAt  string   `json:"startsAt"`
+	Blocks    int      `json:"blocks"`
+	MemberIDs []string `json:"memberIds"`
+}
+
+type addSwarmMemberRequest struct {
+	MemberID string `json:"memberId"`
+}
+
+type swarmResponse struct {
+	ID        string   `json:"id"`
+	SessionID string   `json:"sessionId"`
+	Name      string   `json:"name"`
+	Mission   string   `json:"mission"`
+	Status    string   `json:"status"`
+	StartsAt  string   `json:"startsAt"`
+	EndsAt    string   `json:"endsAt"`
+	MemberIDs []string `json:"memberIds"`
+	// ChatChannel is null until the swarm's channel is first provisioned.
+	ChatChannel *swarmChannelResponse `json:"chatChannel"`
+	CreatedAt   string                `json:"createdAt"`
+	UpdatedAt   string                `json:"updatedAt"`
+}
+
+type swarmChannelResponse struct {
+	Provider  string `json:"provider"`
+	ChannelID string `json:"channelId"`
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	Status    string `json:"status"`
+	LastError string `json:"lastError"`
+	Attempts  int    `json:"attempts"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+type listSwarmResponse struct {
+	Items []swarmResponse `json:"items"`
+}
+
+func (h *sessionsHandler) routeSwarms(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID, segments []string) {
+	if len(segments) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			h.handleListSwarms(w, r, sessionID)
+		case http.MethodPost:
+			h.handleCreateSwarm(w, r, sessionID)
+		default:
+			h.methodNotAllowed(w, http.MethodGet, http.MethodPost)
+		}
+		return
+	}
+
+	swarmID, err := uuid.Parse(segments[0])
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid swarm id")
+		return
+	}
+
+	switch {
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		h.handleRetrieveSwarm(w, r, sessionID, swarmID)
+	case len(segments) == 1 && r.Method == http.MethodDelete:
+		h.handleDissolveSwarm(w, r, sessionID, swarmID)
+	case len(segments) == 1:
+		h.methodNotAllowed(w, http.MethodGet, http.MethodDelete)
+	case len(segments) == 2 && segments[1] == "members" && r.Method == http.MethodPost:
+		h.handleAddSwarmMember(w, r, sessionID, swarmID)
+	case len(segments) == 2 && segments[1] == "members":
+		h.methodNotAllowed(w, http.MethodPost)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *sessionsHandler) handleCreateSwarm(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID) {
+	ctx := r.Context()
+
+	var payload createSwarmRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid swarm payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if strings.TrimSpace(payload.Name) == "" {
+		writeJSONError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	input := database.SwarmInput{
+		SessionID: sessionID,
+		Name:      strings.TrimSpace(payload.Name),
+		Mission:   strings.TrimSpace(payload.Mission),
+		Blocks:    payload.Blocks,
+	}
+
+	if input.Blocks == 0 {
+		input.Blocks = 1
+	}
+	if input.Blocks < 1 {
+		writeJSONError(w, http.StatusBadRequest, "blocks must be at least 1")
+		return
+	}
+
+	if value := strings.TrimSpace(payload.StartsAt); value != "" {
+		ts, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "startsAt must be RFC3339 timestamp")
+			return
+		}
+		input.StartsAt = &ts
+	}
+
+	seen := make(map[uuid.UUID]struct{})
+	for _, raw := range payload.MemberIDs {
+		memberID, err := uuid.Parse(strings.TrimSpace(raw))
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "memberIds must contain valid member ids")
+			return
+		}
+		if _, ok := seen[memberID]; ok {
+			continue
+		}
+		seen[memberID] = struct{}{}
+		input.MemberIDs = append(input.MemberIDs, memberID)
+	}
+
+	record, err := database.CreateSwarm(ctx, h.db, input)
+	if err != nil {
+		h.writeSchedulingError(ctx, w, err, "failed to form swarm")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(toSwarmResponse(record)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *sessionsHandler) handleListSwarms(w http.ResponseWriter, r *http.Request, sessionID uuid.UUID) {
+	ctx := r.Context()
+
+	records, err := database.ListSwarms(ctx, h.db, sessionID)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list swarms", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	responses, err := h.swarmResponses(ctx, records...)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to load swarm channels", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listSwarmResponse{Items: responses}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *sessionsHandler) handleRetrieveSwarm(w http.ResponseWriter, r *http.Request, sessionID, swarmID uuid.UUID) {
+	ctx := r.Context()
+
+	record, err := database.GetSwarm(ctx, h.db, sessionID, swarmID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "swarm not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to retrieve swarm", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	responses, err := h.swarmResponses(ctx, record)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to load swarm channel", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(responses[0]); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *sessionsHandler) handleDissolveSwarm(w http.ResponseWriter, r *http.Request, sessionID, swarmID uuid.UUID) {
+	ctx := r.Context()
+
+	record, err := database.DissolveSwarm(ctx, h.db, sessionID, swarmID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "swarm not found")
+			return
+		}
+		if errors.Is(err, database.ErrSessionClosed) {
+			writeJSONError(w, http.StatusConflict, err.Error())
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to dissolve swarm", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	responses, err := h.swarmResponses(ctx, record)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to load swarm channel", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(responses[0]); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *sessionsHandler) handleAddSwarmMember(w http.ResponseWriter, r *http.Request, sessionID, swarmID uuid.UUID) {
+	ctx := r.Context()
+
+	var payload addSwarmMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(ctx, "invalid swarm member payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	memberID, err := uuid.Parse(strings.TrimSpace(payload.MemberID))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "memberId must be a valid member id")
+		return
+	}
+
+	record, err := database.AddSwarmMember(ctx, h.db, sessionID, swarmID, memberID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "swarm not found")
+			return
+		}
+		h.writeSchedulingError(ctx, w, err, "failed to add swarm member")
+		return
+	}
+
+	responses, err := h.swarmResponses(ctx, record)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to load swarm channel", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(responses[0]); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+// swarmResponses converts swarms to responses carrying their chat channels.
+func (h *sessionsHandler) swarmResponses(ctx context.Context, swarms ...database.Swarm) ([]swarmResponse, error) {
+	swarmIDs := make([]uuid.UUID, 0, len(swarms))
+	for _, swarm := range swarms {
+		swarmIDs = append(swarmIDs, swarm.ID)
+	}
+
+	channels, err := database.ListSwarmChannels(ctx, h.db, swarmIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]swarmResponse, 0, len(swarms))
+	for _, swarm := range swarms {
+		response := toSwarmResponse(swarm)
+		if channel, ok := channels[swarm.ID]; ok {
+			response.ChatChannel = &swarmChannelResponse{
+				Provider:  channel.Provider,
+				ChannelID: channel.ChannelID,
+				Name:      channel.Name,
+				URL:       channel.URL,
+				Status:    channel.Status,
+				LastError: channel.LastError,
+				Attempts:  channel.Attempts,
+				UpdatedAt: channel.UpdatedAt.Format(time.RFC3339),
+			}
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
+
+func toSwarmResponse(swarm database.Swarm) swarmResponse {
+	memberIDs := make([]string, 0, len(swarm.MemberIDs))
+	for _, memberID := range swarm.MemberIDs {
+		memberIDs = append(memberIDs, memberID.String())
+	}
+
+	return swarmResponse{
+		ID:        swarm.ID.String(),
+		SessionID: swarm.SessionID.String(),
+		Name:      swarm.Name,
+		Mission:   swarm.Mission,
+		Status:    swarm.Status,
+		StartsAt:  swarm.StartsAt.Format(time.RFC3339),
+		EndsAt:    swarm.EndsAt.Format(time.RFC3339),
+		MemberIDs: memberIDs,
+		CreatedAt: swarm.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: swarm.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/intent/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestSessionsHandlerRetrieveSwarmSurfacesChannelFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessionID, swarmID := uuid.New(), uuid.New()
+	now := time.Now().UTC()
+
+	mock.ExpectQuery("FROM swarms").
+		WithArgs(swarmID, sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "session_id", "name", "mission", "status", "starts_at", "ends_at", "created_at", "updated_at"}).
+			AddRow(swarmID, sessionID, "Checkout fixes", "Unblock release", database.SwarmActive, now, now.Add(time.Hour), now, now))
+	mock.ExpectQuery("FROM swarm_members").
+		WillReturnRows(sqlmock.NewRows([]string{"swarm_id", "member_id"}))
+	mock.ExpectQuery("FROM swarm_channels WHERE swarm_id = ANY").
+		WillReturnRows(sqlmock.NewRows([]string{"swarm_id", "provider", "channel_id", "name", "url", "status", "pinned", "last_error", "attempts", "created_at", "updated_at"}).
+			AddRow(swarmID, "slack", nil, "swarm-checkout-fixes", "", database.ChannelFailed, false, "create channel: slack conversations.create failed: restricted_action", 5, now, now))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sessions/"+sessionID.String()+"/swarms/"+swarmID.String(), nil)
+	rr := httptest.NewRecorder()
+
+	SessionsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+
+	var response swarmResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+
+	if response.ChatChannel == nil || response.ChatChannel.Status != database.ChannelFailed || response.ChatChannel.Attempts != 5 || response.ChatChannel.LastError == "" {
+		t.Fatalf("unexpected chat channel %+v", response.ChatChannel)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/example/intent/backend/internal/database"
+)
+
+type facetCountResponse struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type intentFacetsResponse struct {
+	Tags     []facetCountResponse `json:"tags"`
+	Statuses []facetCountResponse `json:"statuses"`
+	Goals    []facetCountResponse `json:"goals"`
+}
+
+type goalFacetsResponse struct {
+	Tags     []facetCountResponse `json:"tags"`
+	Statuses []facetCountResponse `json:"statuses"`
+}
+
+// normalizeTags lowercases and trims tags, dropping blanks and duplicates.
+func normalizeTags(tags []string) []string {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// parseTagFilter reads the tag filter of a list request. Tags are given as
+// repeated or comma-separated tag parameters, and tagMatch chooses between
+// any (the default) and all of them.
+func parseTagFilter(query url.Values) ([]string, database.TagMatch, error) {
+	var tags []string
+	for _, value := range query["tag"] {
+		tags = append(tags, strings.Split(value, ",")...)
+	}
+
+	match := database.TagMatch(strings.ToLower(strings.TrimSpace(query.Get("tagMatch"))))
+	switch match {
+	case "":
+		match = database.TagMatchAny
+	case database.TagMatchAny, database.TagMatchAll:
+	default:
+		return nil, "", errors.New("tagMatch must be any or all")
+	}
+
+	return normalizeTags(tags), match, nil
+}
+
+func toFacetCountResponses(counts []database.FacetCount) []facetCountResponse {
+	responses := make([]facetCountResponse, 0, len(counts))
+	for _, count := range counts {
+		responses = append(responses, facetCountResponse{Value: count.Value, Count: count.Count})
+	}
+	return responses
+}
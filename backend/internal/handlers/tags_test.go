@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/intent/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestIntentsHandlerListFiltersByTagsWithFacets(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	goalID := uuid.New()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM intents WHERE tags ?& $1::text[]")).
+		WithArgs(`{"reliability","checkout"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM intents WHERE tags ?& $1::text[] ORDER BY created_at DESC")).
+		WithArgs(`{"reliability","checkout"}`, defaultPageSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta("UNION ALL SELECT 'tag', t.tag, COUNT(*) FROM intents")).
+		WithArgs(`{"reliability","checkout"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"facet", "value", "count"}).
+			AddRow(database.FacetGoal, goalID.String(), 3).
+			AddRow(database.FacetGoal, "", 1).
+			AddRow(database.FacetStatus, "active", 4).
+			AddRow(database.FacetTag, "checkout", 4).
+			AddRow(database.FacetTag, "reliability", 4))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/intents?tag=Reliability&tag=checkout,+reliability&tagMatch=ALL", nil)
+	rr := httptest.NewRecorder()
+
+	IntentsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200 got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var payload listIntentResponse
+	if err := json.NewDecoder(rr.Body).Decode(&payload); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	facets := payload.Facets
+	if len(facets.Tags) != 2 || len(facets.Statuses) != 1 || len(facets.Goals) != 2 {
+		t.Fatalf("unexpected facets %+v", facets)
+	}
+	if facets.Goals[0] != (facetCountResponse{Value: goalID.String(), Count: 3}) {
+		t.Fatalf("unexpected goal facet %+v", facets.Goals[0])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
+
+func TestGoalsHandlerListRejectsUnknownTagMatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	req := httptest.NewRequest(http.MethodGet, "/api/goals?tag=onboarding&tagMatch=some", nil)
+	rr := httptest.NewRecorder()
+
+	GoalsHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 got %d", rr.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("sql expectations: %v", err)
+	}
+}
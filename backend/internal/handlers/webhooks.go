@@ -0,0 +1,389 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/webhooks"
+	"github.com/google/uuid"
+)
+
+type webhookRequest struct {
+	URL         string   `json:"url"`
+	Secret      string   `json:"secret"`
+	EventTypes  []string `json:"eventTypes"`
+	Description string   `json:"description"`
+	Active      *bool    `json:"active"`
+}
+
+type webhookResponse struct {
+	ID          string   `json:"id"`
+	URL         string   `json:"url"`
+	Secret      string   `json:"secret,omitempty"`
+	EventTypes  []string `json:"eventTypes"`
+	Description string   `json:"description"`
+	Active      bool     `json:"active"`
+	CreatedAt   string   `json:"createdAt"`
+	UpdatedAt   string   `json:"updatedAt"`
+}
+
+type listWebhookResponse struct {
+	Items []webhookResponse `json:"items"`
+}
+
+type webhookDeliveryResponse struct {
+	ID         string `json:"id"`
+	EventID    string `json:"eventId"`
+	EventType  string `json:"eventType"`
+	Attempt    int    `json:"attempt"`
+	StatusCode *int   `json:"statusCode"`
+	Error      string `json:"error"`
+	DurationMS int64  `json:"durationMs"`
+	Succeeded  bool   `json:"succeeded"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type listWebhookDeliveryResponse struct {
+	Items []webhookDeliveryResponse `json:"items"`
+}
+
+type webhooksHandler struct {
+	logger *slog.Logger
+	db     *sql.DB
+}
+
+// WebhooksHandler manages outbound webhook subscriptions and their delivery
+// logs.
+func WebhooksHandler(logger *slog.Logger, db *sql.DB) http.Handler {
+	return &webhooksHandler{logger: logger, db: db}
+}
+
+func (h *webhooksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/webhooks"), "/")
+	if path == "" {
+		switch r.Method {
+		case http.MethodGet:
+			h.handleList(w, r)
+		case http.MethodPost:
+			h.handleCreate(w, r)
+		default:
+			h.methodNotAllowed(w, http.MethodGet, http.MethodPost)
+		}
+		return
+	}
+
+	segments := strings.Split(path, "/")
+	id := segments[0]
+
+	switch {
+	case len(segments) == 1:
+		switch r.Method {
+		case http.MethodGet:
+			h.handleRetrieve(w, r, id)
+		case http.MethodPut:
+			h.handleUpdate(w, r, id)
+		case http.MethodDelete:
+			h.handleDelete(w, r, id)
+		default:
+			h.methodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodDelete)
+		}
+	case len(segments) == 2 && segments[1] == "deliveries":
+		if r.Method != http.MethodGet {
+			h.methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		h.handleListDeliveries(w, r, id)
+	case len(segments) == 4 && segments[1] == "deliveries" && segments[3] == "redeliver":
+		if r.Method != http.MethodPost {
+			h.methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		h.handleRedeliver(w, r, id, segments[2])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *webhooksHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	subscriptions, err := database.ListWebhookSubscriptions(ctx, h.db)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list webhooks", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	responses := make([]webhookResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		responses = append(responses, toWebhookResponse(subscription))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listWebhookResponse{Items: responses}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *webhooksHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	input, ok := h.decodeWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	if input.Secret == "" {
+		secret, err := webhooks.NewSecret()
+		if err != nil {
+			h.logger.ErrorContext(ctx, "failed to generate webhook secret", "error", err)
+			writeJSONError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		input.Secret = secret
+	}
+
+	subscription, err := database.CreateWebhookSubscription(ctx, h.db, input)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to create webhook", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	// The secret is only ever shown when the subscription is created.
+	response := toWebhookResponse(subscription)
+	response.Secret = subscription.Secret
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *webhooksHandler) handleRetrieve(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	subscriptionID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	subscription, err := database.GetWebhookSubscription(ctx, h.db, subscriptionID)
+	if err != nil {
+		h.writeLookupError(ctx, w, err, "failed to retrieve webhook")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toWebhookResponse(subscription)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *webhooksHandler) handleUpdate(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	subscriptionID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	input, ok := h.decodeWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	subscription, err := database.UpdateWebhookSubscription(ctx, h.db, subscriptionID, input)
+	if err != nil {
+		h.writeLookupError(ctx, w, err, "failed to update webhook")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(toWebhookResponse(subscription)); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *webhooksHandler) handleDelete(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	subscriptionID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	if err := database.DeleteWebhookSubscription(ctx, h.db, subscriptionID); err != nil {
+		h.writeLookupError(ctx, w, err, "failed to delete webhook")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *webhooksHandler) handleListDeliveries(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	subscriptionID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	limit := parsePositiveInt(r.URL.Query().Get("limit"), 50)
+	if limit < 1 || limit > 200 {
+		writeJSONError(w, http.StatusBadRequest, "limit must be between 1 and 200")
+		return
+	}
+
+	if _, err := database.GetWebhookSubscription(ctx, h.db, subscriptionID); err != nil {
+		h.writeLookupError(ctx, w, err, "failed to retrieve webhook")
+		return
+	}
+
+	deliveries, err := database.ListWebhookDeliveries(ctx, h.db, subscriptionID, limit)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to list webhook deliveries", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	responses := make([]webhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		responses = append(responses, toWebhookDeliveryResponse(delivery))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(listWebhookDeliveryResponse{Items: responses}); err != nil {
+		h.logger.ErrorContext(ctx, "failed to encode response", "error", err)
+	}
+}
+
+func (h *webhooksHandler) handleRedeliver(w http.ResponseWriter, r *http.Request, id, deliveryID string) {
+	ctx := r.Context()
+
+	subscriptionID, err := uuid.Parse(id)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	parsedDeliveryID, err := uuid.Parse(deliveryID)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid delivery id")
+		return
+	}
+
+	if _, err := database.RedeliverWebhook(ctx, h.db, subscriptionID, parsedDeliveryID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSONError(w, http.StatusNotFound, "delivery not found")
+			return
+		}
+		h.logger.ErrorContext(ctx, "failed to queue webhook redelivery", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// decodeWebhook reads and validates a subscription payload, writing a 400
+// response when it is unusable.
+func (h *webhooksHandler) decodeWebhook(w http.ResponseWriter, r *http.Request) (database.WebhookSubscriptionInput, bool) {
+	var payload webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		h.logger.WarnContext(r.Context(), "invalid webhook payload", "error", err)
+		writeJSONError(w, http.StatusBadRequest, "invalid request body")
+		return database.WebhookSubscriptionInput{}, false
+	}
+
+	input, err := parseWebhook(payload)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return database.WebhookSubscriptionInput{}, false
+	}
+
+	return input, true
+}
+
+func parseWebhook(payload webhookRequest) (database.WebhookSubscriptionInput, error) {
+	parsed, err := url.Parse(strings.TrimSpace(payload.URL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return database.WebhookSubscriptionInput{}, errors.New("url must be an absolute http or https URL")
+	}
+
+	input := database.WebhookSubscriptionInput{
+		URL:         parsed.String(),
+		Secret:      strings.TrimSpace(payload.Secret),
+		EventTypes:  make([]string, 0, len(payload.EventTypes)),
+		Description: strings.TrimSpace(payload.Description),
+		Active:      true,
+	}
+	if payload.Active != nil {
+		input.Active = *payload.Active
+	}
+
+	for _, eventType := range payload.EventTypes {
+		if !slices.Contains(database.WebhookEventTypes, eventType) {
+			return database.WebhookSubscriptionInput{}, errors.New("eventTypes has unknown event type " + eventType)
+		}
+		if !slices.Contains(input.EventTypes, eventType) {
+			input.EventTypes = append(input.EventTypes, eventType)
+		}
+	}
+
+	return input, nil
+}
+
+func (h *webhooksHandler) writeLookupError(ctx context.Context, w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, sql.ErrNoRows) {
+		writeJSONError(w, http.StatusNotFound, "webhook not found")
+		return
+	}
+	h.logger.ErrorContext(ctx, message, "error", err)
+	writeJSONError(w, http.StatusInternalServerError, "internal server error")
+}
+
+func (h *webhooksHandler) methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func toWebhookResponse(subscription database.WebhookSubscription) webhookResponse {
+	return webhookResponse{
+		ID:          subscription.ID.String(),
+		URL:         subscription.URL,
+		EventTypes:  subscription.EventTypes,
+		Description: subscription.Description,
+		Active:      subscription.Active,
+		CreatedAt:   subscription.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   subscription.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func toWebhookDeliveryResponse(delivery database.WebhookDelivery) webhookDeliveryResponse {
+	return webhookDeliveryResponse{
+		ID:         delivery.ID.String(),
+		EventID:    delivery.EventID.String(),
+		EventType:  delivery.EventType,
+		Attempt:    delivery.Attempt,
+		StatusCode: delivery.StatusCode,
+		Error:      delivery.Error,
+		DurationMS: delivery.Duration.Milliseconds(),
+		Succeeded:  delivery.Succeeded(),
+		CreatedAt:  delivery.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/intent/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// expectWebhookEvent expects an event of the given type to be written to the
+// outbox inside the surrounding transaction.
+func expectWebhookEvent(mock sqlmock.Sqlmock, eventType string) {
+	mock.ExpectExec("INSERT INTO webhook_events").
+		WithArgs(sqlmock.AnyArg(), eventType, sqlmock.AnyArg(), sqlmock.AnyArg(), database.WebhookDeliveryJob, database.WebhookDeliveryAttempts).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestWebhooksHandlerCreateGeneratesSecret(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectExec("INSERT INTO webhook_subscriptions").
+		WithArgs(sqlmock.AnyArg(), "https://hooks.example.com/intent", sqlmock.AnyArg(), `["intent.created","goal.deleted"]`, "Planning board", true, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	body := []byte(`{"url":"https://hooks.example.com/intent","eventTypes":["intent.created","goal.deleted","intent.created"],"description":"Planning board"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/webhooks", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	WebhooksHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d got %d: %s", http.StatusCreated, rr.Code, rr.Body.String())
+	}
+
+	var response webhookResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("response is not valid JSON: %v", err)
+	}
+
+	if len(response.Secret) != 64 || !response.Active || len(response.EventTypes) != 2 {
+		t.Fatalf("unexpected response %+v", response)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestWebhooksHandlerRejectsUnknownEventType(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	body := []byte(`{"url":"https://hooks.example.com/intent","eventTypes":["intent.exploded"]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/webhooks", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	WebhooksHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestWebhooksHandlerRetrieveHidesSecret(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	id := uuid.New()
+	now := time.Now().UTC()
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM webhook_subscriptions WHERE id = $1")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url", "secret", "event_types", "description", "active", "created_at", "updated_at"}).
+			AddRow(id, "https://hooks.example.com/intent", "s3cret", []byte(`[]`), "", true, now, now))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/webhooks/"+id.String(), nil)
+	rr := httptest.NewRecorder()
+
+	WebhooksHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d got %d", http.StatusOK, rr.Code)
+	}
+
+	if bytes.Contains(rr.Body.Bytes(), []byte("s3cret")) {
+		t.Fatalf("secret leaked in %s", rr.Body.String())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestWebhooksHandlerRedeliver(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	subscriptionID, deliveryID, eventID := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectQuery("FROM webhook_deliveries d").
+		WithArgs(deliveryID, subscriptionID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "subscription_id", "event_id", "type", "attempt", "status_code", "error", "duration_ms", "created_at"}).
+			AddRow(deliveryID, subscriptionID, eventID, database.EventIntentUpdated, 8, nil, "timeout", 10000, time.Now().UTC()))
+	mock.ExpectExec("INSERT INTO jobs").
+		WithArgs(sqlmock.AnyArg(), database.WebhookDeliveryJob, sqlmock.AnyArg(), sqlmock.AnyArg(), database.WebhookDeliveryAttempts, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/webhooks/"+subscriptionID.String()+"/deliveries/"+deliveryID.String()+"/redeliver", nil)
+	rr := httptest.NewRecorder()
+
+	WebhooksHandler(testLogger(t), db).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected status %d got %d: %s", http.StatusAccepted, rr.Code, rr.Body.String())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodeEvent(t *testing.T) {
+	start := time.Date(2024, 5, 6, 11, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	err := Encode(&buf, Calendar{
+		ProdID: "-//Test//EN",
+		Name:   "Platform",
+		Events: []Event{{
+			UID:         "swarm-1@intent",
+			Sequence:    2,
+			Stamp:       start,
+			Start:       start,
+			End:         start.Add(time.Hour),
+			Summary:     "Fix checkout, again; now",
+			Description: "line one\nline two",
+			Status:      StatusCancelled,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:swarm-1@intent\r\n",
+		"SEQUENCE:2\r\n",
+		"DTSTART:20240506T110000Z\r\n",
+		"DTEND:20240506T120000Z\r\n",
+		`SUMMARY:Fix checkout\, again\; now` + "\r\n",
+		`DESCRIPTION:line one\nline two` + "\r\n",
+		"STATUS:CANCELLED\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestEncodeFoldsLongLines(t *testing.T) {
+	var buf bytes.Buffer
+	err := Encode(&buf, Calendar{
+		ProdID: "-//Test//EN",
+		Events: []Event{{UID: "a", Summary: strings.Repeat("é", 100)}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Fatalf("line exceeds %d octets: %q", maxLineOctets, line)
+		}
+		if !utf8ValidLine(line) {
+			t.Fatalf("line splits a UTF-8 sequence: %q", line)
+		}
+	}
+
+	unfolded := strings.ReplaceAll(buf.String(), "\r\n ", "")
+	if !strings.Contains(unfolded, "SUMMARY:"+strings.Repeat("é", 100)+"\r\n") {
+		t.Fatal("expected folded summary to unfold to the original value")
+	}
+}
+
+func utf8ValidLine(line string) bool {
+	return strings.ToValidUTF8(line, "�") == line
+}
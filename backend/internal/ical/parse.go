@@ -0,0 +1,331 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VEvent is an event read from an imported calendar. Recurring events carry
+// their rule and exclusions; use Busy to expand them into concrete intervals.
+type VEvent struct {
+	UID          string
+	Summary      string
+	Start        time.Time
+	End          time.Time
+	AllDay       bool
+	Rule         *Rule
+	ExDates      []time.Time
+	RecurrenceID *time.Time
+	Status       string
+	Transparent  bool
+}
+
+// ErrInvalidCalendar is returned when input is not an iCalendar document.
+var ErrInvalidCalendar = errors.New("input is not a valid iCalendar document")
+
+// Parse reads the VEVENT components of an iCalendar document. Floating times
+// and TZIDs that cannot be resolved are interpreted in loc. Events recurring
+// more often than daily only contribute their DTSTART instance.
+func Parse(r io.Reader, loc *time.Location) ([]VEvent, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events   []VEvent
+		current  *VEvent
+		duration *time.Duration
+		sawStart bool
+		depth    int
+	)
+
+	for _, line := range lines {
+		name, params, value, ok := splitContentLine(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN":
+			depth++
+			if strings.EqualFold(value, "VCALENDAR") {
+				sawStart = true
+			}
+			if strings.EqualFold(value, "VEVENT") && current == nil {
+				current = &VEvent{}
+				duration = nil
+			}
+			continue
+		case name == "END":
+			depth--
+			if strings.EqualFold(value, "VEVENT") && current != nil {
+				if current.Start.IsZero() {
+					return nil, fmt.Errorf("%w: event %q has no DTSTART", ErrInvalidCalendar, current.UID)
+				}
+				if current.End.IsZero() {
+					switch {
+					case duration != nil:
+						current.End = current.Start.Add(*duration)
+					case current.AllDay:
+						current.End = current.Start.AddDate(0, 0, 1)
+					default:
+						current.End = current.Start
+					}
+				}
+				events = append(events, *current)
+				current = nil
+			}
+			continue
+		}
+
+		// Properties of nested components such as VALARM belong to them,
+		// not to the event.
+		if current == nil || depth != 2 {
+			continue
+		}
+
+		switch name {
+		case "UID":
+			current.UID = value
+		case "SUMMARY":
+			current.Summary = unescapeText(value)
+		case "DTSTART":
+			t, allDay, err := parseDateTime(value, params, loc)
+			if err != nil {
+				return nil, err
+			}
+			current.Start, current.AllDay = t, allDay
+		case "DTEND":
+			t, _, err := parseDateTime(value, params, loc)
+			if err != nil {
+				return nil, err
+			}
+			current.End = t
+		case "DURATION":
+			d, err := parseDuration(value)
+			if err != nil {
+				return nil, err
+			}
+			duration = &d
+		case "RRULE":
+			rule, err := parseRule(value, loc)
+			if errors.Is(err, errUnsupportedFreq) {
+				// Sub-daily series are kept as their first instance rather
+				// than rejecting the whole calendar.
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			current.Rule = &rule
+		case "EXDATE":
+			for _, part := range strings.Split(value, ",") {
+				t, _, err := parseDateTime(part, params, loc)
+				if err != nil {
+					return nil, err
+				}
+				current.ExDates = append(current.ExDates, t)
+			}
+		case "RECURRENCE-ID":
+			t, _, err := parseDateTime(value, params, loc)
+			if err != nil {
+				return nil, err
+			}
+			current.RecurrenceID = &t
+		case "STATUS":
+			current.Status = strings.ToUpper(value)
+		case "TRANSP":
+			current.Transparent = strings.EqualFold(value, "TRANSPARENT")
+		}
+	}
+
+	if !sawStart {
+		return nil, ErrInvalidCalendar
+	}
+
+	return events, nil
+}
+
+// unfold joins continuation lines, accepting both CRLF and bare LF endings.
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+// splitContentLine splits "NAME;PARAM=VALUE:value" into its parts, honouring
+// quoted parameter values that may contain ':' or ';'.
+func splitContentLine(line string) (string, map[string]string, string, bool) {
+	inQuotes := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", false
+	}
+
+	head, value := line[:colon], line[colon+1:]
+	parts := splitOutsideQuotes(head, ';')
+	name := strings.ToUpper(parts[0])
+
+	params := make(map[string]string, len(parts)-1)
+	for _, part := range parts[1:] {
+		key, val, found := strings.Cut(part, "=")
+		if !found {
+			continue
+		}
+		params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+	}
+
+	return name, params, value, true
+}
+
+func splitOutsideQuotes(s string, sep rune) []string {
+	var (
+		parts    []string
+		start    int
+		inQuotes bool
+	)
+	for i, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+		case r == sep && !inQuotes:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func parseDateTime(value string, params map[string]string, loc *time.Location) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+
+	if params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%w: invalid date %q", ErrInvalidCalendar, value)
+		}
+		return t, true, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%w: invalid date-time %q", ErrInvalidCalendar, value)
+		}
+		return t, false, nil
+	}
+
+	zone := loc
+	if tzid := params["TZID"]; tzid != "" {
+		if resolved, err := time.LoadLocation(tzid); err == nil {
+			zone = resolved
+		}
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, zone)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%w: invalid date-time %q", ErrInvalidCalendar, value)
+	}
+	return t, false, nil
+}
+
+// parseDuration parses an RFC 5545 DURATION such as "PT1H30M" or "-P1W".
+func parseDuration(value string) (time.Duration, error) {
+	invalid := fmt.Errorf("%w: invalid duration %q", ErrInvalidCalendar, value)
+
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(value, "-"):
+		sign = -1
+		value = value[1:]
+	case strings.HasPrefix(value, "+"):
+		value = value[1:]
+	}
+
+	if !strings.HasPrefix(value, "P") {
+		return 0, invalid
+	}
+	value = value[1:]
+
+	var (
+		total   time.Duration
+		number  string
+		inTime  bool
+		matched bool
+	)
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			number += string(r)
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		}
+
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			return 0, invalid
+		}
+		number = ""
+		matched = true
+
+		switch {
+		case r == 'W' && !inTime:
+			total += time.Duration(n) * 7 * 24 * time.Hour
+		case r == 'D' && !inTime:
+			total += time.Duration(n) * 24 * time.Hour
+		case r == 'H' && inTime:
+			total += time.Duration(n) * time.Hour
+		case r == 'M' && inTime:
+			total += time.Duration(n) * time.Minute
+		case r == 'S' && inTime:
+			total += time.Duration(n) * time.Second
+		default:
+			return 0, invalid
+		}
+	}
+
+	if number != "" || !matched {
+		return 0, invalid
+	}
+
+	return sign * total, nil
+}
+
+func unescapeText(value string) string {
+	replacer := strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+	return replacer.Replace(value)
+}
@@ -0,0 +1,225 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const weeklyStandup = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VTIMEZONE\r\n" +
+	"TZID:Europe/Berlin\r\n" +
+	"END:VTIMEZONE\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"SUMMARY:Team sync\\, weekly\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20240506T140000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20240506T150000\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=MO,TH;COUNT=6\r\n" +
+	"EXDATE;TZID=Europe/Berlin:20240509T140000\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"RECURRENCE-ID;TZID=Europe/Berlin:20240513T140000\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20240513T160000\r\n" +
+	"DURATION:PT30M\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:focus\r\n" +
+	"DTSTART:20240506T120000Z\r\n" +
+	"DTEND:20240506T130000Z\r\n" +
+	"TRANSP:TRANSPARENT\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseReadsEvents(t *testing.T) {
+	events, err := Parse(strings.NewReader(weeklyStandup), time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events got %d", len(events))
+	}
+
+	master := events[0]
+	if master.Summary != "Team sync, weekly" {
+		t.Fatalf("expected unescaped summary got %q", master.Summary)
+	}
+
+	if master.Rule == nil || master.Rule.Freq != FreqWeekly || master.Rule.Count != 6 || len(master.Rule.ByDay) != 2 {
+		t.Fatalf("unexpected rule %+v", master.Rule)
+	}
+
+	if got := master.Start.UTC(); !got.Equal(time.Date(2024, 5, 6, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected TZID to be honoured got %s", got)
+	}
+
+	if override := events[1]; override.RecurrenceID == nil || override.End.Sub(override.Start) != 30*time.Minute {
+		t.Fatalf("unexpected override %+v", override)
+	}
+
+	if !events[2].Transparent {
+		t.Fatal("expected TRANSP:TRANSPARENT to be parsed")
+	}
+}
+
+func TestParseRejectsNonCalendar(t *testing.T) {
+	if _, err := Parse(strings.NewReader("hello"), time.UTC); err == nil {
+		t.Fatal("expected error for non-calendar input")
+	}
+}
+
+func TestBusyExpandsRecurrences(t *testing.T) {
+	events, err := Parse(strings.NewReader(weeklyStandup), time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	busy := Busy(events, from, to)
+
+	// COUNT=6 yields 6, 9, 13, 16, 20 and 23 May; the 9th is excluded and
+	// the 13th is moved to 16:00 Berlin for 30 minutes.
+	want := []Interval{
+		{Start: time.Date(2024, 5, 6, 12, 0, 0, 0, time.UTC), End: time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)},
+		{Start: time.Date(2024, 5, 13, 14, 0, 0, 0, time.UTC), End: time.Date(2024, 5, 13, 14, 30, 0, 0, time.UTC)},
+		{Start: time.Date(2024, 5, 16, 12, 0, 0, 0, time.UTC), End: time.Date(2024, 5, 16, 13, 0, 0, 0, time.UTC)},
+		{Start: time.Date(2024, 5, 20, 12, 0, 0, 0, time.UTC), End: time.Date(2024, 5, 20, 13, 0, 0, 0, time.UTC)},
+		{Start: time.Date(2024, 5, 23, 12, 0, 0, 0, time.UTC), End: time.Date(2024, 5, 23, 13, 0, 0, 0, time.UTC)},
+	}
+
+	if len(busy) != len(want) {
+		t.Fatalf("expected %d intervals got %d: %v", len(want), len(busy), busy)
+	}
+
+	for i := range want {
+		if !busy[i].Start.Equal(want[i].Start) || !busy[i].End.Equal(want[i].End) {
+			t.Fatalf("interval %d: expected %v got %v", i, want[i], busy[i])
+		}
+	}
+}
+
+func TestBusyMonthlyLastWeekday(t *testing.T) {
+	rule, err := parseRule("FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20240801T000000Z", time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	events := []VEvent{{
+		UID:   "retro",
+		Start: time.Date(2024, 5, 31, 9, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 5, 31, 10, 0, 0, 0, time.UTC),
+		Rule:  &rule,
+	}}
+
+	busy := Busy(events, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC))
+
+	if len(busy) != 3 {
+		t.Fatalf("expected 3 occurrences before UNTIL got %v", busy)
+	}
+
+	if busy[1].Start.Day() != 28 || busy[1].Start.Month() != time.June {
+		t.Fatalf("expected last Friday of June got %s", busy[1].Start)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"PT1H30M": 90 * time.Minute,
+		"P1W":     7 * 24 * time.Hour,
+		"P1DT2H":  26 * time.Hour,
+		"-PT15M":  -15 * time.Minute,
+	}
+
+	for input, want := range cases {
+		got, err := parseDuration(input)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", input, err)
+		}
+		if got != want {
+			t.Fatalf("%s: expected %s got %s", input, want, got)
+		}
+	}
+
+	if _, err := parseDuration("PT"); err == nil {
+		t.Fatal("expected error for empty duration")
+	}
+}
+
+func TestParseKeepsSubDailySeriesAndIgnoresAlarms(t *testing.T) {
+	doc := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:ping\r\n" +
+		"DTSTART:20240506T120000Z\r\n" +
+		"DURATION:PT15M\r\n" +
+		"RRULE:FREQ=HOURLY;COUNT=4\r\n" +
+		"BEGIN:VALARM\r\n" +
+		"TRIGGER:-PT10M\r\n" +
+		"DURATION:PT5M\r\n" +
+		"END:VALARM\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	events, err := Parse(strings.NewReader(doc), time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(events) != 1 || events[0].Rule != nil {
+		t.Fatalf("expected a single non-recurring event got %+v", events)
+	}
+
+	if got := events[0].End.Sub(events[0].Start); got != 15*time.Minute {
+		t.Fatalf("expected the event's own duration got %s", got)
+	}
+}
+
+func TestBusyExpandsLongRunningDailySeries(t *testing.T) {
+	rule, err := parseRule("FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	events := []VEvent{{
+		UID:   "lunch",
+		Start: time.Date(2004, 1, 5, 12, 0, 0, 0, time.UTC),
+		End:   time.Date(2004, 1, 5, 13, 0, 0, 0, time.UTC),
+		Rule:  &rule,
+	}}
+
+	// Monday 6 May to Monday 13 May 2024, two decades after DTSTART.
+	busy := Busy(events, time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC))
+
+	if len(busy) != 5 {
+		t.Fatalf("expected five weekday lunches got %v", busy)
+	}
+
+	if busy[4].Start.Weekday() != time.Friday {
+		t.Fatalf("expected weekends to be skipped got %v", busy)
+	}
+}
+
+func TestBusyMonthlyIntersectsByDayAndByMonthDay(t *testing.T) {
+	// Friday the 13th.
+	rule, err := parseRule("FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13", time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	events := []VEvent{{
+		UID:   "unlucky",
+		Start: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		Rule:  &rule,
+	}}
+
+	busy := Busy(events, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if len(busy) != 2 || busy[0].Start.Month() != time.September || busy[1].Start.Month() != time.December {
+		t.Fatalf("expected September and December 2024 got %v", busy)
+	}
+}
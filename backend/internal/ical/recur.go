@@ -0,0 +1,404 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported recurrence frequencies.
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+)
+
+// Limits guarding expansion of unbounded or pathological rules.
+const (
+	maxOccurrences = 5000
+	maxPeriods     = 50000
+)
+
+// Rule is the subset of an RRULE needed to expand busy time: FREQ, INTERVAL,
+// COUNT, UNTIL, BYDAY (with ordinals for monthly rules) and BYMONTHDAY.
+type Rule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+}
+
+// WeekdayNum is a BYDAY entry such as "MO" or "-1FR".
+type WeekdayNum struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// Interval is a half-open span of busy time.
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// errUnsupportedFreq is returned by parseRule for frequencies other than
+// the supported ones, such as HOURLY.
+var errUnsupportedFreq = errors.New("unsupported FREQ")
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+func parseRule(value string, loc *time.Location) (Rule, error) {
+	rule := Rule{Interval: 1}
+
+	for _, part := range strings.Split(value, ";") {
+		key, val, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(val)
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("%w: invalid INTERVAL %q", ErrInvalidCalendar, val)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("%w: invalid COUNT %q", ErrInvalidCalendar, val)
+			}
+			rule.Count = n
+		case "UNTIL":
+			t, _, err := parseDateTime(val, nil, loc)
+			if err != nil {
+				return Rule{}, err
+			}
+			rule.Until = &t
+		case "BYDAY":
+			for _, code := range strings.Split(val, ",") {
+				code = strings.ToUpper(strings.TrimSpace(code))
+				if len(code) < 2 {
+					return Rule{}, fmt.Errorf("%w: invalid BYDAY %q", ErrInvalidCalendar, val)
+				}
+				weekday, ok := weekdayCodes[code[len(code)-2:]]
+				if !ok {
+					return Rule{}, fmt.Errorf("%w: invalid BYDAY %q", ErrInvalidCalendar, val)
+				}
+				entry := WeekdayNum{Weekday: weekday}
+				if prefix := code[:len(code)-2]; prefix != "" {
+					n, err := strconv.Atoi(prefix)
+					if err != nil {
+						return Rule{}, fmt.Errorf("%w: invalid BYDAY %q", ErrInvalidCalendar, val)
+					}
+					entry.Ordinal = n
+				}
+				rule.ByDay = append(rule.ByDay, entry)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(day)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return Rule{}, fmt.Errorf("%w: invalid BYMONTHDAY %q", ErrInvalidCalendar, val)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		}
+	}
+
+	switch rule.Freq {
+	case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+		return rule, nil
+	default:
+		return Rule{}, fmt.Errorf("%w %q", errUnsupportedFreq, rule.Freq)
+	}
+}
+
+// Busy expands events into merged busy intervals overlapping [from, to).
+// Cancelled and transparent events are ignored, EXDATEs are removed, and
+// instances overridden via RECURRENCE-ID are replaced by their override.
+func Busy(events []VEvent, from, to time.Time) []Interval {
+	overridden := make(map[string]map[int64]struct{})
+	for _, event := range events {
+		if event.RecurrenceID == nil {
+			continue
+		}
+		if overridden[event.UID] == nil {
+			overridden[event.UID] = make(map[int64]struct{})
+		}
+		overridden[event.UID][event.RecurrenceID.Unix()] = struct{}{}
+	}
+
+	var intervals []Interval
+	for _, event := range events {
+		if event.Status == "CANCELLED" || event.Transparent {
+			continue
+		}
+
+		length := event.End.Sub(event.Start)
+		if length <= 0 {
+			continue
+		}
+
+		if event.Rule == nil || event.RecurrenceID != nil {
+			if event.Start.Before(to) && event.End.After(from) {
+				intervals = append(intervals, Interval{Start: event.Start, End: event.End})
+			}
+			continue
+		}
+
+		excluded := make(map[int64]struct{}, len(event.ExDates))
+		for _, exdate := range event.ExDates {
+			excluded[exdate.Unix()] = struct{}{}
+		}
+		for unix := range overridden[event.UID] {
+			excluded[unix] = struct{}{}
+		}
+
+		for _, start := range occurrences(event.Start, *event.Rule, from.Add(-length), to) {
+			if _, skip := excluded[start.Unix()]; skip {
+				continue
+			}
+			end := start.Add(length)
+			if start.Before(to) && end.After(from) {
+				intervals = append(intervals, Interval{Start: start, End: end})
+			}
+		}
+	}
+
+	return merge(intervals, from, to)
+}
+
+// occurrences returns the rule's instance start times in [from, to). COUNT is
+// applied from dtstart regardless of from, so counted rules are expanded from
+// their first period; other rules start at the last period beginning at or
+// before from, and maxOccurrences caps the instances generated from there.
+func occurrences(dtstart time.Time, rule Rule, from, to time.Time) []time.Time {
+	var (
+		result    []time.Time
+		generated int
+	)
+
+	limit := maxOccurrences
+	first := 0
+	if rule.Count > 0 {
+		limit = rule.Count
+	} else {
+		first = firstPeriod(dtstart, rule, from)
+	}
+
+	for period := first; period < first+maxPeriods && generated < limit; period++ {
+		candidates := periodCandidates(dtstart, rule, period)
+		if candidates == nil {
+			break
+		}
+
+		for _, candidate := range candidates {
+			if candidate.Before(dtstart) {
+				continue
+			}
+			if rule.Until != nil && candidate.After(*rule.Until) {
+				return result
+			}
+			if generated >= limit {
+				return result
+			}
+			if !candidate.Before(to) {
+				return result
+			}
+			generated++
+			if !candidate.Before(from) {
+				result = append(result, candidate)
+			}
+		}
+	}
+
+	return result
+}
+
+// firstPeriod returns the index of a period starting at or before from, one
+// period early so instances of the period straddling from are not skipped.
+func firstPeriod(dtstart time.Time, rule Rule, from time.Time) int {
+	from = from.In(dtstart.Location())
+	if !from.After(dtstart) {
+		return 0
+	}
+
+	var elapsed int
+	switch rule.Freq {
+	case FreqDaily:
+		elapsed = civilDays(dtstart, from)
+	case FreqWeekly:
+		elapsed = civilDays(dtstart, from) / 7
+	case FreqMonthly:
+		elapsed = (from.Year()-dtstart.Year())*12 + int(from.Month()) - int(dtstart.Month())
+	case FreqYearly:
+		elapsed = from.Year() - dtstart.Year()
+	}
+
+	return max(elapsed/rule.Interval-1, 0)
+}
+
+// civilDays counts calendar days from a to b, ignoring DST shifts.
+func civilDays(a, b time.Time) int {
+	day := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC) }
+	return int(day(b).Sub(day(a)) / (24 * time.Hour))
+}
+
+// periodCandidates returns the sorted instance starts in the period-th
+// interval of the rule, or an empty non-nil slice when none fall in it.
+func periodCandidates(dtstart time.Time, rule Rule, period int) []time.Time {
+	loc := dtstart.Location()
+	hour, minute, second := dtstart.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, minute, second, 0, loc)
+	}
+
+	candidates := make([]time.Time, 0, 4)
+
+	switch rule.Freq {
+	case FreqDaily:
+		day := dtstart.AddDate(0, 0, period*rule.Interval)
+		if len(rule.ByDay) > 0 && !hasWeekday(rule.ByDay, day.Weekday()) {
+			break
+		}
+		candidates = append(candidates, at(day.Year(), day.Month(), day.Day()))
+	case FreqWeekly:
+		offset := (int(dtstart.Weekday()) + 6) % 7 // days since Monday
+		monday := dtstart.AddDate(0, 0, -offset+period*rule.Interval*7)
+		if len(rule.ByDay) == 0 {
+			day := monday.AddDate(0, 0, offset)
+			candidates = append(candidates, at(day.Year(), day.Month(), day.Day()))
+			break
+		}
+		for _, entry := range rule.ByDay {
+			day := monday.AddDate(0, 0, (int(entry.Weekday)+6)%7)
+			candidates = append(candidates, at(day.Year(), day.Month(), day.Day()))
+		}
+	case FreqMonthly:
+		first := time.Date(dtstart.Year(), dtstart.Month()+time.Month(period*rule.Interval), 1, 0, 0, 0, 0, loc)
+		year, month := first.Year(), first.Month()
+		days := daysIn(year, month, loc)
+
+		if len(rule.ByDay) == 0 && len(rule.ByMonthDay) == 0 {
+			if dtstart.Day() <= days {
+				candidates = append(candidates, at(year, month, dtstart.Day()))
+			}
+			break
+		}
+
+		// BYDAY and BYMONTHDAY each restrict the month's days; when both
+		// are given only days matching both are instances.
+		matches := make(map[int]int)
+		for _, entry := range rule.ByDay {
+			for _, day := range monthWeekdays(year, month, entry, loc) {
+				matches[day] |= 1
+			}
+		}
+		for _, day := range rule.ByMonthDay {
+			if day < 0 {
+				day = days + day + 1
+			}
+			if day >= 1 && day <= days {
+				matches[day] |= 2
+			}
+		}
+
+		want := 0
+		if len(rule.ByDay) > 0 {
+			want |= 1
+		}
+		if len(rule.ByMonthDay) > 0 {
+			want |= 2
+		}
+		for day, matched := range matches {
+			if matched == want {
+				candidates = append(candidates, at(year, month, day))
+			}
+		}
+	case FreqYearly:
+		year := dtstart.Year() + period*rule.Interval
+		if dtstart.Day() <= daysIn(year, dtstart.Month(), loc) {
+			candidates = append(candidates, at(year, dtstart.Month(), dtstart.Day()))
+		}
+	default:
+		return nil
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Before(candidates[j]) })
+	return candidates
+}
+
+func hasWeekday(entries []WeekdayNum, weekday time.Weekday) bool {
+	for _, entry := range entries {
+		if entry.Weekday == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+func daysIn(year int, month time.Month, loc *time.Location) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+}
+
+// monthWeekdays returns the days of month matching a BYDAY entry, e.g. every
+// Monday for "MO", the second Tuesday for "2TU" or the last Friday for "-1FR".
+func monthWeekdays(year int, month time.Month, entry WeekdayNum, loc *time.Location) []int {
+	var days []int
+	for day := 1; day <= daysIn(year, month, loc); day++ {
+		if time.Date(year, month, day, 0, 0, 0, 0, loc).Weekday() == entry.Weekday {
+			days = append(days, day)
+		}
+	}
+
+	switch {
+	case entry.Ordinal > 0 && entry.Ordinal <= len(days):
+		return []int{days[entry.Ordinal-1]}
+	case entry.Ordinal < 0 && -entry.Ordinal <= len(days):
+		return []int{days[len(days)+entry.Ordinal]}
+	case entry.Ordinal != 0:
+		return nil
+	default:
+		return days
+	}
+}
+
+// merge clips intervals to [from, to) and coalesces overlapping spans.
+func merge(intervals []Interval, from, to time.Time) []Interval {
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].Start.Before(intervals[j].Start) })
+
+	merged := make([]Interval, 0, len(intervals))
+	for _, interval := range intervals {
+		if interval.Start.Before(from) {
+			interval.Start = from
+		}
+		if interval.End.After(to) {
+			interval.End = to
+		}
+		if !interval.Start.Before(interval.End) {
+			continue
+		}
+
+		if n := len(merged); n > 0 && !interval.Start.After(merged[n-1].End) {
+			if interval.End.After(merged[n-1].End) {
+				merged[n-1].End = interval.End
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+
+	return merged
+}
@@ -0,0 +1,140 @@
+// Package impact renders goal impact maps as Graphviz DOT and Mermaid
+// flowcharts for chapter reviews.
+package impact
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Goal is a node of the impact map. Goals with a ParentID are drawn with an
+// edge to their parent, which must also be in the map.
+type Goal struct {
+	ID                uuid.UUID
+	ParentID          *uuid.UUID
+	Title             string
+	Intents           int
+	Outcomes          int
+	EvidenceLinks     int
+	Sessions          int
+	CriteriaSatisfied int
+	CriteriaTotal     int
+}
+
+// EncodeDOT writes goals to w as a Graphviz digraph with edges from each
+// goal to its parent.
+func EncodeDOT(w io.Writer, goals []Goal) error {
+	var buf bytes.Buffer
+
+	buf.WriteString("digraph impact {\n")
+	buf.WriteString("  rankdir=BT;\n")
+	buf.WriteString("  node [shape=box, style=rounded];\n")
+
+	known := nodeIDs(goals)
+	for _, goal := range goals {
+		fmt.Fprintf(&buf, "  %s [label=\"%s\"];\n", nodeID(goal.ID), escapeDOT(label(goal)))
+	}
+	for _, goal := range goals {
+		if parent, ok := parentOf(goal, known); ok {
+			fmt.Fprintf(&buf, "  %s -> %s;\n", nodeID(goal.ID), nodeID(parent))
+		}
+	}
+
+	buf.WriteString("}\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// EncodeMermaid writes goals to w as a bottom-up Mermaid flowchart with
+// edges from each goal to its parent.
+func EncodeMermaid(w io.Writer, goals []Goal) error {
+	var buf bytes.Buffer
+
+	buf.WriteString("flowchart BT\n")
+
+	known := nodeIDs(goals)
+	for _, goal := range goals {
+		fmt.Fprintf(&buf, "  %s[\"%s\"]\n", nodeID(goal.ID), escapeMermaid(label(goal)))
+	}
+	for _, goal := range goals {
+		if parent, ok := parentOf(goal, known); ok {
+			fmt.Fprintf(&buf, "  %s --> %s\n", nodeID(goal.ID), nodeID(parent))
+		}
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// label summarises a goal on one line per fact.
+func label(goal Goal) string {
+	lines := []string{
+		goal.Title,
+		fmt.Sprintf("%s, %s", plural(goal.Intents, "intent"), plural(goal.Outcomes, "outcome")),
+		fmt.Sprintf("%s, %s", plural(goal.EvidenceLinks, "evidence link"), plural(goal.Sessions, "session")),
+		fmt.Sprintf("%d/%d criteria satisfied", goal.CriteriaSatisfied, goal.CriteriaTotal),
+	}
+	return strings.Join(lines, "\n")
+}
+
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// nodeID derives an identifier both formats accept without quoting.
+func nodeID(id uuid.UUID) string {
+	return "g" + strings.ReplaceAll(id.String(), "-", "")
+}
+
+func nodeIDs(goals []Goal) map[uuid.UUID]struct{} {
+	ids := make(map[uuid.UUID]struct{}, len(goals))
+	for _, goal := range goals {
+		ids[goal.ID] = struct{}{}
+	}
+	return ids
+}
+
+// parentOf returns the goal's parent when it is part of the map, so that an
+// edge never points at a node that was not drawn.
+func parentOf(goal Goal, known map[uuid.UUID]struct{}) (uuid.UUID, bool) {
+	if goal.ParentID == nil {
+		return uuid.UUID{}, false
+	}
+	if _, ok := known[*goal.ParentID]; !ok {
+		return uuid.UUID{}, false
+	}
+	return *goal.ParentID, true
+}
+
+// escapeDOT escapes a double-quoted DOT string, turning newlines into
+// centred line breaks.
+func escapeDOT(value string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+	return replacer.Replace(value)
+}
+
+// escapeMermaid escapes a quoted Mermaid label using its entity codes and
+// HTML line breaks.
+func escapeMermaid(value string) string {
+	replacer := strings.NewReplacer(
+		`"`, "#quot;",
+		"\r\n", "<br/>",
+		"\n", "<br/>",
+		"\r", "<br/>",
+	)
+	return replacer.Replace(value)
+}
@@ -0,0 +1,75 @@
+package impact
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func testGoals() (Goal, Goal) {
+	parentID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	parent := Goal{ID: parentID, Title: `Grow "enterprise" revenue`, CriteriaTotal: 2}
+	child := Goal{
+		ID:                uuid.MustParse("22222222-2222-2222-2222-222222222222"),
+		ParentID:          &parentID,
+		Title:             "Reliable checkout",
+		Intents:           3,
+		Outcomes:          1,
+		EvidenceLinks:     4,
+		Sessions:          2,
+		CriteriaSatisfied: 1,
+		CriteriaTotal:     2,
+	}
+	return parent, child
+}
+
+func TestEncodeDOT(t *testing.T) {
+	parent, child := testGoals()
+
+	var buf bytes.Buffer
+	if err := EncodeDOT(&buf, []Goal{parent, child}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"digraph impact {\n",
+		`g11111111111111111111111111111111 [label="Grow \"enterprise\" revenue\n0 intents, 0 outcomes\n0 evidence links, 0 sessions\n0/2 criteria satisfied"];`,
+		`[label="Reliable checkout\n3 intents, 1 outcome\n4 evidence links, 2 sessions\n1/2 criteria satisfied"];`,
+		"g22222222222222222222222222222222 -> g11111111111111111111111111111111;\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestEncodeMermaidSkipsParentsOutsideMap(t *testing.T) {
+	parent, child := testGoals()
+
+	var buf bytes.Buffer
+	if err := EncodeMermaid(&buf, []Goal{parent, child}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"flowchart BT\n",
+		`g11111111111111111111111111111111["Grow #quot;enterprise#quot; revenue<br/>0 intents, 0 outcomes<br/>0 evidence links, 0 sessions<br/>0/2 criteria satisfied"]`,
+		"g22222222222222222222222222222222 --> g11111111111111111111111111111111\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := EncodeMermaid(&buf, []Goal{child}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(buf.String(), "-->") {
+		t.Fatalf("expected no edge to a goal outside the map, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,107 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/example/intent/backend/internal/database"
+)
+
+// Job kinds for intent coverage snapshots. The planner runs every
+// PlanInterval and enqueues a snapshot at each of NudgeLeads before every
+// scheduled session, the same checkpoints members are nudged at.
+const (
+	KindCoveragePlan     = "coverage.plan"
+	KindCoverageSnapshot = "coverage.snapshot"
+)
+
+// CoveragePayload identifies the session and checkpoint of a snapshot job.
+type CoveragePayload struct {
+	SessionID  uuid.UUID `json:"sessionId"`
+	Checkpoint string    `json:"checkpoint"`
+}
+
+// RegisterCoverage registers the coverage planner and snapshot jobs with the
+// pool.
+func RegisterCoverage(pool *Pool, logger *slog.Logger, db *sql.DB) {
+	pool.Register(KindCoveragePlan, func(ctx context.Context, job database.Job) error {
+		now := time.Now().UTC()
+		if err := EnqueuePlanner(ctx, db, KindCoveragePlan, now.Add(PlanInterval)); err != nil {
+			return err
+		}
+		_, err := PlanCoverageSnapshots(ctx, db, now)
+		return err
+	})
+	pool.Register(KindCoverageSnapshot, func(ctx context.Context, job database.Job) error {
+		return captureCoverage(ctx, logger, db, job)
+	})
+}
+
+// PlanCoverageSnapshots enqueues a snapshot for every checkpoint of a
+// scheduled session that falls within one planner interval of now. Unlike
+// nudges, a checkpoint that passed longer ago is skipped rather than caught
+// up: coverage captured hours late would not describe the checkpoint. It
+// returns the number of new jobs.
+func PlanCoverageSnapshots(ctx context.Context, db *sql.DB, now time.Time) (int, error) {
+	horizon := now.Add(NudgeLeads[0].Before + PlanInterval)
+	sessions, err := database.ListSessions(ctx, db, database.SessionFilters{
+		StartsAfter:  &now,
+		StartsBefore: &horizon,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, session := range sessions {
+		if session.State != database.SessionScheduled {
+			continue
+		}
+
+		for _, lead := range NudgeLeads {
+			runAt := session.StartsAt.Add(-lead.Before)
+			if runAt.After(now.Add(PlanInterval)) || runAt.Before(now.Add(-PlanInterval)) {
+				continue
+			}
+
+			ok, err := database.EnqueueJob(ctx, db, database.JobInput{
+				Kind:    KindCoverageSnapshot,
+				Key:     session.ID.String() + ":" + lead.Name,
+				Payload: CoveragePayload{SessionID: session.ID, Checkpoint: lead.Name},
+				RunAt:   runAt,
+			})
+			if err != nil {
+				return created, err
+			}
+			if ok {
+				created++
+			}
+		}
+	}
+
+	return created, nil
+}
+
+func captureCoverage(ctx context.Context, logger *slog.Logger, db *sql.DB, job database.Job) error {
+	var payload CoveragePayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("decode coverage payload: %w", err)
+	}
+
+	snapshot, ok, err := database.RecordCoverageSnapshot(ctx, db, payload.SessionID, payload.Checkpoint, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		logger.InfoContext(ctx, "coverage captured", "session_id", payload.SessionID, "checkpoint", payload.Checkpoint, "declared", snapshot.DeclaredMembers, "available", snapshot.AvailableMembers)
+	}
+
+	return nil
+}
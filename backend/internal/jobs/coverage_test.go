@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+
+	"github.com/example/intent/backend/internal/database"
+)
+
+func TestPlanCoverageSnapshotsSkipsStaleCheckpoints(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now().UTC()
+	tomorrow, inAnHour, shortNotice := uuid.New(), uuid.New(), uuid.New()
+	chapterID := uuid.New()
+
+	rows := sqlmock.NewRows(sessionRowColumns)
+	for _, session := range []struct {
+		id       uuid.UUID
+		startsAt time.Time
+	}{
+		{id: tomorrow, startsAt: now.Add(24*time.Hour + 2*time.Minute)},
+		{id: inAnHour, startsAt: now.Add(time.Hour - time.Minute)},
+		{id: shortNotice, startsAt: now.Add(30 * time.Minute)},
+	} {
+		rows.AddRow(session.id, chapterID, session.startsAt, session.startsAt.Add(4*time.Hour), database.SessionScheduled, nil, nil, now)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM sessions WHERE starts_at >= $1 AND starts_at <= $2")).
+		WithArgs(now, now.Add(24*time.Hour+PlanInterval)).
+		WillReturnRows(rows)
+	for _, key := range []string{tomorrow.String() + ":24h", inAnHour.String() + ":1h"} {
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO jobs")).
+			WithArgs(sqlmock.AnyArg(), KindCoverageSnapshot, key, sqlmock.AnyArg(), database.DefaultJobAttempts, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	created, err := PlanCoverageSnapshots(context.Background(), db, now)
+	if err != nil {
+		t.Fatalf("PlanCoverageSnapshots returned error: %v", err)
+	}
+	if created != 2 {
+		t.Fatalf("expected 2 snapshot jobs got %d", created)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
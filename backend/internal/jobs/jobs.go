@@ -0,0 +1,180 @@
+// Package jobs runs background work from the durable Postgres job queue.
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+)
+
+// Handler performs a claimed job. Jobs may run more than once (after a
+// failure, or when a worker dies mid-job), so handlers must be idempotent.
+type Handler func(ctx context.Context, job database.Job) error
+
+const (
+	// DefaultPollInterval is how long an idle worker waits before looking
+	// for due jobs again.
+	DefaultPollInterval = 5 * time.Second
+	// DefaultLease is how long a worker holds a job before another worker
+	// may reclaim it. Handlers are cancelled when their lease runs out.
+	DefaultLease = 5 * time.Minute
+)
+
+// PlanInterval is how often planner jobs, which look for work coming due and
+// enqueue the jobs that do it, run.
+const PlanInterval = 5 * time.Minute
+
+// EnqueuePlanner schedules a run of the planner job kind for the interval
+// containing at. Every server enqueues one at start-up and each run enqueues
+// the next; the key collapses them into one.
+func EnqueuePlanner(ctx context.Context, db *sql.DB, kind string, at time.Time) error {
+	runAt := at.UTC().Truncate(PlanInterval)
+	_, err := database.EnqueueJob(ctx, db, database.JobInput{
+		Kind:  kind,
+		Key:   runAt.Format(time.RFC3339),
+		RunAt: runAt,
+	})
+	return err
+}
+
+// Backoff returns the delay before the next attempt of a job that has failed
+// attempts times: 30s doubling per attempt, capped at one hour.
+func Backoff(attempts int) time.Duration {
+	const (
+		base    = 30 * time.Second
+		ceiling = time.Hour
+	)
+
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	delay := base
+	for i := 1; i < attempts && delay < ceiling; i++ {
+		delay *= 2
+	}
+
+	return min(delay, ceiling)
+}
+
+// Pool is a set of workers claiming jobs for the registered kinds.
+type Pool struct {
+	logger   *slog.Logger
+	db       *sql.DB
+	workers  int
+	poll     time.Duration
+	lease    time.Duration
+	handlers map[string]Handler
+}
+
+// NewPool constructs a pool of the given number of workers.
+func NewPool(logger *slog.Logger, db *sql.DB, workers int) *Pool {
+	return &Pool{
+		logger:   logger,
+		db:       db,
+		workers:  workers,
+		poll:     DefaultPollInterval,
+		lease:    DefaultLease,
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Register routes jobs of kind to handler. It must be called before Run.
+func (p *Pool) Register(kind string, handler Handler) {
+	p.handlers[kind] = handler
+}
+
+// Run starts the workers and blocks until ctx is cancelled and every worker
+// has finished its current job.
+func (p *Pool) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (p *Pool) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		if p.RunNext(ctx) {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(p.poll):
+		}
+	}
+}
+
+// RunNext claims and runs one due job, reporting whether there was one.
+// A failed job is retried after Backoff, or dead-lettered once it has used
+// all of its attempts.
+func (p *Pool) RunNext(ctx context.Context) bool {
+	kinds := make([]string, 0, len(p.handlers))
+	for kind := range p.handlers {
+		kinds = append(kinds, kind)
+	}
+
+	job, err := database.ClaimJob(ctx, p.db, kinds, p.lease)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) && ctx.Err() == nil {
+			p.logger.ErrorContext(ctx, "failed to claim job", "error", err)
+		}
+		return false
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, p.lease)
+	err = p.run(runCtx, job)
+	cancel()
+
+	// Record the outcome even when shutting down, so a finished job is not
+	// run again once its lease lapses.
+	ctx = context.WithoutCancel(ctx)
+
+	if err == nil {
+		if err := database.CompleteJob(ctx, p.db, job); err != nil {
+			p.logger.ErrorContext(ctx, "failed to complete job", "job_id", job.ID, "kind", job.Kind, "error", err)
+		}
+		return true
+	}
+
+	status, failErr := database.FailJob(ctx, p.db, job, err, time.Now().UTC().Add(Backoff(job.Attempts)))
+	if failErr != nil {
+		p.logger.ErrorContext(ctx, "failed to record job failure", "job_id", job.ID, "kind", job.Kind, "error", failErr)
+		return true
+	}
+
+	if status == database.JobDead {
+		p.logger.ErrorContext(ctx, "job dead-lettered", "job_id", job.ID, "kind", job.Kind, "attempts", job.Attempts, "error", err)
+	} else {
+		p.logger.WarnContext(ctx, "job failed, will retry", "job_id", job.ID, "kind", job.Kind, "attempts", job.Attempts, "error", err)
+	}
+
+	return true
+}
+
+func (p *Pool) run(ctx context.Context, job database.Job) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+
+	handler, ok := p.handlers[job.Kind]
+	if !ok {
+		return fmt.Errorf("no handler for job kind %q", job.Kind)
+	}
+
+	return handler(ctx, job)
+}
@@ -0,0 +1,132 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+
+	"github.com/example/intent/backend/internal/database"
+)
+
+var jobRowColumns = []string{"id", "kind", "key", "payload", "status", "attempts", "max_attempts", "run_at", "locked_until", "last_error", "created_at", "updated_at"}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: 30 * time.Second},
+		{attempts: 1, want: 30 * time.Second},
+		{attempts: 2, want: time.Minute},
+		{attempts: 4, want: 4 * time.Minute},
+		{attempts: 20, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func expectClaim(mock sqlmock.Sqlmock, jobID uuid.UUID, attempts, maxAttempts int) {
+	now := time.Now().UTC()
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+		WithArgs(`{"test"}`, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(jobRowColumns).
+			AddRow(jobID, "test", "key", []byte(`{}`), database.JobRunning, attempts, maxAttempts, now, now.Add(DefaultLease), nil, now, now))
+}
+
+func TestPoolRunNextCompletesJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	jobID := uuid.New()
+	expectClaim(mock, jobID, 1, 5)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE jobs SET status = 'done'")).
+		WithArgs(jobID, sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	pool := NewPool(discardLogger(), db, 1)
+	ran := false
+	pool.Register("test", func(context.Context, database.Job) error {
+		ran = true
+		return nil
+	})
+
+	if !pool.RunNext(context.Background()) {
+		t.Fatal("expected a job to run")
+	}
+	if !ran {
+		t.Fatal("expected handler to be called")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestPoolRunNextDeadLettersPanickingJob(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	jobID := uuid.New()
+	expectClaim(mock, jobID, 5, 5)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE jobs SET status = $2")).
+		WithArgs(jobID, database.JobDead, sqlmock.AnyArg(), "job panicked: boom", sqlmock.AnyArg(), 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	pool := NewPool(discardLogger(), db, 1)
+	pool.Register("test", func(context.Context, database.Job) error {
+		panic("boom")
+	})
+
+	if !pool.RunNext(context.Background()) {
+		t.Fatal("expected a job to run")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestPoolRunNextIdle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE SKIP LOCKED")).
+		WillReturnRows(sqlmock.NewRows(jobRowColumns))
+
+	pool := NewPool(discardLogger(), db, 1)
+	pool.Register("test", func(context.Context, database.Job) error {
+		return errors.New("should not run")
+	})
+
+	if pool.RunNext(context.Background()) {
+		t.Fatal("expected no job to run")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/notify"
+	"github.com/example/intent/backend/internal/schedule"
+)
+
+// Job kinds for pre-session nudges. The planner runs every PlanInterval,
+// enqueues a nudge job for each lead of every session starting soon and
+// enqueues its own next run.
+const (
+	KindNudgePlan    = "nudge.plan"
+	KindSessionNudge = "session.nudge"
+)
+
+// NudgeLead is how long before a session starts a nudge is sent.
+type NudgeLead struct {
+	Name   string
+	Before time.Duration
+}
+
+// NudgeLeads lists the nudges sent ahead of every session, longest first.
+var NudgeLeads = []NudgeLead{
+	{Name: "24h", Before: 24 * time.Hour},
+	{Name: "1h", Before: time.Hour},
+}
+
+// NudgePayload identifies the session and lead of a nudge job.
+type NudgePayload struct {
+	SessionID uuid.UUID `json:"sessionId"`
+	Lead      string    `json:"lead"`
+}
+
+// RegisterNudges registers the nudge planner and nudge jobs with the pool.
+// Nudges are sent through notifier.
+func RegisterNudges(pool *Pool, logger *slog.Logger, db *sql.DB, notifier *notify.Notifier) {
+	pool.Register(KindNudgePlan, func(ctx context.Context, job database.Job) error {
+		now := time.Now().UTC()
+		if err := EnqueuePlanner(ctx, db, KindNudgePlan, now.Add(PlanInterval)); err != nil {
+			return err
+		}
+		_, err := PlanNudges(ctx, db, now)
+		return err
+	})
+	pool.Register(KindSessionNudge, func(ctx context.Context, job database.Job) error {
+		return sendNudges(ctx, logger, db, notifier, job)
+	})
+}
+
+// PlanNudges enqueues the nudge jobs for sessions starting within the longest
+// lead (plus one planner interval) of now. A lead whose time has passed is
+// sent at once, unless a shorter lead is also due, so a session scheduled at
+// short notice gets a single nudge. It returns the number of new jobs.
+func PlanNudges(ctx context.Context, db *sql.DB, now time.Time) (int, error) {
+	horizon := now.Add(NudgeLeads[0].Before + PlanInterval)
+	sessions, err := database.ListSessions(ctx, db, database.SessionFilters{
+		StartsAfter:  &now,
+		StartsBefore: &horizon,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, session := range sessions {
+		if session.State != database.SessionScheduled {
+			continue
+		}
+
+		for i, lead := range NudgeLeads {
+			runAt := session.StartsAt.Add(-lead.Before)
+			if runAt.After(now.Add(PlanInterval)) {
+				continue
+			}
+			if i+1 < len(NudgeLeads) && !session.StartsAt.Add(-NudgeLeads[i+1].Before).After(now) {
+				continue
+			}
+
+			ok, err := database.EnqueueJob(ctx, db, database.JobInput{
+				Kind:    KindSessionNudge,
+				Key:     session.ID.String() + ":" + lead.Name,
+				Payload: NudgePayload{SessionID: session.ID, Lead: lead.Name},
+				RunAt:   runAt,
+			})
+			if err != nil {
+				return created, err
+			}
+			if ok {
+				created++
+			}
+		}
+	}
+
+	return created, nil
+}
+
+func sendNudges(ctx context.Context, logger *slog.Logger, db *sql.DB, notifier *notify.Notifier, job database.Job) error {
+	var payload NudgePayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return err
+	}
+
+	loc, err := sessionLocation(ctx, db, payload.SessionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	nudges, err := database.RecordSessionNudges(ctx, db, payload.SessionID, payload.Lead, func(tx *sql.Tx, nudge database.SessionNudge) error {
+		_, err := notifier.Notify(ctx, tx, nudgeNotification(nudge, loc))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.InfoContext(ctx, "session nudges sent", "session_id", payload.SessionID, "lead", payload.Lead, "members", len(nudges))
+	return nil
+}
+
+// sessionLocation returns the timezone of the session's chapter, in which
+// nudges state the start time.
+func sessionLocation(ctx context.Context, db *sql.DB, sessionID uuid.UUID) (*time.Location, error) {
+	session, err := database.GetSession(ctx, db, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	chapter, err := database.GetChapter(ctx, db, session.ChapterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return schedule.LoadLocation(chapter.Timezone)
+}
+
+func nudgeNotification(nudge database.SessionNudge, loc *time.Location) database.NotificationInput {
+	startsAt := nudge.SessionStartsAt.In(loc).Format("Mon 2 Jan at 15:04 MST")
+
+	input := database.NotificationInput{
+		MemberID: nudge.MemberID,
+		Type:     notify.TypeSessionNudge,
+		Title:    "Declare your intent for the next session",
+		Body:     fmt.Sprintf("Your chapter session starts %s and you have not declared an intent for it yet.", startsAt),
+	}
+	if nudge.Reason == database.NudgeRefine {
+		input.Title = "Refine your intent for the next session"
+		input.Body = fmt.Sprintf("Your chapter session starts %s and your intent for it is still a draft. Refine it before the session starts.", startsAt)
+	}
+
+	return input
+}
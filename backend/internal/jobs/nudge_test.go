@@ -0,0 +1,88 @@
+package jobs
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/notify"
+)
+
+var sessionRowColumns = []string{"id", "chapter_id", "starts_at", "ends_at", "state", "kicked_off_at", "closed_at", "created_at"}
+
+func TestPlanNudgesSendsOnlyTheShortestDueLead(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now().UTC()
+	tomorrow, soon, shortNotice, closed := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	chapterID := uuid.New()
+
+	rows := sqlmock.NewRows(sessionRowColumns)
+	for _, session := range []struct {
+		id       uuid.UUID
+		startsAt time.Time
+		state    string
+	}{
+		{id: tomorrow, startsAt: now.Add(24*time.Hour + time.Minute), state: database.SessionScheduled},
+		{id: soon, startsAt: now.Add(3 * time.Hour), state: database.SessionScheduled},
+		{id: shortNotice, startsAt: now.Add(30 * time.Minute), state: database.SessionScheduled},
+		{id: closed, startsAt: now.Add(2 * time.Hour), state: database.SessionClosed},
+	} {
+		rows.AddRow(session.id, chapterID, session.startsAt, session.startsAt.Add(4*time.Hour), session.state, nil, nil, now)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM sessions WHERE starts_at >= $1 AND starts_at <= $2")).
+		WithArgs(now, now.Add(24*time.Hour+PlanInterval)).
+		WillReturnRows(rows)
+	for _, key := range []string{tomorrow.String() + ":24h", soon.String() + ":24h", shortNotice.String() + ":1h"} {
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO jobs")).
+			WithArgs(sqlmock.AnyArg(), KindSessionNudge, key, sqlmock.AnyArg(), database.DefaultJobAttempts, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	created, err := PlanNudges(context.Background(), db, now)
+	if err != nil {
+		t.Fatalf("PlanNudges returned error: %v", err)
+	}
+	if created != 3 {
+		t.Fatalf("expected 3 nudge jobs got %d", created)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestNudgeNotificationStatesStartInChapterTime(t *testing.T) {
+	loc, err := time.LoadLocation("Pacific/Auckland")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	nudge := database.SessionNudge{
+		MemberID:        uuid.New(),
+		Reason:          database.NudgeRefine,
+		SessionStartsAt: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+	}
+
+	input := nudgeNotification(nudge, loc)
+	if input.Type != notify.TypeSessionNudge || input.MemberID != nudge.MemberID {
+		t.Fatalf("unexpected notification: %+v", input)
+	}
+	if input.Title != "Refine your intent for the next session" {
+		t.Fatalf("unexpected title %q", input.Title)
+	}
+	if want := "starts Mon 19 Oct at 13:00 NZDT"; !strings.Contains(input.Body, want) {
+		t.Fatalf("expected body to contain %q got %q", want, input.Body)
+	}
+}
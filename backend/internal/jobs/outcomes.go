@@ -0,0 +1,157 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/notify"
+)
+
+// Job kinds for missing-outcome alerts. The planner runs every PlanInterval
+// and enqueues a check for every session that has been closed, or ended more
+// than database.MissingOutcomeGrace ago; a check alerts authors and Chapter
+// Leads and enqueues the session's escalation.
+const (
+	KindOutcomePlan     = "outcome.plan"
+	KindOutcomeCheck    = "outcome.check"
+	KindOutcomeEscalate = "outcome.escalate"
+)
+
+// OutcomeLookback bounds how far back the planner looks for sessions, so a
+// fresh deployment does not alert on its whole history.
+const OutcomeLookback = 7 * 24 * time.Hour
+
+// OutcomePayload identifies the session of an outcome check or escalation.
+type OutcomePayload struct {
+	SessionID uuid.UUID `json:"sessionId"`
+}
+
+// RegisterOutcomeAlerts registers the missing-outcome planner, checks and
+// escalations with the pool. Leads get an escalation digest escalateAfter a
+// session's check for intents that still lack an outcome.
+func RegisterOutcomeAlerts(pool *Pool, logger *slog.Logger, db *sql.DB, notifier *notify.Notifier, escalateAfter time.Duration) {
+	pool.Register(KindOutcomePlan, func(ctx context.Context, job database.Job) error {
+		now := time.Now().UTC()
+		if err := EnqueuePlanner(ctx, db, KindOutcomePlan, now.Add(PlanInterval)); err != nil {
+			return err
+		}
+		_, err := PlanOutcomeChecks(ctx, db, now)
+		return err
+	})
+	pool.Register(KindOutcomeCheck, func(ctx context.Context, job database.Job) error {
+		return sendOutcomeAlerts(ctx, logger, db, notifier, job, database.OutcomeAlertMissing, escalateAfter)
+	})
+	pool.Register(KindOutcomeEscalate, func(ctx context.Context, job database.Job) error {
+		return sendOutcomeAlerts(ctx, logger, db, notifier, job, database.OutcomeAlertEscalation, 0)
+	})
+}
+
+// PlanOutcomeChecks enqueues a check for every session that started within
+// OutcomeLookback of now and has been closed, or ended more than
+// database.MissingOutcomeGrace ago. It returns the number of new jobs.
+func PlanOutcomeChecks(ctx context.Context, db *sql.DB, now time.Time) (int, error) {
+	from := now.Add(-OutcomeLookback)
+	sessions, err := database.ListSessions(ctx, db, database.SessionFilters{
+		StartsAfter:  &from,
+		StartsBefore: &now,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, session := range sessions {
+		if session.State != database.SessionClosed && session.EndsAt.After(now.Add(-database.MissingOutcomeGrace)) {
+			continue
+		}
+
+		ok, err := database.EnqueueJob(ctx, db, database.JobInput{
+			Kind:    KindOutcomeCheck,
+			Key:     session.ID.String(),
+			Payload: OutcomePayload{SessionID: session.ID},
+		})
+		if err != nil {
+			return created, err
+		}
+		if ok {
+			created++
+		}
+	}
+
+	return created, nil
+}
+
+func sendOutcomeAlerts(ctx context.Context, logger *slog.Logger, db *sql.DB, notifier *notify.Notifier, job database.Job, kind string, escalateAfter time.Duration) error {
+	var payload OutcomePayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return err
+	}
+
+	loc, err := sessionLocation(ctx, db, payload.SessionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	alerts, err := database.RecordOutcomeAlerts(ctx, db, payload.SessionID, kind, func(tx *sql.Tx, alert database.OutcomeAlert) error {
+		_, err := notifier.Notify(ctx, tx, outcomeNotification(alert, loc))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if escalateAfter > 0 {
+		if _, err := database.EnqueueJob(ctx, db, database.JobInput{
+			Kind:    KindOutcomeEscalate,
+			Key:     payload.SessionID.String(),
+			Payload: payload,
+			RunAt:   time.Now().UTC().Add(escalateAfter),
+		}); err != nil {
+			return err
+		}
+	}
+
+	logger.InfoContext(ctx, "missing outcome alerts sent", "session_id", payload.SessionID, "kind", kind, "recipients", len(alerts))
+	return nil
+}
+
+func outcomeNotification(alert database.OutcomeAlert, loc *time.Location) database.NotificationInput {
+	var list strings.Builder
+	authored := true
+	for _, intent := range alert.Intents {
+		fmt.Fprintf(&list, "\n- %s", intent.Statement)
+		if intent.MemberID == nil || *intent.MemberID != alert.RecipientID {
+			authored = false
+		}
+	}
+
+	ended := alert.Intents[0].SessionEndsAt.In(loc).Format("Mon 2 Jan")
+
+	input := database.NotificationInput{MemberID: alert.RecipientID, Type: notify.TypeMissingOutcome}
+	switch {
+	case alert.Kind == database.OutcomeAlertEscalation:
+		input.Type = notify.TypeOutcomeDigest
+		input.Title = fmt.Sprintf("Still missing: %d outcome(s) from the %s session", len(alert.Intents), ended)
+		input.Body = "These intents still have no recorded outcome:" + list.String()
+	case authored:
+		input.Title = fmt.Sprintf("Record the outcome of your work in the %s session", ended)
+		input.Body = "No outcome has been recorded yet for your intent:" + list.String()
+	default:
+		input.Title = fmt.Sprintf("%d intent(s) from the %s session have no outcome", len(alert.Intents), ended)
+		input.Body = "No outcome has been recorded for:" + list.String()
+	}
+
+	return input
+}
@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/notify"
+)
+
+func TestPlanOutcomeChecksWaitsForCloseOrGrace(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now().UTC()
+	closed, lapsed, justEnded := uuid.New(), uuid.New(), uuid.New()
+	chapterID := uuid.New()
+
+	rows := sqlmock.NewRows(sessionRowColumns).
+		AddRow(closed, chapterID, now.Add(-3*time.Hour), now.Add(-time.Hour), database.SessionClosed, nil, now, now).
+		AddRow(lapsed, chapterID, now.Add(-7*time.Hour), now.Add(-3*time.Hour), database.SessionKickedOff, nil, nil, now).
+		AddRow(justEnded, chapterID, now.Add(-5*time.Hour), now.Add(-time.Hour), database.SessionKickedOff, nil, nil, now)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM sessions WHERE starts_at >= $1 AND starts_at <= $2")).
+		WithArgs(now.Add(-OutcomeLookback), now).
+		WillReturnRows(rows)
+	for _, id := range []uuid.UUID{closed, lapsed} {
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO jobs")).
+			WithArgs(sqlmock.AnyArg(), KindOutcomeCheck, id.String(), sqlmock.AnyArg(), database.DefaultJobAttempts, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	created, err := PlanOutcomeChecks(context.Background(), db, now)
+	if err != nil {
+		t.Fatalf("PlanOutcomeChecks returned error: %v", err)
+	}
+	if created != 2 {
+		t.Fatalf("expected 2 checks got %d", created)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestOutcomeNotification(t *testing.T) {
+	authorID, leadID := uuid.New(), uuid.New()
+	endsAt := time.Date(2026, 10, 19, 4, 0, 0, 0, time.UTC)
+	intents := []database.MissingOutcome{
+		{Statement: "Ship retry dashboard", MemberID: &authorID, SessionEndsAt: endsAt},
+		{Statement: "Spike on caching", SessionEndsAt: endsAt},
+	}
+
+	author := outcomeNotification(database.OutcomeAlert{RecipientID: authorID, Kind: database.OutcomeAlertMissing, Intents: intents[:1]}, time.UTC)
+	if author.Type != notify.TypeMissingOutcome || !strings.Contains(author.Title, "your work in the Mon 19 Oct session") {
+		t.Fatalf("unexpected author notification: %+v", author)
+	}
+
+	lead := outcomeNotification(database.OutcomeAlert{RecipientID: leadID, Kind: database.OutcomeAlertMissing, Intents: intents}, time.UTC)
+	if !strings.HasPrefix(lead.Title, "2 intent(s)") || !strings.Contains(lead.Body, "\n- Spike on caching") {
+		t.Fatalf("unexpected lead notification: %+v", lead)
+	}
+
+	digest := outcomeNotification(database.OutcomeAlert{RecipientID: leadID, Kind: database.OutcomeAlertEscalation, Intents: intents}, time.UTC)
+	if digest.Type != notify.TypeOutcomeDigest || !strings.HasPrefix(digest.Title, "Still missing") {
+		t.Fatalf("unexpected digest: %+v", digest)
+	}
+}
@@ -0,0 +1,104 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/tracker"
+)
+
+// Job kinds for work tracker sync. The planner runs every PlanInterval and
+// enqueues a sync for every linked issue whose intent is not done; a sync
+// pulls the issue's status and raises a status suggestion when it moved.
+const (
+	KindTrackerPlan = "tracker.plan"
+	KindTrackerSync = "tracker.sync"
+)
+
+// TrackerSyncPayload identifies the link a tracker sync pulls.
+type TrackerSyncPayload struct {
+	IntentID uuid.UUID `json:"intentId"`
+	LinkID   uuid.UUID `json:"linkId"`
+}
+
+// RegisterTrackerSync registers the tracker sync planner and syncs with the
+// pool.
+func RegisterTrackerSync(pool *Pool, logger *slog.Logger, db *sql.DB, t tracker.Tracker) {
+	pool.Register(KindTrackerPlan, func(ctx context.Context, job database.Job) error {
+		now := time.Now().UTC()
+		if err := EnqueuePlanner(ctx, db, KindTrackerPlan, now.Add(PlanInterval)); err != nil {
+			return err
+		}
+		_, err := PlanTrackerSyncs(ctx, db, t, now)
+		return err
+	})
+	pool.Register(KindTrackerSync, func(ctx context.Context, job database.Job) error {
+		return syncTrackerLink(ctx, logger, db, t, job)
+	})
+}
+
+// PlanTrackerSyncs enqueues a sync of every issue linked in t for the plan
+// interval containing now. It returns the number of new jobs.
+func PlanTrackerSyncs(ctx context.Context, db *sql.DB, t tracker.Tracker, now time.Time) (int, error) {
+	links, err := database.ListTrackedLinks(ctx, db, t.Name())
+	if err != nil {
+		return 0, err
+	}
+
+	interval := now.UTC().Truncate(PlanInterval).Format(time.RFC3339)
+	created := 0
+	for _, link := range links {
+		ok, err := database.EnqueueJob(ctx, db, database.JobInput{
+			Kind:    KindTrackerSync,
+			Key:     link.ID.String() + ":" + interval,
+			Payload: TrackerSyncPayload{IntentID: link.IntentID, LinkID: link.ID},
+		})
+		if err != nil {
+			return created, err
+		}
+		if ok {
+			created++
+		}
+	}
+
+	return created, nil
+}
+
+func syncTrackerLink(ctx context.Context, logger *slog.Logger, db *sql.DB, t tracker.Tracker, job database.Job) error {
+	var payload TrackerSyncPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("decode tracker sync payload: %w", err)
+	}
+
+	link, err := database.GetIntentLink(ctx, db, payload.IntentID, payload.LinkID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The link or its intent was deleted since the sync was planned.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, suggestion, err := tracker.Sync(ctx, db, t, link)
+	if errors.Is(err, tracker.ErrNotFound) {
+		logger.WarnContext(ctx, "linked issue no longer exists in tracker", "intent_id", link.IntentID, "issue", link.ExternalKey)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if suggestion != nil {
+		logger.InfoContext(ctx, "intent status suggested from tracker", "intent_id", link.IntentID, "issue", link.ExternalKey, "suggested_status", suggestion.SuggestedStatus)
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+
+	"github.com/example/intent/backend/internal/database"
+	"github.com/example/intent/backend/internal/tracker"
+)
+
+var intentLinkRowColumns = []string{"id", "intent_id", "kind", "url", "title", "tracker", "external_key", "external_status", "synced_at", "created_at"}
+
+// staticTracker reports fixed issues without calling out.
+type staticTracker map[string]tracker.Issue
+
+func (s staticTracker) Name() string { return "jira" }
+
+func (s staticTracker) CreateIssue(ctx context.Context, input tracker.IssueInput) (tracker.Issue, error) {
+	return tracker.Issue{}, nil
+}
+
+func (s staticTracker) GetIssue(ctx context.Context, key string) (tracker.Issue, error) {
+	issue, ok := s[key]
+	if !ok {
+		return tracker.Issue{}, tracker.ErrNotFound
+	}
+	return issue, nil
+}
+
+func TestPlanTrackerSyncsEnqueuesOnePerLinkPerInterval(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Date(2026, 10, 19, 9, 7, 0, 0, time.UTC)
+	first, second := uuid.New(), uuid.New()
+
+	mock.ExpectQuery("FROM intent_links l JOIN intents i").
+		WithArgs("jira").
+		WillReturnRows(sqlmock.NewRows(intentLinkRowColumns).
+			AddRow(first, uuid.New(), database.LinkIssue, "https://jira/browse/PLAT-1", "PLAT-1", "jira", "PLAT-1", "To Do", now, now).
+			AddRow(second, uuid.New(), database.LinkIssue, "https://jira/browse/PLAT-2", "PLAT-2", "jira", "PLAT-2", "To Do", nil, now))
+	for _, id := range []uuid.UUID{first, second} {
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO jobs")).
+			WithArgs(sqlmock.AnyArg(), KindTrackerSync, id.String()+":2026-10-19T09:05:00Z", sqlmock.AnyArg(), database.DefaultJobAttempts, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	created, err := PlanTrackerSyncs(context.Background(), db, staticTracker{}, now)
+	if err != nil {
+		t.Fatalf("PlanTrackerSyncs returned error: %v", err)
+	}
+	if created != 2 {
+		t.Fatalf("expected 2 syncs got %d", created)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestSyncTrackerLinkSuggestsDone(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now().UTC()
+	intentID, linkID := uuid.New(), uuid.New()
+	issues := staticTracker{"PLAT-1": {Key: "PLAT-1", Status: "Closed", Category: tracker.CategoryDone}}
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM intent_links WHERE id = $1 AND intent_id = $2")).
+		WithArgs(linkID, intentID).
+		WillReturnRows(sqlmock.NewRows(intentLinkRowColumns).
+			AddRow(linkID, intentID, database.LinkIssue, "https://jira/browse/PLAT-1", "PLAT-1", "jira", "PLAT-1", "In Progress", now, now))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT l.external_status, i.status FROM intent_links l").
+		WithArgs(linkID).
+		WillReturnRows(sqlmock.NewRows([]string{"external_status", "status"}).AddRow("In Progress", database.IntentActive))
+	mock.ExpectQuery("UPDATE intent_links SET external_status").
+		WithArgs(linkID, "Closed", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(intentLinkRowColumns).
+			AddRow(linkID, intentID, database.LinkIssue, "https://jira/browse/PLAT-1", "PLAT-1", "jira", "PLAT-1", "Closed", now, now))
+	mock.ExpectExec("UPDATE intent_status_suggestions SET resolved_at").
+		WithArgs(intentID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO intent_status_suggestions").
+		WithArgs(sqlmock.AnyArg(), intentID, linkID, database.IntentDone, "PLAT-1 moved to Closed", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	payload, _ := json.Marshal(TrackerSyncPayload{IntentID: intentID, LinkID: linkID})
+	job := database.Job{ID: uuid.New(), Kind: KindTrackerSync, Payload: payload, Attempts: 1}
+
+	if err := syncTrackerLink(context.Background(), discardLogger(), db, issues, job); err != nil {
+		t.Fatalf("syncTrackerLink returned error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
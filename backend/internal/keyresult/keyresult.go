@@ -0,0 +1,139 @@
+// Package keyresult evaluates measurable success criteria: how far the
+// latest measurement has moved from a baseline towards a target, and the
+// health of a goal across its criteria.
+package keyresult
+
+import (
+	"errors"
+	"math"
+)
+
+// Directions a metric can move towards its target.
+const (
+	Increase = "increase"
+	Decrease = "decrease"
+)
+
+// Statuses of a criterion and, apart from StatusNoMetric and
+// StatusUnmeasured, of a goal's overall health.
+const (
+	StatusNoMetric   = "no_metric"
+	StatusUnmeasured = "unmeasured"
+	StatusUnknown    = "unknown"
+	StatusOffTrack   = "off_track"
+	StatusAtRisk     = "at_risk"
+	StatusOnTrack    = "on_track"
+	StatusMet        = "met"
+)
+
+// Metric makes a success criterion measurable: it is met once measurements
+// move from Baseline to Target in Direction.
+type Metric struct {
+	Baseline  float64
+	Target    float64
+	Unit      string
+	Direction string
+}
+
+// Progress is the evaluation of one criterion. Ratio is the share of the way
+// from baseline to target covered by the latest measurement, between 0 and
+// 1; it is nil until the criterion has a metric and a measurement.
+type Progress struct {
+	Ratio  *float64
+	Status string
+}
+
+// Validate checks that the direction is known and that the target lies
+// beyond the baseline in that direction.
+func (m Metric) Validate() error {
+	if isInvalid(m.Baseline) || isInvalid(m.Target) {
+		return errors.New("baseline and target must be finite numbers")
+	}
+
+	switch m.Direction {
+	case Increase:
+		if m.Target <= m.Baseline {
+			return errors.New("target must be above the baseline for an increasing metric")
+		}
+	case Decrease:
+		if m.Target >= m.Baseline {
+			return errors.New("target must be below the baseline for a decreasing metric")
+		}
+	default:
+		return errors.New("direction must be increase or decrease")
+	}
+
+	return nil
+}
+
+// Evaluate rates a criterion from its metric, its latest measurement and the
+// one before it. A criterion is met once the latest measurement reaches the
+// target, off track while it is worse than the baseline and at risk when it
+// moved away from the target since the previous measurement.
+func Evaluate(metric *Metric, latest, previous *float64) Progress {
+	if metric == nil {
+		return Progress{Status: StatusNoMetric}
+	}
+	if latest == nil {
+		return Progress{Status: StatusUnmeasured}
+	}
+
+	raw := (*latest - metric.Baseline) / (metric.Target - metric.Baseline)
+	ratio := math.Max(0, math.Min(1, raw))
+
+	status := StatusOnTrack
+	switch {
+	case raw >= 1:
+		status = StatusMet
+	case raw < 0:
+		status = StatusOffTrack
+	case previous != nil && regressed(metric.Direction, *previous, *latest):
+		status = StatusAtRisk
+	}
+
+	return Progress{Ratio: &ratio, Status: status}
+}
+
+// Health summarises criterion statuses into a goal's health: the worst status
+// among measured criteria, met only when all of them are, and unknown when
+// none has been measured.
+func Health(statuses []string) string {
+	measured, met := 0, 0
+	worst := StatusOnTrack
+	for _, status := range statuses {
+		switch status {
+		case StatusOffTrack:
+			worst = StatusOffTrack
+		case StatusAtRisk:
+			if worst != StatusOffTrack {
+				worst = StatusAtRisk
+			}
+		case StatusMet:
+			met++
+		case StatusOnTrack:
+		default:
+			continue
+		}
+		measured++
+	}
+
+	switch {
+	case measured == 0:
+		return StatusUnknown
+	case met == measured:
+		return StatusMet
+	default:
+		return worst
+	}
+}
+
+func regressed(direction string, previous, latest float64) bool {
+	if direction == Decrease {
+		return latest > previous
+	}
+	return latest < previous
+}
+
+func isInvalid(value float64) bool {
+	return math.IsNaN(value) || math.IsInf(value, 0)
+}
@@ -0,0 +1,79 @@
+package keyresult
+
+import "testing"
+
+func float(value float64) *float64 {
+	return &value
+}
+
+func TestMetricValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		metric Metric
+		valid  bool
+	}{
+		{name: "increase", metric: Metric{Baseline: 60, Target: 90, Direction: Increase}, valid: true},
+		{name: "decrease", metric: Metric{Baseline: 5, Target: 1, Direction: Decrease}, valid: true},
+		{name: "increase below baseline", metric: Metric{Baseline: 60, Target: 40, Direction: Increase}},
+		{name: "decrease to baseline", metric: Metric{Baseline: 5, Target: 5, Direction: Decrease}},
+		{name: "unknown direction", metric: Metric{Baseline: 0, Target: 1, Direction: "sideways"}},
+	} {
+		if err := tc.metric.Validate(); (err == nil) != tc.valid {
+			t.Fatalf("%s: expected valid=%v got %v", tc.name, tc.valid, err)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	coverage := &Metric{Baseline: 60, Target: 80, Direction: Increase}
+	errorRate := &Metric{Baseline: 4, Target: 1, Direction: Decrease}
+
+	for _, tc := range []struct {
+		name     string
+		metric   *Metric
+		latest   *float64
+		previous *float64
+		ratio    float64
+		status   string
+	}{
+		{name: "halfway", metric: coverage, latest: float(70), ratio: 0.5, status: StatusOnTrack},
+		{name: "past target", metric: coverage, latest: float(85), ratio: 1, status: StatusMet},
+		{name: "below baseline", metric: coverage, latest: float(55), ratio: 0, status: StatusOffTrack},
+		{name: "slipping", metric: coverage, latest: float(70), previous: float(75), ratio: 0.5, status: StatusAtRisk},
+		{name: "decreasing", metric: errorRate, latest: float(2), previous: float(3), ratio: 2.0 / 3, status: StatusOnTrack},
+		{name: "decreasing slipping", metric: errorRate, latest: float(3), previous: float(2), ratio: 1.0 / 3, status: StatusAtRisk},
+	} {
+		progress := Evaluate(tc.metric, tc.latest, tc.previous)
+		if progress.Status != tc.status {
+			t.Fatalf("%s: expected status %s got %s", tc.name, tc.status, progress.Status)
+		}
+		if progress.Ratio == nil || *progress.Ratio != tc.ratio {
+			t.Fatalf("%s: expected ratio %v got %v", tc.name, tc.ratio, progress.Ratio)
+		}
+	}
+
+	if progress := Evaluate(nil, float(1), nil); progress.Status != StatusNoMetric || progress.Ratio != nil {
+		t.Fatalf("expected no metric got %+v", progress)
+	}
+	if progress := Evaluate(coverage, nil, nil); progress.Status != StatusUnmeasured || progress.Ratio != nil {
+		t.Fatalf("expected unmeasured got %+v", progress)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	for _, tc := range []struct {
+		statuses []string
+		want     string
+	}{
+		{statuses: nil, want: StatusUnknown},
+		{statuses: []string{StatusNoMetric, StatusUnmeasured}, want: StatusUnknown},
+		{statuses: []string{StatusMet, StatusMet, StatusNoMetric}, want: StatusMet},
+		{statuses: []string{StatusMet, StatusOnTrack}, want: StatusOnTrack},
+		{statuses: []string{StatusOnTrack, StatusAtRisk, StatusMet}, want: StatusAtRisk},
+		{statuses: []string{StatusOffTrack, StatusAtRisk}, want: StatusOffTrack},
+	} {
+		if got := Health(tc.statuses); got != tc.want {
+			t.Fatalf("%v: expected %s got %s", tc.statuses, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Package messaging provisions chat channels for swarms: a channel is
+// created when a swarm forms, its members are invited and its charter is
+// pinned, and the channel is archived when the swarm dissolves.
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/example/intent/backend/internal/database"
+)
+
+// Channel is a channel as the chat provider reports it.
+type Channel struct {
+	ID   string
+	Name string
+	URL  string
+}
+
+// Messenger manages channels in a chat provider. Every method must tolerate
+// being repeated after a partial failure: inviting a member already in the
+// channel or archiving an archived channel succeeds.
+type Messenger interface {
+	Name() string
+	CreateChannel(ctx context.Context, name string) (Channel, error)
+	InviteMembers(ctx context.Context, channelID string, emails []string) error
+	PinMessage(ctx context.Context, channelID, text string) error
+	ArchiveChannel(ctx context.Context, channelID string) error
+}
+
+// maxChannelName is the longest channel name Slack accepts.
+const maxChannelName = 80
+
+// ChannelName derives a channel name for swarm: lower-case letters, digits
+// and dashes, prefixed with "swarm-" and suffixed with the start of the
+// swarm's id so swarms sharing a name get distinct channels.
+func ChannelName(swarm database.Swarm) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(swarm.Name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	suffix := "-" + swarm.ID.String()[:8]
+	slug := strings.TrimRight(b.String(), "-")
+	if limit := maxChannelName - len("swarm-") - len(suffix); len(slug) > limit {
+		slug = strings.TrimRight(slug[:limit], "-")
+	}
+	if slug == "" {
+		return "swarm" + suffix
+	}
+	return "swarm-" + slug + suffix
+}
+
+// Charter is the message pinned in a swarm's channel.
+func Charter(swarm database.Swarm) string {
+	return fmt.Sprintf("*Swarm charter: %s*\nMission: %s\nTimebox: %s to %s UTC",
+		swarm.Name,
+		swarm.Mission,
+		swarm.StartsAt.UTC().Format("Mon 2 Jan 15:04"),
+		swarm.EndsAt.UTC().Format("15:04"),
+	)
+}
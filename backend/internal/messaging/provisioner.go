@@ -0,0 +1,255 @@
+package messaging
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/example/intent/backend/internal/database"
+)
+
+// Job kinds handled by the Provisioner.
+const (
+	KindProvision = database.SwarmChannelProvisionJob
+	KindInvite    = database.SwarmChannelInviteJob
+	KindArchive   = database.SwarmChannelArchiveJob
+)
+
+// Provisioner runs the swarm channel jobs against a Messenger. Each step is
+// recorded as it completes, so a failed job retried by the queue picks up
+// where it stopped, and the last error is kept on the swarm's channel.
+type Provisioner struct {
+	logger    *slog.Logger
+	db        *sql.DB
+	messenger Messenger
+}
+
+// NewProvisioner constructs a provisioner managing channels in messenger.
+func NewProvisioner(logger *slog.Logger, db *sql.DB, messenger Messenger) *Provisioner {
+	return &Provisioner{logger: logger, db: db, messenger: messenger}
+}
+
+// Provision creates the swarm's channel, invites its members and pins its
+// charter. It is the handler for KindProvision jobs.
+func (p *Provisioner) Provision(ctx context.Context, job database.Job) error {
+	payload, err := decodePayload(job)
+	if err != nil {
+		return err
+	}
+
+	swarm, err := database.GetSwarm(ctx, p.db, payload.SessionID, payload.SwarmID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	channel, err := database.GetSwarmChannel(ctx, p.db, swarm.ID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		channel = database.SwarmChannel{
+			SwarmID:  swarm.ID,
+			Provider: p.messenger.Name(),
+			Name:     ChannelName(swarm),
+			Status:   database.ChannelProvisioning,
+		}
+	case err != nil:
+		return err
+	case channel.Status != database.ChannelProvisioning && channel.Status != database.ChannelFailed:
+		return nil
+	}
+
+	// A dissolved swarm is never provisioned. A swarm dissolved before its
+	// channel was created never gets one; a channel left behind by an earlier
+	// attempt is archived.
+	if swarm.Status == database.SwarmDissolved {
+		if channel.ChannelID == "" {
+			return nil
+		}
+		return p.archive(ctx, channel)
+	}
+
+	channel.Attempts = job.Attempts
+	if err := p.provision(ctx, swarm, &channel); err != nil {
+		channel.LastError = err.Error()
+		if job.Attempts >= job.MaxAttempts {
+			channel.Status = database.ChannelFailed
+		}
+		if saveErr := database.SaveSwarmChannel(ctx, p.db, channel); saveErr != nil {
+			p.logger.ErrorContext(ctx, "failed to record swarm channel error", "swarm_id", swarm.ID, "error", saveErr)
+		}
+		return err
+	}
+
+	channel.Status = database.ChannelActive
+	channel.LastError = ""
+	if err := database.SaveSwarmChannel(ctx, p.db, channel); err != nil {
+		return err
+	}
+
+	p.logger.InfoContext(ctx, "swarm channel provisioned", "swarm_id", swarm.ID, "channel", channel.Name)
+
+	// An archive job that ran while the channel was being created found
+	// nothing to archive, so check again now that the channel is recorded.
+	current, err := database.GetSwarm(ctx, p.db, payload.SessionID, payload.SwarmID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if current.Status == database.SwarmDissolved {
+		return p.archive(ctx, channel)
+	}
+
+	return nil
+}
+
+func (p *Provisioner) provision(ctx context.Context, swarm database.Swarm, channel *database.SwarmChannel) error {
+	if channel.ChannelID == "" {
+		created, err := p.messenger.CreateChannel(ctx, channel.Name)
+		if err != nil {
+			return fmt.Errorf("create channel: %w", err)
+		}
+		channel.ChannelID, channel.Name, channel.URL = created.ID, created.Name, created.URL
+
+		// Keep the channel id even if a later step fails, so a retry does
+		// not create a second channel.
+		if err := database.SaveSwarmChannel(ctx, p.db, *channel); err != nil {
+			return err
+		}
+	}
+
+	emails, err := p.memberEmails(ctx, swarm)
+	if err != nil {
+		return err
+	}
+
+	if err := p.messenger.InviteMembers(ctx, channel.ChannelID, emails); err != nil {
+		return fmt.Errorf("invite members: %w", err)
+	}
+
+	if !channel.Pinned {
+		if err := p.messenger.PinMessage(ctx, channel.ChannelID, Charter(swarm)); err != nil {
+			return fmt.Errorf("pin charter: %w", err)
+		}
+		channel.Pinned = true
+	}
+
+	return nil
+}
+
+// Invite adds a member who joined the swarm to its channel. Members who join
+// before the channel exists are invited when it is provisioned. It is the
+// handler for KindInvite jobs.
+func (p *Provisioner) Invite(ctx context.Context, job database.Job) error {
+	payload, err := decodePayload(job)
+	if err != nil {
+		return err
+	}
+
+	if payload.MemberID == nil {
+		return errors.New("invite job has no member")
+	}
+
+	channel, err := database.GetSwarmChannel(ctx, p.db, payload.SwarmID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if channel.Status != database.ChannelActive {
+		return nil
+	}
+
+	member, err := database.GetMember(ctx, p.db, *payload.MemberID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := p.messenger.InviteMembers(ctx, channel.ChannelID, []string{member.Email}); err != nil {
+		return p.recordError(ctx, channel, fmt.Errorf("invite member: %w", err))
+	}
+
+	return nil
+}
+
+// Archive archives the channel of a dissolved swarm. It is the handler for
+// KindArchive jobs.
+func (p *Provisioner) Archive(ctx context.Context, job database.Job) error {
+	payload, err := decodePayload(job)
+	if err != nil {
+		return err
+	}
+
+	channel, err := database.GetSwarmChannel(ctx, p.db, payload.SwarmID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return p.archive(ctx, channel)
+}
+
+func (p *Provisioner) archive(ctx context.Context, channel database.SwarmChannel) error {
+	if channel.Status == database.ChannelArchived {
+		return nil
+	}
+
+	if channel.ChannelID != "" {
+		if err := p.messenger.ArchiveChannel(ctx, channel.ChannelID); err != nil {
+			return p.recordError(ctx, channel, fmt.Errorf("archive channel: %w", err))
+		}
+	}
+
+	channel.Status = database.ChannelArchived
+	channel.LastError = ""
+	return database.SaveSwarmChannel(ctx, p.db, channel)
+}
+
+// recordError keeps err on the channel so it shows on the swarm, and returns
+// it so the job is retried.
+func (p *Provisioner) recordError(ctx context.Context, channel database.SwarmChannel, err error) error {
+	channel.LastError = err.Error()
+	if saveErr := database.SaveSwarmChannel(ctx, p.db, channel); saveErr != nil {
+		p.logger.ErrorContext(ctx, "failed to record swarm channel error", "swarm_id", channel.SwarmID, "error", saveErr)
+	}
+	return err
+}
+
+func (p *Provisioner) memberEmails(ctx context.Context, swarm database.Swarm) ([]string, error) {
+	emails := make([]string, 0, len(swarm.MemberIDs))
+	for _, memberID := range swarm.MemberIDs {
+		member, err := database.GetMember(ctx, p.db, memberID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if member.Email != "" {
+			emails = append(emails, member.Email)
+		}
+	}
+	return emails, nil
+}
+
+func decodePayload(job database.Job) (database.SwarmChannelPayload, error) {
+	var payload database.SwarmChannelPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return database.SwarmChannelPayload{}, fmt.Errorf("decode swarm channel payload: %w", err)
+	}
+	return payload, nil
+}
@@ -0,0 +1,255 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+
+	"github.com/example/intent/backend/internal/database"
+)
+
+var swarmChannelRowColumns = []string{"swarm_id", "provider", "channel_id", "name", "url", "status", "pinned", "last_error", "attempts", "created_at", "updated_at"}
+
+// stubMessenger records calls and fails pins with pinErr.
+type stubMessenger struct {
+	created  []string
+	invited  []string
+	archived []string
+	pinErr   error
+}
+
+func (s *stubMessenger) Name() string { return "slack" }
+
+func (s *stubMessenger) CreateChannel(ctx context.Context, name string) (Channel, error) {
+	s.created = append(s.created, name)
+	return Channel{ID: "C0001", Name: name, URL: "https://slack.com/app_redirect?channel=C0001"}, nil
+}
+
+func (s *stubMessenger) InviteMembers(ctx context.Context, channelID string, emails []string) error {
+	s.invited = append(s.invited, emails...)
+	return nil
+}
+
+func (s *stubMessenger) PinMessage(ctx context.Context, channelID, text string) error {
+	return s.pinErr
+}
+
+func (s *stubMessenger) ArchiveChannel(ctx context.Context, channelID string) error {
+	s.archived = append(s.archived, channelID)
+	return nil
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func channelJob(t *testing.T, kind string, sessionID, swarmID uuid.UUID, attempts int) database.Job {
+	t.Helper()
+
+	payload, err := json.Marshal(database.SwarmChannelPayload{SessionID: sessionID, SwarmID: swarmID})
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+
+	return database.Job{ID: uuid.New(), Kind: kind, Payload: payload, Attempts: attempts, MaxAttempts: database.DefaultJobAttempts}
+}
+
+func expectSwarmLookup(mock sqlmock.Sqlmock, sessionID, swarmID uuid.UUID, status string, now time.Time) {
+	mock.ExpectQuery("FROM swarms").
+		WithArgs(swarmID, sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "session_id", "name", "mission", "status", "starts_at", "ends_at", "created_at", "updated_at"}).
+			AddRow(swarmID, sessionID, "Checkout fixes", "Unblock release", status, now, now.Add(time.Hour), now, now))
+	mock.ExpectQuery("FROM swarm_members").
+		WillReturnRows(sqlmock.NewRows([]string{"swarm_id", "member_id"}))
+}
+
+func TestProvisionRecordsFailureAndKeepsChannel(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now().UTC()
+	sessionID, swarmID, memberID := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectQuery("FROM swarms").
+		WithArgs(swarmID, sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "session_id", "name", "mission", "status", "starts_at", "ends_at", "created_at", "updated_at"}).
+			AddRow(swarmID, sessionID, "Checkout fixes", "Unblock release", database.SwarmActive, now, now.Add(time.Hour), now, now))
+	mock.ExpectQuery("FROM swarm_members").
+		WillReturnRows(sqlmock.NewRows([]string{"swarm_id", "member_id"}).AddRow(swarmID, memberID))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM swarm_channels WHERE swarm_id = $1")).
+		WithArgs(swarmID).
+		WillReturnRows(sqlmock.NewRows(swarmChannelRowColumns))
+	mock.ExpectExec("INSERT INTO swarm_channels").
+		WithArgs(swarmID, "slack", "C0001", sqlmock.AnyArg(), sqlmock.AnyArg(), database.ChannelProvisioning, false, "", 2, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("FROM members").
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "chapter_id", "display_name", "email", "role", "created_at"}).
+			AddRow(memberID, uuid.New(), "Ana", "ana@example.com", "member", now))
+	mock.ExpectExec("INSERT INTO swarm_channels").
+		WithArgs(swarmID, "slack", "C0001", sqlmock.AnyArg(), sqlmock.AnyArg(), database.ChannelProvisioning, false, "pin charter: ratelimited", 2, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	messenger := &stubMessenger{pinErr: errors.New("ratelimited")}
+	err = NewProvisioner(discardLogger(), db, messenger).Provision(context.Background(), channelJob(t, KindProvision, sessionID, swarmID, 2))
+	if err == nil {
+		t.Fatal("expected the failed pin to fail the job so it is retried")
+	}
+
+	if len(messenger.created) != 1 || len(messenger.invited) != 1 || messenger.invited[0] != "ana@example.com" {
+		t.Fatalf("unexpected calls %+v", messenger)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestProvisionResumesWithoutRecreatingChannel(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now().UTC()
+	sessionID, swarmID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery("FROM swarms").
+		WithArgs(swarmID, sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "session_id", "name", "mission", "status", "starts_at", "ends_at", "created_at", "updated_at"}).
+			AddRow(swarmID, sessionID, "Checkout fixes", "Unblock release", database.SwarmActive, now, now.Add(time.Hour), now, now))
+	mock.ExpectQuery("FROM swarm_members").
+		WillReturnRows(sqlmock.NewRows([]string{"swarm_id", "member_id"}))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM swarm_channels WHERE swarm_id = $1")).
+		WithArgs(swarmID).
+		WillReturnRows(sqlmock.NewRows(swarmChannelRowColumns).
+			AddRow(swarmID, "slack", "C0001", "swarm-checkout-fixes", "https://slack.com/app_redirect?channel=C0001", database.ChannelProvisioning, false, "pin charter: ratelimited", 2, now, now))
+	mock.ExpectExec("INSERT INTO swarm_channels").
+		WithArgs(swarmID, "slack", "C0001", "swarm-checkout-fixes", sqlmock.AnyArg(), database.ChannelActive, true, "", 3, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectSwarmLookup(mock, sessionID, swarmID, database.SwarmActive, now)
+
+	messenger := &stubMessenger{}
+	if err := NewProvisioner(discardLogger(), db, messenger).Provision(context.Background(), channelJob(t, KindProvision, sessionID, swarmID, 3)); err != nil {
+		t.Fatalf("Provision returned error: %v", err)
+	}
+
+	if len(messenger.created) != 0 {
+		t.Fatalf("expected the existing channel to be reused, created %v", messenger.created)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestProvisionArchivesChannelOfSwarmDissolvedMeanwhile(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now().UTC()
+	sessionID, swarmID := uuid.New(), uuid.New()
+
+	expectSwarmLookup(mock, sessionID, swarmID, database.SwarmActive, now)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM swarm_channels WHERE swarm_id = $1")).
+		WithArgs(swarmID).
+		WillReturnRows(sqlmock.NewRows(swarmChannelRowColumns))
+	mock.ExpectExec("INSERT INTO swarm_channels").
+		WithArgs(swarmID, "slack", "C0001", sqlmock.AnyArg(), sqlmock.AnyArg(), database.ChannelProvisioning, false, "", 1, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO swarm_channels").
+		WithArgs(swarmID, "slack", "C0001", sqlmock.AnyArg(), sqlmock.AnyArg(), database.ChannelActive, true, "", 1, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// The archive job ran before the channel was recorded and found nothing.
+	expectSwarmLookup(mock, sessionID, swarmID, database.SwarmDissolved, now)
+	mock.ExpectExec("INSERT INTO swarm_channels").
+		WithArgs(swarmID, "slack", "C0001", sqlmock.AnyArg(), sqlmock.AnyArg(), database.ChannelArchived, true, "", 1, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	messenger := &stubMessenger{}
+	if err := NewProvisioner(discardLogger(), db, messenger).Provision(context.Background(), channelJob(t, KindProvision, sessionID, swarmID, 1)); err != nil {
+		t.Fatalf("Provision returned error: %v", err)
+	}
+
+	if len(messenger.archived) != 1 || messenger.archived[0] != "C0001" {
+		t.Fatalf("expected the new channel to be archived got %v", messenger.archived)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestProvisionArchivesFailedChannelOfDissolvedSwarm(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	now := time.Now().UTC()
+	sessionID, swarmID := uuid.New(), uuid.New()
+
+	expectSwarmLookup(mock, sessionID, swarmID, database.SwarmDissolved, now)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM swarm_channels WHERE swarm_id = $1")).
+		WithArgs(swarmID).
+		WillReturnRows(sqlmock.NewRows(swarmChannelRowColumns).
+			AddRow(swarmID, "slack", "C0001", "swarm-checkout-fixes", "https://slack.com/app_redirect?channel=C0001", database.ChannelFailed, false, "pin charter: ratelimited", 5, now, now))
+	mock.ExpectExec("INSERT INTO swarm_channels").
+		WithArgs(swarmID, "slack", "C0001", "swarm-checkout-fixes", sqlmock.AnyArg(), database.ChannelArchived, false, "", 5, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	messenger := &stubMessenger{}
+	if err := NewProvisioner(discardLogger(), db, messenger).Provision(context.Background(), channelJob(t, KindProvision, sessionID, swarmID, 1)); err != nil {
+		t.Fatalf("Provision returned error: %v", err)
+	}
+
+	if len(messenger.invited) != 0 || len(messenger.archived) != 1 {
+		t.Fatalf("expected the channel to be archived without retrying got %+v", messenger)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestArchiveSkipsSwarmsWithoutChannel(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	swarmID := uuid.New()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM swarm_channels WHERE swarm_id = $1")).
+		WithArgs(swarmID).
+		WillReturnRows(sqlmock.NewRows(swarmChannelRowColumns))
+
+	messenger := &stubMessenger{}
+	if err := NewProvisioner(discardLogger(), db, messenger).Archive(context.Background(), channelJob(t, KindArchive, uuid.New(), swarmID, 1)); err != nil {
+		t.Fatalf("Archive returned error: %v", err)
+	}
+
+	if len(messenger.archived) != 0 {
+		t.Fatalf("unexpected archive %v", messenger.archived)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
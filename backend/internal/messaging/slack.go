@@ -0,0 +1,158 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultClient is used to call the chat provider's API.
+var DefaultClient = &http.Client{Timeout: 15 * time.Second}
+
+// SlackAPIURL is the base URL of the Slack Web API.
+const SlackAPIURL = "https://slack.com/api"
+
+// Slack manages channels through the Slack Web API with a bot token holding
+// the channels:manage, channels:write.invites, chat:write, pins:write and
+// users:read.email scopes.
+type Slack struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewSlack constructs a Slack messenger calling the Web API at baseURL.
+func NewSlack(baseURL, token string, client *http.Client) *Slack {
+	return &Slack{baseURL: strings.TrimRight(baseURL, "/"), token: token, client: client}
+}
+
+// Name implements Messenger.
+func (s *Slack) Name() string { return "slack" }
+
+// SlackError is an error reported by the Slack Web API, such as name_taken.
+type SlackError struct {
+	Method string
+	Code   string
+}
+
+func (e *SlackError) Error() string {
+	return fmt.Sprintf("slack %s failed: %s", e.Method, e.Code)
+}
+
+type slackResponse struct {
+	OK      bool   `json:"ok"`
+	Error   string `json:"error"`
+	TS      string `json:"ts"`
+	Channel struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"channel"`
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+}
+
+// CreateChannel implements Messenger.
+func (s *Slack) CreateChannel(ctx context.Context, name string) (Channel, error) {
+	resp, err := s.call(ctx, "conversations.create", url.Values{"name": {name}, "is_private": {"false"}})
+	if err != nil {
+		return Channel{}, err
+	}
+
+	return Channel{
+		ID:   resp.Channel.ID,
+		Name: resp.Channel.Name,
+		URL:  "https://slack.com/app_redirect?channel=" + url.QueryEscape(resp.Channel.ID),
+	}, nil
+}
+
+// InviteMembers implements Messenger. Emails without a Slack account are
+// skipped.
+func (s *Slack) InviteMembers(ctx context.Context, channelID string, emails []string) error {
+	users := make([]string, 0, len(emails))
+	for _, email := range emails {
+		resp, err := s.call(ctx, "users.lookupByEmail", url.Values{"email": {email}})
+		if isSlackError(err, "users_not_found") {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		users = append(users, resp.User.ID)
+	}
+
+	if len(users) == 0 {
+		return nil
+	}
+
+	_, err := s.call(ctx, "conversations.invite", url.Values{"channel": {channelID}, "users": {strings.Join(users, ",")}, "force": {"true"}})
+	if isSlackError(err, "already_in_channel") {
+		return nil
+	}
+	return err
+}
+
+// PinMessage implements Messenger.
+func (s *Slack) PinMessage(ctx context.Context, channelID, text string) error {
+	resp, err := s.call(ctx, "chat.postMessage", url.Values{"channel": {channelID}, "text": {text}})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.call(ctx, "pins.add", url.Values{"channel": {channelID}, "timestamp": {resp.TS}})
+	if isSlackError(err, "already_pinned") {
+		return nil
+	}
+	return err
+}
+
+// ArchiveChannel implements Messenger.
+func (s *Slack) ArchiveChannel(ctx context.Context, channelID string) error {
+	_, err := s.call(ctx, "conversations.archive", url.Values{"channel": {channelID}})
+	if isSlackError(err, "already_archived") {
+		return nil
+	}
+	return err
+}
+
+func (s *Slack) call(ctx context.Context, method string, params url.Values) (slackResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/"+method, strings.NewReader(params.Encode()))
+	if err != nil {
+		return slackResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+s.token)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return slackResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return slackResponse{}, fmt.Errorf("slack %s responded %s", method, resp.Status)
+	}
+
+	var decoded slackResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return slackResponse{}, fmt.Errorf("slack %s: %w", method, err)
+	}
+
+	if !decoded.OK {
+		return slackResponse{}, &SlackError{Method: method, Code: decoded.Error}
+	}
+
+	return decoded, nil
+}
+
+func isSlackError(err error, code string) bool {
+	var slackErr *SlackError
+	return errors.As(err, &slackErr) && slackErr.Code == code
+}
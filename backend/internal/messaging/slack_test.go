@@ -0,0 +1,211 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/example/intent/backend/internal/database"
+)
+
+// fakeSlack is an in-process stand-in for the Slack Web API methods the
+// messenger calls.
+type fakeSlack struct {
+	mu       sync.Mutex
+	users    map[string]string
+	channels map[string]*fakeChannel
+	auth     []string
+}
+
+type fakeChannel struct {
+	name     string
+	members  []string
+	pinned   []string
+	archived bool
+}
+
+func newFakeSlack(t *testing.T) (*fakeSlack, *httptest.Server) {
+	t.Helper()
+
+	fake := &fakeSlack{
+		users:    map[string]string{"ana@example.com": "U01ANA", "jamie@example.com": "U02JAMIE"},
+		channels: map[string]*fakeChannel{},
+	}
+	server := httptest.NewServer(http.HandlerFunc(fake.serve))
+	t.Cleanup(server.Close)
+
+	return fake, server
+}
+
+func (f *fakeSlack) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reply := func(fields map[string]any) {
+		if _, ok := fields["ok"]; !ok {
+			fields["ok"] = true
+		}
+		_ = json.NewEncoder(w).Encode(fields)
+	}
+	fail := func(code string) { reply(map[string]any{"ok": false, "error": code}) }
+
+	channel := f.channels[r.PostForm.Get("channel")]
+	switch strings.TrimPrefix(r.URL.Path, "/") {
+	case "conversations.create":
+		for _, existing := range f.channels {
+			if existing.name == r.PostForm.Get("name") {
+				fail("name_taken")
+				return
+			}
+		}
+		id := fmt.Sprintf("C%04d", len(f.channels)+1)
+		f.channels[id] = &fakeChannel{name: r.PostForm.Get("name")}
+		reply(map[string]any{"channel": map[string]string{"id": id, "name": r.PostForm.Get("name")}})
+	case "users.lookupByEmail":
+		id, ok := f.users[r.PostForm.Get("email")]
+		if !ok {
+			fail("users_not_found")
+			return
+		}
+		reply(map[string]any{"user": map[string]string{"id": id}})
+	case "conversations.invite":
+		if channel == nil {
+			fail("channel_not_found")
+			return
+		}
+		for _, user := range strings.Split(r.PostForm.Get("users"), ",") {
+			for _, member := range channel.members {
+				if member == user {
+					fail("already_in_channel")
+					return
+				}
+			}
+			channel.members = append(channel.members, user)
+		}
+		reply(map[string]any{})
+	case "chat.postMessage":
+		if channel == nil {
+			fail("channel_not_found")
+			return
+		}
+		reply(map[string]any{"ts": "1700000000.000100", "text": r.PostForm.Get("text")})
+	case "pins.add":
+		if channel == nil {
+			fail("channel_not_found")
+			return
+		}
+		channel.pinned = append(channel.pinned, r.PostForm.Get("timestamp"))
+		reply(map[string]any{})
+	case "conversations.archive":
+		if channel == nil {
+			fail("channel_not_found")
+			return
+		}
+		if channel.archived {
+			fail("already_archived")
+			return
+		}
+		channel.archived = true
+		reply(map[string]any{})
+	default:
+		fail("unknown_method")
+	}
+}
+
+func TestSlackChannelLifecycle(t *testing.T) {
+	fake, server := newFakeSlack(t)
+	slack := NewSlack(server.URL+"/", "xoxb-test", server.Client())
+	ctx := context.Background()
+
+	channel, err := slack.CreateChannel(ctx, "swarm-checkout-fixes-1a2b3c4d")
+	if err != nil {
+		t.Fatalf("CreateChannel returned error: %v", err)
+	}
+
+	if channel.ID != "C0001" || channel.URL != "https://slack.com/app_redirect?channel=C0001" {
+		t.Fatalf("unexpected channel %+v", channel)
+	}
+
+	if err := slack.InviteMembers(ctx, channel.ID, []string{"ana@example.com", "nobody@example.com"}); err != nil {
+		t.Fatalf("InviteMembers returned error: %v", err)
+	}
+
+	// Repeating an invite, as a retried job does, is not an error.
+	if err := slack.InviteMembers(ctx, channel.ID, []string{"ana@example.com"}); err != nil {
+		t.Fatalf("repeated InviteMembers returned error: %v", err)
+	}
+
+	if err := slack.PinMessage(ctx, channel.ID, "charter"); err != nil {
+		t.Fatalf("PinMessage returned error: %v", err)
+	}
+
+	if err := slack.ArchiveChannel(ctx, channel.ID); err != nil {
+		t.Fatalf("ArchiveChannel returned error: %v", err)
+	}
+
+	if err := slack.ArchiveChannel(ctx, channel.ID); err != nil {
+		t.Fatalf("repeated ArchiveChannel returned error: %v", err)
+	}
+
+	got := fake.channels["C0001"]
+	if len(got.members) != 1 || got.members[0] != "U01ANA" || len(got.pinned) != 1 || !got.archived {
+		t.Fatalf("unexpected channel state %+v", got)
+	}
+
+	for _, auth := range fake.auth {
+		if auth != "Bearer xoxb-test" {
+			t.Fatalf("unexpected authorization %q", auth)
+		}
+	}
+}
+
+func TestSlackReportsAPIErrors(t *testing.T) {
+	_, server := newFakeSlack(t)
+	slack := NewSlack(server.URL, "xoxb-test", server.Client())
+
+	if _, err := slack.CreateChannel(context.Background(), "taken"); err != nil {
+		t.Fatalf("CreateChannel returned error: %v", err)
+	}
+
+	_, err := slack.CreateChannel(context.Background(), "taken")
+	if !isSlackError(err, "name_taken") {
+		t.Fatalf("expected name_taken got %v", err)
+	}
+}
+
+func TestChannelNameAndCharter(t *testing.T) {
+	swarm := database.Swarm{
+		ID:       uuid.MustParse("1a2b3c4d-0000-4000-8000-000000000000"),
+		Name:     "Checkout fixes: Q3 / EU!",
+		Mission:  "Unblock the release",
+		StartsAt: time.Date(2026, 10, 19, 13, 0, 0, 0, time.UTC),
+		EndsAt:   time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC),
+	}
+
+	if got := ChannelName(swarm); got != "swarm-checkout-fixes-q3-eu-1a2b3c4d" {
+		t.Fatalf("unexpected channel name %q", got)
+	}
+
+	if got := Charter(swarm); got != "*Swarm charter: Checkout fixes: Q3 / EU!*\nMission: Unblock the release\nTimebox: Mon 19 Oct 13:00 to 15:00 UTC" {
+		t.Fatalf("unexpected charter %q", got)
+	}
+
+	swarm.Name = "¡¿"
+	if got := ChannelName(swarm); got != "swarm-1a2b3c4d" {
+		t.Fatalf("unexpected channel name %q", got)
+	}
+}
@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+)
+
+// Email delivers notifications to the member's address over SMTP. STARTTLS
+// is used whenever the server offers it.
+type Email struct {
+	Addr string
+	From mail.Address
+	Auth smtp.Auth
+}
+
+// NewEmail constructs the email channel for the SMTP server at addr
+// (host:port). Auth may be nil for servers that accept unauthenticated mail,
+// such as a local stand-in.
+func NewEmail(addr string, from mail.Address, auth smtp.Auth) *Email {
+	return &Email{Addr: addr, From: from, Auth: auth}
+}
+
+// Name implements Channel.
+func (e *Email) Name() string { return ChannelEmail }
+
+// Send implements Channel.
+func (e *Email) Send(ctx context.Context, to Recipient, notification database.Notification) error {
+	if strings.TrimSpace(to.Member.Email) == "" {
+		return ErrNoAddress
+	}
+
+	message, err := composeEmail(e.From, mail.Address{Name: to.Member.DisplayName, Address: to.Member.Email}, notification, time.Now())
+	if err != nil {
+		return err
+	}
+
+	host, _, err := net.SplitHostPort(e.Addr)
+	if err != nil {
+		return err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", e.Addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(nil); err != nil {
+			return err
+		}
+	}
+	if e.Auth != nil {
+		if err := client.Auth(e.Auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(e.From.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to.Member.Email); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// composeEmail renders a plain-text RFC 5322 message. The notification id
+// doubles as the Message-ID so a redelivered email can be recognised.
+func composeEmail(from, to mail.Address, notification database.Notification, now time.Time) ([]byte, error) {
+	subject := strings.Join(strings.Fields(notification.Title), " ")
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@intent>\r\n", notification.ID)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	body := quotedprintable.NewWriter(&buf)
+	if _, err := body.Write([]byte(strings.ReplaceAll(notification.Body, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\r\n")
+
+	return buf.Bytes(), nil
+}
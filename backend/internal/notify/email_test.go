@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/example/intent/backend/internal/database"
+)
+
+// smtpStandIn accepts a single message over plain SMTP and reports the
+// envelope recipient and the message data.
+func smtpStandIn(t *testing.T) (string, <-chan [2]string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan [2]string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		var rcpt string
+		var data strings.Builder
+		reply("220 stand-in ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 stand-in")
+			case strings.HasPrefix(command, "MAIL FROM"):
+				reply("250 OK")
+			case strings.HasPrefix(command, "RCPT TO"):
+				rcpt = strings.TrimSpace(line[len("RCPT TO:"):])
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 go ahead")
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				received <- [2]string{rcpt, data.String()}
+				reply("250 queued")
+			case command == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), received
+}
+
+func TestEmailSendDeliversToStandIn(t *testing.T) {
+	addr, received := smtpStandIn(t)
+
+	channel := NewEmail(addr, mail.Address{Name: "Intent", Address: "intent@example.test"}, nil)
+	notification := database.Notification{
+		ID:    uuid.New(),
+		Title: "Declare your intent\r\nBcc: someone@example.test",
+		Body:  "Your chapter session starts Mon 19 Oct at 13:00 UTC.",
+	}
+	to := Recipient{Member: database.Member{DisplayName: "Aroha", Email: "aroha@example.test"}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := channel.Send(ctx, to, notification); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	message := <-received
+	if message[0] != "<aroha@example.test>" {
+		t.Fatalf("unexpected recipient %q", message[0])
+	}
+	for _, want := range []string{
+		"To: \"Aroha\" <aroha@example.test>\r\n",
+		"Subject: Declare your intent Bcc: someone@example.test\r\n",
+		"Message-ID: <" + notification.ID.String() + "@intent>\r\n",
+		"Your chapter session starts Mon 19 Oct at 13:00 UTC.",
+	} {
+		if !strings.Contains(message[1], want) {
+			t.Errorf("message missing %q:\n%s", want, message[1])
+		}
+	}
+	if strings.Contains(message[1], "\r\nBcc:") {
+		t.Errorf("title injected a header:\n%s", message[1])
+	}
+}
+
+func TestEmailSendWithoutAddress(t *testing.T) {
+	channel := NewEmail("127.0.0.1:1", mail.Address{Address: "intent@example.test"}, nil)
+
+	if err := channel.Send(context.Background(), Recipient{}, database.Notification{ID: uuid.New()}); err != ErrNoAddress {
+		t.Fatalf("expected ErrNoAddress got %v", err)
+	}
+}
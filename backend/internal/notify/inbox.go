@@ -0,0 +1,26 @@
+package notify
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/example/intent/backend/internal/database"
+)
+
+// Inbox delivers notifications to the member's in-app inbox.
+type Inbox struct {
+	db *sql.DB
+}
+
+// NewInbox constructs the in-app inbox channel.
+func NewInbox(db *sql.DB) *Inbox {
+	return &Inbox{db: db}
+}
+
+// Name implements Channel.
+func (i *Inbox) Name() string { return ChannelInbox }
+
+// Send implements Channel.
+func (i *Inbox) Send(ctx context.Context, _ Recipient, notification database.Notification) error {
+	return database.DeliverToInbox(ctx, i.db, notification.ID)
+}
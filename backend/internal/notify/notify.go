@@ -0,0 +1,191 @@
+// Package notify delivers notifications to members over the channels they
+// choose: the in-app inbox, SMTP email and Slack-compatible chat webhooks.
+package notify
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/example/intent/backend/internal/database"
+)
+
+// Delivery channels.
+const (
+	ChannelInbox = "inbox"
+	ChannelEmail = "email"
+	ChannelChat  = "chat"
+)
+
+// Channels lists every delivery channel a member can choose.
+var Channels = []string{ChannelInbox, ChannelEmail, ChannelChat}
+
+// Notification types members can choose channels for.
+const (
+	TypeSessionNudge   = "session_nudge"
+	TypeMissingOutcome = "missing_outcome"
+	TypeOutcomeDigest  = "missing_outcome_digest"
+)
+
+// Types lists every notification type.
+var Types = []string{TypeSessionNudge, TypeMissingOutcome, TypeOutcomeDigest}
+
+// DefaultChannels are used for types a member has not chosen channels for.
+var DefaultChannels = []string{ChannelInbox, ChannelEmail}
+
+// KindDeliver is the job kind that delivers one notification to one channel.
+const KindDeliver = "notify.deliver"
+
+// ErrNoAddress is returned by a channel that has nowhere to send to for the
+// member, such as chat without a webhook URL. Such deliveries are dropped
+// rather than retried.
+var ErrNoAddress = errors.New("member has no address for this channel")
+
+// Recipient is the member a notification is delivered to.
+type Recipient struct {
+	Member      database.Member
+	Preferences database.NotificationPreferences
+}
+
+// Channel sends notifications to members over one medium.
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, to Recipient, notification database.Notification) error
+}
+
+// ChannelsFor returns the channels a member wants notifications of type on.
+func ChannelsFor(prefs database.NotificationPreferences, notificationType string) []string {
+	if channels, ok := prefs.Channels[notificationType]; ok {
+		return channels
+	}
+	return DefaultChannels
+}
+
+// Notifier records notifications and fans them out to the configured
+// channels through the job queue.
+type Notifier struct {
+	logger   *slog.Logger
+	db       *sql.DB
+	channels map[string]Channel
+}
+
+// NewNotifier constructs a notifier delivering over the given channels.
+// Deliveries to a channel that is not configured, such as email without an
+// SMTP server, are skipped.
+func NewNotifier(logger *slog.Logger, db *sql.DB, channels ...Channel) *Notifier {
+	n := &Notifier{logger: logger, db: db, channels: make(map[string]Channel, len(channels))}
+	for _, channel := range channels {
+		n.channels[channel.Name()] = channel
+	}
+	return n
+}
+
+type deliveryPayload struct {
+	NotificationID uuid.UUID `json:"notificationId"`
+	Channel        string    `json:"channel"`
+}
+
+// Notify records a notification inside tx and enqueues a delivery job for
+// each channel the member chose for its type, so nothing is sent unless tx
+// commits. While the member sits in a session block and wants quiet hours,
+// email and chat are held back until the block ends; the inbox is silent
+// and is delivered at once.
+func (n *Notifier) Notify(ctx context.Context, tx *sql.Tx, input database.NotificationInput) (database.Notification, error) {
+	now := time.Now().UTC()
+
+	plan, err := database.PlanNotification(ctx, tx, input.MemberID, now)
+	if err != nil {
+		return database.Notification{}, err
+	}
+
+	notification, err := database.CreateNotification(ctx, tx, input)
+	if err != nil {
+		return database.Notification{}, err
+	}
+
+	for _, name := range ChannelsFor(plan.Preferences, input.Type) {
+		if _, ok := n.channels[name]; !ok {
+			continue
+		}
+
+		runAt := now
+		if name != ChannelInbox && plan.QuietUntil != nil {
+			runAt = *plan.QuietUntil
+		}
+
+		if _, err := database.EnqueueJobTx(ctx, tx, database.JobInput{
+			Kind:    KindDeliver,
+			Key:     notification.ID.String() + ":" + name,
+			Payload: deliveryPayload{NotificationID: notification.ID, Channel: name},
+			RunAt:   runAt,
+		}); err != nil {
+			return database.Notification{}, err
+		}
+	}
+
+	return notification, nil
+}
+
+// Deliver is the job handler for KindDeliver. Notifications whose member has
+// since been removed, and members a channel cannot reach, are skipped.
+func (n *Notifier) Deliver(ctx context.Context, job database.Job) error {
+	var payload deliveryPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return err
+	}
+
+	channel, ok := n.channels[payload.Channel]
+	if !ok {
+		n.logger.WarnContext(ctx, "notification channel not configured", "channel", payload.Channel, "notification_id", payload.NotificationID)
+		return nil
+	}
+
+	notification, err := database.GetNotification(ctx, n.db, payload.NotificationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	member, err := database.GetMember(ctx, n.db, notification.MemberID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	prefs, err := database.GetNotificationPreferences(ctx, n.db, member.ID)
+	if err != nil {
+		return err
+	}
+
+	err = channel.Send(ctx, Recipient{Member: member, Preferences: prefs}, notification)
+	if errors.Is(err, ErrNoAddress) {
+		n.logger.InfoContext(ctx, "notification skipped", "channel", payload.Channel, "notification_id", notification.ID, "member_id", member.ID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("deliver %s notification: %w", payload.Channel, err)
+	}
+
+	return nil
+}
+
+// ValidChannel reports whether name is a known delivery channel.
+func ValidChannel(name string) bool {
+	return slices.Contains(Channels, name)
+}
+
+// ValidType reports whether name is a known notification type.
+func ValidType(name string) bool {
+	return slices.Contains(Types, name)
+}
@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"log/slog"
+	"net/mail"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+
+	"github.com/example/intent/backend/internal/database"
+)
+
+// runAtWithin matches a job's run_at argument against an expected time.
+type runAtWithin struct {
+	want time.Time
+}
+
+func (m runAtWithin) Match(value driver.Value) bool {
+	got, ok := value.(time.Time)
+	return ok && got.Sub(m.want).Abs() < time.Minute
+}
+
+func TestNotifyHoldsBackEmailDuringSession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	memberID := uuid.New()
+	sessionEnds := time.Now().UTC().Add(2 * time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM notification_preferences")).
+		WithArgs(memberID).
+		WillReturnRows(sqlmock.NewRows([]string{"channels", "quiet_during_sessions", "chat_webhook_url", "updated_at"}).
+			AddRow([]byte(`{"session_nudge":["inbox","email","chat"]}`), true, nil, time.Now()))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT MAX(s.ends_at)")).
+		WithArgs(memberID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(sessionEnds))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO notifications")).
+		WithArgs(sqlmock.AnyArg(), memberID, TypeSessionNudge, "Declare your intent", "Body", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO jobs")).
+		WithArgs(sqlmock.AnyArg(), KindDeliver, sqlmock.AnyArg(), sqlmock.AnyArg(), database.DefaultJobAttempts, runAtWithin{time.Now().UTC()}, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO jobs")).
+		WithArgs(sqlmock.AnyArg(), KindDeliver, sqlmock.AnyArg(), sqlmock.AnyArg(), database.DefaultJobAttempts, runAtWithin{sessionEnds}, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// Chat is chosen but not configured, so only inbox and email are queued.
+	notifier := NewNotifier(slog.New(slog.NewTextHandler(io.Discard, nil)), db, NewInbox(db), NewEmail("127.0.0.1:25", mail.Address{Address: "intent@example.test"}, nil))
+
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to begin: %v", err)
+	}
+	if _, err := notifier.Notify(ctx, tx, database.NotificationInput{MemberID: memberID, Type: TypeSessionNudge, Title: "Declare your intent", Body: "Body"}); err != nil {
+		t.Fatalf("Notify returned error: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestChannelsForFallsBackToDefaults(t *testing.T) {
+	prefs := database.NotificationPreferences{Channels: map[string][]string{"other": {ChannelChat}}}
+	if got := ChannelsFor(prefs, TypeSessionNudge); len(got) != len(DefaultChannels) {
+		t.Fatalf("expected defaults got %v", got)
+	}
+
+	prefs.Channels[TypeSessionNudge] = []string{}
+	if got := ChannelsFor(prefs, TypeSessionNudge); len(got) != 0 {
+		t.Fatalf("expected muted type to have no channels got %v", got)
+	}
+}
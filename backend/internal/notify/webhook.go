@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+)
+
+// DefaultClient is used to call chat webhooks.
+var DefaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// Webhook posts notifications to the member's Slack-compatible incoming
+// webhook.
+type Webhook struct {
+	client *http.Client
+}
+
+// NewWebhook constructs the chat webhook channel.
+func NewWebhook(client *http.Client) *Webhook {
+	return &Webhook{client: client}
+}
+
+// Name implements Channel.
+func (w *Webhook) Name() string { return ChannelChat }
+
+type webhookMessage struct {
+	Text string `json:"text"`
+}
+
+// slackEscaper escapes the characters Slack treats as control sequences.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Send implements Channel.
+func (w *Webhook) Send(ctx context.Context, to Recipient, notification database.Notification) error {
+	url := to.Preferences.ChatWebhookURL
+	if url == "" {
+		return ErrNoAddress
+	}
+
+	text := "*" + slackEscaper.Replace(notification.Title) + "*"
+	if notification.Body != "" {
+		text += "\n" + slackEscaper.Replace(notification.Body)
+	}
+
+	payload, err := json.Marshal(webhookMessage{Text: text})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("chat webhook responded %s", resp.Status)
+	}
+
+	return nil
+}
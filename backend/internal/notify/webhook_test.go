@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/example/intent/backend/internal/database"
+)
+
+func TestWebhookSendPostsSlackText(t *testing.T) {
+	var got webhookMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected content type %q", r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode payload: %v", err)
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+
+	to := Recipient{Preferences: database.NotificationPreferences{ChatWebhookURL: server.URL}}
+	notification := database.Notification{ID: uuid.New(), Title: "Refine <draft> intent", Body: "Q&A at 13:00"}
+
+	if err := NewWebhook(server.Client()).Send(context.Background(), to, notification); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	if want := "*Refine &lt;draft&gt; intent*\nQ&amp;A at 13:00"; got.Text != want {
+		t.Fatalf("expected text %q got %q", want, got.Text)
+	}
+}
+
+func TestWebhookSendFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	t.Cleanup(server.Close)
+
+	to := Recipient{Preferences: database.NotificationPreferences{ChatWebhookURL: server.URL}}
+
+	if err := NewWebhook(server.Client()).Send(context.Background(), to, database.Notification{ID: uuid.New()}); err == nil {
+		t.Fatal("expected error for forbidden webhook")
+	}
+}
+
+func TestWebhookSendWithoutURL(t *testing.T) {
+	if err := NewWebhook(http.DefaultClient).Send(context.Background(), Recipient{}, database.Notification{}); err != ErrNoAddress {
+		t.Fatalf("expected ErrNoAddress got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+// Package placeholder fills {{name}} variables in template text.
+package placeholder
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var pattern = regexp.MustCompile(`\{\{\s*([A-Za-z][A-Za-z0-9_]*)\s*\}\}`)
+
+// Names returns the variables used across texts, in order of first use.
+func Names(texts ...string) []string {
+	names := make([]string, 0)
+	for _, text := range texts {
+		for _, match := range pattern.FindAllStringSubmatch(text, -1) {
+			if !slices.Contains(names, match[1]) {
+				names = append(names, match[1])
+			}
+		}
+	}
+	return names
+}
+
+// Fill replaces each variable in text with its value. Variables without a
+// non-blank value are left in place and returned, in order of first use.
+func Fill(text string, values map[string]string) (string, []string) {
+	missing := make([]string, 0)
+	filled := pattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := pattern.FindStringSubmatch(match)[1]
+		value := strings.TrimSpace(values[name])
+		if value == "" {
+			if !slices.Contains(missing, name) {
+				missing = append(missing, name)
+			}
+			return match
+		}
+		return value
+	})
+	return filled, missing
+}
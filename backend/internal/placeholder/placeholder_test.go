@@ -0,0 +1,34 @@
+package placeholder
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNames(t *testing.T) {
+	got := Names("I intend to spike {{ option }} for {{service}}.", "Compare {{option}} with {{current_tool}}.")
+	want := []string{"option", "service", "current_tool"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v got %v", want, got)
+	}
+}
+
+func TestFill(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		text    string
+		values  map[string]string
+		want    string
+		missing []string
+	}{
+		{name: "all given", text: "Refactor {{module}} in {{ service }}", values: map[string]string{"module": "billing", "service": "api"}, want: "Refactor billing in api", missing: []string{}},
+		{name: "repeated", text: "{{a}} and {{a}}", values: map[string]string{"a": "x"}, want: "x and x", missing: []string{}},
+		{name: "missing", text: "Review {{design}} with {{team}}", values: map[string]string{"design": "auth flow", "team": " "}, want: "Review auth flow with {{team}}", missing: []string{"team"}},
+		{name: "no variables", text: "Plain text", want: "Plain text", missing: []string{}},
+	} {
+		got, missing := Fill(tc.text, tc.values)
+		if got != tc.want || !slices.Equal(missing, tc.missing) {
+			t.Fatalf("%s: expected %q %v got %q %v", tc.name, tc.want, tc.missing, got, missing)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Package quality scores how clearly an intent is written against a set of
+// rules and turns the rules it misses into coaching questions. Rules are
+// data: the defaults ship in rules.json and chapters can replace them.
+package quality
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Fields of an intent a rule can look at.
+const (
+	FieldStatement       = "statement"
+	FieldContext         = "context"
+	FieldExpectedOutcome = "expectedOutcome"
+	FieldCollaborators   = "collaborators"
+	FieldGoal            = "goal"
+)
+
+// Checks a rule can apply to its field.
+const (
+	// CheckPrefix passes when the text starts with Value, ignoring case.
+	CheckPrefix = "prefix"
+	// CheckPattern passes when the text matches the regular expression Value.
+	CheckPattern = "pattern"
+	// CheckMinWords passes when the text, or the list of collaborators, has
+	// at least Min words or names.
+	CheckMinWords = "minWords"
+	// CheckPresent passes when the field is filled in.
+	CheckPresent = "present"
+)
+
+// MaxRules caps the number of rules a chapter can configure.
+const MaxRules = 20
+
+//go:embed rules.json
+var defaultRulesJSON []byte
+
+// Rule is one clarity check. Weight is its share of the score and Question
+// is the coaching prompt offered when the intent misses it.
+type Rule struct {
+	ID       string `json:"id"`
+	Label    string `json:"label"`
+	Field    string `json:"field"`
+	Check    string `json:"check"`
+	Value    string `json:"value,omitempty"`
+	Min      int    `json:"min,omitempty"`
+	Weight   int    `json:"weight"`
+	Question string `json:"question"`
+}
+
+// Intent is the part of an intent the rules look at.
+type Intent struct {
+	Statement       string
+	Context         string
+	ExpectedOutcome string
+	Collaborators   []string
+	HasGoal         bool
+}
+
+// RuleResult records whether an intent passed one rule.
+type RuleResult struct {
+	RuleID string `json:"ruleId"`
+	Label  string `json:"label"`
+	Weight int    `json:"weight"`
+	Passed bool   `json:"passed"`
+}
+
+// Result is an intent's score out of 100, how each rule contributed to it,
+// and the coaching questions of the rules it missed.
+type Result struct {
+	Score     int
+	Breakdown []RuleResult
+	Questions []string
+}
+
+// DefaultRules returns a copy of the rules used by chapters that have not
+// configured their own.
+func DefaultRules() []Rule {
+	var rules []Rule
+	if err := json.Unmarshal(defaultRulesJSON, &rules); err != nil {
+		panic(fmt.Sprintf("quality: invalid default rules: %v", err))
+	}
+	return rules
+}
+
+// Validate checks that rules have unique ids, known fields and checks, a
+// positive weight, a question, and the value or minimum their check needs.
+func Validate(rules []Rule) error {
+	if len(rules) == 0 {
+		return errors.New("at least one rule is required")
+	}
+	if len(rules) > MaxRules {
+		return fmt.Errorf("at most %d rules are allowed", MaxRules)
+	}
+
+	seen := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if strings.TrimSpace(rule.ID) == "" {
+			return errors.New("rule id is required")
+		}
+		if seen[rule.ID] {
+			return fmt.Errorf("rule %s is defined more than once", rule.ID)
+		}
+		seen[rule.ID] = true
+
+		if rule.Weight <= 0 {
+			return fmt.Errorf("rule %s: weight must be positive", rule.ID)
+		}
+		if strings.TrimSpace(rule.Question) == "" {
+			return fmt.Errorf("rule %s: question is required", rule.ID)
+		}
+
+		text := false
+		switch rule.Field {
+		case FieldStatement, FieldContext, FieldExpectedOutcome:
+			text = true
+		case FieldCollaborators, FieldGoal:
+		default:
+			return fmt.Errorf("rule %s: unknown field %q", rule.ID, rule.Field)
+		}
+
+		switch rule.Check {
+		case CheckPrefix:
+			if !text || strings.TrimSpace(rule.Value) == "" {
+				return fmt.Errorf("rule %s: prefix needs a text field and a value", rule.ID)
+			}
+		case CheckPattern:
+			if !text {
+				return fmt.Errorf("rule %s: pattern needs a text field", rule.ID)
+			}
+			if _, err := regexp.Compile(rule.Value); err != nil || rule.Value == "" {
+				return fmt.Errorf("rule %s: value must be a valid regular expression", rule.ID)
+			}
+		case CheckMinWords:
+			if rule.Field == FieldGoal || rule.Min <= 0 {
+				return fmt.Errorf("rule %s: minWords needs a text or collaborators field and a positive min", rule.ID)
+			}
+		case CheckPresent:
+		default:
+			return fmt.Errorf("rule %s: unknown check %q", rule.ID, rule.Check)
+		}
+	}
+
+	return nil
+}
+
+// Analyze scores intent against rules, which must be valid. The score is
+// the weight of the rules passed as a share of the total weight.
+func Analyze(intent Intent, rules []Rule) Result {
+	result := Result{
+		Breakdown: make([]RuleResult, 0, len(rules)),
+		Questions: make([]string, 0),
+	}
+
+	earned, total := 0, 0
+	for _, rule := range rules {
+		passed := passes(intent, rule)
+		result.Breakdown = append(result.Breakdown, RuleResult{
+			RuleID: rule.ID,
+			Label:  rule.Label,
+			Weight: rule.Weight,
+			Passed: passed,
+		})
+
+		total += rule.Weight
+		if passed {
+			earned += rule.Weight
+		} else {
+			result.Questions = append(result.Questions, rule.Question)
+		}
+	}
+
+	if total > 0 {
+		result.Score = int(math.Round(float64(earned) * 100 / float64(total)))
+	}
+
+	return result
+}
+
+func passes(intent Intent, rule Rule) bool {
+	switch rule.Field {
+	case FieldCollaborators:
+		names := 0
+		for _, name := range intent.Collaborators {
+			if strings.TrimSpace(name) != "" {
+				names++
+			}
+		}
+		if rule.Check == CheckMinWords {
+			return names >= rule.Min
+		}
+		return names > 0
+	case FieldGoal:
+		return intent.HasGoal
+	}
+
+	text := strings.TrimSpace(fieldText(intent, rule.Field))
+	switch rule.Check {
+	case CheckPrefix:
+		return strings.HasPrefix(strings.ToLower(text), strings.ToLower(rule.Value))
+	case CheckPattern:
+		pattern, err := regexp.Compile(rule.Value)
+		return err == nil && pattern.MatchString(text)
+	case CheckMinWords:
+		return len(strings.Fields(text)) >= rule.Min
+	default:
+		return text != ""
+	}
+}
+
+func fieldText(intent Intent, field string) string {
+	switch field {
+	case FieldStatement:
+		return intent.Statement
+	case FieldContext:
+		return intent.Context
+	default:
+		return intent.ExpectedOutcome
+	}
+}
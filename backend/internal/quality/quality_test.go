@@ -0,0 +1,77 @@
+package quality
+
+import "testing"
+
+func TestDefaultRulesAreValid(t *testing.T) {
+	if err := Validate(DefaultRules()); err != nil {
+		t.Fatalf("default rules: %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := Rule{ID: "goal", Field: FieldGoal, Check: CheckPresent, Weight: 10, Question: "Which goal?"}
+
+	for _, tc := range []struct {
+		name  string
+		rules []Rule
+		valid bool
+	}{
+		{name: "present", rules: []Rule{valid}, valid: true},
+		{name: "empty", rules: nil},
+		{name: "duplicate id", rules: []Rule{valid, valid}},
+		{name: "unknown field", rules: []Rule{{ID: "x", Field: "title", Check: CheckPresent, Weight: 1, Question: "?"}}},
+		{name: "zero weight", rules: []Rule{{ID: "x", Field: FieldGoal, Check: CheckPresent, Question: "?"}}},
+		{name: "no question", rules: []Rule{{ID: "x", Field: FieldGoal, Check: CheckPresent, Weight: 1}}},
+		{name: "prefix on goal", rules: []Rule{{ID: "x", Field: FieldGoal, Check: CheckPrefix, Value: "I", Weight: 1, Question: "?"}}},
+		{name: "bad pattern", rules: []Rule{{ID: "x", Field: FieldContext, Check: CheckPattern, Value: "(", Weight: 1, Question: "?"}}},
+		{name: "min words without min", rules: []Rule{{ID: "x", Field: FieldContext, Check: CheckMinWords, Weight: 1, Question: "?"}}},
+		{name: "min collaborators", rules: []Rule{{ID: "x", Field: FieldCollaborators, Check: CheckMinWords, Min: 2, Weight: 1, Question: "?"}}, valid: true},
+	} {
+		if err := Validate(tc.rules); (err == nil) != tc.valid {
+			t.Fatalf("%s: expected valid=%v got %v", tc.name, tc.valid, err)
+		}
+	}
+}
+
+func TestAnalyzeDefaultRules(t *testing.T) {
+	strong := Intent{
+		Statement:       "I intend to cut our deploy time in half.",
+		Context:         "Deploys take forty minutes since the monorepo move and block the Thursday release train.",
+		ExpectedOutcome: "Median deploy time under 20 minutes by the end of May.",
+		Collaborators:   []string{"Ana"},
+		HasGoal:         true,
+	}
+
+	result := Analyze(strong, DefaultRules())
+	if result.Score != 100 || len(result.Questions) != 0 {
+		t.Fatalf("expected a perfect score got %+v", result)
+	}
+
+	weak := Intent{Statement: "Faster deploys", Context: "Slow.", ExpectedOutcome: "Things are better."}
+
+	result = Analyze(weak, DefaultRules())
+	if result.Score != 0 {
+		t.Fatalf("expected score 0 got %d", result.Score)
+	}
+	if len(result.Questions) != len(DefaultRules()) || len(result.Breakdown) != len(DefaultRules()) {
+		t.Fatalf("expected a question per rule got %+v", result)
+	}
+}
+
+func TestAnalyzeWeighsRules(t *testing.T) {
+	rules := []Rule{
+		{ID: "prefix", Field: FieldStatement, Check: CheckPrefix, Value: "I intend to", Weight: 3, Question: "Own it?"},
+		{ID: "pair", Field: FieldCollaborators, Check: CheckMinWords, Min: 2, Weight: 1, Question: "Who else?"},
+	}
+
+	result := Analyze(Intent{Statement: "  i INTEND to pair more", Collaborators: []string{"Ana", " "}}, rules)
+	if result.Score != 75 {
+		t.Fatalf("expected score 75 got %d", result.Score)
+	}
+	if len(result.Questions) != 1 || result.Questions[0] != "Who else?" {
+		t.Fatalf("expected the pairing question got %v", result.Questions)
+	}
+	if !result.Breakdown[0].Passed || result.Breakdown[1].Passed {
+		t.Fatalf("unexpected breakdown %+v", result.Breakdown)
+	}
+}
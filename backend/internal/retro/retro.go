@@ -0,0 +1,315 @@
+package retro
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Question kinds supported by retro micro-surveys.
+const (
+	KindScale  = "scale"
+	KindChoice = "choice"
+	KindText   = "text"
+)
+
+const (
+	// MaxQuestions keeps surveys short enough to answer at close-out.
+	MaxQuestions = 10
+	// MaxScaleSteps bounds the number of points on a scale question.
+	MaxScaleSteps = 11
+	// MaxTextLength bounds free-text answers.
+	MaxTextLength = 2000
+)
+
+// ErrInvalidAnswer is returned when a response does not fit the survey.
+var ErrInvalidAnswer = errors.New("invalid answer")
+
+// Question is a single survey question. Scale questions are answered with an
+// integer between Min and Max inclusive, choice questions with one of
+// Options, and text questions with free text.
+type Question struct {
+	ID       string   `json:"id"`
+	Prompt   string   `json:"prompt"`
+	Kind     string   `json:"kind"`
+	Min      int      `json:"min,omitempty"`
+	Max      int      `json:"max,omitempty"`
+	Options  []string `json:"options,omitempty"`
+	Required bool     `json:"required"`
+}
+
+// Response is a submitted set of answers keyed by question id. Respondent is
+// nil for anonymous surveys.
+type Response struct {
+	Respondent *uuid.UUID
+	Answers    map[string]any
+}
+
+// TextAnswer is a free-text answer, attributed when the survey is not
+// anonymous.
+type TextAnswer struct {
+	Text       string
+	Respondent *uuid.UUID
+}
+
+// Tally records the answers to an anonymous survey per question. Each
+// question's answers are kept sorted and apart from the answers given to other
+// questions, so no single response can be rebuilt from it.
+type Tally struct {
+	Responses int              `json:"responses"`
+	Answers   map[string][]any `json:"answers"`
+}
+
+// Add counts one response's normalized answers.
+func (t *Tally) Add(answers map[string]any) {
+	t.Responses++
+	for id, value := range answers {
+		t.add(id, value)
+	}
+}
+
+// Merge folds another tally into t.
+func (t *Tally) Merge(other Tally) {
+	t.Responses += other.Responses
+	for id, values := range other.Answers {
+		for _, value := range values {
+			t.add(id, value)
+		}
+	}
+}
+
+func (t *Tally) add(id string, value any) {
+	if t.Answers == nil {
+		t.Answers = make(map[string][]any)
+	}
+
+	values := append(t.Answers[id], value)
+	sort.SliceStable(values, func(i, j int) bool {
+		return fmt.Sprint(values[i]) < fmt.Sprint(values[j])
+	})
+	t.Answers[id] = values
+}
+
+// Result aggregates the answers to one question. Distribution counts scale
+// values (keyed by the value) or choice options; Average is set for scale
+// questions with at least one answer.
+type Result struct {
+	Question     Question
+	Answered     int
+	Average      *float64
+	Distribution map[string]int
+	TextAnswers  []TextAnswer
+}
+
+// ValidateQuestions checks that a template's questions are well formed.
+func ValidateQuestions(questions []Question) error {
+	if len(questions) == 0 {
+		return errors.New("at least one question is required")
+	}
+	if len(questions) > MaxQuestions {
+		return fmt.Errorf("a survey may have at most %d questions", MaxQuestions)
+	}
+
+	seen := make(map[string]struct{}, len(questions))
+	for _, question := range questions {
+		if strings.TrimSpace(question.ID) == "" {
+			return errors.New("every question needs an id")
+		}
+		if _, ok := seen[question.ID]; ok {
+			return fmt.Errorf("question id %q is used more than once", question.ID)
+		}
+		seen[question.ID] = struct{}{}
+
+		if strings.TrimSpace(question.Prompt) == "" {
+			return fmt.Errorf("question %q needs a prompt", question.ID)
+		}
+
+		switch question.Kind {
+		case KindScale:
+			if question.Min >= question.Max || question.Max-question.Min+1 > MaxScaleSteps {
+				return fmt.Errorf("question %q needs a scale of 2 to %d points", question.ID, MaxScaleSteps)
+			}
+		case KindChoice:
+			if len(question.Options) < 2 {
+				return fmt.Errorf("question %q needs at least two options", question.ID)
+			}
+			options := make(map[string]struct{}, len(question.Options))
+			for _, option := range question.Options {
+				if strings.TrimSpace(option) == "" {
+					return fmt.Errorf("question %q has an empty option", question.ID)
+				}
+				if _, ok := options[option]; ok {
+					return fmt.Errorf("question %q repeats option %q", question.ID, option)
+				}
+				options[option] = struct{}{}
+			}
+		case KindText:
+		default:
+			return fmt.Errorf("question %q must be of kind scale, choice, or text", question.ID)
+		}
+	}
+
+	return nil
+}
+
+// NormalizeAnswers validates answers decoded from JSON against the questions
+// and returns them with scale answers as ints and other answers as trimmed
+// strings. Unanswered optional questions and empty text answers are dropped.
+// Errors wrap ErrInvalidAnswer.
+func NormalizeAnswers(questions []Question, answers map[string]any) (map[string]any, error) {
+	byID := make(map[string]Question, len(questions))
+	for _, question := range questions {
+		byID[question.ID] = question
+	}
+
+	for id := range answers {
+		if _, ok := byID[id]; !ok {
+			return nil, fmt.Errorf("%w: unknown question %q", ErrInvalidAnswer, id)
+		}
+	}
+
+	normalized := make(map[string]any, len(answers))
+	for _, question := range questions {
+		value, ok := answers[question.ID]
+		if !ok || value == nil {
+			if question.Required {
+				return nil, fmt.Errorf("%w: question %q is required", ErrInvalidAnswer, question.ID)
+			}
+			continue
+		}
+
+		switch question.Kind {
+		case KindScale:
+			number, ok := value.(float64)
+			if !ok || number != math.Trunc(number) || int(number) < question.Min || int(number) > question.Max {
+				return nil, fmt.Errorf("%w: question %q needs a whole number from %d to %d", ErrInvalidAnswer, question.ID, question.Min, question.Max)
+			}
+			normalized[question.ID] = int(number)
+		case KindChoice:
+			text, ok := value.(string)
+			if !ok || !contains(question.Options, text) {
+				return nil, fmt.Errorf("%w: question %q needs one of its options", ErrInvalidAnswer, question.ID)
+			}
+			normalized[question.ID] = text
+		case KindText:
+			text, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: question %q needs a text answer", ErrInvalidAnswer, question.ID)
+			}
+			text = strings.TrimSpace(text)
+			if len(text) > MaxTextLength {
+				return nil, fmt.Errorf("%w: question %q allows at most %d characters", ErrInvalidAnswer, question.ID, MaxTextLength)
+			}
+			if text == "" {
+				if question.Required {
+					return nil, fmt.Errorf("%w: question %q is required", ErrInvalidAnswer, question.ID)
+				}
+				continue
+			}
+			normalized[question.ID] = text
+		}
+	}
+
+	return normalized, nil
+}
+
+// Aggregate summarises responses and a tally of anonymous answers per
+// question. Text answers are only collected when includeText is set.
+func Aggregate(questions []Question, responses []Response, tally Tally, includeText bool) []Result {
+	results := make([]Result, 0, len(questions))
+	for _, question := range questions {
+		result := Result{Question: question, Distribution: map[string]int{}, TextAnswers: []TextAnswer{}}
+
+		switch question.Kind {
+		case KindScale:
+			for value := question.Min; value <= question.Max; value++ {
+				result.Distribution[strconv.Itoa(value)] = 0
+			}
+		case KindChoice:
+			for _, option := range question.Options {
+				result.Distribution[option] = 0
+			}
+		}
+
+		sum := 0
+		count := func(value any, respondent *uuid.UUID) {
+			switch question.Kind {
+			case KindScale:
+				number, ok := asInt(value)
+				if !ok {
+					return
+				}
+				sum += number
+				result.Distribution[strconv.Itoa(number)]++
+			case KindChoice:
+				text, ok := value.(string)
+				if !ok {
+					return
+				}
+				result.Distribution[text]++
+			case KindText:
+				text, ok := value.(string)
+				if !ok {
+					return
+				}
+				if includeText {
+					result.TextAnswers = append(result.TextAnswers, TextAnswer{Text: text, Respondent: respondent})
+				}
+			}
+			result.Answered++
+		}
+
+		for _, response := range responses {
+			if value, ok := response.Answers[question.ID]; ok {
+				count(value, response.Respondent)
+			}
+		}
+
+		for _, value := range tally.Answers[question.ID] {
+			count(value, nil)
+		}
+
+		if question.Kind == KindScale && result.Answered > 0 {
+			average := math.Round(float64(sum)/float64(result.Answered)*100) / 100
+			result.Average = &average
+		}
+
+		if question.Kind == KindText {
+			result.Distribution = nil
+			sort.SliceStable(result.TextAnswers, func(i, j int) bool {
+				return result.TextAnswers[i].Text < result.TextAnswers[j].Text
+			})
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// asInt accepts scale answers either as stored ints or as numbers decoded
+// from JSONB.
+func asInt(value any) (int, bool) {
+	switch number := value.(type) {
+	case int:
+		return number, true
+	case float64:
+		return int(number), number == math.Trunc(number)
+	default:
+		return 0, false
+	}
+}
+
+func contains(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
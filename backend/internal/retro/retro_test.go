@@ -0,0 +1,125 @@
+package retro
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+var testQuestions = []Question{
+	{ID: "energy", Prompt: "How was your energy?", Kind: KindScale, Min: 1, Max: 5, Required: true},
+	{ID: "format", Prompt: "Keep the format?", Kind: KindChoice, Options: []string{"yes", "no"}},
+	{ID: "notes", Prompt: "Anything else?", Kind: KindText},
+}
+
+func TestValidateQuestionsRejectsBadScale(t *testing.T) {
+	err := ValidateQuestions([]Question{{ID: "q", Prompt: "Rate it", Kind: KindScale, Min: 3, Max: 3}})
+	if err == nil {
+		t.Fatalf("expected single-point scale to be rejected")
+	}
+}
+
+func TestValidateQuestionsRejectsDuplicateIDs(t *testing.T) {
+	questions := []Question{
+		{ID: "q", Prompt: "One", Kind: KindText},
+		{ID: "q", Prompt: "Two", Kind: KindText},
+	}
+	if err := ValidateQuestions(questions); err == nil {
+		t.Fatalf("expected duplicate ids to be rejected")
+	}
+}
+
+func TestNormalizeAnswers(t *testing.T) {
+	answers, err := NormalizeAnswers(testQuestions, map[string]any{"energy": float64(4), "format": "no", "notes": "  "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if answers["energy"] != 4 || answers["format"] != "no" {
+		t.Fatalf("unexpected answers %v", answers)
+	}
+
+	if _, ok := answers["notes"]; ok {
+		t.Fatalf("expected blank text answer to be dropped")
+	}
+}
+
+func TestNormalizeAnswersRejectsInvalid(t *testing.T) {
+	cases := map[string]map[string]any{
+		"missing required": {"format": "yes"},
+		"out of range":     {"energy": float64(6)},
+		"fractional":       {"energy": 2.5},
+		"unknown option":   {"energy": float64(3), "format": "maybe"},
+		"unknown question": {"energy": float64(3), "mood": "fine"},
+	}
+
+	for name, answers := range cases {
+		if _, err := NormalizeAnswers(testQuestions, answers); !errors.Is(err, ErrInvalidAnswer) {
+			t.Fatalf("%s: expected ErrInvalidAnswer got %v", name, err)
+		}
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	member := uuid.New()
+	responses := []Response{
+		{Respondent: &member, Answers: map[string]any{"energy": float64(5), "format": "yes", "notes": "More pairing"}},
+		{Answers: map[string]any{"energy": 2, "format": "yes"}},
+	}
+
+	results := Aggregate(testQuestions, responses, Tally{}, true)
+
+	energy := results[0]
+	if energy.Answered != 2 || energy.Average == nil || *energy.Average != 3.5 {
+		t.Fatalf("unexpected scale result %+v", energy)
+	}
+	if energy.Distribution["5"] != 1 || energy.Distribution["1"] != 0 {
+		t.Fatalf("unexpected scale distribution %v", energy.Distribution)
+	}
+
+	if format := results[1]; format.Distribution["yes"] != 2 || format.Distribution["no"] != 0 {
+		t.Fatalf("unexpected choice distribution %v", format.Distribution)
+	}
+
+	notes := results[2]
+	if len(notes.TextAnswers) != 1 || notes.TextAnswers[0].Respondent == nil || *notes.TextAnswers[0].Respondent != member {
+		t.Fatalf("expected attributed text answer got %+v", notes.TextAnswers)
+	}
+
+	if withoutText := Aggregate(testQuestions, responses, Tally{}, false); len(withoutText[2].TextAnswers) != 0 || withoutText[2].Answered != 1 {
+		t.Fatalf("expected text answers to be counted but not collected got %+v", withoutText[2])
+	}
+}
+
+func TestTallyKeepsAnswersApart(t *testing.T) {
+	var tally Tally
+	tally.Add(map[string]any{"energy": 5, "notes": "Zebra"})
+	tally.Add(map[string]any{"energy": 2, "format": "no", "notes": "Apples"})
+
+	var merged Tally
+	merged.Merge(tally)
+	merged.Merge(Tally{Responses: 1, Answers: map[string][]any{"format": {"yes"}}})
+
+	if merged.Responses != 3 {
+		t.Fatalf("expected 3 responses got %d", merged.Responses)
+	}
+
+	if notes := merged.Answers["notes"]; len(notes) != 2 || notes[0] != "Apples" {
+		t.Fatalf("expected sorted text answers got %v", notes)
+	}
+
+	results := Aggregate(testQuestions, nil, merged, true)
+
+	if energy := results[0]; energy.Answered != 2 || *energy.Average != 3.5 {
+		t.Fatalf("unexpected scale result %+v", energy)
+	}
+
+	if format := results[1]; format.Distribution["yes"] != 1 || format.Distribution["no"] != 1 {
+		t.Fatalf("unexpected choice distribution %v", format.Distribution)
+	}
+
+	if notes := results[2]; len(notes.TextAnswers) != 2 || notes.TextAnswers[0].Respondent != nil {
+		t.Fatalf("expected anonymous text answers got %+v", notes.TextAnswers)
+	}
+}
@@ -0,0 +1,77 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	// Chapter timezones are resolved at runtime, so embed the zone database
+	// for container images that do not ship one.
+	_ "time/tzdata"
+)
+
+// Window is a recurring weekly slot in which chapter work may be booked.
+type Window struct {
+	Weekday time.Weekday
+	Start   time.Duration // offset from local midnight
+	End     time.Duration // offset from local midnight
+}
+
+// ChapterWindows are the only slots chapter time may be booked into:
+// Monday 13:00–17:00 and Thursday 13:00–17:00 in the chapter's timezone.
+var ChapterWindows = []Window{
+	{Weekday: time.Monday, Start: 13 * time.Hour, End: 17 * time.Hour},
+	{Weekday: time.Thursday, Start: 13 * time.Hour, End: 17 * time.Hour},
+}
+
+// ErrOutsideWindow is returned when a date or time range falls outside the
+// chapter windows.
+var ErrOutsideWindow = errors.New("chapter time can only be booked on Monday or Thursday between 13:00 and 17:00")
+
+// WindowOn returns the start and end of the chapter window on the given
+// calendar date in loc. It returns ErrOutsideWindow when no window falls on
+// that weekday.
+func WindowOn(date time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+	for _, window := range ChapterWindows {
+		if day.Weekday() != window.Weekday {
+			continue
+		}
+		start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc).Add(window.Start)
+		end := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc).Add(window.End)
+		return start, end, nil
+	}
+	return time.Time{}, time.Time{}, ErrOutsideWindow
+}
+
+// ParseDate parses a YYYY-MM-DD calendar date.
+func ParseDate(value string) (time.Time, error) {
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("date must be formatted as YYYY-MM-DD: %w", err)
+	}
+	return date, nil
+}
+
+// Within reports whether [start, end) is a non-empty range contained in
+// [windowStart, windowEnd).
+func Within(start, end, windowStart, windowEnd time.Time) bool {
+	return start.Before(end) && !start.Before(windowStart) && !end.After(windowEnd)
+}
+
+// Blocks returns the number of whole blocks of the given length that fit in
+// [start, end).
+func Blocks(start, end time.Time, blockLength time.Duration) int {
+	if blockLength <= 0 || !start.Before(end) {
+		return 0
+	}
+	return int(end.Sub(start) / blockLength)
+}
+
+// LoadLocation resolves an IANA timezone name, treating an empty name as UTC.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(name)
+}
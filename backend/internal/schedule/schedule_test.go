@@ -0,0 +1,67 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWindowOnMonday(t *testing.T) {
+	loc, err := time.LoadLocation("Pacific/Auckland")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+
+	start, end, err := WindowOn(time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC), loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if start.Hour() != 13 || start.Weekday() != time.Monday || start.Location() != loc {
+		t.Fatalf("unexpected window start %s", start)
+	}
+
+	if end.Sub(start) != 4*time.Hour {
+		t.Fatalf("expected a four hour window got %s", end.Sub(start))
+	}
+}
+
+func TestWindowOnRejectsOtherDays(t *testing.T) {
+	_, _, err := WindowOn(time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC), time.UTC)
+	if !errors.Is(err, ErrOutsideWindow) {
+		t.Fatalf("expected ErrOutsideWindow got %v", err)
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	start := time.Date(2026, time.October, 19, 13, 0, 0, 0, time.UTC)
+
+	if got := Blocks(start, start.Add(4*time.Hour), time.Hour); got != 4 {
+		t.Fatalf("expected 4 blocks got %d", got)
+	}
+
+	if got := Blocks(start, start.Add(150*time.Minute), time.Hour); got != 2 {
+		t.Fatalf("expected partial blocks to be dropped got %d", got)
+	}
+
+	if got := Blocks(start, start, time.Hour); got != 0 {
+		t.Fatalf("expected empty range to have 0 blocks got %d", got)
+	}
+}
+
+func TestWithin(t *testing.T) {
+	windowStart := time.Date(2026, time.October, 19, 13, 0, 0, 0, time.UTC)
+	windowEnd := windowStart.Add(4 * time.Hour)
+
+	if !Within(windowStart.Add(time.Hour), windowEnd, windowStart, windowEnd) {
+		t.Fatal("expected range inside window to be within")
+	}
+
+	if Within(windowStart.Add(-time.Minute), windowEnd, windowStart, windowEnd) {
+		t.Fatal("expected range starting before window to be rejected")
+	}
+
+	if Within(windowStart, windowEnd.Add(time.Minute), windowStart, windowEnd) {
+		t.Fatal("expected range ending after 17:00 to be rejected")
+	}
+}
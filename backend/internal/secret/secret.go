@@ -0,0 +1,91 @@
+// Package secret seals credentials that must be stored at rest, such as
+// calendar passwords, with AES-256-GCM under a server-side key. The key never
+// touches the database, so a dump of the tables does not reveal them.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KeySize is the length in bytes of the key accepted by NewBox.
+const KeySize = 32
+
+// prefix tags sealed values so the format can change later.
+const prefix = "v1:"
+
+// ErrMalformed is returned by Open for values that were not produced by Seal
+// or were sealed under a different key.
+var ErrMalformed = errors.New("sealed value is malformed or was sealed with another key")
+
+// Box seals and opens secrets under a single key.
+type Box struct {
+	aead cipher.AEAD
+}
+
+// NewBox constructs a box from a KeySize-byte key.
+func NewBox(key []byte) (*Box, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("secret key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Box{aead: aead}, nil
+}
+
+// ParseKey decodes a base64 encoded key, as generated by
+// `openssl rand -base64 32`, and constructs a box from it.
+func ParseKey(encoded string) (*Box, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("secret key is not valid base64: %w", err)
+	}
+
+	return NewBox(key)
+}
+
+// Seal encrypts plaintext under a fresh random nonce.
+func (b *Box) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return prefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value produced by Seal.
+func (b *Box) Open(sealed string) (string, error) {
+	encoded, ok := strings.CutPrefix(sealed, prefix)
+	if !ok {
+		return "", ErrMalformed
+	}
+
+	data, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(data) < b.aead.NonceSize() {
+		return "", ErrMalformed
+	}
+
+	nonce, ciphertext := data[:b.aead.NonceSize()], data[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrMalformed
+	}
+
+	return string(plaintext), nil
+}
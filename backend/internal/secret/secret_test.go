@@ -0,0 +1,69 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBoxRoundTrip(t *testing.T) {
+	box, err := ParseKey(base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, KeySize)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sealed, err := box.Seal("app-pass")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Contains(sealed, "app-pass") {
+		t.Fatalf("expected plaintext to be hidden got %q", sealed)
+	}
+
+	again, err := box.Seal("app-pass")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again == sealed {
+		t.Fatal("expected a fresh nonce for every seal")
+	}
+
+	opened, err := box.Open(sealed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opened != "app-pass" {
+		t.Fatalf("expected app-pass got %q", opened)
+	}
+}
+
+func TestBoxOpenRejectsOtherKeyAndPlaintext(t *testing.T) {
+	box, err := NewBox(bytes.Repeat([]byte{1}, KeySize))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	other, err := NewBox(bytes.Repeat([]byte{2}, KeySize))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sealed, err := other.Seal("app-pass")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, value := range []string{sealed, "app-pass", prefix + "!!"} {
+		if _, err := box.Open(value); !errors.Is(err, ErrMalformed) {
+			t.Fatalf("expected ErrMalformed for %q got %v", value, err)
+		}
+	}
+}
+
+func TestNewBoxRejectsShortKey(t *testing.T) {
+	if _, err := NewBox([]byte("short")); err == nil {
+		t.Fatal("expected an error for a short key")
+	}
+}
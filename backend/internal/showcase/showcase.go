@@ -0,0 +1,125 @@
+// Package showcase packs demo requests into the time left in upcoming chapter
+// sessions.
+package showcase
+
+import (
+	"sort"
+	"time"
+
+	"github.com/example/intent/backend/internal/schedule"
+	"github.com/google/uuid"
+)
+
+// Window is the part of a session demos may be scheduled into.
+type Window struct {
+	SessionID uuid.UUID
+	Start     time.Time
+	End       time.Time
+}
+
+// Slot is the time a demo holds within a session.
+type Slot struct {
+	SessionID uuid.UUID
+	Start     time.Time
+	End       time.Time
+}
+
+// Request is a demo in queue order. Requests with a Fixed slot (locked by a
+// facilitator or already under way) keep it and are packed around.
+type Request struct {
+	ID       uuid.UUID
+	Duration time.Duration
+	Fixed    *Slot
+}
+
+// SessionWindow returns the window left in a session at now. The window
+// never extends past the end of the chapter window on that day (17:00 in
+// loc); ok is false when nothing is left.
+func SessionWindow(sessionID uuid.UUID, startsAt, endsAt, now time.Time, loc *time.Location) (Window, bool) {
+	window := Window{SessionID: sessionID, Start: startsAt, End: endsAt}
+
+	local := startsAt.In(loc)
+	if _, chapterEnd, err := schedule.WindowOn(local, loc); err == nil && chapterEnd.Before(window.End) {
+		window.End = chapterEnd
+	}
+
+	if now.After(window.Start) {
+		window.Start = now.Truncate(time.Minute)
+		if window.Start.Before(now) {
+			window.Start = window.Start.Add(time.Minute)
+		}
+	}
+
+	return window, window.Start.Before(window.End)
+}
+
+// Fits reports whether slot lies within one of the windows of its session.
+func Fits(slot Slot, windows []Window) bool {
+	for _, window := range windows {
+		if window.SessionID == slot.SessionID && schedule.Within(slot.Start, slot.End, window.Start, window.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// Pack assigns each request without a fixed slot the earliest gap, across
+// windows in chronological order, that is long enough for it. Requests are
+// placed in queue order so earlier requests get earlier slots; a later,
+// shorter demo may still fill a gap an earlier one did not fit. Requests
+// that fit nowhere are absent from the result.
+func Pack(windows []Window, requests []Request) map[uuid.UUID]Slot {
+	ordered := append([]Window(nil), windows...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Start.Before(ordered[j].Start) })
+
+	gaps := make([][]Slot, len(ordered))
+	for i, window := range ordered {
+		gaps[i] = []Slot{{SessionID: window.SessionID, Start: window.Start, End: window.End}}
+		for _, request := range requests {
+			if request.Fixed != nil && request.Fixed.SessionID == window.SessionID {
+				gaps[i] = subtract(gaps[i], *request.Fixed)
+			}
+		}
+	}
+
+	slots := make(map[uuid.UUID]Slot)
+	for _, request := range requests {
+		if request.Fixed != nil || request.Duration <= 0 {
+			continue
+		}
+
+	search:
+		for i := range gaps {
+			for j, gap := range gaps[i] {
+				if gap.End.Sub(gap.Start) < request.Duration {
+					continue
+				}
+				slot := Slot{SessionID: gap.SessionID, Start: gap.Start, End: gap.Start.Add(request.Duration)}
+				slots[request.ID] = slot
+				gaps[i][j].Start = slot.End
+				break search
+			}
+		}
+	}
+
+	return slots
+}
+
+// subtract removes taken from the gaps, splitting a gap when taken falls in
+// its middle.
+func subtract(gaps []Slot, taken Slot) []Slot {
+	remaining := make([]Slot, 0, len(gaps)+1)
+	for _, gap := range gaps {
+		if !taken.Start.Before(gap.End) || !taken.End.After(gap.Start) {
+			remaining = append(remaining, gap)
+			continue
+		}
+		if gap.Start.Before(taken.Start) {
+			remaining = append(remaining, Slot{SessionID: gap.SessionID, Start: gap.Start, End: taken.Start})
+		}
+		if taken.End.Before(gap.End) {
+			remaining = append(remaining, Slot{SessionID: gap.SessionID, Start: taken.End, End: gap.End})
+		}
+	}
+	return remaining
+}
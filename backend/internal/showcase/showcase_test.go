@@ -0,0 +1,92 @@
+package showcase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPackFillsWindowsInQueueOrder(t *testing.T) {
+	monday, thursday := uuid.New(), uuid.New()
+	mondayStart := time.Date(2024, 5, 6, 16, 0, 0, 0, time.UTC)
+	thursdayStart := time.Date(2024, 5, 9, 13, 0, 0, 0, time.UTC)
+
+	windows := []Window{
+		{SessionID: thursday, Start: thursdayStart, End: thursdayStart.Add(4 * time.Hour)},
+		{SessionID: monday, Start: mondayStart, End: mondayStart.Add(time.Hour)},
+	}
+
+	first, second, third := uuid.New(), uuid.New(), uuid.New()
+	slots := Pack(windows, []Request{
+		{ID: first, Duration: 40 * time.Minute},
+		{ID: second, Duration: 30 * time.Minute},
+		{ID: third, Duration: 20 * time.Minute},
+	})
+
+	if slot := slots[first]; slot.SessionID != monday || !slot.Start.Equal(mondayStart) {
+		t.Fatalf("expected first request at the start of Monday got %+v", slot)
+	}
+
+	if slot := slots[second]; slot.SessionID != thursday || !slot.Start.Equal(thursdayStart) {
+		t.Fatalf("expected second request to spill over to Thursday got %+v", slot)
+	}
+
+	if slot := slots[third]; slot.SessionID != monday || !slot.Start.Equal(mondayStart.Add(40*time.Minute)) {
+		t.Fatalf("expected third request to fill the rest of Monday got %+v", slot)
+	}
+}
+
+func TestPackWorksAroundFixedSlots(t *testing.T) {
+	sessionID := uuid.New()
+	start := time.Date(2024, 5, 6, 13, 0, 0, 0, time.UTC)
+	windows := []Window{{SessionID: sessionID, Start: start, End: start.Add(time.Hour)}}
+
+	locked := &Slot{SessionID: sessionID, Start: start.Add(10 * time.Minute), End: start.Add(40 * time.Minute)}
+	fixedID, shortID, longID := uuid.New(), uuid.New(), uuid.New()
+
+	slots := Pack(windows, []Request{
+		{ID: longID, Duration: 25 * time.Minute},
+		{ID: fixedID, Duration: 30 * time.Minute, Fixed: locked},
+		{ID: shortID, Duration: 10 * time.Minute},
+	})
+
+	if _, ok := slots[fixedID]; ok {
+		t.Fatalf("expected fixed request to be left alone")
+	}
+
+	if _, ok := slots[longID]; ok {
+		t.Fatalf("expected 25 minute request not to fit around the locked slot")
+	}
+
+	if slot := slots[shortID]; !slot.Start.Equal(start) {
+		t.Fatalf("expected short request before the locked slot got %+v", slot)
+	}
+}
+
+func TestSessionWindowStopsAtFive(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+
+	start := time.Date(2024, 5, 6, 13, 0, 0, 0, loc)
+	now := time.Date(2024, 5, 6, 14, 20, 30, 0, loc)
+
+	window, ok := SessionWindow(uuid.New(), start, start.Add(5*time.Hour), now, loc)
+	if !ok {
+		t.Fatalf("expected time left in the session")
+	}
+
+	if want := time.Date(2024, 5, 6, 14, 21, 0, 0, loc); !window.Start.Equal(want) {
+		t.Fatalf("expected window to start at %s got %s", want, window.Start)
+	}
+
+	if want := time.Date(2024, 5, 6, 17, 0, 0, 0, loc); !window.End.Equal(want) {
+		t.Fatalf("expected window to end at 17:00 got %s", window.End.In(loc))
+	}
+
+	if _, ok := SessionWindow(uuid.New(), start, start.Add(4*time.Hour), start.Add(5*time.Hour), loc); ok {
+		t.Fatalf("expected no window once the session is over")
+	}
+}
@@ -0,0 +1,45 @@
+package similarity
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Trigrams returns the set of character trigrams for s using the same rules
+// as Postgres' pg_trgm extension: text is lower-cased, split into words on
+// non-alphanumeric characters, and each word is padded with two leading
+// spaces and one trailing space before being shingled.
+func Trigrams(s string) map[string]struct{} {
+	set := make(map[string]struct{})
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+
+	return set
+}
+
+// Score returns the trigram similarity of a and b in the range [0, 1],
+// matching the semantics of pg_trgm's similarity() function.
+func Score(a, b string) float64 {
+	left := Trigrams(a)
+	right := Trigrams(b)
+	if len(left) == 0 || len(right) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for gram := range left {
+		if _, ok := right[gram]; ok {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(left)+len(right)-shared)
+}
@@ -0,0 +1,50 @@
+package similarity
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTrigramsMatchesPgTrgm(t *testing.T) {
+	got := Trigrams("Cat")
+	want := []string{"  c", " ca", "cat", "at "}
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d trigrams got %d: %v", len(want), len(got), got)
+	}
+
+	for _, gram := range want {
+		if _, ok := got[gram]; !ok {
+			t.Fatalf("expected trigram %q in %v", gram, got)
+		}
+	}
+}
+
+func TestScoreIdenticalText(t *testing.T) {
+	statement := "Enable Product Discovery Guild to co-create sprint objectives with clarity"
+
+	if score := Score(statement, statement); score != 1 {
+		t.Fatalf("expected identical statements to score 1 got %f", score)
+	}
+}
+
+func TestScoreIgnoresCaseAndPunctuation(t *testing.T) {
+	score := Score("Enable product discovery!", "enable PRODUCT discovery")
+	if score != 1 {
+		t.Fatalf("expected case and punctuation to be ignored got %f", score)
+	}
+}
+
+func TestScorePartialOverlap(t *testing.T) {
+	// "word" and "words" share 4 of 7 distinct trigrams in pg_trgm.
+	score := Score("word", "words")
+	if math.Abs(score-4.0/7.0) > 1e-9 {
+		t.Fatalf("expected score 4/7 got %f", score)
+	}
+}
+
+func TestScoreEmptyInput(t *testing.T) {
+	if score := Score("", "anything"); score != 0 {
+		t.Fatalf("expected empty input to score 0 got %f", score)
+	}
+}
@@ -0,0 +1,159 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultClient is used to call the tracker's API.
+var DefaultClient = &http.Client{Timeout: 15 * time.Second}
+
+// Jira talks to the Jira REST API, version 2, which Jira Cloud, Jira Data
+// Center and most Jira-compatible trackers serve.
+type Jira struct {
+	baseURL   string
+	project   string
+	issueType string
+	email     string
+	token     string
+	client    *http.Client
+}
+
+// NewJira constructs a Jira tracker creating issues of issueType in project.
+// With an email the token is sent as a Jira Cloud API token over basic auth;
+// without one it is sent as a personal access token.
+func NewJira(baseURL, project, issueType, email, token string, client *http.Client) *Jira {
+	return &Jira{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		project:   project,
+		issueType: issueType,
+		email:     email,
+		token:     token,
+		client:    client,
+	}
+}
+
+// Name implements Tracker.
+func (j *Jira) Name() string { return "jira" }
+
+type jiraCreateRequest struct {
+	Fields jiraCreateFields `json:"fields"`
+}
+
+type jiraCreateFields struct {
+	Project     jiraKey  `json:"project"`
+	Summary     string   `json:"summary"`
+	Description string   `json:"description,omitempty"`
+	IssueType   jiraName `json:"issuetype"`
+}
+
+type jiraKey struct {
+	Key string `json:"key"`
+}
+
+type jiraName struct {
+	Name string `json:"name"`
+}
+
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Status struct {
+			Name           string  `json:"name"`
+			StatusCategory jiraKey `json:"statusCategory"`
+		} `json:"status"`
+	} `json:"fields"`
+}
+
+// CreateIssue implements Tracker.
+func (j *Jira) CreateIssue(ctx context.Context, input IssueInput) (Issue, error) {
+	body := jiraCreateRequest{Fields: jiraCreateFields{
+		Project:     jiraKey{Key: j.project},
+		Summary:     input.Summary,
+		Description: input.Description,
+		IssueType:   jiraName{Name: j.issueType},
+	}}
+
+	var created jiraKey
+	if err := j.do(ctx, http.MethodPost, "/rest/api/2/issue", body, &created); err != nil {
+		return Issue{}, err
+	}
+
+	// The create response carries no status, so read the issue back.
+	return j.GetIssue(ctx, created.Key)
+}
+
+// GetIssue implements Tracker.
+func (j *Jira) GetIssue(ctx context.Context, key string) (Issue, error) {
+	var issue jiraIssue
+	if err := j.do(ctx, http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(key)+"?fields=status", nil, &issue); err != nil {
+		return Issue{}, err
+	}
+
+	return Issue{
+		Key:      issue.Key,
+		URL:      j.baseURL + "/browse/" + issue.Key,
+		Status:   issue.Fields.Status.Name,
+		Category: jiraCategory(issue.Fields.Status.StatusCategory.Key),
+	}, nil
+}
+
+// jiraCategory maps Jira's fixed status category keys onto ours.
+func jiraCategory(key string) string {
+	switch key {
+	case "indeterminate":
+		return CategoryInProgress
+	case "done":
+		return CategoryDone
+	default:
+		return CategoryToDo
+	}
+}
+
+func (j *Jira) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, j.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if j.email != "" {
+		req.SetBasicAuth(j.email, j.token)
+	} else if j.token != "" {
+		req.Header.Set("Authorization", "Bearer "+j.token)
+	}
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("jira responded %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,141 @@
+package tracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeJira is an in-process stand-in for the parts of the Jira REST API the
+// tracker uses.
+type fakeJira struct {
+	mu       sync.Mutex
+	issues   map[string]fakeIssue
+	next     int
+	lastAuth string
+	created  jiraCreateRequest
+}
+
+type fakeIssue struct {
+	status   string
+	category string
+}
+
+func newFakeJira(t *testing.T) (*fakeJira, *httptest.Server) {
+	t.Helper()
+
+	fake := &fakeJira{issues: map[string]fakeIssue{}, next: 100}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /rest/api/2/issue", func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		fake.lastAuth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&fake.created); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		fake.next++
+		key := fmt.Sprintf("%s-%d", fake.created.Fields.Project.Key, fake.next)
+		fake.issues[key] = fakeIssue{status: "To Do", category: "new"}
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id":"%d","key":%q,"self":"http://jira/rest/api/2/issue/%d"}`, fake.next, key, fake.next)
+	})
+	mux.HandleFunc("GET /rest/api/2/issue/{key}", func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		fake.lastAuth = r.Header.Get("Authorization")
+		issue, ok := fake.issues[r.PathValue("key")]
+		if !ok {
+			http.Error(w, `{"errorMessages":["Issue does not exist or you do not have permission to see it."]}`, http.StatusNotFound)
+			return
+		}
+
+		fmt.Fprintf(w, `{"key":%q,"fields":{"status":{"name":%q,"statusCategory":{"key":%q}}}}`, r.PathValue("key"), issue.status, issue.category)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return fake, server
+}
+
+func (f *fakeJira) move(key, status, category string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.issues[key] = fakeIssue{status: status, category: category}
+}
+
+func TestJiraCreateIssueThenPullStatus(t *testing.T) {
+	fake, server := newFakeJira(t)
+	jira := NewJira(server.URL+"/", "PLAT", "Task", "ana@example.com", "api-token", server.Client())
+
+	issue, err := jira.CreateIssue(context.Background(), IssueInput{Summary: "Cut checkout latency", Description: "p95 under 300ms"})
+	if err != nil {
+		t.Fatalf("CreateIssue returned error: %v", err)
+	}
+
+	if issue.Key != "PLAT-101" || issue.URL != server.URL+"/browse/PLAT-101" || issue.Status != "To Do" || issue.Category != CategoryToDo {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+
+	fields := fake.created.Fields
+	if fields.Summary != "Cut checkout latency" || fields.Description != "p95 under 300ms" || fields.IssueType.Name != "Task" {
+		t.Fatalf("unexpected create request %+v", fields)
+	}
+
+	if !strings.HasPrefix(fake.lastAuth, "Basic ") {
+		t.Fatalf("expected basic auth got %q", fake.lastAuth)
+	}
+
+	fake.move("PLAT-101", "In Review", "indeterminate")
+
+	issue, err = jira.GetIssue(context.Background(), "PLAT-101")
+	if err != nil {
+		t.Fatalf("GetIssue returned error: %v", err)
+	}
+
+	if issue.Status != "In Review" || issue.Category != CategoryInProgress {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+
+	if got := SuggestedStatus(issue.Category); got != "active" {
+		t.Fatalf("expected suggestion active got %q", got)
+	}
+}
+
+func TestJiraBearerTokenWithoutEmail(t *testing.T) {
+	fake, server := newFakeJira(t)
+	fake.move("OPS-7", "Done", "done")
+
+	issue, err := NewJira(server.URL, "OPS", "Task", "", "pat", server.Client()).GetIssue(context.Background(), "OPS-7")
+	if err != nil {
+		t.Fatalf("GetIssue returned error: %v", err)
+	}
+
+	if fake.lastAuth != "Bearer pat" {
+		t.Fatalf("expected bearer auth got %q", fake.lastAuth)
+	}
+
+	if issue.Category != CategoryDone {
+		t.Fatalf("expected done category got %q", issue.Category)
+	}
+}
+
+func TestJiraGetIssueNotFound(t *testing.T) {
+	_, server := newFakeJira(t)
+
+	_, err := NewJira(server.URL, "OPS", "Task", "", "", server.Client()).GetIssue(context.Background(), "OPS-404")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound got %v", err)
+	}
+}
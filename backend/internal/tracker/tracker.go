@@ -0,0 +1,81 @@
+// Package tracker links intents to issues in an external work tracker. It
+// creates issues from intents and pulls their status back, surfacing status
+// changes as suggestions for the intent's owner rather than applying them.
+package tracker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/example/intent/backend/internal/database"
+)
+
+// Status categories trackers map their workflow statuses onto.
+const (
+	CategoryToDo       = "todo"
+	CategoryInProgress = "in_progress"
+	CategoryDone       = "done"
+)
+
+// ErrNotFound is returned when the tracker has no issue with the given key.
+var ErrNotFound = errors.New("issue not found in tracker")
+
+// Issue is an issue as the tracker reports it. Status is the tracker's own
+// workflow status name; Category is what it means.
+type Issue struct {
+	Key      string
+	URL      string
+	Status   string
+	Category string
+}
+
+// IssueInput captures the fields used to create an issue.
+type IssueInput struct {
+	Summary     string
+	Description string
+}
+
+// Tracker creates and reads issues in a work tracker.
+type Tracker interface {
+	Name() string
+	CreateIssue(ctx context.Context, input IssueInput) (Issue, error)
+	GetIssue(ctx context.Context, key string) (Issue, error)
+}
+
+// SuggestedStatus returns the intent status an issue in category implies, or
+// "" when it implies none. An issue that has not been started says nothing
+// about whether the intent is a draft.
+func SuggestedStatus(category string) string {
+	switch category {
+	case CategoryInProgress:
+		return database.IntentActive
+	case CategoryDone:
+		return database.IntentDone
+	default:
+		return ""
+	}
+}
+
+// IssueFromIntent builds the issue created for an intent.
+func IssueFromIntent(intent database.Intent) IssueInput {
+	description := intent.Context
+	if intent.ExpectedOutcome != "" {
+		if description != "" {
+			description += "\n\n"
+		}
+		description += "Expected outcome: " + intent.ExpectedOutcome
+	}
+	return IssueInput{Summary: intent.Statement, Description: description}
+}
+
+// Sync pulls the status of a linked issue from t and records it, raising a
+// status suggestion when the issue moved to a status the intent is not in.
+func Sync(ctx context.Context, db *sql.DB, t Tracker, link database.IntentLink) (database.IntentLink, *database.StatusSuggestion, error) {
+	issue, err := t.GetIssue(ctx, link.ExternalKey)
+	if err != nil {
+		return database.IntentLink{}, nil, err
+	}
+
+	return database.RecordTrackerStatus(ctx, db, link.ID, issue.Status, SuggestedStatus(issue.Category))
+}
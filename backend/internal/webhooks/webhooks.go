@@ -0,0 +1,173 @@
+// Package webhooks delivers outbox events to subscribers' endpoints.
+//
+// Each delivery is a POST of a JSON Envelope. The request carries the event
+// type and id, a Unix timestamp and an HMAC-SHA256 signature of the
+// timestamp and body keyed with the subscription's secret, so a receiver can
+// check that the request came from us and reject replays.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/example/intent/backend/internal/database"
+)
+
+// KindDeliver is the job kind that delivers one event to one subscription.
+const KindDeliver = database.WebhookDeliveryJob
+
+// Request headers set on every delivery.
+const (
+	EventHeader     = "X-Intent-Event"
+	DeliveryHeader  = "X-Intent-Delivery"
+	TimestampHeader = "X-Intent-Timestamp"
+	SignatureHeader = "X-Intent-Signature"
+)
+
+// DefaultClient is used to call subscriber endpoints.
+var DefaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// Envelope is the body POSTed to subscribers. Data holds the changed
+// resource; deleted resources carry only their id.
+type Envelope struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt string          `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Deliverer runs webhook.deliver jobs.
+type Deliverer struct {
+	logger *slog.Logger
+	db     *sql.DB
+	client *http.Client
+}
+
+// NewDeliverer constructs a Deliverer that calls endpoints with client.
+func NewDeliverer(logger *slog.Logger, db *sql.DB, client *http.Client) *Deliverer {
+	return &Deliverer{logger: logger, db: db, client: client}
+}
+
+// Deliver is the job handler for KindDeliver. Every attempt is written to the
+// delivery log; an error or non-2xx response fails the job so the queue
+// retries it with backoff. Events for deleted or paused subscriptions are
+// dropped.
+func (d *Deliverer) Deliver(ctx context.Context, job database.Job) error {
+	var payload database.WebhookDeliveryPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return err
+	}
+
+	subscription, err := database.GetWebhookSubscription(ctx, d.db, payload.SubscriptionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !subscription.Active {
+		d.logger.InfoContext(ctx, "webhook delivery skipped for paused subscription", "subscription_id", subscription.ID, "event_id", payload.EventID)
+		return nil
+	}
+
+	event, err := database.GetWebhookEvent(ctx, d.db, payload.EventID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	delivery := d.post(ctx, subscription, event)
+	delivery.Attempt = job.Attempts
+
+	if _, err := database.RecordWebhookDelivery(ctx, d.db, delivery); err != nil {
+		return err
+	}
+
+	if !delivery.Succeeded() {
+		if delivery.Error != "" {
+			return fmt.Errorf("deliver %s to webhook %s: %s", event.Type, subscription.ID, delivery.Error)
+		}
+		return fmt.Errorf("deliver %s to webhook %s: endpoint responded %d", event.Type, subscription.ID, *delivery.StatusCode)
+	}
+
+	return nil
+}
+
+// post sends the event and describes the attempt; it never fails, recording
+// transport errors on the returned delivery instead.
+func (d *Deliverer) post(ctx context.Context, subscription database.WebhookSubscription, event database.WebhookEvent) database.WebhookDelivery {
+	delivery := database.WebhookDelivery{SubscriptionID: subscription.ID, EventID: event.ID, EventType: event.Type}
+
+	body, err := json.Marshal(Envelope{
+		ID:        event.ID.String(),
+		Type:      event.Type,
+		CreatedAt: event.CreatedAt.UTC().Format(time.RFC3339),
+		Data:      event.Payload,
+	})
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+
+	timestamp := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event.Type)
+	req.Header.Set(DeliveryHeader, event.ID.String())
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, timestamp, body))
+
+	started := time.Now()
+	resp, err := d.client.Do(req)
+	delivery.Duration = time.Since(started)
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	status := resp.StatusCode
+	delivery.StatusCode = &status
+	return delivery
+}
+
+// Sign returns the signature header value for body sent at timestamp:
+// "sha256=" followed by the hex HMAC-SHA256 of "<unix seconds>.<body>".
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSecret generates a random signing secret.
+func NewSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,157 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+
+	"github.com/example/intent/backend/internal/database"
+)
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func deliveryJob(t *testing.T, eventID, subscriptionID uuid.UUID, attempts int) database.Job {
+	t.Helper()
+
+	payload, err := json.Marshal(database.WebhookDeliveryPayload{EventID: eventID, SubscriptionID: subscriptionID})
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+
+	return database.Job{ID: uuid.New(), Kind: KindDeliver, Payload: payload, Attempts: attempts}
+}
+
+func expectSubscription(mock sqlmock.Sqlmock, id uuid.UUID, url string, active bool) {
+	mock.ExpectQuery(regexp.QuoteMeta("FROM webhook_subscriptions WHERE id = $1")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url", "secret", "event_types", "description", "active", "created_at", "updated_at"}).
+			AddRow(id, url, "s3cret", []byte(`[]`), "", active, time.Now(), time.Now()))
+}
+
+func expectEvent(mock sqlmock.Sqlmock, id uuid.UUID) {
+	mock.ExpectQuery(regexp.QuoteMeta("FROM webhook_events WHERE id = $1")).
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "type", "payload", "created_at"}).
+			AddRow(id, database.EventGoalDeleted, []byte(`{"id":"f6d1c8a4-1c56-4b43-9f0e-0d0e6a1f5b11"}`), time.Now()))
+}
+
+func TestDeliverSignsRequestAndLogsAttempt(t *testing.T) {
+	var (
+		gotSignature string
+		gotEvent     string
+		gotEnvelope  Envelope
+		verified     bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotSignature = r.Header.Get(SignatureHeader)
+		gotEvent = r.Header.Get(EventHeader)
+		_ = json.Unmarshal(body, &gotEnvelope)
+
+		seconds, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		verified = err == nil && Sign("s3cret", time.Unix(seconds, 0), body) == gotSignature
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	eventID, subscriptionID := uuid.New(), uuid.New()
+
+	expectSubscription(mock, subscriptionID, server.URL, true)
+	expectEvent(mock, eventID)
+	mock.ExpectExec("INSERT INTO webhook_deliveries").
+		WithArgs(sqlmock.AnyArg(), subscriptionID, eventID, 1, sqlmock.AnyArg(), "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	deliverer := NewDeliverer(discardLogger(), db, server.Client())
+	if err := deliverer.Deliver(context.Background(), deliveryJob(t, eventID, subscriptionID, 1)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !verified {
+		t.Fatalf("signature %q does not verify", gotSignature)
+	}
+
+	if gotEvent != database.EventGoalDeleted || gotEnvelope.ID != eventID.String() || string(gotEnvelope.Data) != `{"id":"f6d1c8a4-1c56-4b43-9f0e-0d0e6a1f5b11"}` {
+		t.Fatalf("unexpected delivery %q %+v", gotEvent, gotEnvelope)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestDeliverFailsJobOnServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(server.Close)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	eventID, subscriptionID := uuid.New(), uuid.New()
+
+	expectSubscription(mock, subscriptionID, server.URL, true)
+	expectEvent(mock, eventID)
+	mock.ExpectExec("INSERT INTO webhook_deliveries").
+		WithArgs(sqlmock.AnyArg(), subscriptionID, eventID, 3, sqlmock.AnyArg(), "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	deliverer := NewDeliverer(discardLogger(), db, server.Client())
+	if err := deliverer.Deliver(context.Background(), deliveryJob(t, eventID, subscriptionID, 3)); err == nil {
+		t.Fatal("expected a 502 to fail the job so it is retried")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestDeliverDropsEventsForPausedSubscription(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create sqlmock: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	subscriptionID := uuid.New()
+	expectSubscription(mock, subscriptionID, "http://127.0.0.1:1", false)
+
+	deliverer := NewDeliverer(discardLogger(), db, DefaultClient)
+	if err := deliverer.Deliver(context.Background(), deliveryJob(t, uuid.New(), subscriptionID, 1)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expectations: %v", err)
+	}
+}
+
+func TestSignMatchesKnownVector(t *testing.T) {
+	got := Sign("secret", time.Unix(1700000000, 0), []byte(`{"id":"1"}`))
+	want := "sha256=086f6aff7bd084c98679825129c5a64dbad88c760016d6d2c0fb123f27951d54"
+	if got != want {
+		t.Fatalf("expected %q got %q", want, got)
+	}
+}